@@ -0,0 +1,145 @@
+package geojson
+
+import (
+	"math"
+)
+
+// NewPointGeometryZ creates and initializes a point geometry with an
+// altitude, the third coordinate.
+func NewPointGeometryZ(lon, lat, alt float64) *Geometry {
+	return NewPointGeometry([]float64{lon, lat, alt})
+}
+
+// NewPointFeatureZ creates and initializes a GeoJSON feature with a point
+// geometry with an altitude, the third coordinate.
+func NewPointFeatureZ(lon, lat, alt float64) *Feature {
+	return NewFeature(NewPointGeometryZ(lon, lat, alt))
+}
+
+// HasZ returns true if all the positions of the geometry have an altitude,
+// the third coordinate, and it has at least one. Geometries mixing
+// positions with and without altitude fail validation, see
+// ValidationMixedDimensions.
+func (g *Geometry) HasZ() bool {
+	n, z := 0, 0
+	forEachPosition(g, func(p []float64) {
+		n++
+		if len(p) >= 3 {
+			z++
+		}
+	})
+	return n > 0 && z == n
+}
+
+// DropZ removes in place the altitudes of the positions of the geometry,
+// and the coordinates after them, like measures, leaving two dimensional
+// positions. A three dimensional bounding box is made two dimensional.
+// The members of geometry collections are changed too.
+func (g *Geometry) DropZ() {
+	if g == nil {
+		return
+	}
+
+	if len(g.BoundingBox) == 6 {
+		bb := g.BoundingBox
+		g.BoundingBox = []float64{bb[0], bb[1], bb[3], bb[4]}
+	}
+	for _, m := range g.Geometries {
+		m.DropZ()
+	}
+	replacePositions(g, func(p []float64) []float64 {
+		if len(p) > 2 {
+			return p[:2:2]
+		}
+		return p
+	})
+}
+
+// ForceZ sets in place the altitude of the positions of the geometry
+// without one, the others are left as they are. A two dimensional bounding
+// box is made three dimensional, spanning the altitudes. The members of
+// geometry collections are changed too.
+func (g *Geometry) ForceZ(altitude float64) {
+	if g == nil {
+		return
+	}
+
+	for _, m := range g.Geometries {
+		m.ForceZ(altitude)
+	}
+	replacePositions(g, func(p []float64) []float64 {
+		if len(p) == 2 {
+			return []float64{p[0], p[1], altitude}
+		}
+		return p
+	})
+
+	if len(g.BoundingBox) == 4 {
+		low, high := math.Inf(1), math.Inf(-1)
+		forEachPosition(g, func(p []float64) {
+			if len(p) >= 3 {
+				low, high = math.Min(low, p[2]), math.Max(high, p[2])
+			}
+		})
+		if low <= high {
+			bb := g.BoundingBox
+			g.BoundingBox = []float64{bb[0], bb[1], low, bb[2], bb[3], high}
+		}
+	}
+}
+
+// replacePositions replaces in place the positions of the geometry, but
+// not of the members of a geometry collection, by the result of fn.
+func replacePositions(g *Geometry, fn func(p []float64) []float64) {
+	replace := func(path [][]float64) {
+		for i, p := range path {
+			path[i] = fn(p)
+		}
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) != 0 {
+			g.Point = fn(g.Point)
+		}
+	case GeometryMultiPoint:
+		replace(g.MultiPoint)
+	case GeometryLineString:
+		replace(g.LineString)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			replace(l)
+		}
+	case GeometryPolygon:
+		for _, r := range g.Polygon {
+			replace(r)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				replace(r)
+			}
+		}
+	}
+}
+
+// mixedDimensions returns true if the positions of the geometry, of at
+// least 2 coordinates, do not all have as many. The members of geometry
+// collections are not compared to each other.
+func mixedDimensions(g *Geometry) bool {
+	if g.Type == GeometryCollection {
+		return false
+	}
+
+	dimension, mixed := 0, false
+	forEachPosition(g, func(p []float64) {
+		switch {
+		case len(p) < 2:
+		case dimension == 0:
+			dimension = len(p)
+		case len(p) != dimension:
+			mixed = true
+		}
+	})
+	return mixed
+}
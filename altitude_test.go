@@ -0,0 +1,89 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPointGeometryZ(t *testing.T) {
+	g := NewPointGeometryZ(4.35, 50.85, 120)
+	if !reflect.DeepEqual(g.Point, []float64{4.35, 50.85, 120}) {
+		t.Errorf("incorrect point, got %v", g.Point)
+	}
+	if f := NewPointFeatureZ(4.35, 50.85, 120); !f.Geometry.HasZ() {
+		t.Errorf("should have an altitude, got %v", f.Geometry.Point)
+	}
+}
+
+func TestGeometryHasZ(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		hasZ     bool
+	}{
+		{"2D point", NewPointGeometry([]float64{1, 2}), false},
+		{"3D line", NewLineStringGeometry([][]float64{{1, 2, 3}, {4, 5, 6}}), true},
+		{"mixed line", NewLineStringGeometry([][]float64{{1, 2, 3}, {4, 5}}), false},
+		{"empty", NewEmptyGeometry(GeometryLineString), false},
+		{"3D collection", NewCollectionGeometry(NewPointGeometryZ(1, 2, 3), NewPointGeometryZ(4, 5, 6)), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.geometry.HasZ() != c.hasZ {
+				t.Errorf("incorrect HasZ, got %v", !c.hasZ)
+			}
+		})
+	}
+}
+
+func TestGeometryDropZ(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometryZ(1, 2, 3),
+		NewPolygonGeometry([][][]float64{{{0, 0, 1, 7}, {1, 0, 2}, {1, 1}, {0, 0, 1, 7}}}),
+	)
+	g.BoundingBox = []float64{0, 0, 1, 1, 2, 3}
+
+	g.DropZ()
+	expected := NewCollectionGeometry(
+		NewPointGeometry([]float64{1, 2}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+	)
+	expected.BoundingBox = []float64{0, 0, 1, 2}
+	if !g.Equal(expected) || !reflect.DeepEqual(g.BoundingBox, expected.BoundingBox) {
+		t.Errorf("incorrect geometry, got %v", g)
+	}
+}
+
+func TestGeometryForceZ(t *testing.T) {
+	line := []float64{1, 1}
+	g := NewLineStringGeometry([][]float64{{0, 0}, {1, 0, 5}, line})
+	g.BoundingBox = []float64{0, 0, 1, 1}
+
+	g.ForceZ(2)
+	if !reflect.DeepEqual(g.LineString, [][]float64{{0, 0, 2}, {1, 0, 5}, {1, 1, 2}}) {
+		t.Errorf("incorrect positions, got %v", g.LineString)
+	}
+	if !reflect.DeepEqual(g.BoundingBox, []float64{0, 0, 2, 1, 1, 5}) {
+		t.Errorf("incorrect bounding box, got %v", g.BoundingBox)
+	}
+	if !g.HasZ() || len(g.Validate()) != 0 {
+		t.Errorf("should be a valid 3D geometry, got %v", g.Validate())
+	}
+	if len(line) != 2 {
+		t.Errorf("should not change the positions in place")
+	}
+}
+
+func TestValidateMixedDimensions(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{1, 2, 3}, {4, 5}})
+	errs := g.Validate()
+	if len(errs) != 1 || errs[0].Kind != ValidationMixedDimensions || errs[0].Pointer != "/coordinates" {
+		t.Errorf("should flag the mixed dimensions, got %v", errs)
+	}
+
+	c := NewCollectionGeometry(NewPointGeometryZ(1, 2, 3), NewPointGeometry([]float64{4, 5}))
+	if errs := c.Validate(); len(errs) != 0 {
+		t.Errorf("should not compare the members of collections, got %v", errs)
+	}
+}
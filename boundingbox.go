@@ -1,6 +1,10 @@
 package geojson
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"sort"
+)
 
 func decodeBoundingBox(bb interface{}) ([]float64, error) {
 	if bb == nil {
@@ -12,17 +16,400 @@ func decodeBoundingBox(bb interface{}) ([]float64, error) {
 		return f, nil
 	case []interface{}:
 		bb := make([]float64, 0, 4)
-		for _, v := range f {
+		for i, v := range f {
 			switch c := v.(type) {
 			case float64:
 				bb = append(bb, c)
+			case int32:
+				bb = append(bb, float64(c))
+			case int64:
+				bb = append(bb, float64(c))
 			default:
-				return nil, fmt.Errorf("bounding box coordinate not usable, got %T", v)
+				return nil, decodeError(indexPointer("", i), v, "a number")
 			}
 
 		}
 		return bb, nil
 	default:
-		return nil, fmt.Errorf("bounding box property not usable, got %T", bb)
+		return nil, decodeError("", bb, "an array of numbers")
 	}
 }
+
+// boundingBox computes the two dimensional bounding box of all the positions
+// of the geometry. It returns nil if the geometry has no positions.
+func boundingBox(g *Geometry) []float64 {
+	var bb []float64
+	forEachPosition(g, func(p []float64) {
+		if len(p) < 2 {
+			return
+		}
+		if bb == nil {
+			bb = []float64{p[0], p[1], p[0], p[1]}
+			return
+		}
+		bb[0] = math.Min(bb[0], p[0])
+		bb[1] = math.Min(bb[1], p[1])
+		bb[2] = math.Max(bb[2], p[0])
+		bb[3] = math.Max(bb[3], p[1])
+	})
+	return bb
+}
+
+// boundingBoxPolygon returns the polygon covering the two dimensional bounding box.
+func boundingBoxPolygon(bb []float64) *Geometry {
+	return NewPolygonGeometry([][][]float64{{
+		{bb[0], bb[1]},
+		{bb[2], bb[1]},
+		{bb[2], bb[3]},
+		{bb[0], bb[3]},
+		{bb[0], bb[1]},
+	}})
+}
+
+// featureBoundingBox returns the two dimensional bounding box of the feature,
+// using its own bounding box when it has one.
+func featureBoundingBox(f *Feature) []float64 {
+	if f == nil {
+		return nil
+	}
+	if len(f.BoundingBox) == 4 {
+		return f.BoundingBox
+	}
+	if len(f.BoundingBox) == 6 {
+		return []float64{f.BoundingBox[0], f.BoundingBox[1], f.BoundingBox[3], f.BoundingBox[4]}
+	}
+	return boundingBox(f.Geometry)
+}
+
+// A BBox is a bounding box: the minimum coordinates of the box followed by
+// its maximum coordinates, 4 values in two dimensions and 6 in three. Its
+// west edge is east of its east edge when it crosses the antimeridian.
+// The BoundingBox members of GeoJSON objects convert to it, as in
+// BBox(g.BoundingBox).
+type BBox []float64
+
+// NewBBox creates and initializes a bounding box from its values, which
+// must be 4 or 6 finite numbers, the minimum latitude and altitude not
+// above the maximum ones.
+func NewBBox(values ...float64) (BBox, error) {
+	b := BBox(values)
+	if err := b.Check(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Check returns an error if the bounding box is not 4 or 6 finite numbers
+// with the minimum latitude and altitude not above the maximum ones.
+func (b BBox) Check() error {
+	if len(b) != 4 && len(b) != 6 {
+		return fmt.Errorf("bounding box must have 4 or 6 values, got %d", len(b))
+	}
+	for _, c := range b {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return fmt.Errorf("bounding box %v is not finite", []float64(b))
+		}
+	}
+	n := b.Dimension()
+	for i := 1; i < n; i++ {
+		if b[i] > b[n+i] {
+			return fmt.Errorf("bounding box minimum %v above maximum %v", b.Min(), b.Max())
+		}
+	}
+	return nil
+}
+
+// Dimension returns the number of dimensions of the bounding box, 2 or 3,
+// 0 if it has neither 4 nor 6 values.
+func (b BBox) Dimension() int {
+	switch len(b) {
+	case 4:
+		return 2
+	case 6:
+		return 3
+	}
+	return 0
+}
+
+// Min returns the minimum corner of the bounding box, nil if it is
+// invalid.
+func (b BBox) Min() []float64 {
+	n := b.Dimension()
+	if n == 0 {
+		return nil
+	}
+	return b[:n:n]
+}
+
+// Max returns the maximum corner of the bounding box, nil if it is
+// invalid.
+func (b BBox) Max() []float64 {
+	n := b.Dimension()
+	if n == 0 {
+		return nil
+	}
+	return b[n:]
+}
+
+// CrossesAntimeridian returns true if the west edge of the bounding box is
+// east of its east edge.
+func (b BBox) CrossesAntimeridian() bool {
+	n := b.Dimension()
+	return n != 0 && b[0] > b[n]
+}
+
+// Contains returns true if the position is inside the bounding box, or on
+// its edges. The altitude is only checked if both have one.
+func (b BBox) Contains(p []float64) bool {
+	n := b.Dimension()
+	if n == 0 || len(p) < 2 {
+		return false
+	}
+
+	if !containsLongitude(b[0], b[n], p[0]) || p[1] < b[1] || p[1] > b[n+1] {
+		return false
+	}
+	return n == 2 || len(p) < 3 || p[2] >= b[2] && p[2] <= b[5]
+}
+
+// Intersects returns true if the bounding boxes overlap, or touch. The
+// altitudes are only checked if both have them.
+func (b BBox) Intersects(other BBox) bool {
+	n, m := b.Dimension(), other.Dimension()
+	if n == 0 || m == 0 {
+		return false
+	}
+
+	if !intersectsLongitudes(b[0], b[n], other[0], other[m]) {
+		return false
+	}
+	if b[1] > other[m+1] || other[1] > b[n+1] {
+		return false
+	}
+	return n == 2 || m == 2 || b[2] <= other[5] && other[2] <= b[5]
+}
+
+// Expand returns the bounding box grown to contain the positions of the
+// geometry, a new two dimensional one for an empty bounding box. The
+// altitudes of a three dimensional box grow with the positions that have
+// one. Longitudes grow without crossing the antimeridian.
+func (b BBox) Expand(g *Geometry) BBox {
+	n := b.Dimension()
+	if n == 0 {
+		return BBox(boundingBox(g))
+	}
+
+	expanded := append(BBox(nil), b...)
+	forEachPosition(g, func(p []float64) {
+		for i := 0; i < n && i < len(p); i++ {
+			expanded[i] = math.Min(expanded[i], p[i])
+			expanded[n+i] = math.Max(expanded[n+i], p[i])
+		}
+	})
+	return expanded
+}
+
+// containsLongitude tells if the longitude is between west and east, going
+// east.
+func containsLongitude(west, east, lon float64) bool {
+	if west <= east {
+		return lon >= west && lon <= east
+	}
+	return lon >= west || lon <= east
+}
+
+// intersectsLongitudes tells if the longitude ranges, going east, overlap.
+func intersectsLongitudes(west1, east1, west2, east2 float64) bool {
+	switch {
+	case west1 <= east1 && west2 <= east2:
+		return west1 <= east2 && west2 <= east1
+	case west1 > east1 && west2 > east2:
+		// both contain the antimeridian
+		return true
+	case west1 > east1:
+		return west2 <= east1 || east2 >= west1
+	default:
+		return west1 <= east2 || east1 >= west2
+	}
+}
+
+// ComputeBoundingBox computes the bounding box of the positions of the
+// geometry, including those of the members of a collection, sets it as the
+// bounding box of the geometry and returns it. It is nil for a geometry
+// without positions.
+//
+// The bounding box is three dimensional if all the positions have an
+// altitude. It crosses the antimeridian, its west edge east of its east
+// edge, when that makes it narrower: RFC 7946 asks for geometries crossing
+// the antimeridian to be split in parts on each side, like the polygons of
+// a multi polygon, and the box then covers the parts across the
+// antimeridian. The extent in longitude of each line and polygon is kept.
+func (g *Geometry) ComputeBoundingBox() BBox {
+	if g == nil {
+		return nil
+	}
+	b := &bboxBuilder{}
+	b.geometry(g)
+	g.BoundingBox = b.bbox()
+	return g.BoundingBox
+}
+
+// ComputeBoundingBox computes the bounding box of the geometry of the
+// feature, like Geometry.ComputeBoundingBox, sets it as the bounding box of
+// the feature and returns it. The geometry is left as it is.
+func (f *Feature) ComputeBoundingBox() BBox {
+	if f == nil {
+		return nil
+	}
+	b := &bboxBuilder{}
+	b.geometry(f.Geometry)
+	f.BoundingBox = b.bbox()
+	return f.BoundingBox
+}
+
+// ComputeBoundingBox computes the bounding box of the geometries of the
+// features, like Geometry.ComputeBoundingBox, sets it as the bounding box
+// of the collection and returns it. The features are left as they are.
+func (fc *FeatureCollection) ComputeBoundingBox() BBox {
+	b := &bboxBuilder{}
+	for _, f := range fc.Features {
+		if f != nil {
+			b.geometry(f.Geometry)
+		}
+	}
+	fc.BoundingBox = b.bbox()
+	return fc.BoundingBox
+}
+
+// A bboxBuilder accumulates the extent of parts of geometries, like lines
+// and polygons, to compute a bounding box that may cross the antimeridian.
+type bboxBuilder struct {
+	// longitudes are the extents in longitude of the parts
+	longitudes [][2]float64
+
+	min, max [3]float64
+	n        int
+	altitude bool
+}
+
+func (b *bboxBuilder) geometry(g *Geometry) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		b.part([][]float64{g.Point})
+	case GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			b.part([][]float64{p})
+		}
+	case GeometryLineString:
+		b.part(g.LineString)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			b.part(l)
+		}
+	case GeometryPolygon:
+		b.polygon(g.Polygon)
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			b.polygon(p)
+		}
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			b.geometry(m)
+		}
+	}
+}
+
+// polygon adds the rings of the polygon.
+func (b *bboxBuilder) polygon(polygon [][][]float64) {
+	for _, ring := range polygon {
+		b.part(ring)
+	}
+}
+
+// part adds positions whose longitude extent is kept.
+func (b *bboxBuilder) part(positions [][]float64) {
+	west, east := math.Inf(1), math.Inf(-1)
+	for _, p := range positions {
+		if len(p) < 2 {
+			continue
+		}
+
+		if b.n == 0 {
+			b.altitude = true
+			b.min = [3]float64{p[0], p[1], math.Inf(1)}
+			b.max = [3]float64{p[0], p[1], math.Inf(-1)}
+		}
+		b.n++
+		b.min[1] = math.Min(b.min[1], p[1])
+		b.max[1] = math.Max(b.max[1], p[1])
+		if len(p) > 2 {
+			b.min[2] = math.Min(b.min[2], p[2])
+			b.max[2] = math.Max(b.max[2], p[2])
+		} else {
+			b.altitude = false
+		}
+
+		west = math.Min(west, p[0])
+		east = math.Max(east, p[0])
+	}
+	if west <= east {
+		b.longitudes = append(b.longitudes, [2]float64{west, east})
+	}
+}
+
+// addLongitudes adds the longitude range from west going east to east,
+// crossing the antimeridian if west is larger.
+func (b *bboxBuilder) addLongitudes(west, east float64) {
+	if west <= east {
+		b.longitudes = append(b.longitudes, [2]float64{west, east})
+		return
+	}
+	b.longitudes = append(b.longitudes, [2]float64{west, 180}, [2]float64{-180, east})
+}
+
+// bbox returns the bounding box of the parts, nil if there are none.
+func (b *bboxBuilder) bbox() BBox {
+	if b.n == 0 {
+		return nil
+	}
+
+	west, east := b.longitudeExtent()
+	if b.altitude {
+		return BBox{west, b.min[1], b.min[2], east, b.max[1], b.max[2]}
+	}
+	return BBox{west, b.min[1], east, b.max[1]}
+}
+
+// longitudeExtent returns the narrowest longitude range covering the
+// parts, going east from west, which may cross the antimeridian.
+func (b *bboxBuilder) longitudeExtent() (float64, float64) {
+	intervals := append([][2]float64(nil), b.longitudes...)
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0] < intervals[j][0] })
+
+	// merge the overlapping intervals
+	merged := intervals[:1]
+	for _, in := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if in[0] <= last[1] {
+			last[1] = math.Max(last[1], in[1])
+			continue
+		}
+		merged = append(merged, in)
+	}
+
+	// leave out the widest gap between intervals, the one across the
+	// antimeridian by default
+	west, east := merged[0][0], merged[len(merged)-1][1]
+	gap := 360 - (east - west)
+	for i := 1; i < len(merged); i++ {
+		if g := merged[i][0] - merged[i-1][1]; g > gap {
+			gap = g
+			west, east = merged[i][0], merged[i-1][1]
+		}
+	}
+	return west, east
+}
@@ -0,0 +1,195 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewBBox(t *testing.T) {
+	cases := []struct {
+		name   string
+		values []float64
+		valid  bool
+	}{
+		{"2D", []float64{0, 0, 1, 1}, true},
+		{"3D", []float64{0, 0, -5, 1, 1, 5}, true},
+		{"antimeridian", []float64{170, 0, -170, 1}, true},
+		{"5 values", []float64{0, 0, 0, 1, 1}, false},
+		{"latitudes swapped", []float64{0, 1, 1, 0}, false},
+		{"altitudes swapped", []float64{0, 0, 5, 1, 1, -5}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := NewBBox(c.values...)
+			if c.valid != (err == nil) {
+				t.Fatalf("incorrect validation, got %v", err)
+			}
+			if c.valid && !reflect.DeepEqual([]float64(b), c.values) {
+				t.Errorf("incorrect bounding box, got %v", b)
+			}
+		})
+	}
+}
+
+func TestBBoxMinMax(t *testing.T) {
+	b := BBox{1, 2, 3, 4, 5, 6}
+	if b.Dimension() != 3 || !reflect.DeepEqual(b.Min(), []float64{1, 2, 3}) || !reflect.DeepEqual(b.Max(), []float64{4, 5, 6}) {
+		t.Errorf("incorrect corners, got %v %v", b.Min(), b.Max())
+	}
+
+	min := b.Min()
+	min = append(min, 0)
+	if b[3] != 4 {
+		t.Errorf("appending to the minimum should not change the maximum")
+	}
+
+	if BBox([]float64{1, 2, 3}).Min() != nil {
+		t.Errorf("should have no minimum when invalid")
+	}
+}
+
+func TestBBoxContains(t *testing.T) {
+	b := BBox{0, 0, 10, 10}
+	antimeridian := BBox{170, -10, -170, 10}
+	box3D := BBox{0, 0, 0, 10, 10, 100}
+
+	cases := []struct {
+		name     string
+		box      BBox
+		point    []float64
+		expected bool
+	}{
+		{"inside", b, []float64{5, 5}, true},
+		{"edge", b, []float64{10, 0}, true},
+		{"outside", b, []float64{11, 5}, false},
+		{"east of antimeridian", antimeridian, []float64{-175, 0}, true},
+		{"west of antimeridian", antimeridian, []float64{175, 0}, true},
+		{"outside antimeridian", antimeridian, []float64{0, 0}, false},
+		{"altitude inside", box3D, []float64{5, 5, 50}, true},
+		{"altitude outside", box3D, []float64{5, 5, 150}, false},
+		{"no altitude", box3D, []float64{5, 5}, true},
+		{"short position", b, []float64{5}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.box.Contains(c.point); got != c.expected {
+				t.Errorf("incorrect containment, got %v", got)
+			}
+		})
+	}
+}
+
+func TestBBoxIntersects(t *testing.T) {
+	b := BBox{0, 0, 10, 10}
+
+	cases := []struct {
+		name     string
+		other    BBox
+		expected bool
+	}{
+		{"overlap", BBox{5, 5, 15, 15}, true},
+		{"touch", BBox{10, 10, 20, 20}, true},
+		{"disjoint", BBox{11, 0, 20, 10}, false},
+		{"latitudes disjoint", BBox{0, 11, 10, 20}, false},
+		{"antimeridian", BBox{170, 0, 5, 10}, true},
+		{"antimeridian disjoint", BBox{170, 0, -5, 10}, false},
+		{"3D", BBox{0, 0, 0, 10, 10, 10}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := b.Intersects(c.other); got != c.expected {
+				t.Errorf("incorrect intersection, got %v", got)
+			}
+			if got := c.other.Intersects(b); got != c.expected {
+				t.Errorf("incorrect reverse intersection, got %v", got)
+			}
+		})
+	}
+
+	if !(BBox{170, 0, -170, 10}).Intersects(BBox{160, 0, -160, 10}) {
+		t.Errorf("boxes crossing the antimeridian should intersect")
+	}
+	if (BBox{0, 0, 0, 10, 10, 10}).Intersects(BBox{0, 0, 20, 10, 10, 30}) {
+		t.Errorf("altitudes should not intersect")
+	}
+}
+
+func TestBBoxExpand(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{-1, 2, 50}, {3, 4, -10}})
+
+	expanded := BBox{0, 0, 1, 1}.Expand(g)
+	if !reflect.DeepEqual(expanded, BBox{-1, 0, 3, 4}) {
+		t.Errorf("incorrect 2D bounding box, got %v", expanded)
+	}
+
+	expanded = BBox{0, 0, 0, 1, 1, 1}.Expand(g)
+	if !reflect.DeepEqual(expanded, BBox{-1, 0, -10, 3, 4, 50}) {
+		t.Errorf("incorrect 3D bounding box, got %v", expanded)
+	}
+
+	expanded = BBox(nil).Expand(g)
+	if !reflect.DeepEqual(expanded, BBox{-1, 2, 3, 4}) {
+		t.Errorf("incorrect new bounding box, got %v", expanded)
+	}
+	if BBox(nil).Expand(NewEmptyGeometry(GeometryPoint)) != nil {
+		t.Errorf("should have no bounding box without positions")
+	}
+}
+
+func TestGeometryComputeBoundingBox(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		expected BBox
+	}{
+		{"empty", NewEmptyGeometry(GeometryLineString), nil},
+		{"point", NewPointGeometry([]float64{1, 2}), BBox{1, 2, 1, 2}},
+		{"3D line", NewLineStringGeometry([][]float64{{0, 0, 5}, {2, 1, -5}}), BBox{0, 0, -5, 2, 1, 5}},
+		{"mixed dimensions", NewLineStringGeometry([][]float64{{0, 0, 5}, {2, 1}}), BBox{0, 0, 2, 1}},
+		{"wide line", NewLineStringGeometry([][]float64{{-170, 0}, {170, 10}}), BBox{-170, 0, 170, 10}},
+		{"points across the antimeridian", NewMultiPointGeometry([]float64{170, 0}, []float64{-175, 5}, []float64{-170, 10}), BBox{170, 0, -170, 10}},
+		{"polygons across the antimeridian", NewMultiPolygonGeometry(
+			[][][]float64{{{170, 0}, {180, 0}, {180, 10}, {170, 10}, {170, 0}}},
+			[][][]float64{{{-180, 0}, {-160, 0}, {-160, 10}, {-180, 10}, {-180, 0}}},
+		), BBox{170, 0, -160, 10}},
+		{"collection", NewCollectionGeometry(
+			NewPointGeometry([]float64{-10, 20}),
+			NewCollectionGeometry(NewLineStringGeometry([][]float64{{5, -5}, {15, 0}})),
+		), BBox{-10, -5, 15, 20}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bb := c.geometry.ComputeBoundingBox()
+			if !reflect.DeepEqual(bb, c.expected) {
+				t.Errorf("incorrect bounding box, got %v", bb)
+			}
+			if !reflect.DeepEqual(BBox(c.geometry.BoundingBox), c.expected) {
+				t.Errorf("should set the bounding box, got %v", c.geometry.BoundingBox)
+			}
+		})
+	}
+}
+
+func TestFeatureComputeBoundingBox(t *testing.T) {
+	f := NewFeature(NewMultiPointGeometry([]float64{179, 1}, []float64{-179, 2}))
+	if bb := f.ComputeBoundingBox(); !reflect.DeepEqual(bb, BBox{179, 1, -179, 2}) {
+		t.Errorf("incorrect bounding box, got %v", bb)
+	}
+	if f.Geometry.BoundingBox != nil {
+		t.Errorf("should leave the geometry as it is")
+	}
+	if bb := NewFeature(nil).ComputeBoundingBox(); bb != nil {
+		t.Errorf("should have no bounding box without geometry, got %v", bb)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	fc.AddFeature(NewPointFeature([]float64{170, -3}))
+	if bb := fc.ComputeBoundingBox(); !reflect.DeepEqual(bb, BBox{170, -3, -179, 2}) {
+		t.Errorf("incorrect collection bounding box, got %v", bb)
+	}
+}
@@ -1,9 +1,19 @@
 package geojson
 
 import (
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// bsonDocument returns the members as a BSON document, in order.
+func bsonDocument(members []member) bson.D {
+	doc := make(bson.D, len(members))
+	for i, m := range members {
+		doc[i] = bson.E{Key: m.key, Value: m.value}
+	}
+	return doc
+}
+
 func convertAToArray(obj *map[string]interface{}) {
 	for k, v := range *obj {
 		(*obj)[k] = arr(v)
@@ -0,0 +1,261 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// A BuildErrorKind classifies the problems found by the Build methods of
+// the geometry builders.
+type BuildErrorKind int
+
+// The kinds of build errors.
+const (
+	// BuildEmpty is a builder without lines or rings, or a hole added
+	// before any ring.
+	BuildEmpty BuildErrorKind = iota
+
+	// BuildInvalidPosition is a position with less than 2 coordinates,
+	// or with a coordinate that is not a finite number.
+	BuildInvalidPosition
+
+	// BuildTooFewPositions is a line with less than 2 positions, or a
+	// ring with less than 4.
+	BuildTooFewPositions
+
+	// BuildUnclosedRing is a ring whose last position is not its first.
+	BuildUnclosedRing
+
+	// BuildWrongOrientation is an exterior ring that is not
+	// counterclockwise or a hole that is not clockwise, as RFC 7946 asks.
+	BuildWrongOrientation
+
+	// BuildHoleOutside is a hole not inside the exterior ring.
+	BuildHoleOutside
+)
+
+// String returns a description of the kind.
+func (k BuildErrorKind) String() string {
+	switch k {
+	case BuildEmpty:
+		return "nothing to build"
+	case BuildInvalidPosition:
+		return "invalid position"
+	case BuildTooFewPositions:
+		return "too few positions"
+	case BuildUnclosedRing:
+		return "ring is not closed"
+	case BuildWrongOrientation:
+		return "wrong ring orientation"
+	case BuildHoleOutside:
+		return "hole outside of the exterior ring"
+	}
+	return fmt.Sprintf("build error %d", int(k))
+}
+
+// A BuildError is a problem found when building a geometry.
+type BuildError struct {
+	Kind BuildErrorKind
+
+	// Part is the index of the line or of the polygon, in the order they
+	// were started, and Ring the index of the ring in the polygon, 0 for
+	// the exterior ring and -1 for lines.
+	Part int
+	Ring int
+}
+
+// Error describes the build error.
+func (e *BuildError) Error() string {
+	if e.Ring < 0 {
+		return fmt.Sprintf("line %d: %s", e.Part, e.Kind)
+	}
+	return fmt.Sprintf("polygon %d ring %d: %s", e.Part, e.Ring, e.Kind)
+}
+
+// A LineStringBuilder builds a line string, or a multi line string when
+// more lines are started.
+//
+//	g, err := geojson.NewLineStringBuilder().
+//		Point(0, 0).Point(1, 1).
+//		Line().Point(2, 2).Point(3, 3).
+//		Build()
+type LineStringBuilder struct {
+	lines [][][]float64
+}
+
+// NewLineStringBuilder creates and initializes a line string builder,
+// with a first line started.
+func NewLineStringBuilder() *LineStringBuilder {
+	return &LineStringBuilder{lines: [][][]float64{nil}}
+}
+
+// Point adds a position with the coordinates to the current line.
+func (b *LineStringBuilder) Point(coordinates ...float64) *LineStringBuilder {
+	return b.Positions(append([]float64(nil), coordinates...))
+}
+
+// Positions adds the positions to the current line.
+func (b *LineStringBuilder) Positions(positions ...[]float64) *LineStringBuilder {
+	last := len(b.lines) - 1
+	b.lines[last] = append(b.lines[last], positions...)
+	return b
+}
+
+// Line starts a new line.
+func (b *LineStringBuilder) Line() *LineStringBuilder {
+	b.lines = append(b.lines, nil)
+	return b
+}
+
+// Build checks the lines and returns a line string, or a multi line string
+// if several lines were started. Lines started but left empty are ignored.
+func (b *LineStringBuilder) Build() (*Geometry, error) {
+	var lines [][][]float64
+	for i, l := range b.lines {
+		if len(l) == 0 {
+			continue
+		}
+		if len(l) < 2 {
+			return nil, &BuildError{Kind: BuildTooFewPositions, Part: i, Ring: -1}
+		}
+		if !validPositions(l) {
+			return nil, &BuildError{Kind: BuildInvalidPosition, Part: i, Ring: -1}
+		}
+		lines = append(lines, l)
+	}
+
+	switch len(lines) {
+	case 0:
+		return nil, &BuildError{Kind: BuildEmpty, Ring: -1}
+	case 1:
+		return NewLineStringGeometry(lines[0]), nil
+	}
+	return NewMultiLineStringGeometry(lines...), nil
+}
+
+// A PolygonBuilder builds a polygon, or a multi polygon when more exterior
+// rings are added. Holes belong to the last exterior ring.
+//
+//	g, err := geojson.NewPolygonBuilder().
+//		Ring([]float64{0, 0}, []float64{10, 0}, []float64{10, 10}, []float64{0, 10}, []float64{0, 0}).
+//		Hole([]float64{2, 2}, []float64{2, 4}, []float64{4, 4}, []float64{4, 2}, []float64{2, 2}).
+//		Build()
+type PolygonBuilder struct {
+	polygons  [][][][]float64
+	holeFirst bool
+	autoClose bool
+	rewind    bool
+}
+
+// NewPolygonBuilder creates and initializes a polygon builder.
+func NewPolygonBuilder() *PolygonBuilder {
+	return &PolygonBuilder{}
+}
+
+// Ring starts a new polygon with the exterior ring.
+func (b *PolygonBuilder) Ring(positions ...[]float64) *PolygonBuilder {
+	b.polygons = append(b.polygons, [][][]float64{append([][]float64(nil), positions...)})
+	return b
+}
+
+// Hole adds the hole to the last polygon.
+func (b *PolygonBuilder) Hole(positions ...[]float64) *PolygonBuilder {
+	if len(b.polygons) == 0 {
+		b.holeFirst = true
+		return b
+	}
+	last := len(b.polygons) - 1
+	b.polygons[last] = append(b.polygons[last], append([][]float64(nil), positions...))
+	return b
+}
+
+// AutoClose makes Build close the rings whose last position is not their
+// first, instead of failing.
+func (b *PolygonBuilder) AutoClose() *PolygonBuilder {
+	b.autoClose = true
+	return b
+}
+
+// Rewind makes Build reverse the rings with the wrong orientation, instead
+// of failing.
+func (b *PolygonBuilder) Rewind() *PolygonBuilder {
+	b.rewind = true
+	return b
+}
+
+// Build checks the rings and returns a polygon, or a multi polygon if
+// several exterior rings were added. Rings must be closed, have at least 4
+// positions and follow the right hand rule of RFC 7946: exterior rings
+// counterclockwise and holes clockwise. Holes must start inside their
+// exterior ring.
+func (b *PolygonBuilder) Build() (*Geometry, error) {
+	if b.holeFirst || len(b.polygons) == 0 {
+		return nil, &BuildError{Kind: BuildEmpty}
+	}
+
+	polygons := make([][][][]float64, len(b.polygons))
+	for i, p := range b.polygons {
+		polygons[i] = make([][][]float64, len(p))
+		for j, r := range p {
+			ring, kind, ok := b.ring(r, j == 0)
+			if !ok {
+				return nil, &BuildError{Kind: kind, Part: i, Ring: j}
+			}
+			polygons[i][j] = ring
+		}
+
+		for j, h := range polygons[i][1:] {
+			if PointInPolygonWinding(h[0], polygons[i][:1]) == Exterior {
+				return nil, &BuildError{Kind: BuildHoleOutside, Part: i, Ring: j + 1}
+			}
+		}
+	}
+
+	if len(polygons) == 1 {
+		return NewPolygonGeometry(polygons[0]), nil
+	}
+	return NewMultiPolygonGeometry(polygons...), nil
+}
+
+// ring checks the ring, closing or reversing a copy of it if the builder
+// is set to.
+func (b *PolygonBuilder) ring(ring [][]float64, exterior bool) ([][]float64, BuildErrorKind, bool) {
+	if !validPositions(ring) {
+		return nil, BuildInvalidPosition, false
+	}
+	if len(ring) > 0 && !samePosition(ring[0], ring[len(ring)-1]) {
+		if !b.autoClose {
+			return nil, BuildUnclosedRing, false
+		}
+		ring = append(ring[:len(ring):len(ring)], ring[0])
+	}
+	if len(ring) < 4 {
+		return nil, BuildTooFewPositions, false
+	}
+
+	if (ringArea2D(ring) > 0) != exterior {
+		if !b.rewind {
+			return nil, BuildWrongOrientation, false
+		}
+		ring = append([][]float64(nil), ring...)
+		reverseRing(ring)
+	}
+
+	return ring, 0, true
+}
+
+// validPositions tells if all the positions have at least 2 coordinates,
+// all finite.
+func validPositions(positions [][]float64) bool {
+	for _, p := range positions {
+		if len(p) < 2 {
+			return false
+		}
+		for _, c := range p {
+			if math.IsNaN(c) || math.IsInf(c, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package geojson
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestLineStringBuilder(t *testing.T) {
+	g, err := NewLineStringBuilder().Point(0, 0).Point(1, 1, 5).Build()
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+	if !g.Equal(NewLineStringGeometry([][]float64{{0, 0}, {1, 1, 5}})) {
+		t.Errorf("incorrect line, got %v", g.LineString)
+	}
+
+	g, err = NewLineStringBuilder().Positions([]float64{0, 0}, []float64{1, 1}).Line().Line().Point(2, 2).Point(3, 3).Build()
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+	if !g.IsMultiLineString() || len(g.MultiLineString) != 2 {
+		t.Errorf("should build a multi line string of 2 lines, got %v", g)
+	}
+
+	cases := []struct {
+		builder *LineStringBuilder
+		kind    BuildErrorKind
+		part    int
+	}{
+		{NewLineStringBuilder(), BuildEmpty, 0},
+		{NewLineStringBuilder().Point(0, 0).Point(1, 1).Line().Point(2, 2), BuildTooFewPositions, 1},
+		{NewLineStringBuilder().Point(0, 0).Point(1), BuildInvalidPosition, 0},
+		{NewLineStringBuilder().Point(0, 0).Point(1, math.NaN()), BuildInvalidPosition, 0},
+	}
+	for _, tc := range cases {
+		_, err := tc.builder.Build()
+		var buildErr *BuildError
+		if !errors.As(err, &buildErr) {
+			t.Errorf("should fail with a build error, got %v", err)
+			continue
+		}
+		if buildErr.Kind != tc.kind || buildErr.Part != tc.part || buildErr.Ring != -1 {
+			t.Errorf("incorrect error, expected %s in line %d, got %v", tc.kind, tc.part, buildErr)
+		}
+	}
+}
+
+func TestPolygonBuilder(t *testing.T) {
+	exterior := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := [][]float64{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}
+
+	g, err := NewPolygonBuilder().Ring(exterior...).Hole(hole...).Build()
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+	if !g.Equal(NewPolygonGeometry([][][]float64{exterior, hole})) {
+		t.Errorf("incorrect polygon, got %v", g.Polygon)
+	}
+
+	g, err = NewPolygonBuilder().Ring(exterior...).Ring(hole[4], hole[3], hole[2], hole[1], hole[0]).Build()
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+	if !g.IsMultiPolygon() || len(g.MultiPolygon) != 2 {
+		t.Errorf("should build a multi polygon of 2 polygons, got %v", g)
+	}
+
+	cases := []struct {
+		name    string
+		builder *PolygonBuilder
+		kind    BuildErrorKind
+		part    int
+		ring    int
+	}{
+		{"empty", NewPolygonBuilder(), BuildEmpty, 0, 0},
+		{"hole first", NewPolygonBuilder().Hole(hole...).Ring(exterior...), BuildEmpty, 0, 0},
+		{"unclosed", NewPolygonBuilder().Ring(exterior[:4]...), BuildUnclosedRing, 0, 0},
+		{"too few", NewPolygonBuilder().Ring([]float64{0, 0}, []float64{1, 1}, []float64{0, 0}), BuildTooFewPositions, 0, 0},
+		{"invalid", NewPolygonBuilder().Ring([]float64{0, 0}, []float64{1}, []float64{1, 1}, []float64{0, 0}), BuildInvalidPosition, 0, 0},
+		{"clockwise exterior", NewPolygonBuilder().Ring(hole...), BuildWrongOrientation, 0, 0},
+		{"counterclockwise hole", NewPolygonBuilder().Ring(exterior...).Ring(exterior...).Hole(exterior...), BuildWrongOrientation, 1, 1},
+		{"hole outside", NewPolygonBuilder().Ring(exterior...).Hole([]float64{20, 20}, []float64{20, 22}, []float64{22, 22}, []float64{20, 20}), BuildHoleOutside, 0, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.builder.Build()
+			var buildErr *BuildError
+			if !errors.As(err, &buildErr) {
+				t.Fatalf("should fail with a build error, but got %v", err)
+			}
+			if buildErr.Kind != tc.kind || buildErr.Part != tc.part || buildErr.Ring != tc.ring {
+				t.Errorf("incorrect error, expected %s in polygon %d ring %d, got %v", tc.kind, tc.part, tc.ring, buildErr)
+			}
+		})
+	}
+}
+
+func TestPolygonBuilderRepairs(t *testing.T) {
+	unclosed := [][]float64{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+	g, err := NewPolygonBuilder().Ring(unclosed...).AutoClose().Rewind().Build()
+	if err != nil {
+		t.Fatalf("should repair the ring, but got %v", err)
+	}
+	expected := NewPolygonGeometry([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	if !g.Equal(expected) {
+		t.Errorf("incorrect repaired polygon, got %v", g.Polygon)
+	}
+	if len(unclosed) != 4 || unclosed[1][1] != 10 {
+		t.Errorf("should not modify the positions given, got %v", unclosed)
+	}
+
+	err = (&BuildError{Kind: BuildUnclosedRing, Part: 1, Ring: 2})
+	if err.Error() != "polygon 1 ring 2: ring is not closed" {
+		t.Errorf("incorrect message, got %s", err)
+	}
+}
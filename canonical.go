@@ -0,0 +1,133 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// canonical returns the geometry, or a copy of it and of its members
+// sharing the coordinates with canonical foreign members, in canonical
+// mode.
+func (o MarshalOptions) canonical(g *Geometry) *Geometry {
+	if !o.Canonical || g == nil {
+		return g
+	}
+
+	c := *g
+	c.ForeignMembers = o.canonicalMembers(g.ForeignMembers)
+	if g.Geometries != nil {
+		c.Geometries = make([]*Geometry, len(g.Geometries))
+		for i, m := range g.Geometries {
+			c.Geometries[i] = o.canonical(m)
+		}
+	}
+	return &c
+}
+
+// canonicalFeature returns the feature, or a copy of it and of its
+// geometry with canonical foreign members, in canonical mode.
+func (o MarshalOptions) canonicalFeature(f *Feature) *Feature {
+	if !o.Canonical || f == nil {
+		return f
+	}
+
+	c := *f
+	c.ForeignMembers = o.canonicalMembers(f.ForeignMembers)
+	c.Geometry = o.canonical(f.Geometry)
+	return &c
+}
+
+// canonicalMembers returns the foreign members, or a copy of them with the
+// members of their objects sorted by key, in canonical mode. Their numbers
+// are rewritten with the others, see canonicalNumber.
+func (o MarshalOptions) canonicalMembers(members map[string]json.RawMessage) map[string]json.RawMessage {
+	if !o.Canonical || len(members) == 0 {
+		return members
+	}
+
+	c := make(map[string]json.RawMessage, len(members))
+	for key, raw := range members {
+		c[key] = raw
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			// left as is, for the encoding to report it
+			continue
+		}
+		if data, err := json.Marshal(v); err == nil {
+			c[key] = data
+		}
+	}
+	return c
+}
+
+// propertyOrder returns the properties written first, none in canonical
+// mode.
+func (o MarshalOptions) propertyOrder() []string {
+	if o.Canonical {
+		return nil
+	}
+	return o.PropertyOrder
+}
+
+// canonicalNumber returns the number as the shortest plain decimal reading
+// back as the same float64, 0 for negative zero, nil for integers, which
+// are kept as they are so large identifiers do not lose digits.
+func canonicalNumber(f float64, number []byte) []byte {
+	if f == 0 && number[0] == '-' {
+		return []byte("0")
+	}
+	if bytes.IndexAny(number, ".eE") < 0 {
+		return nil
+	}
+	return []byte(NewDecimal(f))
+}
+
+// canonicalNumbers rewrites the numbers of the JSON, see canonicalNumber,
+// in canonical mode.
+func (o MarshalOptions) canonicalNumbers(data []byte, err error) ([]byte, error) {
+	if err != nil || !o.Canonical {
+		return data, err
+	}
+	return rewriteNumbers(data, canonicalNumber), nil
+}
+
+// rewriteNumbers replaces the numbers of the JSON, which is valid, by the
+// result of rewrite, unless nil.
+func rewriteNumbers(data []byte, rewrite func(f float64, number []byte) []byte) []byte {
+	var result []byte
+	last := 0
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			for i++; data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case c == '-' || c >= '0' && c <= '9':
+			start := i
+			for i+1 < len(data) && bytes.IndexByte([]byte("+-.eE0123456789"), data[i+1]) >= 0 {
+				i++
+			}
+			number := data[start : i+1]
+			f, err := strconv.ParseFloat(string(number), 64)
+			if err != nil {
+				continue
+			}
+			rewritten := rewrite(f, number)
+			if rewritten == nil {
+				continue
+			}
+			result = append(append(result, data[last:start]...), rewritten...)
+			last = i + 1
+		}
+	}
+
+	if result == nil {
+		return data
+	}
+	return append(result, data[last:]...)
+}
@@ -0,0 +1,64 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalOptionsCanonical(t *testing.T) {
+	a := NewPointFeature([]float64{1e-7, -0.0})
+	a.ID = 1.0
+	a.Properties["b"] = 2.50
+	a.Properties["a"] = map[string]interface{}{"y": 1, "x": 1e21}
+	a.ForeignMembers = map[string]json.RawMessage{"z": json.RawMessage(`{"b": 1.50, "a": [ 2.0 ]}`), "title": json.RawMessage(`"A"`)}
+
+	b := NewPointFeature([]float64{0.0000001, 0})
+	b.ID = 1
+	b.Properties["a"] = map[string]interface{}{"x": 1e21, "y": 1.0}
+	b.Properties["b"] = 2.5
+	b.ForeignMembers = map[string]json.RawMessage{"title": json.RawMessage(`"A"`), "z": json.RawMessage(`{"a":[2],"b":1.5}`)}
+
+	o := MarshalOptions{Canonical: true, PropertyOrder: []string{"b"}}
+	da, err := o.MarshalFeature(a)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	db, err := o.MarshalFeature(b)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	expected := `{"id":1,"type":"Feature","geometry":{"type":"Point","coordinates":[0.0000001,0]},` +
+		`"properties":{"a":{"x":1000000000000000000000,"y":1},"b":2.5},"title":"A","z":{"a":[2],"b":1.5}}`
+	if string(da) != expected {
+		t.Errorf("incorrect canonical JSON, got %s", da)
+	}
+	if string(da) != string(db) {
+		t.Errorf("should write identical documents alike, got %s and %s", da, db)
+	}
+	if string(a.ForeignMembers["z"]) != `{"b": 1.50, "a": [ 2.0 ]}` {
+		t.Errorf("should not change the feature")
+	}
+
+	fa, fb := NewFeatureCollection(), NewFeatureCollection()
+	fa.AddFeature(a)
+	fb.AddFeature(b)
+	fa.ForeignMembers = map[string]json.RawMessage{"meta": json.RawMessage(`{"v":1,"n":-0.0}`)}
+	fb.ForeignMembers = map[string]json.RawMessage{"meta": json.RawMessage(`{"n":0,"v":1}`)}
+	o.Workers = 4
+	ca, err := o.MarshalFeatureCollection(fa)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	cb, _ := o.MarshalFeatureCollection(fb)
+	if string(ca) != string(cb) {
+		t.Errorf("should write identical collections alike, got %s and %s", ca, cb)
+	}
+
+	big := NewPointFeature([]float64{1, 2})
+	big.ID = json.Number("9007199254740993")
+	data, _ := o.MarshalFeature(big)
+	if string(data[:24]) != `{"id":9007199254740993,"` {
+		t.Errorf("should keep integers as they are, got %s", data)
+	}
+}
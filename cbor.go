@@ -0,0 +1,425 @@
+package geojson
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// The major types of CBOR data items, RFC 8949.
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborBytes    = 2 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborTag      = 6 << 5
+	cborSimple   = 7 << 5
+)
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// MarshalCBOR encodes the geometry as a CBOR map with the members of its
+// GeoJSON object, so it can be embedded in CBOR payloads like COSE
+// messages. The encoding is deterministic: map keys are sorted and floats
+// use the shortest of single or double precision keeping their value.
+// This fulfills the cbor.Marshaler interface.
+func (g Geometry) MarshalCBOR() ([]byte, error) {
+	return appendValue(cborFormat{}, nil, &g, 0)
+}
+
+// UnmarshalCBOR decodes the CBOR map into a GeoJSON geometry.
+// This fulfills the cbor.Unmarshaler interface.
+func (g *Geometry) UnmarshalCBOR(data []byte) error {
+	object, err := unmarshalCBORObject(data)
+	if err != nil {
+		return err
+	}
+	return decodeGeometry(g, object, false)
+}
+
+// MarshalCBOR encodes the feature as a CBOR map with the members of its
+// GeoJSON object, the geometry encoded like Geometry.MarshalCBOR does.
+// Properties can hold any value encoding/json can encode; values with no
+// CBOR counterpart, like structs, are encoded as their JSON values.
+// This fulfills the cbor.Marshaler interface.
+func (f Feature) MarshalCBOR() ([]byte, error) {
+	members, err := f.members()
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(cborFormat{}, nil, members, 0)
+}
+
+// UnmarshalCBOR decodes the CBOR map into a GeoJSON feature. Integers
+// are decoded as int64, like BSON ones, floats as float64 and byte
+// strings as []byte. Tags are ignored, their content decoded.
+// This fulfills the cbor.Unmarshaler interface.
+func (f *Feature) UnmarshalCBOR(data []byte) error {
+	object, err := unmarshalCBORObject(data)
+	if err != nil {
+		return err
+	}
+	return decodeFeature(f, object, false)
+}
+
+// cborFormat is the binaryFormat of CBOR.
+type cborFormat struct{}
+
+// appendHead appends the head of a data item with the major type and the
+// argument, in its shortest form.
+func (cborFormat) appendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, major|25, 0, 0)
+		binary.BigEndian.PutUint16(buf[len(buf)-2:], uint16(n))
+		return buf
+	case n <= math.MaxUint32:
+		buf = append(buf, major|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], uint32(n))
+		return buf
+	}
+	buf = append(buf, major|27, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], n)
+	return buf
+}
+
+func (cborFormat) appendNull(buf []byte) []byte {
+	return append(buf, cborSimple|22)
+}
+
+func (cborFormat) appendBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, cborSimple|21)
+	}
+	return append(buf, cborSimple|20)
+}
+
+func (c cborFormat) appendInt(buf []byte, i int64) []byte {
+	if i < 0 {
+		return c.appendHead(buf, cborNegative, uint64(-(i + 1)))
+	}
+	return c.appendHead(buf, cborUnsigned, uint64(i))
+}
+
+func (c cborFormat) appendUint(buf []byte, u uint64) []byte {
+	return c.appendHead(buf, cborUnsigned, u)
+}
+
+// appendFloat appends the float in single precision when it keeps its
+// value, double precision else.
+func (cborFormat) appendFloat(buf []byte, x float64, single bool) []byte {
+	if f := float32(x); float64(f) == x || math.IsNaN(x) {
+		buf = append(buf, cborSimple|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], math.Float32bits(f))
+		return buf
+	}
+	buf = append(buf, cborSimple|27, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], math.Float64bits(x))
+	return buf
+}
+
+func (c cborFormat) appendText(buf []byte, s string) []byte {
+	buf = c.appendHead(buf, cborText, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func (c cborFormat) appendBytes(buf []byte, b []byte) []byte {
+	buf = c.appendHead(buf, cborBytes, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func (c cborFormat) appendArrayHead(buf []byte, n int) []byte {
+	return c.appendHead(buf, cborArray, uint64(n))
+}
+
+func (c cborFormat) appendMapHead(buf []byte, n int) []byte {
+	return c.appendHead(buf, cborMap, uint64(n))
+}
+
+// sortMembers orders the members like the core deterministic encoding of
+// RFC 8949: shorter keys first, then bytewise.
+func (cborFormat) sortMembers(members []member) {
+	sort.SliceStable(members, func(i, j int) bool {
+		if len(members[i].key) != len(members[j].key) {
+			return len(members[i].key) < len(members[j].key)
+		}
+		return members[i].key < members[j].key
+	})
+}
+
+// unmarshalCBORObject decodes the data, a single CBOR map.
+func unmarshalCBORObject(data []byte) (map[string]interface{}, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("cbor: %d bytes after the data item", len(data)-d.pos)
+	}
+
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cbor: expected a map, got %T", v)
+	}
+	return object, nil
+}
+
+// A cborDecoder decodes CBOR data items into the values encoding/json
+// decodes into an interface{}, integers decoded as int64.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads the head of a data item, returning its major type, additional
+// information and argument. The argument is 0 for indefinite lengths.
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, errCBORTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info := b&0xe0, b&0x1f
+
+	size := 0
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31 && major >= cborBytes && major <= cborMap || info == 31 && major == cborSimple:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: invalid additional information %d at offset %d", info, d.pos-1)
+	}
+
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, errCBORTruncated
+	}
+	var n uint64
+	for _, c := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.pos += size
+	return major, info, n, nil
+}
+
+// isBreak returns true, and skips it, if the next byte is the break
+// ending an indefinite length item.
+func (d *cborDecoder) isBreak() (bool, error) {
+	if d.pos >= len(d.data) {
+		return false, errCBORTruncated
+	}
+	if d.data[d.pos] == cborSimple|31 {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// length checks that n items of at least one byte each can follow.
+func (d *cborDecoder) length(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, errCBORTruncated
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) value(depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("cbor: data nested too deeply")
+	}
+
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		if n > math.MaxInt64 {
+			return float64(n), nil
+		}
+		return int64(n), nil
+	case cborNegative:
+		if n > math.MaxInt64 {
+			return -1 - float64(n), nil
+		}
+		return -1 - int64(n), nil
+	case cborBytes, cborText:
+		b, err := d.chunks(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborBytes {
+			return b, nil
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("cbor: invalid UTF-8 text")
+		}
+		return string(b), nil
+	case cborArray:
+		return d.array(info, n, depth)
+	case cborMap:
+		return d.object(info, n, depth)
+	case cborTag:
+		return d.value(depth + 1)
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfToFloat64(uint16(n)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", n)
+}
+
+// chunks reads the content of a byte or text string, joining the chunks
+// of an indefinite length one.
+func (d *cborDecoder) chunks(major, info byte, n uint64) ([]byte, error) {
+	if info != 31 {
+		size, err := d.length(n)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size)
+		copy(b, d.data[d.pos:d.pos+size])
+		d.pos += size
+		return b, nil
+	}
+
+	var b []byte
+	for {
+		end, err := d.isBreak()
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return b, nil
+		}
+		m, i, size, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || i == 31 {
+			return nil, errors.New("cbor: invalid chunk of an indefinite length string")
+		}
+		chunk, err := d.chunks(m, i, size)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+func (d *cborDecoder) array(info byte, n uint64, depth int) ([]interface{}, error) {
+	var values []interface{}
+	if info != 31 {
+		size, err := d.length(n)
+		if err != nil {
+			return nil, err
+		}
+		values = make([]interface{}, 0, size)
+	} else {
+		values = []interface{}{}
+	}
+
+	for i := uint64(0); info == 31 || i < n; i++ {
+		if info == 31 {
+			end, err := d.isBreak()
+			if err != nil {
+				return nil, err
+			}
+			if end {
+				break
+			}
+		}
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *cborDecoder) object(info byte, n uint64, depth int) (map[string]interface{}, error) {
+	if info != 31 {
+		if _, err := d.length(2 * n); err != nil || n > math.MaxInt32 {
+			return nil, errCBORTruncated
+		}
+	}
+
+	object := make(map[string]interface{})
+	for i := uint64(0); info == 31 || i < n; i++ {
+		if info == 31 {
+			end, err := d.isBreak()
+			if err != nil {
+				return nil, err
+			}
+			if end {
+				break
+			}
+		}
+		k, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("cbor: map key must be a text string, got %T", k)
+		}
+		if object[key], err = d.value(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// halfToFloat64 converts the IEEE 754 half precision float.
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var x float64
+	switch exp {
+	case 0:
+		x = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		x = math.Inf(1)
+	default:
+		x = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,164 @@
+package geojson
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGeometryMarshalCBOR(t *testing.T) {
+	data, err := NewPointGeometry([]float64{1, 2.1}).MarshalCBOR()
+	if err != nil {
+		t.Fatalf("should marshal point, but got %v", err)
+	}
+
+	expected := "a2" + "6474797065" + "65506f696e74" + "6b636f6f7264696e61746573" + "82" + "fa3f800000" + "fb4000cccccccccccd"
+	if hex.EncodeToString(data) != expected {
+		t.Errorf("incorrect encoding, expected %s, got %x", expected, data)
+	}
+}
+
+func TestGeometryCBORRoundTrip(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1.5}, {0, 0}}}),
+		NewMultiLineStringGeometry([][]float64{{-1e300, 2}, {3, 4, 5}}),
+		NewPointGeometry(nil),
+	)
+	g.BoundingBox = []float64{-1e300, 0, 3, 4}
+	g.ForeignMembers = map[string]json.RawMessage{"title": json.RawMessage(`"shapes"`)}
+
+	data, err := g.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("should marshal collection, but got %v", err)
+	}
+
+	var decoded Geometry
+	if err := decoded.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("should unmarshal collection, but got %v", err)
+	}
+	if !decoded.Equal(g) || !reflect.DeepEqual(decoded.BoundingBox, g.BoundingBox) {
+		t.Errorf("incorrect collection after round trip, got %v", decoded)
+	}
+	if string(decoded.ForeignMembers["title"]) != `"shapes"` {
+		t.Errorf("incorrect foreign members, got %v", decoded.ForeignMembers)
+	}
+}
+
+func TestFeatureCBORRoundTrip(t *testing.T) {
+	f := NewPointFeature([]float64{4.35, 50.85})
+	f.ID = int64(-42)
+	f.SetProperty("name", "Brussels")
+	f.SetProperty("population", 1200000)
+	f.SetProperty("density", 7.5)
+	f.SetProperty("capital", true)
+	f.SetProperty("tags", []string{"city", "region"})
+	f.SetProperty("none", nil)
+	f.SetProperty("raw", []byte{1, 2})
+	f.SetProperty("nested", map[string]interface{}{"a": json.Number("3")})
+	f.SetProperty("struct", struct {
+		Code string `json:"code"`
+	}{"BE"})
+
+	data, err := f.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("should marshal feature, but got %v", err)
+	}
+
+	var decoded Feature
+	if err := decoded.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("should unmarshal feature, but got %v", err)
+	}
+	if decoded.ID != int64(-42) || decoded.Type != "Feature" || !decoded.Geometry.Equal(f.Geometry) {
+		t.Errorf("incorrect feature after round trip, got %v", decoded)
+	}
+
+	expected := map[string]interface{}{
+		"name":       "Brussels",
+		"population": int64(1200000),
+		"density":    7.5,
+		"capital":    true,
+		"tags":       []interface{}{"city", "region"},
+		"none":       nil,
+		"raw":        []byte{1, 2},
+		"nested":     map[string]interface{}{"a": int64(3)},
+		"struct":     map[string]interface{}{"code": "BE"},
+	}
+	if !reflect.DeepEqual(decoded.Properties, expected) {
+		t.Errorf("incorrect properties, got %#v", decoded.Properties)
+	}
+
+	data, err = NewFeature(nil).MarshalCBOR()
+	if err != nil {
+		t.Fatalf("should marshal feature without geometry, but got %v", err)
+	}
+	decoded = Feature{}
+	if err := decoded.UnmarshalCBOR(data); err != nil || decoded.Geometry != nil || decoded.Properties != nil {
+		t.Errorf("incorrect feature without geometry, got %v, %v", decoded, err)
+	}
+}
+
+func TestUnmarshalCBOR(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected []float64
+	}{
+		{
+			name: "integers",
+			// {"type": "Point", "coordinates": [1, -500]}
+			data:     "a2" + "6474797065" + "65506f696e74" + "6b636f6f7264696e61746573" + "82" + "01" + "3901f3",
+			expected: []float64{1, -500},
+		},
+		{
+			name: "indefinite lengths and half floats",
+			// {_ "type": (_ "Po", "int"), "coordinates": [_ 1.5, -0.0]}
+			data:     "bf" + "6474797065" + "7f" + "62506f" + "63696e74" + "ff" + "6b636f6f7264696e61746573" + "9f" + "f93e00" + "f98000" + "ff" + "ff",
+			expected: []float64{1.5, 0},
+		},
+		{
+			name: "tagged",
+			// 55799({"type": "Point", "coordinates": [2, 3]})
+			data:     "d9d9f7" + "a2" + "6474797065" + "65506f696e74" + "6b636f6f7264696e61746573" + "82" + "02" + "03",
+			expected: []float64{2, 3},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.data)
+			var g Geometry
+			if err := g.UnmarshalCBOR(data); err != nil {
+				t.Fatalf("should unmarshal, but got %v", err)
+			}
+			if !g.IsPoint() || !reflect.DeepEqual(g.Point, tc.expected) {
+				t.Errorf("incorrect point, expected %v, got %v", tc.expected, g)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCBORErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"truncated":      "a26474797065",
+		"not a map":      "8101",
+		"integer key":    "a10101",
+		"trailing bytes": "a000",
+		"huge array":     "a1" + "6474797065" + "9b7fffffffffffffff",
+		"bad info":       "a1" + "6474797065" + "1c",
+		"invalid text":   "a1" + "6474797065" + "61ff",
+		"deep":           "a1" + "6474797065" + strings.Repeat("81", maxBinaryDepth+1) + "00",
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			b, _ := hex.DecodeString(data)
+			var f Feature
+			if err := f.UnmarshalCBOR(b); err == nil {
+				t.Errorf("should fail to unmarshal %s", data)
+			}
+		})
+	}
+}
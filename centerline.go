@@ -0,0 +1,301 @@
+package geojson
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+)
+
+// centerlineMaxPoints bounds the number of boundary positions the medial
+// axis of a polygon is approximated from.
+const centerlineMaxPoints = 4000
+
+// Centerline returns an approximate centerline of the polygon, or of each
+// polygon of the multi-polygon, like the axis of a river or a road
+// digitized as a polygon, to place labels along. A polygon gets a line
+// string and a multi-polygon a multi-line string.
+//
+// The medial axis of the polygon is approximated by the edges of the
+// Voronoi diagram of its densified boundary that are inside of it, and the
+// centerline is its longest path, leaving out the branches to the corners
+// and the tributaries. Distances are planar, in degrees of longitude scaled
+// to the mean latitude of the polygon.
+func Centerline(polygon *Geometry) (*Geometry, error) {
+	if polygon == nil {
+		return nil, errors.New("centerline needs a polygon")
+	}
+
+	switch polygon.Type {
+	case GeometryPolygon:
+		line := centerline(polygon.Polygon)
+		if line == nil {
+			return nil, errors.New("centerline needs a polygon with an area")
+		}
+		return NewLineStringGeometry(line), nil
+	case GeometryMultiPolygon:
+		var lines [][][]float64
+		for _, p := range polygon.MultiPolygon {
+			if line := centerline(p); line != nil {
+				lines = append(lines, line)
+			}
+		}
+		if lines == nil {
+			return nil, errors.New("centerline needs a polygon with an area")
+		}
+		return NewMultiLineStringGeometry(lines...), nil
+	}
+	return nil, errors.New("centerline needs a polygon")
+}
+
+// centerline returns the centerline of the polygon, nil if it has no area.
+func centerline(polygon [][][]float64) [][]float64 {
+	if len(polygon) == 0 || len(polygon[0]) < 4 {
+		return nil
+	}
+
+	// project to a plane where distances are about the same in all directions
+	latitude, n := 0.0, 0
+	for _, p := range polygon[0] {
+		if len(p) >= 2 {
+			latitude += p[1]
+			n++
+		}
+	}
+	scale := math.Cos(latitude / float64(n) * math.Pi / 180)
+	projected := make([][][]float64, len(polygon))
+	area, perimeter := 0.0, 0.0
+	for i, ring := range polygon {
+		for _, p := range ring {
+			if len(p) >= 2 {
+				projected[i] = append(projected[i], []float64{p[0] * scale, p[1]})
+			}
+		}
+		a := math.Abs(ringArea2D(projected[i]))
+		if i > 0 {
+			a = -a
+		}
+		area += a
+		for j := 1; j < len(projected[i]); j++ {
+			perimeter += math.Hypot(projected[i][j][0]-projected[i][j-1][0], projected[i][j][1]-projected[i][j-1][1])
+		}
+	}
+	if !(area > 0) {
+		return nil
+	}
+
+	// about half the width of a long and narrow polygon
+	spacing := math.Max(area/perimeter, perimeter/centerlineMaxPoints)
+	t := newDelaunay(densifyRings(projected, spacing))
+
+	// the Voronoi vertices are the circumcenters of the triangles, joined
+	// when their triangles share an edge
+	inside := make([]bool, len(t.triangles))
+	for i, tri := range t.triangles {
+		inside[i] = !t.super(tri) && !math.IsInf(tri.r2, 1) &&
+			PointInPolygonWinding([]float64{tri.x, tri.y}, projected) == Interior
+	}
+	edges := make(map[[2]int][]int)
+	for i, tri := range t.triangles {
+		if !inside[i] {
+			continue
+		}
+		for _, e := range [][2]int{{tri.a, tri.b}, {tri.b, tri.c}, {tri.c, tri.a}} {
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			edges[e] = append(edges[e], i)
+		}
+	}
+	adjacent := make(map[int][]int)
+	for _, e := range edges {
+		if len(e) == 2 {
+			adjacent[e[0]] = append(adjacent[e[0]], e[1])
+			adjacent[e[1]] = append(adjacent[e[1]], e[0])
+		}
+	}
+
+	distance := func(i, j int) float64 {
+		return math.Hypot(t.triangles[i].x-t.triangles[j].x, t.triangles[i].y-t.triangles[j].y)
+	}
+
+	// the longest path of each tree of the medial axis runs between the
+	// nodes the farthest from any of its nodes and from that node
+	var best []int
+	bestLength := -1.0
+	visited := make([]bool, len(t.triangles))
+	for start := range t.triangles {
+		if !inside[start] || visited[start] {
+			continue
+		}
+		from, _, _ := farthestNode(start, adjacent, distance, visited)
+		to, length, previous := farthestNode(from, adjacent, distance, nil)
+		if length > bestLength {
+			best, bestLength = nil, length
+			for i := to; i != -1; i = previous[i] {
+				best = append(best, i)
+			}
+		}
+	}
+
+	var line [][]float64
+	for _, i := range best {
+		p := []float64{t.triangles[i].x / scale, t.triangles[i].y}
+		if len(line) == 0 || !samePosition(line[len(line)-1], p) {
+			line = append(line, p)
+		}
+	}
+	if len(line) < 2 {
+		return nil
+	}
+	return line
+}
+
+// farthestNode returns the node of the graph the farthest from the start,
+// with its distance and the previous node of each reached node on its
+// shortest path from the start, -1 for the start. Reached nodes are marked
+// as visited.
+func farthestNode(start int, adjacent map[int][]int, distance func(i, j int) float64, visited []bool) (int, float64, map[int]int) {
+	cost := map[int]float64{start: 0}
+	previous := map[int]int{start: -1}
+	farthest := start
+	queue := &graphQueue{{node: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(graphQueueItem)
+		if item.priority > cost[item.node] {
+			continue
+		}
+		if visited != nil {
+			visited[item.node] = true
+		}
+		if item.priority > cost[farthest] {
+			farthest = item.node
+		}
+		for _, next := range adjacent[item.node] {
+			c := item.priority + distance(item.node, next)
+			if known, ok := cost[next]; !ok || c < known {
+				cost[next] = c
+				previous[next] = item.node
+				heap.Push(queue, graphQueueItem{node: next, priority: c})
+			}
+		}
+	}
+	return farthest, cost[farthest], previous
+}
+
+// densifyRings returns the distinct positions of the rings, with positions
+// added along their edges so they are at most spacing apart.
+func densifyRings(rings [][][]float64, spacing float64) [][2]float64 {
+	var points [][2]float64
+	seen := make(map[[2]float64]bool)
+	add := func(p [2]float64) {
+		if !seen[p] {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			a, b := ring[i-1], ring[i]
+			n := int(math.Ceil(math.Hypot(b[0]-a[0], b[1]-a[1]) / spacing))
+			for j := 0; j < n; j++ {
+				f := float64(j) / float64(n)
+				add([2]float64{a[0] + f*(b[0]-a[0]), a[1] + f*(b[1]-a[1])})
+			}
+		}
+	}
+	return points
+}
+
+// A delaunay is the Delaunay triangulation of points, built by the
+// Bowyer-Watson algorithm within a super triangle whose vertices follow
+// the points.
+type delaunay struct {
+	points    [][2]float64
+	triangles []delaunayTriangle
+}
+
+// A delaunayTriangle is a triangle of a delaunay, its vertices as indexes
+// of the points, with its circumcircle.
+type delaunayTriangle struct {
+	a, b, c int
+	x, y    float64
+	r2      float64 // squared radius, infinite for degenerate triangles
+}
+
+func newDelaunay(points [][2]float64) *delaunay {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	size := math.Max(math.Max(maxX-minX, maxY-minY), 1e-9)
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+
+	n := len(points)
+	t := &delaunay{points: append(points,
+		[2]float64{midX - 20*size, midY - size},
+		[2]float64{midX, midY + 20*size},
+		[2]float64{midX + 20*size, midY - size},
+	)}
+	t.triangles = []delaunayTriangle{t.triangle(n, n+1, n+2)}
+
+	for i := 0; i < n; i++ {
+		p := t.points[i]
+		count := make(map[[2]int]int)
+		var cavity [][2]int
+		kept := t.triangles[:0]
+		for _, tri := range t.triangles {
+			dx, dy := p[0]-tri.x, p[1]-tri.y
+			if dx*dx+dy*dy < tri.r2 {
+				for _, e := range [][2]int{{tri.a, tri.b}, {tri.b, tri.c}, {tri.c, tri.a}} {
+					key := e
+					if key[0] > key[1] {
+						key[0], key[1] = key[1], key[0]
+					}
+					count[key]++
+					cavity = append(cavity, e)
+				}
+				continue
+			}
+			kept = append(kept, tri)
+		}
+
+		t.triangles = kept
+		for _, e := range cavity {
+			key := e
+			if key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if count[key] == 1 {
+				t.triangles = append(t.triangles, t.triangle(e[0], e[1], i))
+			}
+		}
+	}
+	return t
+}
+
+// triangle returns the triangle of the points, with its circumcircle.
+func (t *delaunay) triangle(a, b, c int) delaunayTriangle {
+	pa, pb, pc := t.points[a], t.points[b], t.points[c]
+	tri := delaunayTriangle{a: a, b: b, c: c, r2: math.Inf(1)}
+	d := 2 * (pa[0]*(pb[1]-pc[1]) + pb[0]*(pc[1]-pa[1]) + pc[0]*(pa[1]-pb[1]))
+	if d == 0 {
+		return tri
+	}
+
+	la := pa[0]*pa[0] + pa[1]*pa[1]
+	lb := pb[0]*pb[0] + pb[1]*pb[1]
+	lc := pc[0]*pc[0] + pc[1]*pc[1]
+	tri.x = (la*(pb[1]-pc[1]) + lb*(pc[1]-pa[1]) + lc*(pa[1]-pb[1])) / d
+	tri.y = (la*(pc[0]-pb[0]) + lb*(pa[0]-pc[0]) + lc*(pb[0]-pa[0])) / d
+	dx, dy := pa[0]-tri.x, pa[1]-tri.y
+	tri.r2 = dx*dx + dy*dy
+	return tri
+}
+
+// super returns true if the triangle has a vertex of the super triangle.
+func (t *delaunay) super(tri delaunayTriangle) bool {
+	n := len(t.points) - 3
+	return tri.a >= n || tri.b >= n || tri.c >= n
+}
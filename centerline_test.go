@@ -0,0 +1,75 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCenterline(t *testing.T) {
+	// a road 10° long and 1° wide along the equator
+	road := NewPolygonGeometry([][][]float64{{{0, -0.5}, {10, -0.5}, {10, 0.5}, {0, 0.5}, {0, -0.5}}})
+
+	line, err := Centerline(road)
+	if err != nil {
+		t.Fatalf("should compute the centerline, but got %v", err)
+	}
+	if line.Type != GeometryLineString {
+		t.Fatalf("incorrect type, got %v", line.Type)
+	}
+	// the medial axis forks to the corners at the ends of the road
+	for _, p := range line.LineString {
+		if p[0] > 1 && p[0] < 9 && math.Abs(p[1]) > 0.01 {
+			t.Errorf("should follow the middle of the road, got %v", p)
+		}
+	}
+	first, last := line.LineString[0], line.LineString[len(line.LineString)-1]
+	if math.Min(first[0], last[0]) > 1 || math.Max(first[0], last[0]) < 9 {
+		t.Errorf("should run along the road, got from %v to %v", first, last)
+	}
+}
+
+func TestCenterlineBend(t *testing.T) {
+	// a river turning north, 1° wide
+	river := NewPolygonGeometry([][][]float64{{
+		{0, 0}, {6, 0}, {6, 6}, {5, 6}, {5, 1}, {0, 1}, {0, 0},
+	}})
+
+	line, err := Centerline(river)
+	if err != nil {
+		t.Fatalf("should compute the centerline, but got %v", err)
+	}
+	for _, p := range line.LineString {
+		if PointInPolygonWinding(p, river.Polygon) != Interior {
+			t.Errorf("should be inside the river, got %v", p)
+		}
+	}
+	ends := [][]float64{line.LineString[0], line.LineString[len(line.LineString)-1]}
+	if ends[0][0] > ends[1][0] {
+		ends[0], ends[1] = ends[1], ends[0]
+	}
+	if ends[0][0] > 1 || ends[1][1] < 5 {
+		t.Errorf("should run from one end of the river to the other, got %v", ends)
+	}
+}
+
+func TestCenterlineMultiPolygon(t *testing.T) {
+	g := NewMultiPolygonGeometry(
+		[][][]float64{{{0, 0}, {4, 0}, {4, 1}, {0, 1}, {0, 0}}},
+		[][][]float64{{{0, 10}, {1, 10}, {1, 14}, {0, 14}, {0, 10}}},
+	)
+
+	line, err := Centerline(g)
+	if err != nil {
+		t.Fatalf("should compute the centerline, but got %v", err)
+	}
+	if line.Type != GeometryMultiLineString || len(line.MultiLineString) != 2 {
+		t.Fatalf("should have a line per polygon, got %v", line)
+	}
+
+	if _, err := Centerline(NewPointGeometry([]float64{1, 2})); err == nil {
+		t.Errorf("should reject a point")
+	}
+	if _, err := Centerline(NewPolygonGeometry([][][]float64{{{0, 0}, {1, 1}, {2, 2}, {0, 0}}})); err == nil {
+		t.Errorf("should reject a polygon without area")
+	}
+}
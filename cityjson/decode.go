@@ -0,0 +1,383 @@
+/*
+Package cityjson converts the building footprints of CityJSON city models to
+and from GeoJSON feature collections. The ground surfaces of buildings become
+2.5D polygons, with the height of the building as a property, which is what
+3D map renderers extrude.
+*/
+package cityjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// The properties holding the extrusion of a footprint.
+const (
+	// HeightProperty is the height of the building above its base, in the
+	// units of the reference system.
+	HeightProperty = "height"
+
+	// BaseProperty is the altitude of the ground surface.
+	BaseProperty = "base_height"
+)
+
+// The CityJSON semantic surface types used for footprints.
+const (
+	groundSurface = "GroundSurface"
+	roofSurface   = "RoofSurface"
+	wallSurface   = "WallSurface"
+)
+
+type document struct {
+	Type        string                `json:"type"`
+	Version     string                `json:"version"`
+	Transform   *transform            `json:"transform,omitempty"`
+	Metadata    *metadata             `json:"metadata,omitempty"`
+	CityObjects map[string]cityObject `json:"CityObjects"`
+	Vertices    [][]float64           `json:"vertices"`
+}
+
+type transform struct {
+	Scale     []float64 `json:"scale"`
+	Translate []float64 `json:"translate"`
+}
+
+type metadata struct {
+	ReferenceSystem string `json:"referenceSystem,omitempty"`
+}
+
+type cityObject struct {
+	Type       string                 `json:"type"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Geometry   []geometry             `json:"geometry,omitempty"`
+}
+
+type geometry struct {
+	Type       string          `json:"type"`
+	LOD        json.RawMessage `json:"lod"`
+	Boundaries json.RawMessage `json:"boundaries"`
+	Semantics  *semantics      `json:"semantics,omitempty"`
+}
+
+type semantics struct {
+	Surfaces []surfaceType   `json:"surfaces"`
+	Values   json.RawMessage `json:"values"`
+}
+
+type surfaceType struct {
+	Type string `json:"type"`
+}
+
+// A surface is a polygon of vertex indexes, with the index of its semantic
+// surface or -1.
+type surface struct {
+	rings    [][]int
+	semantic int
+}
+
+// Decode converts the buildings and building parts of a CityJSON document
+// into polygon features, or multi polygon features when they have several
+// ground surfaces. Each feature has the attributes of the city object as
+// properties, and its id as id.
+//
+// The footprint is taken from the surfaces with the GroundSurface semantic,
+// or from the lowest horizontal surfaces when there are no semantics, in the
+// most detailed level of detail. The height is the "measuredHeight" attribute
+// if set, otherwise the difference between the highest and lowest vertex.
+// A reference system in the metadata becomes the CRS of the collection.
+func Decode(data []byte) (*geojson.FeatureCollection, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Type != "CityJSON" {
+		return nil, fmt.Errorf("not a CityJSON document, got type %q", doc.Type)
+	}
+
+	vertices, err := doc.vertices()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(doc.CityObjects))
+	for id := range doc.CityObjects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fc := geojson.NewFeatureCollection()
+	if doc.Metadata != nil && doc.Metadata.ReferenceSystem != "" {
+		fc.CRS = map[string]interface{}{
+			"type":       "name",
+			"properties": map[string]interface{}{"name": doc.Metadata.ReferenceSystem},
+		}
+	}
+
+	for _, id := range ids {
+		o := doc.CityObjects[id]
+		if o.Type != "Building" && o.Type != "BuildingPart" {
+			continue
+		}
+
+		f, err := decodeBuilding(o, vertices)
+		if err != nil {
+			return nil, fmt.Errorf("city object %s: %v", id, err)
+		}
+		if f == nil {
+			continue
+		}
+		f.ID = id
+		fc.AddFeature(f)
+	}
+
+	return fc, nil
+}
+
+// vertices returns the real coordinates of the vertices.
+func (doc *document) vertices() ([][]float64, error) {
+	scale, translate := []float64{1, 1, 1}, []float64{0, 0, 0}
+	if doc.Transform != nil {
+		if len(doc.Transform.Scale) != 3 || len(doc.Transform.Translate) != 3 {
+			return nil, errors.New("transform must have 3 scales and 3 translations")
+		}
+		scale, translate = doc.Transform.Scale, doc.Transform.Translate
+	}
+
+	vertices := make([][]float64, len(doc.Vertices))
+	for i, v := range doc.Vertices {
+		if len(v) != 3 {
+			return nil, fmt.Errorf("vertex %d must have 3 coordinates, got %d", i, len(v))
+		}
+		vertices[i] = []float64{
+			v[0]*scale[0] + translate[0],
+			v[1]*scale[1] + translate[1],
+			v[2]*scale[2] + translate[2],
+		}
+	}
+	return vertices, nil
+}
+
+func decodeBuilding(o cityObject, vertices [][]float64) (*geojson.Feature, error) {
+	var best *geometry
+	bestLOD := math.Inf(-1)
+	for i := range o.Geometry {
+		g := &o.Geometry[i]
+		if lod := levelOfDetail(g.LOD); best == nil || lod > bestLOD {
+			best, bestLOD = g, lod
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	surfaces, err := best.surfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	for _, s := range surfaces {
+		for _, r := range s.rings {
+			for _, vi := range r {
+				if vi < 0 || vi >= len(vertices) {
+					return nil, fmt.Errorf("vertex index %d out of range", vi)
+				}
+				minZ = math.Min(minZ, vertices[vi][2])
+				maxZ = math.Max(maxZ, vertices[vi][2])
+			}
+		}
+	}
+
+	var ground []surface
+	for _, s := range surfaces {
+		if s.semantic >= 0 && best.Semantics.Surfaces[s.semantic].Type == groundSurface {
+			ground = append(ground, s)
+		}
+	}
+	if len(ground) == 0 {
+		ground = lowestSurfaces(surfaces, vertices, minZ)
+	}
+	if len(ground) == 0 {
+		return nil, nil
+	}
+
+	base := math.Inf(1)
+	polygons := make([][][][]float64, len(ground))
+	for i, s := range ground {
+		polygons[i] = make([][][]float64, len(s.rings))
+		for j, r := range s.rings {
+			ring := make([][]float64, 0, len(r)+1)
+			for _, vi := range r {
+				v := vertices[vi]
+				ring = append(ring, []float64{v[0], v[1], v[2]})
+				base = math.Min(base, v[2])
+			}
+			ring = append(ring, ring[0])
+			polygons[i][j] = orient(ring, j == 0)
+		}
+	}
+
+	var f *geojson.Feature
+	if len(polygons) == 1 {
+		f = geojson.NewPolygonFeature(polygons[0])
+	} else {
+		f = geojson.NewMultiPolygonFeature(polygons...)
+	}
+	for k, v := range o.Attributes {
+		f.SetProperty(k, v)
+	}
+
+	height := maxZ - base
+	if h, ok := o.Attributes["measuredHeight"].(float64); ok {
+		height = h
+	}
+	f.SetProperty(HeightProperty, height)
+	f.SetProperty(BaseProperty, base)
+
+	return f, nil
+}
+
+// levelOfDetail parses the lod of a geometry, a number in CityJSON 1.0 and a
+// string like "2.2" since 1.1.
+func levelOfDetail(raw json.RawMessage) float64 {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if lod, err := strconv.ParseFloat(s, 64); err == nil {
+			return lod
+		}
+		return 0
+	}
+
+	var lod float64
+	json.Unmarshal(raw, &lod)
+	return lod
+}
+
+// surfaces flattens the boundaries of the geometry with their semantics.
+func (g *geometry) surfaces() ([]surface, error) {
+	var result []surface
+	add := func(rings [][]int, semantic *int) {
+		s := surface{rings: rings, semantic: -1}
+		if semantic != nil && g.Semantics != nil && *semantic >= 0 && *semantic < len(g.Semantics.Surfaces) {
+			s.semantic = *semantic
+		}
+		if len(rings) > 0 && len(rings[0]) >= 3 {
+			result = append(result, s)
+		}
+	}
+
+	switch g.Type {
+	case "MultiSurface", "CompositeSurface":
+		var boundaries [][][]int
+		var values []*int
+		if err := g.decode(&boundaries, &values); err != nil {
+			return nil, err
+		}
+		for i, s := range boundaries {
+			add(s, semanticAt(values, i))
+		}
+	case "Solid":
+		var boundaries [][][][]int
+		var values [][]*int
+		if err := g.decode(&boundaries, &values); err != nil {
+			return nil, err
+		}
+		for i, shell := range boundaries {
+			for j, s := range shell {
+				var v []*int
+				if i < len(values) {
+					v = values[i]
+				}
+				add(s, semanticAt(v, j))
+			}
+		}
+	case "MultiSolid", "CompositeSolid":
+		var boundaries [][][][][]int
+		var values [][][]*int
+		if err := g.decode(&boundaries, &values); err != nil {
+			return nil, err
+		}
+		for i, solid := range boundaries {
+			for j, shell := range solid {
+				for k, s := range shell {
+					var v []*int
+					if i < len(values) && j < len(values[i]) {
+						v = values[i][j]
+					}
+					add(s, semanticAt(v, k))
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (g *geometry) decode(boundaries, values interface{}) error {
+	if err := json.Unmarshal(g.Boundaries, boundaries); err != nil {
+		return fmt.Errorf("invalid %s boundaries: %v", g.Type, err)
+	}
+	if g.Semantics != nil && len(g.Semantics.Values) > 0 {
+		if err := json.Unmarshal(g.Semantics.Values, values); err != nil {
+			return fmt.Errorf("invalid %s semantic values: %v", g.Type, err)
+		}
+	}
+	return nil
+}
+
+func semanticAt(values []*int, i int) *int {
+	if i < len(values) {
+		return values[i]
+	}
+	return nil
+}
+
+// lowestSurfaces returns the horizontal surfaces lying at the lowest altitude.
+func lowestSurfaces(surfaces []surface, vertices [][]float64, minZ float64) []surface {
+	const tolerance = 1e-6
+
+	var result []surface
+	for _, s := range surfaces {
+		flat := true
+		for _, vi := range s.rings[0] {
+			if math.Abs(vertices[vi][2]-minZ) > tolerance {
+				flat = false
+				break
+			}
+		}
+		if flat {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// orient returns the closed ring, reversed if needed so it is counterclockwise
+// when ccw is set and clockwise otherwise, seen from above.
+func orient(ring [][]float64, ccw bool) [][]float64 {
+	if (signedArea(ring) > 0) == ccw {
+		return ring
+	}
+
+	reversed := make([][]float64, len(ring))
+	for i, p := range ring {
+		reversed[len(ring)-1-i] = p
+	}
+	return reversed
+}
+
+// signedArea is positive for counterclockwise rings.
+func signedArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		area += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return area / 2
+}
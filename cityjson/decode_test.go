@@ -0,0 +1,127 @@
+package cityjson
+
+import (
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// a 10 by 10 meters building, 6 meters high, standing at an altitude of 2
+const testBuilding = `{
+	"type": "CityJSON",
+	"version": "1.1",
+	"transform": {"scale": [0.01, 0.01, 0.01], "translate": [1000, 2000, 0]},
+	"metadata": {"referenceSystem": "https://www.opengis.net/def/crs/EPSG/0/7415"},
+	"CityObjects": {
+		"b1": {
+			"type": "Building",
+			"attributes": {"yearOfConstruction": 1905},
+			"geometry": [{
+				"type": "Solid",
+				"lod": "1",
+				"boundaries": [[
+					[[0, 3, 2, 1]], [[4, 5, 6, 7]],
+					[[0, 1, 5, 4]], [[1, 2, 6, 5]], [[2, 3, 7, 6]], [[3, 0, 4, 7]]
+				]],
+				"semantics": {
+					"surfaces": [{"type": "GroundSurface"}, {"type": "RoofSurface"}, {"type": "WallSurface"}],
+					"values": [[0, 1, 2, 2, 2, null]]
+				}
+			}]
+		},
+		"tree": {"type": "SolitaryVegetationObject"}
+	},
+	"vertices": [
+		[0, 0, 200], [1000, 0, 200], [1000, 1000, 200], [0, 1000, 200],
+		[0, 0, 800], [1000, 0, 800], [1000, 1000, 800], [0, 1000, 800]
+	]
+}`
+
+func TestDecode(t *testing.T) {
+	fc, err := Decode([]byte(testBuilding))
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("should only decode the building, got %d features", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	if f.ID != "b1" {
+		t.Errorf("should use the city object id, got %v", f.ID)
+	}
+
+	expected := geojson.NewPolygonGeometry([][][]float64{{
+		{1000, 2000, 2}, {1010, 2000, 2}, {1010, 2010, 2}, {1000, 2010, 2}, {1000, 2000, 2},
+	}})
+	if !f.Geometry.EqualWithTolerance(expected, 1e-9) {
+		t.Errorf("incorrect footprint, got %v", f.Geometry.Polygon)
+	}
+
+	if h := f.PropertyMustFloat64(HeightProperty); h != 6 {
+		t.Errorf("should have a height of 6, got %v", h)
+	}
+	if b := f.PropertyMustFloat64(BaseProperty); b != 2 {
+		t.Errorf("should have a base of 2, got %v", b)
+	}
+	if y := f.PropertyMustInt("yearOfConstruction"); y != 1905 {
+		t.Errorf("should keep the attributes, got %v", y)
+	}
+
+	props, _ := fc.CRS["properties"].(map[string]interface{})
+	if props["name"] != "https://www.opengis.net/def/crs/EPSG/0/7415" {
+		t.Errorf("should set the reference system as CRS, got %v", fc.CRS)
+	}
+}
+
+func TestDecodeWithoutSemantics(t *testing.T) {
+	data := `{
+		"type": "CityJSON",
+		"version": "1.0",
+		"CityObjects": {
+			"b1": {
+				"type": "BuildingPart",
+				"attributes": {"measuredHeight": 12.5},
+				"geometry": [
+					{"type": "MultiSurface", "lod": 0, "boundaries": [[[0, 1, 2]]]},
+					{"type": "MultiSurface", "lod": 1, "boundaries": [[[0, 1, 2]], [[3, 4, 5]]]}
+				]
+			}
+		},
+		"vertices": [[0, 0, 1], [4, 0, 1], [0, 4, 1], [0, 0, 9], [0, 4, 9], [4, 0, 9]]
+	}`
+
+	fc, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("should decode the building part, got %d features", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	expected := geojson.NewPolygonGeometry([][][]float64{{{0, 0, 1}, {4, 0, 1}, {0, 4, 1}, {0, 0, 1}}})
+	if !f.Geometry.Equal(expected) {
+		t.Errorf("should use the lowest surface, got %v", f.Geometry.Polygon)
+	}
+	if h := f.PropertyMustFloat64(HeightProperty); h != 12.5 {
+		t.Errorf("should use the measured height, got %v", h)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	cases := map[string]string{
+		"not json":     `{`,
+		"type":         `{"type": "FeatureCollection"}`,
+		"vertex":       `{"type": "CityJSON", "vertices": [[0, 0]]}`,
+		"transform":    `{"type": "CityJSON", "transform": {"scale": [1], "translate": [0, 0, 0]}}`,
+		"vertex index": `{"type": "CityJSON", "CityObjects": {"b": {"type": "Building", "geometry": [{"type": "MultiSurface", "boundaries": [[[0, 1, 2]]]}]}}, "vertices": []}`,
+		"boundaries":   `{"type": "CityJSON", "CityObjects": {"b": {"type": "Building", "geometry": [{"type": "Solid", "boundaries": [[0, 1, 2]]}]}}}`,
+	}
+
+	for name, data := range cases {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("should reject invalid %s", name)
+		}
+	}
+}
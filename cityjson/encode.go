@@ -0,0 +1,282 @@
+package cityjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Version is the CityJSON version written by Encode.
+const Version = "1.1"
+
+// DefaultScale is the precision of the vertices written by Encode when the
+// options do not set one, a millimeter in metric reference systems.
+const DefaultScale = 0.001
+
+// Options configures the CityJSON written by Encode.
+type Options struct {
+	// Scale is the size of a vertex unit, DefaultScale if zero.
+	Scale float64
+
+	// ReferenceSystem is written in the metadata, like
+	// "https://www.opengis.net/def/crs/EPSG/0/7415".
+	ReferenceSystem string
+}
+
+// Encode converts polygon and multi polygon features into a CityJSON
+// document with a LoD1 building per feature. Each footprint is extruded from
+// its base by its height, read from the HeightProperty and BaseProperty
+// properties. Without a base, the lowest altitude of the footprint is used,
+// or 0 for 2D footprints. The other properties become the attributes of the
+// building, with the height as "measuredHeight".
+func Encode(fc *geojson.FeatureCollection, opts Options) ([]byte, error) {
+	scale := opts.Scale
+	if scale == 0 {
+		scale = DefaultScale
+	}
+	if scale < 0 {
+		return nil, errors.New("scale must be positive")
+	}
+
+	e := &encoder{
+		scale:   scale,
+		indexes: make(map[[3]int64]int),
+		objects: make(map[string]cityObject),
+	}
+	if err := e.computeTranslation(fc.Features); err != nil {
+		return nil, err
+	}
+
+	for i, f := range fc.Features {
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+		if err := e.encodeBuilding(i, f); err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+	}
+
+	doc := document{
+		Type:        "CityJSON",
+		Version:     Version,
+		Transform:   &transform{Scale: []float64{scale, scale, scale}, Translate: e.translate},
+		CityObjects: e.objects,
+		Vertices:    e.vertices,
+	}
+	if doc.Vertices == nil {
+		doc.Vertices = [][]float64{}
+	}
+	if opts.ReferenceSystem != "" {
+		doc.Metadata = &metadata{ReferenceSystem: opts.ReferenceSystem}
+	}
+
+	return json.Marshal(doc)
+}
+
+type encoder struct {
+	scale     float64
+	translate []float64
+
+	indexes  map[[3]int64]int
+	vertices [][]float64
+	objects  map[string]cityObject
+}
+
+// computeTranslation moves the origin to the lowest corner of the footprints,
+// keeping the vertex integers small.
+func (e *encoder) computeTranslation(features []*geojson.Feature) error {
+	minimum := []float64{math.Inf(1), math.Inf(1)}
+	for _, f := range features {
+		polygons, err := footprints(f)
+		if err != nil {
+			return err
+		}
+		for _, polygon := range polygons {
+			for _, ring := range polygon {
+				for _, p := range ring {
+					minimum[0] = math.Min(minimum[0], p[0])
+					minimum[1] = math.Min(minimum[1], p[1])
+				}
+			}
+		}
+	}
+
+	e.translate = []float64{0, 0, 0}
+	if !math.IsInf(minimum[0], 1) {
+		e.translate[0], e.translate[1] = minimum[0], minimum[1]
+	}
+	return nil
+}
+
+func (e *encoder) encodeBuilding(i int, f *geojson.Feature) error {
+	polygons, err := footprints(f)
+	if err != nil {
+		return err
+	}
+	if len(polygons) == 0 {
+		return nil
+	}
+
+	height, err := f.PropertyFloat64(HeightProperty)
+	if err != nil {
+		return err
+	}
+	if height <= 0 {
+		return fmt.Errorf("height must be positive, got %v", height)
+	}
+
+	base, err := f.PropertyFloat64(BaseProperty)
+	if err != nil {
+		base = lowestAltitude(polygons)
+	}
+
+	var shell [][][]int
+	var values []*int
+	semantic := func(i int) *int { return &i }
+	ground, roof, wall := semantic(0), semantic(1), semantic(2)
+
+	for _, polygon := range polygons {
+		var bottom, top [][]int
+		for j, r := range polygon {
+			ring := orient(r, j == 0)
+			if first, last := ring[0], ring[len(ring)-1]; first[0] == last[0] && first[1] == last[1] {
+				ring = ring[:len(ring)-1]
+			}
+
+			b := make([]int, len(ring))
+			t := make([]int, len(ring))
+			for k, p := range ring {
+				b[k] = e.vertex(p[0], p[1], base)
+				t[k] = e.vertex(p[0], p[1], base+height)
+			}
+			for k := range ring {
+				next := (k + 1) % len(ring)
+				shell = append(shell, [][]int{{b[k], b[next], t[next], t[k]}})
+				values = append(values, wall)
+			}
+
+			reversed := make([]int, len(b))
+			for k, v := range b {
+				reversed[len(b)-1-k] = v
+			}
+			bottom = append(bottom, reversed)
+			top = append(top, t)
+		}
+
+		shell = append(shell, bottom, top)
+		values = append(values, ground, roof)
+	}
+
+	boundaries, err := json.Marshal([][][][]int{shell})
+	if err != nil {
+		return err
+	}
+	semanticValues, err := json.Marshal([][]*int{values})
+	if err != nil {
+		return err
+	}
+
+	attributes := make(map[string]interface{}, len(f.Properties))
+	for k, v := range f.Properties {
+		if k != HeightProperty && k != BaseProperty {
+			attributes[k] = v
+		}
+	}
+	attributes["measuredHeight"] = height
+
+	id := fmt.Sprintf("building-%d", i)
+	if f.ID != nil {
+		id = fmt.Sprint(f.ID)
+	}
+	if _, ok := e.objects[id]; ok {
+		return fmt.Errorf("duplicate id %s", id)
+	}
+
+	e.objects[id] = cityObject{
+		Type:       "Building",
+		Attributes: attributes,
+		Geometry: []geometry{{
+			Type:       "Solid",
+			LOD:        json.RawMessage(`"1"`),
+			Boundaries: boundaries,
+			Semantics: &semantics{
+				Surfaces: []surfaceType{{groundSurface}, {roofSurface}, {wallSurface}},
+				Values:   semanticValues,
+			},
+		}},
+	}
+	return nil
+}
+
+// vertex returns the index of the quantized vertex, adding it if new.
+func (e *encoder) vertex(x, y, z float64) int {
+	key := [3]int64{
+		int64(math.Round((x - e.translate[0]) / e.scale)),
+		int64(math.Round((y - e.translate[1]) / e.scale)),
+		int64(math.Round((z - e.translate[2]) / e.scale)),
+	}
+	if i, ok := e.indexes[key]; ok {
+		return i
+	}
+
+	e.indexes[key] = len(e.vertices)
+	e.vertices = append(e.vertices, []float64{float64(key[0]), float64(key[1]), float64(key[2])})
+	return len(e.vertices) - 1
+}
+
+// footprints returns the polygons of a polygon or multi polygon feature,
+// nil for the other geometries.
+func footprints(f *geojson.Feature) ([][][][]float64, error) {
+	if f == nil || f.Geometry == nil {
+		return nil, nil
+	}
+
+	var polygons [][][][]float64
+	switch f.Geometry.Type {
+	case geojson.GeometryPolygon:
+		polygons = [][][][]float64{f.Geometry.Polygon}
+	case geojson.GeometryMultiPolygon:
+		polygons = f.Geometry.MultiPolygon
+	default:
+		return nil, nil
+	}
+
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return nil, errors.New("polygon without rings")
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, errors.New("ring must have at least 4 positions")
+			}
+			for _, p := range ring {
+				if len(p) < 2 {
+					return nil, errors.New("position must have at least 2 coordinates")
+				}
+			}
+		}
+	}
+	return polygons, nil
+}
+
+// lowestAltitude returns the lowest altitude of the footprint positions, 0 if
+// they have none.
+func lowestAltitude(polygons [][][][]float64) float64 {
+	lowest := math.Inf(1)
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			for _, p := range ring {
+				if len(p) > 2 {
+					lowest = math.Min(lowest, p[2])
+				}
+			}
+		}
+	}
+	if math.IsInf(lowest, 1) {
+		return 0
+	}
+	return lowest
+}
@@ -0,0 +1,104 @@
+package cityjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestEncode(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+
+	f := geojson.NewPolygonFeature([][][]float64{
+		{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}},
+		{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+	})
+	f.ID = "town-hall"
+	f.SetProperty(HeightProperty, 15.0)
+	f.SetProperty(BaseProperty, 3.0)
+	f.SetProperty("name", "Town hall")
+	fc.AddFeature(f)
+	fc.AddFeature(geojson.NewPointFeature([]float64{1, 2}))
+
+	data, err := Encode(fc, Options{ReferenceSystem: "https://www.opengis.net/def/crs/EPSG/0/7415"})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("should be valid JSON, but got %v", err)
+	}
+	if doc.Version != Version || len(doc.CityObjects) != 1 {
+		t.Fatalf("should have one building, got %s", data)
+	}
+	if len(doc.Vertices) != 16 {
+		t.Errorf("should share the vertices of the surfaces, got %d", len(doc.Vertices))
+	}
+
+	o := doc.CityObjects["town-hall"]
+	if o.Attributes["measuredHeight"] != 15.0 || o.Attributes["name"] != "Town hall" {
+		t.Errorf("incorrect attributes, got %v", o.Attributes)
+	}
+	if _, ok := o.Attributes[HeightProperty]; ok {
+		t.Errorf("should not copy the height property, got %v", o.Attributes)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	g := decoded.Features[0].Geometry
+	expected := geojson.NewPolygonGeometry([][][]float64{
+		{{0, 0, 3}, {10, 0, 3}, {10, 10, 3}, {0, 10, 3}, {0, 0, 3}},
+		{{2, 2, 3}, {2, 4, 3}, {4, 4, 3}, {4, 2, 3}, {2, 2, 3}},
+	})
+	if !equalRings(g, expected) {
+		t.Errorf("should round trip the footprint, got %v", g.Polygon)
+	}
+	if h := decoded.Features[0].PropertyMustFloat64(HeightProperty); h != 15 {
+		t.Errorf("should round trip the height, got %v", h)
+	}
+	if b := decoded.Features[0].PropertyMustFloat64(BaseProperty); b != 3 {
+		t.Errorf("should round trip the base, got %v", b)
+	}
+}
+
+func TestEncodeWithoutHeight(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+
+	if _, err := Encode(fc, Options{}); err == nil {
+		t.Errorf("should require a height")
+	}
+}
+
+// equalRings compares the polygons, ignoring where the rings start.
+func equalRings(g, expected *geojson.Geometry) bool {
+	if len(g.Polygon) != len(expected.Polygon) {
+		return false
+	}
+	for i, ring := range g.Polygon {
+		other := expected.Polygon[i]
+		if len(ring) != len(other) {
+			return false
+		}
+
+		matched := false
+		for shift := 0; shift < len(ring)-1 && !matched; shift++ {
+			matched = true
+			for j := 0; j < len(ring)-1; j++ {
+				p, q := ring[(j+shift)%(len(ring)-1)], other[j]
+				if p[0] != q[0] || p[1] != q[1] || p[2] != q[2] {
+					matched = false
+					break
+				}
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package geojson
+
+import (
+	"encoding/json"
+)
+
+// Clone returns a deep copy of the geometry, sharing no slices or maps with it.
+func (g *Geometry) Clone() *Geometry {
+	if g == nil {
+		return nil
+	}
+
+	c := &Geometry{
+		Type:            g.Type,
+		BoundingBox:     clonePosition(g.BoundingBox),
+		Point:           clonePosition(g.Point),
+		MultiPoint:      clonePath(g.MultiPoint),
+		LineString:      clonePath(g.LineString),
+		MultiLineString: clonePaths(g.MultiLineString),
+		Polygon:         clonePaths(g.Polygon),
+		CRS:             cloneProperties(g.CRS),
+		ForeignMembers:  cloneForeignMembers(g.ForeignMembers),
+		provenance:      append([]Transformation(nil), g.provenance...),
+	}
+
+	if g.MultiPolygon != nil {
+		c.MultiPolygon = make([][][][]float64, len(g.MultiPolygon))
+		for i, p := range g.MultiPolygon {
+			c.MultiPolygon[i] = clonePaths(p)
+		}
+	}
+
+	if g.Geometries != nil {
+		c.Geometries = make([]*Geometry, len(g.Geometries))
+		for i, child := range g.Geometries {
+			c.Geometries[i] = child.Clone()
+		}
+	}
+
+	return c
+}
+
+// Clone returns a deep copy of the feature, including its geometry and properties.
+func (f *Feature) Clone() *Feature {
+	if f == nil {
+		return nil
+	}
+
+	return &Feature{
+		ID:             cloneValue(f.ID),
+		Type:           f.Type,
+		BoundingBox:    clonePosition(f.BoundingBox),
+		Geometry:       f.Geometry.Clone(),
+		Properties:     cloneProperties(f.Properties),
+		CRS:            cloneProperties(f.CRS),
+		ForeignMembers: cloneForeignMembers(f.ForeignMembers),
+	}
+}
+
+// Clone returns a deep copy of the feature collection and all its features.
+func (fc *FeatureCollection) Clone() *FeatureCollection {
+	if fc == nil {
+		return nil
+	}
+
+	c := &FeatureCollection{
+		Type:           fc.Type,
+		BoundingBox:    clonePosition(fc.BoundingBox),
+		CRS:            cloneProperties(fc.CRS),
+		ForeignMembers: cloneForeignMembers(fc.ForeignMembers),
+	}
+
+	if fc.Features != nil {
+		c.Features = make([]*Feature, len(fc.Features))
+		for i, f := range fc.Features {
+			c.Features[i] = f.Clone()
+		}
+	}
+
+	return c
+}
+
+func clonePosition(p []float64) []float64 {
+	if p == nil {
+		return nil
+	}
+	return append(make([]float64, 0, len(p)), p...)
+}
+
+func clonePath(path [][]float64) [][]float64 {
+	if path == nil {
+		return nil
+	}
+
+	result := make([][]float64, len(path))
+	for i, p := range path {
+		result[i] = clonePosition(p)
+	}
+	return result
+}
+
+func clonePaths(paths [][][]float64) [][][]float64 {
+	if paths == nil {
+		return nil
+	}
+
+	result := make([][][]float64, len(paths))
+	for i, p := range paths {
+		result[i] = clonePath(p)
+	}
+	return result
+}
+
+// cloneProperties deep copies the nested maps and slices of decoded JSON values,
+// other values are copied as is.
+func cloneProperties(properties map[string]interface{}) map[string]interface{} {
+	if properties == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(properties))
+	for k, v := range properties {
+		result[k] = cloneValue(v)
+	}
+	return result
+}
+
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return cloneProperties(v)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			result[i] = cloneValue(e)
+		}
+		return result
+	case []float64:
+		return clonePosition(v)
+	}
+	return v
+}
+
+func cloneForeignMembers(members map[string]json.RawMessage) map[string]json.RawMessage {
+	if members == nil {
+		return nil
+	}
+
+	result := make(map[string]json.RawMessage, len(members))
+	for k, v := range members {
+		result[k] = append(json.RawMessage(nil), v...)
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGeometryClone(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometry([]float64{1, 2}),
+		NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		&Geometry{Type: GeometryLineString, LineString: [][]float64{}},
+	)
+	g.BoundingBox = []float64{0, 0, 1, 2}
+	g.ForeignMembers = map[string]json.RawMessage{"title": json.RawMessage(`"x"`)}
+
+	c := g.Clone()
+	if !reflect.DeepEqual(g, c) {
+		t.Fatalf("should be equal, got %v", c)
+	}
+
+	c.Geometries[0].Point[0] = 9
+	c.Geometries[1].MultiPolygon[0][0][1][0] = 9
+	c.BoundingBox[0] = 9
+	c.ForeignMembers["title"][1] = 'y'
+
+	if g.Geometries[0].Point[0] != 1 || g.Geometries[1].MultiPolygon[0][0][1][0] != 1 || g.BoundingBox[0] != 0 {
+		t.Errorf("should not share coordinates, got %v", g)
+	}
+	if string(g.ForeignMembers["title"]) != `"x"` {
+		t.Errorf("should not share foreign members, got %s", g.ForeignMembers["title"])
+	}
+	if c.Geometries[2].LineString == nil {
+		t.Errorf("should keep empty slices empty rather than nil")
+	}
+
+	var nilGeometry *Geometry
+	if nilGeometry.Clone() != nil {
+		t.Errorf("should clone nil to nil")
+	}
+}
+
+func TestFeatureClone(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.ID = "a"
+	f.SetProperty("tags", []interface{}{"x", map[string]interface{}{"y": 1.0}})
+
+	c := f.Clone()
+	if !reflect.DeepEqual(f, c) {
+		t.Fatalf("should be equal, got %v", c)
+	}
+
+	c.Geometry.Point[0] = 9
+	c.Properties["tags"].([]interface{})[1].(map[string]interface{})["y"] = 2.0
+	c.SetProperty("new", true)
+
+	if f.Geometry.Point[0] != 1 {
+		t.Errorf("should not share geometry")
+	}
+	if f.Properties["tags"].([]interface{})[1].(map[string]interface{})["y"] != 1.0 || len(f.Properties) != 1 {
+		t.Errorf("should not share properties, got %v", f.Properties)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	cc := fc.Clone()
+	cc.Features[0].Geometry.Point[1] = 9
+	if f.Geometry.Point[1] != 2 || !reflect.DeepEqual(fc.BoundingBox, cc.BoundingBox) {
+		t.Errorf("should deep copy the collection, got %v", cc)
+	}
+}
@@ -0,0 +1,103 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// A CompactGeometry stores a geometry with its coordinates rounded to a
+// fixed number of decimal digits and delta encoded as zig-zag varints, in
+// Tiny Well-Known Binary. Dense lines and polygons take about 4 times less
+// memory than as float64 slices, for servers that mostly pass geometries
+// through.
+//
+// The geometry is decoded on demand, each time it is needed, and is not
+// kept. Bounding boxes, CRS, foreign members and measures are dropped,
+// altitudes are kept if all positions have one.
+type CompactGeometry struct {
+	data []byte
+}
+
+// NewCompactGeometry creates and initializes a compact geometry from the
+// geometry, keeping the given number of decimal digits, from -8 to 7,
+// like MarshalTWKB. 7 digits keep longitudes and latitudes to about a
+// centimeter.
+func NewCompactGeometry(g *Geometry, precision int) (*CompactGeometry, error) {
+	data, err := g.MarshalTWKB(precision)
+	if err != nil {
+		return nil, err
+	}
+	return &CompactGeometry{data: data}, nil
+}
+
+// Geometry decodes the geometry.
+func (c *CompactGeometry) Geometry() (*Geometry, error) {
+	if c == nil {
+		return nil, errors.New("no compact geometry to decode")
+	}
+	return UnmarshalTWKB(c.data)
+}
+
+// Size returns the number of bytes used by the encoded coordinates.
+func (c *CompactGeometry) Size() int {
+	return len(c.data)
+}
+
+// MarshalJSON decodes the geometry and converts it into the proper JSON.
+// This fulfills the json.Marshaler interface.
+func (c *CompactGeometry) MarshalJSON() ([]byte, error) {
+	g, err := c.Geometry()
+	if err != nil {
+		return nil, err
+	}
+	return g.MarshalJSON()
+}
+
+// A CompactFeature stores a feature with its geometry as a
+// CompactGeometry. Its other members are kept as they are.
+type CompactFeature struct {
+	feature  Feature
+	geometry *CompactGeometry
+}
+
+// NewCompactFeature creates and initializes a compact feature from the
+// feature, sharing its properties, with the precision of
+// NewCompactGeometry.
+func NewCompactFeature(f *Feature, precision int) (*CompactFeature, error) {
+	if f == nil {
+		return nil, errors.New("no feature to compact")
+	}
+
+	c := &CompactFeature{feature: *f}
+	c.feature.Geometry = nil
+	if f.Geometry != nil {
+		var err error
+		if c.geometry, err = NewCompactGeometry(f.Geometry, precision); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Feature decodes the feature. Its properties and other members are the
+// ones of the compact feature, not copies.
+func (c *CompactFeature) Feature() (*Feature, error) {
+	f := c.feature
+	if c.geometry != nil {
+		var err error
+		if f.Geometry, err = c.geometry.Geometry(); err != nil {
+			return nil, err
+		}
+	}
+	return &f, nil
+}
+
+// MarshalJSON decodes the feature and converts it into the proper JSON.
+// This fulfills the json.Marshaler interface.
+func (c *CompactFeature) MarshalJSON() ([]byte, error) {
+	f, err := c.Feature()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(f)
+}
@@ -0,0 +1,79 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompactGeometry(t *testing.T) {
+	line := make([][]float64, 1000)
+	for i := range line {
+		line[i] = []float64{4.35 + float64(i)*0.00001, 50.85 + float64(i%7)*0.00001}
+	}
+	g := NewLineStringGeometry(line)
+
+	c, err := NewCompactGeometry(g, 6)
+	if err != nil {
+		t.Fatalf("should compact, but got %v", err)
+	}
+	if raw := len(line) * 2 * 8; c.Size()*4 > raw {
+		t.Errorf("should be at least 4 times smaller than %d bytes, got %d", raw, c.Size())
+	}
+
+	decoded, err := c.Geometry()
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if !decoded.EqualWithTolerance(g, 1e-6) {
+		t.Errorf("incorrect geometry, got %v", decoded.LineString[:3])
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected, _ := decoded.MarshalJSON()
+	if string(data) != string(expected) {
+		t.Errorf("incorrect JSON, got %.60s", data)
+	}
+
+	if _, err := NewCompactGeometry(g, 9); err == nil {
+		t.Errorf("should reject a precision above 7")
+	}
+	var nilCompact *CompactGeometry
+	if _, err := nilCompact.Geometry(); err == nil {
+		t.Errorf("should not decode nil")
+	}
+}
+
+func TestCompactFeature(t *testing.T) {
+	f := NewPolygonFeature([][][]float64{{{0, 0}, {1.25, 0}, {1.25, 1}, {0, 0}}})
+	f.ID = "a"
+	f.SetProperty("name", "triangle")
+
+	c, err := NewCompactFeature(f, 2)
+	if err != nil {
+		t.Fatalf("should compact, but got %v", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"id":"a","type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1.25,0],[1.25,1],[0,0]]]},"properties":{"name":"triangle"}}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+
+	empty, err := NewCompactFeature(NewFeature(nil), 2)
+	if err != nil {
+		t.Fatalf("should compact a feature without geometry, but got %v", err)
+	}
+	decoded, err := empty.Feature()
+	if err != nil || decoded.Geometry != nil {
+		t.Errorf("should decode without geometry, got %v, %v", decoded, err)
+	}
+
+	if _, err := NewCompactFeature(nil, 2); err == nil {
+		t.Errorf("should not compact nil")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Package conformance checks that codecs of GeoJSON, like wrappers of the
+geojson package or custom decoders built on it, follow RFC 7946, against a
+bundled suite of valid and invalid documents.
+
+	func TestCodec(t *testing.T) {
+		conformance.Run(t, myCodec{})
+	}
+
+Valid documents must decode and encode back to the same JSON value, and
+invalid documents must be rejected when decoded.
+*/
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// A Codec decodes and encodes GeoJSON documents.
+type Codec interface {
+	// Decode decodes the GeoJSON document, a geometry, a feature or a
+	// feature collection.
+	Decode(data []byte) (interface{}, error)
+
+	// Encode encodes a value returned by Decode into GeoJSON.
+	Encode(v interface{}) ([]byte, error)
+}
+
+// A Fixture is a GeoJSON document of the suite.
+type Fixture struct {
+	Name     string
+	Document string
+
+	// Valid documents must be decoded and encoded back to the same JSON
+	// value, or to Expected if set. Invalid documents must be rejected.
+	Valid    bool
+	Expected string
+}
+
+// Run runs the bundled Fixtures against the codec, each as a subtest.
+func Run(t *testing.T, c Codec) {
+	RunFixtures(t, c, Fixtures)
+}
+
+// RunFixtures runs the fixtures against the codec, each as a subtest.
+func RunFixtures(t *testing.T, c Codec, fixtures []Fixture) {
+	for _, f := range fixtures {
+		f := f
+		t.Run(f.Name, func(t *testing.T) {
+			if err := check(c, f); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// check runs the fixture against the codec.
+func check(c Codec, f Fixture) error {
+	v, err := c.Decode([]byte(f.Document))
+	if !f.Valid {
+		if err == nil {
+			return fmt.Errorf("should reject %s", f.Document)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("should decode %s, but got %v", f.Document, err)
+	}
+
+	data, err := c.Encode(v)
+	if err != nil {
+		return fmt.Errorf("should encode %s, but got %v", f.Document, err)
+	}
+
+	expected := f.Expected
+	if expected == "" {
+		expected = f.Document
+	}
+	if !sameJSON(data, []byte(expected)) {
+		return fmt.Errorf("incorrect encoding of %s, got %s", f.Document, data)
+	}
+	return nil
+}
+
+// sameJSON tells if the documents hold the same JSON value, regardless of
+// spacing, member order and number formatting.
+func sameJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// Package is the codec of the geojson package, decoding with the options.
+// Strict options pass the suite.
+type Package struct {
+	Options geojson.DecodeOptions
+}
+
+// Decode decodes the document according to its type.
+func (p Package) Decode(data []byte) (interface{}, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	switch object.Type {
+	case "Feature":
+		return p.Options.UnmarshalFeature(data)
+	case "FeatureCollection":
+		return p.Options.UnmarshalFeatureCollection(data)
+	}
+	return p.Options.UnmarshalGeometry(data)
+}
+
+// Encode encodes the geometry, feature or feature collection.
+func (p Package) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
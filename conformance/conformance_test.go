@@ -0,0 +1,59 @@
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestPackage(t *testing.T) {
+	Run(t, Package{Options: geojson.DecodeOptions{Strict: true}})
+}
+
+// lossyCodec drops the properties of features.
+type lossyCodec struct {
+	Package
+}
+
+func (c lossyCodec) Encode(v interface{}) ([]byte, error) {
+	if f, ok := v.(*geojson.Feature); ok {
+		f.Properties = nil
+	}
+	return json.Marshal(v)
+}
+
+func TestCheck(t *testing.T) {
+	valid := Fixture{Name: "feature", Valid: true, Document: `{"type":"Feature","geometry":null,"properties":{"a":1}}`}
+	if err := check(Package{}, valid); err != nil {
+		t.Errorf("should pass, but got %v", err)
+	}
+	if err := check(lossyCodec{}, valid); err == nil {
+		t.Errorf("should fail a codec dropping properties")
+	}
+
+	expected := Fixture{Name: "null", Valid: true, Document: `{"type":"Point","coordinates":null}`, Expected: `{"type":"Point","coordinates":[]}`}
+	if err := check(Package{}, expected); err != nil {
+		t.Errorf("should pass, but got %v", err)
+	}
+
+	invalid := Fixture{Name: "unknown type", Document: `{"type":"Circle","coordinates":[1,2]}`}
+	if err := check(Package{Options: geojson.DecodeOptions{Strict: true}}, invalid); err != nil {
+		t.Errorf("should pass, but got %v", err)
+	}
+	if err := check(Package{}, invalid); err != nil {
+		t.Errorf("should pass, but got %v", err)
+	}
+	if err := check(Package{Options: geojson.DecodeOptions{AllowUnknownGeometryTypes: true}}, invalid); err == nil {
+		t.Errorf("should fail a codec accepting unknown types")
+	}
+}
+
+func TestSameJSON(t *testing.T) {
+	if !sameJSON([]byte(`{"a":1.0,"b":[1, 2]}`), []byte(`{"b":[1,2],"a":1}`)) {
+		t.Errorf("should be the same JSON value")
+	}
+	if sameJSON([]byte(`{"a":1}`), []byte(`{"a":"1"}`)) {
+		t.Errorf("should not be the same JSON value")
+	}
+}
@@ -0,0 +1,55 @@
+package conformance
+
+// Fixtures is the bundled suite of documents run by Run.
+var Fixtures = []Fixture{
+	// geometries
+	{Name: "point", Valid: true, Document: `{"type":"Point","coordinates":[100.0,0.0]}`},
+	{Name: "point with altitude", Valid: true, Document: `{"type":"Point","coordinates":[100.0,0.0,12.5]}`},
+	{Name: "empty point", Valid: true, Document: `{"type":"Point","coordinates":[]}`},
+	{Name: "multi point", Valid: true, Document: `{"type":"MultiPoint","coordinates":[[100.0,0.0],[101.0,1.0]]}`},
+	{Name: "line string", Valid: true, Document: `{"type":"LineString","coordinates":[[100.0,0.0],[101.0,1.0]]}`},
+	{Name: "multi line string", Valid: true, Document: `{"type":"MultiLineString","coordinates":[[[100.0,0.0],[101.0,1.0]],[[102.0,2.0],[103.0,3.0]]]}`},
+	{Name: "polygon", Valid: true, Document: `{"type":"Polygon","coordinates":[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,1.0],[100.0,0.0]]]}`},
+	{Name: "polygon with hole", Valid: true, Document: `{"type":"Polygon","coordinates":[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,1.0],[100.0,0.0]],[[100.8,0.8],[100.8,0.2],[100.2,0.2],[100.2,0.8],[100.8,0.8]]]}`},
+	{Name: "multi polygon", Valid: true, Document: `{"type":"MultiPolygon","coordinates":[[[[102.0,2.0],[103.0,2.0],[103.0,3.0],[102.0,3.0],[102.0,2.0]]],[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,1.0],[100.0,0.0]]]]}`},
+	{Name: "geometry collection", Valid: true, Document: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[100.0,0.0]},{"type":"LineString","coordinates":[[101.0,0.0],[102.0,1.0]]}]}`},
+	{Name: "empty geometry collection", Valid: true, Document: `{"type":"GeometryCollection","geometries":[]}`},
+	{Name: "antimeridian bounding box", Valid: true, Document: `{"type":"MultiPoint","bbox":[177.0,-20.0,-178.0,-16.0],"coordinates":[[177.0,-20.0],[-178.0,-16.0]]}`},
+	{Name: "geometry foreign member", Valid: true, Document: `{"type":"Point","coordinates":[1,2],"title":"summit"}`},
+
+	// features
+	{Name: "feature", Valid: true, Document: `{"type":"Feature","geometry":{"type":"Point","coordinates":[102.0,0.5]},"properties":{"prop0":"value0"}}`},
+	{Name: "feature with string id", Valid: true, Document: `{"type":"Feature","id":"f1","geometry":{"type":"Point","coordinates":[102.0,0.5]},"properties":{"prop0":"value0"}}`},
+	{Name: "feature with number id", Valid: true, Document: `{"type":"Feature","id":42,"geometry":{"type":"Point","coordinates":[102.0,0.5]},"properties":{"prop0":"value0"}}`},
+	{Name: "feature with nested properties", Valid: true, Document: `{"type":"Feature","geometry":{"type":"Point","coordinates":[102.0,0.5]},"properties":{"prop1":{"this":"that"},"prop2":[1,true,null]}}`},
+	{Name: "feature with bounding box", Valid: true, Document: `{"type":"Feature","bbox":[100.0,0.0,101.0,1.0],"geometry":{"type":"LineString","coordinates":[[100.0,0.0],[101.0,1.0]]},"properties":{"a":1}}`},
+	{Name: "feature without geometry", Valid: true, Document: `{"type":"Feature","geometry":null,"properties":{"a":1}}`},
+	{Name: "feature foreign member", Valid: true, Document: `{"type":"Feature","geometry":null,"properties":{"a":1},"title":"Example"}`},
+
+	// feature collections
+	{Name: "feature collection", Valid: true, Document: `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[102.0,0.5]},"properties":{"prop0":"value0"}},{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,1.0],[100.0,0.0]]]},"properties":{"prop1":{"this":"that"}}}]}`},
+	{Name: "empty feature collection", Valid: true, Document: `{"type":"FeatureCollection","features":[]}`},
+	{Name: "feature collection with bounding box", Valid: true, Document: `{"type":"FeatureCollection","bbox":[100.0,0.0,101.0,1.0],"features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[100.0,0.0]},"properties":{"a":1}}]}`},
+
+	// invalid documents
+	{Name: "not JSON", Document: `{"type":"Point","coordinates":[1,2]`},
+	{Name: "trailing data", Document: `{"type":"Point","coordinates":[1,2]} {}`},
+	{Name: "not an object", Document: `[1,2]`},
+	{Name: "unknown type", Document: `{"type":"Circle","coordinates":[1,2]}`},
+	{Name: "lowercase type", Document: `{"type":"point","coordinates":[1,2]}`},
+	{Name: "missing type", Document: `{"coordinates":[1,2]}`},
+	{Name: "missing coordinates", Document: `{"type":"Point"}`},
+	{Name: "string coordinate", Document: `{"type":"Point","coordinates":["1",2]}`},
+	{Name: "single coordinate", Document: `{"type":"Point","coordinates":[1]}`},
+	{Name: "position as point coordinates", Document: `{"type":"LineString","coordinates":[1,2]}`},
+	{Name: "shallow polygon", Document: `{"type":"Polygon","coordinates":[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,0.0]]}`},
+	{Name: "geometry collection member not an object", Document: `{"type":"GeometryCollection","geometries":[[1,2]]}`},
+	{Name: "odd bounding box", Document: `{"type":"Point","bbox":[1,2,3],"coordinates":[1,2]}`},
+	{Name: "feature without geometry member", Document: `{"type":"Feature","properties":{}}`},
+	{Name: "feature without properties member", Document: `{"type":"Feature","geometry":null}`},
+	{Name: "feature with array properties", Document: `{"type":"Feature","geometry":null,"properties":[1]}`},
+	{Name: "feature with invalid geometry", Document: `{"type":"Feature","geometry":{"type":"Point","coordinates":[true,1]},"properties":{}}`},
+	{Name: "features not an array", Document: `{"type":"FeatureCollection","features":{}}`},
+	{Name: "null feature", Document: `{"type":"FeatureCollection","features":[null]}`},
+	{Name: "geometry in features", Document: `{"type":"FeatureCollection","features":[{"type":"Point","coordinates":[1,2]}]}`},
+}
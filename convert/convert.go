@@ -0,0 +1,103 @@
+/*
+Package convert streams features between the formats supported by the
+geojson packages, detecting the format of the source from its first bytes,
+so converting between any two formats needs no bespoke function.
+
+	src, err := convert.NewReader(in)
+	if err != nil {
+		return err
+	}
+	dst, err := convert.NewWriter(out, convert.GeoJSONSeq)
+	if err != nil {
+		return err
+	}
+	n, err := convert.Stream(dst, src)
+
+GeoJSON feature collections, GeoJSON text sequences and WKT are streamed one
+feature at a time, and FlatGeobuf is read one feature at a time. The other
+formats are decoded or encoded as a whole.
+*/
+package convert
+
+import (
+	"io"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// A Format is an encoding of features.
+type Format string
+
+// The supported formats.
+const (
+	// GeoJSON is a GeoJSON feature collection, or a single feature or
+	// geometry when reading.
+	GeoJSON Format = "geojson"
+
+	// GeoJSONSeq is newline delimited GeoJSON features, one per line.
+	// RFC 8142 text sequences, with record separators, are read too.
+	GeoJSONSeq Format = "geojsonseq"
+
+	// WKT is Well-Known Text, one geometry per line.
+	WKT Format = "wkt"
+
+	// WKB is a single Well-Known Binary or EWKB geometry. The geometries of
+	// several features are written as a geometry collection, without their
+	// properties.
+	WKB Format = "wkb"
+
+	Geobuf   Format = "geobuf"
+	TopoJSON Format = "topojson"
+	EsriJSON Format = "esrijson"
+	KML      Format = "kml"
+	GPX      Format = "gpx"
+
+	// GML is a single GML geometry, only read.
+	GML Format = "gml"
+
+	// CityJSON is a CityJSON city model, only read.
+	CityJSON Format = "cityjson"
+
+	// Shapefile is a zipped shapefile, only read.
+	Shapefile Format = "shapefile"
+
+	// FlatGeobuf is written with a packed R-tree index.
+	FlatGeobuf Format = "flatgeobuf"
+)
+
+// A Reader reads the features of a dataset one at a time.
+type Reader interface {
+	// Read returns the next feature, or io.EOF after the last one.
+	Read() (*geojson.Feature, error)
+}
+
+// A Writer writes features in a format.
+type Writer interface {
+	// Write writes the feature.
+	Write(f *geojson.Feature) error
+
+	// Close completes the encoding, without closing the underlying writer.
+	Close() error
+}
+
+// Stream copies all the features of src to dst, then closes dst, and
+// returns the number of features copied.
+func Stream(dst Writer, src Reader) (int, error) {
+	n := 0
+	for {
+		f, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+
+		if err := dst.Write(f); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, dst.Close()
+}
@@ -0,0 +1,56 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestStream(t *testing.T) {
+	src, err := NewReader(strings.NewReader("POINT (1 2)\n\nLINESTRING (0 0, 1 1)\n"))
+	if err != nil {
+		t.Fatalf("should detect the format, but got %v", err)
+	}
+
+	var buf bytes.Buffer
+	dst, err := NewWriter(&buf, GeoJSONSeq)
+	if err != nil {
+		t.Fatalf("should create the writer, but got %v", err)
+	}
+
+	n, err := Stream(dst, src)
+	if err != nil {
+		t.Fatalf("should stream, but got %v", err)
+	}
+	if n != 2 {
+		t.Errorf("should copy 2 features, got %d", n)
+	}
+
+	expected := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}` + "\n" +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":null}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("incorrect output, got %s", buf.String())
+	}
+}
+
+type failingReader struct{ n int }
+
+func (r *failingReader) Read() (*geojson.Feature, error) {
+	if r.n == 0 {
+		return nil, errors.New("broken source")
+	}
+	r.n--
+	return geojson.NewPointFeature([]float64{1, 2}), nil
+}
+
+func TestStreamError(t *testing.T) {
+	dst, _ := NewWriter(ioutil.Discard, GeoJSON)
+	n, err := Stream(dst, &failingReader{n: 3})
+	if err == nil || n != 3 {
+		t.Errorf("should stop at the error after 3 features, got %d, %v", n, err)
+	}
+}
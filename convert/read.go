@@ -0,0 +1,440 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/cityjson"
+	"github.com/fmechant/go.geojson/fgb"
+	"github.com/fmechant/go.geojson/geobuf"
+	"github.com/fmechant/go.geojson/gml"
+	"github.com/fmechant/go.geojson/gpx"
+	"github.com/fmechant/go.geojson/kml"
+	"github.com/fmechant/go.geojson/shp"
+	"github.com/fmechant/go.geojson/topojson"
+)
+
+// sniffSize is the number of bytes looked at to detect the format.
+const sniffSize = 4096
+
+// recordSeparator starts the records of RFC 8142 text sequences.
+const recordSeparator = 0x1e
+
+// NewReader detects the format of the source and returns a reader of its
+// features. Gzip compressed sources are decompressed.
+func NewReader(r io.Reader) (Reader, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	if head, _ := br.Peek(2); len(head) == 2 && head[0] == 0x1f && head[1] == 0x8b {
+		z, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReaderSize(z, sniffSize)
+	}
+
+	format, sure, err := detect(br)
+	if err != nil {
+		return nil, err
+	}
+	if !sure {
+		// the members telling the JSON formats apart are further on
+		data, err := ioutil.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		format, _ = detectJSON(bytes.TrimLeft(data, " \t\r\n\ufeff"))
+		return NewFormatReader(bytes.NewReader(data), format)
+	}
+	return NewFormatReader(br, format)
+}
+
+// Detect returns the format of the data read by br, from its first bytes,
+// which are peeked and not consumed. JSON objects whose type is not in the
+// first bytes are reported as GeoJSON; NewReader reads them as a whole to
+// tell their format.
+func Detect(br *bufio.Reader) (Format, error) {
+	format, _, err := detect(br)
+	return format, err
+}
+
+// detect returns the format of the data read by br, and false if the first
+// bytes are not enough to be sure of it.
+func detect(br *bufio.Reader) (Format, bool, error) {
+	head, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", false, err
+	}
+	if len(head) == 0 {
+		return "", false, errors.New("no data to detect the format of")
+	}
+
+	text := bytes.TrimLeft(head, " \t\r\n\ufeff")
+	if len(text) > 0 {
+		switch text[0] {
+		case recordSeparator:
+			return GeoJSONSeq, true, nil
+		case '{':
+			format, sure := detectJSON(text)
+			return format, sure, nil
+		case '<':
+			return detectXML(text), true, nil
+		}
+
+		word := text
+		if len(word) > 20 {
+			word = word[:20]
+		}
+		for _, prefix := range []string{"SRID=", "POINT", "LINESTRING", "POLYGON", "MULTI", "GEOMETRYCOLLECTION"} {
+			if bytes.HasPrefix(bytes.ToUpper(word), []byte(prefix)) {
+				return WKT, true, nil
+			}
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return Shapefile, true, nil
+	case bytes.HasPrefix(head, []byte("fgb")):
+		return FlatGeobuf, true, nil
+	case head[0] == 0 || head[0] == 1:
+		return WKB, true, nil
+	case head[0] == 0x0a || head[0] == 0x10 || head[0] == 0x18 || head[0] == 0x22 || head[0] == 0x2a || head[0] == 0x32:
+		// the first fields of a geobuf message: keys, dimensions,
+		// precision or the data
+		return Geobuf, true, nil
+	}
+
+	return "", false, errors.New("unknown format")
+}
+
+// detectJSON tells the JSON formats apart from the top level members of
+// the first object, as far as they are in the data. It returns false if
+// the object is cut before its type, GeoJSON being then only a guess.
+func detectJSON(data []byte) (Format, bool) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.Token() // {
+
+	members := make(map[string]bool)
+	var typ string
+	complete := false
+	for {
+		if !d.More() {
+			_, err := d.Token() // }
+			complete = err == nil
+			break
+		}
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		key, _ := tok.(string)
+		members[key] = true
+
+		if key == "type" {
+			if err := d.Decode(&typ); err != nil {
+				break
+			}
+			continue
+		}
+		var skip json.RawMessage
+		if err := d.Decode(&skip); err != nil {
+			break
+		}
+	}
+
+	switch typ {
+	case "FeatureCollection":
+		return GeoJSON, true
+	case "Topology":
+		return TopoJSON, true
+	case "CityJSON":
+		return CityJSON, true
+	case "":
+	default:
+		// a single feature or geometry, possibly followed by others
+		return GeoJSONSeq, true
+	}
+
+	if !complete {
+		return GeoJSON, false
+	}
+	for _, esri := range []string{"geometryType", "spatialReference", "objectIdFieldName", "attributes", "rings", "paths", "x"} {
+		if members[esri] {
+			return EsriJSON, true
+		}
+	}
+	return GeoJSON, true
+}
+
+// detectXML tells the XML formats apart from the name of the root element.
+func detectXML(data []byte) Format {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return GML
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "gpx":
+				return GPX
+			case "kml":
+				return KML
+			}
+			return GML
+		}
+	}
+}
+
+// NewFormatReader returns a reader of the features of the source in the
+// given format.
+func NewFormatReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case GeoJSON:
+		return newCollectionReader(r)
+	case GeoJSONSeq:
+		return &seqReader{d: json.NewDecoder(&separatorFilter{r})}, nil
+	case WKT:
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 64<<20)
+		return &wktReader{s: s}, nil
+	case FlatGeobuf:
+		return fgb.NewReader(r)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var fc *geojson.FeatureCollection
+	switch format {
+	case WKB:
+		var g *geojson.Geometry
+		if g, err = geojson.UnmarshalWKB(data); err == nil {
+			fc = geojson.NewFeatureCollection().AddFeature(geojson.NewFeature(g))
+		}
+	case GML:
+		var g *geojson.Geometry
+		if g, err = gml.Unmarshal(data, gml.Options{}); err == nil {
+			fc = geojson.NewFeatureCollection().AddFeature(geojson.NewFeature(g))
+		}
+	case Geobuf:
+		fc, err = geobuf.UnmarshalFeatureCollection(data)
+	case TopoJSON:
+		fc, err = topojson.UnmarshalTopoJSON(data)
+	case EsriJSON:
+		fc, err = geojson.FromEsriJSON(data)
+	case KML:
+		fc, err = kml.Unmarshal(data)
+	case GPX:
+		fc, err = gpx.Unmarshal(data)
+	case CityJSON:
+		fc, err = cityjson.Decode(data)
+	case Shapefile:
+		fc, err = shp.DecodeZip(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sliceReader{features: fc.Features}, nil
+}
+
+// A sliceReader reads features decoded as a whole.
+type sliceReader struct {
+	features []*geojson.Feature
+}
+
+func (r *sliceReader) Read() (*geojson.Feature, error) {
+	if len(r.features) == 0 {
+		return nil, io.EOF
+	}
+	f := r.features[0]
+	r.features = r.features[1:]
+	return f, nil
+}
+
+// A collectionReader streams the features of a GeoJSON feature collection.
+// Objects of other types are errors, not empty collections.
+type collectionReader struct {
+	d          *json.Decoder
+	inFeatures bool
+	done       bool
+	index      int
+	typ        string
+}
+
+func newCollectionReader(r io.Reader) (*collectionReader, error) {
+	d := json.NewDecoder(r)
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("feature collection must be an object, got %v", tok)
+	}
+	return &collectionReader{d: d}, nil
+}
+
+func (r *collectionReader) Read() (*geojson.Feature, error) {
+	for !r.done {
+		if r.inFeatures {
+			if r.d.More() {
+				var raw json.RawMessage
+				if err := r.d.Decode(&raw); err != nil {
+					return nil, err
+				}
+				if string(raw) == "null" {
+					r.index++
+					continue
+				}
+				f, err := geojson.UnmarshalFeature(raw)
+				if err != nil {
+					return nil, fmt.Errorf("feature %d: %v", r.index, err)
+				}
+				r.index++
+				return f, nil
+			}
+
+			if _, err := r.d.Token(); err != nil { // ]
+				return nil, err
+			}
+			r.inFeatures = false
+			continue
+		}
+
+		tok, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == json.Delim('}') {
+			r.done = true
+			if r.typ != "FeatureCollection" {
+				return nil, fmt.Errorf("not a feature collection, type %q", r.typ)
+			}
+			break
+		}
+
+		if tok == "type" {
+			if err := r.d.Decode(&r.typ); err != nil {
+				return nil, err
+			}
+			if r.typ != "FeatureCollection" {
+				r.done = true
+				return nil, fmt.Errorf("not a feature collection, type %q", r.typ)
+			}
+			continue
+		}
+
+		if tok == "features" {
+			if tok, err = r.d.Token(); err != nil {
+				return nil, err
+			}
+			if tok == nil {
+				continue
+			}
+			if tok != json.Delim('[') {
+				return nil, fmt.Errorf("features must be an array, got %v", tok)
+			}
+			r.inFeatures = true
+			continue
+		}
+
+		var skip json.RawMessage
+		if err := r.d.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// A seqReader reads a sequence of GeoJSON features or geometries.
+type seqReader struct {
+	d     *json.Decoder
+	index int
+}
+
+func (r *seqReader) Read() (*geojson.Feature, error) {
+	var raw json.RawMessage
+	if err := r.d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	r.index++
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("feature %d: %v", r.index-1, err)
+	}
+
+	if object.Type == "Feature" {
+		f, err := geojson.UnmarshalFeature(raw)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", r.index-1, err)
+		}
+		return f, nil
+	}
+
+	g, err := geojson.UnmarshalGeometry(raw)
+	if err != nil {
+		return nil, fmt.Errorf("feature %d: %v", r.index-1, err)
+	}
+	return geojson.NewFeature(g), nil
+}
+
+// A separatorFilter turns the record separators of RFC 8142 text
+// sequences into white space.
+type separatorFilter struct {
+	r io.Reader
+}
+
+func (f *separatorFilter) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == recordSeparator {
+			p[i] = ' '
+		}
+	}
+	return n, err
+}
+
+// A wktReader reads a WKT geometry per line, skipping empty lines.
+type wktReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func (r *wktReader) Read() (*geojson.Feature, error) {
+	for r.s.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.s.Text())
+		if text == "" {
+			continue
+		}
+
+		g, err := geojson.UnmarshalWKT(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		return geojson.NewFeature(g), nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
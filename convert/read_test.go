@@ -0,0 +1,181 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/geobuf"
+)
+
+func TestDetect(t *testing.T) {
+	point := geojson.NewPointGeometry([]float64{1, 2})
+	wkb, _ := point.MarshalWKB()
+	pbf, _ := geobuf.MarshalGeometry(point)
+
+	cases := []struct {
+		data   string
+		format Format
+	}{
+		{`{"type":"FeatureCollection","features":[]}`, GeoJSON},
+		{"\n\t{\"features\":[],\"type\":\"FeatureCollection\"}", GeoJSON},
+		{`{"type":"Feature","geometry":null,"properties":null}`, GeoJSONSeq},
+		{`{"type":"Point","coordinates":[1,2]}`, GeoJSONSeq},
+		{"\x1e{\"type\":\"Feature\"}", GeoJSONSeq},
+		{`{"type":"Topology","objects":{},"arcs":[]}`, TopoJSON},
+		{`{"type":"CityJSON","version":"1.1"}`, CityJSON},
+		{`{"geometryType":"esriGeometryPoint","features":[]}`, EsriJSON},
+		{`{"x":1,"y":2}`, EsriJSON},
+		{`<?xml version="1.0"?><gpx version="1.1"></gpx>`, GPX},
+		{`<kml xmlns="http://www.opengis.net/kml/2.2"></kml>`, KML},
+		{`<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"></gml:Point>`, GML},
+		{"point (1 2)", WKT},
+		{"SRID=4326;POINT(1 2)", WKT},
+		{string(wkb), WKB},
+		{string(pbf), Geobuf},
+		{"PK\x03\x04rest", Shapefile},
+		{"fgb\x03fgb\x00", FlatGeobuf},
+	}
+
+	for _, tc := range cases {
+		format, err := Detect(bufio.NewReader(strings.NewReader(tc.data)))
+		if err != nil {
+			t.Errorf("should detect %q, but got %v", tc.data, err)
+			continue
+		}
+		if format != tc.format {
+			t.Errorf("incorrect format of %q, expected %s, got %s", tc.data, tc.format, format)
+		}
+	}
+
+	for _, data := range []string{"", "   ", "hello"} {
+		if _, err := Detect(bufio.NewReader(strings.NewReader(data))); err == nil {
+			t.Errorf("should not detect %q", data)
+		}
+	}
+}
+
+func readAll(t *testing.T, r Reader) []*geojson.Feature {
+	var features []*geojson.Feature
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			return features
+		}
+		if err != nil {
+			t.Fatalf("should read, but got %v", err)
+		}
+		features = append(features, f)
+	}
+}
+
+func TestCollectionReader(t *testing.T) {
+	data := `{"type":"FeatureCollection","bbox":[0,0,1,1],"features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{"a":1}},
+		null,
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,1]},"properties":{"a":2}}
+	],"crs":null}`
+
+	r, err := NewReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("should create the reader, but got %v", err)
+	}
+	features := readAll(t, r)
+	if len(features) != 2 || features[1].PropertyMustFloat64("a") != 2 {
+		t.Errorf("incorrect features, got %v", features)
+	}
+
+	r, _ = NewFormatReader(strings.NewReader(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":"x"}}]}`), GeoJSON)
+	if _, err := r.Read(); err == nil || !strings.HasPrefix(err.Error(), "feature 0: ") {
+		t.Errorf("should reject the invalid feature, got %v", err)
+	}
+
+	r, _ = NewFormatReader(strings.NewReader(`{"geometry":null,"properties":null,"type":"Feature"}`), GeoJSON)
+	if _, err := r.Read(); err == nil || err == io.EOF {
+		t.Errorf("should reject a feature as a collection, got %v", err)
+	}
+}
+
+func TestReaderLateType(t *testing.T) {
+	line := make([][]float64, 1000)
+	for i := range line {
+		line[i] = []float64{float64(i) + 0.123456, 1.123456}
+	}
+	g, _ := geojson.NewLineStringGeometry(line).MarshalJSON()
+	data := `{"geometry":` + string(g) + `,"properties":{"name":"late"},"type":"Feature"}`
+	if len(data) <= sniffSize {
+		t.Fatalf("should have the type after the sniffed bytes, got %d bytes", len(data))
+	}
+
+	r, err := NewReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("should create the reader, but got %v", err)
+	}
+	features := readAll(t, r)
+	if len(features) != 1 || features[0].PropertyMustString("name") != "late" || len(features[0].Geometry.LineString) != 1000 {
+		t.Errorf("incorrect features, got %v", features)
+	}
+}
+
+func TestSeqReader(t *testing.T) {
+	data := "\x1e{\"type\":\"Feature\",\"geometry\":null,\"properties\":{\"a\":1}}\n" +
+		"\x1e{\"type\":\"Point\",\"coordinates\":[1,2]}\n"
+
+	r, err := NewReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("should create the reader, but got %v", err)
+	}
+	features := readAll(t, r)
+	if len(features) != 2 || features[0].PropertyMustFloat64("a") != 1 || !features[1].Geometry.IsPoint() {
+		t.Errorf("incorrect features, got %v", features)
+	}
+}
+
+func TestReaderGzip(t *testing.T) {
+	var buf bytes.Buffer
+	z := gzip.NewWriter(&buf)
+	z.Write([]byte("POINT (1 2)\nPOINT (3 4)\n"))
+	z.Close()
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("should create the reader, but got %v", err)
+	}
+	if features := readAll(t, r); len(features) != 2 {
+		t.Errorf("should read 2 features, got %d", len(features))
+	}
+}
+
+func TestWholeReaders(t *testing.T) {
+	point := geojson.NewPointGeometry([]float64{1, 2})
+	wkb, _ := point.MarshalWKB()
+
+	cases := map[string]string{
+		"wkb":     string(wkb),
+		"gml":     `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>2 1</gml:pos></gml:Point>`,
+		"esri":    `{"x":1,"y":2}`,
+		"gpx":     `<gpx><wpt lat="2" lon="1"/></gpx>`,
+		"kml":     `<kml><Placemark><Point><coordinates>1,2</coordinates></Point></Placemark></kml>`,
+		"geojson": `{"type":"Point","coordinates":[1,2]}`,
+	}
+
+	for name, data := range cases {
+		r, err := NewReader(strings.NewReader(data))
+		if err != nil {
+			t.Errorf("should create the %s reader, but got %v", name, err)
+			continue
+		}
+		features := readAll(t, r)
+		if len(features) != 1 || !features[0].Geometry.Equal(point) {
+			t.Errorf("incorrect %s features, got %v", name, features)
+		}
+	}
+
+	if _, err := NewFormatReader(strings.NewReader(""), Format("shp")); err == nil {
+		t.Errorf("should reject an unknown format")
+	}
+}
@@ -0,0 +1,194 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/fgb"
+	"github.com/fmechant/go.geojson/geobuf"
+	"github.com/fmechant/go.geojson/gpx"
+	"github.com/fmechant/go.geojson/kml"
+	"github.com/fmechant/go.geojson/topojson"
+)
+
+// NewWriter returns a writer of features in the given format. GeoJSON,
+// GeoJSONSeq and WKT are written as the features come; the features of the
+// other formats are kept until Close encodes them.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case GeoJSON:
+		return &collectionWriter{w: w}, nil
+	case GeoJSONSeq:
+		return &seqWriter{w: w}, nil
+	case WKT:
+		return &wktWriter{w: w}, nil
+	}
+
+	var encode func(fc *geojson.FeatureCollection) ([]byte, error)
+	switch format {
+	case Geobuf:
+		encode = geobuf.MarshalFeatureCollection
+	case TopoJSON:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			t, err := topojson.Encode(fc, topojson.Options{})
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(t)
+		}
+	case EsriJSON:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			return geojson.ToEsriJSON(fc, 0)
+		}
+	case KML:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			return kml.Marshal(fc, kml.Options{})
+		}
+	case GPX:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			return gpx.Marshal(fc, gpx.Options{})
+		}
+	case FlatGeobuf:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			return fgb.Marshal(fc, fgb.Options{})
+		}
+	case WKB:
+		encode = func(fc *geojson.FeatureCollection) ([]byte, error) {
+			var geometries []*geojson.Geometry
+			for _, f := range fc.Features {
+				if f != nil && f.Geometry != nil {
+					geometries = append(geometries, f.Geometry)
+				}
+			}
+			if len(geometries) == 1 {
+				return geometries[0].MarshalWKB()
+			}
+			return geojson.NewCollectionGeometry(geometries...).MarshalWKB()
+		}
+	case GML, CityJSON, Shapefile:
+		return nil, fmt.Errorf("format %s can not be written", format)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	return &bufferedWriter{w: w, fc: geojson.NewFeatureCollection(), encode: encode}, nil
+}
+
+// errClosed is returned when writing to a closed writer.
+var errClosed = errors.New("write to a closed writer")
+
+// A collectionWriter writes a GeoJSON feature collection, feature by feature.
+type collectionWriter struct {
+	w       io.Writer
+	started bool
+	closed  bool
+}
+
+func (cw *collectionWriter) Write(f *geojson.Feature) error {
+	if cw.closed {
+		return errClosed
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	prefix := ","
+	if !cw.started {
+		prefix = `{"type":"FeatureCollection","features":[`
+		cw.started = true
+	}
+	if _, err := io.WriteString(cw.w, prefix); err != nil {
+		return err
+	}
+	_, err = cw.w.Write(data)
+	return err
+}
+
+func (cw *collectionWriter) Close() error {
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+
+	suffix := "]}"
+	if !cw.started {
+		suffix = `{"type":"FeatureCollection","features":[]}`
+	}
+	_, err := io.WriteString(cw.w, suffix)
+	return err
+}
+
+// A seqWriter writes a GeoJSON feature per line.
+type seqWriter struct {
+	w io.Writer
+}
+
+func (sw *seqWriter) Write(f *geojson.Feature) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	_, err = sw.w.Write(append(data, '\n'))
+	return err
+}
+
+func (sw *seqWriter) Close() error {
+	return nil
+}
+
+// A wktWriter writes the WKT of a geometry per line, skipping the features
+// without geometry. The properties are lost.
+type wktWriter struct {
+	w io.Writer
+}
+
+func (ww *wktWriter) Write(f *geojson.Feature) error {
+	if f == nil || f.Geometry == nil {
+		return nil
+	}
+
+	s, err := f.Geometry.ToWKT()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(ww.w, s+"\n")
+	return err
+}
+
+func (ww *wktWriter) Close() error {
+	return nil
+}
+
+// A bufferedWriter collects the features to encode them as a whole.
+type bufferedWriter struct {
+	w      io.Writer
+	fc     *geojson.FeatureCollection
+	encode func(fc *geojson.FeatureCollection) ([]byte, error)
+}
+
+func (bw *bufferedWriter) Write(f *geojson.Feature) error {
+	if bw.fc == nil {
+		return errClosed
+	}
+	bw.fc.Features = append(bw.fc.Features, f)
+	return nil
+}
+
+func (bw *bufferedWriter) Close() error {
+	if bw.fc == nil {
+		return nil
+	}
+
+	data, err := bw.encode(bw.fc)
+	bw.fc = nil
+	if err != nil {
+		return err
+	}
+	_, err = bw.w.Write(data)
+	return err
+}
@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestCollectionWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, GeoJSON)
+	if err := w.Close(); err != nil {
+		t.Fatalf("should close, but got %v", err)
+	}
+	if buf.String() != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("incorrect empty collection, got %s", buf.String())
+	}
+
+	buf.Reset()
+	w, _ = NewWriter(&buf, GeoJSON)
+	w.Write(geojson.NewPointFeature([]float64{1, 2}))
+	w.Write(geojson.NewFeature(nil))
+	w.Close()
+
+	fc, err := geojson.UnmarshalFeatureCollection(buf.Bytes())
+	if err != nil {
+		t.Fatalf("should write a valid collection, but got %v: %s", err, buf.String())
+	}
+	if len(fc.Features) != 2 {
+		t.Errorf("should have 2 features, got %d", len(fc.Features))
+	}
+	if err := w.Write(geojson.NewFeature(nil)); err == nil {
+		t.Errorf("should not write after close")
+	}
+}
+
+func TestWKTWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, WKT)
+	w.Write(geojson.NewPointFeature([]float64{1, 2}))
+	w.Write(geojson.NewFeature(nil))
+	w.Close()
+
+	if buf.String() != "POINT (1 2)\n" {
+		t.Errorf("incorrect WKT, got %q", buf.String())
+	}
+}
+
+func TestRoundTripFormats(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	f := geojson.NewPointFeature([]float64{4.35, 50.85})
+	f.SetProperty("name", "Brussels")
+	fc.AddFeature(f)
+
+	for _, format := range []Format{GeoJSON, GeoJSONSeq, Geobuf, TopoJSON, EsriJSON, KML, GPX, FlatGeobuf} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format)
+		if err != nil {
+			t.Fatalf("should create the %s writer, but got %v", format, err)
+		}
+		if _, err := Stream(w, &sliceReader{features: fc.Features}); err != nil {
+			t.Fatalf("should write %s, but got %v", format, err)
+		}
+
+		r, err := NewReader(&buf)
+		if err != nil {
+			t.Fatalf("should detect %s, but got %v", format, err)
+		}
+		features := readAll(t, r)
+		if len(features) != 1 || !features[0].Geometry.EqualWithTolerance(f.Geometry, 1e-6) {
+			t.Errorf("should round trip %s, got %v", format, features)
+			continue
+		}
+		if name, _ := features[0].PropertyString("name"); name != "Brussels" {
+			t.Errorf("should round trip the properties of %s, got %v", format, features[0].Properties)
+		}
+	}
+}
+
+func TestWKBWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, WKB)
+	w.Write(geojson.NewPointFeature([]float64{1, 2}))
+	w.Write(geojson.NewFeature(nil))
+	w.Write(geojson.NewLineStringFeature([][]float64{{0, 0}, {1, 1}}))
+	if err := w.Close(); err != nil {
+		t.Fatalf("should close, but got %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("should detect WKB, but got %v", err)
+	}
+	features := readAll(t, r)
+	if len(features) != 1 || !features[0].Geometry.IsCollection() || len(features[0].Geometry.Geometries) != 2 {
+		t.Errorf("should write the geometries as a collection, got %v", features)
+	}
+}
+
+func TestNewWriterUnsupported(t *testing.T) {
+	for _, format := range []Format{GML, Shapefile, Format("svg")} {
+		if _, err := NewWriter(&strings.Builder{}, format); err == nil {
+			t.Errorf("should not write %s", format)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package geojson
+
+import (
+	"fmt"
+)
+
+// A TopologyErrorKind classifies the problems found by CheckCoverage.
+type TopologyErrorKind int
+
+// The kinds of topology errors.
+const (
+	TopologyOverlap TopologyErrorKind = iota
+	TopologyGap
+)
+
+// String returns the name of the kind.
+func (k TopologyErrorKind) String() string {
+	if k == TopologyGap {
+		return "gap"
+	}
+	return "overlap"
+}
+
+// A TopologyError is a problem found in a polygon coverage.
+type TopologyError struct {
+	Kind TopologyErrorKind
+
+	// Features are the indexes in the collection of the offending features:
+	// the overlapping pair, or the features bordering a gap.
+	Features []int
+
+	// Geometry locates the problem: a point inside the overlap,
+	// or the polygon of the gap.
+	Geometry *Geometry
+}
+
+// Error describes the topology error.
+func (e TopologyError) Error() string {
+	return fmt.Sprintf("coverage %s between features %v", e.Kind, e.Features)
+}
+
+// CheckCoverage checks that the polygons of the collection tile a region
+// exactly, like census tracts or parcels do, and reports the overlaps
+// between pairs of features and the gaps between them.
+//
+// Gaps are found by cancelling out the edges shared by neighbouring
+// polygons, so the coverage must be noded: neighbours must have the same
+// vertices along their common boundary. Holes in the coverage, including
+// polygon holes not filled by another feature, are reported as gaps.
+func CheckCoverage(fc *FeatureCollection) []TopologyError {
+	type part struct {
+		feature int
+		polygon [][][]float64
+		bbox    []float64
+	}
+
+	var parts []part
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+		for _, p := range polygons(f.Geometry) {
+			if len(p) == 0 || len(p[0]) == 0 {
+				continue
+			}
+			parts = append(parts, part{feature: i, polygon: p, bbox: boundingBox(NewPolygonGeometry(p))})
+		}
+	}
+
+	var errs []TopologyError
+
+	reported := make(map[[2]int]bool)
+	for i := 0; i < len(parts); i++ {
+		for j := i + 1; j < len(parts); j++ {
+			a, b := parts[i], parts[j]
+			if a.feature == b.feature || reported[[2]int{a.feature, b.feature}] {
+				continue
+			}
+			if a.bbox[0] >= b.bbox[2] || b.bbox[0] >= a.bbox[2] || a.bbox[1] >= b.bbox[3] || b.bbox[1] >= a.bbox[3] {
+				continue
+			}
+
+			if p := polygonsOverlap(a.polygon, b.polygon); p != nil {
+				reported[[2]int{a.feature, b.feature}] = true
+				errs = append(errs, TopologyError{
+					Kind:     TopologyOverlap,
+					Features: []int{a.feature, b.feature},
+					Geometry: NewPointGeometry(p),
+				})
+			}
+		}
+	}
+
+	// Orient all rings consistently, exteriors counterclockwise and holes
+	// clockwise, so the edge shared by two neighbours is walked in opposite
+	// directions and cancels out. What remains is the boundary of the union,
+	// with its holes clockwise.
+	type edge struct {
+		from, to [2]float64
+	}
+	owners := make(map[edge][]int)
+	for _, p := range parts {
+		for r, ring := range p.polygon {
+			ccw := ringArea2D(ring) > 0
+			reverse := ccw != (r == 0)
+			forEachPathSegment(ring, func(a, b []float64) {
+				e := edge{[2]float64{a[0], a[1]}, [2]float64{b[0], b[1]}}
+				if reverse {
+					e.from, e.to = e.to, e.from
+				}
+				if e.from == e.to {
+					return
+				}
+
+				twin := edge{e.to, e.from}
+				if len(owners[twin]) > 0 {
+					owners[twin] = owners[twin][1:]
+					if len(owners[twin]) == 0 {
+						delete(owners, twin)
+					}
+					return
+				}
+				owners[e] = append(owners[e], p.feature)
+			})
+		}
+	}
+
+	outgoing := make(map[[2]float64][]edge)
+	for e, o := range owners {
+		for range o {
+			outgoing[e.from] = append(outgoing[e.from], e)
+		}
+	}
+
+	starts := make([][]float64, 0, len(outgoing))
+	for start := range outgoing {
+		starts = append(starts, []float64{start[0], start[1]})
+	}
+	sortPositions(starts)
+
+	for _, s := range starts {
+		start := [2]float64{s[0], s[1]}
+		for len(outgoing[start]) > 0 {
+			ring := [][]float64{{start[0], start[1]}}
+			features := make(map[int]bool)
+
+			at := start
+			for {
+				out := outgoing[at]
+				if len(out) == 0 {
+					break
+				}
+				e := out[len(out)-1]
+				outgoing[at] = out[:len(out)-1]
+
+				for _, f := range owners[e] {
+					features[f] = true
+				}
+				ring = append(ring, []float64{e.to[0], e.to[1]})
+				at = e.to
+				if at == start {
+					break
+				}
+			}
+
+			if area := ringArea2D(ring); area >= 0 || at != start {
+				continue
+			}
+
+			reverseRing(ring)
+			gap := TopologyError{Kind: TopologyGap, Geometry: NewPolygonGeometry([][][]float64{ring})}
+			for i := range fc.Features {
+				if features[i] {
+					gap.Features = append(gap.Features, i)
+				}
+			}
+			errs = append(errs, gap)
+		}
+	}
+
+	return errs
+}
+
+// polygonsOverlap returns a point inside both polygons if their interiors
+// overlap, or nil if they only touch or are disjoint.
+func polygonsOverlap(a, b [][][]float64) []float64 {
+	for _, ra := range a {
+		for i := 1; i < len(ra); i++ {
+			for _, rb := range b {
+				for j := 1; j < len(rb); j++ {
+					if p := properIntersection(ra[i-1], ra[i], rb[j-1], rb[j]); p != nil {
+						return p
+					}
+				}
+			}
+		}
+	}
+
+	// no crossing edges: one contains a vertex or an edge midpoint of the
+	// other, or the polygons are disjoint or only share boundaries.
+	if p := interiorWitness(a, b); p != nil {
+		return p
+	}
+	if p := interiorWitness(b, a); p != nil {
+		return p
+	}
+
+	// identical polygons only have common boundaries
+	if p := interiorPoint(a); p != nil && PointInPolygonWinding(p, b) == Interior {
+		return p
+	}
+	return nil
+}
+
+// interiorPoint returns a point in the interior of the polygon,
+// or nil if none was found.
+func interiorPoint(polygon [][][]float64) []float64 {
+	ring := polygon[0]
+	for i := 2; i < len(ring); i++ {
+		a, b, c := ring[0], ring[i-1], ring[i]
+		p := []float64{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3}
+		if PointInPolygonWinding(p, polygon) == Interior {
+			return p
+		}
+	}
+	return nil
+}
+
+func interiorWitness(a, b [][][]float64) []float64 {
+	for _, ring := range a {
+		for i := 1; i < len(ring); i++ {
+			if PointInPolygonWinding(ring[i], b) == Interior {
+				return ring[i]
+			}
+			mid := []float64{(ring[i-1][0] + ring[i][0]) / 2, (ring[i-1][1] + ring[i][1]) / 2}
+			if PointInPolygonWinding(mid, b) == Interior {
+				return mid
+			}
+		}
+	}
+	return nil
+}
+
+// properIntersection returns the point where the segments a-b and c-d cross,
+// or nil if they do not cross or only touch.
+func properIntersection(a, b, c, d []float64) []float64 {
+	o1, o2 := isLeft(a, b, c), isLeft(a, b, d)
+	o3, o4 := isLeft(c, d, a), isLeft(c, d, b)
+	if o1*o2 >= 0 || o3*o4 >= 0 {
+		return nil
+	}
+
+	t := o3 / (o3 - o4)
+	return []float64{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+}
+
+// ringArea2D returns the signed planar area of the ring,
+// positive for counterclockwise rings.
+func ringArea2D(ring [][]float64) float64 {
+	a := 0.0
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		p, q := ring[i], ring[(i+1)%n]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a / 2
+}
+
+func reverseRing(ring [][]float64) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
@@ -0,0 +1,71 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func coverageSquare(x, y float64) *Feature {
+	return NewPolygonFeature([][][]float64{{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x, y}}})
+}
+
+func TestCheckCoverageValid(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(coverageSquare(0, 0))
+	fc.AddFeature(coverageSquare(1, 0))
+	fc.AddFeature(coverageSquare(0, 1))
+	fc.AddFeature(coverageSquare(1, 1))
+
+	if errs := CheckCoverage(fc); len(errs) != 0 {
+		t.Errorf("should find no errors, got %v", errs)
+	}
+}
+
+func TestCheckCoverageOverlap(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(coverageSquare(0, 0))
+	fc.AddFeature(coverageSquare(0.5, 0))
+	fc.AddFeature(coverageSquare(0, 0))
+
+	errs := CheckCoverage(fc)
+
+	overlaps := 0
+	for _, e := range errs {
+		if e.Kind == TopologyOverlap {
+			overlaps++
+			if e.Geometry == nil || !e.Geometry.IsPoint() {
+				t.Errorf("should locate the overlap, got %v", e.Geometry)
+			}
+		}
+	}
+
+	if overlaps != 3 {
+		t.Errorf("should find 3 overlapping pairs, got %v", errs)
+	}
+}
+
+func TestCheckCoverageGap(t *testing.T) {
+	fc := NewFeatureCollection()
+	for x := 0.0; x < 3; x++ {
+		for y := 0.0; y < 3; y++ {
+			if x != 1 || y != 1 {
+				fc.AddFeature(coverageSquare(x, y))
+			}
+		}
+	}
+
+	errs := CheckCoverage(fc)
+	if len(errs) != 1 {
+		t.Fatalf("should find 1 gap, got %v", errs)
+	}
+
+	gap := errs[0]
+	if gap.Kind != TopologyGap {
+		t.Errorf("should be a gap, got %v", gap.Kind)
+	}
+	if len(gap.Features) != 4 {
+		t.Errorf("should report the 4 bordering features, got %v", gap.Features)
+	}
+	if a := ringArea2D(gap.Geometry.Polygon[0]); a != 1 {
+		t.Errorf("gap should be the missing square, got area %v", a)
+	}
+}
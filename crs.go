@@ -0,0 +1,198 @@
+package geojson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CRS84 is the name of the WGS 84 longitude/latitude coordinate reference
+// system of GeoJSON, EPSG:4326 with longitudes first.
+const CRS84 = "urn:ogc:def:crs:OGC:1.3:CRS84"
+
+// A CRS is a coordinate reference system object of the 2008 GeoJSON
+// specification, a NamedCRS or a LinkedCRS. The CRS members of geometries,
+// features and collections hold their JSON objects, parsed by ParseCRS.
+type CRS interface {
+	// EPSG returns the EPSG code of the coordinate reference system, false
+	// if it can not be derived from the object.
+	EPSG() (int, bool)
+
+	// Object returns the JSON object of the coordinate reference system.
+	Object() map[string]interface{}
+}
+
+// A NamedCRS is a coordinate reference system identified by its name, like
+// "urn:ogc:def:crs:EPSG::3857".
+type NamedCRS struct {
+	Name string
+}
+
+// NewEPSGCRS creates and initializes a named coordinate reference system
+// for the EPSG code, named like "EPSG:3857".
+func NewEPSGCRS(code int) NamedCRS {
+	return NamedCRS{Name: fmt.Sprintf("EPSG:%d", code)}
+}
+
+// EPSG returns the EPSG code of the name, in the "EPSG:3857" and OGC URN
+// and URL forms, 4326 for CRS84.
+func (c NamedCRS) EPSG() (int, bool) {
+	if c.IsCRS84() {
+		return 4326, true
+	}
+	return epsgCode(c.Name)
+}
+
+// IsCRS84 returns true if the name is the one of the default coordinate
+// reference system of GeoJSON, see CRS84, in any of its forms.
+func (c NamedCRS) IsCRS84() bool {
+	switch strings.ToLower(c.Name) {
+	case "urn:ogc:def:crs:ogc:1.3:crs84", "urn:ogc:def:crs:ogc::crs84", "http://www.opengis.net/def/crs/ogc/1.3/crs84", "crs84":
+		return true
+	}
+	return false
+}
+
+// Object returns the JSON object of the named coordinate reference system.
+func (c NamedCRS) Object() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "name",
+		"properties": map[string]interface{}{"name": c.Name},
+	}
+}
+
+// A LinkedCRS is a coordinate reference system described at a link, in a
+// format given by Type, like "proj4" or "ogcwkt", if known.
+type LinkedCRS struct {
+	Href string
+	Type string
+}
+
+// EPSG returns the EPSG code found in the link, like in
+// "http://spatialreference.org/ref/epsg/3857/proj4/".
+func (c LinkedCRS) EPSG() (int, bool) {
+	lower := strings.ToLower(c.Href)
+	for _, prefix := range []string{"/epsg/0/", "/epsg/"} {
+		i := strings.Index(lower, prefix)
+		if i < 0 {
+			continue
+		}
+		digits := lower[i+len(prefix):]
+		if j := strings.IndexAny(digits, "/?#."); j >= 0 {
+			digits = digits[:j]
+		}
+		if code, err := strconv.Atoi(digits); err == nil && code > 0 {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// Object returns the JSON object of the linked coordinate reference system.
+func (c LinkedCRS) Object() map[string]interface{} {
+	properties := map[string]interface{}{"href": c.Href}
+	if c.Type != "" {
+		properties["type"] = c.Type
+	}
+	return map[string]interface{}{
+		"type":       "link",
+		"properties": properties,
+	}
+}
+
+// ParseCRS parses the JSON object of a coordinate reference system, as
+// held by the CRS members. It returns nil for a nil or empty object.
+func ParseCRS(object map[string]interface{}) (CRS, error) {
+	if len(object) == 0 {
+		return nil, nil
+	}
+
+	properties, ok := object["properties"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("crs properties must be an object, got %T", object["properties"])
+	}
+
+	switch object["type"] {
+	case "name":
+		name, ok := properties["name"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("named crs without name, got %v", properties["name"])
+		}
+		return NamedCRS{Name: name}, nil
+	case "link":
+		href, ok := properties["href"].(string)
+		if !ok || href == "" {
+			return nil, fmt.Errorf("linked crs without href, got %v", properties["href"])
+		}
+		t, _ := properties["type"].(string)
+		return LinkedCRS{Href: href, Type: t}, nil
+	}
+	return nil, fmt.Errorf("unknown crs type %v", object["type"])
+}
+
+// ParseCRS parses the coordinate reference system of the geometry, see
+// ParseCRS. It returns nil if the geometry has none.
+func (g *Geometry) ParseCRS() (CRS, error) {
+	return ParseCRS(g.CRS)
+}
+
+// SetCRS sets the coordinate reference system of the geometry, removing it
+// if nil.
+func (g *Geometry) SetCRS(c CRS) {
+	g.CRS = crsObject(c)
+}
+
+// ParseCRS parses the coordinate reference system of the feature, see
+// ParseCRS. It returns nil if the feature has none.
+func (f *Feature) ParseCRS() (CRS, error) {
+	return ParseCRS(f.CRS)
+}
+
+// SetCRS sets the coordinate reference system of the feature, removing it
+// if nil.
+func (f *Feature) SetCRS(c CRS) {
+	f.CRS = crsObject(c)
+}
+
+// ParseCRS parses the coordinate reference system of the collection, see
+// ParseCRS. It returns nil if the collection has none.
+func (fc *FeatureCollection) ParseCRS() (CRS, error) {
+	return ParseCRS(fc.CRS)
+}
+
+// SetCRS sets the coordinate reference system of the collection, removing
+// it if nil.
+func (fc *FeatureCollection) SetCRS(c CRS) {
+	fc.CRS = crsObject(c)
+}
+
+func crsObject(c CRS) map[string]interface{} {
+	if c == nil {
+		return nil
+	}
+	return c.Object()
+}
+
+// epsgCode returns the EPSG code of a name like "EPSG:3857",
+// "urn:ogc:def:crs:EPSG::3857", "urn:ogc:def:crs:EPSG:6.6:3857" or
+// "http://www.opengis.net/def/crs/EPSG/0/3857".
+func epsgCode(name string) (int, bool) {
+	lower := strings.ToLower(name)
+	var digits string
+	switch {
+	case strings.HasPrefix(lower, "epsg:"):
+		digits = name[len("epsg:"):]
+	case strings.HasPrefix(lower, "urn:ogc:def:crs:epsg:"):
+		digits = name[strings.LastIndexByte(name, ':')+1:]
+	case strings.HasPrefix(lower, "http://www.opengis.net/def/crs/epsg/"):
+		digits = name[strings.LastIndexByte(name, '/')+1:]
+	default:
+		return 0, false
+	}
+
+	code, err := strconv.Atoi(digits)
+	if err != nil || code <= 0 {
+		return 0, false
+	}
+	return code, true
+}
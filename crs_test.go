@@ -0,0 +1,105 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCRS(t *testing.T) {
+	cases := []struct {
+		name   string
+		object map[string]interface{}
+		crs    CRS
+		epsg   int
+	}{
+		{"none", nil, nil, 0},
+		{"epsg", NewEPSGCRS(3857).Object(), NamedCRS{Name: "EPSG:3857"}, 3857},
+		{"urn", NamedCRS{Name: "urn:ogc:def:crs:EPSG::2154"}.Object(), NamedCRS{Name: "urn:ogc:def:crs:EPSG::2154"}, 2154},
+		{"versioned urn", NamedCRS{Name: "urn:ogc:def:crs:EPSG:6.6:4326"}.Object(), NamedCRS{Name: "urn:ogc:def:crs:EPSG:6.6:4326"}, 4326},
+		{"url", NamedCRS{Name: "http://www.opengis.net/def/crs/EPSG/0/31370"}.Object(), NamedCRS{Name: "http://www.opengis.net/def/crs/EPSG/0/31370"}, 31370},
+		{"crs84", NamedCRS{Name: CRS84}.Object(), NamedCRS{Name: CRS84}, 4326},
+		{"unknown name", NamedCRS{Name: "local grid"}.Object(), NamedCRS{Name: "local grid"}, 0},
+		{"link", map[string]interface{}{
+			"type":       "link",
+			"properties": map[string]interface{}{"href": "http://spatialreference.org/ref/epsg/3857/proj4/", "type": "proj4"},
+		}, LinkedCRS{Href: "http://spatialreference.org/ref/epsg/3857/proj4/", Type: "proj4"}, 3857},
+		{"unknown link", LinkedCRS{Href: "data.crs"}.Object(), LinkedCRS{Href: "data.crs"}, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			crs, err := ParseCRS(c.object)
+			if err != nil {
+				t.Fatalf("should parse, but got %v", err)
+			}
+			if !reflect.DeepEqual(crs, c.crs) {
+				t.Errorf("incorrect crs, got %#v", crs)
+			}
+			if crs == nil {
+				return
+			}
+			if code, ok := crs.EPSG(); code != c.epsg || ok != (c.epsg != 0) {
+				t.Errorf("incorrect EPSG code, got %v, %v", code, ok)
+			}
+			if !reflect.DeepEqual(crs.Object(), c.object) {
+				t.Errorf("incorrect object, got %v", crs.Object())
+			}
+		})
+	}
+}
+
+func TestParseCRSErrors(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"type": "name"},
+		{"type": "name", "properties": map[string]interface{}{"name": 3857}},
+		{"type": "link", "properties": map[string]interface{}{}},
+		{"type": "EPSG", "properties": map[string]interface{}{"code": 3857}},
+	}
+
+	for _, object := range cases {
+		if _, err := ParseCRS(object); err == nil {
+			t.Errorf("should fail to parse %v", object)
+		}
+	}
+}
+
+func TestNamedCRSIsCRS84(t *testing.T) {
+	for _, name := range []string{CRS84, "urn:ogc:def:crs:OGC::CRS84", "http://www.opengis.net/def/crs/OGC/1.3/CRS84"} {
+		if !(NamedCRS{Name: name}).IsCRS84() {
+			t.Errorf("%s should be CRS84", name)
+		}
+	}
+	if (NamedCRS{Name: "EPSG:4326"}).IsCRS84() {
+		t.Errorf("EPSG:4326 should not be CRS84, its axis order differs")
+	}
+}
+
+func TestSetCRS(t *testing.T) {
+	g, err := UnmarshalGeometry([]byte(`{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"urn:ogc:def:crs:EPSG::3857"}}}`))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	crs, err := g.ParseCRS()
+	if err != nil {
+		t.Fatalf("should parse, but got %v", err)
+	}
+	if code, _ := crs.EPSG(); code != 3857 {
+		t.Errorf("incorrect EPSG code, got %d", code)
+	}
+
+	f := NewFeature(g)
+	f.SetCRS(LinkedCRS{Href: "http://example.com/crs"})
+	if crs, err := f.ParseCRS(); err != nil || crs != (LinkedCRS{Href: "http://example.com/crs"}) {
+		t.Errorf("incorrect feature crs, got %v, %v", crs, err)
+	}
+	f.SetCRS(nil)
+	if f.CRS != nil {
+		t.Errorf("should remove the crs")
+	}
+
+	fc := NewFeatureCollection()
+	fc.SetCRS(NewEPSGCRS(2154))
+	if crs, err := fc.ParseCRS(); err != nil || crs != NewEPSGCRS(2154) {
+		t.Errorf("incorrect collection crs, got %v, %v", crs, err)
+	}
+}
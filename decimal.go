@@ -0,0 +1,568 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// A Decimal is a coordinate stored as the exact decimal text found in the
+// source document. It never goes through float64 unless asked to.
+type Decimal string
+
+// NewDecimal creates a decimal from a float, using the shortest representation
+// that round-trips back to the same float64.
+func NewDecimal(f float64) Decimal {
+	return Decimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// String returns the decimal text.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Float64 returns the nearest float64 to the decimal.
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// Rat returns the exact value of the decimal as a rational number.
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, fmt.Errorf("not a valid decimal, got %q", string(d))
+	}
+	return r, nil
+}
+
+// MarshalJSON writes the decimal text unchanged.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if !isJSONNumber(string(d)) {
+		return nil, fmt.Errorf("not a valid decimal, got %q", string(d))
+	}
+	return []byte(d), nil
+}
+
+// A DecimalGeometry mirrors Geometry but keeps every coordinate as a Decimal,
+// so that coordinates round-trip with exactly the text of the source document.
+// This is meant for applications, like cadastral ones, where float64 rounding
+// is not acceptable.
+type DecimalGeometry struct {
+	Type            GeometryType
+	BoundingBox     []Decimal
+	Point           []Decimal
+	MultiPoint      [][]Decimal
+	LineString      [][]Decimal
+	MultiLineString [][][]Decimal
+	Polygon         [][][]Decimal
+	MultiPolygon    [][][][]Decimal
+	Geometries      []*DecimalGeometry
+	CRS             map[string]interface{} // Coordinate Reference System object, see ParseCRS
+}
+
+// UnmarshalDecimalGeometry decodes the data into a decimal geometry.
+// Alternately one can call json.Unmarshal(g) directly for the same result.
+func UnmarshalDecimalGeometry(data []byte) (*DecimalGeometry, error) {
+	g := &DecimalGeometry{}
+	err := json.Unmarshal(data, g)
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// MarshalJSON converts the decimal geometry object into the correct JSON,
+// writing the coordinates exactly as they are stored.
+func (g DecimalGeometry) MarshalJSON() ([]byte, error) {
+	type geometry struct {
+		Type        GeometryType           `json:"type"`
+		BoundingBox []Decimal              `json:"bbox,omitempty"`
+		Coordinates interface{}            `json:"coordinates,omitempty"`
+		Geometries  interface{}            `json:"geometries,omitempty"`
+		CRS         map[string]interface{} `json:"crs,omitempty"`
+	}
+
+	geo := &geometry{
+		Type: g.Type,
+	}
+
+	if len(g.BoundingBox) != 0 {
+		geo.BoundingBox = g.BoundingBox
+	}
+	if len(g.CRS) != 0 {
+		geo.CRS = g.CRS
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		geo.Coordinates = g.Point
+	case GeometryMultiPoint:
+		geo.Coordinates = g.MultiPoint
+	case GeometryLineString:
+		geo.Coordinates = g.LineString
+	case GeometryMultiLineString:
+		geo.Coordinates = g.MultiLineString
+	case GeometryPolygon:
+		geo.Coordinates = g.Polygon
+	case GeometryMultiPolygon:
+		geo.Coordinates = g.MultiPolygon
+	case GeometryCollection:
+		geo.Geometries = g.Geometries
+	}
+
+	return json.Marshal(geo)
+}
+
+// UnmarshalJSON decodes the data into a decimal geometry, keeping the
+// coordinates as the decimal text of the document.
+// This fulfills the json.Unmarshaler interface.
+func (g *DecimalGeometry) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var object map[string]interface{}
+	err := d.Decode(&object)
+	if err != nil {
+		return err
+	}
+
+	return decodeDecimalGeometry(g, object)
+}
+
+// Scan implements the sql.Scanner interface allowing
+// decimal geometry structs to be passed into rows.Scan(...interface{})
+// The columns must be received as GeoJSON Geometry.
+func (g *DecimalGeometry) Scan(value interface{}) error {
+	var data []byte
+
+	switch value.(type) {
+	case string:
+		data = []byte(value.(string))
+	case []byte:
+		data = value.([]byte)
+	default:
+		return errors.New("unable to parse this type into geojson")
+	}
+
+	return g.UnmarshalJSON(data)
+}
+
+// Geometry converts the decimal geometry into a regular float64 geometry.
+func (g *DecimalGeometry) Geometry() (*Geometry, error) {
+	r := &Geometry{Type: g.Type, CRS: g.CRS}
+
+	var err error
+	if r.BoundingBox, err = decimalsToFloats(g.BoundingBox); err != nil {
+		return nil, err
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		r.Point, err = decimalsToFloats(g.Point)
+	case GeometryMultiPoint:
+		r.MultiPoint, err = decimalSetToFloats(g.MultiPoint)
+	case GeometryLineString:
+		r.LineString, err = decimalSetToFloats(g.LineString)
+	case GeometryMultiLineString:
+		r.MultiLineString, err = decimalPathSetToFloats(g.MultiLineString)
+	case GeometryPolygon:
+		r.Polygon, err = decimalPathSetToFloats(g.Polygon)
+	case GeometryMultiPolygon:
+		r.MultiPolygon = make([][][][]float64, 0, len(g.MultiPolygon))
+		for _, p := range g.MultiPolygon {
+			fp, err := decimalPathSetToFloats(p)
+			if err != nil {
+				return nil, err
+			}
+			r.MultiPolygon = append(r.MultiPolygon, fp)
+		}
+	case GeometryCollection:
+		r.Geometries = make([]*Geometry, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			fc, err := c.Geometry()
+			if err != nil {
+				return nil, err
+			}
+			r.Geometries = append(r.Geometries, fc)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewDecimalGeometry converts a float64 geometry into a decimal geometry,
+// using the shortest decimal representation of each coordinate.
+func NewDecimalGeometry(g *Geometry) *DecimalGeometry {
+	r := &DecimalGeometry{
+		Type:        g.Type,
+		CRS:         g.CRS,
+		BoundingBox: floatsToDecimals(g.BoundingBox),
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		r.Point = floatsToDecimals(g.Point)
+	case GeometryMultiPoint:
+		r.MultiPoint = floatSetToDecimals(g.MultiPoint)
+	case GeometryLineString:
+		r.LineString = floatSetToDecimals(g.LineString)
+	case GeometryMultiLineString:
+		r.MultiLineString = floatPathSetToDecimals(g.MultiLineString)
+	case GeometryPolygon:
+		r.Polygon = floatPathSetToDecimals(g.Polygon)
+	case GeometryMultiPolygon:
+		r.MultiPolygon = make([][][][]Decimal, 0, len(g.MultiPolygon))
+		for _, p := range g.MultiPolygon {
+			r.MultiPolygon = append(r.MultiPolygon, floatPathSetToDecimals(p))
+		}
+	case GeometryCollection:
+		r.Geometries = make([]*DecimalGeometry, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			r.Geometries = append(r.Geometries, NewDecimalGeometry(c))
+		}
+	}
+
+	return r
+}
+
+func decodeDecimalGeometry(g *DecimalGeometry, object map[string]interface{}) error {
+	t, ok := object["type"]
+	if !ok {
+		return errors.New("type property not defined")
+	}
+
+	if s, ok := t.(string); ok {
+		g.Type = GeometryType(s)
+	} else {
+		return errors.New("type property not string")
+	}
+
+	var err error
+	if bb, ok := object["bbox"]; ok && bb != nil {
+		if g.BoundingBox, err = decodeDecimalPosition(bb); err != nil {
+			return err
+		}
+	}
+
+	if crs, ok := object["crs"].(map[string]interface{}); ok {
+		g.CRS = crs
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		g.Point, err = decodeDecimalPosition(object["coordinates"])
+	case GeometryMultiPoint:
+		g.MultiPoint, err = decodeDecimalPositionSet(object["coordinates"])
+	case GeometryLineString:
+		g.LineString, err = decodeDecimalPositionSet(object["coordinates"])
+	case GeometryMultiLineString:
+		g.MultiLineString, err = decodeDecimalPathSet(object["coordinates"])
+	case GeometryPolygon:
+		g.Polygon, err = decodeDecimalPathSet(object["coordinates"])
+	case GeometryMultiPolygon:
+		g.MultiPolygon, err = decodeDecimalPolygonSet(object["coordinates"])
+	case GeometryCollection:
+		g.Geometries, err = decodeDecimalGeometries(object["geometries"])
+	}
+
+	return err
+}
+
+func decodeDecimalPosition(data interface{}) ([]Decimal, error) {
+	coords, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid position, got %#v", data)
+	}
+
+	result := make([]Decimal, 0, len(coords))
+	for _, coord := range coords {
+		n, ok := coord.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("not a valid coordinate in %#v, got %T %v", data, coord, coord)
+		}
+		result = append(result, Decimal(n))
+	}
+
+	return result, nil
+}
+
+func decodeDecimalPositionSet(data interface{}) ([][]Decimal, error) {
+	points, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid set of positions, got %v", data)
+	}
+
+	result := make([][]Decimal, 0, len(points))
+	for _, point := range points {
+		p, err := decodeDecimalPosition(point)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func decodeDecimalPathSet(data interface{}) ([][][]Decimal, error) {
+	sets, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid path, got %v", data)
+	}
+
+	result := make([][][]Decimal, 0, len(sets))
+	for _, set := range sets {
+		s, err := decodeDecimalPositionSet(set)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func decodeDecimalPolygonSet(data interface{}) ([][][][]Decimal, error) {
+	polygons, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid polygon, got %v", data)
+	}
+
+	result := make([][][][]Decimal, 0, len(polygons))
+	for _, polygon := range polygons {
+		p, err := decodeDecimalPathSet(polygon)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func decodeDecimalGeometries(data interface{}) ([]*DecimalGeometry, error) {
+	vs, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid set of geometries, got %v", data)
+	}
+
+	geometries := make([]*DecimalGeometry, 0, len(vs))
+	for _, v := range vs {
+		vmap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("not a valid set of geometries, got %v", data)
+		}
+
+		g := &DecimalGeometry{}
+		if err := decodeDecimalGeometry(g, vmap); err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, g)
+	}
+
+	return geometries, nil
+}
+
+func decimalsToFloats(ds []Decimal) ([]float64, error) {
+	if ds == nil {
+		return nil, nil
+	}
+
+	result := make([]float64, 0, len(ds))
+	for _, d := range ds {
+		f, err := d.Float64()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+func decimalSetToFloats(dss [][]Decimal) ([][]float64, error) {
+	result := make([][]float64, 0, len(dss))
+	for _, ds := range dss {
+		fs, err := decimalsToFloats(ds)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fs)
+	}
+
+	return result, nil
+}
+
+func decimalPathSetToFloats(paths [][][]Decimal) ([][][]float64, error) {
+	result := make([][][]float64, 0, len(paths))
+	for _, path := range paths {
+		fs, err := decimalSetToFloats(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fs)
+	}
+
+	return result, nil
+}
+
+func floatsToDecimals(fs []float64) []Decimal {
+	if fs == nil {
+		return nil
+	}
+
+	result := make([]Decimal, 0, len(fs))
+	for _, f := range fs {
+		result = append(result, NewDecimal(f))
+	}
+
+	return result
+}
+
+func floatSetToDecimals(fss [][]float64) [][]Decimal {
+	result := make([][]Decimal, 0, len(fss))
+	for _, fs := range fss {
+		result = append(result, floatsToDecimals(fs))
+	}
+
+	return result
+}
+
+func floatPathSetToDecimals(paths [][][]float64) [][][]Decimal {
+	result := make([][][]Decimal, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, floatSetToDecimals(path))
+	}
+
+	return result
+}
+
+// isJSONNumber reports whether s is a valid JSON number literal.
+func isJSONNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	return json.Valid([]byte(s)) && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9'))
+}
+
+// A numberFormat appends a coordinate to the JSON being encoded.
+type numberFormat func(dst []byte, f float64) []byte
+
+// numberFormat returns the format of the coordinates and of the bounding
+// boxes, nil for the one of encoding/json.
+func (o MarshalOptions) numberFormat() numberFormat {
+	switch {
+	case o.Precision > 0:
+		decimals := o.Precision
+		return func(dst []byte, f float64) []byte {
+			return appendRounded(dst, f, decimals)
+		}
+	case o.PlainDecimals:
+		return appendPlainDecimal
+	}
+	return nil
+}
+
+// appendPlainDecimal appends the shortest plain decimal reading back as
+// the same float64.
+func appendPlainDecimal(dst []byte, f float64) []byte {
+	return strconv.AppendFloat(dst, f, 'f', -1, 64)
+}
+
+// appendRounded appends the float rounded to the number of decimals, in
+// plain decimal notation without trailing zeros.
+func appendRounded(dst []byte, f float64, decimals int) []byte {
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'f', decimals, 64)
+	if bytes.IndexByte(dst[start:], '.') >= 0 {
+		dst = bytes.TrimRight(dst, "0")
+		dst = bytes.TrimSuffix(dst, []byte("."))
+	}
+	if string(dst[start:]) == "-0" {
+		dst = append(dst[:start], '0')
+	}
+	return dst
+}
+
+// formatted returns the coordinates or the bounding box to encode with the
+// number format, as is if it is nil.
+func formatted(numbers interface{}, format numberFormat) interface{} {
+	if format == nil || numbers == nil {
+		return numbers
+	}
+	return formattedNumbers{numbers, format}
+}
+
+// formattedNumbers encodes coordinates, of any nesting, or a bounding box
+// with a number format.
+type formattedNumbers struct {
+	numbers interface{}
+	format  numberFormat
+}
+
+func (n formattedNumbers) MarshalJSON() ([]byte, error) {
+	return appendNumbers(nil, n.numbers, n.format), nil
+}
+
+// appendNumbers appends the coordinates, null for nil ones like
+// encoding/json.
+func appendNumbers(dst []byte, numbers interface{}, format numberFormat) []byte {
+	switch v := numbers.(type) {
+	case []float64:
+		if v == nil {
+			return append(dst, "null"...)
+		}
+		dst = append(dst, '[')
+		for i, f := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = format(dst, f)
+		}
+		return append(dst, ']')
+	case [][]float64:
+		if v == nil {
+			return append(dst, "null"...)
+		}
+		dst = append(dst, '[')
+		for i, p := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendNumbers(dst, p, format)
+		}
+		return append(dst, ']')
+	case [][][]float64:
+		if v == nil {
+			return append(dst, "null"...)
+		}
+		dst = append(dst, '[')
+		for i, p := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendNumbers(dst, p, format)
+		}
+		return append(dst, ']')
+	case [][][][]float64:
+		if v == nil {
+			return append(dst, "null"...)
+		}
+		dst = append(dst, '[')
+		for i, p := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendNumbers(dst, p, format)
+		}
+		return append(dst, ']')
+	}
+	return append(dst, "null"...)
+}
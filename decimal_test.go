@@ -0,0 +1,170 @@
+package geojson
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalDecimalGeometryRoundTrip(t *testing.T) {
+	rawJSON := `{"type":"Polygon","bbox":[4.10000000000000000001,50.1,4.3,50.30],"coordinates":[[[4.10000000000000000001,50.1],[4.3,50.1],[4.3,50.30],[4.10000000000000000001,50.1]]]}`
+
+	g, err := UnmarshalDecimalGeometry([]byte(rawJSON))
+	if err != nil {
+		t.Fatalf("should unmarshal decimal geometry without issue, err %v", err)
+	}
+
+	if g.Polygon[0][2][1] != "50.30" {
+		t.Errorf("should keep the exact decimal text, got %v", g.Polygon[0][2][1])
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	if string(data) != rawJSON {
+		t.Errorf("should round-trip the exact text")
+		t.Logf("%v", string(data))
+	}
+}
+
+func TestDecimalGeometryCollection(t *testing.T) {
+	rawJSON := `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1.10,2]},{"type":"LineString","coordinates":[[1,2],[3.000,4]]}]}`
+
+	g, err := UnmarshalDecimalGeometry([]byte(rawJSON))
+	if err != nil {
+		t.Fatalf("should unmarshal decimal geometry without issue, err %v", err)
+	}
+
+	if len(g.Geometries) != 2 {
+		t.Fatalf("should have 2 geometries but got %d", len(g.Geometries))
+	}
+
+	fg, err := g.Geometry()
+	if err != nil {
+		t.Fatalf("should convert to geometry, err %v", err)
+	}
+
+	if fg.Geometries[0].Point[0] != 1.1 || fg.Geometries[1].LineString[1][0] != 3 {
+		t.Errorf("incorrect float conversion, got %v", fg.Geometries)
+	}
+}
+
+func TestDecimalRat(t *testing.T) {
+	r, err := Decimal("0.1").Rat()
+	if err != nil {
+		t.Fatalf("should parse rat, err %v", err)
+	}
+
+	if r.Cmp(big.NewRat(1, 10)) != 0 {
+		t.Errorf("should be exactly 1/10, got %v", r)
+	}
+
+	if _, err := Decimal("abc").Rat(); err == nil {
+		t.Errorf("should return error for invalid decimal")
+	}
+}
+
+func TestDecimalMarshalInvalid(t *testing.T) {
+	g := &DecimalGeometry{Type: GeometryPoint, Point: []Decimal{"1", "x"}}
+
+	if _, err := json.Marshal(g); err == nil {
+		t.Errorf("should return error for invalid decimal")
+	}
+}
+
+func TestNewDecimalGeometry(t *testing.T) {
+	g := NewDecimalGeometry(NewLineStringGeometry([][]float64{{0.1, 2}, {3, 4.5}}))
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	if string(data) != `{"type":"LineString","coordinates":[[0.1,2],[3,4.5]]}` {
+		t.Errorf("data not correct")
+		t.Logf("%v", string(data))
+	}
+}
+
+func TestMarshalOptionsPlainDecimals(t *testing.T) {
+	f := NewLineStringFeature([][]float64{{1e-7, -2.5e-8}, {1e21, 0.5}})
+	f.BoundingBox = []float64{1e-7, -2.5e-8, 1e21, 0.5}
+	f.Properties["small"] = 1e-7
+
+	data, err := MarshalOptions{}.MarshalFeature(f)
+	if err != nil || !strings.Contains(string(data), "1e-7") {
+		t.Fatalf("should use exponents by default, got %s, %v", data, err)
+	}
+
+	data, err = MarshalOptions{PlainDecimals: true}.MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"Feature","bbox":[0.0000001,-0.000000025,1000000000000000000000,0.5],` +
+		`"geometry":{"type":"LineString","coordinates":[[0.0000001,-0.000000025],[1000000000000000000000,0.5]]},` +
+		`"properties":{"small":1e-7}}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	data, err = MarshalOptions{PlainDecimals: true, Workers: 2}.MarshalFeatureCollection(fc)
+	if err != nil || strings.Contains(string(data), "e-8") {
+		t.Errorf("should write plain decimals in collections, got %s, %v", data, err)
+	}
+}
+
+func TestMarshalOptionsPrecision(t *testing.T) {
+	f := NewLineStringFeature([][]float64{{4.3517103, 50.8503396}, {-0.0000001, 1e-9}, {2.5, 3}})
+	f.BoundingBox = []float64{-0.0000001, 1e-9, 4.3517103, 50.8503396}
+	f.Properties["precise"] = 4.3517103
+
+	data, err := MarshalOptions{Precision: 3}.MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"Feature","bbox":[0,0,4.352,50.85],` +
+		`"geometry":{"type":"LineString","coordinates":[[4.352,50.85],[0,0],[2.5,3]]},` +
+		`"properties":{"precise":4.3517103}}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+	if f.Geometry.LineString[0][0] != 4.3517103 {
+		t.Errorf("should not change the feature")
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	data, err = MarshalOptions{Precision: 6, Workers: 2}.MarshalFeatureCollection(fc)
+	if err != nil || !strings.Contains(string(data), "[[4.35171,50.85034],") {
+		t.Errorf("should round the coordinates of collections, got %s, %v", data, err)
+	}
+
+	f = NewPointFeature([]float64{1.23456, 2})
+	f.Properties["bbox"] = []float64{0.123456789}
+	f.Properties["coordinates"] = map[string]interface{}{"sensor": 12.3456789}
+	f.ForeignMembers = map[string]json.RawMessage{"bbox": json.RawMessage(`[0.123456789]`), "extent": json.RawMessage(`{"coordinates":[1.23456]}`)}
+	collection := NewCollectionGeometry(NewPointGeometry([]float64{0.123456, 1}))
+	for _, tc := range []struct {
+		options  MarshalOptions
+		expected string
+	}{
+		{MarshalOptions{Precision: 2}, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1.23,2]},` +
+			`"properties":{"bbox":[0.123456789],"coordinates":{"sensor":12.3456789}},"extent":{"coordinates":[1.23456]}}`},
+		{MarshalOptions{PlainDecimals: true}, `{"type":"Feature","geometry":{"type":"Point","coordinates":[1.23456,2]},` +
+			`"properties":{"bbox":[0.123456789],"coordinates":{"sensor":12.3456789}},"extent":{"coordinates":[1.23456]}}`},
+	} {
+		data, err := tc.options.MarshalFeature(f)
+		if err != nil || string(data) != tc.expected {
+			t.Errorf("should leave the properties and foreign members as they are, got %s, %v", data, err)
+		}
+	}
+	data, err = MarshalOptions{Precision: 2}.MarshalGeometry(collection)
+	if err != nil || string(data) != `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0.12,1]}]}` {
+		t.Errorf("should round the members of collections, got %s, %v", data, err)
+	}
+}
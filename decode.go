@@ -0,0 +1,369 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DecodeOptions configures the decoding done by its Unmarshal methods.
+// The zero value decodes like the UnmarshalJSON methods.
+type DecodeOptions struct {
+	// Validators are called in order on every feature as soon as it is
+	// decoded. The first error stops the decoding and is returned wrapped
+	// in a FeatureError.
+	Validators []func(*Feature) error
+
+	// CollectionValidators are called in order on decoded feature
+	// collections, after their features passed the Validators.
+	CollectionValidators []func(*FeatureCollection) error
+
+	// Strict rejects documents that the Unmarshal methods would decode
+	// partially: objects of the wrong type, unknown geometry types,
+	// missing members, malformed bounding boxes, coordinates not nested as
+	// their geometry type asks, positions of less than 2 numbers and
+	// features or geometries that are not objects. The error is a
+	// StrictError locating the offending member. Trailing data after the
+	// document is always rejected.
+	Strict bool
+
+	// AllowUnknownGeometryTypes decodes the geometries of unknown types,
+	// like "Circle", as geometries of that type without coordinates, as
+	// this package did before, instead of failing with an
+	// ErrUnsupportedGeometryType. Strict rejects them anyway.
+	AllowUnknownGeometryTypes bool
+
+	// Spec is the version of the GeoJSON specification decoded. With
+	// SpecRFC7946, the crs members are ignored, and the nested geometry
+	// collections are reported to Warnings.
+	Spec Spec
+
+	// Warnings, if set, is called with the problems of decoded documents
+	// that do not stop the decoding, like ValidationNestedCollection. The
+	// index is the one of the feature in its collection, 0 for a feature
+	// or a geometry decoded on its own, and the pointer is relative to it.
+	Warnings func(index int, w ValidationError)
+
+	// Limits, if set, bound the resources spent decoding, instead of
+	// DefaultDecodeLimits.
+	Limits *DecodeLimits
+
+	// CoordinateRange sets how longitudes outside [-180, 180] and latitudes
+	// outside [-90, 90] are handled. Out of range geometries stop the
+	// decoding with a ValidationError, wrapped in a FeatureError for
+	// features.
+	CoordinateRange CoordinateRangeMode
+
+	// Schema, if set, coerces the properties of every decoded feature to
+	// their declared types, before the Validators are called. A property
+	// that can not be coerced stops the decoding with a FeatureError
+	// wrapping its CoercionError, unless CoercionFailures is set.
+	Schema PropertySchema
+
+	// CoercionFailures, if set, is called with the properties of a feature
+	// that could not be coerced to the Schema, left as decoded, instead of
+	// stopping the decoding. The index is the one of the feature in its
+	// collection, 0 for a feature decoded on its own.
+	CoercionFailures func(index int, f *Feature, errs []*CoercionError)
+
+	// UseNumberIDs decodes the numeric ids of the features as json.Number,
+	// keeping all their digits, like those of 64 bits integers above 2^53,
+	// instead of float64.
+	UseNumberIDs bool
+
+	// Intern, if set, makes the identical geometries of the decoded
+	// features share their coordinates, see InternCache. A cache can be
+	// shared by several decodings, to intern across documents.
+	Intern *InternCache
+
+	// Stats, if set, is called with the statistics of every successful
+	// decoding, from the goroutine that decoded. The memory statistics
+	// are process wide, see DecodeStats.
+	Stats func(DecodeStats)
+}
+
+// DecodeStats are the statistics of a decoding, to track its performance
+// across versions.
+//
+// The memory statistics are not per decoding: they are the differences of
+// the process wide counters of runtime/metrics before and after it, so the
+// allocations and garbage collections of other goroutines decoding or
+// allocating at the same time are counted too. Only the statistics of
+// decodings run one at a time are meaningful. They are approximate as well:
+// the Go runtime counts the heap allocations by batches, so the ones of a
+// small decoding may be reported later, by another one, and the pauses are
+// summed from a histogram.
+type DecodeStats struct {
+	Bytes    int
+	Features int
+	Duration time.Duration
+
+	// Allocs and AllocBytes are the number and the size of the heap
+	// allocations made by the process during the decoding.
+	Allocs     uint64
+	AllocBytes uint64
+
+	// GCCycles is the number of garbage collections of the process
+	// completed during the decoding, and GCPause their total stop the
+	// world pause.
+	GCCycles uint32
+	GCPause  time.Duration
+}
+
+// FeaturesPerSecond returns the number of features decoded per second.
+func (s DecodeStats) FeaturesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Features) / s.Duration.Seconds()
+}
+
+// BytesPerSecond returns the number of bytes decoded per second.
+func (s DecodeStats) BytesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// A statsRecorder measures a decoding for DecodeOptions.Stats.
+type statsRecorder struct {
+	start time.Time
+	mem   memCounters
+}
+
+// memCounters are the cumulative memory statistics of the process.
+type memCounters struct {
+	allocs, allocBytes uint64
+	gcCycles           uint32
+	gcPause            time.Duration
+}
+
+func startStats() *statsRecorder {
+	r := &statsRecorder{mem: readMemCounters()}
+	r.start = time.Now()
+	return r
+}
+
+func (r *statsRecorder) stop(bytes, features int) DecodeStats {
+	duration := time.Since(r.start)
+	mem := readMemCounters()
+
+	return DecodeStats{
+		Bytes:      bytes,
+		Features:   features,
+		Duration:   duration,
+		Allocs:     mem.allocs - r.mem.allocs,
+		AllocBytes: mem.allocBytes - r.mem.allocBytes,
+		GCCycles:   mem.gcCycles - r.mem.gcCycles,
+		GCPause:    mem.gcPause - r.mem.gcPause,
+	}
+}
+
+// WithValidator returns a copy of the options with the feature validator
+// added, for business rules like required properties, allowed geometry types
+// or a maximum area, enforced while decoding.
+func (o DecodeOptions) WithValidator(v func(*Feature) error) DecodeOptions {
+	o.Validators = append(o.Validators[:len(o.Validators):len(o.Validators)], v)
+	return o
+}
+
+// WithCollectionValidator returns a copy of the options with the feature
+// collection validator added.
+func (o DecodeOptions) WithCollectionValidator(v func(*FeatureCollection) error) DecodeOptions {
+	o.CollectionValidators = append(o.CollectionValidators[:len(o.CollectionValidators):len(o.CollectionValidators)], v)
+	return o
+}
+
+// A FeatureError is a validation error of a decoded feature.
+type FeatureError struct {
+	// Index is the index of the feature in its collection, 0 for a
+	// feature decoded on its own.
+	Index int
+	Err   error
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("feature %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the validator.
+func (e *FeatureError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalGeometry decodes the data into a GeoJSON geometry,
+// according to the options.
+func (o DecodeOptions) UnmarshalGeometry(data []byte) (*Geometry, error) {
+	if err := o.limits().checkJSON(data); err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	if o.Strict {
+		if err := checkStrictGeometry(object, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	g := &Geometry{}
+	if err := decodeGeometry(g, object, o.AllowUnknownGeometryTypes); err != nil {
+		return nil, err
+	}
+	o.Spec.decodedGeometry(g, "", o.warn(0))
+	if err := o.CoordinateRange.apply(g, g.BoundingBox, ""); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// UnmarshalFeature decodes the data into a GeoJSON feature,
+// according to the options.
+func (o DecodeOptions) UnmarshalFeature(data []byte) (*Feature, error) {
+	var stats *statsRecorder
+	if o.Stats != nil {
+		stats = startStats()
+	}
+
+	if err := o.limits().checkJSON(data); err != nil {
+		return nil, err
+	}
+
+	object, err := o.unmarshalObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Strict {
+		if err := checkStrictFeature(object, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	f := &Feature{}
+	if err := decodeFeature(f, object, o.AllowUnknownGeometryTypes); err != nil {
+		return nil, err
+	}
+	if err := o.validate(0, f); err != nil {
+		return nil, err
+	}
+
+	if stats != nil {
+		o.Stats(stats.stop(len(data), 1))
+	}
+	return f, nil
+}
+
+// UnmarshalFeatureCollection decodes the data into a GeoJSON feature
+// collection, according to the options.
+func (o DecodeOptions) UnmarshalFeatureCollection(data []byte) (*FeatureCollection, error) {
+	var stats *statsRecorder
+	if o.Stats != nil {
+		stats = startStats()
+	}
+
+	if err := o.limits().checkJSON(data); err != nil {
+		return nil, err
+	}
+
+	object, err := o.unmarshalObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Strict {
+		if err := checkStrictFeatureCollection(object); err != nil {
+			return nil, err
+		}
+	}
+
+	var decoded func(int, *Feature) error
+	if len(o.Validators) != 0 || o.Schema != nil || o.Intern != nil || o.CoordinateRange != CoordinatesAsIs || o.Spec == SpecRFC7946 {
+		decoded = o.validate
+	}
+
+	fc := &FeatureCollection{}
+	if err := decodeFeatureCollection(fc, object, decoded, o.AllowUnknownGeometryTypes); err != nil {
+		return nil, err
+	}
+	if o.Spec == SpecRFC7946 {
+		fc.CRS = nil
+	}
+	for _, v := range o.CollectionValidators {
+		if err := v(fc); err != nil {
+			return nil, err
+		}
+	}
+
+	if stats != nil {
+		o.Stats(stats.stop(len(data), len(fc.Features)))
+	}
+	return fc, nil
+}
+
+// unmarshalObject decodes the JSON object of a feature or of a feature
+// collection, its numeric feature ids as json.Number with UseNumberIDs.
+func (o DecodeOptions) unmarshalObject(data []byte) (map[string]interface{}, error) {
+	if o.UseNumberIDs {
+		return unmarshalObject(data)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// limits returns the limits of the decoding.
+func (o DecodeOptions) limits() DecodeLimits {
+	if o.Limits == nil {
+		return DefaultDecodeLimits
+	}
+	return *o.Limits
+}
+
+// warn returns the function reporting the warnings of the feature at
+// index i, nil if there is no Warnings.
+func (o DecodeOptions) warn(i int) func(ValidationError) {
+	if o.Warnings == nil {
+		return nil
+	}
+	return func(w ValidationError) {
+		o.Warnings(i, w)
+	}
+}
+
+// validate applies the specification to the feature, checks its coordinate
+// range, interns its geometry, coerces its properties to the schema and
+// runs the validators on it.
+func (o DecodeOptions) validate(i int, f *Feature) error {
+	o.Spec.decodedFeature(f, o.warn(i))
+
+	if err := o.CoordinateRange.apply(f.Geometry, f.BoundingBox, "/geometry"); err != nil {
+		return &FeatureError{Index: i, Err: err}
+	}
+
+	if o.Intern != nil {
+		o.Intern.Intern(f.Geometry)
+	}
+
+	if o.Schema != nil {
+		if errs := o.Schema.Coerce(f); len(errs) != 0 {
+			if o.CoercionFailures == nil {
+				return &FeatureError{Index: i, Err: errs[0]}
+			}
+			o.CoercionFailures(i, f, errs)
+		}
+	}
+
+	for _, v := range o.Validators {
+		if err := v(f); err != nil {
+			return &FeatureError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package geojson
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A DecodeError is a value of a document that could not be decoded, like
+// a coordinate that is not a number, located in the document so it can be
+// found in large ones. Errors decoding a geometry, a feature or a feature
+// collection are DecodeErrors, get them with errors.As.
+type DecodeError struct {
+	// Pointer is the RFC 6901 JSON pointer to the offending value,
+	// relative to the decoded object, like "/features/12/geometry".
+	Pointer string
+
+	// Value is the offending value, as decoded by encoding/json, nil for
+	// null or missing members.
+	Value interface{}
+
+	// Expected describes the values allowed, like "a number".
+	Expected string
+
+	// Err, if not nil, is the reason the value is not allowed, like
+	// ErrUnsupportedGeometryType.
+	Err error
+}
+
+// Error describes the offending value and where it is.
+func (e *DecodeError) Error() string {
+	reason := "expected " + e.Expected
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+	reason += ", got " + describeValue(e.Value)
+
+	if e.Pointer == "" {
+		return reason
+	}
+	return e.Path() + ": " + reason
+}
+
+// Unwrap returns the reason the value is not allowed.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// JSONPointer returns the pointer to the offending value.
+func (e *DecodeError) JSONPointer() string {
+	return e.Pointer
+}
+
+// Path returns the location of the offending value in the notation of
+// JavaScript, like "features[12].geometry.coordinates[0][3]".
+func (e *DecodeError) Path() string {
+	var b strings.Builder
+	for _, token := range strings.Split(e.Pointer, "/")[1:] {
+		if token != "" && strings.Trim(token, "0123456789") == "" {
+			b.WriteString("[" + token + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+	}
+	return b.String()
+}
+
+func decodeError(pointer string, value interface{}, expected string) error {
+	return &DecodeError{Pointer: pointer, Value: value, Expected: expected}
+}
+
+// locate returns the error, located under the pointer if it is a
+// DecodeError.
+func locate(err error, pointer string) error {
+	e, ok := err.(*DecodeError)
+	if !ok {
+		return err
+	}
+	c := *e
+	c.Pointer = pointer + e.Pointer
+	return &c
+}
+
+// describeValue describes a decoded JSON value briefly, without the
+// content of arrays and objects, which can be large.
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if len(v) > 40 {
+			return fmt.Sprintf("%q...", v[:40])
+		}
+		return fmt.Sprintf("%q", v)
+	case []interface{}:
+		return fmt.Sprintf("an array of %d values", len(v))
+	case map[string]interface{}:
+		return fmt.Sprintf("an object of %d members", len(v))
+	case float64, int32, int64, bool:
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%T", v)
+}
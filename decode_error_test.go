@@ -0,0 +1,100 @@
+package geojson
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestDecodeError(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		pointer string
+		path    string
+		message string
+	}{
+		{
+			name:    "coordinate",
+			data:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,"a"]]]]},"properties":null}]}`,
+			pointer: "/features/0/geometry/coordinates/0/0/2/1",
+			path:    "features[0].geometry.coordinates[0][0][2][1]",
+			message: `features[0].geometry.coordinates[0][0][2][1]: expected a number, got "a"`,
+		},
+		{
+			name:    "position",
+			data:    `{"type":"FeatureCollection","features":[null,{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],{"x":1}]},"properties":null}]}`,
+			pointer: "/features/1/geometry/coordinates/1",
+			path:    "features[1].geometry.coordinates[1]",
+			message: "features[1].geometry.coordinates[1]: expected a position, got an object of 1 members",
+		},
+		{
+			name:    "collection member",
+			data:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},[]]},"properties":null}]}`,
+			pointer: "/features/0/geometry/geometries/1",
+			path:    "features[0].geometry.geometries[1]",
+			message: "features[0].geometry.geometries[1]: expected a geometry object, got an array of 0 values",
+		},
+		{
+			name:    "bounding box",
+			data:    `{"type":"FeatureCollection","bbox":[0,0,true,1],"features":[]}`,
+			pointer: "/bbox/2",
+			path:    "bbox[2]",
+			message: "bbox[2]: expected a number, got true",
+		},
+		{
+			name:    "feature",
+			data:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null},"feature"]}`,
+			pointer: "/features/1",
+			path:    "features[1]",
+			message: `features[1]: expected a feature object, got "feature"`,
+		},
+		{
+			name:    "properties",
+			data:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":[1,2]}]}`,
+			pointer: "/features/0/properties",
+			path:    "features[0].properties",
+			message: "features[0].properties: expected an object or null, got an array of 2 values",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := UnmarshalFeatureCollection([]byte(c.data))
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("should return a decode error, but got %v", err)
+			}
+			if derr.Pointer != c.pointer {
+				t.Errorf("incorrect pointer, got %q", derr.Pointer)
+			}
+			if derr.Path() != c.path {
+				t.Errorf("incorrect path, got %q", derr.Path())
+			}
+			if err.Error() != c.message {
+				t.Errorf("incorrect message, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestDecodeErrorUnwrap(t *testing.T) {
+	_, err := DecodeOptions{}.UnmarshalFeature([]byte(`{"type":"Feature","geometry":{"type":"Circle","coordinates":[0,0]},"properties":null}`))
+	var derr *DecodeError
+	if !errors.As(err, &derr) || derr.Value != "Circle" {
+		t.Fatalf("should return a decode error, but got %v", err)
+	}
+	if !errors.Is(err, ErrUnsupportedGeometryType) {
+		t.Errorf("should unwrap to the unsupported geometry type error")
+	}
+
+	p := NewProblem(http.StatusBadRequest, err)
+	if len(p.Errors) != 1 || p.Errors[0].Pointer != "/geometry/type" {
+		t.Errorf("incorrect problem, got %+v", p.Errors)
+	}
+
+	_, err = UnmarshalGeometry([]byte(`{"coordinates":[0,0]}`))
+	if err == nil || err.Error() != "type: expected a geometry type, got null" {
+		t.Errorf("incorrect error for a missing type, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package geojson
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// memMetrics are the runtime metrics read by readMemCounters, in the order
+// of its switch.
+var memMetrics = []string{
+	"/gc/heap/allocs:objects",
+	"/gc/heap/allocs:bytes",
+	"/gc/cycles/total:gc-cycles",
+	"/gc/pauses:seconds",
+}
+
+// readMemCounters reads the memory statistics from runtime/metrics, which
+// does not stop the world.
+func readMemCounters() memCounters {
+	samples := make([]metrics.Sample, len(memMetrics))
+	for i, name := range memMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	var c memCounters
+	for i, s := range samples {
+		switch {
+		case s.Value.Kind() == metrics.KindBad:
+			// not supported by this version of Go
+		case i == 0:
+			c.allocs = s.Value.Uint64()
+		case i == 1:
+			c.allocBytes = s.Value.Uint64()
+		case i == 2:
+			c.gcCycles = uint32(s.Value.Uint64())
+		case i == 3:
+			c.gcPause = histogramSum(s.Value.Float64Histogram())
+		}
+	}
+	return c
+}
+
+// histogramSum returns the approximate sum of the durations counted by the
+// histogram, taking the middle of every bucket, or its finite edge.
+func histogramSum(h *metrics.Float64Histogram) time.Duration {
+	var sum float64
+	for i, n := range h.Counts {
+		low, high := h.Buckets[i], h.Buckets[i+1]
+		switch {
+		case math.IsInf(low, -1):
+			low = high
+		case math.IsInf(high, 1):
+			high = low
+		}
+		sum += float64(n) * (low + high) / 2
+	}
+	return time.Duration(sum * float64(time.Second))
+}
@@ -0,0 +1,144 @@
+package geojson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeOptionsValidators(t *testing.T) {
+	errNoName := errors.New("missing name")
+	requireName := func(f *Feature) error {
+		if _, err := f.PropertyString("name"); err != nil {
+			return errNoName
+		}
+		return nil
+	}
+	onlyPoints := func(f *Feature) error {
+		if f.Geometry == nil || !f.Geometry.IsPoint() {
+			return errors.New("not a point")
+		}
+		return nil
+	}
+
+	opts := DecodeOptions{}.WithValidator(requireName).WithValidator(onlyPoints)
+	if len(opts.Validators) != 2 {
+		t.Fatalf("should have 2 validators, got %d", len(opts.Validators))
+	}
+
+	valid := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"name":"b"}}
+	]}`
+	fc, err := opts.UnmarshalFeatureCollection([]byte(valid))
+	if err != nil {
+		t.Fatalf("should unmarshal valid collection, but got %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Errorf("should have 2 features, got %d", len(fc.Features))
+	}
+
+	invalid := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{}}
+	]}`
+	_, err = opts.UnmarshalFeatureCollection([]byte(invalid))
+	var ferr *FeatureError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("should return a feature error, but got %v", err)
+	}
+	if ferr.Index != 1 || !errors.Is(err, errNoName) {
+		t.Errorf("incorrect feature error, got %v", err)
+	}
+	if err.Error() != "feature 1: missing name" {
+		t.Errorf("incorrect error message, got %q", err.Error())
+	}
+
+	_, err = opts.UnmarshalFeature([]byte(`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]},"properties":{"name":"a"}}`))
+	if err == nil || !errors.As(err, &ferr) {
+		t.Errorf("should reject the line string feature, got %v", err)
+	}
+}
+
+func TestDecodeOptionsCollectionValidator(t *testing.T) {
+	opts := DecodeOptions{}.WithCollectionValidator(func(fc *FeatureCollection) error {
+		if len(fc.Features) > 1 {
+			return errors.New("too many features")
+		}
+		return nil
+	})
+
+	data := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":null},
+		{"type":"Feature","geometry":null,"properties":null}
+	]}`
+	if _, err := opts.UnmarshalFeatureCollection([]byte(data)); err == nil || err.Error() != "too many features" {
+		t.Errorf("should reject the collection, got %v", err)
+	}
+
+	fc, err := DecodeOptions{}.UnmarshalFeatureCollection([]byte(data))
+	if err != nil || len(fc.Features) != 2 {
+		t.Errorf("should decode without validators, got %v", err)
+	}
+}
+
+func TestDecodeOptionsWithValidatorCopies(t *testing.T) {
+	base := DecodeOptions{Validators: make([]func(*Feature) error, 1, 4)}
+	base.Validators[0] = func(*Feature) error { return nil }
+
+	a := base.WithValidator(func(*Feature) error { return errors.New("a") })
+	b := base.WithValidator(func(*Feature) error { return errors.New("b") })
+	if a.Validators[1](nil).Error() != "a" || b.Validators[1](nil).Error() != "b" {
+		t.Errorf("should not share the validators of the copies")
+	}
+}
+
+func TestDecodeOptionsStats(t *testing.T) {
+	var stats []DecodeStats
+	opts := DecodeOptions{Stats: func(s DecodeStats) { stats = append(stats, s) }}
+
+	data := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}},
+		{"type":"Feature","geometry":null,"properties":null}
+	]}`
+	if _, err := opts.UnmarshalFeatureCollection([]byte(data)); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if _, err := opts.UnmarshalFeature([]byte(`{"type":"Feature","geometry":null,"properties":null}`)); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if _, err := opts.UnmarshalFeature([]byte(`{"type":`)); err == nil {
+		t.Fatalf("should not decode invalid JSON")
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("should report the 2 successful decodings, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.Bytes != len(data) || s.Features != 2 {
+		t.Errorf("incorrect counts, got %d bytes and %d features", s.Bytes, s.Features)
+	}
+	if s.Duration <= 0 {
+		t.Errorf("should measure the decoding, got %+v", s)
+	}
+	if s.FeaturesPerSecond() <= 0 || s.BytesPerSecond() <= 0 {
+		t.Errorf("should compute the throughput, got %v and %v", s.FeaturesPerSecond(), s.BytesPerSecond())
+	}
+	if stats[1].Features != 1 {
+		t.Errorf("incorrect feature count, got %d", stats[1].Features)
+	}
+
+	if (DecodeStats{}).FeaturesPerSecond() != 0 {
+		t.Errorf("should not divide by a zero duration")
+	}
+
+	// the allocations are counted by batches, seen on larger decodings
+	features := strings.Repeat(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}},`, 10000)
+	large := `{"type":"FeatureCollection","features":[` + strings.TrimSuffix(features, ",") + `]}`
+	if _, err := opts.UnmarshalFeatureCollection([]byte(large)); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if s := stats[len(stats)-1]; s.Allocs == 0 || s.AllocBytes == 0 {
+		t.Errorf("should count the allocations, got %+v", s)
+	}
+}
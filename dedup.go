@@ -0,0 +1,96 @@
+package geojson
+
+import "math"
+
+// DeduplicateVertices snaps the vertices of the features to a grid of
+// gridSize, in the units of the coordinates, and makes all the vertices at the
+// same position share the same slice, and returns the number of unique
+// vertices. Boundary datasets repeat every shared vertex on both sides of the
+// boundary, so this saves a lot of memory, and prepares the collection for
+// topology encoding or graph building. A gridSize of 0 only merges identical
+// vertices.
+//
+// Only the first two coordinates are snapped, but positions differing in
+// altitude are kept apart. As the vertices are shared, changing one of them
+// in place changes it in all the features using it.
+func DeduplicateVertices(fc *FeatureCollection, gridSize float64) int {
+	pool := vertexPool{
+		grid:     gridSize,
+		vertices: make(map[vertexKey][]float64),
+	}
+	for _, f := range fc.Features {
+		if f != nil {
+			pool.geometry(f.Geometry)
+		}
+	}
+	return len(pool.vertices)
+}
+
+type vertexKey struct {
+	coords [4]float64
+	dims   int
+}
+
+type vertexPool struct {
+	grid     float64
+	vertices map[vertexKey][]float64
+}
+
+// vertex returns the pooled vertex at the snapped position of p.
+func (vp *vertexPool) vertex(p []float64) []float64 {
+	if len(p) < 2 || len(p) > len(vertexKey{}.coords) {
+		return p
+	}
+
+	if vp.grid > 0 {
+		p[0] = math.Round(p[0]/vp.grid) * vp.grid
+		p[1] = math.Round(p[1]/vp.grid) * vp.grid
+	}
+
+	key := vertexKey{dims: len(p)}
+	copy(key.coords[:], p)
+	if v, ok := vp.vertices[key]; ok {
+		return v
+	}
+	vp.vertices[key] = p
+	return p
+}
+
+func (vp *vertexPool) path(path [][]float64) {
+	for i, p := range path {
+		path[i] = vp.vertex(p)
+	}
+}
+
+func (vp *vertexPool) geometry(g *Geometry) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		g.Point = vp.vertex(g.Point)
+	case GeometryMultiPoint:
+		vp.path(g.MultiPoint)
+	case GeometryLineString:
+		vp.path(g.LineString)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			vp.path(l)
+		}
+	case GeometryPolygon:
+		for _, r := range g.Polygon {
+			vp.path(r)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				vp.path(r)
+			}
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			vp.geometry(c)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package geojson
+
+import "testing"
+
+func TestDeduplicateVertices(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}))
+	fc.AddFeature(NewPolygonFeature([][][]float64{{{1.0004, 0}, {2, 0}, {2, 1}, {1, 0.9996}, {1.0004, 0}}}))
+	fc.AddFeature(NewPointFeature([]float64{2, 1, 10}))
+
+	unique := DeduplicateVertices(fc, 0.001)
+	if unique != 7 {
+		t.Errorf("incorrect number of unique vertices, expected 7, got %d", unique)
+	}
+
+	a, b := fc.Features[0].Geometry.Polygon[0], fc.Features[1].Geometry.Polygon[0]
+	if &a[1][0] != &b[0][0] || &a[2][0] != &b[3][0] {
+		t.Errorf("should share the vertices of the boundary")
+	}
+	if &a[0][0] != &a[4][0] {
+		t.Errorf("should share the closing vertex of a ring")
+	}
+	if &fc.Features[2].Geometry.Point[0] == &b[2][0] {
+		t.Errorf("should keep vertices differing in altitude apart")
+	}
+}
+
+func TestDeduplicateVerticesExact(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 1}}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{1, 1}, {1.0004, 1}}))
+
+	if unique := DeduplicateVertices(fc, 0); unique != 3 {
+		t.Errorf("incorrect number of unique vertices, expected 3, got %d", unique)
+	}
+	if fc.Features[1].Geometry.LineString[1][0] != 1.0004 {
+		t.Errorf("should not snap without grid, got %v", fc.Features[1].Geometry.LineString[1])
+	}
+}
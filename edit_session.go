@@ -0,0 +1,249 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The kinds of operations recorded by an EditSession.
+const (
+	EditAddFeature    = "add"
+	EditDeleteFeature = "delete"
+	EditMoveVertex    = "move"
+	EditSetProperty   = "set"
+)
+
+// An EditOperation is a single reversible edit of a feature collection.
+// It holds both the new and the previous state, so it can be undone.
+type EditOperation struct {
+	Op    string `json:"op"`
+	Index int    `json:"index"`
+
+	// Feature is the added or deleted feature.
+	Feature *Feature `json:"feature,omitempty"`
+
+	// Ring, Vertex, From and To describe a moved vertex,
+	// rings are numbered as for MoveVertex.
+	Ring   int       `json:"ring,omitempty"`
+	Vertex int       `json:"vertex,omitempty"`
+	From   []float64 `json:"from,omitempty"`
+	To     []float64 `json:"to,omitempty"`
+
+	// Key, Value and Previous describe a set property.
+	// Existed is false when the property was not set before.
+	Key      string      `json:"key,omitempty"`
+	Value    interface{} `json:"value"`
+	Previous interface{} `json:"previous"`
+	Existed  bool        `json:"existed,omitempty"`
+}
+
+// An EditSession edits a feature collection, recording every
+// operation so it can be undone, redone and exported as a patch.
+type EditSession struct {
+	fc     *FeatureCollection
+	done   []EditOperation
+	undone []EditOperation
+}
+
+// NewEditSession creates a new edit session on the feature collection.
+// The collection should only be modified through the session while it is in use.
+func NewEditSession(fc *FeatureCollection) *EditSession {
+	return &EditSession{fc: fc}
+}
+
+// Collection returns the edited feature collection.
+func (s *EditSession) Collection() *FeatureCollection {
+	return s.fc
+}
+
+// AddFeature appends the feature to the collection.
+func (s *EditSession) AddFeature(f *Feature) {
+	_ = s.record(EditOperation{Op: EditAddFeature, Index: len(s.fc.Features), Feature: f})
+}
+
+// DeleteFeature removes the feature at index i from the collection.
+func (s *EditSession) DeleteFeature(i int) error {
+	if i < 0 || i >= len(s.fc.Features) {
+		return fmt.Errorf("feature %d out of range", i)
+	}
+
+	return s.record(EditOperation{Op: EditDeleteFeature, Index: i, Feature: s.fc.Features[i]})
+}
+
+// MoveVertex moves a vertex of the geometry of the feature at index i,
+// see the MoveVertex function.
+func (s *EditSession) MoveVertex(i, ring, pos int, to []float64) error {
+	g, err := s.geometry(i)
+	if err != nil {
+		return err
+	}
+
+	from, err := vertex(g, ring, pos)
+	if err != nil {
+		return err
+	}
+
+	return s.record(EditOperation{Op: EditMoveVertex, Index: i, Ring: ring, Vertex: pos, From: from, To: to})
+}
+
+// SetProperty sets a property of the feature at index i.
+func (s *EditSession) SetProperty(i int, key string, value interface{}) error {
+	if i < 0 || i >= len(s.fc.Features) {
+		return fmt.Errorf("feature %d out of range", i)
+	}
+
+	previous, existed := s.fc.Features[i].Properties[key]
+	return s.record(EditOperation{Op: EditSetProperty, Index: i, Key: key, Value: value, Previous: previous, Existed: existed})
+}
+
+// CanUndo returns true if there is an operation to undo.
+func (s *EditSession) CanUndo() bool {
+	return len(s.done) > 0
+}
+
+// CanRedo returns true if there is an undone operation to redo.
+func (s *EditSession) CanRedo() bool {
+	return len(s.undone) > 0
+}
+
+// Undo reverts the last operation. It returns false if there was nothing to undo.
+func (s *EditSession) Undo() bool {
+	if len(s.done) == 0 {
+		return false
+	}
+
+	op := s.done[len(s.done)-1]
+	s.done = s.done[:len(s.done)-1]
+
+	s.revert(op)
+	s.undone = append(s.undone, op)
+	return true
+}
+
+// Redo reapplies the last undone operation. It returns false if there was nothing to redo.
+func (s *EditSession) Redo() bool {
+	if len(s.undone) == 0 {
+		return false
+	}
+
+	op := s.undone[len(s.undone)-1]
+	s.undone = s.undone[:len(s.undone)-1]
+
+	// operations were valid when recorded and the state is back to what it was
+	_ = applyEditOperation(s.fc, op)
+	s.done = append(s.done, op)
+	return true
+}
+
+// Operations returns the operations applied in the session, oldest first,
+// without the undone ones.
+func (s *EditSession) Operations() []EditOperation {
+	return append([]EditOperation(nil), s.done...)
+}
+
+// Patch exports the operations of the session as JSON,
+// that can be replayed on another copy of the collection with ApplyPatch.
+func (s *EditSession) Patch() ([]byte, error) {
+	ops := s.done
+	if ops == nil {
+		ops = []EditOperation{}
+	}
+	return json.Marshal(ops)
+}
+
+// ApplyPatch replays a patch exported by an edit session on the feature collection.
+// It stops at the first operation that can not be applied.
+func ApplyPatch(fc *FeatureCollection, patch []byte) error {
+	var ops []EditOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		if err := applyEditOperation(fc, op); err != nil {
+			return fmt.Errorf("patch operation %d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *EditSession) record(op EditOperation) error {
+	if err := applyEditOperation(s.fc, op); err != nil {
+		return err
+	}
+
+	s.done = append(s.done, op)
+	s.undone = nil
+	return nil
+}
+
+func (s *EditSession) revert(op EditOperation) {
+	fc := s.fc
+
+	switch op.Op {
+	case EditAddFeature:
+		fc.RemoveFeature(op.Index)
+	case EditDeleteFeature:
+		fc.insertFeature(op.Index, op.Feature)
+	case EditMoveVertex:
+		MoveVertex(fc.Features[op.Index].Geometry, op.Ring, op.Vertex, op.From)
+		fc.shrinkBoundingBox(&Feature{Geometry: NewPointGeometry(op.To)})
+		fc.expandBoundingBox(fc.Features[op.Index])
+	case EditSetProperty:
+		if op.Existed {
+			fc.Features[op.Index].SetProperty(op.Key, op.Previous)
+		} else {
+			delete(fc.Features[op.Index].Properties, op.Key)
+		}
+	}
+}
+
+func (s *EditSession) geometry(i int) (*Geometry, error) {
+	if i < 0 || i >= len(s.fc.Features) {
+		return nil, fmt.Errorf("feature %d out of range", i)
+	}
+
+	g := s.fc.Features[i].Geometry
+	if g == nil {
+		return nil, fmt.Errorf("feature %d has no geometry", i)
+	}
+	return g, nil
+}
+
+func applyEditOperation(fc *FeatureCollection, op EditOperation) error {
+	switch op.Op {
+	case EditAddFeature:
+		if op.Index < 0 || op.Index > len(fc.Features) {
+			return fmt.Errorf("feature %d out of range", op.Index)
+		}
+		fc.insertFeature(op.Index, op.Feature)
+		return nil
+	}
+
+	if op.Index < 0 || op.Index >= len(fc.Features) {
+		return fmt.Errorf("feature %d out of range", op.Index)
+	}
+	f := fc.Features[op.Index]
+
+	switch op.Op {
+	case EditDeleteFeature:
+		fc.RemoveFeature(op.Index)
+	case EditMoveVertex:
+		if f.Geometry == nil {
+			return fmt.Errorf("feature %d has no geometry", op.Index)
+		}
+		if err := MoveVertex(f.Geometry, op.Ring, op.Vertex, op.To); err != nil {
+			return err
+		}
+		fc.shrinkBoundingBox(&Feature{Geometry: NewPointGeometry(op.From)})
+		fc.expandBoundingBox(f)
+	case EditSetProperty:
+		f.SetProperty(op.Key, op.Value)
+	default:
+		return errors.New("unknown edit operation " + op.Op)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func editSessionTestCollection() *FeatureCollection {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+	fc.AddFeature(NewPointFeature([]float64{5, 5}))
+	return fc
+}
+
+func TestEditSessionUndoRedo(t *testing.T) {
+	fc := editSessionTestCollection()
+	s := NewEditSession(fc)
+
+	s.AddFeature(NewPointFeature([]float64{2, 2}))
+	if err := s.SetProperty(1, "name", "a"); err != nil {
+		t.Fatalf("should set property, got %v", err)
+	}
+	if err := s.MoveVertex(0, 0, 0, []float64{-1, -1}); err != nil {
+		t.Fatalf("should move vertex, got %v", err)
+	}
+	if err := s.DeleteFeature(2); err != nil {
+		t.Fatalf("should delete feature, got %v", err)
+	}
+
+	ring := fc.Features[0].Geometry.Polygon[0]
+	if !reflect.DeepEqual(ring[0], []float64{-1, -1}) || !reflect.DeepEqual(ring[3], []float64{-1, -1}) {
+		t.Errorf("should move both ends of the ring, got %v", ring)
+	}
+	if len(fc.Features) != 2 {
+		t.Errorf("should have deleted feature, got %d features", len(fc.Features))
+	}
+
+	for s.Undo() {
+	}
+
+	if !reflect.DeepEqual(fc, editSessionTestCollection()) {
+		t.Errorf("should be back to the original collection, got %v", fc)
+	}
+
+	if !s.Redo() || !s.Redo() {
+		t.Fatalf("should redo")
+	}
+	if len(fc.Features) != 3 || fc.Features[1].PropertyMustString("name") != "a" {
+		t.Errorf("should have redone the add and set property")
+	}
+
+	s.AddFeature(NewPointFeature([]float64{3, 3}))
+	if s.CanRedo() {
+		t.Errorf("new operation should clear the redo stack")
+	}
+}
+
+func TestEditSessionErrors(t *testing.T) {
+	s := NewEditSession(editSessionTestCollection())
+
+	if err := s.DeleteFeature(5); err == nil {
+		t.Errorf("should return error for unknown feature")
+	}
+	if err := s.MoveVertex(0, 1, 0, []float64{0, 0}); err == nil {
+		t.Errorf("should return error for unknown ring")
+	}
+	if s.CanUndo() {
+		t.Errorf("failed operations should not be recorded")
+	}
+}
+
+func TestEditSessionPatch(t *testing.T) {
+	s := NewEditSession(editSessionTestCollection())
+	s.AddFeature(NewPointFeature([]float64{2, 2}))
+	s.SetProperty(0, "visible", false)
+	s.MoveVertex(1, 0, 0, []float64{6, 6})
+
+	patch, err := s.Patch()
+	if err != nil {
+		t.Fatalf("should export patch, got %v", err)
+	}
+
+	other := editSessionTestCollection()
+	if err := ApplyPatch(other, patch); err != nil {
+		t.Fatalf("should apply patch, got %v", err)
+	}
+
+	if len(other.Features) != 3 {
+		t.Errorf("should have added feature, got %d features", len(other.Features))
+	}
+	if v, ok := other.Features[0].Properties["visible"]; !ok || v != false {
+		t.Errorf("should have set property, got %v", other.Features[0].Properties)
+	}
+	if !reflect.DeepEqual(other.Features[1].Geometry.Point, []float64{6, 6}) {
+		t.Errorf("should have moved point, got %v", other.Features[1].Geometry.Point)
+	}
+}
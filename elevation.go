@@ -0,0 +1,182 @@
+package geojson
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DefaultElevationBatchSize is the number of positions sampled at once
+// when an ElevationEnricher has no BatchSize.
+const DefaultElevationBatchSize = 100
+
+// An Enricher adds information to the features of a collection.
+type Enricher interface {
+	Enrich(ctx context.Context, fc *FeatureCollection) error
+}
+
+// An ElevationSampler returns the elevation, in meters, of each of the
+// longitude/latitude positions, for example by querying terrain tiles.
+type ElevationSampler interface {
+	Elevations(ctx context.Context, positions [][]float64) ([]float64, error)
+}
+
+// ElevationSamplerFunc adapts a function to an ElevationSampler.
+type ElevationSamplerFunc func(ctx context.Context, positions [][]float64) ([]float64, error)
+
+// Elevations calls f(ctx, positions).
+func (f ElevationSamplerFunc) Elevations(ctx context.Context, positions [][]float64) ([]float64, error) {
+	return f(ctx, positions)
+}
+
+// ElevationEnricher is an Enricher setting the altitude, the third coordinate,
+// of the positions of points and lines from a sampler.
+// Polygons are left untouched.
+type ElevationEnricher struct {
+	Sampler ElevationSampler
+
+	// BatchSize is the maximum number of positions per call to the sampler,
+	// DefaultElevationBatchSize if not set.
+	BatchSize int
+
+	// Workers is the number of batches sampled concurrently, at least 1.
+	Workers int
+
+	// Overwrite replaces altitudes that are already set,
+	// otherwise only positions without one are sampled.
+	Overwrite bool
+}
+
+// Enrich samples the elevations of the point and line positions of the
+// collection in batches, and sets them as altitudes. It stops at the first
+// error of the sampler, leaving the batches sampled so far applied.
+func (e ElevationEnricher) Enrich(ctx context.Context, fc *FeatureCollection) error {
+	var targets []*[]float64
+	for _, f := range fc.Features {
+		if f != nil {
+			targets = e.appendTargets(targets, f.Geometry)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	size := e.BatchSize
+	if size <= 0 {
+		size = DefaultElevationBatchSize
+	}
+	workers := e.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []*[]float64)
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := e.sample(ctx, batch); err != nil {
+					once.Do(func() {
+						failure = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+send:
+	for start := 0; start < len(targets); start += size {
+		end := start + size
+		if end > len(targets) {
+			end = len(targets)
+		}
+
+		select {
+		case batches <- targets[start:end]:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if failure != nil {
+		return failure
+	}
+	return ctx.Err()
+}
+
+// sample requests the elevations of a batch and sets them.
+func (e ElevationEnricher) sample(ctx context.Context, batch []*[]float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	positions := make([][]float64, len(batch))
+	for i, p := range batch {
+		positions[i] = []float64{(*p)[0], (*p)[1]}
+	}
+
+	elevations, err := e.Sampler.Elevations(ctx, positions)
+	if err != nil {
+		return err
+	}
+	if len(elevations) != len(batch) {
+		return fmt.Errorf("elevation sampler returned %d elevations for %d positions", len(elevations), len(batch))
+	}
+
+	for i, p := range batch {
+		if len(*p) > 2 {
+			(*p)[2] = elevations[i]
+		} else {
+			*p = []float64{(*p)[0], (*p)[1], elevations[i]}
+		}
+	}
+	return nil
+}
+
+// appendTargets adds the positions of points and lines to sample.
+func (e ElevationEnricher) appendTargets(targets []*[]float64, g *Geometry) []*[]float64 {
+	if g == nil {
+		return targets
+	}
+
+	add := func(p *[]float64) {
+		if len(*p) == 2 || (len(*p) > 2 && e.Overwrite) {
+			targets = append(targets, p)
+		}
+	}
+	addPath := func(path [][]float64) {
+		for i := range path {
+			add(&path[i])
+		}
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		add(&g.Point)
+	case GeometryMultiPoint:
+		addPath(g.MultiPoint)
+	case GeometryLineString:
+		addPath(g.LineString)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			addPath(l)
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			targets = e.appendTargets(targets, c)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,79 @@
+package geojson
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestElevationEnricher(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{1, 1}, {2, 2, 50}, {3, 3}}))
+	fc.AddFeature(NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+	fc.AddFeature(NewFeature(nil))
+
+	var calls int32
+	sampler := ElevationSamplerFunc(func(ctx context.Context, positions [][]float64) ([]float64, error) {
+		atomic.AddInt32(&calls, 1)
+		result := make([]float64, len(positions))
+		for i, p := range positions {
+			result[i] = p[0] * 100
+		}
+		return result, nil
+	})
+
+	e := ElevationEnricher{Sampler: sampler, BatchSize: 2, Workers: 2}
+	if err := e.Enrich(context.Background(), fc); err != nil {
+		t.Fatalf("should enrich, but got %v", err)
+	}
+
+	if p := fc.Features[0].Geometry.Point; len(p) != 3 || p[2] != 100 {
+		t.Errorf("should set point altitude, got %v", p)
+	}
+	line := fc.Features[1].Geometry.LineString
+	if line[0][2] != 100 || line[1][2] != 50 || line[2][2] != 300 {
+		t.Errorf("should only fill missing altitudes, got %v", line)
+	}
+	if len(fc.Features[2].Geometry.Polygon[0][0]) != 2 {
+		t.Errorf("should leave polygons untouched")
+	}
+	if calls != 2 {
+		t.Errorf("should sample 3 positions in 2 batches, got %v", calls)
+	}
+
+	e.Overwrite = true
+	if err := e.Enrich(context.Background(), fc); err != nil {
+		t.Fatalf("should enrich, but got %v", err)
+	}
+	if fc.Features[1].Geometry.LineString[1][2] != 200 {
+		t.Errorf("should overwrite altitudes, got %v", fc.Features[1].Geometry.LineString)
+	}
+}
+
+func TestElevationEnricherError(t *testing.T) {
+	fc := NewFeatureCollection()
+	for i := 0; i < 10; i++ {
+		fc.AddFeature(NewPointFeature([]float64{float64(i), 0}))
+	}
+
+	failure := errors.New("tile not found")
+	e := ElevationEnricher{
+		Sampler: ElevationSamplerFunc(func(ctx context.Context, positions [][]float64) ([]float64, error) {
+			return nil, failure
+		}),
+		BatchSize: 1,
+		Workers:   3,
+	}
+	if err := e.Enrich(context.Background(), fc); err != failure {
+		t.Errorf("should return the sampler error, got %v", err)
+	}
+
+	e.Sampler = ElevationSamplerFunc(func(ctx context.Context, positions [][]float64) ([]float64, error) {
+		return []float64{}, nil
+	})
+	if err := e.Enrich(context.Background(), fc); err == nil {
+		t.Errorf("should fail when the sampler returns too few elevations")
+	}
+}
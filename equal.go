@@ -0,0 +1,94 @@
+package geojson
+
+import (
+	"math"
+)
+
+// Equal returns true if both geometries have the same type, structure and
+// coordinates. Unlike reflect.DeepEqual, -0 equals 0 and nil slices equal
+// empty ones. Bounding boxes, CRS and foreign members are not compared.
+func (g *Geometry) Equal(other *Geometry) bool {
+	return g.EqualWithTolerance(other, 0)
+}
+
+// EqualWithTolerance is like Equal, but coordinates only need to be
+// within epsilon of each other, absorbing float rounding noise.
+func (g *Geometry) EqualWithTolerance(other *Geometry, epsilon float64) bool {
+	if g == nil || other == nil {
+		return g == other
+	}
+	if g.Type != other.Type {
+		return false
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		return equalPosition(g.Point, other.Point, epsilon)
+	case GeometryMultiPoint:
+		return equalPath(g.MultiPoint, other.MultiPoint, epsilon)
+	case GeometryLineString:
+		return equalPath(g.LineString, other.LineString, epsilon)
+	case GeometryMultiLineString:
+		return equalPaths(g.MultiLineString, other.MultiLineString, epsilon)
+	case GeometryPolygon:
+		return equalPaths(g.Polygon, other.Polygon, epsilon)
+	case GeometryMultiPolygon:
+		if len(g.MultiPolygon) != len(other.MultiPolygon) {
+			return false
+		}
+		for i := range g.MultiPolygon {
+			if !equalPaths(g.MultiPolygon[i], other.MultiPolygon[i], epsilon) {
+				return false
+			}
+		}
+		return true
+	case GeometryCollection:
+		if len(g.Geometries) != len(other.Geometries) {
+			return false
+		}
+		for i := range g.Geometries {
+			if !g.Geometries[i].EqualWithTolerance(other.Geometries[i], epsilon) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return true
+}
+
+func equalPosition(a, b []float64, epsilon float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.Abs(a[i]-b[i]) <= epsilon) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPath(a, b [][]float64, epsilon float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalPosition(a[i], b[i], epsilon) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPaths(a, b [][][]float64, epsilon float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalPath(a[i], b[i], epsilon) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeometryEqual(t *testing.T) {
+	square := [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+	x, y := 0.1, 0.2
+
+	cases := []struct {
+		name  string
+		a, b  *Geometry
+		equal bool
+	}{
+		{"same", NewPolygonGeometry(square), NewPolygonGeometry(square), true},
+		{"negative zero", NewPointGeometry([]float64{math.Copysign(0, -1), 1}), NewPointGeometry([]float64{0, 1}), true},
+		{"nil and empty", &Geometry{Type: GeometryLineString}, NewLineStringGeometry([][]float64{}), true},
+		{"type", NewMultiPointGeometry([]float64{1, 2}), NewLineStringGeometry([][]float64{{1, 2}}), false},
+		{"dimensions", NewPointGeometry([]float64{1, 2}), NewPointGeometry([]float64{1, 2, 0}), false},
+		{"structure", NewMultiLineStringGeometry([][]float64{{0, 0}, {1, 1}}), NewMultiLineStringGeometry([][]float64{{0, 0}}, [][]float64{{1, 1}}), false},
+		{"noise", NewPointGeometry([]float64{x + y, 1}), NewPointGeometry([]float64{0.3, 1}), false},
+		{"collection", NewCollectionGeometry(NewPointGeometry([]float64{1, 2})), NewCollectionGeometry(NewPointGeometry([]float64{1, 3})), false},
+		{"nil", nil, NewPointGeometry([]float64{1, 2}), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.a.Equal(tc.b) != tc.equal {
+				t.Errorf("should be %v", tc.equal)
+			}
+		})
+	}
+}
+
+func TestGeometryEqualWithTolerance(t *testing.T) {
+	x, y := 0.1, 0.2
+	a := NewLineStringGeometry([][]float64{{x + y, 1}, {2, 2}})
+	b := NewLineStringGeometry([][]float64{{0.3, 1}, {2, 2.0000001}})
+
+	if !a.EqualWithTolerance(b, 1e-6) {
+		t.Errorf("should be equal within tolerance")
+	}
+	if a.EqualWithTolerance(b, 1e-9) {
+		t.Errorf("should not be equal beyond tolerance")
+	}
+	if NewPointGeometry([]float64{math.NaN(), 0}).EqualWithTolerance(NewPointGeometry([]float64{math.NaN(), 0}), 1) {
+		t.Errorf("NaN should not equal NaN")
+	}
+}
@@ -0,0 +1,350 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// esriWebMercator is the legacy wkid of Web Mercator in ArcGIS.
+const esriWebMercator = 102100
+
+type esriSpatialReference struct {
+	WKID       int `json:"wkid,omitempty"`
+	LatestWKID int `json:"latestWkid,omitempty"`
+}
+
+type esriGeometry struct {
+	X      *float64              `json:"x,omitempty"`
+	Y      *float64              `json:"y,omitempty"`
+	Z      *float64              `json:"z,omitempty"`
+	Points [][]float64           `json:"points,omitempty"`
+	Paths  [][][]float64         `json:"paths,omitempty"`
+	Rings  [][][]float64         `json:"rings,omitempty"`
+	HasZ   bool                  `json:"hasZ,omitempty"`
+	HasM   bool                  `json:"hasM,omitempty"`
+	XMin   *float64              `json:"xmin,omitempty"`
+	YMin   *float64              `json:"ymin,omitempty"`
+	XMax   *float64              `json:"xmax,omitempty"`
+	YMax   *float64              `json:"ymax,omitempty"`
+	SR     *esriSpatialReference `json:"spatialReference,omitempty"`
+}
+
+type esriFeature struct {
+	Attributes map[string]interface{} `json:"attributes"`
+	Geometry   *esriGeometry          `json:"geometry,omitempty"`
+}
+
+type esriFeatureSet struct {
+	ObjectIDFieldName string                `json:"objectIdFieldName,omitempty"`
+	GeometryType      string                `json:"geometryType,omitempty"`
+	SR                *esriSpatialReference `json:"spatialReference,omitempty"`
+	Features          []esriFeature         `json:"features"`
+}
+
+// FromEsriJSON decodes the JSON of ArcGIS REST services: a feature set, like
+// the response of a feature service query, a single feature or a single
+// geometry. Esri exterior rings are clockwise and holes counterclockwise, so
+// the rings are regrouped into polygons and reversed to the orientation of
+// RFC 7946. Measures are dropped, and envelopes become polygons.
+//
+// The object id becomes the ID of the features, and a spatial reference
+// other than WGS 84 the CRS of the collection, as EPSG name.
+func FromEsriJSON(data []byte) (*FeatureCollection, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	var set esriFeatureSet
+	_, isSet := object["features"]
+	_, isFeature := object["attributes"]
+	switch {
+	case isSet:
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, err
+		}
+	case isFeature:
+		var f esriFeature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		set.Features = []esriFeature{f}
+	default:
+		var g esriGeometry
+		if err := json.Unmarshal(data, &g); err != nil {
+			return nil, err
+		}
+		set.Features = []esriFeature{{Geometry: &g}}
+	}
+
+	fc := NewFeatureCollection()
+	sr := set.SR
+	for i, ef := range set.Features {
+		f := NewFeature(nil)
+		if ef.Geometry != nil {
+			g, err := ef.Geometry.geometry()
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+			f.Geometry = g
+			if sr == nil {
+				sr = ef.Geometry.SR
+			}
+		}
+
+		if len(ef.Attributes) != 0 {
+			f.Properties = ef.Attributes
+		}
+		if id, ok := ef.Attributes[set.ObjectIDFieldName]; ok {
+			f.ID = id
+		}
+		fc.Features = append(fc.Features, f)
+	}
+
+	if sr != nil {
+		wkid := sr.LatestWKID
+		if wkid == 0 {
+			wkid = sr.WKID
+		}
+		if wkid == esriWebMercator {
+			wkid = 3857
+		}
+		if wkid != 0 && wkid != 4326 {
+			fc.SetCRS(NewEPSGCRS(wkid))
+		}
+	}
+
+	return fc, nil
+}
+
+func (eg *esriGeometry) geometry() (*Geometry, error) {
+	position := func(c []float64) ([]float64, error) {
+		n := 2
+		if eg.HasZ {
+			n = 3
+		}
+		if len(c) < 2 || (eg.HasZ && len(c) < 3) {
+			return nil, fmt.Errorf("position %v needs %d coordinates", c, n)
+		}
+		if len(c) < n {
+			n = len(c)
+		}
+		return c[:n:n], nil // measures are dropped
+	}
+	path := func(coords [][]float64) ([][]float64, error) {
+		result := make([][]float64, len(coords))
+		for i, c := range coords {
+			p, err := position(c)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = p
+		}
+		return result, nil
+	}
+
+	switch {
+	case eg.X != nil || eg.Y != nil:
+		if eg.X == nil || eg.Y == nil {
+			return nil, errors.New("point needs x and y")
+		}
+		p := []float64{*eg.X, *eg.Y}
+		if eg.Z != nil {
+			p = append(p, *eg.Z)
+		}
+		return NewPointGeometry(p), nil
+	case eg.Points != nil:
+		points, err := path(eg.Points)
+		if err != nil {
+			return nil, err
+		}
+		return NewMultiPointGeometry(points...), nil
+	case eg.Paths != nil:
+		lines := make([][][]float64, len(eg.Paths))
+		for i, l := range eg.Paths {
+			var err error
+			if lines[i], err = path(l); err != nil {
+				return nil, err
+			}
+		}
+		if len(lines) == 1 {
+			return NewLineStringGeometry(lines[0]), nil
+		}
+		return NewMultiLineStringGeometry(lines...), nil
+	case eg.Rings != nil:
+		rings := make([][][]float64, len(eg.Rings))
+		for i, r := range eg.Rings {
+			var err error
+			if rings[i], err = path(r); err != nil {
+				return nil, err
+			}
+			if len(rings[i]) < 4 {
+				return nil, fmt.Errorf("ring with %d positions, at least 4 are needed", len(rings[i]))
+			}
+		}
+		polygons := esriPolygons(rings)
+		switch len(polygons) {
+		case 0:
+			return nil, nil
+		case 1:
+			return NewPolygonGeometry(polygons[0]), nil
+		}
+		return NewMultiPolygonGeometry(polygons...), nil
+	case eg.XMin != nil && eg.YMin != nil && eg.XMax != nil && eg.YMax != nil:
+		x0, y0, x1, y1 := *eg.XMin, *eg.YMin, *eg.XMax, *eg.YMax
+		return NewPolygonGeometry([][][]float64{{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}}), nil
+	}
+
+	return nil, nil
+}
+
+// esriPolygons groups clockwise exterior rings with the counterclockwise
+// holes they contain, reversing all of them.
+func esriPolygons(rings [][][]float64) [][][][]float64 {
+	var polygons [][][][]float64
+	var holes [][][]float64
+	for _, r := range rings {
+		r = append([][]float64(nil), r...)
+		reverseRing(r)
+		if ringArea2D(r) >= 0 {
+			polygons = append(polygons, [][][]float64{r})
+		} else {
+			holes = append(holes, r)
+		}
+	}
+
+	for _, h := range holes {
+		owner := -1
+		for i, p := range polygons {
+			if PointInPolygonWinding(h[0], p[:1]) != Exterior {
+				owner = i
+				break
+			}
+		}
+		if owner < 0 {
+			// a counterclockwise ring outside of all exterior rings
+			// is an exterior ring with the wrong orientation
+			reverseRing(h)
+			polygons = append(polygons, [][][]float64{h})
+			continue
+		}
+		polygons[owner] = append(polygons[owner], h)
+	}
+
+	return polygons
+}
+
+// ToEsriJSON encodes the collection as an ArcGIS REST feature set, with the
+// properties as attributes and the given spatial reference wkid, 4326 if 0.
+// Esri feature sets have a single geometry type, so the features must all
+// be points, multi points, lines or polygons, or have no geometry. Polygon
+// rings are written clockwise and holes counterclockwise.
+func ToEsriJSON(fc *FeatureCollection, wkid int) ([]byte, error) {
+	if wkid == 0 {
+		wkid = 4326
+	}
+
+	set := esriFeatureSet{
+		SR:       &esriSpatialReference{WKID: wkid},
+		Features: make([]esriFeature, 0, len(fc.Features)),
+	}
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+
+		ef := esriFeature{Attributes: f.Properties}
+		if ef.Attributes == nil {
+			ef.Attributes = map[string]interface{}{}
+		}
+		if f.Geometry != nil {
+			g, geometryType, err := toEsriGeometry(f.Geometry)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+			if set.GeometryType != "" && set.GeometryType != geometryType {
+				return nil, fmt.Errorf("feature %d: %s in a feature set of %s", i, geometryType, set.GeometryType)
+			}
+			set.GeometryType = geometryType
+			ef.Geometry = g
+		}
+		set.Features = append(set.Features, ef)
+	}
+
+	return json.Marshal(set)
+}
+
+func toEsriGeometry(g *Geometry) (*esriGeometry, string, error) {
+	eg := &esriGeometry{}
+	forEachPosition(g, func(p []float64) {
+		if len(p) > 2 {
+			eg.HasZ = true
+		}
+	})
+
+	path := func(path [][]float64) ([][]float64, error) {
+		result := make([][]float64, len(path))
+		for i, p := range path {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("position %v needs at least 2 coordinates", p)
+			}
+			c := []float64{p[0], p[1]}
+			if eg.HasZ {
+				z := 0.0
+				if len(p) > 2 {
+					z = p[2]
+				}
+				c = append(c, z)
+			}
+			result[i] = c
+		}
+		return result, nil
+	}
+
+	var err error
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) < 2 {
+			return nil, "", errors.New("point needs at least 2 coordinates")
+		}
+		eg.X, eg.Y = &g.Point[0], &g.Point[1]
+		if len(g.Point) > 2 {
+			eg.Z = &g.Point[2]
+		}
+		return eg, "esriGeometryPoint", nil
+	case GeometryMultiPoint:
+		eg.Points, err = path(g.MultiPoint)
+		return eg, "esriGeometryMultipoint", err
+	case GeometryLineString, GeometryMultiLineString:
+		lines := g.MultiLineString
+		if g.Type == GeometryLineString {
+			lines = [][][]float64{g.LineString}
+		}
+		eg.Paths = make([][][]float64, len(lines))
+		for i, l := range lines {
+			if eg.Paths[i], err = path(l); err != nil {
+				return nil, "", err
+			}
+		}
+		return eg, "esriGeometryPolyline", nil
+	case GeometryPolygon, GeometryMultiPolygon:
+		eg.Rings = [][][]float64{}
+		for _, p := range polygons(g) {
+			for i, r := range p {
+				ring, err := path(r)
+				if err != nil {
+					return nil, "", err
+				}
+				// exterior rings clockwise, holes counterclockwise
+				if (ringArea2D(ring) > 0) == (i == 0) {
+					reverseRing(ring)
+				}
+				eg.Rings = append(eg.Rings, ring)
+			}
+		}
+		return eg, "esriGeometryPolygon", nil
+	}
+
+	return nil, "", fmt.Errorf("geometry type %s has no Esri equivalent", g.Type)
+}
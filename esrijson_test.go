@@ -0,0 +1,145 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestFromEsriJSON(t *testing.T) {
+	data := `{
+		"objectIdFieldName": "OBJECTID",
+		"geometryType": "esriGeometryPolygon",
+		"spatialReference": {"wkid": 102100, "latestWkid": 3857},
+		"features": [
+			{
+				"attributes": {"OBJECTID": 1, "NAME": "block"},
+				"geometry": {"rings": [
+					[[0, 0], [0, 10], [10, 10], [10, 0], [0, 0]],
+					[[2, 2], [4, 2], [4, 4], [2, 4], [2, 2]],
+					[[20, 0], [20, 1], [21, 1], [20, 0]]
+				]}
+			},
+			{
+				"attributes": {"OBJECTID": 2, "NAME": "empty"},
+				"geometry": null
+			}
+		]
+	}`
+
+	fc, err := FromEsriJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("should have 2 features, got %d", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	if f.ID != 1.0 || f.PropertyMustString("NAME") != "block" {
+		t.Errorf("incorrect feature, got ID %v and properties %v", f.ID, f.Properties)
+	}
+	expected := NewMultiPolygonGeometry(
+		[][][]float64{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+		},
+		[][][]float64{{{20, 0}, {21, 1}, {20, 1}, {20, 0}}},
+	)
+	if !f.Geometry.Equal(expected) {
+		t.Errorf("incorrect geometry, got %v", f.Geometry.MultiPolygon)
+	}
+	if fc.Features[1].Geometry != nil {
+		t.Errorf("should have no geometry, got %+v", fc.Features[1].Geometry)
+	}
+
+	name := fc.CRS["properties"].(map[string]interface{})["name"]
+	if name != "EPSG:3857" {
+		t.Errorf("incorrect CRS, got %v", fc.CRS)
+	}
+}
+
+func TestFromEsriJSONGeometries(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		geometry *Geometry
+	}{
+		{"point", `{"x": 4.35, "y": 50.85, "spatialReference": {"wkid": 4326}}`, NewPointGeometry([]float64{4.35, 50.85})},
+		{"point z", `{"x": 1, "y": 2, "z": 3}`, NewPointGeometry([]float64{1, 2, 3})},
+		{"multipoint m", `{"hasM": true, "points": [[1, 2, 9], [3, 4, 9]]}`, NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4})},
+		{"polyline zm", `{"hasZ": true, "hasM": true, "paths": [[[1, 2, 3, 9], [4, 5, 6, 9]]]}`, NewLineStringGeometry([][]float64{{1, 2, 3}, {4, 5, 6}})},
+		{"envelope", `{"xmin": 0, "ymin": 1, "xmax": 2, "ymax": 3}`, NewPolygonGeometry([][][]float64{{{0, 1}, {2, 1}, {2, 3}, {0, 3}, {0, 1}}})},
+		{"feature", `{"attributes": {"a": 1}, "geometry": {"paths": [[[0, 0], [1, 1]], [[2, 2], [3, 3]]]}}`, NewMultiLineStringGeometry([][]float64{{0, 0}, {1, 1}}, [][]float64{{2, 2}, {3, 3}})},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc, err := FromEsriJSON([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("should decode, but got %v", err)
+			}
+			if !fc.Features[0].Geometry.Equal(tc.geometry) {
+				t.Errorf("incorrect geometry, got %+v", fc.Features[0].Geometry)
+			}
+			if fc.CRS != nil {
+				t.Errorf("should not set a CRS, got %v", fc.CRS)
+			}
+		})
+	}
+
+	for _, data := range []string{`{"x": 1}`, `{"hasZ": true, "paths": [[[1, 2], [3, 4]]]}`, `{"rings": [[[0, 0], [1, 1], [0, 0]]]}`, `[`} {
+		if _, err := FromEsriJSON([]byte(data)); err == nil {
+			t.Errorf("should reject %s", data)
+		}
+	}
+}
+
+func TestToEsriJSON(t *testing.T) {
+	fc := NewFeatureCollection()
+	f := NewPolygonFeature([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+	})
+	f.SetProperty("name", "block")
+	fc.AddFeature(f)
+	fc.AddFeature(NewFeature(nil))
+
+	data, err := ToEsriJSON(fc, 0)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	expected := `{"geometryType":"esriGeometryPolygon","spatialReference":{"wkid":4326},"features":[` +
+		`{"attributes":{"name":"block"},"geometry":{"rings":[[[0,0],[0,10],[10,10],[10,0],[0,0]],[[2,2],[4,2],[4,4],[2,4],[2,2]]]}},` +
+		`{"attributes":{}}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect Esri JSON, got %s", data)
+	}
+
+	decoded, err := FromEsriJSON(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if !decoded.Features[0].Geometry.Equal(f.Geometry) {
+		t.Errorf("should round trip, got %v", decoded.Features[0].Geometry.Polygon)
+	}
+
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+	if _, err := ToEsriJSON(fc, 0); err == nil {
+		t.Errorf("should reject mixed geometry types")
+	}
+}
+
+func TestToEsriJSONPoint(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2, 3}))
+
+	data, err := ToEsriJSON(fc, 3857)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	expected := `{"geometryType":"esriGeometryPoint","spatialReference":{"wkid":3857},"features":[{"attributes":{},"geometry":{"x":1,"y":2,"z":3,"hasZ":true}}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect Esri JSON, got %s", data)
+	}
+}
@@ -1,7 +1,14 @@
 package geojson
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -13,7 +20,11 @@ type Feature struct {
 	BoundingBox []float64              `json:"bbox,omitempty" bson:",omitempty"`
 	Geometry    *Geometry              `json:"geometry"`
 	Properties  map[string]interface{} `json:"properties"`
-	CRS         map[string]interface{} `json:"crs,omitempty" bson:",omitempty"` // Coordinate Reference System Objects are not currently supported
+	CRS         map[string]interface{} `json:"crs,omitempty" bson:",omitempty"` // Coordinate Reference System object, see ParseCRS
+
+	// ForeignMembers holds the members of the GeoJSON object that are not
+	// part of the specification, like "title", so they survive a round trip.
+	ForeignMembers map[string]json.RawMessage `json:"-" bson:"-"`
 }
 
 // NewFeature creates and initializes a GeoJSON feature given the required attributes.
@@ -60,11 +71,69 @@ func NewCollectionFeature(geometries ...*Geometry) *Feature {
 	return NewFeature(NewCollectionGeometry(geometries...))
 }
 
+// IDString returns the id of the feature as a string, formatting numeric ids.
+// It returns false if the feature has no id.
+func (f *Feature) IDString() (string, bool) {
+	switch id := f.ID.(type) {
+	case nil:
+		return "", false
+	case string:
+		return id, true
+	case json.Number:
+		return id.String(), true
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(id), 'f', -1, 32), true
+	}
+
+	return fmt.Sprint(f.ID), true
+}
+
+// IDInt64 returns the id of the feature as an integer. String ids are
+// parsed, and an error is returned for ids that are not whole numbers.
+func (f *Feature) IDInt64() (int64, error) {
+	switch id := f.ID.(type) {
+	case int:
+		return int64(id), nil
+	case int32:
+		return int64(id), nil
+	case int64:
+		return id, nil
+	case uint32:
+		return int64(id), nil
+	case float64:
+		if id == math.Trunc(id) && math.Abs(id) < 1<<63 {
+			return int64(id), nil
+		}
+	case string:
+		return strconv.ParseInt(id, 10, 64)
+	case json.Number:
+		return id.Int64()
+	}
+
+	return 0, fmt.Errorf("feature id %v is not an integer", f.ID)
+}
+
 // MarshalJSON converts the feature object into the proper JSON.
 // It will handle the encoding of all the child geometries.
 // Alternately one can call json.Marshal(f) directly for the same result.
 func (f Feature) MarshalJSON() ([]byte, error) {
-	type feature Feature
+	return f.marshalJSON(nil, nil)
+}
+
+// marshalJSON converts the feature object into the proper JSON, with the
+// properties listed in order first, and the coordinates and the bounding
+// boxes in the number format, unless nil.
+func (f Feature) marshalJSON(order []string, format numberFormat) ([]byte, error) {
+	type feature struct {
+		ID          interface{}            `json:"id,omitempty"`
+		Type        string                 `json:"type"`
+		BoundingBox interface{}            `json:"bbox,omitempty"`
+		Geometry    interface{}            `json:"geometry"`
+		Properties  interface{}            `json:"properties"`
+		CRS         map[string]interface{} `json:"crs,omitempty"`
+	}
 
 	fea := &feature{
 		ID:       f.ID,
@@ -72,41 +141,48 @@ func (f Feature) MarshalJSON() ([]byte, error) {
 		Geometry: f.Geometry,
 	}
 
+	if format != nil && f.Geometry != nil {
+		fea.Geometry = formattedGeometry{f.Geometry, format}
+	}
 	if f.BoundingBox != nil && len(f.BoundingBox) != 0 {
-		fea.BoundingBox = f.BoundingBox
+		fea.BoundingBox = formatted(f.BoundingBox, format)
 	}
 	if f.Properties != nil && len(f.Properties) != 0 {
 		fea.Properties = f.Properties
+		if order != nil {
+			fea.Properties = orderedProperties{f.Properties, order}
+		}
 	}
 	if f.CRS != nil && len(f.CRS) != 0 {
 		fea.CRS = f.CRS
 	}
 
-	return json.Marshal(fea)
+	data, err := json.Marshal(fea)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendForeignMembers(data, f.ForeignMembers, featureMembers)
 }
 
-// MarshalBSON converts the feature object into the proper BSON.
-// It will handle the encoding of all the child geometries.
+// MarshalBSON converts the feature object into the proper BSON, with its
+// members in the order of its JSON, foreign members included. It will
+// handle the encoding of all the child geometries.
 func (f Feature) MarshalBSON() ([]byte, error) {
-	type feature Feature
-
-	fea := &feature{
-		ID:       f.ID,
-		Type:     "Feature",
-		Geometry: f.Geometry,
+	members, err := f.members()
+	if err != nil {
+		return nil, err
 	}
 
-	if f.BoundingBox != nil && len(f.BoundingBox) != 0 {
-		fea.BoundingBox = f.BoundingBox
-	}
-	if f.Properties != nil && len(f.Properties) != 0 {
-		fea.Properties = f.Properties
-	}
-	if f.CRS != nil && len(f.CRS) != 0 {
-		fea.CRS = f.CRS
+	// BSON has no numbers of arbitrary precision
+	if id, ok := f.ID.(json.Number); ok {
+		if n, err := id.Int64(); err == nil {
+			members[0].value = n
+		} else if x, err := id.Float64(); err == nil {
+			members[0].value = x
+		}
 	}
-
-	return bson.Marshal(fea)
+	return bson.Marshal(bsonDocument(members))
 }
 
 // UnmarshalFeature decodes the data into a GeoJSON feature.
@@ -120,3 +196,176 @@ func UnmarshalFeature(data []byte) (*Feature, error) {
 
 	return f, nil
 }
+
+// UnmarshalJSON decodes the data into a GeoJSON feature.
+// This fulfills the json.Unmarshaler interface.
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	if err := DefaultDecodeLimits.checkJSON(data); err != nil {
+		return err
+	}
+
+	var object map[string]interface{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return err
+	}
+
+	return decodeFeature(f, object, false)
+}
+
+// Scan implements the sql.Scanner interface allowing
+// feature structs to be passed into rows.Scan(...interface{})
+// The columns must be received as GeoJSON Feature.
+// When using PostGIS a row would need to be wrapped in ST_AsGeoJSON.
+func (f *Feature) Scan(value interface{}) error {
+	var data []byte
+
+	switch value.(type) {
+	case string:
+		data = []byte(value.(string))
+	case []byte:
+		data = value.([]byte)
+	default:
+		return errors.New("unable to parse this type into geojson")
+	}
+
+	return f.UnmarshalJSON(data)
+}
+
+// UnmarshalBSON decodes the data into a GeoJSON feature.
+// This fulfills the bson.Unmarshaler interface.
+func (f *Feature) UnmarshalBSON(data []byte) error {
+	if err := DefaultDecodeLimits.checkBSON(data); err != nil {
+		return err
+	}
+
+	var object map[string]interface{}
+	err := bson.Unmarshal(data, &object)
+	if err != nil {
+		return err
+	}
+	convertAToArray(&object)
+	if err := DefaultDecodeLimits.checkObject(object); err != nil {
+		return err
+	}
+
+	// MarshalBSON used to store the bounding box under the field name
+	if bb, ok := object["boundingbox"]; ok {
+		object["bbox"] = bb
+		delete(object, "boundingbox")
+	}
+
+	return decodeFeature(f, object, false)
+}
+
+func decodeFeature(f *Feature, object map[string]interface{}, permissive bool) error {
+	if t, ok := object["type"]; ok {
+		s, ok := t.(string)
+		if !ok {
+			return decodeError("/type", t, "a string")
+		}
+		f.Type = s
+	}
+
+	switch id := object["id"].(type) {
+	case nil, string, json.Number, float64, int32, int64:
+		f.ID = id
+	default:
+		return decodeError("/id", id, "a string or a number")
+	}
+
+	bb, err := decodeBoundingBox(object["bbox"])
+	if err != nil {
+		return locate(err, "/bbox")
+	}
+	f.BoundingBox = bb
+
+	switch g := object["geometry"].(type) {
+	case nil:
+		f.Geometry = nil
+	case map[string]interface{}:
+		f.Geometry = &Geometry{}
+		if err := decodeGeometry(f.Geometry, g, permissive); err != nil {
+			return locate(err, "/geometry")
+		}
+	default:
+		return decodeError("/geometry", g, "a geometry object or null")
+	}
+
+	switch p := object["properties"].(type) {
+	case nil:
+		f.Properties = nil
+	case map[string]interface{}:
+		f.Properties = p
+	default:
+		return decodeError("/properties", p, "an object or null")
+	}
+
+	switch c := object["crs"].(type) {
+	case nil:
+		f.CRS = nil
+	case map[string]interface{}:
+		f.CRS = c
+	default:
+		return decodeError("/crs", c, "an object")
+	}
+
+	f.ForeignMembers, err = decodeForeignMembers(object, featureMembers)
+	return err
+}
+
+// unmarshalObject decodes the JSON object of a feature or of a feature
+// collection, its numbers as float64 like json.Unmarshal does, but the ids
+// of the features as json.Number, so they keep all their digits.
+func unmarshalObject(data []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var object map[string]interface{}
+	if err := d.Decode(&object); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level value")
+	}
+
+	for key, v := range object {
+		if key == "id" {
+			continue
+		}
+		var err error
+		if object[key], err = floatNumbers(v, key == "features"); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// floatNumbers returns the value with its json.Number replaced by float64,
+// in place, but the ids of its objects if ids is true.
+func floatNumbers(v interface{}, ids bool) (interface{}, error) {
+	var err error
+	switch v := v.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, &json.UnmarshalTypeError{Value: "number " + string(v), Type: reflect.TypeOf(f)}
+		}
+		return f, nil
+	case []interface{}:
+		for i, e := range v {
+			if v[i], err = floatNumbers(e, ids); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		for key, e := range v {
+			if ids && key == "id" {
+				continue
+			}
+			if v[key], err = floatNumbers(e, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
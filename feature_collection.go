@@ -7,6 +7,8 @@ package geojson
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 )
 
 // A FeatureCollection correlates to a GeoJSON feature collection.
@@ -14,7 +16,11 @@ type FeatureCollection struct {
 	Type        string                 `json:"type"`
 	BoundingBox []float64              `json:"bbox,omitempty"`
 	Features    []*Feature             `json:"features"`
-	CRS         map[string]interface{} `json:"crs,omitempty"` // Coordinate Reference System Objects are not currently supported
+	CRS         map[string]interface{} `json:"crs,omitempty"` // Coordinate Reference System object, see ParseCRS
+
+	// ForeignMembers holds the members of the GeoJSON object that are not
+	// part of the specification, like "name", so they survive a round trip.
+	ForeignMembers map[string]json.RawMessage `json:"-"`
 }
 
 // NewFeatureCollection creates and initializes a new feature collection.
@@ -26,11 +32,178 @@ func NewFeatureCollection() *FeatureCollection {
 }
 
 // AddFeature appends a feature to the collection.
+// If the collection has a bounding box, two or three dimensional, it is
+// expanded to cover the feature. A collection without bounding box is
+// left without one, see ComputeBoundingBox. The editing methods keep no
+// spatial index: a PackedRTree of the collection must be built again after
+// them.
 func (fc *FeatureCollection) AddFeature(feature *Feature) *FeatureCollection {
 	fc.Features = append(fc.Features, feature)
+	fc.expandBoundingBox(feature)
 	return fc
 }
 
+// RemoveFeature removes the feature at index i from the collection and returns it.
+// If the collection has a bounding box, it is only recomputed when the
+// removed feature touched its edges.
+func (fc *FeatureCollection) RemoveFeature(i int) *Feature {
+	removed := fc.Features[i]
+
+	copy(fc.Features[i:], fc.Features[i+1:])
+	fc.Features[len(fc.Features)-1] = nil
+	fc.Features = fc.Features[:len(fc.Features)-1]
+
+	fc.shrinkBoundingBox(removed)
+	return removed
+}
+
+// ReplaceFeature replaces the feature at index i by the given feature
+// and returns the replaced one.
+// If the collection has a bounding box, it is kept up to date.
+func (fc *FeatureCollection) ReplaceFeature(i int, feature *Feature) *Feature {
+	replaced := fc.Features[i]
+	fc.Features[i] = feature
+
+	fc.shrinkBoundingBox(replaced)
+	fc.expandBoundingBox(feature)
+	return replaced
+}
+
+// insertFeature inserts the feature at index i, shifting the following features.
+func (fc *FeatureCollection) insertFeature(i int, feature *Feature) {
+	fc.Features = append(fc.Features, nil)
+	copy(fc.Features[i+1:], fc.Features[i:])
+	fc.Features[i] = feature
+	fc.expandBoundingBox(feature)
+}
+
+// expandBoundingBox grows a two or three dimensional collection bounding box
+// to cover the feature.
+func (fc *FeatureCollection) expandBoundingBox(f *Feature) {
+	n := boundingBoxDimensions(fc.BoundingBox)
+	if n == 0 {
+		return
+	}
+
+	bb := featureExtent(f)
+	if bb == nil {
+		return
+	}
+	growBoundingBox(fc.BoundingBox, bb)
+}
+
+// shrinkBoundingBox recomputes a two or three dimensional collection
+// bounding box after the feature is gone, if the feature was on its edge.
+func (fc *FeatureCollection) shrinkBoundingBox(f *Feature) {
+	n := boundingBoxDimensions(fc.BoundingBox)
+	if n == 0 {
+		return
+	}
+
+	bb := featureExtent(f)
+	if bb == nil {
+		return
+	}
+	if insideBoundingBox(bb, fc.BoundingBox) {
+		return
+	}
+
+	var result []float64
+	for _, f := range fc.Features {
+		fbb := featureExtent(f)
+		if fbb == nil {
+			continue
+		}
+		if result == nil {
+			// the altitudes of the old box are kept if no feature has any
+			result = append([]float64(nil), fc.BoundingBox...)
+			copy(result, fbb[:2])
+			copy(result[n:], fbb[len(fbb)/2:len(fbb)/2+2])
+			if n == 3 && len(fbb) == 6 {
+				result[2], result[5] = fbb[2], fbb[5]
+			}
+			continue
+		}
+		growBoundingBox(result, fbb)
+	}
+
+	if result == nil {
+		fc.BoundingBox = nil
+		return
+	}
+	copy(fc.BoundingBox, result)
+}
+
+// boundingBoxDimensions returns the number of dimensions of a bounding
+// box, 2 or 3, 0 if it is not a bounding box of the collection to keep up
+// to date.
+func boundingBoxDimensions(bb []float64) int {
+	switch len(bb) {
+	case 4:
+		return 2
+	case 6:
+		return 3
+	}
+	return 0
+}
+
+// featureExtent returns the bounding box of the feature, its own if it has
+// one, or else the one of its geometry, see Geometry.ComputeBoundingBox.
+// Neither is changed.
+func featureExtent(f *Feature) []float64 {
+	if f == nil {
+		return nil
+	}
+	if boundingBoxDimensions(f.BoundingBox) != 0 {
+		return f.BoundingBox
+	}
+
+	b := &bboxBuilder{}
+	b.geometry(f.Geometry)
+	return b.bbox()
+}
+
+// growBoundingBox grows the bounding box to cover the other one, in the
+// dimensions of both, with the narrowest longitude range, which may cross
+// the antimeridian like those of ComputeBoundingBox.
+func growBoundingBox(bb, other []float64) {
+	n, m := len(bb)/2, len(other)/2
+	b := &bboxBuilder{}
+	b.addLongitudes(bb[0], bb[n])
+	b.addLongitudes(other[0], other[m])
+	bb[0], bb[n] = b.longitudeExtent()
+
+	bb[1] = math.Min(bb[1], other[1])
+	bb[n+1] = math.Max(bb[n+1], other[m+1])
+	if n == 3 && m == 3 {
+		bb[2] = math.Min(bb[2], other[2])
+		bb[5] = math.Max(bb[5], other[5])
+	}
+}
+
+// insideBoundingBox reports whether the bounding box is strictly inside the
+// other one, away from its edges, in the dimensions of both. Boxes crossing
+// the antimeridian are handled.
+func insideBoundingBox(bb, other []float64) bool {
+	n, m := len(bb)/2, len(other)/2
+
+	// longitudes east of the west edge of the other box
+	offset := func(lon float64) float64 {
+		return math.Mod(math.Mod(lon-other[0], 360)+360, 360)
+	}
+	west, east, width := offset(bb[0]), offset(bb[n]), offset(other[m])
+	if west == 0 || west > east || east >= width {
+		return false
+	}
+
+	for i := 1; i < n && i < m; i++ {
+		if bb[i] <= other[i] || bb[n+i] >= other[m+i] {
+			return false
+		}
+	}
+	return true
+}
+
 // MarshalJSON converts the feature collection object into the proper JSON.
 // It will handle the encoding of all the child features and geometries.
 // Alternately one can call json.Marshal(fc) directly for the same result.
@@ -54,7 +227,47 @@ func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
 		fcol.CRS = fc.CRS
 	}
 
-	return json.Marshal(fcol)
+	data, err := json.Marshal(fcol)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendForeignMembers(data, fc.ForeignMembers, featureCollectionMembers)
+}
+
+// UnmarshalJSON decodes the data into a GeoJSON feature collection.
+// This fulfills the json.Unmarshaler interface.
+func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
+	if err := DefaultDecodeLimits.checkJSON(data); err != nil {
+		return err
+	}
+
+	var object map[string]interface{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return err
+	}
+
+	return decodeFeatureCollection(fc, object, nil, false)
+}
+
+// Scan implements the sql.Scanner interface allowing
+// feature collection structs to be passed into rows.Scan(...interface{})
+// The columns must be received as GeoJSON FeatureCollection, as built
+// with json_build_object and json_agg in PostGIS.
+func (fc *FeatureCollection) Scan(value interface{}) error {
+	var data []byte
+
+	switch value.(type) {
+	case string:
+		data = []byte(value.(string))
+	case []byte:
+		data = value.([]byte)
+	default:
+		return errors.New("unable to parse this type into geojson")
+	}
+
+	return fc.UnmarshalJSON(data)
 }
 
 // UnmarshalFeatureCollection decodes the data into a GeoJSON feature collection.
@@ -68,3 +281,65 @@ func UnmarshalFeatureCollection(data []byte) (*FeatureCollection, error) {
 
 	return fc, nil
 }
+
+// decodeFeatureCollection decodes the object into the feature collection,
+// calling decoded, if not nil, for each feature as soon as it is decoded.
+// Unknown geometry types are kept when permissive, see decodeGeometry.
+func decodeFeatureCollection(fc *FeatureCollection, object map[string]interface{}, decoded func(i int, f *Feature) error, permissive bool) error {
+	if t, ok := object["type"]; ok {
+		s, ok := t.(string)
+		if !ok {
+			return decodeError("/type", t, "a string")
+		}
+		fc.Type = s
+	}
+
+	bb, err := decodeBoundingBox(object["bbox"])
+	if err != nil {
+		return locate(err, "/bbox")
+	}
+	fc.BoundingBox = bb
+
+	switch fs := object["features"].(type) {
+	case nil:
+		fc.Features = nil
+	case []interface{}:
+		fc.Features = make([]*Feature, 0, len(fs))
+		for i, v := range fs {
+			if v == nil {
+				fc.Features = append(fc.Features, nil)
+				continue
+			}
+
+			vmap, ok := v.(map[string]interface{})
+			if !ok {
+				return decodeError(indexPointer("/features", i), v, "a feature object")
+			}
+
+			f := &Feature{}
+			if err := decodeFeature(f, vmap, permissive); err != nil {
+				return locate(err, indexPointer("/features", i))
+			}
+			if decoded != nil {
+				if err := decoded(i, f); err != nil {
+					return err
+				}
+			}
+			fc.Features = append(fc.Features, f)
+		}
+	default:
+		return decodeError("/features", fs, "an array of features")
+	}
+
+	switch c := object["crs"].(type) {
+	case nil:
+		fc.CRS = nil
+	case map[string]interface{}:
+		fc.CRS = c
+	default:
+		return decodeError("/crs", c, "an object")
+	}
+
+	fc.ForeignMembers, err = decodeForeignMembers(object, featureCollectionMembers)
+	return err
+}
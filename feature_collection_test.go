@@ -3,6 +3,7 @@ package geojson
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -105,3 +106,146 @@ func TestFeatureCollectionMarshalValue(t *testing.T) {
 		t.Errorf("json should set features object to at least empty array")
 	}
 }
+
+func TestFeatureCollectionBoundingBoxMaintenance(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{0, 0, 1, 1}
+
+	fc.AddFeature(NewPointFeature([]float64{0, 0}))
+	fc.AddFeature(NewPointFeature([]float64{1, 1}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{-1, 0.5}, {3, 0.5}}))
+
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{-1, 0, 3, 1}) {
+		t.Errorf("should expand bounding box, got %v", fc.BoundingBox)
+	}
+
+	removed := fc.RemoveFeature(2)
+	if !removed.Geometry.IsLineString() || len(fc.Features) != 2 {
+		t.Fatalf("should remove the line string feature")
+	}
+
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{0, 0, 1, 1}) {
+		t.Errorf("should shrink bounding box, got %v", fc.BoundingBox)
+	}
+
+	fc.ReplaceFeature(1, NewPointFeature([]float64{0.5, 2}))
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{0, 0, 0.5, 2}) {
+		t.Errorf("should update bounding box, got %v", fc.BoundingBox)
+	}
+}
+
+func TestFeatureCollectionBoundingBoxMaintenanceAntimeridian(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{170, 0}))
+	fc.AddFeature(NewPointFeature([]float64{-170, 10}))
+	fc.BoundingBox = fc.ComputeBoundingBox()
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{170, 0, -170, 10}) {
+		t.Fatalf("should cross the antimeridian, but got %v", fc.BoundingBox)
+	}
+
+	fc.AddFeature(NewPointFeature([]float64{175, 5}))
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{170, 0, -170, 10}) {
+		t.Errorf("should keep bounding box, got %v", fc.BoundingBox)
+	}
+
+	fc.AddFeature(NewPointFeature([]float64{-160, 20}))
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{170, 0, -160, 20}) {
+		t.Errorf("should expand bounding box eastward, got %v", fc.BoundingBox)
+	}
+
+	fc.RemoveFeature(3)
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{170, 0, -170, 10}) {
+		t.Errorf("should shrink bounding box, got %v", fc.BoundingBox)
+	}
+
+	fc.RemoveFeature(0)
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{175, 5, -170, 10}) {
+		t.Errorf("should shrink bounding box across the antimeridian, got %v", fc.BoundingBox)
+	}
+}
+
+func TestFeatureCollectionBoundingBoxMaintenance3D(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{0, 0, 0, 1, 1, 10}
+
+	fc.AddFeature(NewPointFeature([]float64{0, 0, 0}))
+	fc.AddFeature(NewPointFeature([]float64{1, 1, 10}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{-1, 0.5, 20}, {3, 0.5, 5}}))
+	fc.AddFeature(NewPointFeature([]float64{0.5, 2}))
+
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{-1, 0, 0, 3, 2, 20}) {
+		t.Errorf("should expand bounding box, got %v", fc.BoundingBox)
+	}
+
+	fc.RemoveFeature(2)
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{0, 0, 0, 1, 2, 10}) {
+		t.Errorf("should shrink bounding box, got %v", fc.BoundingBox)
+	}
+}
+
+func TestFeatureCollectionWithoutBoundingBox(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+	fc.RemoveFeature(0)
+
+	if fc.BoundingBox != nil {
+		t.Errorf("should not add a bounding box, got %v", fc.BoundingBox)
+	}
+}
+
+func TestUnmarshalFeatureCollectionQGIS(t *testing.T) {
+	rawJSON := `
+	  { "type": "FeatureCollection",
+	    "name": "parcels",
+	    "crs": { "type": "name", "properties": { "name": "urn:ogc:def:crs:OGC:1.3:CRS84" } },
+	    "bbox": [4.1, 50.1, 4.3, 50.3],
+	    "features": [
+	      { "type": "Feature", "properties": { "id": 1 }, "geometry": null },
+	      { "type": "Feature", "properties": { "id": 2 }, "geometry": { "type": "Point", "coordinates": [4.2, 50.2] } }
+	    ]
+	  }`
+
+	fc, err := UnmarshalFeatureCollection([]byte(rawJSON))
+	if err != nil {
+		t.Fatalf("should unmarshal feature collection without issue, err %v", err)
+	}
+
+	if len(fc.Features) != 2 || fc.Features[0].Geometry != nil || !fc.Features[1].Geometry.IsPoint() {
+		t.Errorf("incorrect features, got %v", fc.Features)
+	}
+
+	if len(fc.BoundingBox) != 4 || fc.CRS["type"] != "name" {
+		t.Errorf("should decode bbox and crs, got %v and %v", fc.BoundingBox, fc.CRS)
+	}
+}
+
+func TestUnmarshalFeatureCollectionInvalid(t *testing.T) {
+	cases := []string{
+		`{"type": "FeatureCollection", "features": {}}`,
+		`{"type": "FeatureCollection", "features": [1]}`,
+		`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Point", "coordinates": "a"}}]}`,
+	}
+
+	for _, c := range cases {
+		if _, err := UnmarshalFeatureCollection([]byte(c)); err == nil {
+			t.Errorf("should return error for %s", c)
+		}
+	}
+}
+
+func TestFeatureCollectionScan(t *testing.T) {
+	fc := &FeatureCollection{}
+
+	err := fc.Scan(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}]}`)
+	if err != nil {
+		t.Fatalf("should parse without error, got %v", err)
+	}
+
+	if len(fc.Features) != 1 {
+		t.Errorf("should have 1 feature but got %d", len(fc.Features))
+	}
+
+	if err := fc.Scan(123); err == nil {
+		t.Errorf("should return error if not the correct data type")
+	}
+}
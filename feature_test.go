@@ -141,6 +141,40 @@ func TestUnmarshalFeatureID(t *testing.T) {
 	}
 }
 
+func TestDecodeOptionsUseNumberIDs(t *testing.T) {
+	rawJSON := `{"type":"FeatureCollection","features":[` +
+		`{"id":9007199254740993,"type":"Feature","geometry":null,"properties":{"n":9007199254740993}},` +
+		`{"id":1.50,"type":"Feature","geometry":null,"properties":null}]}`
+
+	fc, err := DecodeOptions{UseNumberIDs: true}.UnmarshalFeatureCollection([]byte(rawJSON))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if id, err := fc.Features[0].IDInt64(); err != nil || id != 9007199254740993 {
+		t.Errorf("should keep all the digits of the id, got %v, %v", id, err)
+	}
+	if s, _ := fc.Features[1].IDString(); s != "1.50" {
+		t.Errorf("should keep the id as written, got %s", s)
+	}
+	if _, ok := fc.Features[0].Properties["n"].(float64); !ok {
+		t.Errorf("should decode the numbers of properties as float64, got %T", fc.Features[0].Properties["n"])
+	}
+
+	data, err := json.Marshal(fc.Features[1])
+	if err != nil || string(data) != `{"id":1.50,"type":"Feature","geometry":null,"properties":null}` {
+		t.Errorf("should marshal the id as decoded, got %s, %v", data, err)
+	}
+	f, err := DecodeOptions{UseNumberIDs: true}.UnmarshalFeature([]byte(`{"id":9007199254740993,"type":"Feature","geometry":null,"properties":null}`))
+	if err != nil || f.ID != json.Number("9007199254740993") {
+		t.Errorf("should keep the id of a feature, got %v, %v", f, err)
+	}
+
+	f, err = DecodeOptions{}.UnmarshalFeature([]byte(`{"id":7,"type":"Feature","geometry":null,"properties":null}`))
+	if err != nil || f.ID != 7.0 {
+		t.Errorf("should decode the id as float64 by default, got %T %v, %v", f.ID, f.ID, err)
+	}
+}
+
 func TestBSON(t *testing.T) {
 	f := NewFeature(NewPointGeometry([]float64{1, 2}))
 	f.ID = "abcd"
@@ -172,3 +206,225 @@ func TestBSON(t *testing.T) {
 		t.Fatalf("should still contain right coordinates after BSON round trip but got %v", (*ff.Geometry).Point[1])
 	}
 }
+
+func TestBSONForeignMembers(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.ID = json.Number("9007199254740993")
+	f.BoundingBox = []float64{1, 2, 1, 2}
+	f.ForeignMembers = map[string]json.RawMessage{"title": json.RawMessage(`"x"`), "meta": json.RawMessage(`{"tags":["a"],"n":1}`)}
+	f.Geometry.ForeignMembers = map[string]json.RawMessage{"name": json.RawMessage(`"p"`)}
+	f.Geometry.SetCRS(NewEPSGCRS(3857))
+
+	blob, err := bson.Marshal(f)
+	if err != nil {
+		t.Fatalf("should marshal to bson, but got %v", err)
+	}
+	var ff Feature
+	if err := bson.Unmarshal(blob, &ff); err != nil {
+		t.Fatalf("should unmarshal from bson, but got %v", err)
+	}
+
+	if string(ff.ForeignMembers["title"]) != `"x"` || string(ff.ForeignMembers["meta"]) != `{"n":1,"tags":["a"]}` {
+		t.Errorf("should round trip the foreign members, got %s", ff.ForeignMembers)
+	}
+	if string(ff.Geometry.ForeignMembers["name"]) != `"p"` {
+		t.Errorf("should round trip the foreign members of the geometry, got %s", ff.Geometry.ForeignMembers)
+	}
+	if crs, err := ff.Geometry.ParseCRS(); err != nil || crs == nil {
+		t.Errorf("should round trip the crs of the geometry, got %v", ff.Geometry.CRS)
+	} else if code, _ := crs.EPSG(); code != 3857 {
+		t.Errorf("should round trip the crs of the geometry, got %v", ff.Geometry.CRS)
+	}
+	if id, err := ff.IDInt64(); err != nil || id != 9007199254740993 {
+		t.Errorf("should round trip the id, got %v", ff.ID)
+	}
+	if len(ff.BoundingBox) != 4 {
+		t.Errorf("should round trip the bounding box, got %v", ff.BoundingBox)
+	}
+}
+
+func TestFeatureScan(t *testing.T) {
+	cases := []struct {
+		name  string
+		value interface{}
+	}{
+		{
+			name:  "Scan from bytes",
+			value: []byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-93.787988,32.392335]},"properties":{"a":"b"}}`),
+		},
+		{
+			name:  "Scan from string",
+			value: `{"type":"Feature","geometry":{"type":"Point","coordinates":[-93.787988,32.392335]},"properties":{"a":"b"}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &Feature{}
+
+			err := f.Scan(tc.value)
+			if err != nil {
+				t.Fatalf("should parse without error, got %v", err)
+			}
+
+			if !f.Geometry.IsPoint() {
+				t.Errorf("should be point, but got %v", f.Geometry)
+			}
+
+			if f.PropertyMustString("a") != "b" {
+				t.Errorf("incorrect properties, got %v", f.Properties)
+			}
+		})
+	}
+
+	if err := (&Feature{}).Scan(123); err == nil {
+		t.Errorf("should return error if not the correct data type")
+	}
+}
+
+func TestUnmarshalFeatureInvalid(t *testing.T) {
+	cases := []string{
+		`{"type": 1}`,
+		`{"type": "Feature", "geometry": 1}`,
+		`{"type": "Feature", "properties": []}`,
+		`{"type": "Feature", "bbox": "a"}`,
+	}
+
+	for _, c := range cases {
+		if _, err := UnmarshalFeature([]byte(c)); err == nil {
+			t.Errorf("should return error for %s", c)
+		}
+	}
+}
+
+func TestBSONBoundingBoxAndProperties(t *testing.T) {
+	f := NewFeature(NewPointGeometry([]float64{1, 2}))
+	f.BoundingBox = []float64{1, 2, 1, 2}
+	f.SetProperty("list", []interface{}{"a", "b"})
+	blob, err := bson.Marshal(*f)
+	if err != nil {
+		t.Fatalf("should marshal to bson just fine but got %v", err)
+	}
+
+	var ff Feature
+	err = bson.Unmarshal(blob, &ff)
+	if err != nil {
+		t.Fatalf("should unmarshal from bson just fine but got %v", err)
+	}
+
+	if len(ff.BoundingBox) != 4 {
+		t.Errorf("should have same bounding box after BSON round trip but got %v", ff.BoundingBox)
+	}
+	if l, ok := ff.Properties["list"].([]interface{}); !ok || len(l) != 2 {
+		t.Errorf("should have same properties after BSON round trip but got %#v", ff.Properties)
+	}
+}
+
+func TestUnmarshalFeatureIDInvalid(t *testing.T) {
+	rawJSON := `{"type": "Feature", "id": {"a": 1}, "geometry": null}`
+
+	if _, err := UnmarshalFeature([]byte(rawJSON)); err == nil {
+		t.Errorf("should return error for object id")
+	}
+}
+
+func TestFeatureIDRoundTrip(t *testing.T) {
+	for _, raw := range []string{
+		`{"id":"007","type":"Feature","geometry":null,"properties":null}`,
+		`{"id":7,"type":"Feature","geometry":null,"properties":null}`,
+		`{"id":7.5,"type":"Feature","geometry":null,"properties":null}`,
+	} {
+		f, err := UnmarshalFeature([]byte(raw))
+		if err != nil {
+			t.Fatalf("should unmarshal feature without issue, err %v", err)
+		}
+
+		data, err := f.MarshalJSON()
+		if err != nil {
+			t.Fatalf("should marshal, %v", err)
+		}
+
+		if string(data) != raw {
+			t.Errorf("should round trip id, got %v", string(data))
+		}
+	}
+}
+
+func TestFeatureIDAccessors(t *testing.T) {
+	cases := []struct {
+		id     interface{}
+		str    string
+		i      int64
+		failed bool
+	}{
+		{id: "007", str: "007", i: 7},
+		{id: "abc", str: "abc", failed: true},
+		{id: 123.0, str: "123", i: 123},
+		{id: 1.5, str: "1.5", failed: true},
+		{id: int32(5), str: "5", i: 5},
+		{id: 9007199254740993, str: "9007199254740993", i: 9007199254740993},
+	}
+
+	for _, tc := range cases {
+		f := &Feature{ID: tc.id}
+
+		if s, ok := f.IDString(); !ok || s != tc.str {
+			t.Errorf("incorrect string for %v, got %v", tc.id, s)
+		}
+
+		i, err := f.IDInt64()
+		if tc.failed {
+			if err == nil {
+				t.Errorf("should return error for %v", tc.id)
+			}
+			continue
+		}
+		if err != nil || i != tc.i {
+			t.Errorf("incorrect int for %v, got %v, %v", tc.id, i, err)
+		}
+	}
+
+	if _, ok := (&Feature{}).IDString(); ok {
+		t.Errorf("should report missing id")
+	}
+}
+
+func TestFeatureNullGeometry(t *testing.T) {
+	raw := `{"type":"Feature","geometry":null,"properties":{"name":"unlocated"}}`
+
+	f, err := UnmarshalFeature([]byte(raw))
+	if err != nil {
+		t.Fatalf("should unmarshal feature with null geometry, but got %v", err)
+	}
+	if f.Geometry != nil {
+		t.Errorf("should have nil geometry, got %v", f.Geometry)
+	}
+
+	data, err := f.MarshalJSON()
+	if err != nil {
+		t.Fatalf("should marshal, %v", err)
+	}
+	if string(data) != raw {
+		t.Errorf("should marshal geometry back as null, got %v", string(data))
+	}
+
+	fc, err := UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","features":[` + raw + `,{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}]}`))
+	if err != nil {
+		t.Fatalf("should unmarshal collection with null geometries, but got %v", err)
+	}
+	if len(fc.Features) != 2 || fc.Features[0].Geometry != nil || fc.Features[1].Geometry == nil {
+		t.Errorf("should keep null and set geometries apart, got %v", fc.Features)
+	}
+
+	blob, err := bson.Marshal(*f)
+	if err != nil {
+		t.Fatalf("should marshal to bson just fine but got %v", err)
+	}
+	var ff Feature
+	if err := bson.Unmarshal(blob, &ff); err != nil {
+		t.Fatalf("should unmarshal from bson just fine but got %v", err)
+	}
+	if ff.Geometry != nil || ff.Properties["name"] != "unlocated" {
+		t.Errorf("should keep null geometry after BSON round trip but got %v", ff)
+	}
+}
@@ -0,0 +1,618 @@
+/*
+Package fgb reads and writes FlatGeobuf, the streamable binary encoding of
+features based on flatbuffers, with an optional packed R-tree index of
+their bounding boxes.
+
+Files are read as a whole, or streamed feature by feature with a Reader.
+UnmarshalBoundingBox reads the features intersecting a bounding box only,
+searching the index when the file has one.
+
+Numbers in properties decode as float64, as they do from GeoJSON. FlatGeobuf
+features have no ID, so the IDs of the features are not written.
+*/
+package fgb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// magic starts FlatGeobuf files: version 3.0.
+const magic = "fgb\x03fgb\x00"
+
+// maxBufferSize limits the size of the header and of the features, to not
+// allocate what a corrupt size prefix asks.
+const maxBufferSize = 1 << 30
+
+// The geometry types of the FlatGeobuf schema.
+const (
+	typeUnknown            = 0
+	typePoint              = 1
+	typeLineString         = 2
+	typePolygon            = 3
+	typeMultiPoint         = 4
+	typeMultiLineString    = 5
+	typeMultiPolygon       = 6
+	typeGeometryCollection = 7
+)
+
+var geometryTypes = map[geojson.GeometryType]uint8{
+	geojson.GeometryPoint:           typePoint,
+	geojson.GeometryLineString:      typeLineString,
+	geojson.GeometryPolygon:         typePolygon,
+	geojson.GeometryMultiPoint:      typeMultiPoint,
+	geojson.GeometryMultiLineString: typeMultiLineString,
+	geojson.GeometryMultiPolygon:    typeMultiPolygon,
+	geojson.GeometryCollection:      typeGeometryCollection,
+}
+
+// A ColumnType is the type of the values of a property column.
+type ColumnType uint8
+
+// The column types of the FlatGeobuf schema.
+const (
+	ColumnByte ColumnType = iota
+	ColumnUByte
+	ColumnBool
+	ColumnShort
+	ColumnUShort
+	ColumnInt
+	ColumnUInt
+	ColumnLong
+	ColumnULong
+	ColumnFloat
+	ColumnDouble
+	ColumnString
+	ColumnJSON
+	ColumnDateTime
+	ColumnBinary
+)
+
+// A Column describes a property of the features.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// A Header describes the features of a FlatGeobuf file.
+type Header struct {
+	Name string
+
+	// Envelope is the bounding box of the features, if known.
+	Envelope []float64
+
+	// GeometryType is the type of the geometries of all the features,
+	// empty if they have different types.
+	GeometryType geojson.GeometryType
+
+	HasZ    bool
+	Columns []Column
+
+	// FeaturesCount is the number of features, 0 if unknown.
+	FeaturesCount uint64
+
+	// IndexNodeSize is the number of children of the nodes of the packed
+	// R-tree, 0 if the file has no index.
+	IndexNodeSize int
+
+	// CRS is the EPSG code of the coordinate reference system, 0 if
+	// unknown.
+	CRS int
+}
+
+// indexSize returns the size in bytes of the packed R-tree of the header,
+// 0 if there is none, or an error if it is larger than max bytes. The
+// count of features is not trusted: it is checked before computing sizes
+// from it, so a corrupt header can not overflow them.
+func (h *Header) indexSize(max int64) (int64, error) {
+	if h.IndexNodeSize < 2 || h.FeaturesCount == 0 {
+		return 0, nil
+	}
+
+	const nodeSize = 40
+	maxNodes := uint64(max / nodeSize)
+	if h.FeaturesCount > maxNodes {
+		return 0, fmt.Errorf("index of %d features is larger than the data", h.FeaturesCount)
+	}
+
+	// the root is above the leaves, even of a single feature
+	n := h.FeaturesCount
+	numNodes := n
+	for {
+		n = (n + uint64(h.IndexNodeSize) - 1) / uint64(h.IndexNodeSize)
+		numNodes += n
+		if numNodes > maxNodes {
+			return 0, fmt.Errorf("index of %d features is larger than the data", h.FeaturesCount)
+		}
+		if n <= 1 {
+			break
+		}
+	}
+	return int64(numNodes) * nodeSize, nil
+}
+
+// Unmarshal decodes all the features of the FlatGeobuf data.
+func Unmarshal(data []byte) (*geojson.FeatureCollection, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geojson.NewFeatureCollection()
+	fc.CRS = crsMember(r.header.CRS)
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			return fc, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fc.AddFeature(f)
+	}
+}
+
+// UnmarshalBoundingBox decodes the features of the FlatGeobuf data whose
+// bounding boxes intersect the two dimensional bounding box. When the data
+// has a packed R-tree, only the matching features are read, in the order
+// of the file.
+func UnmarshalBoundingBox(data []byte, bbox []float64) (*geojson.FeatureCollection, error) {
+	if len(bbox) < 4 {
+		return nil, fmt.Errorf("bounding box needs 4 values, got %d", len(bbox))
+	}
+
+	h, headerSize, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	indexSize, err := h.indexSize(int64(len(data) - headerSize))
+	if err != nil {
+		return nil, err
+	}
+	if indexSize == 0 {
+		fc, err := Unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+		var features []*geojson.Feature
+		for _, f := range fc.Features {
+			if bb := boundingBox(f.Geometry); bb != nil && intersects(bb, bbox) {
+				features = append(features, f)
+			}
+		}
+		fc.Features = features
+		return fc, nil
+	}
+
+	indexEnd := int64(headerSize) + indexSize
+	tree, err := loadIndex(data[headerSize:indexEnd], h)
+	if err != nil {
+		return nil, err
+	}
+
+	features := data[indexEnd:]
+	fc := geojson.NewFeatureCollection()
+	fc.CRS = crsMember(h.CRS)
+	for _, offset := range tree.Search(bbox) {
+		if offset < 0 || offset > len(features)-4 {
+			return nil, fmt.Errorf("index offset %d out of range", offset)
+		}
+		size := int(binary.LittleEndian.Uint32(features[offset:]))
+		if size > len(features)-offset-4 {
+			return nil, fmt.Errorf("feature at %d: truncated", offset)
+		}
+
+		f, err := decodeFeature(features[offset+4:offset+4+size], h)
+		if err != nil {
+			return nil, fmt.Errorf("feature at %d: %v", offset, err)
+		}
+		fc.AddFeature(f)
+	}
+
+	return fc, nil
+}
+
+// loadIndex loads the nodes of the packed R-tree of the header, laid out as
+// the packed R-trees of the geojson package.
+func loadIndex(nodes []byte, h *Header) (*geojson.PackedRTree, error) {
+	data := make([]byte, 16, 16+len(nodes))
+	copy(data, "GJRT")
+	binary.LittleEndian.PutUint16(data[4:], uint16(h.IndexNodeSize))
+	binary.LittleEndian.PutUint64(data[8:], h.FeaturesCount)
+	return geojson.LoadPackedRTree(append(data, nodes...))
+}
+
+// A Reader streams the features of FlatGeobuf data, skipping the index.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	index  int
+}
+
+// NewReader reads the header of the FlatGeobuf data of r, and returns a
+// reader of its features.
+func NewReader(r io.Reader) (*Reader, error) {
+	// the index can not be larger than the rest of readers of known length
+	remaining := int64(math.MaxInt64)
+	if l, ok := r.(interface{ Len() int }); ok {
+		remaining = int64(l.Len())
+	}
+	br := bufio.NewReader(r)
+
+	head := make([]byte, len(magic)+4)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, errors.New("not a FlatGeobuf file")
+	}
+	size := binary.LittleEndian.Uint32(head[len(magic):])
+	if err := checkMagic(head); err != nil {
+		return nil, err
+	}
+	if size > maxBufferSize {
+		return nil, fmt.Errorf("header of %d bytes is too large", size)
+	}
+
+	data := make([]byte, len(head)+int(size))
+	copy(data, head)
+	if _, err := io.ReadFull(br, data[len(head):]); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	h, _, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := h.indexSize(remaining - int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		if _, err := io.CopyN(ioutil.Discard, br, n); err != nil {
+			return nil, errors.New("truncated index")
+		}
+	}
+
+	return &Reader{r: br, header: h}, nil
+}
+
+// Header returns the header of the data.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read returns the next feature, or io.EOF after the last one.
+func (r *Reader) Read() (*geojson.Feature, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.r, prefix[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("feature %d: truncated", r.index)
+	}
+
+	size := binary.LittleEndian.Uint32(prefix[:])
+	if size > maxBufferSize {
+		return nil, fmt.Errorf("feature %d: %d bytes is too large", r.index, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, fmt.Errorf("feature %d: truncated", r.index)
+	}
+
+	f, err := decodeFeature(data, r.header)
+	if err != nil {
+		return nil, fmt.Errorf("feature %d: %v", r.index, err)
+	}
+	r.index++
+	return f, nil
+}
+
+func checkMagic(data []byte) error {
+	if len(data) < len(magic) || string(data[:3]) != "fgb" || string(data[4:7]) != "fgb" {
+		return errors.New("not a FlatGeobuf file")
+	}
+	if data[3] != magic[3] {
+		return fmt.Errorf("unsupported FlatGeobuf version %d", data[3])
+	}
+	return nil
+}
+
+// decodeHeader decodes the header of the data, returning it with the
+// position of the index, right after it.
+func decodeHeader(data []byte) (*Header, int, error) {
+	if err := checkMagic(data); err != nil {
+		return nil, 0, err
+	}
+	if len(data) < len(magic)+4 {
+		return nil, 0, errors.New("truncated header")
+	}
+	size := int64(binary.LittleEndian.Uint32(data[len(magic):]))
+	end := int64(len(magic)) + 4 + size
+	if end > int64(len(data)) {
+		return nil, 0, errors.New("truncated header")
+	}
+
+	t := rootTable(data[len(magic)+4 : end])
+	h := &Header{
+		Name:          t.string(0),
+		Envelope:      t.float64s(1),
+		HasZ:          t.bool(3),
+		FeaturesCount: t.uint64(8, 0),
+		IndexNodeSize: int(t.uint16(9, 16)),
+	}
+	if geomType := t.uint8(2, typeUnknown); geomType != typeUnknown {
+		for gt, ft := range geometryTypes {
+			if ft == geomType {
+				h.GeometryType = gt
+			}
+		}
+		if h.GeometryType == "" {
+			return nil, 0, fmt.Errorf("unsupported geometry type %d", geomType)
+		}
+	}
+	h.Columns = decodeColumns(t.tables(7))
+	if crs, ok := t.table(10); ok {
+		h.CRS = int(crs.int32(1, 0))
+	}
+	if t.r.err != nil {
+		return nil, 0, fmt.Errorf("header: %v", t.r.err)
+	}
+
+	return h, int(end), nil
+}
+
+func decodeColumns(tables []table) []Column {
+	columns := make([]Column, len(tables))
+	for i, c := range tables {
+		columns[i] = Column{Name: c.string(0), Type: ColumnType(c.uint8(1, 0))}
+	}
+	return columns
+}
+
+// decodeFeature decodes the flatbuffer of a feature, without its size
+// prefix.
+func decodeFeature(data []byte, h *Header) (*geojson.Feature, error) {
+	t := rootTable(data)
+
+	var g *geojson.Geometry
+	if gt, ok := t.table(0); ok {
+		geomType := geometryTypes[h.GeometryType]
+		var err error
+		if g, err = decodeGeometry(gt, geomType, h.HasZ); err != nil {
+			return nil, err
+		}
+	}
+
+	f := geojson.NewFeature(g)
+	columns := h.Columns
+	if c := t.tables(2); len(c) > 0 {
+		columns = decodeColumns(c)
+	}
+	if err := decodeProperties(f, t.bytes(1), columns); err != nil {
+		return nil, err
+	}
+
+	if t.r.err != nil {
+		return nil, t.r.err
+	}
+	return f, nil
+}
+
+// decodeGeometry decodes the geometry table, of the type of the header or
+// else of its own.
+func decodeGeometry(t table, geomType uint8, hasZ bool) (*geojson.Geometry, error) {
+	if geomType == typeUnknown {
+		geomType = t.uint8(6, typeUnknown)
+	}
+
+	switch geomType {
+	case typeMultiPolygon:
+		var polygons [][][][]float64
+		for _, part := range t.tables(7) {
+			p, err := decodeGeometry(part, typePolygon, hasZ)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				polygons = append(polygons, p.Polygon)
+			}
+		}
+		if len(polygons) == 0 {
+			return nil, nil
+		}
+		return geojson.NewMultiPolygonGeometry(polygons...), nil
+	case typeGeometryCollection:
+		var geometries []*geojson.Geometry
+		for _, part := range t.tables(7) {
+			g, err := decodeGeometry(part, typeUnknown, hasZ)
+			if err != nil {
+				return nil, err
+			}
+			if g != nil {
+				geometries = append(geometries, g)
+			}
+		}
+		return geojson.NewCollectionGeometry(geometries...), nil
+	}
+
+	xy := t.float64s(1)
+	z := t.float64s(2)
+	if len(xy)%2 != 0 {
+		return nil, fmt.Errorf("odd number of coordinates")
+	}
+	if len(z) != 0 && len(z) != len(xy)/2 {
+		return nil, fmt.Errorf("%d altitudes for %d positions", len(z), len(xy)/2)
+	}
+
+	positions := make([][]float64, len(xy)/2)
+	for i := range positions {
+		positions[i] = []float64{xy[2*i], xy[2*i+1]}
+		if len(z) != 0 {
+			positions[i] = append(positions[i], z[i])
+		}
+	}
+
+	var paths [][][]float64
+	start := 0
+	for _, end := range t.uint32s(0) {
+		if int(end) < start || int(end) > len(positions) {
+			return nil, fmt.Errorf("part end %d out of range", end)
+		}
+		paths = append(paths, positions[start:end])
+		start = int(end)
+	}
+	if paths == nil && len(positions) > 0 {
+		paths = [][][]float64{positions}
+	}
+
+	switch geomType {
+	case typePoint:
+		if len(positions) == 0 {
+			return nil, nil
+		}
+		return geojson.NewPointGeometry(positions[0]), nil
+	case typeMultiPoint:
+		if len(positions) == 0 {
+			return nil, nil
+		}
+		return geojson.NewMultiPointGeometry(positions...), nil
+	case typeLineString:
+		if len(positions) == 0 {
+			return nil, nil
+		}
+		return geojson.NewLineStringGeometry(positions), nil
+	case typeMultiLineString:
+		if len(paths) == 0 {
+			return nil, nil
+		}
+		return geojson.NewMultiLineStringGeometry(paths...), nil
+	case typePolygon:
+		if len(paths) == 0 {
+			return nil, nil
+		}
+		return geojson.NewPolygonGeometry(paths), nil
+	}
+
+	return nil, fmt.Errorf("unsupported geometry type %d", geomType)
+}
+
+// decodeProperties decodes the properties of the feature, each the index
+// of its column followed by its value.
+func decodeProperties(f *geojson.Feature, data []byte, columns []Column) error {
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return errors.New("truncated properties")
+		}
+		i := int(binary.LittleEndian.Uint16(data))
+		if i >= len(columns) {
+			return fmt.Errorf("column %d out of range", i)
+		}
+		data = data[2:]
+
+		c := columns[i]
+		size := 0
+		switch c.Type {
+		case ColumnByte, ColumnUByte, ColumnBool:
+			size = 1
+		case ColumnShort, ColumnUShort:
+			size = 2
+		case ColumnInt, ColumnUInt, ColumnFloat:
+			size = 4
+		case ColumnLong, ColumnULong, ColumnDouble:
+			size = 8
+		case ColumnString, ColumnJSON, ColumnDateTime, ColumnBinary:
+			if len(data) < 4 {
+				return fmt.Errorf("column %q: truncated", c.Name)
+			}
+			size = 4 + int(binary.LittleEndian.Uint32(data))
+		default:
+			return fmt.Errorf("column %q: unknown type %d", c.Name, c.Type)
+		}
+		if size < 0 || size > len(data) {
+			return fmt.Errorf("column %q: truncated", c.Name)
+		}
+		v := data[:size]
+		data = data[size:]
+
+		var value interface{}
+		switch c.Type {
+		case ColumnByte:
+			value = float64(int8(v[0]))
+		case ColumnUByte:
+			value = float64(v[0])
+		case ColumnBool:
+			value = v[0] != 0
+		case ColumnShort:
+			value = float64(int16(binary.LittleEndian.Uint16(v)))
+		case ColumnUShort:
+			value = float64(binary.LittleEndian.Uint16(v))
+		case ColumnInt:
+			value = float64(int32(binary.LittleEndian.Uint32(v)))
+		case ColumnUInt:
+			value = float64(binary.LittleEndian.Uint32(v))
+		case ColumnLong:
+			value = float64(int64(binary.LittleEndian.Uint64(v)))
+		case ColumnULong:
+			value = float64(binary.LittleEndian.Uint64(v))
+		case ColumnFloat:
+			value = float64(math.Float32frombits(binary.LittleEndian.Uint32(v)))
+		case ColumnDouble:
+			value = math.Float64frombits(binary.LittleEndian.Uint64(v))
+		case ColumnString, ColumnDateTime:
+			value = string(v[4:])
+		case ColumnJSON:
+			if err := json.Unmarshal(v[4:], &value); err != nil {
+				return fmt.Errorf("column %q: %v", c.Name, err)
+			}
+		case ColumnBinary:
+			value = append([]byte(nil), v[4:]...)
+		}
+		f.SetProperty(c.Name, value)
+	}
+
+	return nil
+}
+
+// crsMember returns the CRS member of a collection in the EPSG code, nil
+// for WGS 84 or unknown.
+func crsMember(code int) map[string]interface{} {
+	if code == 0 || code == 4326 {
+		return nil
+	}
+	return map[string]interface{}{
+		"type":       "name",
+		"properties": map[string]interface{}{"name": fmt.Sprintf("EPSG:%d", code)},
+	}
+}
+
+// boundingBox returns the two dimensional bounding box of the geometry,
+// nil if it has no positions.
+func boundingBox(g *geojson.Geometry) []float64 {
+	var bbox []float64
+	walkPositions(g, func(p []float64) {
+		if len(p) < 2 {
+			return
+		}
+		if bbox == nil {
+			bbox = []float64{p[0], p[1], p[0], p[1]}
+			return
+		}
+		bbox[0] = math.Min(bbox[0], p[0])
+		bbox[1] = math.Min(bbox[1], p[1])
+		bbox[2] = math.Max(bbox[2], p[0])
+		bbox[3] = math.Max(bbox[3], p[1])
+	})
+	return bbox
+}
+
+func intersects(a, b []float64) bool {
+	return a[0] <= b[2] && b[0] <= a[2] && a[1] <= b[3] && b[1] <= a[3]
+}
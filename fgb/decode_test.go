@@ -0,0 +1,177 @@
+package fgb
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func gridCollection() *geojson.FeatureCollection {
+	fc := geojson.NewFeatureCollection()
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			f := geojson.NewPointFeature([]float64{float64(x), float64(y)})
+			f.SetProperty("cell", float64(10*x+y))
+			fc.AddFeature(f)
+		}
+	}
+	return fc
+}
+
+func TestUnmarshalBoundingBox(t *testing.T) {
+	fc := gridCollection()
+	bbox := []float64{2.5, 3.5, 4.5, 5}
+
+	for _, nodeSize := range []int{0, 2, -1} {
+		data, err := Marshal(fc, Options{NodeSize: nodeSize})
+		if err != nil {
+			t.Fatalf("should encode, but got %v", err)
+		}
+
+		found, err := UnmarshalBoundingBox(data, bbox)
+		if err != nil {
+			t.Fatalf("should decode, but got %v", err)
+		}
+		if len(found.Features) != 4 {
+			t.Fatalf("node size %d: should find 4 features, got %d", nodeSize, len(found.Features))
+		}
+		cells := make(map[float64]bool)
+		for _, f := range found.Features {
+			cells[f.PropertyMustFloat64("cell")] = true
+		}
+		for _, c := range []float64{34, 35, 44, 45} {
+			if !cells[c] {
+				t.Errorf("node size %d: should find cell %v, got %v", nodeSize, c, cells)
+			}
+		}
+	}
+
+	single := geojson.NewFeatureCollection().AddFeature(fc.Features[0])
+	data, _ := Marshal(single, Options{})
+	if found, err := UnmarshalBoundingBox(data, []float64{-1, -1, 1, 1}); err != nil || len(found.Features) != 1 {
+		t.Errorf("should find the single feature, got %v", err)
+	}
+
+	data, _ = Marshal(fc, Options{})
+	if _, err := UnmarshalBoundingBox(data, []float64{0, 0}); err == nil {
+		t.Errorf("should reject an incomplete bounding box")
+	}
+	if _, err := UnmarshalBoundingBox(data[:len(data)-100], []float64{0, 0, 10, 10}); err == nil {
+		t.Errorf("should detect a truncated feature")
+	}
+}
+
+func TestReader(t *testing.T) {
+	data, _ := Marshal(gridCollection(), Options{})
+
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("should read the header, but got %v", err)
+	}
+	n := 0
+	for {
+		_, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("should read, but got %v", err)
+		}
+		n++
+	}
+	if n != 100 {
+		t.Errorf("should read 100 features, got %d", n)
+	}
+
+	r, _ = NewReader(bytes.NewReader(data[:len(data)-3]))
+	var last error
+	for last == nil {
+		_, last = r.Read()
+	}
+	if last == io.EOF {
+		t.Errorf("should detect the truncated last feature")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	data, _ := Marshal(gridCollection(), Options{})
+
+	cases := map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("fgc\x03fgb\x00\x00\x00\x00\x00"),
+		"version":   []byte("fgb\x02fgb\x00\x00\x00\x00\x00"),
+		"header":    data[:20],
+		"index":     data[:200],
+		"too large": []byte("fgb\x03fgb\x00\xff\xff\xff\xff"),
+	}
+	for name, data := range cases {
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("should reject the %s", name)
+		}
+	}
+}
+
+func TestCorruptFeaturesCount(t *testing.T) {
+	for _, count := range []uint64{1<<64 - 1, 4611686018427400000} {
+		h := &tableObject{}
+		h.uint64(8, count)
+		data := append([]byte(magic), finishBuffer(h)...)
+
+		if _, err := NewReader(bytes.NewReader(data)); err == nil {
+			t.Errorf("count %d: should reject the index of the reader", count)
+		}
+		if _, err := NewReader(io.MultiReader(bytes.NewReader(data))); err == nil {
+			t.Errorf("count %d: should reject the index of the stream", count)
+		}
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("count %d: should reject the index", count)
+		}
+		if _, err := UnmarshalBoundingBox(data, []float64{0, 0, 1, 1}); err == nil {
+			t.Errorf("count %d: should reject the index of the bounding box", count)
+		}
+	}
+}
+
+func TestDecodeProperties(t *testing.T) {
+	columns := []Column{
+		{"byte", ColumnByte}, {"ubyte", ColumnUByte}, {"short", ColumnShort}, {"ushort", ColumnUShort},
+		{"int", ColumnInt}, {"uint", ColumnUInt}, {"float", ColumnFloat}, {"date", ColumnDateTime},
+		{"binary", ColumnBinary},
+	}
+	data := []byte{
+		0, 0, 0xff,
+		1, 0, 0xff,
+		2, 0, 0xfe, 0xff,
+		3, 0, 0xfe, 0xff,
+		4, 0, 0xfd, 0xff, 0xff, 0xff,
+		5, 0, 3, 0, 0, 0,
+		6, 0, 0, 0, 0xc0, 0x3f,
+		7, 0, 10, 0, 0, 0, '2', '0', '2', '1', '-', '0', '1', '-', '0', '1',
+		8, 0, 2, 0, 0, 0, 7, 8,
+	}
+
+	f := geojson.NewFeature(nil)
+	if err := decodeProperties(f, data, columns); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	expected := map[string]interface{}{
+		"byte": -1.0, "ubyte": 255.0, "short": -2.0, "ushort": 65534.0, "int": -3.0, "uint": 3.0, "float": 1.5, "date": "2021-01-01",
+	}
+	for k, v := range expected {
+		if f.Properties[k] != v {
+			t.Errorf("incorrect %s, expected %v, got %#v", k, v, f.Properties[k])
+		}
+	}
+	if b, ok := f.Properties["binary"].([]byte); !ok || !bytes.Equal(b, []byte{7, 8}) {
+		t.Errorf("incorrect binary, got %#v", f.Properties["binary"])
+	}
+
+	if err := decodeProperties(f, []byte{9, 0, 1}, columns); err == nil {
+		t.Errorf("should reject an unknown column")
+	}
+	if err := decodeProperties(f, []byte{4, 0, 1}, columns); err == nil {
+		t.Errorf("should reject a truncated value")
+	}
+}
@@ -0,0 +1,467 @@
+package fgb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Options configures the FlatGeobuf encoding.
+type Options struct {
+	// Name is the name of the dataset written in the header.
+	Name string
+
+	// NodeSize is the number of children of the nodes of the packed
+	// R-tree, geojson.DefaultNodeSize if zero. No index is written if it
+	// is negative.
+	NodeSize int
+}
+
+// Marshal encodes the features of the collection as FlatGeobuf. With an
+// index, the features are written in the Hilbert order of the packed
+// R-tree; the index is left out when a feature has no position, as every
+// feature must be in it. The columns of the properties are inferred from
+// their values: booleans, strings, integers and floats, or else JSON. A
+// collection CRS named "EPSG:n" sets the CRS of the header, WGS 84 else.
+func Marshal(fc *geojson.FeatureCollection, opts Options) ([]byte, error) {
+	var features []*geojson.Feature
+	for _, f := range fc.Features {
+		if f != nil {
+			features = append(features, f)
+		}
+	}
+
+	header := headerObject(fc, features, opts)
+
+	// the packed R-tree of the geojson package has the layout of
+	// FlatGeobuf, and points at the indexes of the features
+	var nodes []byte
+	numNodes := 0
+	if opts.NodeSize >= 0 && len(features) > 0 {
+		indexed := &geojson.FeatureCollection{Features: features}
+		tree, err := geojson.NewPackedRTree(indexed, opts.NodeSize)
+		if err != nil {
+			return nil, err
+		}
+		if tree.Len() == len(features) {
+			data, _ := tree.MarshalBinary()
+			nodes = data[16:]
+			numNodes = len(nodes) / 40
+
+			ordered := make([]*geojson.Feature, len(features))
+			for i := range ordered {
+				leaf := nodes[(numNodes-len(features)+i)*40:]
+				ordered[i] = features[binary.LittleEndian.Uint64(leaf[32:])]
+			}
+			features = ordered
+		}
+	}
+
+	nodeSize := 0
+	if nodes != nil {
+		nodeSize = opts.NodeSize
+		if nodeSize == 0 {
+			nodeSize = geojson.DefaultNodeSize
+		}
+	}
+	header.uint16(9, uint16(nodeSize))
+
+	columns, columnTypes := inferColumns(features)
+	if len(columns) > 0 {
+		header.object(7, columns)
+	}
+
+	var body []byte
+	hasZ := hasZ(features)
+	for i, f := range features {
+		if nodes != nil {
+			leaf := nodes[(numNodes-len(features)+i)*40:]
+			binary.LittleEndian.PutUint64(leaf[32:], uint64(len(body)))
+		}
+
+		feature := &tableObject{}
+		if f.Geometry != nil {
+			g, err := geometryObject(f.Geometry, hasZ, true)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+			feature.object(0, g)
+		}
+		if props := encodeProperties(f.Properties, columnTypes); len(props) > 0 {
+			feature.object(1, bytesObject(props))
+		}
+		body = append(body, finishBuffer(feature)...)
+	}
+
+	data := []byte(magic)
+	data = append(data, finishBuffer(header)...)
+	data = append(data, nodes...)
+	return append(data, body...), nil
+}
+
+// headerObject returns the header table of the features, without its index
+// node size and columns.
+func headerObject(fc *geojson.FeatureCollection, features []*geojson.Feature, opts Options) *tableObject {
+	h := &tableObject{}
+	if opts.Name != "" {
+		h.object(0, stringObject(opts.Name))
+	}
+
+	var envelope []float64
+	var geomType geojson.GeometryType
+	mixed := false
+	for _, f := range features {
+		if bb := boundingBox(f.Geometry); bb != nil {
+			if envelope == nil {
+				envelope = bb
+			} else {
+				envelope[0] = math.Min(envelope[0], bb[0])
+				envelope[1] = math.Min(envelope[1], bb[1])
+				envelope[2] = math.Max(envelope[2], bb[2])
+				envelope[3] = math.Max(envelope[3], bb[3])
+			}
+		}
+		if f.Geometry == nil {
+			continue
+		}
+		if geomType != "" && geomType != f.Geometry.Type {
+			mixed = true
+		}
+		geomType = f.Geometry.Type
+	}
+	if envelope != nil {
+		h.object(1, float64sObject(envelope))
+	}
+	if !mixed {
+		h.uint8(2, geometryTypes[geomType])
+	}
+	h.bool(3, hasZ(features))
+	h.uint64(8, uint64(len(features)))
+
+	crs := &tableObject{}
+	crs.object(0, stringObject("EPSG"))
+	crs.int32(1, int32(epsgCode(fc.CRS)))
+	h.object(10, crs)
+
+	return h
+}
+
+// epsgCode returns the EPSG code of a named CRS member, 4326 if there is
+// none.
+func epsgCode(crs map[string]interface{}) int {
+	props, _ := crs["properties"].(map[string]interface{})
+	name, _ := props["name"].(string)
+	if i := strings.LastIndex(name, ":"); i >= 0 && strings.Contains(strings.ToUpper(name), "EPSG") {
+		if code, err := strconv.Atoi(name[i+1:]); err == nil {
+			return code
+		}
+	}
+	return 4326
+}
+
+func hasZ(features []*geojson.Feature) bool {
+	found := false
+	for _, f := range features {
+		walkPositions(f.Geometry, func(p []float64) {
+			if len(p) > 2 {
+				found = true
+			}
+		})
+	}
+	return found
+}
+
+// geometryObject returns the geometry table of g, with its type if typed.
+// The parts of multi polygons are untyped polygons.
+func geometryObject(g *geojson.Geometry, hasZ, typed bool) (*tableObject, error) {
+	t := &tableObject{}
+	if typed {
+		t.uint8(6, geometryTypes[g.Type])
+	}
+
+	var paths [][][]float64
+	switch g.Type {
+	case geojson.GeometryPoint:
+		paths = [][][]float64{{g.Point}}
+	case geojson.GeometryMultiPoint:
+		paths = [][][]float64{g.MultiPoint}
+	case geojson.GeometryLineString:
+		paths = [][][]float64{g.LineString}
+	case geojson.GeometryMultiLineString:
+		paths = g.MultiLineString
+	case geojson.GeometryPolygon:
+		paths = g.Polygon
+	case geojson.GeometryMultiPolygon, geojson.GeometryCollection:
+		var parts tablesObject
+		if g.Type == geojson.GeometryMultiPolygon {
+			for _, p := range g.MultiPolygon {
+				part, err := geometryObject(geojson.NewPolygonGeometry(p), hasZ, false)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, part)
+			}
+		} else {
+			for _, c := range g.Geometries {
+				if c == nil {
+					continue
+				}
+				part, err := geometryObject(c, hasZ, true)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, part)
+			}
+		}
+		t.object(7, parts)
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+
+	var xy, z []float64
+	var ends []uint32
+	for _, path := range paths {
+		for _, p := range path {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("position %v needs at least 2 coordinates", p)
+			}
+			xy = append(xy, p[0], p[1])
+			if hasZ {
+				alt := 0.0
+				if len(p) > 2 {
+					alt = p[2]
+				}
+				z = append(z, alt)
+			}
+		}
+		ends = append(ends, uint32(len(xy)/2))
+	}
+
+	if len(ends) > 1 {
+		t.object(0, uint32sObject(ends))
+	}
+	t.object(1, float64sObject(xy))
+	if hasZ {
+		t.object(2, float64sObject(z))
+	}
+	return t, nil
+}
+
+// inferColumns returns the columns of the properties of the features, sorted
+// by name, and their types by name.
+func inferColumns(features []*geojson.Feature) (tablesObject, map[string]ColumnType) {
+	types := make(map[string]ColumnType)
+	for _, f := range features {
+		for k, v := range f.Properties {
+			t, ok := columnType(v)
+			if !ok {
+				continue
+			}
+			if known, seen := types[k]; seen && known != t {
+				switch {
+				case isNumber(known) && isNumber(t):
+					t = ColumnDouble
+				default:
+					t = ColumnJSON
+				}
+			}
+			types[k] = t
+		}
+	}
+
+	names := make([]string, 0, len(types))
+	for k := range types {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var columns tablesObject
+	for _, name := range names {
+		c := &tableObject{}
+		c.object(0, stringObject(name))
+		c.uint8(1, uint8(types[name]))
+		columns = append(columns, c)
+	}
+	return columns, types
+}
+
+// columnType returns the type of the column of the value, false for null.
+func columnType(v interface{}) (ColumnType, bool) {
+	switch v.(type) {
+	case nil:
+		return 0, false
+	case bool:
+		return ColumnBool, true
+	case string:
+		return ColumnString, true
+	case int, int8, int16, int32, int64:
+		return ColumnLong, true
+	case uint, uint8, uint16, uint32, uint64:
+		return ColumnULong, true
+	case float32, float64:
+		return ColumnDouble, true
+	}
+	return ColumnJSON, true
+}
+
+func isNumber(t ColumnType) bool {
+	return t == ColumnLong || t == ColumnULong || t == ColumnDouble
+}
+
+// encodeProperties encodes the properties in the order of their columns,
+// leaving out nulls.
+func encodeProperties(properties map[string]interface{}, types map[string]ColumnType) []byte {
+	names := make([]string, 0, len(properties))
+	for k := range properties {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int, len(types))
+	sorted := make([]string, 0, len(types))
+	for k := range types {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for i, k := range sorted {
+		index[k] = i
+	}
+
+	var data []byte
+	var b [8]byte
+	for _, k := range names {
+		v := properties[k]
+		if v == nil {
+			continue
+		}
+
+		binary.LittleEndian.PutUint16(b[:], uint16(index[k]))
+		data = append(data, b[:2]...)
+
+		switch types[k] {
+		case ColumnBool:
+			if v.(bool) {
+				data = append(data, 1)
+			} else {
+				data = append(data, 0)
+			}
+		case ColumnString:
+			data = appendString(data, v.(string))
+		case ColumnLong:
+			binary.LittleEndian.PutUint64(b[:], uint64(toInt64(v)))
+			data = append(data, b[:]...)
+		case ColumnULong:
+			binary.LittleEndian.PutUint64(b[:], toUint64(v))
+			data = append(data, b[:]...)
+		case ColumnDouble:
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(toFloat64(v)))
+			data = append(data, b[:]...)
+		case ColumnJSON:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				encoded = []byte(strconv.Quote(fmt.Sprint(v)))
+			}
+			data = appendString(data, string(encoded))
+		}
+	}
+	return data
+}
+
+func appendString(data []byte, s string) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(s)))
+	return append(append(data, b[:]...), s...)
+}
+
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return 0
+}
+
+func toUint64(v interface{}) uint64 {
+	switch v := v.(type) {
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	}
+	return 0
+}
+
+func toFloat64(v interface{}) float64 {
+	switch v := v.(type) {
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	if t, _ := columnType(v); t == ColumnULong {
+		return float64(toUint64(v))
+	}
+	return float64(toInt64(v))
+}
+
+// walkPositions calls fn with every position of the geometry.
+func walkPositions(g *geojson.Geometry, fn func(p []float64)) {
+	if g == nil {
+		return
+	}
+	switch g.Type {
+	case geojson.GeometryPoint:
+		fn(g.Point)
+	case geojson.GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			fn(p)
+		}
+	case geojson.GeometryLineString:
+		for _, p := range g.LineString {
+			fn(p)
+		}
+	case geojson.GeometryMultiLineString, geojson.GeometryPolygon:
+		paths := g.MultiLineString
+		if g.Type == geojson.GeometryPolygon {
+			paths = g.Polygon
+		}
+		for _, path := range paths {
+			for _, p := range path {
+				fn(p)
+			}
+		}
+	case geojson.GeometryMultiPolygon:
+		for _, polygon := range g.MultiPolygon {
+			for _, ring := range polygon {
+				for _, p := range ring {
+					fn(p)
+				}
+			}
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			walkPositions(c, fn)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package fgb
+
+import (
+	"bytes"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func testCollection() *geojson.FeatureCollection {
+	fc := geojson.NewFeatureCollection()
+
+	point := geojson.NewPointFeature([]float64{4.35, 50.85})
+	point.ID = "brussels"
+	point.SetProperty("name", "Brussels")
+	point.SetProperty("population", 1200000)
+	point.SetProperty("capital", true)
+	point.SetProperty("tags", []interface{}{"city"})
+	point.SetProperty("none", nil)
+	fc.AddFeature(point)
+
+	line := geojson.NewLineStringFeature([][]float64{{0, 0}, {1, 1}})
+	line.SetProperty("name", "line")
+	line.SetProperty("population", 2.5)
+	line.SetProperty("capital", "no")
+	fc.AddFeature(line)
+
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 4}, {4, 4}, {2, 2}},
+	}))
+	fc.AddFeature(geojson.NewMultiPolygonFeature(
+		[][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		[][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}, {{5.2, 5.1}, {5.8, 5.7}, {5.8, 5.1}, {5.2, 5.1}}},
+	))
+	fc.AddFeature(geojson.NewMultiLineStringFeature([][]float64{{0, 0}, {1, 1}}, [][]float64{{2, 2}, {3, 3}}))
+	fc.AddFeature(geojson.NewFeature(geojson.NewCollectionGeometry(
+		geojson.NewPointGeometry([]float64{1, 2}),
+		geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+	)))
+	return fc
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	fc := testCollection()
+	data, err := Marshal(fc, Options{Name: "test", NodeSize: -1})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		t.Fatalf("should start with the magic bytes, got %q", data[:8])
+	}
+
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(decoded.Features) != len(fc.Features) {
+		t.Fatalf("should decode %d features, got %d", len(fc.Features), len(decoded.Features))
+	}
+
+	for i, f := range decoded.Features {
+		expected := fc.Features[i].Geometry
+		if !f.Geometry.Equal(expected) {
+			t.Errorf("incorrect geometry %d, expected %v, got %v", i, expected, f.Geometry)
+		}
+	}
+
+	p := decoded.Features[0]
+	if p.ID != nil {
+		t.Errorf("should not have an ID, got %v", p.ID)
+	}
+	if p.PropertyMustString("name") != "Brussels" || p.PropertyMustFloat64("population") != 1200000 {
+		t.Errorf("incorrect properties, got %v", p.Properties)
+	}
+	if p.Properties["capital"] != true {
+		t.Errorf("should keep a boolean of a mixed column, got %#v", p.Properties["capital"])
+	}
+	if tags, ok := p.Properties["tags"].([]interface{}); !ok || len(tags) != 1 || tags[0] != "city" {
+		t.Errorf("should keep the JSON of an array, got %#v", p.Properties["tags"])
+	}
+	if _, ok := p.Properties["none"]; ok {
+		t.Errorf("should leave out null properties, got %v", p.Properties)
+	}
+	l := decoded.Features[1]
+	if l.PropertyMustFloat64("population") != 2.5 || l.PropertyMustString("capital") != "no" {
+		t.Errorf("incorrect properties, got %v", l.Properties)
+	}
+}
+
+func TestMarshalAltitudes(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPointFeature([]float64{1, 2, 3}))
+	fc.AddFeature(geojson.NewLineStringFeature([][]float64{{0, 0}, {1, 1}}))
+
+	data, _ := Marshal(fc, Options{NodeSize: -1})
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	// all positions get an altitude when one has
+	expected := geojson.NewLineStringGeometry([][]float64{{0, 0, 0}, {1, 1, 0}})
+	if !decoded.Features[0].Geometry.Equal(fc.Features[0].Geometry) || !decoded.Features[1].Geometry.Equal(expected) {
+		t.Errorf("incorrect altitudes, got %v and %v", decoded.Features[0].Geometry, decoded.Features[1].Geometry)
+	}
+}
+
+func TestMarshalHeader(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.CRS = map[string]interface{}{
+		"type":       "name",
+		"properties": map[string]interface{}{"name": "urn:ogc:def:crs:EPSG::3857"},
+	}
+	for i := 0; i < 20; i++ {
+		f := geojson.NewPointFeature([]float64{float64(i), float64(-i)})
+		f.SetProperty("i", i)
+		fc.AddFeature(f)
+	}
+
+	data, err := Marshal(fc, Options{Name: "points", NodeSize: 4})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("should read the header, but got %v", err)
+	}
+
+	h := r.Header()
+	if h.Name != "points" || h.GeometryType != geojson.GeometryPoint || h.HasZ || h.FeaturesCount != 20 || h.IndexNodeSize != 4 || h.CRS != 3857 {
+		t.Errorf("incorrect header, got %+v", h)
+	}
+	if len(h.Envelope) != 4 || h.Envelope[0] != 0 || h.Envelope[1] != -19 || h.Envelope[2] != 19 || h.Envelope[3] != 0 {
+		t.Errorf("incorrect envelope, got %v", h.Envelope)
+	}
+	if len(h.Columns) != 1 || h.Columns[0] != (Column{Name: "i", Type: ColumnLong}) {
+		t.Errorf("incorrect columns, got %v", h.Columns)
+	}
+
+	decoded, _ := Unmarshal(data)
+	if name := decoded.CRS["properties"].(map[string]interface{})["name"]; name != "EPSG:3857" {
+		t.Errorf("incorrect CRS, got %v", decoded.CRS)
+	}
+
+	// a feature without geometry leaves out the index
+	fc.AddFeature(geojson.NewFeature(nil))
+	data, _ = Marshal(fc, Options{})
+	r, _ = NewReader(bytes.NewReader(data))
+	if r.Header().IndexNodeSize != 0 || r.Header().FeaturesCount != 21 {
+		t.Errorf("should not index, got %+v", r.Header())
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	fc := geojson.NewFeatureCollection().AddFeature(geojson.NewPointFeature([]float64{1}))
+	if _, err := Marshal(fc, Options{NodeSize: -1}); err == nil {
+		t.Errorf("should reject an invalid position")
+	}
+	if _, err := Marshal(geojson.NewFeatureCollection(), Options{NodeSize: 1}); err != nil {
+		t.Errorf("should not build an index of nothing, got %v", err)
+	}
+	fc = geojson.NewFeatureCollection().AddFeature(geojson.NewPointFeature([]float64{1, 2}))
+	if _, err := Marshal(fc, Options{NodeSize: 1}); err == nil {
+		t.Errorf("should reject a node size of 1")
+	}
+}
@@ -0,0 +1,409 @@
+package fgb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// errTruncated is the error of flatbuffers pointing beyond their data.
+var errTruncated = errors.New("truncated flatbuffer")
+
+// A table reads the fields of a flatbuffers table. Errors are kept in the
+// reader shared by the tables of a buffer, the fields read as zero values
+// after the first one.
+type table struct {
+	r   *bufferReader
+	pos int
+}
+
+// A bufferReader holds a flatbuffer and its first error.
+type bufferReader struct {
+	buf []byte
+	err error
+}
+
+// rootTable returns the root table of the flatbuffer.
+func rootTable(buf []byte) table {
+	r := &bufferReader{buf: buf}
+	return table{r: r, pos: r.offset(0)}
+}
+
+func (r *bufferReader) check(pos, n int) bool {
+	if r.err != nil {
+		return false
+	}
+	if pos < 0 || n < 0 || pos > len(r.buf)-n {
+		r.err = errTruncated
+		return false
+	}
+	return true
+}
+
+func (r *bufferReader) uint16(pos int) int {
+	if !r.check(pos, 2) {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint16(r.buf[pos:]))
+}
+
+func (r *bufferReader) uint32(pos int) uint32 {
+	if !r.check(pos, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(r.buf[pos:])
+}
+
+func (r *bufferReader) uint64(pos int) uint64 {
+	if !r.check(pos, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(r.buf[pos:])
+}
+
+// offset returns the position pointed to by the unsigned offset at pos.
+func (r *bufferReader) offset(pos int) int {
+	o := r.uint32(pos)
+	if r.err != nil {
+		return 0
+	}
+	if uint64(o) > uint64(len(r.buf)-pos) {
+		r.err = errTruncated
+		return 0
+	}
+	return pos + int(o)
+}
+
+// field returns the position of the field with the index, 0 if the table
+// does not have it.
+func (t table) field(i int) int {
+	vtable := t.pos - int(int32(t.r.uint32(t.pos)))
+	if t.r.err != nil || !t.r.check(vtable, 4) {
+		return 0
+	}
+	entry := 4 + 2*i
+	if entry >= t.r.uint16(vtable) {
+		return 0
+	}
+	o := t.r.uint16(vtable + entry)
+	if o == 0 || o >= t.r.uint16(vtable+2) {
+		return 0
+	}
+	return t.pos + o
+}
+
+func (t table) uint8(i int, def uint8) uint8 {
+	pos := t.field(i)
+	if pos == 0 || !t.r.check(pos, 1) {
+		return def
+	}
+	return t.r.buf[pos]
+}
+
+func (t table) bool(i int) bool {
+	return t.uint8(i, 0) != 0
+}
+
+func (t table) uint16(i int, def uint16) uint16 {
+	pos := t.field(i)
+	if pos == 0 {
+		return def
+	}
+	return uint16(t.r.uint16(pos))
+}
+
+func (t table) int32(i int, def int32) int32 {
+	pos := t.field(i)
+	if pos == 0 {
+		return def
+	}
+	return int32(t.r.uint32(pos))
+}
+
+func (t table) uint64(i int, def uint64) uint64 {
+	pos := t.field(i)
+	if pos == 0 {
+		return def
+	}
+	return t.r.uint64(pos)
+}
+
+// vector returns the position of the first element and the length of the
+// vector field, with elements of the given size.
+func (t table) vector(i, size int) (int, int) {
+	pos := t.field(i)
+	if pos == 0 {
+		return 0, 0
+	}
+	v := t.r.offset(pos)
+	n := int(t.r.uint32(v))
+	if !t.r.check(v+4, n*size) || n < 0 {
+		return 0, 0
+	}
+	return v + 4, n
+}
+
+func (t table) bytes(i int) []byte {
+	pos, n := t.vector(i, 1)
+	if n == 0 {
+		return nil
+	}
+	return t.r.buf[pos : pos+n]
+}
+
+func (t table) string(i int) string {
+	return string(t.bytes(i))
+}
+
+func (t table) float64s(i int) []float64 {
+	pos, n := t.vector(i, 8)
+	if n == 0 {
+		return nil
+	}
+	values := make([]float64, n)
+	for j := range values {
+		values[j] = math.Float64frombits(binary.LittleEndian.Uint64(t.r.buf[pos+8*j:]))
+	}
+	return values
+}
+
+func (t table) uint32s(i int) []uint32 {
+	pos, n := t.vector(i, 4)
+	if n == 0 {
+		return nil
+	}
+	values := make([]uint32, n)
+	for j := range values {
+		values[j] = binary.LittleEndian.Uint32(t.r.buf[pos+4*j:])
+	}
+	return values
+}
+
+// table returns the table of the field, false if absent.
+func (t table) table(i int) (table, bool) {
+	pos := t.field(i)
+	if pos == 0 {
+		return table{}, false
+	}
+	return table{r: t.r, pos: t.r.offset(pos)}, t.r.err == nil
+}
+
+func (t table) tables(i int) []table {
+	pos, n := t.vector(i, 4)
+	tables := make([]table, n)
+	for j := range tables {
+		tables[j] = table{r: t.r, pos: t.r.offset(pos + 4*j)}
+	}
+	return tables
+}
+
+// An object is written in a flatbuffer after the objects referring to it,
+// as flatbuffers offsets point forward.
+type object interface {
+	// write writes the object and returns the position it is referred at.
+	write(w *bufferWriter) int
+}
+
+// A bufferWriter writes a size prefixed flatbuffer front to back, each
+// table followed by the objects it refers to.
+type bufferWriter struct {
+	buf []byte
+}
+
+// finishBuffer returns the size prefixed flatbuffer with the root table.
+func finishBuffer(root *tableObject) []byte {
+	w := &bufferWriter{buf: make([]byte, 8)}
+	pos := root.write(w)
+	binary.LittleEndian.PutUint32(w.buf[4:], uint32(pos-4))
+	w.align(8, 0)
+	binary.LittleEndian.PutUint32(w.buf, uint32(len(w.buf)-4))
+	return w.buf
+}
+
+// align pads the buffer so that the next byte written after skip more
+// bytes is aligned to size.
+func (w *bufferWriter) align(size, skip int) {
+	for (len(w.buf)+skip)%size != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *bufferWriter) putUint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+// refer writes the objects, patching the offsets at the given positions
+// to point at them.
+func (w *bufferWriter) refer(positions []int, objects []object) {
+	for i, o := range objects {
+		pos := o.write(w)
+		binary.LittleEndian.PutUint32(w.buf[positions[i]:], uint32(pos-positions[i]))
+	}
+}
+
+// A vectorObject is a vector of scalars, or a string, already encoded.
+type vectorObject struct {
+	data     []byte
+	size     int
+	isString bool
+}
+
+func stringObject(s string) *vectorObject {
+	return &vectorObject{data: []byte(s), size: 1, isString: true}
+}
+
+func bytesObject(b []byte) *vectorObject {
+	return &vectorObject{data: b, size: 1}
+}
+
+func float64sObject(values []float64) *vectorObject {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return &vectorObject{data: data, size: 8}
+}
+
+func uint32sObject(values []uint32) *vectorObject {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return &vectorObject{data: data, size: 4}
+}
+
+func (v *vectorObject) write(w *bufferWriter) int {
+	align := 4
+	if v.size > align {
+		align = v.size
+	}
+	w.align(align, 4)
+	pos := len(w.buf)
+	w.putUint32(uint32(len(v.data) / v.size))
+	w.buf = append(w.buf, v.data...)
+	if v.isString {
+		w.buf = append(w.buf, 0)
+	}
+	return pos
+}
+
+// A tablesObject is a vector of tables.
+type tablesObject []*tableObject
+
+func (v tablesObject) write(w *bufferWriter) int {
+	w.align(4, 0)
+	pos := len(w.buf)
+	w.putUint32(uint32(len(v)))
+
+	positions := make([]int, len(v))
+	objects := make([]object, len(v))
+	for i, t := range v {
+		positions[i] = len(w.buf)
+		objects[i] = t
+		w.putUint32(0)
+	}
+	w.refer(positions, objects)
+	return pos
+}
+
+// A tableObject is a table, its fields set by index.
+type tableObject struct {
+	fields []tableField
+}
+
+// A tableField is a scalar, encoded, or an object.
+type tableField struct {
+	index  int
+	scalar []byte
+	object object
+}
+
+func (t *tableObject) scalar(i int, size int, v uint64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	t.fields = append(t.fields, tableField{index: i, scalar: b[:size]})
+}
+
+func (t *tableObject) uint8(i int, v uint8) {
+	t.scalar(i, 1, uint64(v))
+}
+
+func (t *tableObject) bool(i int, v bool) {
+	if v {
+		t.uint8(i, 1)
+	}
+}
+
+func (t *tableObject) uint16(i int, v uint16) {
+	t.scalar(i, 2, uint64(v))
+}
+
+func (t *tableObject) int32(i int, v int32) {
+	t.scalar(i, 4, uint64(uint32(v)))
+}
+
+func (t *tableObject) uint64(i int, v uint64) {
+	t.scalar(i, 8, v)
+}
+
+func (t *tableObject) object(i int, o object) {
+	t.fields = append(t.fields, tableField{index: i, object: o})
+}
+
+func (t *tableObject) write(w *bufferWriter) int {
+	// lay out the fields after the offset to the vtable, largest first
+	// so that they are aligned
+	numFields := 0
+	offsets := make(map[int]int)
+	size := 4
+	for _, n := range []int{8, 4, 2, 1} {
+		for _, f := range t.fields {
+			fieldSize := len(f.scalar)
+			if f.object != nil {
+				fieldSize = 4
+			}
+			if fieldSize != n {
+				continue
+			}
+			offsets[f.index] = size
+			size += n
+			if f.index+1 > numFields {
+				numFields = f.index + 1
+			}
+		}
+	}
+
+	// the vtable is written before the table
+	vtableSize := 4 + 2*numFields
+	w.align(2, 0)
+	vtable := len(w.buf)
+	vt := make([]byte, vtableSize)
+	binary.LittleEndian.PutUint16(vt, uint16(vtableSize))
+	binary.LittleEndian.PutUint16(vt[2:], uint16(size))
+	for i, o := range offsets {
+		binary.LittleEndian.PutUint16(vt[4+2*i:], uint16(o))
+	}
+	w.buf = append(w.buf, vt...)
+
+	w.align(8, 4)
+	pos := len(w.buf)
+	w.putUint32(uint32(pos - vtable))
+	w.buf = append(w.buf, make([]byte, size-4)...)
+
+	var positions []int
+	var objects []object
+	for _, f := range t.fields {
+		at := pos + offsets[f.index]
+		if f.object != nil {
+			positions = append(positions, at)
+			objects = append(objects, f.object)
+			continue
+		}
+		copy(w.buf[at:], f.scalar)
+	}
+	w.refer(positions, objects)
+
+	return pos
+}
@@ -0,0 +1,70 @@
+package fgb
+
+import (
+	"testing"
+)
+
+func TestFlatbufferRoundTrip(t *testing.T) {
+	child := &tableObject{}
+	child.object(0, stringObject("child"))
+	child.int32(1, -7)
+
+	root := &tableObject{}
+	root.object(0, stringObject("root"))
+	root.object(1, float64sObject([]float64{1.5, -2}))
+	root.uint8(2, 3)
+	root.bool(3, true)
+	root.bool(4, false)
+	root.object(5, uint32sObject([]uint32{4, 9}))
+	root.object(7, tablesObject{child, child})
+	root.uint64(8, 1<<40)
+	root.uint16(9, 0)
+	root.object(10, child)
+	root.object(11, bytesObject([]byte{1, 2, 3}))
+
+	buf := finishBuffer(root)
+	if len(buf)%8 != 0 {
+		t.Errorf("should pad the buffer to 8 bytes, got %d", len(buf))
+	}
+
+	r := rootTable(buf[4:])
+	if r.string(0) != "root" || r.uint8(2, 0) != 3 || !r.bool(3) || r.bool(4) || r.uint64(8, 0) != 1<<40 || r.uint16(9, 16) != 0 {
+		t.Errorf("incorrect scalars or string")
+	}
+	if v := r.float64s(1); len(v) != 2 || v[0] != 1.5 || v[1] != -2 {
+		t.Errorf("incorrect doubles, got %v", v)
+	}
+	if v := r.uint32s(5); len(v) != 2 || v[1] != 9 {
+		t.Errorf("incorrect uints, got %v", v)
+	}
+	if v := r.bytes(11); len(v) != 3 || v[2] != 3 {
+		t.Errorf("incorrect bytes, got %v", v)
+	}
+	children := r.tables(7)
+	if len(children) != 2 || children[1].string(0) != "child" || children[1].int32(1, 0) != -7 {
+		t.Errorf("incorrect tables")
+	}
+	if c, ok := r.table(10); !ok || c.int32(1, 0) != -7 {
+		t.Errorf("incorrect table")
+	}
+	if r.uint16(12, 16) != 16 || r.string(13) != "" {
+		t.Errorf("should return the defaults of absent fields")
+	}
+	if r.r.err != nil {
+		t.Errorf("should not fail, but got %v", r.r.err)
+	}
+}
+
+func TestFlatbufferTruncated(t *testing.T) {
+	root := &tableObject{}
+	root.object(0, stringObject("a longer string"))
+	buf := finishBuffer(root)
+
+	r := rootTable(buf[4 : len(buf)-12])
+	if r.string(0) != "" || r.r.err == nil {
+		t.Errorf("should detect the truncation")
+	}
+	if r := rootTable([]byte{0xff, 0xff, 0, 0}); r.string(0) != "" || r.r.err == nil {
+		t.Errorf("should detect an offset out of the buffer")
+	}
+}
@@ -0,0 +1,129 @@
+package geojson
+
+import (
+	"math"
+)
+
+// A Fidelity measures how far a generalized geometry, like a simplified
+// one or one of reduced precision, is from its original geometry.
+type Fidelity struct {
+	// MaxError and MeanError are the largest and the mean distances in
+	// meters from the positions of the original geometry to the
+	// generalized geometry. They are infinite if the generalized geometry
+	// has no positions left.
+	MaxError  float64
+	MeanError float64
+
+	// AreaDelta is the change of the area of the polygons, in percent of
+	// the original area, negative when it shrank. It is 0 for geometries
+	// without area.
+	AreaDelta float64
+}
+
+// CompareFidelity measures how far the generalized geometry is from the
+// original geometry, so pipelines can check a generalization stayed within
+// tolerance. When the geometries have as many parts, points, lines and
+// rings, as generalizations usually keep, the positions of each part of
+// the original are compared to the same part of the generalized geometry,
+// otherwise to all of it. Distances and areas are geodesic, see
+// MeasureOptions.CompareFidelity for other measurers.
+func CompareFidelity(original, generalized *Geometry) Fidelity {
+	return MeasureOptions{}.CompareFidelity(original, generalized)
+}
+
+// CompareFidelity is CompareFidelity measuring with the Measurer of the
+// options.
+func (o MeasureOptions) CompareFidelity(original, generalized *Geometry) Fidelity {
+	var fidelity Fidelity
+	m := o.measurer()
+
+	parts, targets := fidelityParts(original), fidelityParts(generalized)
+	n := 0
+	for i, part := range parts {
+		candidates := targets
+		if len(parts) == len(targets) {
+			candidates = targets[i : i+1]
+		}
+
+		for _, p := range part {
+			d := distanceToParts(p, candidates, m)
+			fidelity.MaxError = math.Max(fidelity.MaxError, d)
+			fidelity.MeanError += d
+			n++
+		}
+	}
+	if n > 0 {
+		fidelity.MeanError /= float64(n)
+	}
+
+	if area := m.Area(original); area > 0 {
+		fidelity.AreaDelta = (m.Area(generalized) - area) / area * 100
+	}
+	return fidelity
+}
+
+// fidelityParts returns the parts of the geometry compared by
+// CompareFidelity: its points, lines and rings, without the positions of
+// less than 2 coordinates.
+func fidelityParts(g *Geometry) [][][]float64 {
+	var parts [][][]float64
+	add := func(path [][]float64) {
+		var part [][]float64
+		for _, p := range path {
+			if len(p) >= 2 {
+				part = append(part, p)
+			}
+		}
+		parts = append(parts, part)
+	}
+
+	if g == nil {
+		return nil
+	}
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) > 0 {
+			add([][]float64{g.Point})
+		}
+	case GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			add([][]float64{p})
+		}
+	case GeometryLineString:
+		add(g.LineString)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			add(l)
+		}
+	case GeometryPolygon:
+		for _, r := range g.Polygon {
+			add(r)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				add(r)
+			}
+		}
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			parts = append(parts, fidelityParts(m)...)
+		}
+	}
+	return parts
+}
+
+// distanceToParts returns the distance in meters from the position to the
+// closest part, infinite if they have no positions.
+func distanceToParts(p []float64, parts [][][]float64, m Measurer) float64 {
+	d := math.Inf(1)
+	for _, part := range parts {
+		if len(part) == 1 {
+			d = math.Min(d, m.Distance(p, part[0]))
+		}
+		for i := 1; i < len(part); i++ {
+			d = math.Min(d, m.Distance(p, closestPointOnSegment(p, part[i-1], part[i])))
+		}
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareFidelity(t *testing.T) {
+	// 0.001 degree of latitude is about 111 meters
+	line := NewLineStringGeometry([][]float64{{0, 0}, {0.01, 0.001}, {0.02, 0}})
+	simplified := Simplify(line, 0.01)
+	if len(simplified.LineString) != 2 {
+		t.Fatalf("should simplify the line, got %v", simplified.LineString)
+	}
+
+	f := CompareFidelity(line, simplified)
+	if math.Abs(f.MaxError-111.2) > 0.5 {
+		t.Errorf("incorrect max error, got %v", f.MaxError)
+	}
+	if math.Abs(f.MeanError-111.2/3) > 0.5 {
+		t.Errorf("incorrect mean error, got %v", f.MeanError)
+	}
+	if f.AreaDelta != 0 {
+		t.Errorf("should have no area delta for lines, got %v", f.AreaDelta)
+	}
+
+	if f := CompareFidelity(line, line.Clone()); f.MaxError != 0 || f.MeanError != 0 {
+		t.Errorf("should have no error comparing the same geometry, got %+v", f)
+	}
+	if f := CompareFidelity(line, NewEmptyGeometry(GeometryLineString)); !math.IsInf(f.MaxError, 1) {
+		t.Errorf("should have an infinite error without positions, got %+v", f)
+	}
+}
+
+func TestCompareFidelityArea(t *testing.T) {
+	square := NewPolygonGeometry([][][]float64{{{0, 0}, {0.01, 0}, {0.01, 0.01}, {0.005, 0.011}, {0, 0.01}, {0, 0}}})
+	generalized := NewPolygonGeometry([][][]float64{{{0, 0}, {0.01, 0}, {0.01, 0.01}, {0, 0.01}, {0, 0}}})
+
+	f := CompareFidelity(square, generalized)
+	if math.Abs(f.AreaDelta-(-4.76)) > 0.05 {
+		t.Errorf("incorrect area delta, got %v", f.AreaDelta)
+	}
+	if math.Abs(f.MaxError-111.2) > 0.5 {
+		t.Errorf("incorrect max error, got %v", f.MaxError)
+	}
+
+	// different numbers of parts compare with the whole geometry
+	points := NewMultiPointGeometry([]float64{0, 0}, []float64{0.01, 0})
+	if f := CompareFidelity(points, NewPointGeometry([]float64{0, 0})); math.Abs(f.MaxError-1112) > 1 {
+		t.Errorf("incorrect max error, got %v", f.MaxError)
+	}
+}
@@ -0,0 +1,194 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// A NonFiniteError is a position with a NaN or infinite coordinate, which
+// JSON can not represent.
+type NonFiniteError struct {
+	// Pointer is the RFC 6901 JSON pointer to the position, relative to
+	// the geometry, like "/coordinates/0/3".
+	Pointer  string
+	Position []float64
+}
+
+// Error describes the non finite position.
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("position %v at %s is not finite", e.Position, e.Pointer)
+}
+
+// JSONPointer returns the pointer to the position.
+func (e *NonFiniteError) JSONPointer() string {
+	return e.Pointer
+}
+
+// CheckFinite returns a NonFiniteError for the first position, or bounding
+// box, of the geometry with a NaN or infinite coordinate, nil if there is
+// none. Geometries are checked when marshaled to JSON, but checking them
+// when built reports the problem where it comes from.
+func (g *Geometry) CheckFinite() error {
+	if g == nil {
+		return nil
+	}
+	if err := checkFinitePosition(g.BoundingBox, "/bbox"); err != nil {
+		return err
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		return checkFinitePosition(g.Point, "/coordinates")
+	case GeometryMultiPoint:
+		return checkFinitePath(g.MultiPoint, "/coordinates")
+	case GeometryLineString:
+		return checkFinitePath(g.LineString, "/coordinates")
+	case GeometryMultiLineString:
+		return checkFinitePaths(g.MultiLineString, "/coordinates")
+	case GeometryPolygon:
+		return checkFinitePaths(g.Polygon, "/coordinates")
+	case GeometryMultiPolygon:
+		for i, p := range g.MultiPolygon {
+			if err := checkFinitePaths(p, indexPointer("/coordinates", i)); err != nil {
+				return err
+			}
+		}
+	case GeometryCollection:
+		for i, m := range g.Geometries {
+			if err := m.CheckFinite(); err != nil {
+				e := *err.(*NonFiniteError)
+				e.Pointer = indexPointer("/geometries", i) + e.Pointer
+				return &e
+			}
+		}
+	}
+	return nil
+}
+
+func checkFinitePosition(p []float64, pointer string) error {
+	for _, c := range p {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return &NonFiniteError{Pointer: pointer, Position: p}
+		}
+	}
+	return nil
+}
+
+func checkFinitePath(path [][]float64, pointer string) error {
+	for i, p := range path {
+		if err := checkFinitePosition(p, indexPointer(pointer, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkFinitePaths(paths [][][]float64, pointer string) error {
+	for i, path := range paths {
+		if err := checkFinitePath(path, indexPointer(pointer, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropNonFinite removes in place the positions of the geometry with a NaN
+// or infinite coordinate, and returns how many were. A point is left empty,
+// and lines and rings may be left with too few positions. Bounding boxes
+// that are not finite are removed too. The members of geometry collections
+// are cleaned too.
+func (g *Geometry) DropNonFinite() int {
+	if g == nil {
+		return 0
+	}
+	if checkFinitePosition(g.BoundingBox, "") != nil {
+		g.BoundingBox = nil
+	}
+
+	n := 0
+	switch g.Type {
+	case GeometryPoint:
+		if checkFinitePosition(g.Point, "") != nil {
+			g.Point = []float64{}
+			n++
+		}
+	case GeometryMultiPoint:
+		g.MultiPoint, n = dropNonFinitePath(g.MultiPoint)
+	case GeometryLineString:
+		g.LineString, n = dropNonFinitePath(g.LineString)
+	case GeometryMultiLineString:
+		n = dropNonFinitePaths(g.MultiLineString)
+	case GeometryPolygon:
+		n = dropNonFinitePaths(g.Polygon)
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			n += dropNonFinitePaths(p)
+		}
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			n += m.DropNonFinite()
+		}
+	}
+	return n
+}
+
+func dropNonFinitePath(path [][]float64) ([][]float64, int) {
+	kept := path[:0]
+	for _, p := range path {
+		if checkFinitePosition(p, "") == nil {
+			kept = append(kept, p)
+		}
+	}
+	return kept, len(path) - len(kept)
+}
+
+func dropNonFinitePaths(paths [][][]float64) int {
+	n := 0
+	for i, path := range paths {
+		var dropped int
+		paths[i], dropped = dropNonFinitePath(path)
+		n += dropped
+	}
+	return n
+}
+
+// finite returns the geometry, or a copy of it without its non finite
+// positions, when the option is set and it has some.
+func (o MarshalOptions) finite(g *Geometry) *Geometry {
+	if !o.DropNonFinite || g.CheckFinite() == nil {
+		return g
+	}
+	c := g.Clone()
+	c.DropNonFinite()
+	return c
+}
+
+// finiteFeature returns the feature, or a copy of it with its geometry
+// without non finite positions, when the option is set and it has some.
+func (o MarshalOptions) finiteFeature(f *Feature) *Feature {
+	if f == nil {
+		return nil
+	}
+	g := o.finite(f.Geometry)
+	if g == f.Geometry {
+		return f
+	}
+	c := *f
+	c.Geometry = g
+	return &c
+}
+
+// finiteCollection returns the collection, or a copy of it with the
+// geometries of its features without non finite positions, when the option
+// is set and they have some.
+func (o MarshalOptions) finiteCollection(fc *FeatureCollection) *FeatureCollection {
+	if !o.DropNonFinite {
+		return fc
+	}
+	c := *fc
+	c.Features = make([]*Feature, len(fc.Features))
+	for i, f := range fc.Features {
+		c.Features[i] = o.finiteFeature(f)
+	}
+	return &c
+}
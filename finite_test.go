@@ -0,0 +1,97 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGeometryCheckFinite(t *testing.T) {
+	nan := math.NaN()
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		pointer  string
+	}{
+		{"finite", NewLineStringGeometry([][]float64{{0, 0}, {1, 1}}), ""},
+		{"empty", NewEmptyGeometry(GeometryPoint), ""},
+		{"point", NewPointGeometry([]float64{math.Inf(1), 0}), "/coordinates"},
+		{"line", NewLineStringGeometry([][]float64{{0, 0}, {1, nan}}), "/coordinates/1"},
+		{"multi polygon", NewMultiPolygonGeometry(nil, [][][]float64{{{0, 0}}, {{0, 0}, {0, math.Inf(-1)}}}), "/coordinates/1/1/1"},
+		{"collection", NewCollectionGeometry(NewPointGeometry([]float64{0, 0}), NewMultiPointGeometry([]float64{nan, 0})), "/geometries/1/coordinates/0"},
+		{"bounding box", &Geometry{Type: GeometryPoint, BoundingBox: []float64{0, 0, nan, 0}, Point: []float64{0, 0}}, "/bbox"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.geometry.CheckFinite()
+			if c.pointer == "" {
+				if err != nil {
+					t.Errorf("should be finite, but got %v", err)
+				}
+				return
+			}
+
+			var nerr *NonFiniteError
+			if !errors.As(err, &nerr) {
+				t.Fatalf("should return a non finite error, but got %v", err)
+			}
+			if nerr.Pointer != c.pointer {
+				t.Errorf("incorrect pointer, got %q", nerr.Pointer)
+			}
+
+			_, err = json.Marshal(c.geometry)
+			if !errors.As(err, &nerr) || nerr.Pointer != c.pointer {
+				t.Errorf("should fail to marshal with the non finite error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGeometryDropNonFinite(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometry([]float64{math.NaN(), 0}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, math.Inf(1)}, {1, 1}, {0, 0}}}),
+	)
+	if n := g.DropNonFinite(); n != 2 {
+		t.Errorf("incorrect number of dropped positions, got %d", n)
+	}
+	if len(g.Geometries[0].Point) != 0 {
+		t.Errorf("should empty the point, got %v", g.Geometries[0].Point)
+	}
+	if !reflect.DeepEqual(g.Geometries[1].Polygon, [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}) {
+		t.Errorf("incorrect polygon, got %v", g.Geometries[1].Polygon)
+	}
+	if g.CheckFinite() != nil {
+		t.Errorf("should be finite")
+	}
+}
+
+func TestMarshalOptionsDropNonFinite(t *testing.T) {
+	f := NewLineStringFeature([][]float64{{0, 0}, {math.NaN(), 1}, {2, 2}})
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+
+	if _, err := (MarshalOptions{}).MarshalFeatureCollection(fc); err == nil {
+		t.Fatalf("should fail on the NaN coordinate")
+	}
+
+	data, err := MarshalOptions{DropNonFinite: true, BoundingBoxes: BoundingBoxesAll}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"FeatureCollection","bbox":[0,0,2,2],"features":[{"type":"Feature","bbox":[0,0,2,2],"geometry":{"type":"LineString","bbox":[0,0,2,2],"coordinates":[[0,0],[2,2]]},"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+
+	data, err = MarshalOptions{DropNonFinite: true}.MarshalFeature(f)
+	if err != nil || string(data) != `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[2,2]]},"properties":null}` {
+		t.Errorf("incorrect feature, got %s, %v", data, err)
+	}
+	if len(f.Geometry.LineString) != 3 {
+		t.Errorf("should leave the feature as it is")
+	}
+}
@@ -0,0 +1,90 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+var (
+	geometryMembers          = []string{"type", "bbox", "coordinates", "geometries", "crs"}
+	featureMembers           = []string{"type", "id", "bbox", "geometry", "properties", "crs"}
+	featureCollectionMembers = []string{"type", "bbox", "features", "crs"}
+)
+
+// decodeForeignMembers returns the members of the object not part of the
+// reserved ones, encoded as JSON. It returns nil if there are none.
+func decodeForeignMembers(object map[string]interface{}, reserved []string) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+
+	for key, value := range object {
+		if isReservedMember(key, reserved) {
+			continue
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if members == nil {
+			members = make(map[string]json.RawMessage)
+		}
+		members[key] = data
+	}
+
+	return members, nil
+}
+
+// appendForeignMembers adds the members to the end of the encoded JSON object,
+// sorted by key. Members using a reserved name are skipped, so they can
+// not override the GeoJSON members.
+func appendForeignMembers(data []byte, members map[string]json.RawMessage, reserved []string) ([]byte, error) {
+	if len(members) == 0 {
+		return data, nil
+	}
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		if !isReservedMember(key, reserved) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return data, nil
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)+64*len(keys)))
+	buf.Write(data[:len(data)-1])
+	for i, key := range keys {
+		if i > 0 || len(data) > 2 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(members[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func isReservedMember(key string, reserved []string) bool {
+	for _, r := range reserved {
+		if key == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestForeignMembersRoundTrip(t *testing.T) {
+	rawJSON := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2],"source":"gps"}],"metadata":{"a":[1,"b"]}},"properties":null,"title":"Example"}],"name":"layer"}`
+
+	fc, err := UnmarshalFeatureCollection([]byte(rawJSON))
+	if err != nil {
+		t.Fatalf("should unmarshal feature collection without issue, err %v", err)
+	}
+
+	if string(fc.ForeignMembers["name"]) != `"layer"` {
+		t.Errorf("should decode collection foreign members, got %v", fc.ForeignMembers)
+	}
+
+	f := fc.Features[0]
+	if string(f.ForeignMembers["title"]) != `"Example"` {
+		t.Errorf("should decode feature foreign members, got %v", f.ForeignMembers)
+	}
+	if string(f.Geometry.Geometries[0].ForeignMembers["source"]) != `"gps"` {
+		t.Errorf("should decode nested geometry foreign members, got %v", f.Geometry.Geometries[0].ForeignMembers)
+	}
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	if string(data) != rawJSON {
+		t.Errorf("should round trip foreign members")
+		t.Logf("%v", string(data))
+	}
+}
+
+func TestForeignMembersCannotOverride(t *testing.T) {
+	g := NewPointGeometry([]float64{1, 2})
+	g.ForeignMembers = map[string]json.RawMessage{
+		"type":  json.RawMessage(`"Polygon"`),
+		"extra": json.RawMessage(` true `),
+	}
+
+	data, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	if string(data) != `{"type":"Point","coordinates":[1,2],"extra":true}` {
+		t.Errorf("data not correct")
+		t.Logf("%v", string(data))
+	}
+}
+
+func TestForeignMembersInvalid(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.ForeignMembers = map[string]json.RawMessage{"bad": json.RawMessage(`{`)}
+
+	if _, err := f.MarshalJSON(); err == nil {
+		t.Errorf("should return error for invalid raw member")
+	}
+}
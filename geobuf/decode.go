@@ -0,0 +1,407 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+// UnmarshalGeometry decodes Geobuf holding a geometry.
+func UnmarshalGeometry(data []byte) (*geojson.Geometry, error) {
+	d, field, m, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	if field != 6 {
+		return nil, errors.New("geobuf does not hold a geometry")
+	}
+	return d.geometry(m)
+}
+
+// UnmarshalFeature decodes Geobuf holding a feature.
+func UnmarshalFeature(data []byte) (*geojson.Feature, error) {
+	d, field, m, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	if field != 5 {
+		return nil, errors.New("geobuf does not hold a feature")
+	}
+	return d.feature(m)
+}
+
+// UnmarshalFeatureCollection decodes Geobuf holding a feature collection.
+func UnmarshalFeatureCollection(data []byte) (*geojson.FeatureCollection, error) {
+	d, field, m, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	if field != 4 {
+		return nil, errors.New("geobuf does not hold a feature collection")
+	}
+
+	fc := geojson.NewFeatureCollection()
+
+	var values []interface{}
+	var custom []uint32
+	r := pbf.NewReader(m)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			f, err := d.feature(r.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", len(fc.Features), err)
+			}
+			fc.Features = append(fc.Features, f)
+		case 13:
+			v, err := decodeValue(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case 15:
+			custom = r.PackedUint32()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	fc.ForeignMembers, err = d.customProperties(custom, values)
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+type decoder struct {
+	keys       []string
+	dimensions int
+	scale      float64
+}
+
+// decodeData reads the top level message, returning the number and content
+// of the field holding the encoded object.
+func decodeData(data []byte) (*decoder, int, []byte, error) {
+	d := &decoder{dimensions: 2}
+	precision := uint32(DefaultPrecision)
+
+	field := 0
+	var m []byte
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			d.keys = append(d.keys, r.Text())
+		case 2:
+			d.dimensions = int(r.Uint32())
+		case 3:
+			precision = r.Uint32()
+		case 4, 5, 6:
+			field, m = r.Field(), r.Bytes()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, 0, nil, err
+	}
+	if field == 0 {
+		return nil, 0, nil, errors.New("geobuf holds no data")
+	}
+	if d.dimensions < 1 || d.dimensions > 16 {
+		return nil, 0, nil, fmt.Errorf("invalid geobuf dimensions %d", d.dimensions)
+	}
+	if precision > 15 {
+		return nil, 0, nil, fmt.Errorf("invalid geobuf precision %d", precision)
+	}
+	d.scale = math.Pow10(int(precision))
+
+	return d, field, m, nil
+}
+
+func (d *decoder) feature(data []byte) (*geojson.Feature, error) {
+	f := geojson.NewFeature(nil)
+
+	var values []interface{}
+	var properties, custom []uint32
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			g, err := d.geometry(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			f.Geometry = g
+		case 11:
+			f.ID = r.Text()
+		case 12:
+			f.ID = float64(r.Sint64())
+		case 13:
+			v, err := decodeValue(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case 14:
+			properties = r.PackedUint32()
+		case 15:
+			custom = r.PackedUint32()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := d.pairs(properties, values, func(k string, v interface{}) error {
+		f.Properties[k] = v
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var err error
+	f.ForeignMembers, err = d.customProperties(custom, values)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *decoder) geometry(data []byte) (*geojson.Geometry, error) {
+	t := uint64(math.MaxUint64)
+	var (
+		lengths    []uint32
+		coords     []int64
+		values     []interface{}
+		custom     []uint32
+		geometries []*geojson.Geometry
+	)
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			t = r.Uint64()
+		case 2:
+			lengths = r.PackedUint32()
+		case 3:
+			coords = r.PackedSint64()
+		case 4:
+			g, err := d.geometry(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			geometries = append(geometries, g)
+		case 13:
+			v, err := decodeValue(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case 15:
+			custom = r.PackedUint32()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	if len(coords)%d.dimensions != 0 {
+		return nil, fmt.Errorf("%d coordinates for %d dimensions", len(coords), d.dimensions)
+	}
+
+	var g *geojson.Geometry
+	var err error
+	switch t {
+	case typePoint:
+		g = geojson.NewPointGeometry(d.point(coords))
+	case typeMultiPoint:
+		g = geojson.NewMultiPointGeometry(d.line(coords, false)...)
+	case typeLineString:
+		g = geojson.NewLineStringGeometry(d.line(coords, false))
+	case typeMultiLineString:
+		var lines [][][]float64
+		lines, err = d.lines(lengths, coords, false)
+		g = geojson.NewMultiLineStringGeometry(lines...)
+	case typePolygon:
+		var rings [][][]float64
+		rings, err = d.lines(lengths, coords, true)
+		g = geojson.NewPolygonGeometry(rings)
+	case typeMultiPolygon:
+		var polygons [][][][]float64
+		polygons, err = d.polygons(lengths, coords)
+		g = geojson.NewMultiPolygonGeometry(polygons...)
+	case typeGeometryCollection:
+		g = geojson.NewCollectionGeometry(geometries...)
+	default:
+		return nil, fmt.Errorf("unknown geobuf geometry type %d", t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g.ForeignMembers, err = d.customProperties(custom, values)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (d *decoder) point(coords []int64) []float64 {
+	p := make([]float64, len(coords))
+	for i, c := range coords {
+		p[i] = float64(c) / d.scale
+	}
+	return p
+}
+
+// line decodes delta encoded positions, repeating the first one at the end
+// of closed rings.
+func (d *decoder) line(coords []int64, closed bool) [][]float64 {
+	n := len(coords) / d.dimensions
+	line := make([][]float64, 0, n+1)
+
+	sum := make([]int64, d.dimensions)
+	for i := 0; i < n; i++ {
+		p := make([]float64, d.dimensions)
+		for j := range p {
+			sum[j] += coords[i*d.dimensions+j]
+			p[j] = float64(sum[j]) / d.scale
+		}
+		line = append(line, p)
+	}
+
+	if closed && n > 0 {
+		line = append(line, append([]float64(nil), line[0]...))
+	}
+	return line
+}
+
+// lines decodes the lines with the given number of positions, a single line
+// if there are no lengths.
+func (d *decoder) lines(lengths []uint32, coords []int64, closed bool) ([][][]float64, error) {
+	if len(lengths) == 0 {
+		return [][][]float64{d.line(coords, closed)}, nil
+	}
+
+	lines := make([][][]float64, 0, len(lengths))
+	start := 0
+	for _, n := range lengths {
+		end := start + int(n)*d.dimensions
+		if end > len(coords) || end < start {
+			return nil, errors.New("geobuf lengths exceed the coordinates")
+		}
+		lines = append(lines, d.line(coords[start:end], closed))
+		start = end
+	}
+	return lines, nil
+}
+
+// polygons decodes the polygons of a multi polygon, whose lengths hold the
+// number of polygons, then for each polygon its number of rings followed by
+// their number of positions.
+func (d *decoder) polygons(lengths []uint32, coords []int64) ([][][][]float64, error) {
+	if len(lengths) == 0 {
+		return [][][][]float64{{d.line(coords, true)}}, nil
+	}
+
+	polygons := make([][][][]float64, 0, lengths[0])
+	i, start := 1, 0
+	for p := uint32(0); p < lengths[0]; p++ {
+		if i >= len(lengths) {
+			return nil, errors.New("geobuf lengths are truncated")
+		}
+		rings := int(lengths[i])
+		i++
+		if rings > len(lengths)-i {
+			return nil, errors.New("geobuf lengths are truncated")
+		}
+
+		polygon := make([][][]float64, 0, rings)
+		for _, n := range lengths[i : i+rings] {
+			end := start + int(n)*d.dimensions
+			if end > len(coords) || end < start {
+				return nil, errors.New("geobuf lengths exceed the coordinates")
+			}
+			polygon = append(polygon, d.line(coords[start:end], true))
+			start = end
+		}
+		i += rings
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+// pairs calls fn for each key and value index pair.
+func (d *decoder) pairs(pairs []uint32, values []interface{}, fn func(k string, v interface{}) error) error {
+	if len(pairs)%2 != 0 {
+		return errors.New("geobuf properties must be key and value pairs")
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		k, v := int(pairs[i]), int(pairs[i+1])
+		if k >= len(d.keys) || v >= len(values) {
+			return fmt.Errorf("geobuf property %d out of range", i/2)
+		}
+		if err := fn(d.keys[k], values[v]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customProperties decodes custom properties as foreign members.
+func (d *decoder) customProperties(pairs []uint32, values []interface{}) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	err := d.pairs(pairs, values, func(k string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if members == nil {
+			members = make(map[string]json.RawMessage)
+		}
+		members[k] = data
+		return nil
+	})
+	return members, err
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var v interface{}
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			v = r.Text()
+		case 2:
+			v = r.Double()
+		case 3:
+			v = float64(r.Uint64())
+		case 4:
+			v = -float64(r.Uint64())
+		case 5:
+			v = r.Bool()
+		case 6:
+			if err := json.Unmarshal(r.Bytes(), &v); err != nil {
+				return nil, fmt.Errorf("invalid geobuf JSON value: %v", err)
+			}
+		default:
+			r.Skip()
+		}
+	}
+
+	return v, r.Err()
+}
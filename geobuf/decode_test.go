@@ -0,0 +1,124 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestGeometryRoundTrip(t *testing.T) {
+	geometries := []*geojson.Geometry{
+		geojson.NewPointGeometry([]float64{1.5, 2.25}),
+		geojson.NewPointGeometry([]float64{1, 2, 3.5}),
+		geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		geojson.NewLineStringGeometry([][]float64{{-71.160281, 42.258729}, {-71.160837, 42.259113}}),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}, {9, 10}}),
+		geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+		geojson.NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		geojson.NewMultiPolygonGeometry(
+			[][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}},
+			[][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+		),
+		geojson.NewCollectionGeometry(geojson.NewPointGeometry([]float64{1, 2}), geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})),
+	}
+
+	for _, g := range geometries {
+		decoded, _ := decodeGeometry(t, Options{}, g)
+		if !decoded.Equal(g) {
+			t.Errorf("should round trip %v, got %+v", g.Type, decoded)
+		}
+	}
+}
+
+func TestFeatureCollectionRoundTrip(t *testing.T) {
+	data := `{
+		"type": "FeatureCollection",
+		"name": "parcels",
+		"features": [
+			{
+				"type": "Feature",
+				"id": 12,
+				"geometry": {"type": "Point", "coordinates": [4.35, 50.85]},
+				"properties": {"name": "Brussels", "population": 1208542, "ratio": 0.25, "delta": -3, "capital": true, "tags": ["a", "b"], "none": null},
+				"title": "capital"
+			},
+			{
+				"type": "Feature",
+				"id": "b",
+				"geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]], "style": {"width": 2}},
+				"properties": {"name": "road"}
+			}
+		]
+	}`
+
+	fc, err := geojson.UnmarshalFeatureCollection([]byte(data))
+	if err != nil {
+		t.Fatalf("should unmarshal GeoJSON, but got %v", err)
+	}
+
+	encoded, err := MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if len(encoded) >= len(data)/2 {
+		t.Errorf("should be compact, got %d bytes", len(encoded))
+	}
+
+	decoded, err := UnmarshalFeatureCollection(encoded)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+
+	expected, _ := json.Marshal(fc)
+	got, _ := json.Marshal(decoded)
+	if string(got) != string(expected) {
+		t.Errorf("should round trip, expected %s, got %s", expected, got)
+	}
+}
+
+func TestUnmarshalFeature(t *testing.T) {
+	f := geojson.NewPointFeature([]float64{1, 2})
+	f.ID = "a"
+	f.SetProperty("name", "a")
+
+	data, err := MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	decoded, err := UnmarshalFeature(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if decoded.ID != "a" || decoded.PropertyMustString("name") != "a" || !decoded.Geometry.Equal(f.Geometry) {
+		t.Errorf("should round trip the feature, got %+v", decoded)
+	}
+
+	if _, err := UnmarshalGeometry(data); err == nil {
+		t.Errorf("should not unmarshal a feature as a geometry")
+	}
+	if _, err := UnmarshalFeatureCollection(data); err == nil {
+		t.Errorf("should not unmarshal a feature as a feature collection")
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	cases := map[string][]byte{
+		"empty":         {},
+		"truncated":     {0x32, 0x06, 0x08},
+		"unknown type":  {0x32, 0x02, 0x08, 0x09},
+		"odd coords":    {0x32, 0x05, 0x08, 0x00, 0x1a, 0x01, 0x02},
+		"lengths":       {0x32, 0x08, 0x08, 0x03, 0x12, 0x01, 0x05, 0x1a, 0x01, 0x02},
+		"polygon count": {0x32, 0x06, 0x08, 0x05, 0x12, 0x02, 0x02, 0x01},
+		"dimensions":    {0x10, 0x00, 0x32, 0x02, 0x08, 0x00},
+	}
+
+	for name, data := range cases {
+		if _, err := UnmarshalGeometry(data); err == nil {
+			t.Errorf("should reject %s", name)
+		}
+	}
+}
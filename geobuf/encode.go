@@ -0,0 +1,507 @@
+/*
+Package geobuf converts geometries, features and feature collections to and
+from Geobuf, the compact protocol buffers encoding of GeoJSON by Mapbox.
+Coordinates are stored as delta encoded integers, scaled by a power of ten
+chosen to keep their decimal digits. Numbers in properties decode as float64,
+as they do from GeoJSON.
+*/
+package geobuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+// DefaultPrecision is the maximum number of decimal digits kept in the
+// coordinates when the options do not set one, about 10 centimeters in
+// longitude/latitude.
+const DefaultPrecision = 6
+
+// The geometry types of the Geobuf schema.
+const (
+	typePoint = iota
+	typeMultiPoint
+	typeLineString
+	typeMultiLineString
+	typePolygon
+	typeMultiPolygon
+	typeGeometryCollection
+)
+
+var geometryTypes = map[geojson.GeometryType]uint64{
+	geojson.GeometryPoint:           typePoint,
+	geojson.GeometryMultiPoint:      typeMultiPoint,
+	geojson.GeometryLineString:      typeLineString,
+	geojson.GeometryMultiLineString: typeMultiLineString,
+	geojson.GeometryPolygon:         typePolygon,
+	geojson.GeometryMultiPolygon:    typeMultiPolygon,
+	geojson.GeometryCollection:      typeGeometryCollection,
+}
+
+// Options configures the Geobuf encoding.
+type Options struct {
+	// Precision is the maximum number of decimal digits kept in the
+	// coordinates, DefaultPrecision if zero. Fewer digits are used when
+	// all the coordinates have fewer.
+	Precision int
+}
+
+// MarshalGeometry encodes the geometry as Geobuf, with the default options.
+func MarshalGeometry(g *geojson.Geometry) ([]byte, error) {
+	return Options{}.MarshalGeometry(g)
+}
+
+// MarshalFeature encodes the feature as Geobuf, with the default options.
+func MarshalFeature(f *geojson.Feature) ([]byte, error) {
+	return Options{}.MarshalFeature(f)
+}
+
+// MarshalFeatureCollection encodes the feature collection as Geobuf, with
+// the default options.
+func MarshalFeatureCollection(fc *geojson.FeatureCollection) ([]byte, error) {
+	return Options{}.MarshalFeatureCollection(fc)
+}
+
+// MarshalGeometry encodes the geometry as Geobuf.
+func (o Options) MarshalGeometry(g *geojson.Geometry) ([]byte, error) {
+	if g == nil {
+		return nil, errors.New("no geometry to encode")
+	}
+
+	e, err := o.newEncoder(func(visit func(*geojson.Geometry)) { visit(g) })
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := e.geometry(g)
+	if err != nil {
+		return nil, err
+	}
+	return e.data(6, m), nil
+}
+
+// MarshalFeature encodes the feature as Geobuf.
+func (o Options) MarshalFeature(f *geojson.Feature) ([]byte, error) {
+	if f == nil {
+		return nil, errors.New("no feature to encode")
+	}
+
+	e, err := o.newEncoder(func(visit func(*geojson.Geometry)) { visit(f.Geometry) })
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := e.feature(f)
+	if err != nil {
+		return nil, err
+	}
+	return e.data(5, m), nil
+}
+
+// MarshalFeatureCollection encodes the feature collection as Geobuf.
+func (o Options) MarshalFeatureCollection(fc *geojson.FeatureCollection) ([]byte, error) {
+	if fc == nil {
+		return nil, errors.New("no feature collection to encode")
+	}
+
+	e, err := o.newEncoder(func(visit func(*geojson.Geometry)) {
+		for _, f := range fc.Features {
+			if f != nil {
+				visit(f.Geometry)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &pbf.Writer{}
+	for i, f := range fc.Features {
+		if f == nil {
+			return nil, fmt.Errorf("feature %d is nil", i)
+		}
+		fm, err := e.feature(f)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		m.Message(1, fm)
+	}
+	if err := e.customProperties(m, fc.ForeignMembers, 0, "type", "features"); err != nil {
+		return nil, err
+	}
+
+	return e.data(4, m), nil
+}
+
+type encoder struct {
+	dimensions int
+	precision  int
+	scale      float64
+
+	keys     []string
+	keyIndex map[string]uint32
+}
+
+// newEncoder creates an encoder for the geometries walked by each, choosing
+// the dimensions and precision of their coordinates.
+func (o Options) newEncoder(each func(visit func(*geojson.Geometry))) (*encoder, error) {
+	max := o.Precision
+	if max == 0 {
+		max = DefaultPrecision
+	}
+	if max < 0 || max > 15 {
+		return nil, fmt.Errorf("precision must be between 1 and 15, got %d", max)
+	}
+
+	e := &encoder{dimensions: 2, keyIndex: make(map[string]uint32)}
+	var err error
+	each(func(g *geojson.Geometry) {
+		forEachPosition(g, func(p []float64) {
+			if len(p) > e.dimensions {
+				e.dimensions = len(p)
+			}
+			for _, c := range p {
+				if math.IsNaN(c) || math.IsInf(c, 0) {
+					err = fmt.Errorf("coordinate %v can not be encoded", c)
+				}
+				for e.precision < max && math.Round(c*math.Pow10(e.precision))/math.Pow10(e.precision) != c {
+					e.precision++
+				}
+			}
+		})
+	})
+	e.scale = math.Pow10(e.precision)
+
+	return e, err
+}
+
+// data wraps the encoded object in the top level message.
+func (e *encoder) data(field int, m *pbf.Writer) []byte {
+	w := &pbf.Writer{}
+	for _, k := range e.keys {
+		w.Text(1, k)
+	}
+	if e.dimensions != 2 {
+		w.Uint64(2, uint64(e.dimensions))
+	}
+	if e.precision != DefaultPrecision {
+		w.Uint64(3, uint64(e.precision))
+	}
+	w.Message(field, m)
+	return w.Bytes()
+}
+
+func (e *encoder) feature(f *geojson.Feature) (*pbf.Writer, error) {
+	m := &pbf.Writer{}
+	if f.Geometry != nil {
+		g, err := e.geometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		m.Message(1, g)
+	}
+
+	switch id := f.ID.(type) {
+	case nil:
+	case string:
+		m.Text(11, id)
+	case float64:
+		if id == math.Trunc(id) && math.Abs(id) < 1<<53 {
+			m.Sint64(12, int64(id))
+		} else {
+			m.Text(11, fmt.Sprint(id))
+		}
+	case json.Number:
+		if n, err := id.Int64(); err == nil && n > -1<<53 && n < 1<<53 {
+			m.Sint64(12, n)
+		} else {
+			m.Text(11, id.String())
+		}
+	case int:
+		m.Sint64(12, int64(id))
+	case int64:
+		m.Sint64(12, id)
+	default:
+		m.Text(11, fmt.Sprint(id))
+	}
+
+	var values []*pbf.Writer
+	var pairs []uint32
+	for _, k := range sortedKeys(f.Properties) {
+		v, err := encodeValue(f.Properties[k])
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %v", k, err)
+		}
+		pairs = append(pairs, e.key(k), uint32(len(values)))
+		values = append(values, v)
+	}
+	for _, v := range values {
+		m.Message(13, v)
+	}
+	m.PackedUint32(14, pairs)
+
+	if err := e.customProperties(m, f.ForeignMembers, len(values), "type", "id", "properties", "geometry"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (e *encoder) geometry(g *geojson.Geometry) (*pbf.Writer, error) {
+	t, ok := geometryTypes[g.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+
+	m := &pbf.Writer{}
+	m.Uint64(1, t)
+
+	var (
+		lengths []uint32
+		lines   [][][]float64
+		closed  bool
+	)
+	switch g.Type {
+	case geojson.GeometryPoint:
+		lines = [][][]float64{{g.Point}}
+	case geojson.GeometryMultiPoint:
+		lines = [][][]float64{g.MultiPoint}
+	case geojson.GeometryLineString:
+		lines = [][][]float64{g.LineString}
+	case geojson.GeometryMultiLineString:
+		lines = g.MultiLineString
+		if len(lines) != 1 {
+			for _, l := range lines {
+				lengths = append(lengths, uint32(len(l)))
+			}
+		}
+	case geojson.GeometryPolygon:
+		lines, closed = g.Polygon, true
+		if len(lines) != 1 {
+			for _, r := range lines {
+				lengths = append(lengths, uint32(len(r)-1))
+			}
+		}
+	case geojson.GeometryMultiPolygon:
+		closed = true
+		if len(g.MultiPolygon) != 1 || len(g.MultiPolygon[0]) != 1 {
+			lengths = append(lengths, uint32(len(g.MultiPolygon)))
+		}
+		for _, p := range g.MultiPolygon {
+			if lengths != nil {
+				lengths = append(lengths, uint32(len(p)))
+			}
+			for _, r := range p {
+				if lengths != nil {
+					lengths = append(lengths, uint32(len(r)-1))
+				}
+				lines = append(lines, r)
+			}
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			if c == nil {
+				return nil, errors.New("nil geometry in collection")
+			}
+			cm, err := e.geometry(c)
+			if err != nil {
+				return nil, err
+			}
+			m.Message(4, cm)
+		}
+	}
+
+	var coords []int64
+	for _, l := range lines {
+		if closed && len(l) < 2 {
+			return nil, errors.New("ring must be closed")
+		}
+
+		var err error
+		if coords, err = e.line(coords, l, closed); err != nil {
+			return nil, err
+		}
+	}
+
+	m.PackedUint32(2, lengths)
+	m.PackedSint64(3, coords)
+
+	if err := e.customProperties(m, g.ForeignMembers, 0, "type", "coordinates", "geometries", "arcs", "properties"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// line appends the positions to coords, each delta encoded from the previous
+// one of the line. The closing position of rings is left out, and the missing
+// coordinates of positions with fewer dimensions are 0.
+func (e *encoder) line(coords []int64, line [][]float64, closed bool) ([]int64, error) {
+	n := len(line)
+	if closed && n > 0 {
+		n--
+	}
+
+	previous := make([]int64, e.dimensions)
+	for _, p := range line[:n] {
+		if len(p) == 0 {
+			continue // empty point
+		}
+		for i := range previous {
+			var c float64
+			if i < len(p) {
+				c = p[i]
+			}
+			v := int64(math.Round(c * e.scale))
+			coords = append(coords, v-previous[i])
+			previous[i] = v
+		}
+	}
+	return coords, nil
+}
+
+// customProperties writes the foreign members as custom properties.
+// The values are appended to the ones already written in the message, from
+// index offset.
+func (e *encoder) customProperties(m *pbf.Writer, members map[string]json.RawMessage, offset int, reserved ...string) error {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		if !isReserved(k, reserved) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var pairs []uint32
+	for i, k := range keys {
+		var value interface{}
+		if err := json.Unmarshal(members[k], &value); err != nil {
+			return fmt.Errorf("member %s: %v", k, err)
+		}
+		v, err := encodeValue(value)
+		if err != nil {
+			return fmt.Errorf("member %s: %v", k, err)
+		}
+
+		m.Message(13, v)
+		pairs = append(pairs, e.key(k), uint32(offset+i))
+	}
+	m.PackedUint32(15, pairs)
+	return nil
+}
+
+// key returns the index of the key, adding it if new.
+func (e *encoder) key(k string) uint32 {
+	if i, ok := e.keyIndex[k]; ok {
+		return i
+	}
+	e.keyIndex[k] = uint32(len(e.keys))
+	e.keys = append(e.keys, k)
+	return uint32(len(e.keys) - 1)
+}
+
+func encodeValue(v interface{}) (*pbf.Writer, error) {
+	m := &pbf.Writer{}
+	switch v := v.(type) {
+	case string:
+		m.Text(1, v)
+	case bool:
+		m.Bool(5, v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			encodeInt(m, int64(v))
+		} else {
+			m.Double(2, v)
+		}
+	case float32:
+		m.Double(2, float64(v))
+	case int:
+		encodeInt(m, int64(v))
+	case int32:
+		encodeInt(m, int64(v))
+	case int64:
+		encodeInt(m, v)
+	case uint64:
+		m.Uint64(3, v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		m.Text(6, string(data))
+	}
+	return m, nil
+}
+
+func encodeInt(m *pbf.Writer, v int64) {
+	if v >= 0 {
+		m.Uint64(3, uint64(v))
+	} else {
+		m.Uint64(4, uint64(-v))
+	}
+}
+
+func forEachPosition(g *geojson.Geometry, fn func(p []float64)) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		fn(g.Point)
+	case geojson.GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			fn(p)
+		}
+	case geojson.GeometryLineString:
+		for _, p := range g.LineString {
+			fn(p)
+		}
+	case geojson.GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			for _, p := range l {
+				fn(p)
+			}
+		}
+	case geojson.GeometryPolygon:
+		for _, r := range g.Polygon {
+			for _, p := range r {
+				fn(p)
+			}
+		}
+	case geojson.GeometryMultiPolygon:
+		for _, polygon := range g.MultiPolygon {
+			for _, r := range polygon {
+				for _, p := range r {
+					fn(p)
+				}
+			}
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			forEachPosition(c, fn)
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isReserved(key string, reserved []string) bool {
+	for _, r := range reserved {
+		if key == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package geobuf
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestMarshalGeometry(t *testing.T) {
+	data, err := MarshalGeometry(geojson.NewPointGeometry([]float64{1, 2}))
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if h := hex.EncodeToString(data); h != "1800320608001a020204" {
+		t.Errorf("incorrect encoding, got %v", h)
+	}
+
+	if _, err := MarshalGeometry(&geojson.Geometry{Type: "Circle"}); err == nil {
+		t.Errorf("should reject unknown geometry types")
+	}
+	if _, err := MarshalGeometry(geojson.NewPointGeometry([]float64{math.NaN(), 0})); err == nil {
+		t.Errorf("should reject NaN coordinates")
+	}
+
+	data, err = MarshalGeometry(geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4, 5}}))
+	if err != nil {
+		t.Fatalf("should marshal mixed dimensions, but got %v", err)
+	}
+	decoded, err := UnmarshalGeometry(data)
+	if err != nil {
+		t.Fatalf("should unmarshal mixed dimensions, but got %v", err)
+	}
+	if !reflect.DeepEqual(decoded.LineString, [][]float64{{1, 2, 0}, {3, 4, 5}}) {
+		t.Errorf("should pad missing coordinates with 0, got %v", decoded.LineString)
+	}
+}
+
+func TestMarshalPrecision(t *testing.T) {
+	line := geojson.NewLineStringGeometry([][]float64{{1.5, 2.25}, {3.125, 4}})
+
+	cases := []struct {
+		name      string
+		opts      Options
+		precision int
+	}{
+		{"smallest exact", Options{}, 3},
+		{"limited", Options{Precision: 2}, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := c.opts.MarshalGeometry(line)
+			if err != nil {
+				t.Fatalf("should marshal, but got %v", err)
+			}
+
+			d, _, _, err := decodeData(data)
+			if err != nil {
+				t.Fatalf("should decode, but got %v", err)
+			}
+			if d.scale != math.Pow10(c.precision) {
+				t.Errorf("should use precision %d, got scale %v", c.precision, d.scale)
+			}
+		})
+	}
+
+	g, _ := decodeGeometry(t, Options{Precision: 1}, geojson.NewPointGeometry([]float64{1.26, -3.14}))
+	if g.Point[0] != 1.3 || g.Point[1] != -3.1 {
+		t.Errorf("should round to the precision, got %v", g.Point)
+	}
+
+	if _, err := (Options{Precision: 16}).MarshalGeometry(line); err == nil {
+		t.Errorf("should reject a precision above 15")
+	}
+}
+
+func TestMarshalDeltaEncoding(t *testing.T) {
+	line := geojson.NewLineStringGeometry([][]float64{{100, 200}, {101, 202}, {101, 202}})
+	data, err := MarshalGeometry(line)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	// 100, 200 then the deltas 1, 2 and 0, 0
+	if h := hex.EncodeToString(data); h != "1800320c08021a08c801900302040000" {
+		t.Errorf("incorrect encoding, got %v", h)
+	}
+}
+
+func decodeGeometry(t *testing.T, opts Options, g *geojson.Geometry) (*geojson.Geometry, []byte) {
+	data, err := opts.MarshalGeometry(g)
+	if err != nil {
+		t.Fatalf("should marshal %v, but got %v", g.Type, err)
+	}
+
+	decoded, err := UnmarshalGeometry(data)
+	if err != nil {
+		t.Fatalf("should unmarshal %v, but got %v", g.Type, err)
+	}
+	return decoded, data
+}
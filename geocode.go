@@ -0,0 +1,269 @@
+package geojson
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultGeocodeBatchSize is the number of positions reverse geocoded at once
+// when a GeocodeEnricher has no BatchSize.
+const DefaultGeocodeBatchSize = 50
+
+// A Geocoder reverse geocodes longitude/latitude positions, returning for each
+// the properties to attach, like the admin area or address. A nil map means
+// nothing was found for the position.
+type Geocoder interface {
+	ReverseGeocode(ctx context.Context, positions [][]float64) ([]map[string]interface{}, error)
+}
+
+// GeocoderFunc adapts a function to a Geocoder.
+type GeocoderFunc func(ctx context.Context, positions [][]float64) ([]map[string]interface{}, error)
+
+// ReverseGeocode calls f(ctx, positions).
+func (f GeocoderFunc) ReverseGeocode(ctx context.Context, positions [][]float64) ([]map[string]interface{}, error) {
+	return f(ctx, positions)
+}
+
+// A GeocodeCache keeps reverse geocoding results, so they can be shared
+// between runs of GeocodeEnrichers. The zero value is an empty cache
+// safe for concurrent use.
+type GeocodeCache struct {
+	mu      sync.Mutex
+	entries map[string]map[string]interface{}
+}
+
+// Len returns the number of cached positions.
+func (c *GeocodeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *GeocodeCache) get(key string) (map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.entries[key]
+	return p, ok
+}
+
+func (c *GeocodeCache) set(key string, properties map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]map[string]interface{})
+	}
+	c.entries[key] = properties
+}
+
+// GeocodeEnricher is an Enricher attaching the properties found by a
+// geocoder to the point features of a collection. Positions shared by
+// several features are only geocoded once.
+type GeocodeEnricher struct {
+	Geocoder Geocoder
+
+	// BatchSize is the maximum number of positions per call to the geocoder,
+	// DefaultGeocodeBatchSize if not set.
+	BatchSize int
+
+	// Workers is the number of batches geocoded concurrently, at least 1.
+	Workers int
+
+	// Interval is the minimum time between the start of two calls to the
+	// geocoder, to respect the rate limits of a service.
+	Interval time.Duration
+
+	// Cache keeps the results between runs, if set.
+	Cache *GeocodeCache
+
+	// Precision is the number of decimals positions are rounded to when
+	// looking them up in the cache, 0 to use them as is.
+	Precision int
+
+	// Prefix is prepended to the keys of the properties attached.
+	Prefix string
+
+	// Overwrite replaces properties that are already set.
+	Overwrite bool
+}
+
+// Enrich reverse geocodes the point features of the collection and sets
+// the properties found on them. It stops at the first error of the geocoder,
+// without modifying any feature.
+func (e GeocodeEnricher) Enrich(ctx context.Context, fc *FeatureCollection) error {
+	cache := e.Cache
+	if cache == nil {
+		cache = &GeocodeCache{}
+	}
+
+	keys := make([]string, len(fc.Features))
+	var (
+		missing   []string
+		positions [][]float64
+		seen      = make(map[string]bool)
+	)
+	for i, f := range fc.Features {
+		if f == nil || f.Geometry == nil || f.Geometry.Type != GeometryPoint || len(f.Geometry.Point) < 2 {
+			continue
+		}
+
+		p := f.Geometry.Point
+		keys[i] = e.key(p)
+		if _, ok := cache.get(keys[i]); ok || seen[keys[i]] {
+			continue
+		}
+		seen[keys[i]] = true
+		missing = append(missing, keys[i])
+		positions = append(positions, []float64{p[0], p[1]})
+	}
+
+	if err := e.geocode(ctx, cache, missing, positions); err != nil {
+		return err
+	}
+
+	for i, f := range fc.Features {
+		if keys[i] == "" {
+			continue
+		}
+
+		properties, _ := cache.get(keys[i])
+		for k, v := range properties {
+			if _, exists := f.Properties[e.Prefix+k]; exists && !e.Overwrite {
+				continue
+			}
+			f.SetProperty(e.Prefix+k, v)
+		}
+	}
+
+	return nil
+}
+
+// geocode calls the geocoder for the positions in batches,
+// storing the results in the cache under the given keys.
+func (e GeocodeEnricher) geocode(ctx context.Context, cache *GeocodeCache, keys []string, positions [][]float64) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	size := e.BatchSize
+	if size <= 0 {
+		size = DefaultGeocodeBatchSize
+	}
+	workers := e.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limiter := &rateLimiter{interval: e.Interval}
+	starts := make(chan int)
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := start + size
+				if end > len(positions) {
+					end = len(positions)
+				}
+
+				results, err := e.geocodeBatch(ctx, limiter, positions[start:end])
+				if err != nil {
+					once.Do(func() {
+						failure = err
+						cancel()
+					})
+					continue
+				}
+				for i, r := range results {
+					cache.set(keys[start+i], r)
+				}
+			}
+		}()
+	}
+
+send:
+	for start := 0; start < len(positions); start += size {
+		select {
+		case starts <- start:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(starts)
+	wg.Wait()
+
+	if failure != nil {
+		return failure
+	}
+	return ctx.Err()
+}
+
+func (e GeocodeEnricher) geocodeBatch(ctx context.Context, limiter *rateLimiter, positions [][]float64) ([]map[string]interface{}, error) {
+	if err := limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	results, err := e.Geocoder.ReverseGeocode(ctx, positions)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(positions) {
+		return nil, fmt.Errorf("geocoder returned %d results for %d positions", len(results), len(positions))
+	}
+	return results, nil
+}
+
+// key returns the cache key of the position.
+func (e GeocodeEnricher) key(p []float64) string {
+	lon, lat := p[0], p[1]
+	if e.Precision > 0 {
+		scale := math.Pow(10, float64(e.Precision))
+		lon, lat = math.Round(lon*scale)/scale, math.Round(lat*scale)/scale
+	}
+	return strconv.FormatFloat(lon, 'g', -1, 64) + "," + strconv.FormatFloat(lat, 'g', -1, 64)
+}
+
+// rateLimiter spaces calls by a minimum interval.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next call is allowed, or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
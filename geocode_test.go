@@ -0,0 +1,99 @@
+package geojson
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGeocodeEnricher(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{4.35, 50.85}))
+	fc.AddFeature(NewPointFeature([]float64{4.350001, 50.850001}))
+	fc.AddFeature(NewPointFeature([]float64{2.35, 48.85}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 1}}))
+	fc.Features[2].SetProperty("geo_country", "FR")
+
+	var calls, positions int32
+	geocoder := GeocoderFunc(func(ctx context.Context, p [][]float64) ([]map[string]interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		atomic.AddInt32(&positions, int32(len(p)))
+		result := make([]map[string]interface{}, len(p))
+		for i, q := range p {
+			if q[0] > 4 {
+				result[i] = map[string]interface{}{"country": "BE", "city": "Brussels"}
+			} else {
+				result[i] = map[string]interface{}{"country": "France"}
+			}
+		}
+		return result, nil
+	})
+
+	cache := &GeocodeCache{}
+	e := GeocodeEnricher{Geocoder: geocoder, BatchSize: 1, Workers: 2, Cache: cache, Precision: 3, Prefix: "geo_"}
+	if err := e.Enrich(context.Background(), fc); err != nil {
+		t.Fatalf("should enrich, but got %v", err)
+	}
+
+	if fc.Features[0].PropertyMustString("geo_city") != "Brussels" || fc.Features[1].PropertyMustString("geo_country") != "BE" {
+		t.Errorf("should attach geocoded properties, got %v and %v", fc.Features[0].Properties, fc.Features[1].Properties)
+	}
+	if fc.Features[2].PropertyMustString("geo_country") != "FR" {
+		t.Errorf("should not overwrite existing properties, got %v", fc.Features[2].Properties)
+	}
+	if fc.Features[3].Properties["geo_country"] != nil {
+		t.Errorf("should only geocode points")
+	}
+	if calls != 2 || positions != 2 || cache.Len() != 2 {
+		t.Errorf("should geocode close positions once, got %v calls for %v positions", calls, positions)
+	}
+
+	if err := e.Enrich(context.Background(), fc); err != nil {
+		t.Fatalf("should enrich, but got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("should use the cache, got %v calls", calls)
+	}
+}
+
+func TestGeocodeEnricherInterval(t *testing.T) {
+	fc := NewFeatureCollection()
+	for i := 0; i < 3; i++ {
+		fc.AddFeature(NewPointFeature([]float64{float64(i), 0}))
+	}
+
+	e := GeocodeEnricher{
+		Geocoder: GeocoderFunc(func(ctx context.Context, p [][]float64) ([]map[string]interface{}, error) {
+			return make([]map[string]interface{}, len(p)), nil
+		}),
+		BatchSize: 1,
+		Workers:   3,
+		Interval:  20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	if err := e.Enrich(context.Background(), fc); err != nil {
+		t.Fatalf("should enrich, but got %v", err)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("should space the calls, took %v", d)
+	}
+}
+
+func TestGeocodeEnricherError(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+
+	failure := errors.New("quota exceeded")
+	e := GeocodeEnricher{Geocoder: GeocoderFunc(func(ctx context.Context, p [][]float64) ([]map[string]interface{}, error) {
+		return nil, failure
+	})}
+	if err := e.Enrich(context.Background(), fc); err != failure {
+		t.Errorf("should return the geocoder error, got %v", err)
+	}
+	if fc.Features[0].Properties != nil && len(fc.Features[0].Properties) != 0 {
+		t.Errorf("should not modify features on error, got %v", fc.Features[0].Properties)
+	}
+}
@@ -0,0 +1,341 @@
+package geojson
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in meters,
+// used by all the geodesic computations of the package.
+const EarthRadius = 6371008.8
+
+// DefaultMaxSegmentLength is the length in meters above which edges are
+// densified by a Geodesic with no MaxSegmentLength configured.
+const DefaultMaxSegmentLength = 10000.0
+
+// A Measurer computes distances, lengths and perimeters in meters and areas
+// in square meters. Geodesic is accurate everywhere, while a Ruler is much
+// faster on city scale data.
+type Measurer interface {
+	Distance(a, b []float64) float64
+	Length(g *Geometry) float64
+	Perimeter(g *Geometry) float64
+	Area(g *Geometry) float64
+}
+
+// MeasureOptions selects the Measurer of the functions measuring in meters,
+// like LocateAlong, SnapTo or NearestPairs, which are also its methods. The
+// zero value measures with Geodesic, like the functions.
+type MeasureOptions struct {
+	// Measurer computes the distances, lengths and areas, Geodesic if not
+	// set. A Ruler is much faster on city scale data.
+	Measurer Measurer
+}
+
+// measurer returns the Measurer of the options.
+func (o MeasureOptions) measurer() Measurer {
+	if o.Measurer == nil {
+		return Geodesic{}
+	}
+	return o.Measurer
+}
+
+// A Geodesic computes measures in meters on a spherical earth model,
+// treating the edges between positions as great circle arcs, like
+// the PostGIS geography type does.
+//
+// Area computations treat edges as straight in an equal-area projection,
+// so long edges are first densified along their great circle. Shorter
+// segments are more accurate and slower; MaxSegmentLength is the knob.
+// The zero value uses DefaultMaxSegmentLength.
+type Geodesic struct {
+	// MaxSegmentLength is the maximum length in meters of an edge before
+	// area computation. A negative value disables densification.
+	MaxSegmentLength float64
+}
+
+// Distance returns the great circle distance in meters between two positions.
+func (m Geodesic) Distance(a, b []float64) float64 {
+	return EarthRadius * angularDistance(a, b)
+}
+
+// Length returns the length in meters of the lines of the geometry.
+// Points and polygons have no length, see Perimeter for the latter.
+func (m Geodesic) Length(g *Geometry) float64 {
+	return lengthOf(g, m.pathLength)
+}
+
+// Perimeter returns the length in meters of all the rings of the polygons of the geometry.
+func (m Geodesic) Perimeter(g *Geometry) float64 {
+	return perimeterOf(g, m.pathLength)
+}
+
+// Area returns the area in square meters of the polygons of the geometry.
+// Holes are subtracted, whatever the winding order of the rings.
+func (m Geodesic) Area(g *Geometry) float64 {
+	return areaOf(g, m.polygonArea)
+}
+
+func (m Geodesic) maxSegmentLength() float64 {
+	if m.MaxSegmentLength == 0 {
+		return DefaultMaxSegmentLength
+	}
+	return m.MaxSegmentLength
+}
+
+func (m Geodesic) pathLength(path [][]float64) float64 {
+	l := 0.0
+	for i := 1; i < len(path); i++ {
+		l += m.Distance(path[i-1], path[i])
+	}
+	return l
+}
+
+func (m Geodesic) polygonArea(polygon [][][]float64) float64 {
+	if len(polygon) == 0 {
+		return 0
+	}
+
+	max := m.maxSegmentLength()
+	a := math.Abs(ringArea(densifyPath(polygon[0], max)))
+	for _, hole := range polygon[1:] {
+		a -= math.Abs(ringArea(densifyPath(hole, max)))
+	}
+
+	return math.Max(a, 0)
+}
+
+// ringArea returns the signed area of the ring in square meters, positive for
+// counterclockwise rings. The edges are straight lines in the Lambert
+// cylindrical equal-area projection.
+func ringArea(ring [][]float64) float64 {
+	n := len(ring)
+	if n < 3 {
+		return 0
+	}
+
+	total := 0.0
+	for i := 0; i < n; i++ {
+		lower := ring[i]
+		middle := ring[(i+1)%n]
+		upper := ring[(i+2)%n]
+		total += (radians(upper[0]) - radians(lower[0])) * math.Sin(radians(middle[1]))
+	}
+
+	// the formula runs over the closing position twice for closed rings,
+	// the contribution of that degenerate edge is zero so it does not matter.
+	return total * EarthRadius * EarthRadius / 2
+}
+
+// Densify returns a copy of the geometry where every edge longer than
+// maxSegmentLength meters is split along its great circle.
+func Densify(g *Geometry, maxSegmentLength float64) *Geometry {
+	if g == nil {
+		return nil
+	}
+
+	r := &Geometry{
+		Type:        g.Type,
+		BoundingBox: g.BoundingBox,
+		CRS:         g.CRS,
+		Point:       g.Point,
+		MultiPoint:  g.MultiPoint,
+	}
+
+	switch g.Type {
+	case GeometryLineString:
+		r.LineString = densifyPath(g.LineString, maxSegmentLength)
+	case GeometryMultiLineString:
+		r.MultiLineString = densifyPaths(g.MultiLineString, maxSegmentLength)
+	case GeometryPolygon:
+		r.Polygon = densifyPaths(g.Polygon, maxSegmentLength)
+	case GeometryMultiPolygon:
+		r.MultiPolygon = make([][][][]float64, 0, len(g.MultiPolygon))
+		for _, p := range g.MultiPolygon {
+			r.MultiPolygon = append(r.MultiPolygon, densifyPaths(p, maxSegmentLength))
+		}
+	case GeometryCollection:
+		r.Geometries = make([]*Geometry, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			r.Geometries = append(r.Geometries, Densify(c, maxSegmentLength))
+		}
+	}
+
+	return r
+}
+
+func densifyPaths(paths [][][]float64, max float64) [][][]float64 {
+	result := make([][][]float64, 0, len(paths))
+	for _, p := range paths {
+		result = append(result, densifyPath(p, max))
+	}
+	return result
+}
+
+func densifyPath(path [][]float64, max float64) [][]float64 {
+	if max <= 0 || len(path) < 2 {
+		return path
+	}
+
+	result := make([][]float64, 0, len(path))
+	result = append(result, path[0])
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		n := int(math.Ceil(EarthRadius * angularDistance(a, b) / max))
+		for j := 1; j < n; j++ {
+			result = append(result, intermediate(a, b, float64(j)/float64(n)))
+		}
+		result = append(result, b)
+	}
+
+	return result
+}
+
+// Intermediate returns the position at the given fraction of the great circle
+// arc from one position to the other, 0 being from and 1 being to. The altitude
+// is interpolated linearly if both positions have one. The arc between
+// antipodal positions is undefined.
+func Intermediate(from, to []float64, fraction float64) []float64 {
+	p := intermediate(from, to, fraction)
+	if len(from) > 2 && len(to) > 2 {
+		p = append(p, from[2]+(to[2]-from[2])*fraction)
+	}
+	return p
+}
+
+// FractionAlong returns the fraction of the length of the line string at which
+// the point, snapped onto the line, lies. It returns 0 for lines without length.
+func FractionAlong(line *Geometry, point []float64) (float64, error) {
+	return MeasureOptions{}.FractionAlong(line, point)
+}
+
+// FractionAlong is FractionAlong measuring with the Measurer of the options.
+func (o MeasureOptions) FractionAlong(line *Geometry, point []float64) (float64, error) {
+	measure, _, err := o.LocateAlong(line, point)
+	if err != nil {
+		return 0, err
+	}
+
+	measures, _ := o.Measures(line)
+	length := measures[len(measures)-1]
+	if length == 0 {
+		return 0, nil
+	}
+	return measure / length, nil
+}
+
+// lengthOf sums the lengths of the lines of the geometry.
+func lengthOf(g *Geometry, pathLength func([][]float64) float64) float64 {
+	if g == nil {
+		return 0
+	}
+
+	l := 0.0
+	switch g.Type {
+	case GeometryLineString:
+		l = pathLength(g.LineString)
+	case GeometryMultiLineString:
+		for _, line := range g.MultiLineString {
+			l += pathLength(line)
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			l += lengthOf(c, pathLength)
+		}
+	}
+
+	return l
+}
+
+// perimeterOf sums the lengths of the polygon rings of the geometry.
+func perimeterOf(g *Geometry, pathLength func([][]float64) float64) float64 {
+	if g == nil {
+		return 0
+	}
+
+	l := 0.0
+	switch g.Type {
+	case GeometryPolygon:
+		for _, ring := range g.Polygon {
+			l += pathLength(ring)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, ring := range p {
+				l += pathLength(ring)
+			}
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			l += perimeterOf(c, pathLength)
+		}
+	}
+
+	return l
+}
+
+// areaOf sums the areas of the polygons of the geometry.
+func areaOf(g *Geometry, polygonArea func([][][]float64) float64) float64 {
+	if g == nil {
+		return 0
+	}
+
+	a := 0.0
+	switch g.Type {
+	case GeometryPolygon:
+		a = polygonArea(g.Polygon)
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			a += polygonArea(p)
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			a += areaOf(c, polygonArea)
+		}
+	}
+
+	return a
+}
+
+// angularDistance returns the great circle distance in radians between
+// two positions, using the haversine formula.
+func angularDistance(a, b []float64) float64 {
+	lat1, lat2 := radians(a[1]), radians(b[1])
+	dLat := lat2 - lat1
+	dLon := radians(b[0] - a[0])
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// intermediate returns the position at the given fraction of the
+// great circle arc from a to b.
+func intermediate(a, b []float64, fraction float64) []float64 {
+	d := angularDistance(a, b)
+	if d == 0 {
+		return []float64{a[0], a[1]}
+	}
+
+	lat1, lon1 := radians(a[1]), radians(a[0])
+	lat2, lon2 := radians(b[1]), radians(b[0])
+
+	sa := math.Sin((1-fraction)*d) / math.Sin(d)
+	sb := math.Sin(fraction*d) / math.Sin(d)
+
+	x := sa*math.Cos(lat1)*math.Cos(lon1) + sb*math.Cos(lat2)*math.Cos(lon2)
+	y := sa*math.Cos(lat1)*math.Sin(lon1) + sb*math.Cos(lat2)*math.Sin(lon2)
+	z := sa*math.Sin(lat1) + sb*math.Sin(lat2)
+
+	lat := math.Atan2(z, math.Sqrt(x*x+y*y))
+	lon := math.Atan2(y, x)
+
+	return []float64{degrees(lon), degrees(lat)}
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+func degrees(r float64) float64 {
+	return r * 180 / math.Pi
+}
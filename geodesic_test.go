@@ -0,0 +1,169 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeodesicDistance(t *testing.T) {
+	d := Geodesic{}.Distance([]float64{0, 0}, []float64{1, 0})
+
+	if math.Abs(d-111195.08) > 0.1 {
+		t.Errorf("incorrect distance, got %v", d)
+	}
+}
+
+func TestGeodesicLength(t *testing.T) {
+	g := NewMultiLineStringGeometry(
+		[][]float64{{0, 0}, {1, 0}},
+		[][]float64{{0, 0}, {0, 1}, {0, 2}},
+	)
+
+	l := Geodesic{}.Length(g)
+	if math.Abs(l-3*111195.08) > 0.5 {
+		t.Errorf("incorrect length, got %v", l)
+	}
+
+	if p := (Geodesic{}).Length(NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})); p != 0 {
+		t.Errorf("polygons should have no length, got %v", p)
+	}
+}
+
+func TestGeodesicArea(t *testing.T) {
+	square := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}})
+
+	// a lat/lon cell with parallels as edges
+	cell := EarthRadius * EarthRadius * radians(1) * math.Sin(radians(1))
+
+	a := Geodesic{MaxSegmentLength: -1}.Area(square)
+	if math.Abs(a-cell)/cell > 1e-9 {
+		t.Errorf("without densification should match the cell area, got %v, expected %v", a, cell)
+	}
+
+	// the northern great circle edge bulges towards the pole
+	d := Geodesic{MaxSegmentLength: 1000}.Area(square)
+	if d <= a {
+		t.Errorf("densified area should be larger, got %v and %v", d, a)
+	}
+	if (d-a)/a > 1e-3 {
+		t.Errorf("densified area should stay close, got %v and %v", d, a)
+	}
+}
+
+func TestGeodesicAreaHole(t *testing.T) {
+	outer := [][]float64{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}
+	hole := [][]float64{{0.5, 0.5}, {0.5, 1.5}, {1.5, 1.5}, {1.5, 0.5}, {0.5, 0.5}}
+
+	m := Geodesic{}
+	full := m.Area(NewPolygonGeometry([][][]float64{outer}))
+	holed := m.Area(NewPolygonGeometry([][][]float64{outer, hole}))
+	h := m.Area(NewPolygonGeometry([][][]float64{hole}))
+
+	if math.Abs(full-h-holed) > 1 {
+		t.Errorf("hole should be subtracted, got %v - %v != %v", full, h, holed)
+	}
+}
+
+func TestDensify(t *testing.T) {
+	g := Densify(NewLineStringGeometry([][]float64{{0, 0}, {1, 0}}), 10000)
+
+	if len(g.LineString) != 13 {
+		t.Fatalf("should split 111km edge in 12 segments, got %d positions", len(g.LineString))
+	}
+
+	if math.Abs(g.LineString[6][0]-0.5) > 1e-9 || math.Abs(g.LineString[6][1]) > 1e-9 {
+		t.Errorf("incorrect intermediate position, got %v", g.LineString[6])
+	}
+}
+
+func TestIntermediate(t *testing.T) {
+	p := Intermediate([]float64{0, 0, 100}, []float64{90, 0, 200}, 0.5)
+	if math.Abs(p[0]-45) > 1e-9 || math.Abs(p[1]) > 1e-9 || p[2] != 150 {
+		t.Errorf("incorrect intermediate point on the equator, got %v", p)
+	}
+
+	// the great circle between two points on a parallel bulges towards the pole
+	p = Intermediate([]float64{-10, 50}, []float64{10, 50}, 0.5)
+	if math.Abs(p[0]) > 1e-9 || p[1] <= 50 {
+		t.Errorf("incorrect intermediate point, got %v", p)
+	}
+	if len(p) != 2 {
+		t.Errorf("should not add an altitude, got %v", p)
+	}
+
+	d := Geodesic{}.Distance([]float64{-10, 50}, p)
+	if total := (Geodesic{}).Distance([]float64{-10, 50}, []float64{10, 50}); math.Abs(d-total/2) > 1e-6 {
+		t.Errorf("should be half way, got %v of %v", d, total)
+	}
+}
+
+func TestFractionAlong(t *testing.T) {
+	line := NewLineStringGeometry([][]float64{{0, 0}, {1, 0}, {1, 1}})
+
+	f, err := FractionAlong(line, []float64{1, 0.0001})
+	if err != nil {
+		t.Fatalf("should compute fraction, but got %v", err)
+	}
+	if math.Abs(f-0.5) > 1e-3 {
+		t.Errorf("incorrect fraction, got %v", f)
+	}
+
+	if f, _ := FractionAlong(line, []float64{5, 5}); f != 1 {
+		t.Errorf("should clamp to the end, got %v", f)
+	}
+	if f, _ := FractionAlong(NewLineStringGeometry([][]float64{{1, 1}}), []float64{5, 5}); f != 0 {
+		t.Errorf("should be 0 without length, got %v", f)
+	}
+}
+
+// doubleMeasurer measures twice the geodesic measures.
+type doubleMeasurer struct{ Geodesic }
+
+func (m doubleMeasurer) Distance(a, b []float64) float64 { return 2 * m.Geodesic.Distance(a, b) }
+func (m doubleMeasurer) Area(g *Geometry) float64        { return 4 * m.Geodesic.Area(g) }
+
+func TestMeasureOptions(t *testing.T) {
+	o := MeasureOptions{Measurer: doubleMeasurer{}}
+	line := NewLineStringGeometry([][]float64{{0, 0}, {0.01, 0}})
+	length := Geodesic{}.Distance(line.LineString[0], line.LineString[1])
+
+	if measures, _ := o.Measures(line); math.Abs(measures[1]-2*length) > 1e-6 {
+		t.Errorf("should measure with the measurer, got %v", measures)
+	}
+	if measure, _, _ := o.LocateAlong(line, []float64{0.005, 0.001}); math.Abs(measure-length) > 1e-6 {
+		t.Errorf("incorrect measure, got %v", measure)
+	}
+	if f, _ := o.FractionAlong(line, []float64{0.005, 0.001}); math.Abs(f-0.5) > 1e-9 {
+		t.Errorf("incorrect fraction, got %v", f)
+	}
+	if p, _ := o.PositionAt(line, length); math.Abs(p[0]-0.005) > 1e-9 {
+		t.Errorf("incorrect position, got %v", p)
+	}
+
+	// 0.0001° of latitude is about 11 m, 22 m measured twice
+	if n := o.SnapTo(NewPointGeometry([]float64{0, 0.0001}), line, 15); n != 0 {
+		t.Errorf("should not snap beyond the measured tolerance")
+	}
+	if n := SnapTo(NewPointGeometry([]float64{0, 0.0001}), line, 15); n != 1 {
+		t.Errorf("should snap within the geodesic tolerance")
+	}
+
+	a := NewFeatureCollection().AddFeature(NewPointFeature([]float64{0, 0.0001}))
+	b := NewFeatureCollection().AddFeature(NewPointFeature([]float64{0, 0}))
+	pairs, err := o.NearestPairs(a, b, 0)
+	if err != nil || len(pairs) != 1 || math.Abs(pairs[0].Distance-2*Geodesic{}.Distance([]float64{0, 0.0001}, []float64{0, 0})) > 1e-6 {
+		t.Errorf("should measure the pairs with the measurer, got %v, %v", pairs, err)
+	}
+
+	fidelity := o.CompareFidelity(NewPointGeometry([]float64{0, 0.0001}), NewPointGeometry([]float64{0, 0}))
+	if math.Abs(fidelity.MaxError-pairs[0].Distance) > 1e-6 {
+		t.Errorf("should measure the errors with the measurer, got %v", fidelity.MaxError)
+	}
+
+	// cells of 2 km measured twice are 1 km geodesic ones, at the equator
+	fc := NewFeatureCollection().AddFeature(NewPointFeature([]float64{0.015, 0}))
+	cells, err := o.PartitionByGrid(fc, 2000, PartitionByCentroid)
+	if err != nil || cells["1/0"] == nil {
+		t.Errorf("should size the cells with the measurer, got %v, %v", cells, err)
+	}
+}
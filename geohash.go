@@ -0,0 +1,203 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// geohashAlphabet is the base 32 alphabet of geohashes, without a, i, l and o.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// maxGeohashPrecision is the number of characters of the most precise
+// geohashes, cells of a few centimeters, beyond the precision of float64.
+const maxGeohashPrecision = 12
+
+// maxGeohashCells limits the number of cells of a coverage.
+const maxGeohashCells = 1 << 20
+
+// PointToGeohash returns the geohash of the point with the given number of
+// characters, from 1 to 12, like Redis GEO and the geohash_grid aggregation
+// of Elasticsearch do: 5 characters are cells of about 5 km, 7 of about
+// 150 m and 9 of about 5 m.
+func (g *Geometry) PointToGeohash(precision int) (string, error) {
+	if g == nil || g.Type != GeometryPoint {
+		return "", errors.New("only a point has a geohash")
+	}
+	if precision < 1 || precision > maxGeohashPrecision {
+		return "", fmt.Errorf("geohash precision must be between 1 and %d, got %d", maxGeohashPrecision, precision)
+	}
+	if len(g.Point) < 2 {
+		return "", fmt.Errorf("position %v needs at least 2 coordinates", g.Point)
+	}
+	lon, lat := g.Point[0], g.Point[1]
+	if !(lon >= -180 && lon <= 180 && lat >= -90 && lat <= 90) {
+		return "", fmt.Errorf("position %v is out of the longitude/latitude range", g.Point)
+	}
+
+	box := [4]float64{-180, -90, 180, 90}
+	var b strings.Builder
+	even := true
+	for b.Len() < precision {
+		c := 0
+		for bit := 0; bit < 5; bit++ {
+			c <<= 1
+			if even {
+				if mid := (box[0] + box[2]) / 2; lon >= mid {
+					c |= 1
+					box[0] = mid
+				} else {
+					box[2] = mid
+				}
+			} else {
+				if mid := (box[1] + box[3]) / 2; lat >= mid {
+					c |= 1
+					box[1] = mid
+				} else {
+					box[3] = mid
+				}
+			}
+			even = !even
+		}
+		b.WriteByte(geohashAlphabet[c])
+	}
+
+	return b.String(), nil
+}
+
+// GeohashToGeometry decodes the geohash into the polygon of its cell and the
+// point at its center. Geohashes are case insensitive.
+func GeohashToGeometry(hash string) (cell *Geometry, center *Geometry, err error) {
+	box, err := geohashBounds(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cell = NewPolygonGeometry([][][]float64{{
+		{box[0], box[1]}, {box[2], box[1]}, {box[2], box[3]}, {box[0], box[3]}, {box[0], box[1]},
+	}})
+	center = NewPointGeometry([]float64{(box[0] + box[2]) / 2, (box[1] + box[3]) / 2})
+	return cell, center, nil
+}
+
+// geohashBounds returns the longitude/latitude bounding box of the cell
+// of the geohash.
+func geohashBounds(hash string) ([4]float64, error) {
+	box := [4]float64{-180, -90, 180, 90}
+	if hash == "" {
+		return box, errors.New("empty geohash")
+	}
+	if len(hash) > maxGeohashPrecision {
+		return box, fmt.Errorf("geohash %q has more than %d characters", hash, maxGeohashPrecision)
+	}
+
+	even := true
+	for _, r := range strings.ToLower(hash) {
+		c := strings.IndexRune(geohashAlphabet, r)
+		if c < 0 {
+			return box, fmt.Errorf("invalid character %q in geohash %q", r, hash)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := c>>uint(bit)&1 == 1
+			if even {
+				if mid := (box[0] + box[2]) / 2; set {
+					box[0] = mid
+				} else {
+					box[2] = mid
+				}
+			} else {
+				if mid := (box[1] + box[3]) / 2; set {
+					box[1] = mid
+				} else {
+					box[3] = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return box, nil
+}
+
+// GeohashCoverage returns the sorted geohashes of the given precision of the
+// cells overlapping the polygons of the geometry, to query or bucket by
+// geohash prefixes. Cells only touching the polygons are left out. The
+// bounding box of the geometry may span at most about a million cells.
+func GeohashCoverage(g *Geometry, precision int) ([]string, error) {
+	if g == nil || (g.Type != GeometryPolygon && g.Type != GeometryMultiPolygon) {
+		return nil, errors.New("only polygons have a geohash coverage")
+	}
+	if precision < 1 || precision > maxGeohashPrecision {
+		return nil, fmt.Errorf("geohash precision must be between 1 and %d, got %d", maxGeohashPrecision, precision)
+	}
+
+	bbox := boundingBox(g)
+	if bbox == nil {
+		return []string{}, nil
+	}
+
+	width, height := geohashCellSize(precision)
+	columns := math.Floor(bbox[2]/width) - math.Floor(bbox[0]/width) + 1
+	rows := math.Floor(bbox[3]/height) - math.Floor(bbox[1]/height) + 1
+	if columns*rows > maxGeohashCells {
+		return nil, fmt.Errorf("geohash coverage would span %.0f cells, more than %d", columns*rows, maxGeohashCells)
+	}
+	// clipping leaves slivers along the edges of the cells
+	minArea := width * height * 1e-9
+
+	hashes := []string{}
+	var cover func(prefix string)
+	cover = func(prefix string) {
+		for i := 0; i < len(geohashAlphabet); i++ {
+			hash := prefix + geohashAlphabet[i:i+1]
+			box, _ := geohashBounds(hash)
+			if box[2] <= bbox[0] || box[0] >= bbox[2] || box[3] <= bbox[1] || box[1] >= bbox[3] {
+				continue
+			}
+			if !overlapsBox(g, box, minArea) {
+				continue
+			}
+
+			if len(hash) < precision {
+				cover(hash)
+				continue
+			}
+			hashes = append(hashes, hash)
+		}
+	}
+	cover("")
+
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// geohashCellSize returns the width and height in degrees of the cells of
+// the precision. The bits of a geohash alternate between longitude and
+// latitude, starting with longitude.
+func geohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	return 360 / math.Pow(2, float64((bits+1)/2)), 180 / math.Pow(2, float64(bits/2))
+}
+
+// overlapsBox tells if the polygons of the geometry and the box share an
+// area larger than minArea, not just a boundary.
+func overlapsBox(g *Geometry, box [4]float64, minArea float64) bool {
+	clipped := clipToBox(g, box)
+	if clipped == nil {
+		return false
+	}
+
+	area := 0.0
+	for _, p := range polygons(clipped) {
+		for i, r := range p {
+			if i == 0 {
+				area += math.Abs(ringArea2D(r))
+			} else {
+				area -= math.Abs(ringArea2D(r))
+			}
+		}
+	}
+	return area > minArea
+}
@@ -0,0 +1,109 @@
+package geojson
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPointToGeohash(t *testing.T) {
+	cases := []struct {
+		point     []float64
+		precision int
+		hash      string
+	}{
+		{[]float64{-5.6, 42.6}, 5, "ezs42"},
+		{[]float64{10.40744, 57.64911}, 11, "u4pruydqqvj"},
+		{[]float64{0, 0}, 1, "s"},
+		{[]float64{-180, -90}, 3, "000"},
+		{[]float64{180, 90, 12}, 3, "zzz"},
+	}
+
+	for _, tc := range cases {
+		hash, err := NewPointGeometry(tc.point).PointToGeohash(tc.precision)
+		if err != nil {
+			t.Fatalf("should encode %v, but got %v", tc.point, err)
+		}
+		if hash != tc.hash {
+			t.Errorf("incorrect geohash of %v, expected %s, got %s", tc.point, tc.hash, hash)
+		}
+	}
+
+	for _, g := range []*Geometry{nil, NewPointGeometry([]float64{181, 0}), NewPointGeometry([]float64{math.NaN(), 0}), NewLineStringGeometry([][]float64{{0, 0}, {1, 1}})} {
+		if _, err := g.PointToGeohash(5); err == nil {
+			t.Errorf("should not encode %v", g)
+		}
+	}
+	if _, err := NewPointGeometry([]float64{0, 0}).PointToGeohash(13); err == nil {
+		t.Errorf("should reject precision 13")
+	}
+}
+
+func TestGeohashToGeometry(t *testing.T) {
+	cell, center, err := GeohashToGeometry("EZS42")
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if math.Abs(center.Point[0]+5.603) > 0.001 || math.Abs(center.Point[1]-42.605) > 0.001 {
+		t.Errorf("incorrect center, got %v", center.Point)
+	}
+	if PointInPolygonWinding([]float64{-5.6, 42.6}, cell.Polygon) != Interior {
+		t.Errorf("cell should contain the point, got %v", cell.Polygon)
+	}
+	if ringArea2D(cell.Polygon[0]) <= 0 {
+		t.Errorf("cell should be counterclockwise, got %v", cell.Polygon)
+	}
+
+	cell, _, _ = GeohashToGeometry("u")
+	expected := NewPolygonGeometry([][][]float64{{{0, 45}, {45, 45}, {45, 90}, {0, 90}, {0, 45}}})
+	if !cell.Equal(expected) {
+		t.Errorf("incorrect cell, got %v", cell.Polygon)
+	}
+
+	for _, hash := range []string{"", "ua", "0123456789bcd"} {
+		if _, _, err := GeohashToGeometry(hash); err == nil {
+			t.Errorf("should not decode %q", hash)
+		}
+	}
+}
+
+func TestGeohashCoverage(t *testing.T) {
+	cell, _, _ := GeohashToGeometry("u")
+	hashes, err := GeohashCoverage(cell, 2)
+	if err != nil {
+		t.Fatalf("should cover, but got %v", err)
+	}
+	if len(hashes) != 32 {
+		t.Fatalf("should cover the 32 cells of u, got %v", hashes)
+	}
+	for _, h := range hashes {
+		if !strings.HasPrefix(h, "u") {
+			t.Errorf("should only cover cells of u, got %s", h)
+		}
+	}
+
+	small := NewPolygonGeometry([][][]float64{{{-5.601, 42.601}, {-5.599, 42.601}, {-5.599, 42.602}, {-5.601, 42.601}}})
+	hashes, _ = GeohashCoverage(small, 5)
+	if len(hashes) != 1 || hashes[0] != "ezs42" {
+		t.Errorf("incorrect coverage of a small polygon, got %v", hashes)
+	}
+
+	// a square around "s" at precision 2 with a hole the size of cell "s0"
+	outer, _, _ := GeohashToGeometry("s")
+	hole, _, _ := GeohashToGeometry("s0")
+	holeRing := append([][]float64(nil), hole.Polygon[0]...)
+	reverseRing(holeRing)
+	withHole := NewPolygonGeometry([][][]float64{outer.Polygon[0], holeRing})
+	hashes, _ = GeohashCoverage(withHole, 2)
+	if len(hashes) != 31 || hashes[0] != "s1" {
+		t.Errorf("should leave out the cell in the hole, got %v", hashes)
+	}
+
+	if _, err := GeohashCoverage(NewPointGeometry([]float64{0, 0}), 5); err == nil {
+		t.Errorf("should not cover a point")
+	}
+	world := NewPolygonGeometry([][][]float64{{{-180, -90}, {180, -90}, {180, 90}, {-180, 90}, {-180, -90}}})
+	if _, err := GeohashCoverage(world, 6); err == nil {
+		t.Errorf("should reject a coverage of billions of cells")
+	}
+}
@@ -1,6 +1,8 @@
 package geojson
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,6 +24,12 @@ const (
 	GeometryCollection      GeometryType = "GeometryCollection"
 )
 
+// ErrUnsupportedGeometryType is returned, wrapped in a DecodeError with the
+// offending type, when decoding a geometry of a type that is not one of the
+// GeoJSON types, rather than an empty geometry, see
+// DecodeOptions.AllowUnknownGeometryTypes.
+var ErrUnsupportedGeometryType = errors.New("unsupported geometry type")
+
 // A Geometry correlates to a GeoJSON geometry object.
 type Geometry struct {
 	Type            GeometryType `json:"type"`
@@ -33,7 +41,15 @@ type Geometry struct {
 	Polygon         [][][]float64
 	MultiPolygon    [][][][]float64
 	Geometries      []*Geometry
-	CRS             map[string]interface{} `json:"crs,omitempty"` // Coordinate Reference System Objects are not currently supported
+	CRS             map[string]interface{} `json:"crs,omitempty"` // Coordinate Reference System object, see ParseCRS
+
+	// ForeignMembers holds the members of the GeoJSON object that are not
+	// part of the specification, like "title", so they survive a round trip.
+	ForeignMembers map[string]json.RawMessage `json:"-"`
+
+	// provenance records the transformations applied to the coordinates,
+	// see Provenance.
+	provenance []Transformation
 }
 
 // NewPointGeometry creates and initializes a point geometry with the give coordinate.
@@ -92,44 +108,136 @@ func NewCollectionGeometry(geometries ...*Geometry) *Geometry {
 	}
 }
 
+// NewEmptyGeometry creates and initializes an empty geometry of the given
+// type, the representation decoding normalizes empty geometries to. Empty
+// geometries are marshaled with empty coordinates, like
+// {"type":"Point","coordinates":[]}, or empty geometries for a collection.
+// Decoding also accepts null coordinates and geometries as empty.
+func NewEmptyGeometry(t GeometryType) *Geometry {
+	g := &Geometry{Type: t}
+	switch t {
+	case GeometryPoint:
+		g.Point = []float64{}
+	case GeometryMultiPoint:
+		g.MultiPoint = [][]float64{}
+	case GeometryLineString:
+		g.LineString = [][]float64{}
+	case GeometryMultiLineString:
+		g.MultiLineString = [][][]float64{}
+	case GeometryPolygon:
+		g.Polygon = [][][]float64{}
+	case GeometryMultiPolygon:
+		g.MultiPolygon = [][][][]float64{}
+	case GeometryCollection:
+		g.Geometries = []*Geometry{}
+	}
+	return g
+}
+
 // MarshalJSON converts the geometry object into the correct JSON.
 // This fulfills the json.Marshaler interface.
 func (g Geometry) MarshalJSON() ([]byte, error) {
+	return g.marshalJSON(nil)
+}
+
+// marshalJSON encodes the geometry with the coordinates and the bounding
+// boxes in the number format, unless nil.
+func (g Geometry) marshalJSON(format numberFormat) ([]byte, error) {
 	// defining a struct here lets us define the order of the JSON elements.
 	type geometry struct {
 		Type        GeometryType           `json:"type"`
-		BoundingBox []float64              `json:"bbox,omitempty"`
+		BoundingBox interface{}            `json:"bbox,omitempty"`
 		Coordinates interface{}            `json:"coordinates,omitempty"`
 		Geometries  interface{}            `json:"geometries,omitempty"`
 		CRS         map[string]interface{} `json:"crs,omitempty"`
 	}
 
+	if err := g.CheckFinite(); err != nil {
+		return nil, err
+	}
+
 	geo := &geometry{
 		Type: g.Type,
 	}
 
 	if g.BoundingBox != nil && len(g.BoundingBox) != 0 {
-		geo.BoundingBox = g.BoundingBox
+		geo.BoundingBox = formatted(g.BoundingBox, format)
+	}
+
+	coordinates, geometries := g.encodedMembers()
+	geo.Coordinates, geo.Geometries = formatted(coordinates, format), geometries
+	if format != nil && geometries != nil {
+		members := make([]formattedGeometry, len(g.Geometries))
+		for i, m := range g.Geometries {
+			members[i] = formattedGeometry{m, format}
+		}
+		geo.Geometries = members
+	}
+
+	data, err := json.Marshal(geo)
+	if err != nil {
+		return nil, err
 	}
 
+	return appendForeignMembers(data, g.ForeignMembers, geometryMembers)
+}
+
+// formattedGeometry encodes a geometry with a number format.
+type formattedGeometry struct {
+	g      *Geometry
+	format numberFormat
+}
+
+func (m formattedGeometry) MarshalJSON() ([]byte, error) {
+	if m.g == nil {
+		return []byte("null"), nil
+	}
+	return m.g.marshalJSON(m.format)
+}
+
+// encodedMembers returns the coordinates, or the geometries of a collection,
+// to encode. Empty geometries get an empty array rather than null, which
+// is not a valid value for those members.
+func (g Geometry) encodedMembers() (coordinates interface{}, geometries interface{}) {
 	switch g.Type {
 	case GeometryPoint:
-		geo.Coordinates = g.Point
+		if g.Point == nil {
+			return []float64{}, nil
+		}
+		return g.Point, nil
 	case GeometryMultiPoint:
-		geo.Coordinates = g.MultiPoint
+		if g.MultiPoint == nil {
+			return [][]float64{}, nil
+		}
+		return g.MultiPoint, nil
 	case GeometryLineString:
-		geo.Coordinates = g.LineString
+		if g.LineString == nil {
+			return [][]float64{}, nil
+		}
+		return g.LineString, nil
 	case GeometryMultiLineString:
-		geo.Coordinates = g.MultiLineString
+		if g.MultiLineString == nil {
+			return [][][]float64{}, nil
+		}
+		return g.MultiLineString, nil
 	case GeometryPolygon:
-		geo.Coordinates = g.Polygon
+		if g.Polygon == nil {
+			return [][][]float64{}, nil
+		}
+		return g.Polygon, nil
 	case GeometryMultiPolygon:
-		geo.Coordinates = g.MultiPolygon
+		if g.MultiPolygon == nil {
+			return [][][][]float64{}, nil
+		}
+		return g.MultiPolygon, nil
 	case GeometryCollection:
-		geo.Geometries = g.Geometries
+		if g.Geometries == nil {
+			return nil, []*Geometry{}
+		}
+		return nil, g.Geometries
 	}
 
-	return json.Marshal(geo)
+	return nil, nil
 }
 
 // UnmarshalGeometry decodes the data into a GeoJSON geometry.
@@ -147,19 +255,24 @@ func UnmarshalGeometry(data []byte) (*Geometry, error) {
 // UnmarshalJSON decodes the data into a GeoJSON geometry.
 // This fulfills the json.Unmarshaler interface.
 func (g *Geometry) UnmarshalJSON(data []byte) error {
+	if err := DefaultDecodeLimits.checkJSON(data); err != nil {
+		return err
+	}
+
 	var object map[string]interface{}
 	err := json.Unmarshal(data, &object)
 	if err != nil {
 		return err
 	}
 
-	return decodeGeometry(g, object)
+	return decodeGeometry(g, object, false)
 }
 
 // Scan implements the sql.Scanner interface allowing
 // geometry structs to be passed into rows.Scan(...interface{})
-// The columns must be received as GeoJSON Geometry.
-// When using PostGIS a spatial column would need to be wrapped in ST_AsGeoJSON.
+// The columns can be received as GeoJSON Geometry, or as EWKB, binary or hex
+// encoded, so PostGIS geometry columns can be scanned directly. An SRID other
+// than 4326 is kept as a named CRS, like "EPSG:3857".
 func (g *Geometry) Scan(value interface{}) error {
 	var data []byte
 
@@ -172,107 +285,135 @@ func (g *Geometry) Scan(value interface{}) error {
 		return errors.New("unable to parse this type into geojson")
 	}
 
-	return g.UnmarshalJSON(data)
-}
-
-// MarshalBSON converts the geometry object into the correct JSON.
-// This fulfills the bson.Marshaler interface.
-func (g Geometry) MarshalBSON() ([]byte, error) {
-	type geometry struct {
-		Type        GeometryType           `bson:"type"`
-		BoundingBox []float64              `bson:"bbox,omitempty"`
-		Coordinates interface{}            `bson:"coordinates,omitempty"`
-		Geometries  interface{}            `bson:"geometries,omitempty"`
-		CRS         map[string]interface{} `bson:"crs,omitempty"`
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] == '{' {
+		return g.UnmarshalJSON(data)
 	}
 
-	geo := &geometry{
-		Type: g.Type,
+	if data[0] != 0 && data[0] != 1 {
+		decoded := make([]byte, hex.DecodedLen(len(data)))
+		if _, err := hex.Decode(decoded, data); err != nil {
+			return fmt.Errorf("unable to parse hex EWKB: %v", err)
+		}
+		data = decoded
 	}
 
-	if g.BoundingBox != nil && len(g.BoundingBox) != 0 {
-		geo.BoundingBox = g.BoundingBox
+	decoded, srid, err := UnmarshalEWKB(data)
+	if err != nil {
+		return err
 	}
-
-	switch g.Type {
-	case GeometryPoint:
-		geo.Coordinates = g.Point
-	case GeometryMultiPoint:
-		geo.Coordinates = g.MultiPoint
-	case GeometryLineString:
-		geo.Coordinates = g.LineString
-	case GeometryMultiLineString:
-		geo.Coordinates = g.MultiLineString
-	case GeometryPolygon:
-		geo.Coordinates = g.Polygon
-	case GeometryMultiPolygon:
-		geo.Coordinates = g.MultiPolygon
-	case GeometryCollection:
-		geo.Geometries = g.Geometries
+	*g = *decoded
+	if srid != 0 && srid != 4326 {
+		g.SetCRS(NewEPSGCRS(srid))
 	}
+	return nil
+}
 
-	return bson.Marshal(geo)
+// MarshalBSON converts the geometry object into the correct BSON, with
+// its members in the order of its JSON, foreign members included.
+// This fulfills the bson.Marshaler interface.
+func (g Geometry) MarshalBSON() ([]byte, error) {
+	members, err := g.members()
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(bsonDocument(members))
 }
 
 // UnmarshalBSON decodes the data into a GeoJSON geometry.
 // This fulfills the bson.Unmarshaler interface.
 func (g *Geometry) UnmarshalBSON(data []byte) error {
+	if err := DefaultDecodeLimits.checkBSON(data); err != nil {
+		return err
+	}
+
 	var object map[string]interface{}
 	err := bson.Unmarshal(data, &object)
 	if err != nil {
 		return err
 	}
 	convertAToArray(&object)
+	if err := DefaultDecodeLimits.checkObject(object); err != nil {
+		return err
+	}
 
-	return decodeGeometry(g, object)
+	return decodeGeometry(g, object, false)
 }
 
-func decodeGeometry(g *Geometry, object map[string]interface{}) error {
-	t, ok := object["type"]
-	if !ok {
-		return errors.New("type property not defined")
-	}
-
-	if s, ok := t.(string); ok {
+// decodeGeometry decodes the object into the geometry. Unless permissive,
+// an unknown type is an ErrUnsupportedGeometryType, otherwise the geometry
+// is left without coordinates.
+func decodeGeometry(g *Geometry, object map[string]interface{}, permissive bool) error {
+	if s, ok := object["type"].(string); ok {
 		g.Type = GeometryType(s)
 	} else {
-		return errors.New("type property not string")
+		return decodeError("/type", object["type"], "a geometry type")
 	}
 
 	bb, err := decodeBoundingBox(object["bbox"])
 	if err != nil {
-		return err
+		return locate(err, "/bbox")
 	}
 	g.BoundingBox = bb
 
+	switch c := object["crs"].(type) {
+	case nil:
+		g.CRS = nil
+	case map[string]interface{}:
+		g.CRS = c
+	default:
+		return decodeError("/crs", c, "an object")
+	}
+
+	g.ForeignMembers, err = decodeForeignMembers(object, geometryMembers)
+	if err != nil {
+		return err
+	}
+
+	// some systems write empty geometries with null members
+	coordinates := emptyIfNull(object, "coordinates")
 	switch g.Type {
 	case GeometryPoint:
-		g.Point, err = decodePosition(object["coordinates"])
+		g.Point, err = decodePosition(coordinates)
 	case GeometryMultiPoint:
-		g.MultiPoint, err = decodePositionSet(object["coordinates"])
+		g.MultiPoint, err = decodePositionSet(coordinates)
 	case GeometryLineString:
-		g.LineString, err = decodePositionSet(object["coordinates"])
+		g.LineString, err = decodePositionSet(coordinates)
 	case GeometryMultiLineString:
-		g.MultiLineString, err = decodePathSet(object["coordinates"])
+		g.MultiLineString, err = decodePathSet(coordinates)
 	case GeometryPolygon:
-		g.Polygon, err = decodePathSet(object["coordinates"])
+		g.Polygon, err = decodePathSet(coordinates)
 	case GeometryMultiPolygon:
-		g.MultiPolygon, err = decodePolygonSet(object["coordinates"])
+		g.MultiPolygon, err = decodePolygonSet(coordinates)
 	case GeometryCollection:
-		g.Geometries, err = decodeGeometries(object["geometries"])
+		g.Geometries, err = decodeGeometries(emptyIfNull(object, "geometries"), permissive)
+		return locate(err, "/geometries")
+	default:
+		if !permissive {
+			return &DecodeError{Pointer: "/type", Value: object["type"], Expected: "a geometry type", Err: ErrUnsupportedGeometryType}
+		}
 	}
 
-	return err
+	return locate(err, "/coordinates")
+}
+
+// emptyIfNull returns the member of the object, an empty array if it is
+// null.
+func emptyIfNull(object map[string]interface{}, key string) interface{} {
+	if value, ok := object[key]; ok && value == nil {
+		return []interface{}{}
+	}
+	return object[key]
 }
 
 func decodePosition(data interface{}) ([]float64, error) {
 	coords, ok := data.([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("not a valid position, got %#v", data)
+		return nil, decodeError("", data, "a position")
 	}
 
 	result := make([]float64, 0, len(coords))
-	for _, coord := range coords {
+	for j, coord := range coords {
 		if f, ok := coord.(float64); ok {
 			result = append(result, f)
 		} else {
@@ -282,7 +423,7 @@ func decodePosition(data interface{}) ([]float64, error) {
 				if i, ok := coord.(int64); ok {
 					result = append(result, float64(i))
 				} else {
-					return nil, fmt.Errorf("not a valid coordinate in %#v, got %T %v", data, coord, coord)
+					return nil, decodeError(indexPointer("", j), coord, "a number")
 				}
 			}
 		}
@@ -294,15 +435,15 @@ func decodePosition(data interface{}) ([]float64, error) {
 func decodePositionSet(data interface{}) ([][]float64, error) {
 	points, ok := data.([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("not a valid set of positions, got %v", data)
+		return nil, decodeError("", data, "an array of positions")
 	}
 
 	result := make([][]float64, 0, len(points))
-	for _, point := range points {
+	for i, point := range points {
 		if p, err := decodePosition(point); err == nil {
 			result = append(result, p)
 		} else {
-			return nil, err
+			return nil, locate(err, indexPointer("", i))
 		}
 	}
 
@@ -312,16 +453,16 @@ func decodePositionSet(data interface{}) ([][]float64, error) {
 func decodePathSet(data interface{}) ([][][]float64, error) {
 	sets, ok := data.([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("not a valid path, got %v", data)
+		return nil, decodeError("", data, "an array of paths")
 	}
 
 	result := make([][][]float64, 0, len(sets))
 
-	for _, set := range sets {
+	for i, set := range sets {
 		if s, err := decodePositionSet(set); err == nil {
 			result = append(result, s)
 		} else {
-			return nil, err
+			return nil, locate(err, indexPointer("", i))
 		}
 	}
 
@@ -331,46 +472,57 @@ func decodePathSet(data interface{}) ([][][]float64, error) {
 func decodePolygonSet(data interface{}) ([][][][]float64, error) {
 	polygons, ok := data.([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("not a valid polygon, got %v", data)
+		return nil, decodeError("", data, "an array of polygons")
 	}
 
 	result := make([][][][]float64, 0, len(polygons))
-	for _, polygon := range polygons {
+	for i, polygon := range polygons {
 		if p, err := decodePathSet(polygon); err == nil {
 			result = append(result, p)
 		} else {
-			return nil, err
+			return nil, locate(err, indexPointer("", i))
 		}
 	}
 
 	return result, nil
 }
 
-func decodeGeometries(data interface{}) ([]*Geometry, error) {
-	if vs, ok := data.([]interface{}); ok {
-		geometries := make([]*Geometry, 0, len(vs))
-		for _, v := range vs {
-			g := &Geometry{}
-
-			vmap, ok := v.(map[string]interface{})
-			if !ok {
-				break
-			}
+func decodeGeometries(data interface{}, permissive bool) ([]*Geometry, error) {
+	vs, ok := data.([]interface{})
+	if !ok {
+		return nil, decodeError("", data, "an array of geometries")
+	}
 
-			err := decodeGeometry(g, vmap)
-			if err != nil {
-				return nil, err
-			}
+	geometries := make([]*Geometry, 0, len(vs))
+	for i, v := range vs {
+		g := &Geometry{}
 
-			geometries = append(geometries, g)
+		vmap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, decodeError(indexPointer("", i), v, "a geometry object")
 		}
 
-		if len(geometries) == len(vs) {
-			return geometries, nil
+		err := decodeGeometry(g, vmap, permissive)
+		if err != nil {
+			return nil, locate(err, indexPointer("", i))
 		}
+
+		geometries = append(geometries, g)
 	}
 
-	return nil, fmt.Errorf("not a valid set of geometries, got %v", data)
+	return geometries, nil
+}
+
+// IsEmpty returns true if the geometry has no positions, like a point
+// with empty coordinates or a geometry collection without geometries.
+func (g *Geometry) IsEmpty() bool {
+	empty := true
+	forEachPosition(g, func(p []float64) {
+		if len(p) > 0 {
+			empty = false
+		}
+	})
+	return empty
 }
 
 // IsPoint returns true with the geometry object is a Point type.
@@ -407,3 +559,106 @@ func (g *Geometry) IsMultiPolygon() bool {
 func (g *Geometry) IsCollection() bool {
 	return g.Type == GeometryCollection
 }
+
+// forEachPosition calls fn for every position of the geometry,
+// including those of all members of a geometry collection.
+func forEachPosition(g *Geometry, fn func(p []float64)) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) != 0 {
+			fn(g.Point)
+		}
+	case GeometryMultiPoint:
+		forEachPathPosition(g.MultiPoint, fn)
+	case GeometryLineString:
+		forEachPathPosition(g.LineString, fn)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			forEachPathPosition(l, fn)
+		}
+	case GeometryPolygon:
+		for _, r := range g.Polygon {
+			forEachPathPosition(r, fn)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				forEachPathPosition(r, fn)
+			}
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			forEachPosition(c, fn)
+		}
+	}
+}
+
+func forEachPathPosition(path [][]float64, fn func(p []float64)) {
+	for _, p := range path {
+		fn(p)
+	}
+}
+
+// forEachSegment calls fn for every segment of the lines and rings of the geometry,
+// including those of all members of a geometry collection.
+func forEachSegment(g *Geometry, fn func(a, b []float64)) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryLineString:
+		forEachPathSegment(g.LineString, fn)
+	case GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			forEachPathSegment(l, fn)
+		}
+	case GeometryPolygon:
+		for _, r := range g.Polygon {
+			forEachPathSegment(r, fn)
+		}
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				forEachPathSegment(r, fn)
+			}
+		}
+	case GeometryCollection:
+		for _, c := range g.Geometries {
+			forEachSegment(c, fn)
+		}
+	}
+}
+
+func forEachPathSegment(path [][]float64, fn func(a, b []float64)) {
+	for i := 1; i < len(path); i++ {
+		fn(path[i-1], path[i])
+	}
+}
+
+// polygons returns the polygons of the geometry: itself for a polygon,
+// its members for a multi polygon and those of all the members of a collection.
+func polygons(g *Geometry) [][][][]float64 {
+	if g == nil {
+		return nil
+	}
+
+	switch g.Type {
+	case GeometryPolygon:
+		return [][][][]float64{g.Polygon}
+	case GeometryMultiPolygon:
+		return g.MultiPolygon
+	case GeometryCollection:
+		var result [][][][]float64
+		for _, c := range g.Geometries {
+			result = append(result, polygons(c)...)
+		}
+		return result
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package geojson
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -411,3 +412,123 @@ func TestBSONMultiPolygon(t *testing.T) {
 		t.Fatalf("should be the same point %v after bson round trip but got %v", *g, gg)
 	}
 }
+
+func TestEmptyGeometries(t *testing.T) {
+	cases := []struct {
+		geometry *Geometry
+		json     string
+	}{
+		{&Geometry{Type: GeometryPoint}, `{"type":"Point","coordinates":[]}`},
+		{&Geometry{Type: GeometryMultiPoint}, `{"type":"MultiPoint","coordinates":[]}`},
+		{&Geometry{Type: GeometryLineString}, `{"type":"LineString","coordinates":[]}`},
+		{&Geometry{Type: GeometryMultiLineString}, `{"type":"MultiLineString","coordinates":[]}`},
+		{&Geometry{Type: GeometryPolygon}, `{"type":"Polygon","coordinates":[]}`},
+		{&Geometry{Type: GeometryMultiPolygon}, `{"type":"MultiPolygon","coordinates":[]}`},
+		{&Geometry{Type: GeometryCollection}, `{"type":"GeometryCollection","geometries":[]}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.geometry.Type), func(t *testing.T) {
+			if !tc.geometry.IsEmpty() {
+				t.Errorf("should be empty")
+			}
+
+			data, err := json.Marshal(tc.geometry)
+			if err != nil {
+				t.Fatalf("should marshal empty geometry, but got %v", err)
+			}
+			if string(data) != tc.json {
+				t.Errorf("incorrect json, got %v", string(data))
+			}
+
+			g, err := UnmarshalGeometry(data)
+			if err != nil {
+				t.Fatalf("should unmarshal empty geometry, but got %v", err)
+			}
+			if g.Type != tc.geometry.Type || !g.IsEmpty() {
+				t.Errorf("should round trip, got %v", g)
+			}
+
+			blob, err := bson.Marshal(tc.geometry)
+			if err != nil {
+				t.Fatalf("should marshal empty geometry to bson, but got %v", err)
+			}
+			var b Geometry
+			if err := bson.Unmarshal(blob, &b); err != nil {
+				t.Fatalf("should unmarshal empty geometry from bson, but got %v", err)
+			}
+			if b.Type != tc.geometry.Type || !b.IsEmpty() {
+				t.Errorf("should round trip through bson, got %v", b)
+			}
+		})
+	}
+
+	if NewPointGeometry([]float64{1, 2}).IsEmpty() {
+		t.Errorf("point with coordinates should not be empty")
+	}
+	if !NewCollectionGeometry(&Geometry{Type: GeometryPoint}).IsEmpty() {
+		t.Errorf("collection of empty geometries should be empty")
+	}
+}
+
+func TestNewEmptyGeometry(t *testing.T) {
+	types := []GeometryType{GeometryPoint, GeometryMultiPoint, GeometryLineString,
+		GeometryMultiLineString, GeometryPolygon, GeometryMultiPolygon, GeometryCollection}
+
+	for _, typ := range types {
+		t.Run(string(typ), func(t *testing.T) {
+			empty := NewEmptyGeometry(typ)
+			if empty.Type != typ || !empty.IsEmpty() {
+				t.Errorf("should be empty, got %v", empty)
+			}
+
+			member := "coordinates"
+			if typ == GeometryCollection {
+				member = "geometries"
+			}
+			for _, data := range []string{
+				`{"type":"` + string(typ) + `","` + member + `":[]}`,
+				`{"type":"` + string(typ) + `","` + member + `":null}`,
+			} {
+				g, err := UnmarshalGeometry([]byte(data))
+				if err != nil {
+					t.Fatalf("should unmarshal %s, but got %v", data, err)
+				}
+				if !reflect.DeepEqual(g, empty) {
+					t.Errorf("should normalize %s, got %#v", data, g)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalMissingCoordinates(t *testing.T) {
+	if _, err := UnmarshalGeometry([]byte(`{"type":"Point"}`)); err == nil {
+		t.Errorf("should not unmarshal a point without coordinates")
+	}
+}
+
+func TestUnmarshalUnsupportedGeometryType(t *testing.T) {
+	cases := []string{
+		`{"type":"Circle","coordinates":[0,0]}`,
+		`{"type":"point","coordinates":[0,0]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"Curve","coordinates":[]}]}`,
+	}
+
+	for _, data := range cases {
+		_, err := UnmarshalGeometry([]byte(data))
+		if !errors.Is(err, ErrUnsupportedGeometryType) {
+			t.Errorf("should fail with an unsupported type error for %s, got %v", data, err)
+		}
+
+		g, err := DecodeOptions{AllowUnknownGeometryTypes: true}.UnmarshalGeometry([]byte(data))
+		if err != nil || g == nil {
+			t.Errorf("should decode when allowed, but got %v", err)
+		}
+	}
+
+	_, err := UnmarshalFeature([]byte(`{"type":"Feature","geometry":{"type":"Circle","coordinates":[0,0]},"properties":null}`))
+	if err == nil || err.Error() != `geometry.type: unsupported geometry type, got "Circle"` {
+		t.Errorf("incorrect feature error, got %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Unmarshal decodes the first GML geometry of the document, like the
+// geometry of the first feature of a WFS response. It reads the GML 3.2
+// geometries Marshal writes, their Curve and Surface forms with linear
+// segments and patches, and the GML 2 outerBoundaryIs, innerBoundaryIs and
+// coordinates elements.
+//
+// Positions are read latitude first, unless opts.LonLat is set or the
+// srsName of the geometry is CRS84. The other options are ignored.
+func Unmarshal(data []byte, opts Options) (*geojson.Geometry, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New("no GML geometry in the document")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || !geometryElements[start.Name.Local] {
+			continue
+		}
+
+		n, err := readNode(d, start)
+		if err != nil {
+			return nil, err
+		}
+
+		lonLat := opts.LonLat || strings.Contains(n.attrs["srsName"], "CRS84")
+		dec := &decoder{lonLat: lonLat, dims: 2}
+		return dec.geometry(n)
+	}
+}
+
+// geometryElements are the names of the geometry elements Unmarshal decodes.
+var geometryElements = map[string]bool{
+	"Point":           true,
+	"LineString":      true,
+	"Curve":           true,
+	"Polygon":         true,
+	"Surface":         true,
+	"MultiPoint":      true,
+	"MultiCurve":      true,
+	"MultiLineString": true,
+	"MultiSurface":    true,
+	"MultiPolygon":    true,
+	"MultiGeometry":   true,
+}
+
+// A node is an XML element, with its attributes and children by local name.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+func readNode(d *xml.Decoder, start xml.StartElement) (*node, error) {
+	n := &node{name: start.Name.Local, attrs: make(map[string]string)}
+	for _, a := range start.Attr {
+		n.attrs[a.Name.Local] = a.Value
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			c, err := readNode(d, tok)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, c)
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			n.text = strings.TrimSpace(text.String())
+			return n, nil
+		}
+	}
+}
+
+// child returns the first child with one of the names.
+func (n *node) child(names ...string) *node {
+	for _, c := range n.children {
+		if hasName(c, names) {
+			return c
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	lonLat bool
+	dims   int
+}
+
+func (dec *decoder) geometry(n *node) (*geojson.Geometry, error) {
+	if d, ok := n.attrs["srsDimension"]; ok {
+		dims, err := strconv.Atoi(d)
+		if err != nil || dims < 2 {
+			return nil, fmt.Errorf("invalid srsDimension %q", d)
+		}
+		defer func(dims int) { dec.dims = dims }(dec.dims)
+		dec.dims = dims
+	}
+
+	switch n.name {
+	case "Point":
+		path, err := dec.positions(n)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) != 1 {
+			return nil, fmt.Errorf("point with %d positions", len(path))
+		}
+		return geojson.NewPointGeometry(path[0]), nil
+	case "LineString", "Curve":
+		path, err := dec.curve(n)
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewLineStringGeometry(path), nil
+	case "Polygon", "Surface":
+		polygon, err := dec.surface(n)
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewPolygonGeometry(polygon), nil
+	case "MultiPoint":
+		members, err := dec.members(n, "pointMember", "pointMembers")
+		if err != nil {
+			return nil, err
+		}
+		var points [][]float64
+		for _, m := range members {
+			if m.Type != geojson.GeometryPoint {
+				return nil, fmt.Errorf("%s member in MultiPoint", m.Type)
+			}
+			points = append(points, m.Point)
+		}
+		return geojson.NewMultiPointGeometry(points...), nil
+	case "MultiCurve", "MultiLineString":
+		members, err := dec.members(n, "curveMember", "curveMembers", "lineStringMember")
+		if err != nil {
+			return nil, err
+		}
+		var lines [][][]float64
+		for _, m := range members {
+			if m.Type != geojson.GeometryLineString {
+				return nil, fmt.Errorf("%s member in %s", m.Type, n.name)
+			}
+			lines = append(lines, m.LineString)
+		}
+		return geojson.NewMultiLineStringGeometry(lines...), nil
+	case "MultiSurface", "MultiPolygon":
+		members, err := dec.members(n, "surfaceMember", "surfaceMembers", "polygonMember")
+		if err != nil {
+			return nil, err
+		}
+		var polygons [][][][]float64
+		for _, m := range members {
+			if m.Type != geojson.GeometryPolygon {
+				return nil, fmt.Errorf("%s member in %s", m.Type, n.name)
+			}
+			polygons = append(polygons, m.Polygon)
+		}
+		return geojson.NewMultiPolygonGeometry(polygons...), nil
+	case "MultiGeometry":
+		members, err := dec.members(n, "geometryMember", "geometryMembers")
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewCollectionGeometry(members...), nil
+	}
+
+	return nil, fmt.Errorf("unsupported GML geometry %s", n.name)
+}
+
+// members decodes the geometries in the member elements of a multi geometry,
+// which hold one geometry each, or several for the plural forms.
+func (dec *decoder) members(n *node, names ...string) ([]*geojson.Geometry, error) {
+	var members []*geojson.Geometry
+	for _, c := range n.children {
+		if !hasName(c, names) {
+			continue
+		}
+		for _, m := range c.children {
+			g, err := dec.geometry(m)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, g)
+		}
+	}
+	return members, nil
+}
+
+// curve returns the path of a LineString, or of a Curve with linear segments.
+func (dec *decoder) curve(n *node) ([][]float64, error) {
+	if n.name == "LineString" {
+		return dec.positions(n)
+	}
+
+	segments := n.child("segments")
+	if segments == nil {
+		return nil, errors.New("Curve without segments")
+	}
+
+	var path [][]float64
+	for _, s := range segments.children {
+		if s.name != "LineStringSegment" {
+			return nil, fmt.Errorf("unsupported curve segment %s", s.name)
+		}
+		p, err := dec.positions(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > 0 && len(p) > 0 {
+			p = p[1:] // segments share their ends
+		}
+		path = append(path, p...)
+	}
+	return path, nil
+}
+
+// surface returns the rings of a Polygon, or of a Surface with a single
+// polygon patch.
+func (dec *decoder) surface(n *node) ([][][]float64, error) {
+	if n.name == "Surface" {
+		patches := n.child("patches")
+		if patches == nil || len(patches.children) != 1 || patches.children[0].name != "PolygonPatch" {
+			return nil, errors.New("Surface without a single PolygonPatch")
+		}
+		n = patches.children[0]
+	}
+
+	var polygon [][][]float64
+	for _, c := range n.children {
+		if !hasName(c, []string{"exterior", "interior", "outerBoundaryIs", "innerBoundaryIs"}) {
+			continue
+		}
+		exterior := c.name == "exterior" || c.name == "outerBoundaryIs"
+		if exterior != (len(polygon) == 0) {
+			return nil, errors.New("polygon needs one exterior ring, before its interior rings")
+		}
+
+		ring := c.child("LinearRing")
+		if ring == nil {
+			return nil, fmt.Errorf("%s without LinearRing", c.name)
+		}
+		path, err := dec.positions(ring)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) < 4 {
+			return nil, fmt.Errorf("ring with %d positions, at least 4 are needed", len(path))
+		}
+		polygon = append(polygon, path)
+	}
+	if len(polygon) == 0 {
+		return nil, errors.New("polygon without rings")
+	}
+	return polygon, nil
+}
+
+// positions reads the positions of the pos, posList or coordinates children
+// of an element.
+func (dec *decoder) positions(n *node) ([][]float64, error) {
+	var path [][]float64
+	for _, c := range n.children {
+		switch c.name {
+		case "pos", "posList":
+			dims := dec.dims
+			if d, ok := c.attrs["srsDimension"]; ok {
+				var err error
+				if dims, err = strconv.Atoi(d); err != nil || dims < 2 {
+					return nil, fmt.Errorf("invalid srsDimension %q", d)
+				}
+			}
+
+			coords, err := parseFloats(strings.Fields(c.text))
+			if err != nil {
+				return nil, err
+			}
+			if c.name == "pos" {
+				dims = len(coords)
+			}
+			if dims < 2 || len(coords)%dims != 0 {
+				return nil, fmt.Errorf("%d coordinates is not a multiple of dimension %d", len(coords), dims)
+			}
+			for i := 0; i < len(coords); i += dims {
+				path = append(path, dec.position(coords[i:i+dims]))
+			}
+		case "coordinates":
+			for _, tuple := range strings.Fields(c.text) {
+				coords, err := parseFloats(strings.Split(tuple, ","))
+				if err != nil {
+					return nil, err
+				}
+				if len(coords) < 2 {
+					return nil, fmt.Errorf("coordinate tuple %q needs at least 2 coordinates", tuple)
+				}
+				path = append(path, dec.position(coords))
+			}
+		}
+	}
+	return path, nil
+}
+
+// position converts the coordinates to a longitude first position.
+func (dec *decoder) position(coords []float64) []float64 {
+	p := append([]float64(nil), coords...)
+	if !dec.lonLat {
+		p[0], p[1] = p[1], p[0]
+	}
+	return p
+}
+
+func parseFloats(fields []string) ([]float64, error) {
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q", f)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func hasName(n *node, names []string) bool {
+	for _, name := range names {
+		if n.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package gml
+
+import (
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []struct {
+		name     string
+		gml      string
+		opts     Options
+		geometry *geojson.Geometry
+	}{
+		{
+			"point",
+			`<gml:Point gml:id="p" xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>50.85 4.35</gml:pos></gml:Point>`,
+			Options{},
+			geojson.NewPointGeometry([]float64{4.35, 50.85}),
+		},
+		{
+			"crs84 line string",
+			`<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:OGC:1.3:CRS84"><gml:posList>1 2 3 4</gml:posList></gml:LineString>`,
+			Options{},
+			geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+		},
+		{
+			"curve",
+			`<gml:Curve xmlns:gml="http://www.opengis.net/gml/3.2"><gml:segments>` +
+				`<gml:LineStringSegment><gml:pos>1 2</gml:pos><gml:pos>3 4</gml:pos></gml:LineStringSegment>` +
+				`<gml:LineStringSegment><gml:posList>3 4 5 6</gml:posList></gml:LineStringSegment>` +
+				`</gml:segments></gml:Curve>`,
+			Options{LonLat: true},
+			geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}, {5, 6}}),
+		},
+		{
+			"gml 2 polygon",
+			`<gml:Polygon xmlns:gml="http://www.opengis.net/gml"><gml:outerBoundaryIs><gml:LinearRing>` +
+				`<gml:coordinates>0,0 1,0 1,1 0,0</gml:coordinates>` +
+				`</gml:LinearRing></gml:outerBoundaryIs></gml:Polygon>`,
+			Options{LonLat: true},
+			geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		},
+		{
+			"multi surface 3d",
+			`<gml:MultiSurface xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="3"><gml:surfaceMembers>` +
+				`<gml:Surface><gml:patches><gml:PolygonPatch><gml:exterior><gml:LinearRing><gml:posList>0 0 1 1 0 1 1 1 1 0 0 1</gml:posList></gml:LinearRing></gml:exterior></gml:PolygonPatch></gml:patches></gml:Surface>` +
+				`</gml:surfaceMembers></gml:MultiSurface>`,
+			Options{LonLat: true},
+			geojson.NewMultiPolygonGeometry([][][]float64{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}}),
+		},
+		{
+			"wfs response",
+			`<wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs/2.0" xmlns:gml="http://www.opengis.net/gml/3.2" xmlns:app="urn:app">` +
+				`<wfs:member><app:Building><app:name>Atomium</app:name><app:geometry>` +
+				`<gml:MultiGeometry><gml:geometryMember><gml:Point><gml:pos>50.89 4.34</gml:pos></gml:Point></gml:geometryMember></gml:MultiGeometry>` +
+				`</app:geometry></app:Building></wfs:member></wfs:FeatureCollection>`,
+			Options{},
+			geojson.NewCollectionGeometry(geojson.NewPointGeometry([]float64{4.34, 50.89})),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := Unmarshal([]byte(tc.gml), tc.opts)
+			if err != nil {
+				t.Fatalf("should unmarshal, but got %v", err)
+			}
+			if !g.Equal(tc.geometry) {
+				t.Errorf("incorrect geometry, got %+v", g)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRoundTrip(t *testing.T) {
+	geometries := []*geojson.Geometry{
+		geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}}),
+		geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {{0.2, 0.1}, {0.3, 0.1}, {0.3, 0.2}, {0.2, 0.1}}}),
+		geojson.NewCollectionGeometry(
+			geojson.NewPointGeometry([]float64{1, 2, 3}),
+			geojson.NewMultiPolygonGeometry([][][]float64{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}}),
+		),
+	}
+
+	for _, g := range geometries {
+		data, err := Marshal(g, Options{})
+		if err != nil {
+			t.Fatalf("should marshal, but got %v", err)
+		}
+		decoded, err := Unmarshal(data, Options{})
+		if err != nil {
+			t.Fatalf("should unmarshal %s, but got %v", data, err)
+		}
+		if !decoded.Equal(g) {
+			t.Errorf("should round trip %s, got %+v", g.Type, decoded)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no geometry":   `<root><name>none</name></root>`,
+		"dimension":     `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="3"><gml:posList>1 2 3 4</gml:posList></gml:LineString>`,
+		"coordinate":    `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 a</gml:pos></gml:Point>`,
+		"short ring":    `<gml:Polygon xmlns:gml="http://www.opengis.net/gml/3.2"><gml:exterior><gml:LinearRing><gml:posList>0 0 1 1 0 0</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon>`,
+		"member type":   `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pointMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:pointMember></gml:MultiPoint>`,
+		"truncated":     `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 2`,
+		"interior only": `<gml:Polygon xmlns:gml="http://www.opengis.net/gml/3.2"><gml:interior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:interior></gml:Polygon>`,
+	}
+
+	for name, data := range cases {
+		if _, err := Unmarshal([]byte(data), Options{}); err == nil {
+			t.Errorf("should reject %s", name)
+		}
+	}
+}
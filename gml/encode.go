@@ -0,0 +1,272 @@
+/*
+Package gml converts geometries to and from GML 3.2, the Geography Markup
+Language used by WFS services and INSPIRE datasets.
+*/
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Namespace is the XML namespace of GML 3.2.
+const Namespace = "http://www.opengis.net/gml/3.2"
+
+// DefaultSRSName is the srsName of geometries encoded without one.
+const DefaultSRSName = "http://www.opengis.net/def/crs/EPSG/0/4326"
+
+// Options configures the encoding of geometries.
+type Options struct {
+	// SRSName is the reference system set on the outer geometry,
+	// DefaultSRSName if empty.
+	SRSName string
+
+	// LonLat writes positions longitude first. By default, positions are
+	// written latitude first, the axis order EPSG:4326 and ETRS89 define.
+	LonLat bool
+
+	// IDPrefix prefixes the gml:id of the geometries, "geom" if empty.
+	IDPrefix string
+
+	// OmitNamespace leaves the xmlns:gml declaration out, for geometries
+	// embedded in a document declaring it.
+	OmitNamespace bool
+}
+
+// Marshal encodes the geometry as a GML 3.2 element. Multi line strings,
+// multi polygons and geometry collections become MultiCurve, MultiSurface
+// and MultiGeometry elements, as the older multi geometries are deprecated.
+func Marshal(g *geojson.Geometry, opts Options) ([]byte, error) {
+	if g == nil {
+		return nil, errors.New("no geometry to encode")
+	}
+
+	dims := 2
+	forEachPosition(g, func(p []float64) {
+		if len(p) > 2 {
+			dims = 3
+		}
+	})
+
+	e := &encoder{opts: opts, dims: dims}
+	if e.opts.IDPrefix == "" {
+		e.opts.IDPrefix = "geom"
+	}
+	if e.opts.SRSName == "" {
+		e.opts.SRSName = DefaultSRSName
+	}
+
+	if err := e.geometry(g, true); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf  bytes.Buffer
+	opts Options
+	dims int
+	ids  int
+}
+
+// open writes the start tag of a geometry element with its attributes.
+func (e *encoder) open(name string, outer bool) {
+	e.ids++
+	fmt.Fprintf(&e.buf, `<gml:%s gml:id="%s.%d"`, name, xmlEscape(e.opts.IDPrefix), e.ids)
+	if outer {
+		if !e.opts.OmitNamespace {
+			fmt.Fprintf(&e.buf, ` xmlns:gml="%s"`, Namespace)
+		}
+		fmt.Fprintf(&e.buf, ` srsName="%s" srsDimension="%d"`, xmlEscape(e.opts.SRSName), e.dims)
+	}
+	e.buf.WriteByte('>')
+}
+
+func (e *encoder) close(name string) {
+	fmt.Fprintf(&e.buf, "</gml:%s>", name)
+}
+
+func (e *encoder) geometry(g *geojson.Geometry, outer bool) error {
+	if g == nil {
+		return errors.New("nil geometry in collection")
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		e.open("Point", outer)
+		e.buf.WriteString("<gml:pos>")
+		if err := e.positions([][]float64{g.Point}); err != nil {
+			return err
+		}
+		e.buf.WriteString("</gml:pos>")
+		e.close("Point")
+	case geojson.GeometryLineString:
+		e.open("LineString", outer)
+		if err := e.posList(g.LineString); err != nil {
+			return err
+		}
+		e.close("LineString")
+	case geojson.GeometryPolygon:
+		return e.polygon(g.Polygon, outer)
+	case geojson.GeometryMultiPoint:
+		e.open("MultiPoint", outer)
+		for _, p := range g.MultiPoint {
+			e.buf.WriteString("<gml:pointMember>")
+			if err := e.geometry(geojson.NewPointGeometry(p), false); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:pointMember>")
+		}
+		e.close("MultiPoint")
+	case geojson.GeometryMultiLineString:
+		e.open("MultiCurve", outer)
+		for _, l := range g.MultiLineString {
+			e.buf.WriteString("<gml:curveMember>")
+			if err := e.geometry(geojson.NewLineStringGeometry(l), false); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:curveMember>")
+		}
+		e.close("MultiCurve")
+	case geojson.GeometryMultiPolygon:
+		e.open("MultiSurface", outer)
+		for _, p := range g.MultiPolygon {
+			e.buf.WriteString("<gml:surfaceMember>")
+			if err := e.polygon(p, false); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:surfaceMember>")
+		}
+		e.close("MultiSurface")
+	case geojson.GeometryCollection:
+		e.open("MultiGeometry", outer)
+		for _, c := range g.Geometries {
+			e.buf.WriteString("<gml:geometryMember>")
+			if err := e.geometry(c, false); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:geometryMember>")
+		}
+		e.close("MultiGeometry")
+	default:
+		return fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+
+	return nil
+}
+
+func (e *encoder) polygon(polygon [][][]float64, outer bool) error {
+	if len(polygon) == 0 {
+		return errors.New("polygon without rings")
+	}
+
+	e.open("Polygon", outer)
+	for i, ring := range polygon {
+		tag := "interior"
+		if i == 0 {
+			tag = "exterior"
+		}
+
+		fmt.Fprintf(&e.buf, "<gml:%s><gml:LinearRing>", tag)
+		if err := e.posList(ring); err != nil {
+			return err
+		}
+		fmt.Fprintf(&e.buf, "</gml:LinearRing></gml:%s>", tag)
+	}
+	e.close("Polygon")
+	return nil
+}
+
+func (e *encoder) posList(path [][]float64) error {
+	e.buf.WriteString("<gml:posList>")
+	if err := e.positions(path); err != nil {
+		return err
+	}
+	e.buf.WriteString("</gml:posList>")
+	return nil
+}
+
+// positions writes the coordinates of the positions separated by spaces,
+// in the configured axis order and with the altitude if encoding in 3D.
+func (e *encoder) positions(path [][]float64) error {
+	for i, p := range path {
+		if len(p) < 2 {
+			return fmt.Errorf("position %v needs at least 2 coordinates", p)
+		}
+
+		x, y := p[1], p[0]
+		if e.opts.LonLat {
+			x, y = p[0], p[1]
+		}
+		coords := []float64{x, y}
+		if e.dims == 3 {
+			z := 0.0
+			if len(p) > 2 {
+				z = p[2]
+			}
+			coords = append(coords, z)
+		}
+
+		for j, c := range coords {
+			if math.IsNaN(c) || math.IsInf(c, 0) {
+				return fmt.Errorf("coordinate %v can not be encoded", c)
+			}
+			if i > 0 || j > 0 {
+				e.buf.WriteByte(' ')
+			}
+			e.buf.WriteString(strconv.FormatFloat(c, 'f', -1, 64))
+		}
+	}
+	return nil
+}
+
+func forEachPosition(g *geojson.Geometry, fn func(p []float64)) {
+	if g == nil {
+		return
+	}
+
+	path := func(path [][]float64) {
+		for _, p := range path {
+			fn(p)
+		}
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		fn(g.Point)
+	case geojson.GeometryMultiPoint:
+		path(g.MultiPoint)
+	case geojson.GeometryLineString:
+		path(g.LineString)
+	case geojson.GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			path(l)
+		}
+	case geojson.GeometryPolygon:
+		for _, r := range g.Polygon {
+			path(r)
+		}
+	case geojson.GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			for _, r := range p {
+				path(r)
+			}
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			forEachPosition(c, fn)
+		}
+	}
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
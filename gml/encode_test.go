@@ -0,0 +1,107 @@
+package gml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *geojson.Geometry
+		opts     Options
+		gml      string
+	}{
+		{
+			"point",
+			geojson.NewPointGeometry([]float64{4.35, 50.85}),
+			Options{},
+			`<gml:Point gml:id="geom.1" xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/4326" srsDimension="2"><gml:pos>50.85 4.35</gml:pos></gml:Point>`,
+		},
+		{
+			"line string lon lat",
+			geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+			Options{LonLat: true, SRSName: "urn:ogc:def:crs:OGC:1.3:CRS84", IDPrefix: "road", OmitNamespace: true},
+			`<gml:LineString gml:id="road.1" srsName="urn:ogc:def:crs:OGC:1.3:CRS84" srsDimension="2"><gml:posList>1 2 3 4</gml:posList></gml:LineString>`,
+		},
+		{
+			"polygon",
+			geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {{0.2, 0.1}, {0.3, 0.1}, {0.3, 0.2}, {0.2, 0.1}}}),
+			Options{LonLat: true, OmitNamespace: true},
+			`<gml:Polygon gml:id="geom.1" srsName="http://www.opengis.net/def/crs/EPSG/0/4326" srsDimension="2">` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>0.2 0.1 0.3 0.1 0.3 0.2 0.2 0.1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			"multi polygon 3d",
+			geojson.NewMultiPolygonGeometry([][][]float64{{{0, 0, 1}, {1, 0, 1}, {1, 1}, {0, 0, 1}}}),
+			Options{LonLat: true, OmitNamespace: true},
+			`<gml:MultiSurface gml:id="geom.1" srsName="http://www.opengis.net/def/crs/EPSG/0/4326" srsDimension="3">` +
+				`<gml:surfaceMember><gml:Polygon gml:id="geom.2"><gml:exterior><gml:LinearRing><gml:posList>0 0 1 1 0 1 1 1 0 0 0 1</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon></gml:surfaceMember>` +
+				`</gml:MultiSurface>`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.geometry, tc.opts)
+			if err != nil {
+				t.Fatalf("should marshal, but got %v", err)
+			}
+			if string(data) != tc.gml {
+				t.Errorf("incorrect gml, got %s", data)
+			}
+		})
+	}
+}
+
+func TestMarshalWellFormed(t *testing.T) {
+	g := geojson.NewCollectionGeometry(
+		geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+	)
+
+	data, err := Marshal(g, Options{IDPrefix: `a"&b`})
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	d := xml.NewDecoder(strings.NewReader(string(data)))
+	elements := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("should be well formed xml, but got %v", err)
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			elements++
+		}
+	}
+	if elements != 14 {
+		t.Errorf("should be well formed xml, got %v elements in %s", elements, data)
+	}
+	if !strings.Contains(string(data), "<gml:MultiCurve") || !strings.Contains(string(data), "<gml:MultiGeometry") {
+		t.Errorf("should use GML 3.2 multi geometries, got %s", data)
+	}
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	for _, g := range []*geojson.Geometry{
+		nil,
+		{Type: "Circle"},
+		geojson.NewPointGeometry([]float64{1}),
+		geojson.NewPolygonGeometry(nil),
+	} {
+		if _, err := Marshal(g, Options{}); err == nil {
+			t.Errorf("should fail for %v", g)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Package gpx converts the waypoints, routes and tracks of GPX files to and from
+GeoJSON features, for fitness and telemetry data. Elevations become the
+altitude of positions and timestamps properties.
+*/
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Namespace is the XML namespace of GPX 1.1.
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+// The properties set on decoded features, and read when encoding them.
+const (
+	// KindProperty tells what a feature was in the GPX file, KindWaypoint,
+	// KindRoute or KindTrack.
+	KindProperty = "gpx"
+
+	// TimeProperty is the timestamp of a waypoint.
+	TimeProperty = "time"
+
+	// TimesProperty holds the timestamps of the positions of routes and
+	// tracks, a list for a line string and a list per line for a multi line
+	// string. Missing timestamps are null.
+	TimesProperty = "times"
+)
+
+// The kinds of GPX elements.
+const (
+	KindWaypoint = "waypoint"
+	KindRoute    = "route"
+	KindTrack    = "track"
+)
+
+type document struct {
+	XMLName   xml.Name `xml:"gpx"`
+	Namespace string   `xml:"xmlns,attr,omitempty"`
+	Version   string   `xml:"version,attr"`
+	Creator   string   `xml:"creator,attr"`
+	Waypoints []point  `xml:"wpt"`
+	Routes    []route  `xml:"rte"`
+	Tracks    []track  `xml:"trk"`
+}
+
+type point struct {
+	Lat         float64  `xml:"lat,attr"`
+	Lon         float64  `xml:"lon,attr"`
+	Elevation   *float64 `xml:"ele"`
+	Time        string   `xml:"time,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	Comment     string   `xml:"cmt,omitempty"`
+	Description string   `xml:"desc,omitempty"`
+	Symbol      string   `xml:"sym,omitempty"`
+	Type        string   `xml:"type,omitempty"`
+}
+
+type route struct {
+	Name        string  `xml:"name,omitempty"`
+	Comment     string  `xml:"cmt,omitempty"`
+	Description string  `xml:"desc,omitempty"`
+	Type        string  `xml:"type,omitempty"`
+	Points      []point `xml:"rtept"`
+}
+
+type track struct {
+	Name        string    `xml:"name,omitempty"`
+	Comment     string    `xml:"cmt,omitempty"`
+	Description string    `xml:"desc,omitempty"`
+	Type        string    `xml:"type,omitempty"`
+	Segments    []segment `xml:"trkseg"`
+}
+
+type segment struct {
+	Points []point `xml:"trkpt"`
+}
+
+// The properties holding the descriptive elements.
+var descriptions = []string{"name", "cmt", "desc", "type"}
+
+// Unmarshal decodes a GPX document into a point feature per waypoint, a line
+// string feature per route and a line string, or multi line string if it has
+// several segments, per track. Positions have an altitude when all the
+// points of their feature have an elevation.
+func Unmarshal(data []byte) (*geojson.FeatureCollection, error) {
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	fc := geojson.NewFeatureCollection()
+	for i, w := range doc.Waypoints {
+		if err := w.check(); err != nil {
+			return nil, fmt.Errorf("waypoint %d: %v", i, err)
+		}
+
+		f := geojson.NewPointFeature(w.position(w.Elevation != nil))
+		setDescriptions(f, w.Name, w.Comment, w.Description, w.Type)
+		if w.Symbol != "" {
+			f.SetProperty("sym", w.Symbol)
+		}
+		if w.Time != "" {
+			f.SetProperty(TimeProperty, w.Time)
+		}
+		f.SetProperty(KindProperty, KindWaypoint)
+		fc.AddFeature(f)
+	}
+
+	for i, r := range doc.Routes {
+		lines, times, err := decodeLines([][]point{r.Points})
+		if err != nil {
+			return nil, fmt.Errorf("route %d: %v", i, err)
+		}
+
+		f := geojson.NewLineStringFeature(lines[0])
+		setDescriptions(f, r.Name, r.Comment, r.Description, r.Type)
+		if times != nil {
+			f.SetProperty(TimesProperty, times[0])
+		}
+		f.SetProperty(KindProperty, KindRoute)
+		fc.AddFeature(f)
+	}
+
+	for i, t := range doc.Tracks {
+		segments := make([][]point, len(t.Segments))
+		for j, s := range t.Segments {
+			segments[j] = s.Points
+		}
+		lines, times, err := decodeLines(segments)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %v", i, err)
+		}
+
+		var f *geojson.Feature
+		if len(lines) == 1 {
+			f = geojson.NewLineStringFeature(lines[0])
+			if times != nil {
+				f.SetProperty(TimesProperty, times[0])
+			}
+		} else {
+			f = geojson.NewMultiLineStringFeature(lines...)
+			if times != nil {
+				list := make([]interface{}, len(times))
+				for i, l := range times {
+					list[i] = l
+				}
+				f.SetProperty(TimesProperty, list)
+			}
+		}
+		setDescriptions(f, t.Name, t.Comment, t.Description, t.Type)
+		f.SetProperty(KindProperty, KindTrack)
+		fc.AddFeature(f)
+	}
+
+	return fc, nil
+}
+
+// decodeLines converts the points into lines, with altitudes if all the points
+// have an elevation, and returns their timestamps, nil if none has one.
+func decodeLines(paths [][]point) ([][][]float64, [][]interface{}, error) {
+	elevations, timed := true, false
+	for _, path := range paths {
+		for _, p := range path {
+			if err := p.check(); err != nil {
+				return nil, nil, err
+			}
+			elevations = elevations && p.Elevation != nil
+			timed = timed || p.Time != ""
+		}
+	}
+
+	lines := make([][][]float64, len(paths))
+	var times [][]interface{}
+	if timed {
+		times = make([][]interface{}, len(paths))
+	}
+	for i, path := range paths {
+		lines[i] = make([][]float64, len(path))
+		for j, p := range path {
+			lines[i][j] = p.position(elevations)
+		}
+
+		if timed {
+			times[i] = make([]interface{}, len(path))
+			for j, p := range path {
+				if p.Time != "" {
+					times[i][j] = p.Time
+				}
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		lines = [][][]float64{{}}
+		if timed {
+			times = [][]interface{}{{}}
+		}
+	}
+	return lines, times, nil
+}
+
+func (p point) check() error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("invalid position lat=%v lon=%v", p.Lat, p.Lon)
+	}
+	if p.Elevation != nil && *p.Elevation != *p.Elevation {
+		return errors.New("invalid elevation")
+	}
+	return nil
+}
+
+func (p point) position(elevation bool) []float64 {
+	if elevation {
+		return []float64{p.Lon, p.Lat, *p.Elevation}
+	}
+	return []float64{p.Lon, p.Lat}
+}
+
+func setDescriptions(f *geojson.Feature, values ...string) {
+	for i, v := range values {
+		if v != "" {
+			f.SetProperty(descriptions[i], v)
+		}
+	}
+}
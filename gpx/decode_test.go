@@ -0,0 +1,107 @@
+package gpx
+
+import (
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+const testDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+	<metadata><name>Morning run</name></metadata>
+	<wpt lat="50.85" lon="4.35">
+		<ele>13.5</ele>
+		<time>2024-05-01T07:00:00Z</time>
+		<name>Start</name>
+		<sym>Flag</sym>
+	</wpt>
+	<rte>
+		<name>Plan</name>
+		<rtept lat="50.85" lon="4.35"/>
+		<rtept lat="50.86" lon="4.36"/>
+	</rte>
+	<trk>
+		<name>Run</name>
+		<type>running</type>
+		<trkseg>
+			<trkpt lat="50.85" lon="4.35"><ele>13</ele><time>2024-05-01T07:00:00Z</time></trkpt>
+			<trkpt lat="50.86" lon="4.36"><ele>15</ele><time>2024-05-01T07:05:00Z</time></trkpt>
+		</trkseg>
+		<trkseg>
+			<trkpt lat="50.87" lon="4.37"><ele>16</ele></trkpt>
+			<trkpt lat="50.88" lon="4.38"><ele>14</ele><time>2024-05-01T07:15:00Z</time></trkpt>
+		</trkseg>
+	</trk>
+</gpx>`
+
+func TestUnmarshal(t *testing.T) {
+	fc, err := Unmarshal([]byte(testDocument))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if len(fc.Features) != 3 {
+		t.Fatalf("should have a feature per waypoint, route and track, got %d", len(fc.Features))
+	}
+
+	wpt := fc.Features[0]
+	if !wpt.Geometry.Equal(geojson.NewPointGeometry([]float64{4.35, 50.85, 13.5})) {
+		t.Errorf("incorrect waypoint, got %v", wpt.Geometry.Point)
+	}
+	if wpt.PropertyMustString(TimeProperty) != "2024-05-01T07:00:00Z" || wpt.PropertyMustString("name") != "Start" ||
+		wpt.PropertyMustString("sym") != "Flag" || wpt.PropertyMustString(KindProperty) != KindWaypoint {
+		t.Errorf("incorrect waypoint properties, got %v", wpt.Properties)
+	}
+
+	rte := fc.Features[1]
+	if !rte.Geometry.Equal(geojson.NewLineStringGeometry([][]float64{{4.35, 50.85}, {4.36, 50.86}})) {
+		t.Errorf("incorrect route, got %v", rte.Geometry.LineString)
+	}
+	if _, ok := rte.Properties[TimesProperty]; ok {
+		t.Errorf("should not have timestamps without times, got %v", rte.Properties)
+	}
+
+	trk := fc.Features[2]
+	expected := geojson.NewMultiLineStringGeometry(
+		[][]float64{{4.35, 50.85, 13}, {4.36, 50.86, 15}},
+		[][]float64{{4.37, 50.87, 16}, {4.38, 50.88, 14}},
+	)
+	if !trk.Geometry.Equal(expected) {
+		t.Errorf("incorrect track, got %v", trk.Geometry.MultiLineString)
+	}
+	times := trk.Properties[TimesProperty].([]interface{})
+	if times[0].([]interface{})[1] != "2024-05-01T07:05:00Z" || times[1].([]interface{})[0] != nil {
+		t.Errorf("incorrect timestamps, got %v", times)
+	}
+	if trk.PropertyMustString("type") != "running" || trk.PropertyMustString(KindProperty) != KindTrack {
+		t.Errorf("incorrect track properties, got %v", trk.Properties)
+	}
+}
+
+func TestUnmarshalPartialElevations(t *testing.T) {
+	data := `<gpx><trk><trkseg>
+		<trkpt lat="1" lon="2"><ele>3</ele></trkpt>
+		<trkpt lat="4" lon="5"/>
+	</trkseg></trk></gpx>`
+
+	fc, err := Unmarshal([]byte(data))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if !fc.Features[0].Geometry.Equal(geojson.NewLineStringGeometry([][]float64{{2, 1}, {5, 4}})) {
+		t.Errorf("should drop partial elevations, got %v", fc.Features[0].Geometry.LineString)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	cases := map[string]string{
+		"xml":      `<gpx><wpt`,
+		"latitude": `<gpx><wpt lat="91" lon="0"/></gpx>`,
+		"number":   `<gpx><wpt lat="a" lon="0"/></gpx>`,
+	}
+
+	for name, data := range cases {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("should reject invalid %s", name)
+		}
+	}
+}
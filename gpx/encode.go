@@ -0,0 +1,159 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// DefaultCreator is the creator of documents encoded without one.
+const DefaultCreator = "github.com/fmechant/go.geojson"
+
+// Options configures the encoding of GPX documents.
+type Options struct {
+	// Creator is the program written as creator of the document,
+	// DefaultCreator if empty.
+	Creator string
+}
+
+// Marshal encodes the features as a GPX 1.1 document. Points and multi
+// points become waypoints, line strings become tracks, or routes if their
+// KindProperty is KindRoute, and multi line strings tracks with a segment per
+// line. The altitudes are written as elevations and the TimeProperty and
+// TimesProperty as timestamps. Other geometries are an error.
+func Marshal(fc *geojson.FeatureCollection, opts Options) ([]byte, error) {
+	doc := document{
+		Namespace: Namespace,
+		Version:   "1.1",
+		Creator:   opts.Creator,
+	}
+	if doc.Creator == "" {
+		doc.Creator = DefaultCreator
+	}
+
+	for i, f := range fc.Features {
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+		if err := doc.add(f); err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+	}
+
+	data, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (doc *document) add(f *geojson.Feature) error {
+	name, comment, description, kind := descriptionsOf(f)
+
+	switch f.Geometry.Type {
+	case geojson.GeometryPoint, geojson.GeometryMultiPoint:
+		positions := f.Geometry.MultiPoint
+		if f.Geometry.Type == geojson.GeometryPoint {
+			positions = [][]float64{f.Geometry.Point}
+		}
+
+		time, _ := f.PropertyString(TimeProperty)
+		symbol, _ := f.PropertyString("sym")
+		for _, p := range positions {
+			w, err := encodePoint(p, time)
+			if err != nil {
+				return err
+			}
+			w.Name, w.Comment, w.Description, w.Type, w.Symbol = name, comment, description, kind, symbol
+			doc.Waypoints = append(doc.Waypoints, w)
+		}
+	case geojson.GeometryLineString:
+		points, err := encodePoints(f.Geometry.LineString, f.Properties[TimesProperty])
+		if err != nil {
+			return err
+		}
+
+		if k, _ := f.PropertyString(KindProperty); k == KindRoute {
+			doc.Routes = append(doc.Routes, route{
+				Name: name, Comment: comment, Description: description, Type: kind,
+				Points: points,
+			})
+			break
+		}
+		doc.Tracks = append(doc.Tracks, track{
+			Name: name, Comment: comment, Description: description, Type: kind,
+			Segments: []segment{{points}},
+		})
+	case geojson.GeometryMultiLineString:
+		times, _ := f.Properties[TimesProperty].([]interface{})
+		if len(times) != len(f.Geometry.MultiLineString) {
+			times = nil
+		}
+
+		t := track{Name: name, Comment: comment, Description: description, Type: kind}
+		for i, l := range f.Geometry.MultiLineString {
+			var lineTimes interface{}
+			if times != nil {
+				lineTimes = times[i]
+			}
+			points, err := encodePoints(l, lineTimes)
+			if err != nil {
+				return err
+			}
+			t.Segments = append(t.Segments, segment{points})
+		}
+		doc.Tracks = append(doc.Tracks, t)
+	default:
+		return fmt.Errorf("geometry type %s can not be written as GPX", f.Geometry.Type)
+	}
+
+	return nil
+}
+
+// encodePoints converts the positions, with their timestamps if times is a
+// list of the same length.
+func encodePoints(path [][]float64, times interface{}) ([]point, error) {
+	list, _ := times.([]interface{})
+	if len(list) != len(path) {
+		list = nil
+	}
+
+	points := make([]point, len(path))
+	for i, p := range path {
+		time := ""
+		if list != nil {
+			time, _ = list[i].(string)
+		}
+
+		var err error
+		if points[i], err = encodePoint(p, time); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+func encodePoint(p []float64, time string) (point, error) {
+	if len(p) < 2 {
+		return point{}, errors.New("position must have at least 2 coordinates")
+	}
+
+	w := point{Lon: p[0], Lat: p[1], Time: time}
+	if len(p) > 2 {
+		ele := p[2]
+		w.Elevation = &ele
+	}
+	return w, w.check()
+}
+
+// descriptionsOf returns the name, comment, description and type of the
+// feature, from its properties.
+func descriptionsOf(f *geojson.Feature) (string, string, string, string) {
+	values := make([]string, len(descriptions))
+	for i, key := range descriptions {
+		values[i], _ = f.PropertyString(key)
+	}
+	return values[0], values[1], values[2], values[3]
+}
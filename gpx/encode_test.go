@@ -0,0 +1,74 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	w := geojson.NewPointFeature([]float64{4.35, 50.85, 13.5})
+	w.SetProperty("name", "Start")
+	w.SetProperty(TimeProperty, "2024-05-01T07:00:00Z")
+	fc.AddFeature(w)
+
+	r := geojson.NewLineStringFeature([][]float64{{4.35, 50.85}, {4.36, 50.86}})
+	r.SetProperty(KindProperty, KindRoute)
+	fc.AddFeature(r)
+
+	data, err := Marshal(fc, Options{})
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	expected := xml.Header + `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="github.com/fmechant/go.geojson">` +
+		`<wpt lat="50.85" lon="4.35"><ele>13.5</ele><time>2024-05-01T07:00:00Z</time><name>Start</name></wpt>` +
+		`<rte><rtept lat="50.85" lon="4.35"></rtept><rtept lat="50.86" lon="4.36"></rtept></rte></gpx>`
+	if string(data) != expected {
+		t.Errorf("incorrect document, expected %s, got %s", expected, data)
+	}
+
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+	if _, err := Marshal(fc, Options{}); err == nil {
+		t.Errorf("should reject polygons")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	original, err := Unmarshal([]byte(testDocument))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+
+	data, err := Marshal(original, Options{Creator: "test"})
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if len(decoded.Features) != len(original.Features) {
+		t.Fatalf("should round trip all the features, got %d", len(decoded.Features))
+	}
+
+	for i, f := range original.Features {
+		g := decoded.Features[i]
+		if !g.Geometry.Equal(f.Geometry) {
+			t.Errorf("should round trip the geometry of feature %d, got %+v", i, g.Geometry)
+		}
+		for k, v := range f.Properties {
+			if k != TimesProperty && g.Properties[k] != v {
+				t.Errorf("should round trip property %s of feature %d, got %v", k, i, g.Properties[k])
+			}
+		}
+	}
+
+	times := decoded.Features[2].Properties[TimesProperty].([]interface{})
+	if times[0].([]interface{})[0] != "2024-05-01T07:00:00Z" || times[1].([]interface{})[0] != nil || times[1].([]interface{})[1] != "2024-05-01T07:15:00Z" {
+		t.Errorf("should round trip the timestamps, got %v", times)
+	}
+}
@@ -0,0 +1,274 @@
+package geojson
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+)
+
+// ErrNoPath is returned by Graph.ShortestPath when the nodes are not connected.
+var ErrNoPath = errors.New("no path between the positions")
+
+// GraphOptions configures how BuildGraph turns line features into a graph.
+type GraphOptions struct {
+	// Directed makes edges only traversable in the direction of their line.
+	Directed bool
+
+	// Weight computes the cost of traversing an edge cut from the feature,
+	// given the length of the edge in meters. Defaults to the length,
+	// which allows A* search. Negative weights make the edge impassable.
+	Weight func(f *Feature, length float64) float64
+
+	// Properties are the feature properties copied onto its edges.
+	Properties []string
+
+	// Measurer computes the edge lengths, Geodesic if not set.
+	Measurer Measurer
+}
+
+// A GraphNode is a position where lines meet or end.
+type GraphNode struct {
+	Position []float64
+	Edges    []int // indexes of the edges leaving the node
+}
+
+// A GraphEdge is a piece of line between two nodes.
+type GraphEdge struct {
+	From, To   int
+	Path       [][]float64
+	Length     float64
+	Weight     float64
+	Feature    int // index of the feature in the collection
+	Properties map[string]interface{}
+}
+
+// A Graph is a routing network built from line features.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+
+	lengthWeight bool
+	measurer     Measurer
+}
+
+// BuildGraph builds a routing graph from the line strings and multi line
+// strings of the collection. Lines are split into edges at their ends and at
+// every vertex they share with another line, or with themselves.
+func BuildGraph(lines *FeatureCollection, opts GraphOptions) (*Graph, error) {
+	g := &Graph{lengthWeight: opts.Weight == nil, measurer: opts.Measurer}
+	if g.measurer == nil {
+		g.measurer = Geodesic{}
+	}
+
+	type path struct {
+		feature int
+		line    [][]float64
+	}
+	var paths []path
+	for i, f := range lines.Features {
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+		switch f.Geometry.Type {
+		case GeometryLineString:
+			paths = append(paths, path{i, f.Geometry.LineString})
+		case GeometryMultiLineString:
+			for _, l := range f.Geometry.MultiLineString {
+				paths = append(paths, path{i, l})
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no line strings to build a graph from")
+	}
+
+	uses := make(map[[2]float64]int)
+	for _, p := range paths {
+		for i, v := range p.line {
+			uses[[2]float64{v[0], v[1]}]++
+			if i == 0 || i == len(p.line)-1 {
+				// ends are always nodes
+				uses[[2]float64{v[0], v[1]}]++
+			}
+		}
+	}
+
+	nodes := make(map[[2]float64]int)
+	node := func(v []float64) int {
+		key := [2]float64{v[0], v[1]}
+		if n, ok := nodes[key]; ok {
+			return n
+		}
+		nodes[key] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, GraphNode{Position: []float64{v[0], v[1]}})
+		return len(g.Nodes) - 1
+	}
+
+	for _, p := range paths {
+		if len(p.line) < 2 {
+			continue
+		}
+
+		f := lines.Features[p.feature]
+		start := 0
+		for i := 1; i < len(p.line); i++ {
+			if i != len(p.line)-1 && uses[[2]float64{p.line[i][0], p.line[i][1]}] < 2 {
+				continue
+			}
+
+			e := GraphEdge{
+				From:    node(p.line[start]),
+				To:      node(p.line[i]),
+				Path:    p.line[start : i+1],
+				Feature: p.feature,
+			}
+			e.Length = g.measurer.Length(NewLineStringGeometry(e.Path))
+			e.Weight = e.Length
+			if opts.Weight != nil {
+				e.Weight = opts.Weight(f, e.Length)
+			}
+			for _, key := range opts.Properties {
+				if v, ok := f.Properties[key]; ok {
+					if e.Properties == nil {
+						e.Properties = make(map[string]interface{})
+					}
+					e.Properties[key] = v
+				}
+			}
+
+			g.Nodes[e.From].Edges = append(g.Nodes[e.From].Edges, len(g.Edges))
+			if !opts.Directed && e.From != e.To {
+				g.Nodes[e.To].Edges = append(g.Nodes[e.To].Edges, len(g.Edges))
+			}
+			g.Edges = append(g.Edges, e)
+			start = i
+		}
+	}
+
+	return g, nil
+}
+
+// NearestNode returns the index of the node closest to the position.
+func (g *Graph) NearestNode(p []float64) int {
+	nearest, min := -1, math.Inf(1)
+	for i, n := range g.Nodes {
+		if d := angularDistance(p, n.Position); d < min {
+			nearest, min = i, d
+		}
+	}
+	return nearest
+}
+
+// ShortestPath finds the cheapest route between the nodes closest to the
+// given positions, and returns it as a line string feature with the total
+// "length" in meters and "weight" as properties. It uses A* when the
+// weights are the edge lengths and Dijkstra otherwise.
+func (g *Graph) ShortestPath(from, to []float64) (*Feature, error) {
+	source, target := g.NearestNode(from), g.NearestNode(to)
+	if source < 0 || target < 0 {
+		return nil, ErrNoPath
+	}
+
+	heuristic := func(n int) float64 { return 0 }
+	if g.lengthWeight {
+		goal := g.Nodes[target].Position
+		heuristic = func(n int) float64 {
+			return g.measurer.Distance(g.Nodes[n].Position, goal)
+		}
+	}
+
+	cost := make([]float64, len(g.Nodes))
+	via := make([]int, len(g.Nodes))
+	for i := range cost {
+		cost[i], via[i] = math.Inf(1), -1
+	}
+	cost[source] = 0
+
+	queue := &graphQueue{{node: source, priority: heuristic(source)}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(graphQueueItem)
+		n := item.node
+		if n == target {
+			break
+		}
+		if item.priority > cost[n]+heuristic(n) {
+			continue // stale entry
+		}
+
+		for _, ei := range g.Nodes[n].Edges {
+			e := g.Edges[ei]
+			if e.Weight < 0 {
+				continue
+			}
+
+			next := e.To
+			if next == n {
+				next = e.From
+			}
+
+			c := cost[n] + e.Weight
+			if c < cost[next] {
+				cost[next], via[next] = c, ei
+				heap.Push(queue, graphQueueItem{node: next, priority: c + heuristic(next)})
+			}
+		}
+	}
+
+	if math.IsInf(cost[target], 1) {
+		return nil, ErrNoPath
+	}
+
+	var edges []int
+	for n := target; n != source; {
+		ei := via[n]
+		edges = append(edges, ei)
+		if g.Edges[ei].To == n {
+			n = g.Edges[ei].From
+		} else {
+			n = g.Edges[ei].To
+		}
+	}
+
+	line := [][]float64{g.Nodes[source].Position}
+	length := 0.0
+	at := source
+	for i := len(edges) - 1; i >= 0; i-- {
+		e := g.Edges[edges[i]]
+		length += e.Length
+		if e.From == at {
+			line = append(line, e.Path[1:]...)
+			at = e.To
+		} else {
+			for j := len(e.Path) - 2; j >= 0; j-- {
+				line = append(line, e.Path[j])
+			}
+			at = e.From
+		}
+	}
+	if len(line) == 1 {
+		line = append(line, line[0])
+	}
+
+	f := NewLineStringFeature(line)
+	f.SetProperty("length", length)
+	f.SetProperty("weight", cost[target])
+	return f, nil
+}
+
+type graphQueueItem struct {
+	node     int
+	priority float64
+}
+
+type graphQueue []graphQueueItem
+
+func (q graphQueue) Len() int            { return len(q) }
+func (q graphQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q graphQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *graphQueue) Push(x interface{}) { *q = append(*q, x.(graphQueueItem)) }
+func (q *graphQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
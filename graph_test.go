@@ -0,0 +1,104 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func graphTestCollection() *FeatureCollection {
+	fc := NewFeatureCollection()
+
+	// a square with a slow diagonal, crossing lines meet at shared vertices
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 0}, {1, 1}}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {0, 1}, {1, 1}}))
+	diagonal := NewLineStringFeature([][]float64{{0, 0}, {1, 1}})
+	diagonal.SetProperty("speed", 0.1)
+	fc.AddFeature(diagonal)
+
+	return fc
+}
+
+func TestBuildGraph(t *testing.T) {
+	g, err := BuildGraph(graphTestCollection(), GraphOptions{Properties: []string{"speed"}})
+	if err != nil {
+		t.Fatalf("should build the graph, but got %v", err)
+	}
+
+	if len(g.Nodes) != 2 {
+		t.Errorf("should only have nodes at the line ends, got %v", len(g.Nodes))
+	}
+	if len(g.Edges) != 3 {
+		t.Errorf("should have an edge per line, got %v", len(g.Edges))
+	}
+	if g.Edges[2].Properties["speed"] != 0.1 {
+		t.Errorf("should copy the selected properties, got %v", g.Edges[2].Properties)
+	}
+	if g.Edges[0].Properties != nil {
+		t.Errorf("should not have properties when missing, got %v", g.Edges[0].Properties)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 0}, {2, 0}}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{1, -1}, {1, 0}, {1, 1}}))
+	g, _ = BuildGraph(fc, GraphOptions{})
+	if len(g.Nodes) != 5 || len(g.Edges) != 4 {
+		t.Errorf("should split lines at shared vertices, got %v nodes and %v edges", len(g.Nodes), len(g.Edges))
+	}
+
+	if _, err := BuildGraph(NewFeatureCollection(), GraphOptions{}); err == nil {
+		t.Errorf("should fail without lines")
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	g, _ := BuildGraph(graphTestCollection(), GraphOptions{})
+
+	f, err := g.ShortestPath([]float64{0.01, 0}, []float64{1, 0.99})
+	if err != nil {
+		t.Fatalf("should find a path, but got %v", err)
+	}
+	if len(f.Geometry.LineString) != 2 {
+		t.Errorf("should take the diagonal, got %v", f.Geometry.LineString)
+	}
+
+	expected := Geodesic{}.Distance([]float64{0, 0}, []float64{1, 1})
+	if l, _ := f.PropertyFloat64("length"); math.Abs(l-expected) > 1e-6 {
+		t.Errorf("incorrect length, got %v expected %v", l, expected)
+	}
+
+	slow := func(f *Feature, length float64) float64 {
+		if speed, err := f.PropertyFloat64("speed"); err == nil {
+			return length / speed
+		}
+		return length
+	}
+	g, _ = BuildGraph(graphTestCollection(), GraphOptions{Weight: slow})
+
+	f, err = g.ShortestPath([]float64{1, 1}, []float64{0, 0})
+	if err != nil {
+		t.Fatalf("should find a path, but got %v", err)
+	}
+	if len(f.Geometry.LineString) != 3 {
+		t.Errorf("should avoid the slow diagonal, got %v", f.Geometry.LineString)
+	}
+	if p := f.Geometry.LineString; p[0][0] != 1 || p[0][1] != 1 || p[2][0] != 0 || p[2][1] != 0 {
+		t.Errorf("should follow edges backwards from start to end, got %v", p)
+	}
+}
+
+func TestShortestPathDirected(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 0}}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{5, 5}, {6, 5}}))
+
+	g, _ := BuildGraph(fc, GraphOptions{Directed: true})
+	if _, err := g.ShortestPath([]float64{0, 0}, []float64{1, 0}); err != nil {
+		t.Errorf("should follow the line direction, but got %v", err)
+	}
+	if _, err := g.ShortestPath([]float64{1, 0}, []float64{0, 0}); err != ErrNoPath {
+		t.Errorf("should not go against the line direction, got %v", err)
+	}
+	if _, err := g.ShortestPath([]float64{0, 0}, []float64{6, 5}); err != ErrNoPath {
+		t.Errorf("should not connect separate networks, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package geojson
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// An InternCache makes identical geometries share their coordinates, so
+// denormalized datasets, like thousands of features repeating the same
+// administrative boundary, hold a single copy of them in memory.
+//
+// Geometries are identical if they are Equal: bounding boxes, CRS and
+// foreign members are not compared and are kept by every geometry. As the
+// coordinates are shared, changing them in place changes them in all the
+// geometries using them; use Clone before editing an interned geometry.
+//
+// An InternCache is safe for concurrent use.
+type InternCache struct {
+	mu         sync.Mutex
+	geometries map[uint64][]*Geometry
+	n          int
+}
+
+// NewInternCache creates and initializes an empty intern cache.
+func NewInternCache() *InternCache {
+	return &InternCache{geometries: make(map[uint64][]*Geometry)}
+}
+
+// Intern makes the geometry share the coordinates of the identical geometry
+// interned before, if any, and returns true. Otherwise, the geometry is
+// added to the cache and false is returned. The members of geometry
+// collections are interned one by one, and true is returned if any of them
+// was shared.
+func (c *InternCache) Intern(g *Geometry) bool {
+	if g == nil {
+		return false
+	}
+
+	if g.Type == GeometryCollection {
+		shared := false
+		for _, m := range g.Geometries {
+			shared = c.Intern(m) || shared
+		}
+		return shared
+	}
+
+	key := geometryHash(g)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cached := range c.geometries[key] {
+		if cached.Equal(g) {
+			g.Point = cached.Point
+			g.MultiPoint = cached.MultiPoint
+			g.LineString = cached.LineString
+			g.MultiLineString = cached.MultiLineString
+			g.Polygon = cached.Polygon
+			g.MultiPolygon = cached.MultiPolygon
+			return true
+		}
+	}
+
+	c.geometries[key] = append(c.geometries[key], g)
+	c.n++
+	return false
+}
+
+// Len returns the number of distinct geometries in the cache.
+func (c *InternCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// geometryHash returns the hash of the type and the coordinates of the
+// geometry, consistent with Equal.
+func geometryHash(g *Geometry) uint64 {
+	h := geometryHasher{hash: fnv.New64a()}
+	h.hash.Write([]byte(g.Type))
+
+	switch g.Type {
+	case GeometryPoint:
+		h.position(g.Point)
+	case GeometryMultiPoint:
+		h.path(g.MultiPoint)
+	case GeometryLineString:
+		h.path(g.LineString)
+	case GeometryMultiLineString:
+		h.paths(g.MultiLineString)
+	case GeometryPolygon:
+		h.paths(g.Polygon)
+	case GeometryMultiPolygon:
+		h.length(len(g.MultiPolygon))
+		for _, p := range g.MultiPolygon {
+			h.paths(p)
+		}
+	}
+
+	return h.hash.Sum64()
+}
+
+type geometryHasher struct {
+	hash hash.Hash64
+	buf  [8]byte
+}
+
+func (h *geometryHasher) uint64(x uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], x)
+	h.hash.Write(h.buf[:])
+}
+
+// length hashes the length of an array, so differently nested coordinates
+// hash differently.
+func (h *geometryHasher) length(n int) {
+	h.uint64(uint64(n))
+}
+
+func (h *geometryHasher) position(p []float64) {
+	h.length(len(p))
+	for _, x := range p {
+		if x == 0 {
+			// -0 equals 0
+			x = 0
+		}
+		h.uint64(math.Float64bits(x))
+	}
+}
+
+func (h *geometryHasher) path(path [][]float64) {
+	h.length(len(path))
+	for _, p := range path {
+		h.position(p)
+	}
+}
+
+func (h *geometryHasher) paths(paths [][][]float64) {
+	h.length(len(paths))
+	for _, path := range paths {
+		h.path(path)
+	}
+}
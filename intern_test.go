@@ -0,0 +1,95 @@
+package geojson
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInternCache(t *testing.T) {
+	c := NewInternCache()
+
+	a := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	b := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	b.BoundingBox = []float64{0, 0, 1, 1}
+	other := NewPolygonGeometry([][][]float64{{{0, 0}, {2, 0}, {2, 2}, {0, 0}}})
+	line := NewLineStringGeometry([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+
+	if c.Intern(a) {
+		t.Errorf("should not share the first geometry")
+	}
+	if !c.Intern(b) {
+		t.Fatalf("should share the identical geometry")
+	}
+	if &b.Polygon[0][0][0] != &a.Polygon[0][0][0] {
+		t.Errorf("should share the coordinates")
+	}
+	if b.BoundingBox == nil {
+		t.Errorf("should keep the bounding box")
+	}
+	if c.Intern(other) || c.Intern(line) {
+		t.Errorf("should not share different geometries")
+	}
+	if c.Intern(nil) {
+		t.Errorf("should not share nil")
+	}
+	if c.Len() != 3 {
+		t.Errorf("incorrect length, got %d", c.Len())
+	}
+
+	collection := NewCollectionGeometry(NewPointGeometry([]float64{5, 5}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+	if !c.Intern(collection) {
+		t.Errorf("should share a member of the collection")
+	}
+	if &collection.Geometries[1].Polygon[0][0][0] != &a.Polygon[0][0][0] {
+		t.Errorf("should share the coordinates of the member")
+	}
+}
+
+func TestInternCacheNegativeZero(t *testing.T) {
+	c := NewInternCache()
+	c.Intern(NewPointGeometry([]float64{0, 1}))
+	negativeZero := []float64{0, 1}
+	negativeZero[0] = -negativeZero[0]
+	if !c.Intern(NewPointGeometry(negativeZero)) {
+		t.Errorf("should share the coordinates of an equal point")
+	}
+}
+
+func TestInternCacheConcurrent(t *testing.T) {
+	c := NewInternCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Intern(NewPointGeometry([]float64{float64(j), 0}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() != 100 {
+		t.Errorf("incorrect length, got %d", c.Len())
+	}
+}
+
+func TestDecodeOptionsIntern(t *testing.T) {
+	c := NewInternCache()
+	fc, err := DecodeOptions{Intern: c}.UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"id":1}},
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"id":2}},
+		{"type":"Feature","geometry":null,"properties":{"id":3}}
+	]}`))
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	if &fc.Features[0].Geometry.LineString[0] != &fc.Features[1].Geometry.LineString[0] {
+		t.Errorf("should share the coordinates")
+	}
+	if c.Len() != 1 {
+		t.Errorf("incorrect length, got %d", c.Len())
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Command regiongen generates the Go file embedding a region index in the
+geojson package, behind a build tag, from the polygon features of a GeoJSON
+feature collection keyed by a property:
+
+	go run ./internal/cmd/regiongen -in ne_110m_admin_0_countries.geojson \
+		-key ISO_A2 -var embeddedCountries -tag geojson_countries -out countries_data.go
+
+The index is encoded by RegionIndex.MarshalBinary and written as a string
+constant, so it needs no go:embed. The polygons can be simplified first to
+keep the index small.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func main() {
+	in := flag.String("in", "", "GeoJSON feature collection of the regions")
+	key := flag.String("key", "", "property holding the keys of the regions")
+	name := flag.String("var", "", "embeddedIndex variable of the geojson package to set, like embeddedCountries")
+	tag := flag.String("tag", "", "build tag of the generated file")
+	out := flag.String("out", "", "generated Go file")
+	tolerance := flag.Float64("tolerance", 0, "simplification tolerance in degrees, 0 to keep the polygons as they are")
+	flag.Parse()
+
+	if *in == "" || *key == "" || *name == "" || *tag == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := generate(*in, *key, *name, *tag, *out, *tolerance); err != nil {
+		fmt.Fprintf(os.Stderr, "regiongen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(in, key, name, tag, out string, tolerance float64) error {
+	data, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	fc, err := geojson.UnmarshalFeatureCollection(data)
+	if err != nil {
+		return err
+	}
+	if tolerance > 0 {
+		for _, f := range fc.Features {
+			if f != nil && f.Geometry != nil {
+				f.Geometry = geojson.Simplify(f.Geometry, tolerance)
+			}
+		}
+	}
+
+	index, err := geojson.NewRegionIndex(fc, key)
+	if err != nil {
+		return err
+	}
+	if index.Len() == 0 {
+		return fmt.Errorf("no regions in %s", in)
+	}
+	encoded, err := index.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by regiongen from %s; DO NOT EDIT.\n\n", filepath.Base(in))
+	fmt.Fprintf(&buf, "//go:build %s\n// +build %s\n\n", tag, tag)
+	fmt.Fprintf(&buf, "package geojson\n\n")
+	fmt.Fprintf(&buf, "func init() {\n\t%s.data = []byte(%sData)\n}\n\n", name, name)
+	fmt.Fprintf(&buf, "// %sData is the index of %d regions keyed by %s.\n", name, index.Len(), key)
+	fmt.Fprintf(&buf, "const %sData = %s\n", name, quote(encoded))
+	return ioutil.WriteFile(out, buf.Bytes(), 0644)
+}
+
+// quote returns the data as a Go string literal, split in lines.
+func quote(data []byte) string {
+	var buf bytes.Buffer
+	buf.WriteString("\"\" +\n\t\"")
+	for i, b := range data {
+		if i > 0 && i%32 == 0 {
+			buf.WriteString("\" +\n\t\"")
+		}
+		fmt.Fprintf(&buf, "\\x%02x", b)
+	}
+	buf.WriteString("\"")
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+package pbf
+
+import (
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var nested Writer
+	nested.Text(1, "inner")
+
+	var w Writer
+	w.Uint64(1, 300)
+	w.Sint64(2, -5)
+	w.Double(3, 1.5)
+	w.Float(4, 2.5)
+	w.Bool(5, true)
+	w.Message(6, &nested)
+	w.PackedUint32(7, []uint32{1, 2, 300})
+	w.PackedSint64(8, []int64{-1, 1})
+	w.Int64(9, -1)
+
+	r := NewReader(w.Bytes())
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			if v := r.Uint64(); v != 300 {
+				t.Errorf("incorrect varint, got %v", v)
+			}
+		case 2:
+			if v := r.Sint64(); v != -5 {
+				t.Errorf("incorrect zigzag varint, got %v", v)
+			}
+		case 3:
+			if v := r.Double(); v != 1.5 {
+				t.Errorf("incorrect double, got %v", v)
+			}
+		case 4:
+			if v := r.Float(); v != 2.5 {
+				t.Errorf("incorrect float, got %v", v)
+			}
+		case 5:
+			if !r.Bool() {
+				t.Errorf("incorrect bool")
+			}
+		case 6:
+			inner := NewReader(r.Bytes())
+			if !inner.Next() || inner.Text() != "inner" {
+				t.Errorf("incorrect message")
+			}
+		case 7:
+			if v := r.PackedUint32(); len(v) != 3 || v[2] != 300 {
+				t.Errorf("incorrect packed varints, got %v", v)
+			}
+		case 8:
+			if v := r.PackedSint64(); len(v) != 2 || v[0] != -1 {
+				t.Errorf("incorrect packed zigzag varints, got %v", v)
+			}
+		case 9:
+			if v := r.Int64(); v != -1 {
+				t.Errorf("incorrect negative varint, got %v", v)
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("should read the message, but got %v", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	var w Writer
+	w.Text(1, "truncated")
+	data := w.Bytes()
+
+	r := NewReader(data[:len(data)-2])
+	for r.Next() {
+		_ = r.Text()
+	}
+	if r.Err() == nil {
+		t.Errorf("should fail on truncated data")
+	}
+
+	r = NewReader(data)
+	r.Next()
+	r.Uint64()
+	if r.Err() == nil {
+		t.Errorf("should fail on wrong wire type")
+	}
+}
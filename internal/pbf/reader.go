@@ -0,0 +1,251 @@
+// Package pbf reads and writes the protocol buffers wire format, enough for
+// the hand written message codecs of the vector tile, geobuf and flatgeobuf
+// packages, without generated code or an external dependency.
+package pbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The wire types of the protocol buffers encoding.
+const (
+	Varint  = 0
+	Fixed64 = 1
+	Bytes   = 2
+	Fixed32 = 5
+)
+
+// ErrUnexpectedEOF is returned when a message ends in the middle of a field.
+var ErrUnexpectedEOF = errors.New("pbf: unexpected end of message")
+
+// A Reader iterates over the fields of an encoded message.
+type Reader struct {
+	data []byte
+	pos  int
+
+	field    int
+	wireType int
+	err      error
+}
+
+// NewReader creates and initializes a reader over the encoded message.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Next advances to the next field, returning false at the end of the
+// message or on an error, see Err.
+func (r *Reader) Next() bool {
+	if r.err != nil || r.pos >= len(r.data) {
+		return false
+	}
+
+	key, err := r.varint()
+	if err != nil {
+		r.err = err
+		return false
+	}
+
+	r.field, r.wireType = int(key>>3), int(key&7)
+	if r.field == 0 {
+		r.err = errors.New("pbf: invalid field number 0")
+		return false
+	}
+	return true
+}
+
+// Field returns the number of the current field.
+func (r *Reader) Field() int {
+	return r.field
+}
+
+// WireType returns the wire type of the current field.
+func (r *Reader) WireType() int {
+	return r.wireType
+}
+
+// Err returns the first error met while reading.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Uint64 reads the current varint field.
+func (r *Reader) Uint64() uint64 {
+	if !r.expect(Varint) {
+		return 0
+	}
+	v, err := r.varint()
+	r.fail(err)
+	return v
+}
+
+// Uint32 reads the current varint field as an uint32.
+func (r *Reader) Uint32() uint32 {
+	return uint32(r.Uint64())
+}
+
+// Int64 reads the current varint field as an int64.
+func (r *Reader) Int64() int64 {
+	return int64(r.Uint64())
+}
+
+// Sint64 reads the current zigzag encoded varint field.
+func (r *Reader) Sint64() int64 {
+	return DecodeZigzag(r.Uint64())
+}
+
+// Bool reads the current varint field as a bool.
+func (r *Reader) Bool() bool {
+	return r.Uint64() != 0
+}
+
+// Double reads the current fixed64 field as a float64.
+func (r *Reader) Double() float64 {
+	if !r.expect(Fixed64) {
+		return 0
+	}
+	if len(r.data)-r.pos < 8 {
+		r.fail(ErrUnexpectedEOF)
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+	r.pos += 8
+	return v
+}
+
+// Float reads the current fixed32 field as a float32.
+func (r *Reader) Float() float32 {
+	if !r.expect(Fixed32) {
+		return 0
+	}
+	if len(r.data)-r.pos < 4 {
+		r.fail(ErrUnexpectedEOF)
+		return 0
+	}
+	v := math.Float32frombits(binary.LittleEndian.Uint32(r.data[r.pos:]))
+	r.pos += 4
+	return v
+}
+
+// Bytes reads the current length delimited field, sharing the message data.
+func (r *Reader) Bytes() []byte {
+	if !r.expect(Bytes) {
+		return nil
+	}
+
+	n, err := r.varint()
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		r.fail(ErrUnexpectedEOF)
+		return nil
+	}
+
+	v := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return v
+}
+
+// Text reads the current length delimited field as a string.
+func (r *Reader) Text() string {
+	return string(r.Bytes())
+}
+
+// PackedUint32 reads the current packed repeated varint field.
+func (r *Reader) PackedUint32() []uint32 {
+	packed := NewReader(r.Bytes())
+
+	var result []uint32
+	for packed.pos < len(packed.data) {
+		v, err := packed.varint()
+		if err != nil {
+			r.fail(err)
+			return nil
+		}
+		result = append(result, uint32(v))
+	}
+	return result
+}
+
+// PackedSint64 reads the current packed repeated zigzag encoded varint field.
+func (r *Reader) PackedSint64() []int64 {
+	packed := NewReader(r.Bytes())
+
+	var result []int64
+	for packed.pos < len(packed.data) {
+		v, err := packed.varint()
+		if err != nil {
+			r.fail(err)
+			return nil
+		}
+		result = append(result, DecodeZigzag(v))
+	}
+	return result
+}
+
+// Skip skips the current field.
+func (r *Reader) Skip() {
+	switch r.wireType {
+	case Varint:
+		_, err := r.varint()
+		r.fail(err)
+	case Fixed64:
+		r.skip(8)
+	case Fixed32:
+		r.skip(4)
+	case Bytes:
+		r.Bytes()
+	default:
+		r.fail(fmt.Errorf("pbf: unsupported wire type %d", r.wireType))
+	}
+}
+
+func (r *Reader) skip(n int) {
+	if len(r.data)-r.pos < n {
+		r.fail(ErrUnexpectedEOF)
+		return
+	}
+	r.pos += n
+}
+
+func (r *Reader) expect(wireType int) bool {
+	if r.err != nil {
+		return false
+	}
+	if r.wireType != wireType {
+		r.fail(fmt.Errorf("pbf: field %d has wire type %d, expected %d", r.field, r.wireType, wireType))
+		return false
+	}
+	return true
+}
+
+func (r *Reader) fail(err error) {
+	if err != nil && r.err == nil {
+		r.err = err
+		r.pos = len(r.data)
+	}
+}
+
+func (r *Reader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, ErrUnexpectedEOF
+	}
+	r.pos += n
+	return v, nil
+}
+
+// DecodeZigzag decodes a zigzag encoded signed integer.
+func DecodeZigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// EncodeZigzag zigzag encodes a signed integer.
+func EncodeZigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
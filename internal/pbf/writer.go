@@ -0,0 +1,117 @@
+package pbf
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// A Writer encodes the fields of a message.
+type Writer struct {
+	buf []byte
+}
+
+// Bytes returns the encoded message.
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+// Len returns the length of the encoded message.
+func (w *Writer) Len() int {
+	return len(w.buf)
+}
+
+func (w *Writer) key(field, wireType int) {
+	w.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (w *Writer) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	w.buf = append(w.buf, b[:n]...)
+}
+
+// Uint64 writes a varint field.
+func (w *Writer) Uint64(field int, v uint64) {
+	w.key(field, Varint)
+	w.varint(v)
+}
+
+// Int64 writes a varint field, negative values taking 10 bytes.
+func (w *Writer) Int64(field int, v int64) {
+	w.Uint64(field, uint64(v))
+}
+
+// Sint64 writes a zigzag encoded varint field.
+func (w *Writer) Sint64(field int, v int64) {
+	w.Uint64(field, EncodeZigzag(v))
+}
+
+// Bool writes a varint field holding a bool.
+func (w *Writer) Bool(field int, v bool) {
+	if v {
+		w.Uint64(field, 1)
+	} else {
+		w.Uint64(field, 0)
+	}
+}
+
+// Double writes a fixed64 field.
+func (w *Writer) Double(field int, v float64) {
+	w.key(field, Fixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	w.buf = append(w.buf, b[:]...)
+}
+
+// Float writes a fixed32 field.
+func (w *Writer) Float(field int, v float32) {
+	w.key(field, Fixed32)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+	w.buf = append(w.buf, b[:]...)
+}
+
+// BytesField writes a length delimited field.
+func (w *Writer) BytesField(field int, v []byte) {
+	w.key(field, Bytes)
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// Text writes a length delimited field holding a string.
+func (w *Writer) Text(field int, v string) {
+	w.key(field, Bytes)
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// Message writes an embedded message field.
+func (w *Writer) Message(field int, m *Writer) {
+	w.BytesField(field, m.buf)
+}
+
+// PackedUint32 writes a packed repeated varint field, nothing if it is empty.
+func (w *Writer) PackedUint32(field int, v []uint32) {
+	if len(v) == 0 {
+		return
+	}
+
+	var packed Writer
+	for _, e := range v {
+		packed.varint(uint64(e))
+	}
+	w.BytesField(field, packed.buf)
+}
+
+// PackedSint64 writes a packed repeated zigzag encoded varint field, nothing if it is empty.
+func (w *Writer) PackedSint64(field int, v []int64) {
+	if len(v) == 0 {
+		return
+	}
+
+	var packed Writer
+	for _, e := range v {
+		packed.varint(EncodeZigzag(e))
+	}
+	w.BytesField(field, packed.buf)
+}
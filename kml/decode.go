@@ -0,0 +1,287 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Unmarshal decodes the placemarks of a KML document into features, wherever
+// they are in its folders. The placemark id becomes the feature id, and the
+// name, description and extended data the properties, as strings since KML
+// does not type them. Placemarks without a supported geometry get a null
+// geometry.
+func Unmarshal(data []byte) (*geojson.FeatureCollection, error) {
+	fc := geojson.NewFeatureCollection()
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		f, err := decodePlacemark(d, start)
+		if err != nil {
+			return nil, fmt.Errorf("placemark %d: %v", len(fc.Features), err)
+		}
+		fc.AddFeature(f)
+	}
+
+	return fc, nil
+}
+
+type extendedData struct {
+	Data []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	} `xml:"Data"`
+	SchemaData []struct {
+		SimpleData []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"SimpleData"`
+	} `xml:"SchemaData"`
+}
+
+func decodePlacemark(d *xml.Decoder, start xml.StartElement) (*geojson.Feature, error) {
+	f := geojson.NewFeature(nil)
+	for _, a := range start.Attr {
+		if a.Name.Local == "id" {
+			f.ID = a.Value
+		}
+	}
+
+	return f, forEachChild(d, func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case NameProperty, DescriptionProperty:
+			var s string
+			if err := d.DecodeElement(&s, &child); err != nil {
+				return err
+			}
+			f.SetProperty(child.Name.Local, strings.TrimSpace(s))
+		case "ExtendedData":
+			var ed extendedData
+			if err := d.DecodeElement(&ed, &child); err != nil {
+				return err
+			}
+			for _, data := range ed.Data {
+				f.SetProperty(data.Name, data.Value)
+			}
+			for _, sd := range ed.SchemaData {
+				for _, data := range sd.SimpleData {
+					f.SetProperty(data.Name, data.Value)
+				}
+			}
+		default:
+			g, err := decodeGeometry(d, child)
+			if err != nil {
+				return err
+			}
+			if g != nil {
+				f.Geometry = g
+			}
+		}
+		return nil
+	})
+}
+
+// decodeGeometry decodes a geometry element, or skips the element and
+// returns nil if it is not a supported geometry.
+func decodeGeometry(d *xml.Decoder, start xml.StartElement) (*geojson.Geometry, error) {
+	switch start.Name.Local {
+	case "Point":
+		path, err := decodeCoordinates(d)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) != 1 {
+			return nil, fmt.Errorf("point with %d positions", len(path))
+		}
+		return geojson.NewPointGeometry(path[0]), nil
+	case "LineString", "LinearRing":
+		path, err := decodeCoordinates(d)
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewLineStringGeometry(path), nil
+	case "Polygon":
+		polygon, err := decodePolygon(d)
+		if err != nil {
+			return nil, err
+		}
+		return geojson.NewPolygonGeometry(polygon), nil
+	case "MultiGeometry":
+		return decodeMultiGeometry(d)
+	}
+
+	return nil, d.Skip()
+}
+
+func decodePolygon(d *xml.Decoder) ([][][]float64, error) {
+	var outer [][]float64
+	var inner [][][]float64
+	err := forEachChild(d, func(boundary xml.StartElement) error {
+		name := boundary.Name.Local
+		if name != "outerBoundaryIs" && name != "innerBoundaryIs" {
+			return d.Skip()
+		}
+
+		return forEachChild(d, func(ring xml.StartElement) error {
+			if ring.Name.Local != "LinearRing" {
+				return d.Skip()
+			}
+
+			path, err := decodeCoordinates(d)
+			if err != nil {
+				return err
+			}
+			if name == "outerBoundaryIs" {
+				outer = path
+			} else {
+				inner = append(inner, path)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if outer == nil {
+		return nil, errors.New("polygon without outer boundary")
+	}
+
+	return append([][][]float64{outer}, inner...), nil
+}
+
+// decodeMultiGeometry returns a multi point, multi line string or multi
+// polygon if all the geometries are of the matching type, a geometry
+// collection otherwise.
+func decodeMultiGeometry(d *xml.Decoder) (*geojson.Geometry, error) {
+	var geometries []*geojson.Geometry
+	err := forEachChild(d, func(child xml.StartElement) error {
+		g, err := decodeGeometry(d, child)
+		if g != nil {
+			geometries = append(geometries, g)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(geometries) > 0 {
+		same := true
+		for _, g := range geometries {
+			same = same && g.Type == geometries[0].Type
+		}
+
+		if same {
+			switch geometries[0].Type {
+			case geojson.GeometryPoint:
+				points := make([][]float64, len(geometries))
+				for i, g := range geometries {
+					points[i] = g.Point
+				}
+				return geojson.NewMultiPointGeometry(points...), nil
+			case geojson.GeometryLineString:
+				lines := make([][][]float64, len(geometries))
+				for i, g := range geometries {
+					lines[i] = g.LineString
+				}
+				return geojson.NewMultiLineStringGeometry(lines...), nil
+			case geojson.GeometryPolygon:
+				polygons := make([][][][]float64, len(geometries))
+				for i, g := range geometries {
+					polygons[i] = g.Polygon
+				}
+				return geojson.NewMultiPolygonGeometry(polygons...), nil
+			}
+		}
+	}
+
+	return geojson.NewCollectionGeometry(geometries...), nil
+}
+
+// decodeCoordinates decodes the coordinates element of a geometry element,
+// tuples of comma separated coordinates separated by white space.
+func decodeCoordinates(d *xml.Decoder) ([][]float64, error) {
+	var path [][]float64
+	found := false
+	err := forEachChild(d, func(child xml.StartElement) error {
+		if child.Name.Local != "coordinates" {
+			return d.Skip()
+		}
+		found = true
+
+		var s string
+		if err := d.DecodeElement(&s, &child); err != nil {
+			return err
+		}
+
+		// some writers put spaces after the commas
+		s = strings.Replace(s, ", ", ",", -1)
+		for _, tuple := range strings.Fields(s) {
+			parts := strings.Split(strings.Trim(tuple, ","), ",")
+			if len(parts) < 2 {
+				return fmt.Errorf("invalid coordinates %q", tuple)
+			}
+
+			p := make([]float64, len(parts))
+			for i, part := range parts {
+				v, err := strconv.ParseFloat(part, 64)
+				if err != nil {
+					return fmt.Errorf("invalid coordinates %q", tuple)
+				}
+				p[i] = v
+			}
+			path = append(path, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("missing coordinates")
+	}
+
+	return path, nil
+}
+
+// forEachChild calls fn with the start of each child element, until the end
+// of the current element. fn must consume the whole child element.
+func forEachChild(d *xml.Decoder, fn func(child xml.StartElement) error) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package kml
+
+import (
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+const testDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document>
+	<name>Test</name>
+	<Style id="s"><LineStyle><width>2</width></LineStyle></Style>
+	<Folder>
+		<name>Parks</name>
+		<Placemark id="p1">
+			<name>Park</name>
+			<description><![CDATA[A <b>big</b> park]]></description>
+			<styleUrl>#s</styleUrl>
+			<ExtendedData>
+				<Data name="area"><displayName>Area</displayName><value>12.5</value></Data>
+				<SchemaData schemaUrl="#schema"><SimpleData name="kind">public</SimpleData></SchemaData>
+			</ExtendedData>
+			<Polygon>
+				<extrude>1</extrude>
+				<outerBoundaryIs><LinearRing><coordinates>
+					0,0,10 4,0,10
+					4,4,10 0,0,10
+				</coordinates></LinearRing></outerBoundaryIs>
+				<innerBoundaryIs>
+					<LinearRing><coordinates>1,1 2,1 2,2 1,1</coordinates></LinearRing>
+				</innerBoundaryIs>
+			</Polygon>
+		</Placemark>
+	</Folder>
+	<Placemark>
+		<MultiGeometry>
+			<Point><coordinates>1, 2</coordinates></Point>
+			<LineString><coordinates>1,2 3,4</coordinates></LineString>
+		</MultiGeometry>
+	</Placemark>
+	<Placemark>
+		<MultiGeometry>
+			<LineString><coordinates>1,2 3,4</coordinates></LineString>
+			<LineString><coordinates>5,6 7,8</coordinates></LineString>
+		</MultiGeometry>
+	</Placemark>
+	<Placemark><name>No geometry</name></Placemark>
+</Document>
+</kml>`
+
+func TestUnmarshal(t *testing.T) {
+	fc, err := Unmarshal([]byte(testDocument))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if len(fc.Features) != 4 {
+		t.Fatalf("should find the placemarks in folders, got %d", len(fc.Features))
+	}
+
+	park := fc.Features[0]
+	if park.ID != "p1" {
+		t.Errorf("should use the placemark id, got %v", park.ID)
+	}
+	expectedProperties := map[string]string{"name": "Park", "description": "A <b>big</b> park", "area": "12.5", "kind": "public"}
+	for k, v := range expectedProperties {
+		if s := park.PropertyMustString(k); s != v {
+			t.Errorf("incorrect property %s, expected %q, got %q", k, v, s)
+		}
+	}
+
+	expected := []*geojson.Geometry{
+		geojson.NewPolygonGeometry([][][]float64{
+			{{0, 0, 10}, {4, 0, 10}, {4, 4, 10}, {0, 0, 10}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+		}),
+		geojson.NewCollectionGeometry(
+			geojson.NewPointGeometry([]float64{1, 2}),
+			geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+		),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}}),
+	}
+	for i, g := range expected {
+		if !fc.Features[i].Geometry.Equal(g) {
+			t.Errorf("incorrect geometry %d, got %+v", i, fc.Features[i].Geometry)
+		}
+	}
+	if fc.Features[3].Geometry != nil {
+		t.Errorf("should have a null geometry, got %+v", fc.Features[3].Geometry)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewMultiPolygonFeature(
+		[][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		[][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+	))
+	fc.AddFeature(geojson.NewFeature(geojson.NewCollectionGeometry(
+		geojson.NewPointGeometry([]float64{1, 2}),
+		geojson.NewMultiPointGeometry([]float64{3, 4}, []float64{5, 6}),
+	)))
+
+	data, err := Marshal(fc, Options{})
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+
+	for i, f := range fc.Features {
+		if !decoded.Features[i].Geometry.Equal(f.Geometry) {
+			t.Errorf("should round trip %v, got %+v", f.Geometry.Type, decoded.Features[i].Geometry)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	cases := map[string]string{
+		"truncated":   `<kml><Placemark><Point>`,
+		"coordinates": `<kml><Placemark><Point><coordinates>1,a</coordinates></Point></Placemark></kml>`,
+		"tuple":       `<kml><Placemark><LineString><coordinates>1 2</coordinates></LineString></Placemark></kml>`,
+		"missing":     `<kml><Placemark><LineString></LineString></Placemark></kml>`,
+		"boundary":    `<kml><Placemark><Polygon></Polygon></Placemark></kml>`,
+	}
+
+	for name, data := range cases {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("should reject invalid %s", name)
+		}
+	}
+}
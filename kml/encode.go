@@ -0,0 +1,255 @@
+/*
+Package kml converts KML placemarks, as used by Google Earth, to and from
+GeoJSON features. Points, line strings, polygons and multi geometries map to
+the GeoJSON geometries, and the extended data of placemarks to properties.
+*/
+package kml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// Namespace is the XML namespace of KML 2.2.
+const Namespace = "http://www.opengis.net/kml/2.2"
+
+// The properties mapped to the name and description elements of placemarks,
+// instead of their extended data.
+const (
+	NameProperty        = "name"
+	DescriptionProperty = "description"
+)
+
+// Options configures the encoding of KML documents.
+type Options struct {
+	// DocumentName is the name of the document, left out if empty.
+	DocumentName string
+}
+
+// Marshal encodes the features as placemarks of a KML document. The feature
+// ids become placemark ids, and the properties extended data, except the
+// name and description. Values that are not strings, numbers or booleans are
+// written as JSON. Multi geometries and geometry collections become
+// MultiGeometry elements. KML coordinates are longitude/latitude in WGS 84.
+func Marshal(fc *geojson.FeatureCollection, opts Options) ([]byte, error) {
+	e := &encoder{}
+	e.buf.WriteString(xml.Header)
+	fmt.Fprintf(&e.buf, `<kml xmlns="%s"><Document>`, Namespace)
+	if opts.DocumentName != "" {
+		fmt.Fprintf(&e.buf, "<name>%s</name>", xmlEscape(opts.DocumentName))
+	}
+
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+		if err := e.placemark(f); err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+	}
+
+	e.buf.WriteString("</Document></kml>")
+	return e.buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) placemark(f *geojson.Feature) error {
+	e.buf.WriteString("<Placemark")
+	if f.ID != nil {
+		fmt.Fprintf(&e.buf, ` id="%s"`, xmlEscape(fmt.Sprint(f.ID)))
+	}
+	e.buf.WriteByte('>')
+
+	for _, key := range []string{NameProperty, DescriptionProperty} {
+		if v, ok := f.Properties[key]; ok && v != nil {
+			s, err := text(v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&e.buf, "<%s>%s</%s>", key, xmlEscape(s), key)
+		}
+	}
+
+	keys := make([]string, 0, len(f.Properties))
+	for k := range f.Properties {
+		if k != NameProperty && k != DescriptionProperty {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		e.buf.WriteString("<ExtendedData>")
+		for _, k := range keys {
+			s, err := text(f.Properties[k])
+			if err != nil {
+				return fmt.Errorf("property %s: %v", k, err)
+			}
+			fmt.Fprintf(&e.buf, `<Data name="%s"><value>%s</value></Data>`, xmlEscape(k), xmlEscape(s))
+		}
+		e.buf.WriteString("</ExtendedData>")
+	}
+
+	if f.Geometry != nil {
+		if err := e.geometry(f.Geometry); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteString("</Placemark>")
+	return nil
+}
+
+func (e *encoder) geometry(g *geojson.Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry in collection")
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		return e.element("Point", [][]float64{g.Point})
+	case geojson.GeometryLineString:
+		return e.element("LineString", g.LineString)
+	case geojson.GeometryPolygon:
+		return e.polygon(g.Polygon)
+	case geojson.GeometryMultiPoint:
+		e.buf.WriteString("<MultiGeometry>")
+		for _, p := range g.MultiPoint {
+			if err := e.element("Point", [][]float64{p}); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteString("</MultiGeometry>")
+	case geojson.GeometryMultiLineString:
+		e.buf.WriteString("<MultiGeometry>")
+		for _, l := range g.MultiLineString {
+			if err := e.element("LineString", l); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteString("</MultiGeometry>")
+	case geojson.GeometryMultiPolygon:
+		e.buf.WriteString("<MultiGeometry>")
+		for _, p := range g.MultiPolygon {
+			if err := e.polygon(p); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteString("</MultiGeometry>")
+	case geojson.GeometryCollection:
+		e.buf.WriteString("<MultiGeometry>")
+		for _, c := range g.Geometries {
+			if err := e.geometry(c); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteString("</MultiGeometry>")
+	default:
+		return fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+
+	return nil
+}
+
+// element writes a geometry element holding only coordinates.
+func (e *encoder) element(name string, path [][]float64) error {
+	fmt.Fprintf(&e.buf, "<%s>", name)
+	if err := e.coordinates(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(&e.buf, "</%s>", name)
+	return nil
+}
+
+func (e *encoder) polygon(polygon [][][]float64) error {
+	if len(polygon) == 0 {
+		return errors.New("polygon without rings")
+	}
+
+	e.buf.WriteString("<Polygon>")
+	for i, ring := range polygon {
+		tag := "innerBoundaryIs"
+		if i == 0 {
+			tag = "outerBoundaryIs"
+		}
+
+		fmt.Fprintf(&e.buf, "<%s>", tag)
+		if err := e.element("LinearRing", ring); err != nil {
+			return err
+		}
+		fmt.Fprintf(&e.buf, "</%s>", tag)
+	}
+	e.buf.WriteString("</Polygon>")
+	return nil
+}
+
+// coordinates writes the positions as space separated tuples of comma
+// separated coordinates.
+func (e *encoder) coordinates(path [][]float64) error {
+	e.buf.WriteString("<coordinates>")
+	for i, p := range path {
+		if len(p) < 2 {
+			return fmt.Errorf("position %v needs at least 2 coordinates", p)
+		}
+		if i > 0 {
+			e.buf.WriteByte(' ')
+		}
+
+		n := len(p)
+		if n > 3 {
+			n = 3
+		}
+		for j, c := range p[:n] {
+			if math.IsNaN(c) || math.IsInf(c, 0) {
+				return fmt.Errorf("coordinate %v can not be encoded", c)
+			}
+			if j > 0 {
+				e.buf.WriteByte(',')
+			}
+			e.buf.WriteString(strconv.FormatFloat(c, 'f', -1, 64))
+		}
+	}
+	e.buf.WriteString("</coordinates>")
+	return nil
+}
+
+// text converts a property value into the text of a KML element.
+func text(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
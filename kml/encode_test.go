@@ -0,0 +1,75 @@
+package kml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	f := geojson.NewPointFeature([]float64{4.35, 50.85, 13})
+	f.ID = "bxl"
+	f.SetProperty("name", "Brussels & co")
+	f.SetProperty("population", 1208542.0)
+	f.SetProperty("capital", true)
+	f.SetProperty("tags", []interface{}{"a", "b"})
+	fc.AddFeature(f)
+
+	data, err := Marshal(fc, Options{DocumentName: "Cities"})
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	expected := xml.Header + `<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>Cities</name>` +
+		`<Placemark id="bxl"><name>Brussels &amp; co</name><ExtendedData>` +
+		`<Data name="capital"><value>true</value></Data>` +
+		`<Data name="population"><value>1208542</value></Data>` +
+		`<Data name="tags"><value>[&#34;a&#34;,&#34;b&#34;]</value></Data>` +
+		`</ExtendedData><Point><coordinates>4.35,50.85,13</coordinates></Point></Placemark></Document></kml>`
+	if string(data) != expected {
+		t.Errorf("incorrect document, expected %s, got %s", expected, data)
+	}
+}
+
+func TestMarshalGeometries(t *testing.T) {
+	cases := []struct {
+		geometry *geojson.Geometry
+		expected string
+	}{
+		{
+			geojson.NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+			`<LineString><coordinates>1,2 3,4</coordinates></LineString>`,
+		},
+		{
+			geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+			`<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 4,0 4,4 0,0</coordinates></LinearRing></outerBoundaryIs>` +
+				`<innerBoundaryIs><LinearRing><coordinates>1,1 2,1 2,2 1,1</coordinates></LinearRing></innerBoundaryIs></Polygon>`,
+		},
+		{
+			geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+			`<MultiGeometry><Point><coordinates>1,2</coordinates></Point><Point><coordinates>3,4</coordinates></Point></MultiGeometry>`,
+		},
+	}
+
+	for _, c := range cases {
+		fc := geojson.NewFeatureCollection()
+		fc.AddFeature(geojson.NewFeature(c.geometry))
+
+		data, err := Marshal(fc, Options{})
+		if err != nil {
+			t.Fatalf("should marshal %v, but got %v", c.geometry.Type, err)
+		}
+		if !strings.Contains(string(data), "<Placemark>"+c.expected+"</Placemark>") {
+			t.Errorf("incorrect %v, got %s", c.geometry.Type, data)
+		}
+	}
+
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{}))
+	if _, err := Marshal(fc, Options{}); err == nil {
+		t.Errorf("should reject a polygon without rings")
+	}
+}
@@ -0,0 +1,171 @@
+package geojson
+
+import (
+	"math"
+	"strconv"
+)
+
+// LintLatLonOrder flags the geometry if its positions look like latitude,
+// longitude pairs, by far the most common mistake of GeoJSON producers:
+// a second coordinate outside [-90, 90] while the first is inside, and
+// all the positions in range once swapped. The error points to the first
+// such position. The members of geometry collections are checked one by
+// one. Fix the flagged geometries with SwapLatLon.
+func (g *Geometry) LintLatLonOrder() []ValidationError {
+	var errs []ValidationError
+	lintLatLonOrder(g, "", &errs)
+	return errs
+}
+
+// LintLatLonOrder flags the geometry of the feature, see
+// Geometry.LintLatLonOrder.
+func (f *Feature) LintLatLonOrder() []ValidationError {
+	var errs []ValidationError
+	lintLatLonOrder(f.Geometry, "/geometry", &errs)
+	return errs
+}
+
+// LintLatLonOrder flags the geometries of the features of the collection,
+// see Geometry.LintLatLonOrder.
+func (fc *FeatureCollection) LintLatLonOrder() []ValidationError {
+	var errs []ValidationError
+	for i, f := range fc.Features {
+		if f != nil {
+			lintLatLonOrder(f.Geometry, "/features/"+strconv.Itoa(i)+"/geometry", &errs)
+		}
+	}
+	return errs
+}
+
+func lintLatLonOrder(g *Geometry, pointer string, errs *[]ValidationError) {
+	if g == nil {
+		return
+	}
+	if g.Type == GeometryCollection {
+		for i, m := range g.Geometries {
+			lintLatLonOrder(m, indexPointer(pointer+"/geometries", i), errs)
+		}
+		return
+	}
+
+	first := ""
+	swappable := true
+	forEachPositionPointer(g, pointer+"/coordinates", func(p []float64, pointer string) {
+		if len(p) < 2 {
+			return
+		}
+		if math.Abs(p[0]) > 90 || math.Abs(p[1]) > 180 {
+			swappable = false
+		}
+		if first == "" && math.Abs(p[1]) > 90 {
+			first = pointer
+		}
+	})
+	if first != "" && swappable {
+		*errs = append(*errs, ValidationError{Kind: ValidationLatLonOrder, Pointer: first})
+	}
+}
+
+// forEachPositionPointer calls fn with the positions of the geometry, but
+// not of the members of a geometry collection, and their pointers under
+// the pointer to the coordinates.
+func forEachPositionPointer(g *Geometry, pointer string, fn func(p []float64, pointer string)) {
+	path := func(path [][]float64, pointer string) {
+		for i, p := range path {
+			fn(p, indexPointer(pointer, i))
+		}
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) != 0 {
+			fn(g.Point, pointer)
+		}
+	case GeometryMultiPoint:
+		path(g.MultiPoint, pointer)
+	case GeometryLineString:
+		path(g.LineString, pointer)
+	case GeometryMultiLineString:
+		for i, l := range g.MultiLineString {
+			path(l, indexPointer(pointer, i))
+		}
+	case GeometryPolygon:
+		for i, r := range g.Polygon {
+			path(r, indexPointer(pointer, i))
+		}
+	case GeometryMultiPolygon:
+		for i, p := range g.MultiPolygon {
+			for j, r := range p {
+				path(r, indexPointer(indexPointer(pointer, i), j))
+			}
+		}
+	}
+}
+
+// SwapLatLon swaps in place the first two coordinates of the positions of
+// the geometry, and of its bounding box, fixing the geometries flagged by
+// LintLatLonOrder. The members of geometry collections are swapped too.
+func (g *Geometry) SwapLatLon() {
+	if g == nil {
+		return
+	}
+
+	swapBoundingBox(g.BoundingBox)
+	if g.Type == GeometryCollection {
+		for _, m := range g.Geometries {
+			m.SwapLatLon()
+		}
+		return
+	}
+
+	// a position may be shared, like the first and last ones of a ring
+	swapped := make(map[*float64]bool)
+	forEachPosition(g, func(p []float64) {
+		if len(p) >= 2 && !swapped[&p[0]] {
+			swapped[&p[0]] = true
+			p[0], p[1] = p[1], p[0]
+		}
+	})
+}
+
+// SwapLatLon swaps in place the first two coordinates of the positions of
+// the geometry of the feature, and of the bounding boxes of the feature and
+// of its geometry, see Geometry.SwapLatLon.
+func (f *Feature) SwapLatLon() {
+	if f == nil {
+		return
+	}
+	swapBoundingBox(f.BoundingBox)
+	f.Geometry.SwapLatLon()
+}
+
+// SwapLatLon swaps in place the first two coordinates of the positions of
+// the geometries of the features, and of all the bounding boxes of the
+// collection, see Geometry.SwapLatLon. Features or geometries shared
+// between features are swapped once.
+func (fc *FeatureCollection) SwapLatLon() {
+	swapBoundingBox(fc.BoundingBox)
+
+	swapped := make(map[interface{}]bool)
+	for _, f := range fc.Features {
+		if f == nil || swapped[f] {
+			continue
+		}
+		swapped[f] = true
+		swapBoundingBox(f.BoundingBox)
+		if f.Geometry != nil && !swapped[f.Geometry] {
+			swapped[f.Geometry] = true
+			f.Geometry.SwapLatLon()
+		}
+	}
+}
+
+// swapBoundingBox swaps in place the first two coordinates of the corners
+// of the bounding box.
+func swapBoundingBox(bb []float64) {
+	if len(bb) >= 4 && len(bb)%2 == 0 {
+		n := len(bb) / 2
+		bb[0], bb[1] = bb[1], bb[0]
+		bb[n], bb[n+1] = bb[n+1], bb[n]
+	}
+}
@@ -0,0 +1,103 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeometryLintLatLonOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		pointer  string
+	}{
+		{"lon lat", NewPointGeometry([]float64{4.35, 50.85}), ""},
+		{"lat lon in range", NewPointGeometry([]float64{50.85, 4.35}), ""},
+		{"swapped point", NewPointGeometry([]float64{35.7, 139.7}), "/coordinates"},
+		{"swapped line", NewLineStringGeometry([][]float64{{40.7, 74.0}, {40.8, 100.5}}), "/coordinates/1"},
+		{"out of range anyway", NewLineStringGeometry([][]float64{{40.7, 100.5}, {95, 10}}), ""},
+		{
+			"swapped polygon",
+			NewPolygonGeometry([][][]float64{{{-33.9, 151.2}, {-33.8, 151.2}, {-33.8, 151.3}, {-33.9, 151.2}}}),
+			"/coordinates/0/0",
+		},
+		{
+			"collection",
+			NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewPointGeometry([]float64{-33.9, 151.2})),
+			"/geometries/1/coordinates",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := c.geometry.LintLatLonOrder()
+			if c.pointer == "" {
+				if len(errs) != 0 {
+					t.Errorf("should not flag it, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Kind != ValidationLatLonOrder || errs[0].Pointer != c.pointer {
+				t.Errorf("incorrect lint, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestFeatureCollectionLintLatLonOrder(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{4.35, 50.85}))
+	fc.AddFeature(NewPointFeature([]float64{35.7, 139.7}))
+	fc.AddFeature(NewFeature(nil))
+
+	errs := fc.LintLatLonOrder()
+	if len(errs) != 1 || errs[0].Pointer != "/features/1/geometry/coordinates" {
+		t.Errorf("incorrect lint, got %v", errs)
+	}
+	if errs := fc.Features[1].LintLatLonOrder(); len(errs) != 1 || errs[0].Pointer != "/geometry/coordinates" {
+		t.Errorf("incorrect feature lint, got %v", errs)
+	}
+}
+
+func TestGeometrySwapLatLon(t *testing.T) {
+	first := []float64{-33.9, 151.2}
+	g := NewPolygonGeometry([][][]float64{{first, {-33.8, 151.2, 10}, {-33.8, 151.3}, first}})
+	g.BoundingBox = []float64{-33.9, 151.2, -33.8, 151.3}
+	c := NewCollectionGeometry(g)
+
+	c.SwapLatLon()
+	expected := [][]float64{{151.2, -33.9}, {151.2, -33.8, 10}, {151.3, -33.8}, {151.2, -33.9}}
+	if !reflect.DeepEqual(g.Polygon[0], expected) {
+		t.Errorf("incorrect positions, got %v", g.Polygon[0])
+	}
+	if !reflect.DeepEqual(g.BoundingBox, []float64{151.2, -33.9, 151.3, -33.8}) {
+		t.Errorf("incorrect bounding box, got %v", g.BoundingBox)
+	}
+	if errs := c.LintLatLonOrder(); len(errs) != 0 {
+		t.Errorf("should fix the geometry, got %v", errs)
+	}
+}
+
+func TestFeatureCollectionSwapLatLon(t *testing.T) {
+	f := NewPointFeature([]float64{48.85, 2.35, 35})
+	f.BoundingBox = []float64{48.85, 2.35, 35, 48.85, 2.35, 35}
+	shared := NewFeature(f.Geometry)
+	fc := NewFeatureCollection().AddFeature(f).AddFeature(shared).AddFeature(f).AddFeature(nil)
+	fc.BoundingBox = []float64{48.85, 2.35, 48.86, 2.36}
+
+	fc.SwapLatLon()
+	if !reflect.DeepEqual(f.Geometry.Point, []float64{2.35, 48.85, 35}) {
+		t.Errorf("should swap the positions once, got %v", f.Geometry.Point)
+	}
+	if !reflect.DeepEqual(f.BoundingBox, []float64{2.35, 48.85, 35, 2.35, 48.85, 35}) {
+		t.Errorf("incorrect feature bounding box, got %v", f.BoundingBox)
+	}
+	if !reflect.DeepEqual(fc.BoundingBox, []float64{2.35, 48.85, 2.36, 48.86}) {
+		t.Errorf("incorrect collection bounding box, got %v", fc.BoundingBox)
+	}
+
+	f.SwapLatLon()
+	if !reflect.DeepEqual(f.Geometry.Point, []float64{48.85, 2.35, 35}) || f.BoundingBox[0] != 48.85 {
+		t.Errorf("should swap the feature back, got %v and %v", f.Geometry.Point, f.BoundingBox)
+	}
+}
@@ -0,0 +1,201 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDecodeLimit is returned, wrapped with the exceeded limit, when a
+// document exceeds the DecodeLimits it is decoded with.
+var ErrDecodeLimit = errors.New("decode limit exceeded")
+
+// DecodeLimits bound the resources spent decoding a document, so a hostile
+// document, like a huge upload, can not exhaust the memory of a service.
+// JSON documents are checked before being decoded, by a scan that does not
+// build the document in memory. A value of 0 or less disables a limit.
+type DecodeLimits struct {
+	// MaxDocumentSize is the size of the document, in bytes.
+	MaxDocumentSize int
+
+	// MaxDepth is the nesting depth of the arrays and objects of the
+	// document, properties included. A feature collection of polygons
+	// is 7 deep.
+	MaxDepth int
+
+	// MaxVertices is the number of positions of all the geometries.
+	MaxVertices int
+
+	// MaxFeatures is the number of features of a collection.
+	MaxFeatures int
+}
+
+// DefaultDecodeLimits are the limits of the UnmarshalJSON and UnmarshalBSON
+// methods, and of the Unmarshal methods of DecodeOptions without Limits.
+// None are set by default. Services decoding untrusted documents should
+// set them once, before decoding.
+var DefaultDecodeLimits DecodeLimits
+
+// checkJSON returns an error wrapping ErrDecodeLimit if the JSON document
+// exceeds the limits. The document is not validated, encoding/json does
+// it when decoding.
+func (l DecodeLimits) checkJSON(data []byte) error {
+	if l.MaxDocumentSize > 0 && len(data) > l.MaxDocumentSize {
+		return l.exceeded("document size", l.MaxDocumentSize)
+	}
+	if l.MaxDepth <= 0 && l.MaxVertices <= 0 && l.MaxFeatures <= 0 {
+		return nil
+	}
+
+	// members tells, for each open array or object, the member it is the
+	// value of, or is in
+	var members []scanMember
+	var key []byte
+	member := scanOther
+	vertices, features := 0, 0
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			start := i + 1
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i < len(data) {
+				key = data[start:i]
+			}
+		case ':':
+			switch string(key) {
+			case "coordinates":
+				member = scanCoordinates
+			case "features":
+				member = scanFeatures
+			default:
+				member = scanOther
+			}
+		case ',':
+			member = scanOther
+		case '[', '{':
+			if len(members) > 0 && member == scanOther {
+				switch parent := members[len(members)-1]; {
+				case parent == scanFeatures && c == '{':
+					features++
+				case parent != scanFeatures:
+					member = parent
+				}
+			}
+			members = append(members, member)
+			member = scanOther
+
+			if l.MaxDepth > 0 && len(members) > l.MaxDepth {
+				return l.exceeded("depth", l.MaxDepth)
+			}
+			if l.MaxFeatures > 0 && features > l.MaxFeatures {
+				return l.exceeded("features", l.MaxFeatures)
+			}
+		case ']', '}':
+			if len(members) > 0 {
+				members = members[:len(members)-1]
+			}
+			member = scanOther
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			// a position is an array of coordinates starting with a number
+			if len(members) == 0 || members[len(members)-1] != scanCoordinates || !startsArray(data[:i]) {
+				continue
+			}
+			vertices++
+			if l.MaxVertices > 0 && vertices > l.MaxVertices {
+				return l.exceeded("vertices", l.MaxVertices)
+			}
+		}
+	}
+	return nil
+}
+
+// A scanMember is the kind of member an array or object is in, when
+// scanning a JSON document.
+type scanMember int
+
+const (
+	scanOther scanMember = iota
+	scanCoordinates
+	scanFeatures
+)
+
+// startsArray returns true if the data ends with the opening bracket of
+// an array, ignoring white space.
+func startsArray(data []byte) bool {
+	for i := len(data) - 1; i >= 0; i-- {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '[':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// checkBSON returns an error wrapping ErrDecodeLimit if the BSON document
+// exceeds the size limit. The other limits are checked on the decoded
+// document.
+func (l DecodeLimits) checkBSON(data []byte) error {
+	if l.MaxDocumentSize > 0 && len(data) > l.MaxDocumentSize {
+		return l.exceeded("document size", l.MaxDocumentSize)
+	}
+	return nil
+}
+
+// checkObject returns an error wrapping ErrDecodeLimit if the decoded
+// object, like a BSON document, exceeds the limits other than the size.
+func (l DecodeLimits) checkObject(object map[string]interface{}) error {
+	if l.MaxDepth <= 0 && l.MaxVertices <= 0 && l.MaxFeatures <= 0 {
+		return nil
+	}
+
+	if features, ok := object["features"].([]interface{}); ok && l.MaxFeatures > 0 && len(features) > l.MaxFeatures {
+		return l.exceeded("features", l.MaxFeatures)
+	}
+
+	vertices := 0
+	var walk func(v interface{}, depth int, coordinates bool) error
+	walk = func(v interface{}, depth int, coordinates bool) error {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			if l.MaxDepth > 0 && depth > l.MaxDepth {
+				return l.exceeded("depth", l.MaxDepth)
+			}
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, m := range v {
+				if err := walk(m, depth+1, key == "coordinates"); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			if len(v) > 0 && coordinates {
+				if _, ok := v[0].([]interface{}); !ok {
+					vertices++
+					if l.MaxVertices > 0 && vertices > l.MaxVertices {
+						return l.exceeded("vertices", l.MaxVertices)
+					}
+					return nil
+				}
+			}
+			for _, m := range v {
+				if err := walk(m, depth+1, coordinates); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(object, 1, false)
+}
+
+func (l DecodeLimits) exceeded(limit string, max int) error {
+	return fmt.Errorf("%w: %s over %d", ErrDecodeLimit, limit, max)
+}
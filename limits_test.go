@@ -0,0 +1,84 @@
+package geojson
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDecodeLimitsCheckJSON(t *testing.T) {
+	collection := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},"properties":{"tags":[[1,2],[3,4]]}},
+		{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[ 5, 5 ]}]},"properties":{"a":"[1,2]"}}
+	]}`
+
+	cases := []struct {
+		name     string
+		limits   DecodeLimits
+		exceeded bool
+	}{
+		{"none", DecodeLimits{}, false},
+		{"size", DecodeLimits{MaxDocumentSize: len(collection)}, false},
+		{"over size", DecodeLimits{MaxDocumentSize: 100}, true},
+		{"depth", DecodeLimits{MaxDepth: 7}, false},
+		{"over depth", DecodeLimits{MaxDepth: 6}, true},
+		{"vertices", DecodeLimits{MaxVertices: 5}, false},
+		{"over vertices", DecodeLimits{MaxVertices: 4}, true},
+		{"features", DecodeLimits{MaxFeatures: 2}, false},
+		{"over features", DecodeLimits{MaxFeatures: 1}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.limits.checkJSON([]byte(collection))
+			if c.exceeded != errors.Is(err, ErrDecodeLimit) {
+				t.Errorf("incorrect result, got %v", err)
+			}
+
+			var object map[string]interface{}
+			if err := bson.UnmarshalExtJSON([]byte(collection), false, &object); err != nil {
+				t.Fatalf("should convert to BSON, but got %v", err)
+			}
+			convertAToArray(&object)
+			if c.limits.MaxDocumentSize > 0 {
+				return
+			}
+			err = c.limits.checkObject(object)
+			if c.exceeded != errors.Is(err, ErrDecodeLimit) {
+				t.Errorf("incorrect result for the decoded object, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	data := []byte(`{"type":"MultiPoint","coordinates":[[0,0],[1,1],[2,2]]}`)
+
+	_, err := DecodeOptions{Limits: &DecodeLimits{MaxVertices: 2}}.UnmarshalGeometry(data)
+	if !errors.Is(err, ErrDecodeLimit) || err.Error() != "decode limit exceeded: vertices over 2" {
+		t.Errorf("should exceed the vertices, got %v", err)
+	}
+
+	defer func(l DecodeLimits) { DefaultDecodeLimits = l }(DefaultDecodeLimits)
+	DefaultDecodeLimits = DecodeLimits{MaxDepth: 2}
+	if _, err := UnmarshalGeometry(data); !errors.Is(err, ErrDecodeLimit) {
+		t.Errorf("should apply the default limits, got %v", err)
+	}
+	if _, err := (DecodeOptions{Limits: &DecodeLimits{}}).UnmarshalGeometry(data); err != nil {
+		t.Errorf("should override the default limits, got %v", err)
+	}
+
+	doc, err := bson.Marshal(NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if err := bson.Unmarshal(doc, &Feature{}); !errors.Is(err, ErrDecodeLimit) {
+		t.Errorf("should apply the default limits to BSON, got %v", err)
+	}
+
+	DefaultDecodeLimits = DecodeLimits{MaxDocumentSize: 10}
+	if err := (&FeatureCollection{}).UnmarshalJSON([]byte(`{"type":"FeatureCollection","features":[]}`)); !errors.Is(err, ErrDecodeLimit) {
+		t.Errorf("should limit the size, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Measures returns the measure of each position of the line string,
+// being the geodesic distance in meters along the line from its start.
+func Measures(line *Geometry) ([]float64, error) {
+	return MeasureOptions{}.Measures(line)
+}
+
+// Measures is Measures measuring with the Measurer of the options.
+func (o MeasureOptions) Measures(line *Geometry) ([]float64, error) {
+	if err := checkLinearGeometry(line); err != nil {
+		return nil, err
+	}
+
+	m := o.measurer()
+	measures := make([]float64, len(line.LineString))
+	for i := 1; i < len(line.LineString); i++ {
+		measures[i] = measures[i-1] + m.Distance(line.LineString[i-1], line.LineString[i])
+	}
+	return measures, nil
+}
+
+// LocateAlong snaps the point onto the closest segment of the line string.
+// It returns the measure of the snapped position, in meters from the start
+// of the line, and the snapped position itself.
+func LocateAlong(line *Geometry, point []float64) (float64, []float64, error) {
+	return MeasureOptions{}.LocateAlong(line, point)
+}
+
+// LocateAlong is LocateAlong measuring with the Measurer of the options.
+func (o MeasureOptions) LocateAlong(line *Geometry, point []float64) (float64, []float64, error) {
+	measures, err := o.Measures(line)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(point) < 2 {
+		return 0, nil, fmt.Errorf("point needs at least 2 coordinates, got %d", len(point))
+	}
+
+	path := line.LineString
+	if len(path) == 1 {
+		return 0, append([]float64(nil), path[0]...), nil
+	}
+
+	var (
+		measure float64
+		snapped []float64
+		min     = math.Inf(1)
+	)
+	for i := 1; i < len(path); i++ {
+		t := segmentFraction(point, path[i-1], path[i])
+		q := interpolatePosition(path[i-1], path[i], t)
+		if d := o.measurer().Distance(point, q); d < min {
+			min = d
+			measure = measures[i-1] + t*(measures[i]-measures[i-1])
+			snapped = q
+		}
+	}
+
+	return measure, snapped, nil
+}
+
+// PositionAt returns the position of the line string at the given measure,
+// in meters from its start. Measures beyond the line are clamped to its ends.
+func PositionAt(line *Geometry, measure float64) ([]float64, error) {
+	return MeasureOptions{}.PositionAt(line, measure)
+}
+
+// PositionAt is PositionAt measuring with the Measurer of the options.
+func (o MeasureOptions) PositionAt(line *Geometry, measure float64) ([]float64, error) {
+	measures, err := o.Measures(line)
+	if err != nil {
+		return nil, err
+	}
+
+	return positionAt(line.LineString, measures, measure), nil
+}
+
+// ExtractRange returns the part of the line string between the two measures,
+// in meters from its start. Measures beyond the line are clamped to its ends.
+// If from is larger than to, the extracted line runs in the opposite direction.
+func ExtractRange(line *Geometry, from, to float64) (*Geometry, error) {
+	return MeasureOptions{}.ExtractRange(line, from, to)
+}
+
+// ExtractRange is ExtractRange measuring with the Measurer of the options.
+func (o MeasureOptions) ExtractRange(line *Geometry, from, to float64) (*Geometry, error) {
+	measures, err := o.Measures(line)
+	if err != nil {
+		return nil, err
+	}
+
+	reverse := from > to
+	if reverse {
+		from, to = to, from
+	}
+
+	path := line.LineString
+	from = math.Max(from, 0)
+	to = math.Min(to, measures[len(measures)-1])
+	result := [][]float64{positionAt(path, measures, from)}
+	for i, m := range measures {
+		if m > from && m < to {
+			result = append(result, append([]float64(nil), path[i]...))
+		}
+	}
+	result = append(result, positionAt(path, measures, to))
+
+	if reverse {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+
+	return NewLineStringGeometry(result), nil
+}
+
+func positionAt(path [][]float64, measures []float64, measure float64) []float64 {
+	last := len(path) - 1
+	if measure <= 0 || last == 0 {
+		return append([]float64(nil), path[0]...)
+	}
+	if measure >= measures[last] {
+		return append([]float64(nil), path[last]...)
+	}
+
+	i := 1
+	for measures[i] < measure {
+		i++
+	}
+
+	t := 0.0
+	if length := measures[i] - measures[i-1]; length > 0 {
+		t = (measure - measures[i-1]) / length
+	}
+	return interpolatePosition(path[i-1], path[i], t)
+}
+
+// interpolatePosition returns the position at fraction t of the straight
+// segment a-b, with the coordinates both positions have.
+func interpolatePosition(a, b []float64, t float64) []float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	p := make([]float64, n)
+	for i := range p {
+		p[i] = a[i] + (b[i]-a[i])*t
+	}
+	return p
+}
+
+func checkLinearGeometry(line *Geometry) error {
+	if line == nil || line.Type != GeometryLineString {
+		return errors.New("linear referencing needs a line string")
+	}
+	if len(line.LineString) == 0 {
+		return errors.New("linear referencing needs a non empty line string")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasures(t *testing.T) {
+	line := NewLineStringGeometry([][]float64{{0, 0}, {1, 0}, {1, 1}})
+
+	m, err := Measures(line)
+	if err != nil {
+		t.Fatalf("should compute measures, but got %v", err)
+	}
+
+	degree := Geodesic{}.Distance([]float64{0, 0}, []float64{1, 0})
+	if len(m) != 3 || m[0] != 0 || math.Abs(m[1]-degree) > 1e-6 || math.Abs(m[2]-2*degree) > 1e-6 {
+		t.Errorf("incorrect measures, got %v", m)
+	}
+
+	if _, err := Measures(NewPointGeometry([]float64{0, 0})); err == nil {
+		t.Errorf("should fail on points")
+	}
+}
+
+func TestLocateAlong(t *testing.T) {
+	line := NewLineStringGeometry([][]float64{{0, 0}, {1, 0}, {1, 1}})
+	degree := Geodesic{}.Distance([]float64{0, 0}, []float64{1, 0})
+
+	m, p, err := LocateAlong(line, []float64{0.5, 0.01})
+	if err != nil {
+		t.Fatalf("should locate, but got %v", err)
+	}
+	if math.Abs(m-degree/2) > 1e-6 {
+		t.Errorf("incorrect measure, got %v", m)
+	}
+	if math.Abs(p[0]-0.5) > 1e-9 || p[1] != 0 {
+		t.Errorf("should snap onto the line, got %v", p)
+	}
+
+	m, p, _ = LocateAlong(line, []float64{1.2, 0.5})
+	if math.Abs(m-1.5*degree) > 1 {
+		t.Errorf("incorrect measure on second segment, got %v", m)
+	}
+	if p[0] != 1 {
+		t.Errorf("should snap onto the second segment, got %v", p)
+	}
+
+	m, _, _ = LocateAlong(line, []float64{-1, -1})
+	if m != 0 {
+		t.Errorf("should clamp before the start, got %v", m)
+	}
+}
+
+func TestExtractRange(t *testing.T) {
+	line := NewLineStringGeometry([][]float64{{0, 0, 10}, {1, 0, 20}, {1, 1, 30}})
+	degree := Geodesic{}.Distance([]float64{0, 0}, []float64{1, 0})
+
+	g, err := ExtractRange(line, degree/2, 1.5*degree)
+	if err != nil {
+		t.Fatalf("should extract, but got %v", err)
+	}
+	expected := [][]float64{{0.5, 0, 15}, {1, 0, 20}, {1, 0.5, 25}}
+	if len(g.LineString) != 3 {
+		t.Fatalf("incorrect range, got %v", g.LineString)
+	}
+	for i, p := range g.LineString {
+		for j := range p {
+			if math.Abs(p[j]-expected[i][j]) > 1e-6 {
+				t.Errorf("incorrect range, got %v expected %v", g.LineString, expected)
+			}
+		}
+	}
+
+	g, _ = ExtractRange(line, 10*degree, -1)
+	if len(g.LineString) != 3 || g.LineString[0][1] != 1 || g.LineString[2][0] != 0 {
+		t.Errorf("should clamp and reverse, got %v", g.LineString)
+	}
+
+	p, _ := PositionAt(line, degree)
+	if p[0] != 1 || p[1] != 0 {
+		t.Errorf("should be on the vertex, got %v", p)
+	}
+}
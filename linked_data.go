@@ -0,0 +1,69 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GeoJSONLDContext is the JSON-LD context of the GeoJSON vocabulary.
+const GeoJSONLDContext = "https://geojson.org/geojson-ld/geojson-context.jsonld"
+
+// A LinkedDataContext is the JSON-LD "@context" of encoded features, so
+// linked data consumers can read them as RDF.
+type LinkedDataContext struct {
+	// Base is the context extended by the terms, GeoJSONLDContext if empty.
+	Base string
+
+	// Terms maps property names to the IRIs they stand for,
+	// like "name" to "http://schema.org/name".
+	Terms map[string]string
+
+	// Types maps property names to the IRI of the datatype of their values,
+	// like "http://www.w3.org/2001/XMLSchema#date". Typed properties must
+	// have a term.
+	Types map[string]string
+}
+
+// MarshalJSON converts the context into the value of the "@context" member,
+// the base alone or followed by the term definitions.
+func (c LinkedDataContext) MarshalJSON() ([]byte, error) {
+	base := c.Base
+	if base == "" {
+		base = GeoJSONLDContext
+	}
+	if len(c.Terms) == 0 && len(c.Types) == 0 {
+		return json.Marshal(base)
+	}
+
+	terms := make(map[string]interface{}, len(c.Terms))
+	for name, iri := range c.Terms {
+		if t, ok := c.Types[name]; ok {
+			terms[name] = map[string]string{"@id": iri, "@type": t}
+		} else {
+			terms[name] = iri
+		}
+	}
+	for name := range c.Types {
+		if _, ok := c.Terms[name]; !ok {
+			return nil, fmt.Errorf("property %s has a type but no IRI", name)
+		}
+	}
+
+	return json.Marshal([]interface{}{base, terms})
+}
+
+// withContext returns the foreign members with the "@context" member set,
+// leaving the given ones untouched.
+func withContext(members map[string]json.RawMessage, c *LinkedDataContext) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]json.RawMessage, len(members)+1)
+	for k, v := range members {
+		result[k] = v
+	}
+	result["@context"] = data
+	return result, nil
+}
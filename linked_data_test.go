@@ -0,0 +1,85 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLinkedDataContext(t *testing.T) {
+	cases := []struct {
+		name     string
+		context  LinkedDataContext
+		expected string
+	}{
+		{"default", LinkedDataContext{}, `"https://geojson.org/geojson-ld/geojson-context.jsonld"`},
+		{"base", LinkedDataContext{Base: "https://example.com/context.jsonld"}, `"https://example.com/context.jsonld"`},
+		{
+			"terms",
+			LinkedDataContext{
+				Terms: map[string]string{"name": "http://schema.org/name", "opened": "http://schema.org/foundingDate"},
+				Types: map[string]string{"opened": "http://www.w3.org/2001/XMLSchema#date"},
+			},
+			`["https://geojson.org/geojson-ld/geojson-context.jsonld",{"name":"http://schema.org/name","opened":{"@id":"http://schema.org/foundingDate","@type":"http://www.w3.org/2001/XMLSchema#date"}}]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := json.Marshal(c.context)
+			if err != nil {
+				t.Fatalf("should marshal, but got %v", err)
+			}
+			if string(data) != c.expected {
+				t.Errorf("incorrect context, expected %s, got %s", c.expected, data)
+			}
+		})
+	}
+
+	_, err := json.Marshal(LinkedDataContext{Types: map[string]string{"opened": "http://www.w3.org/2001/XMLSchema#date"}})
+	if err == nil {
+		t.Errorf("should reject a type without a term")
+	}
+}
+
+func TestMarshalOptionsContext(t *testing.T) {
+	opts := MarshalOptions{Context: &LinkedDataContext{Terms: map[string]string{"name": "http://schema.org/name"}}}
+	context := `"@context":["https://geojson.org/geojson-ld/geojson-context.jsonld",{"name":"http://schema.org/name"}]`
+
+	f := NewPointFeature([]float64{1, 2})
+	f.SetProperty("name", "a")
+	f.ForeignMembers = map[string]json.RawMessage{"@context": json.RawMessage(`"old"`), "title": json.RawMessage(`"t"`)}
+
+	data, err := opts.MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"},` + context + `,"title":"t"}`
+	if string(data) != expected {
+		t.Errorf("incorrect feature, expected %s, got %s", expected, data)
+	}
+	if string(f.ForeignMembers["@context"]) != `"old"` {
+		t.Errorf("should not modify the feature")
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+	fc.AddFeature(NewPointFeature([]float64{3, 4}))
+
+	for _, workers := range []int{0, 2} {
+		opts.Workers = workers
+		data, err := opts.MarshalFeatureCollection(fc)
+		if err != nil {
+			t.Fatalf("should marshal, but got %v", err)
+		}
+
+		expected := `{"type":"FeatureCollection","features":[` +
+			`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null},` +
+			`{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":null}],` + context + `}`
+		if string(data) != expected {
+			t.Errorf("incorrect collection with %d workers, expected %s, got %s", workers, expected, data)
+		}
+	}
+	if fc.ForeignMembers != nil {
+		t.Errorf("should not modify the collection")
+	}
+}
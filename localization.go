@@ -0,0 +1,102 @@
+package geojson
+
+import (
+	"strings"
+)
+
+// A Localization keeps a single language of the localized properties of
+// encoded features, following the "key:language" convention of
+// OpenStreetMap, like "name:de" for the German name.
+type Localization struct {
+	// Languages are the language tags wanted, in order of preference.
+	Languages []string
+
+	// Keys are the localized properties, "name" if empty.
+	Keys []string
+}
+
+// LocalizedProperty returns the string property localized in the language,
+// the "key:language" property, or else in the first fallback language
+// having it. A language tag with subtags, like "de-CH", falls back to its
+// primary language, "de". The unlocalized property is returned when none
+// of the languages have it, and false if it does not exist either.
+func (f *Feature) LocalizedProperty(key, language string, fallback ...string) (string, bool) {
+	for _, lang := range append([]string{language}, fallback...) {
+		for lang != "" {
+			if s, ok := f.Properties[key+":"+lang].(string); ok {
+				return s, true
+			}
+
+			i := strings.LastIndexByte(lang, '-')
+			if i < 0 {
+				break
+			}
+			lang = lang[:i]
+		}
+	}
+
+	s, ok := f.Properties[key].(string)
+	return s, ok
+}
+
+// localize returns the feature, or a copy of it sharing everything but the
+// properties when the localized properties are replaced by their value in
+// the preferred language, set as the unlocalized property.
+func (l *Localization) localize(f *Feature) *Feature {
+	if l == nil || f == nil || len(f.Properties) == 0 {
+		return f
+	}
+
+	keys := l.Keys
+	if len(keys) == 0 {
+		keys = []string{"name"}
+	}
+	var language string
+	var fallback []string
+	if len(l.Languages) > 0 {
+		language, fallback = l.Languages[0], l.Languages[1:]
+	}
+
+	c := *f
+	c.Properties = make(map[string]interface{}, len(f.Properties))
+	for k, v := range f.Properties {
+		if !isLocalizedKey(k, keys) {
+			c.Properties[k] = v
+		}
+	}
+	for _, key := range keys {
+		if s, ok := f.LocalizedProperty(key, language, fallback...); ok {
+			c.Properties[key] = s
+		}
+	}
+	return &c
+}
+
+// isLocalizedKey returns true if the key is one of the keys followed by a
+// language tag.
+func isLocalizedKey(k string, keys []string) bool {
+	for _, key := range keys {
+		if len(k) > len(key)+1 && k[len(key)] == ':' && strings.HasPrefix(k, key) && isLanguageTag(k[len(key)+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLanguageTag returns true if the tag looks like a BCP 47 language tag:
+// a primary language of 2 or 3 letters followed by alphanumeric subtags,
+// like "de", "zh-Hans" or "es-419".
+func isLanguageTag(tag string) bool {
+	for i, subtag := range strings.Split(tag, "-") {
+		if i == 0 && (len(subtag) < 2 || len(subtag) > 3) || len(subtag) < 1 || len(subtag) > 8 {
+			return false
+		}
+		for _, r := range subtag {
+			letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+			if !letter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestLocalizedProperty(t *testing.T) {
+	f := NewPointFeature([]float64{4.35, 50.85})
+	f.SetProperty("name", "Bruxelles - Brussel")
+	f.SetProperty("name:de", "Brüssel")
+	f.SetProperty("name:nl", "Brussel")
+	f.SetProperty("name:zh-Hant", "布魯塞爾")
+	f.SetProperty("name:ja", 42)
+
+	cases := []struct {
+		language string
+		fallback []string
+		expected string
+	}{
+		{"de", nil, "Brüssel"},
+		{"de-CH", nil, "Brüssel"},
+		{"fr", []string{"it", "nl"}, "Brussel"},
+		{"zh-Hant-TW", nil, "布魯塞爾"},
+		{"zh", nil, "Bruxelles - Brussel"},
+		{"ja", nil, "Bruxelles - Brussel"},
+		{"", nil, "Bruxelles - Brussel"},
+	}
+
+	for _, tc := range cases {
+		s, ok := f.LocalizedProperty("name", tc.language, tc.fallback...)
+		if !ok || s != tc.expected {
+			t.Errorf("incorrect name in %s %v, expected %s, got %s", tc.language, tc.fallback, tc.expected, s)
+		}
+	}
+
+	if s, ok := f.LocalizedProperty("alt_name", "de"); ok {
+		t.Errorf("should not find a missing property, got %s", s)
+	}
+}
+
+func TestMarshalOptionsLocalization(t *testing.T) {
+	f := NewPointFeature([]float64{4.35, 50.85})
+	f.SetProperty("name", "Bruxelles - Brussel")
+	f.SetProperty("name:de", "Brüssel")
+	f.SetProperty("name:es-419", "Bruselas")
+	f.SetProperty("name:etymology:wikidata", "Q1")
+	f.SetProperty("official_name:de", "Region Brüssel-Hauptstadt")
+	f.SetProperty("building:use", "office")
+
+	data, err := MarshalOptions{Localization: &Localization{Languages: []string{"de"}}}.MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"Feature","geometry":{"type":"Point","coordinates":[4.35,50.85]},"properties":` +
+		`{"building:use":"office","name":"Brüssel","name:etymology:wikidata":"Q1","official_name:de":"Region Brüssel-Hauptstadt"}}`
+	if string(data) != expected {
+		t.Errorf("incorrect localized feature, got %s", data)
+	}
+	if len(f.Properties) != 6 || f.Properties["name"] != "Bruxelles - Brussel" {
+		t.Errorf("should not change the feature, got %v", f.Properties)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	fc.AddFeature(NewFeature(nil))
+	o := MarshalOptions{
+		Localization: &Localization{Languages: []string{"fr"}, Keys: []string{"name", "official_name"}},
+		Workers:      2,
+	}
+	data, err = o.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected = `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[4.35,50.85]},"properties":` +
+		`{"building:use":"office","name":"Bruxelles - Brussel","name:etymology:wikidata":"Q1"}},` +
+		`{"type":"Feature","geometry":null,"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect localized collection, got %s", data)
+	}
+}
@@ -0,0 +1,186 @@
+package geojson
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A Manifest describes a dataset published in chunks, like the partitions
+// of PartitionByTile, so consumers can verify the integrity of the chunks
+// they fetch, and only fetch the chunks that changed since the previous
+// publication, see Changes.
+type Manifest struct {
+	// Chunks are sorted by key.
+	Chunks []ManifestChunk `json:"chunks"`
+
+	// BoundingBox is the two dimensional bounding box of all the chunks.
+	BoundingBox []float64 `json:"bbox,omitempty"`
+
+	// Features is the number of features of all the chunks, counting
+	// features put in several chunks, like clipped ones, in each.
+	Features int `json:"features"`
+	Size     int `json:"size"`
+
+	// Signature is the base64 Ed25519 signature of the manifest, see Sign.
+	Signature string `json:"signature,omitempty"`
+}
+
+// A ManifestChunk describes a published chunk.
+type ManifestChunk struct {
+	Key string `json:"key"`
+
+	// SHA256 is the hexadecimal SHA-256 digest of the published data.
+	SHA256      string    `json:"sha256"`
+	Size        int       `json:"size"`
+	Features    int       `json:"features"`
+	BoundingBox []float64 `json:"bbox,omitempty"`
+}
+
+// NewManifest encodes the chunks with the options, and returns the encoded
+// chunks, to publish, with their manifest.
+func NewManifest(chunks map[string]*FeatureCollection, o MarshalOptions) (*Manifest, map[string][]byte, error) {
+	m := &Manifest{Chunks: []ManifestChunk{}}
+	encoded := make(map[string][]byte, len(chunks))
+	for key, fc := range chunks {
+		data, err := o.MarshalFeatureCollection(fc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("chunk %s: %v", key, err)
+		}
+		encoded[key] = data
+		m.Add(key, fc, data)
+	}
+	return m, encoded, nil
+}
+
+// Add adds the chunk with the key, replacing any chunk with the same key,
+// for its data, the collection encoded as published. The signature, if
+// any, is removed.
+func (m *Manifest) Add(key string, fc *FeatureCollection, data []byte) {
+	digest := sha256.Sum256(data)
+	chunk := ManifestChunk{
+		Key:      key,
+		SHA256:   hex.EncodeToString(digest[:]),
+		Size:     len(data),
+		Features: len(fc.Features),
+	}
+	for _, f := range fc.Features {
+		chunk.BoundingBox = unionBoundingBox(chunk.BoundingBox, featureBoundingBox(f))
+	}
+
+	i := sort.Search(len(m.Chunks), func(i int) bool { return m.Chunks[i].Key >= key })
+	if i < len(m.Chunks) && m.Chunks[i].Key == key {
+		m.Chunks[i] = chunk
+	} else {
+		m.Chunks = append(m.Chunks, ManifestChunk{})
+		copy(m.Chunks[i+1:], m.Chunks[i:])
+		m.Chunks[i] = chunk
+	}
+
+	m.Signature = ""
+	m.Features, m.Size, m.BoundingBox = 0, 0, nil
+	for _, c := range m.Chunks {
+		m.Features += c.Features
+		m.Size += c.Size
+		m.BoundingBox = unionBoundingBox(m.BoundingBox, c.BoundingBox)
+	}
+}
+
+// Chunk returns the chunk with the key, false if there is none.
+func (m *Manifest) Chunk(key string) (ManifestChunk, bool) {
+	i := sort.Search(len(m.Chunks), func(i int) bool { return m.Chunks[i].Key >= key })
+	if i < len(m.Chunks) && m.Chunks[i].Key == key {
+		return m.Chunks[i], true
+	}
+	return ManifestChunk{}, false
+}
+
+// Verify returns an error if the data is not the one of the chunk with the
+// key, like a truncated download.
+func (m *Manifest) Verify(key string, data []byte) error {
+	c, ok := m.Chunk(key)
+	if !ok {
+		return fmt.Errorf("no chunk %s in the manifest", key)
+	}
+
+	digest := sha256.Sum256(data)
+	if c.Size != len(data) || c.SHA256 != hex.EncodeToString(digest[:]) {
+		return fmt.Errorf("chunk %s does not match its checksum", key)
+	}
+	return nil
+}
+
+// Changes returns the keys of the chunks added or changed since the
+// previous manifest, which are the ones to fetch again, and of the chunks
+// removed, in order. Every chunk is added if there is no previous manifest.
+func (m *Manifest) Changes(previous *Manifest) (changed []string, removed []string) {
+	if previous == nil {
+		previous = &Manifest{}
+	}
+
+	for _, c := range m.Chunks {
+		if p, ok := previous.Chunk(c.Key); !ok || p.SHA256 != c.SHA256 {
+			changed = append(changed, c.Key)
+		}
+	}
+	for _, p := range previous.Chunks {
+		if _, ok := m.Chunk(p.Key); !ok {
+			removed = append(removed, p.Key)
+		}
+	}
+	return changed, removed
+}
+
+// Sign signs the manifest with the private key, setting its Signature.
+func (m *Manifest) Sign(key ed25519.PrivateKey) error {
+	data, err := m.signed()
+	if err != nil {
+		return err
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return nil
+}
+
+// VerifySignature returns an error if the manifest is not signed by the
+// private key of the public key, or was changed since.
+func (m *Manifest) VerifySignature(key ed25519.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil || m.Signature == "" {
+		return errors.New("manifest not signed")
+	}
+
+	data, err := m.signed()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return errors.New("invalid manifest signature")
+	}
+	return nil
+}
+
+// signed returns the signed data of the manifest: its JSON encoding
+// without signature.
+func (m *Manifest) signed() ([]byte, error) {
+	c := *m
+	c.Signature = ""
+	return json.Marshal(c)
+}
+
+// unionBoundingBox returns the two dimensional bounding box covering both
+// bounding boxes, either of which may be nil.
+func unionBoundingBox(a, b []float64) []float64 {
+	if a == nil {
+		return append([]float64(nil), b...)
+	}
+	if b == nil {
+		return a
+	}
+	return []float64{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Max(a[2], b[2]), math.Max(a[3], b[3])}
+}
@@ -0,0 +1,115 @@
+package geojson
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewManifest(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 1}))
+	fc.AddFeature(NewPointFeature([]float64{-100, -40}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{2, 2}, {3, 3}}))
+	chunks, err := PartitionByTile(fc, 1, PartitionByCentroid)
+	if err != nil {
+		t.Fatalf("should partition, but got %v", err)
+	}
+
+	m, encoded, err := NewManifest(chunks, MarshalOptions{})
+	if err != nil {
+		t.Fatalf("should build the manifest, but got %v", err)
+	}
+	if len(m.Chunks) != 2 || m.Chunks[0].Key != "1/0/1" || m.Chunks[1].Key != "1/1/0" {
+		t.Fatalf("incorrect chunks, got %+v", m.Chunks)
+	}
+	if m.Features != 3 || m.Size != len(encoded["1/0/1"])+len(encoded["1/1/0"]) {
+		t.Errorf("incorrect counts, got %d features of %d bytes", m.Features, m.Size)
+	}
+	if !reflect.DeepEqual(m.BoundingBox, []float64{-100, -40, 3, 3}) {
+		t.Errorf("incorrect bounding box, got %v", m.BoundingBox)
+	}
+	if !reflect.DeepEqual(m.Chunks[1].BoundingBox, []float64{1, 1, 3, 3}) {
+		t.Errorf("incorrect chunk bounding box, got %v", m.Chunks[1].BoundingBox)
+	}
+
+	for key, data := range encoded {
+		if err := m.Verify(key, data); err != nil {
+			t.Errorf("should verify chunk %s, but got %v", key, err)
+		}
+	}
+	if m.Verify("1/1/0", encoded["1/1/0"][1:]) == nil {
+		t.Errorf("should not verify altered data")
+	}
+	if m.Verify("1/1/1", nil) == nil {
+		t.Errorf("should not verify a missing chunk")
+	}
+}
+
+func TestManifestChanges(t *testing.T) {
+	chunk := func(x float64) *FeatureCollection {
+		fc := NewFeatureCollection()
+		fc.AddFeature(NewPointFeature([]float64{x, 0}))
+		return fc
+	}
+
+	previous, _, err := NewManifest(map[string]*FeatureCollection{"a": chunk(1), "b": chunk(2), "c": chunk(3)}, MarshalOptions{})
+	if err != nil {
+		t.Fatalf("should build the manifest, but got %v", err)
+	}
+	m, _, err := NewManifest(map[string]*FeatureCollection{"a": chunk(1), "b": chunk(5), "d": chunk(4)}, MarshalOptions{})
+	if err != nil {
+		t.Fatalf("should build the manifest, but got %v", err)
+	}
+
+	changed, removed := m.Changes(previous)
+	if !reflect.DeepEqual(changed, []string{"b", "d"}) || !reflect.DeepEqual(removed, []string{"c"}) {
+		t.Errorf("incorrect changes, got %v and %v", changed, removed)
+	}
+	if changed, _ := m.Changes(nil); len(changed) != 3 {
+		t.Errorf("should change every chunk without previous manifest, got %v", changed)
+	}
+
+	m.Add("b", chunk(2), []byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[2,0]},"properties":{}}]}`))
+	if len(m.Chunks) != 3 || m.Features != 3 {
+		t.Errorf("should replace the chunk, got %+v", m.Chunks)
+	}
+}
+
+func TestManifestSign(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("should generate a key, but got %v", err)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 1}))
+	m, _, err := NewManifest(map[string]*FeatureCollection{"0/0/0": fc}, MarshalOptions{})
+	if err != nil {
+		t.Fatalf("should build the manifest, but got %v", err)
+	}
+	if m.VerifySignature(public) == nil {
+		t.Errorf("should not verify an unsigned manifest")
+	}
+	if err := m.Sign(private); err != nil {
+		t.Fatalf("should sign, but got %v", err)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	published := &Manifest{}
+	if err := json.Unmarshal(data, published); err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if err := published.VerifySignature(public); err != nil {
+		t.Errorf("should verify the signature, but got %v", err)
+	}
+
+	published.Features++
+	if published.VerifySignature(public) == nil {
+		t.Errorf("should not verify a changed manifest")
+	}
+}
@@ -0,0 +1,403 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+)
+
+// MarshalOptions configures the encoding done by its Marshal methods.
+// The zero value produces the same output as the MarshalJSON methods.
+type MarshalOptions struct {
+	// Workers is the number of goroutines encoding the features of a
+	// collection. Each feature is encoded into its own buffer and the
+	// buffers are stitched together in order. Values below 2 encode
+	// sequentially.
+	Workers int
+
+	// Context adds a JSON-LD "@context" member to the top level object,
+	// replacing any foreign member of that name.
+	Context *LinkedDataContext
+
+	// BoundingBoxes sets which bounding boxes are written.
+	BoundingBoxes BoundingBoxMode
+
+	// PropertyOrder lists the properties written first, in that order, like
+	// the order of a schema. The other properties follow sorted by key,
+	// the order encoding/json always writes map keys in, so the output is
+	// stable with or without it.
+	PropertyOrder []string
+
+	// Localization writes the localized properties of features in a single
+	// language, dropping the other languages.
+	Localization *Localization
+
+	// DropNonFinite drops the positions with a NaN or infinite coordinate,
+	// see Geometry.DropNonFinite, instead of failing with a NonFiniteError.
+	// The marshaled objects are left as they are.
+	DropNonFinite bool
+
+	// Provenance writes the provenance of geometries that have one as their
+	// "provenance" foreign member, an array of transformations, see
+	// Geometry.Provenance. The marshaled objects are left as they are.
+	Provenance bool
+
+	// Rewind writes the rings of polygons following the right-hand rule of
+	// RFC 7946, see Geometry.Rewind. The marshaled objects are left as
+	// they are.
+	Rewind bool
+
+	// PlainDecimals writes the coordinates and the bounding boxes in plain
+	// decimal notation, like 0.0000001 rather than 1e-7, for the tools
+	// that do not read exponents. The shortest decimal reading back as the
+	// same float64 is written, without trailing zeros. The numbers of the
+	// properties and of the foreign members are written as they are.
+	PlainDecimals bool
+
+	// Precision, when above 0, rounds the coordinates and the bounding
+	// boxes written to that number of decimals, in plain decimal notation
+	// without trailing zeros, like 6 for about 10 cm, shrinking the output
+	// for web delivery. The numbers of the properties and of the foreign
+	// members are written as they are, and so are the marshaled objects.
+	Precision int
+
+	// DropMeasures writes the positions without their measures, the fourth
+	// coordinates, for strict RFC 7946 output, see Geometry.DropM. The
+	// marshaled objects are left as they are.
+	DropMeasures bool
+
+	// Canonical writes the same bytes for semantically identical objects,
+	// for hashes, ETags and diffs: the members of GeoJSON objects in their
+	// fixed order, the properties and the other members sorted by key,
+	// ignoring PropertyOrder, and the numbers that are not integers as the
+	// shortest plain decimal reading back as the same float64, negative
+	// zero as 0. The marshaled objects are left as they are.
+	Canonical bool
+
+	// Spec is the version of the GeoJSON specification written. With
+	// SpecRFC7946, the crs members are left out. The marshaled objects are
+	// left as they are.
+	Spec Spec
+}
+
+// A BoundingBoxMode sets which bounding boxes are written.
+type BoundingBoxMode int
+
+// The bounding box modes.
+const (
+	// BoundingBoxesAsIs writes the bounding boxes the objects have.
+	BoundingBoxesAsIs BoundingBoxMode = iota
+
+	// BoundingBoxesAll computes and writes the two dimensional bounding box
+	// of every object: the collection, its features, their geometries and
+	// the members of geometry collections. Objects without positions get
+	// none.
+	BoundingBoxesAll
+
+	// BoundingBoxesNone strips all the bounding boxes.
+	BoundingBoxesNone
+
+	// BoundingBoxesMissing computes and writes the bounding box of the
+	// collection and of its features when they have none, like
+	// Feature.ComputeBoundingBox, which may cross the antimeridian. The
+	// bounding boxes they have and those of the geometries are written as
+	// they are.
+	BoundingBoxesMissing
+)
+
+// MarshalGeometry converts the geometry object into the proper JSON,
+// according to the options.
+func (o MarshalOptions) MarshalGeometry(g *Geometry) ([]byte, error) {
+	return o.canonicalNumbers(o.marshalGeometry(g))
+}
+
+func (o MarshalOptions) marshalGeometry(g *Geometry) ([]byte, error) {
+	g, err := o.withProvenance(o.rewound(o.boundingBoxes(o.finite(o.withoutCRS(o.canonical(o.withoutMeasures(g)))))))
+	if err != nil {
+		return nil, err
+	}
+	return g.marshalJSON(o.numberFormat())
+}
+
+// MarshalFeature converts the feature object into the proper JSON,
+// according to the options.
+func (o MarshalOptions) MarshalFeature(f *Feature) ([]byte, error) {
+	return o.canonicalNumbers(o.marshalFeature(f))
+}
+
+func (o MarshalOptions) marshalFeature(f *Feature) ([]byte, error) {
+	f, err := o.featureWithProvenance(o.rewoundFeature(o.featureBoundingBoxes(o.finiteFeature(o.featureWithoutCRS(o.canonicalFeature(o.featureWithoutMeasures(f)))))))
+	if err != nil {
+		return nil, err
+	}
+	f = o.Localization.localize(f)
+	if o.Context == nil {
+		return f.marshalJSON(o.propertyOrder(), o.numberFormat())
+	}
+
+	c := *f
+	if c.ForeignMembers, err = withContext(f.ForeignMembers, o.Context); err != nil {
+		return nil, err
+	}
+	return c.marshalJSON(o.propertyOrder(), o.numberFormat())
+}
+
+// MarshalFeatureCollection converts the feature collection object into
+// the proper JSON, according to the options.
+func (o MarshalOptions) MarshalFeatureCollection(fc *FeatureCollection) ([]byte, error) {
+	return o.canonicalNumbers(o.marshalFeatureCollection(fc))
+}
+
+func (o MarshalOptions) marshalFeatureCollection(fc *FeatureCollection) ([]byte, error) {
+	fc = o.collectionBoundingBoxes(o.finiteCollection(fc))
+	if o.Localization != nil || o.Rewind || o.Provenance || o.Spec == SpecRFC7946 || o.Canonical || o.DropMeasures {
+		c := *fc
+		if o.Spec == SpecRFC7946 {
+			c.CRS = nil
+		}
+		c.ForeignMembers = o.canonicalMembers(fc.ForeignMembers)
+		c.Features = make([]*Feature, len(fc.Features))
+		for i, f := range fc.Features {
+			f, err := o.featureWithProvenance(o.rewoundFeature(o.featureWithoutCRS(o.canonicalFeature(o.featureWithoutMeasures(f)))))
+			if err != nil {
+				return nil, err
+			}
+			c.Features[i] = o.Localization.localize(f)
+		}
+		fc = &c
+	}
+	if o.Context != nil {
+		c := *fc
+		var err error
+		if c.ForeignMembers, err = withContext(fc.ForeignMembers, o.Context); err != nil {
+			return nil, err
+		}
+		fc = &c
+	}
+
+	if (o.Workers < 2 || len(fc.Features) < 2) && o.propertyOrder() == nil && o.numberFormat() == nil {
+		return fc.MarshalJSON()
+	}
+
+	features, err := o.marshalFeatures(fc.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 64
+	for _, f := range features {
+		size += len(f) + 1
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	buf.WriteString(`{"type":"FeatureCollection"`)
+
+	if fc.BoundingBox != nil && len(fc.BoundingBox) != 0 {
+		data, err := json.Marshal(formatted(fc.BoundingBox, o.numberFormat()))
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`,"bbox":`)
+		buf.Write(data)
+	}
+
+	buf.WriteString(`,"features":[`)
+	for i, f := range features {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(f)
+	}
+	buf.WriteByte(']')
+
+	if fc.CRS != nil && len(fc.CRS) != 0 {
+		data, err := json.Marshal(fc.CRS)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`,"crs":`)
+		buf.Write(data)
+	}
+
+	buf.WriteByte('}')
+	return appendForeignMembers(buf.Bytes(), fc.ForeignMembers, featureCollectionMembers)
+}
+
+// marshalFeatures encodes the features on o.Workers goroutines, at least
+// one, returning the encoded features in their original order.
+func (o MarshalOptions) marshalFeatures(features []*Feature) ([][]byte, error) {
+	result := make([][]byte, len(features))
+	errs := make([]error, len(features))
+
+	workers := o.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	indexes := make(chan int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if features[i] == nil {
+					result[i] = []byte("null")
+					continue
+				}
+				result[i], errs[i] = features[i].marshalJSON(o.propertyOrder(), o.numberFormat())
+			}
+		}()
+	}
+
+	for i := range features {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// collectionBoundingBoxes returns the collection, or a copy of it sharing
+// everything but the bounding boxes when they are computed or stripped.
+func (o MarshalOptions) collectionBoundingBoxes(fc *FeatureCollection) *FeatureCollection {
+	if o.BoundingBoxes == BoundingBoxesAsIs {
+		return fc
+	}
+
+	c := *fc
+	if o.BoundingBoxes == BoundingBoxesMissing {
+		c.Features = make([]*Feature, len(fc.Features))
+		for i, f := range fc.Features {
+			c.Features[i] = o.featureBoundingBoxes(f)
+		}
+		if c.BoundingBox == nil {
+			c.ComputeBoundingBox()
+		}
+		return &c
+	}
+
+	c.BoundingBox = nil
+	c.Features = make([]*Feature, len(fc.Features))
+	for i, f := range fc.Features {
+		c.Features[i] = o.featureBoundingBoxes(f)
+		if o.BoundingBoxes != BoundingBoxesAll || c.Features[i] == nil {
+			continue
+		}
+
+		bb := c.Features[i].BoundingBox
+		if bb == nil {
+			continue
+		}
+		if c.BoundingBox == nil {
+			c.BoundingBox = append([]float64(nil), bb...)
+			continue
+		}
+		c.BoundingBox[0] = math.Min(c.BoundingBox[0], bb[0])
+		c.BoundingBox[1] = math.Min(c.BoundingBox[1], bb[1])
+		c.BoundingBox[2] = math.Max(c.BoundingBox[2], bb[2])
+		c.BoundingBox[3] = math.Max(c.BoundingBox[3], bb[3])
+	}
+	return &c
+}
+
+// featureBoundingBoxes returns the feature, or a copy of it sharing
+// everything but the bounding boxes when they are computed or stripped.
+func (o MarshalOptions) featureBoundingBoxes(f *Feature) *Feature {
+	if o.BoundingBoxes == BoundingBoxesAsIs || f == nil {
+		return f
+	}
+	if o.BoundingBoxes == BoundingBoxesMissing {
+		if f.BoundingBox != nil {
+			return f
+		}
+		c := *f
+		c.ComputeBoundingBox()
+		return &c
+	}
+
+	c := *f
+	c.Geometry = o.boundingBoxes(f.Geometry)
+	c.BoundingBox = nil
+	if o.BoundingBoxes == BoundingBoxesAll {
+		c.BoundingBox = boundingBox(f.Geometry)
+	}
+	return &c
+}
+
+// boundingBoxes returns the geometry, or a copy of it and of its members
+// sharing the coordinates when the bounding boxes are computed or stripped.
+func (o MarshalOptions) boundingBoxes(g *Geometry) *Geometry {
+	if o.BoundingBoxes == BoundingBoxesAsIs || o.BoundingBoxes == BoundingBoxesMissing || g == nil {
+		return g
+	}
+
+	c := *g
+	c.BoundingBox = nil
+	if o.BoundingBoxes == BoundingBoxesAll {
+		c.BoundingBox = boundingBox(g)
+	}
+	if g.Geometries != nil {
+		c.Geometries = make([]*Geometry, len(g.Geometries))
+		for i, m := range g.Geometries {
+			c.Geometries[i] = o.boundingBoxes(m)
+		}
+	}
+	return &c
+}
+
+// orderedProperties encodes properties with the keys in order first, the
+// others sorted.
+type orderedProperties struct {
+	properties map[string]interface{}
+	order      []string
+}
+
+func (p orderedProperties) MarshalJSON() ([]byte, error) {
+	keys := make([]string, 0, len(p.properties))
+	listed := make(map[string]bool, len(p.order))
+	for _, key := range p.order {
+		if _, ok := p.properties[key]; ok && !listed[key] {
+			keys = append(keys, key)
+			listed[key] = true
+		}
+	}
+	first := len(keys)
+	for key := range p.properties {
+		if !listed[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[first:])
+
+	buf := bytes.NewBuffer(make([]byte, 0, 32*len(keys)))
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(p.properties[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package geojson
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestMarshalOptionsWorkers(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{0, 0, 100, 100}
+	fc.CRS = map[string]interface{}{"type": "name"}
+	for i := 0; i < 100; i++ {
+		f := NewPointFeature([]float64{float64(i), float64(i)})
+		f.ID = i
+		fc.AddFeature(f)
+	}
+
+	expected, err := fc.MarshalJSON()
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	data, err := MarshalOptions{Workers: 4}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal to json just fine but got %v", err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Errorf("should produce the same output as sequential marshal")
+		t.Logf("%v", string(data))
+	}
+}
+
+func TestMarshalOptionsWorkersError(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{1, 2}))
+	fc.AddFeature(NewPointFeature([]float64{math.NaN(), 2}))
+
+	if _, err := (MarshalOptions{Workers: 2}).MarshalFeatureCollection(fc); err == nil {
+		t.Errorf("should return error of failing feature")
+	}
+}
+
+func TestMarshalOptionsBoundingBoxes(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{-1, -1, 1, 1}
+	fc.AddFeature(NewFeature(NewCollectionGeometry(
+		NewPointGeometry([]float64{1, 2}),
+		NewLineStringGeometry([][]float64{{3, 4}, {5, 0}}),
+	)))
+	fc.AddFeature(NewFeature(nil))
+
+	data, err := MarshalOptions{BoundingBoxes: BoundingBoxesAll}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"FeatureCollection","bbox":[1,0,5,4],"features":[` +
+		`{"type":"Feature","bbox":[1,0,5,4],"geometry":{"type":"GeometryCollection","bbox":[1,0,5,4],"geometries":[` +
+		`{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2]},` +
+		`{"type":"LineString","bbox":[3,0,5,4],"coordinates":[[3,4],[5,0]]}]},"properties":null},` +
+		`{"type":"Feature","geometry":null,"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect bounding boxes, got %s", data)
+	}
+	if len(fc.BoundingBox) != 4 || fc.BoundingBox[0] != -1 || fc.Features[0].Geometry.Geometries[0].BoundingBox != nil {
+		t.Errorf("should not change the collection")
+	}
+
+	fc.Features[0].BoundingBox = []float64{0, 0, 9, 9}
+	fc.Features[0].Geometry.Geometries[1].BoundingBox = []float64{0, 0, 9, 9}
+	data, err = MarshalOptions{BoundingBoxes: BoundingBoxesNone, Workers: 2}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if bytes.Contains(data, []byte("bbox")) {
+		t.Errorf("should strip all bounding boxes, got %s", data)
+	}
+
+	data, err = MarshalOptions{BoundingBoxes: BoundingBoxesNone}.MarshalGeometry(fc.Features[0].Geometry)
+	if err != nil || bytes.Contains(data, []byte("bbox")) {
+		t.Errorf("should strip the geometry bounding boxes, got %s, %v", data, err)
+	}
+}
+
+func TestMarshalOptionsBoundingBoxesMissing(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewFeature(NewMultiPointGeometry([]float64{170, 0}, []float64{-170, 10})))
+	fc.AddFeature(NewFeature(NewPointGeometry([]float64{175, 5})))
+	fc.Features[1].BoundingBox = []float64{175, 5, 175, 5}
+
+	data, err := MarshalOptions{BoundingBoxes: BoundingBoxesMissing}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"FeatureCollection","bbox":[170,0,-170,10],"features":[` +
+		`{"type":"Feature","bbox":[170,0,-170,10],"geometry":{"type":"MultiPoint","coordinates":[[170,0],[-170,10]]},"properties":null},` +
+		`{"type":"Feature","bbox":[175,5,175,5],"geometry":{"type":"Point","coordinates":[175,5]},"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect bounding boxes, got %s", data)
+	}
+	if fc.BoundingBox != nil || fc.Features[0].BoundingBox != nil {
+		t.Errorf("should not change the collection")
+	}
+}
+
+func TestMarshalOptionsPropertyOrder(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.SetProperty("zone", "b")
+	f.SetProperty("name", "a")
+	f.SetProperty("id", 7)
+	f.SetProperty("area", 1.5)
+	f.SetProperty("nested", map[string]interface{}{"y": 1, "x": 2})
+
+	o := MarshalOptions{PropertyOrder: []string{"name", "id", "missing", "name"}}
+	expected := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":` +
+		`{"name":"a","id":7,"area":1.5,"nested":{"x":2,"y":1},"zone":"b"}}`
+	for i := 0; i < 10; i++ {
+		data, err := o.MarshalFeature(f)
+		if err != nil {
+			t.Fatalf("should marshal, but got %v", err)
+		}
+		if string(data) != expected {
+			t.Fatalf("incorrect property order, got %s", data)
+		}
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	fc.AddFeature(NewFeature(nil))
+	fc.Features = append(fc.Features, nil)
+	expected = `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":` +
+		`{"zone":"b","area":1.5,"id":7,"name":"a","nested":{"x":2,"y":1}}},` +
+		`{"type":"Feature","geometry":null,"properties":null},null]}`
+	for _, workers := range []int{0, 3} {
+		o := MarshalOptions{PropertyOrder: []string{"zone"}, Workers: workers}
+		data, err := o.MarshalFeatureCollection(fc)
+		if err != nil {
+			t.Fatalf("should marshal, but got %v", err)
+		}
+		if string(data) != expected {
+			t.Errorf("incorrect property order with %d workers, got %s", workers, data)
+		}
+	}
+}
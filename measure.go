@@ -0,0 +1,74 @@
+package geojson
+
+// NewPointGeometryZM creates and initializes a point geometry with an
+// altitude and a measure, like the positions of linear referencing data.
+func NewPointGeometryZM(lon, lat, alt, m float64) *Geometry {
+	return NewPointGeometry([]float64{lon, lat, alt, m})
+}
+
+// MeasureOf returns the measure of the position, its fourth coordinate
+// after the altitude, like the positions of "LINESTRING ZM" geometries.
+// It returns false if the position has none.
+func MeasureOf(p []float64) (float64, bool) {
+	if len(p) < 4 {
+		return 0, false
+	}
+	return p[3], true
+}
+
+// HasM returns true if all the positions of the geometry have a measure,
+// the fourth coordinate, and it has at least one.
+func (g *Geometry) HasM() bool {
+	n, m := 0, 0
+	forEachPosition(g, func(p []float64) {
+		n++
+		if len(p) >= 4 {
+			m++
+		}
+	})
+	return n > 0 && m == n
+}
+
+// DropM removes in place the measures of the positions of the geometry,
+// and the coordinates after them, keeping their altitudes, as RFC 7946
+// asks positions to have at most 3 coordinates. The members of geometry
+// collections are changed too.
+func (g *Geometry) DropM() {
+	if g == nil {
+		return
+	}
+
+	for _, m := range g.Geometries {
+		m.DropM()
+	}
+	replacePositions(g, func(p []float64) []float64 {
+		if len(p) > 3 {
+			return p[:3:3]
+		}
+		return p
+	})
+}
+
+// withoutMeasures returns the geometry, or a copy of it without measures,
+// when dropping them.
+func (o MarshalOptions) withoutMeasures(g *Geometry) *Geometry {
+	if !o.DropMeasures || g == nil {
+		return g
+	}
+
+	c := g.Clone()
+	c.DropM()
+	return c
+}
+
+// featureWithoutMeasures returns the feature, or a copy of it with its
+// geometry without measures, when dropping them.
+func (o MarshalOptions) featureWithoutMeasures(f *Feature) *Feature {
+	if !o.DropMeasures || f == nil {
+		return f
+	}
+
+	c := *f
+	c.Geometry = o.withoutMeasures(f.Geometry)
+	return &c
+}
@@ -0,0 +1,80 @@
+package geojson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMeasureOf(t *testing.T) {
+	g := NewPointGeometryZM(4.35, 50.85, 12, 150)
+	if m, ok := MeasureOf(g.Point); !ok || m != 150 {
+		t.Errorf("incorrect measure, got %v, %v", m, ok)
+	}
+	if _, ok := MeasureOf([]float64{1, 2, 3}); ok {
+		t.Errorf("should have no measure")
+	}
+}
+
+func TestGeometryHasM(t *testing.T) {
+	if !NewLineStringGeometry([][]float64{{1, 2, 3, 4}, {5, 6, 7, 8}}).HasM() {
+		t.Errorf("should have measures")
+	}
+	if NewLineStringGeometry([][]float64{{1, 2, 3, 4}, {5, 6, 7}}).HasM() {
+		t.Errorf("should not have measures on all positions")
+	}
+	if NewEmptyGeometry(GeometryPoint).HasM() {
+		t.Errorf("should not have measures without positions")
+	}
+}
+
+func TestGeometryDropM(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometryZM(1, 2, 3, 4),
+		NewLineStringGeometry([][]float64{{1, 2, 3, 4}, {5, 6}}),
+	)
+	g.DropM()
+	if !reflect.DeepEqual(g.Geometries[0].Point, []float64{1, 2, 3}) ||
+		!reflect.DeepEqual(g.Geometries[1].LineString, [][]float64{{1, 2, 3}, {5, 6}}) {
+		t.Errorf("incorrect positions, got %v", g)
+	}
+}
+
+func TestMeasuresRoundTrip(t *testing.T) {
+	g, err := UnmarshalWKT("LINESTRING ZM (1 2 3 0, 4 5 6 10)")
+	if err != nil {
+		t.Fatalf("should parse, but got %v", err)
+	}
+
+	data, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if string(data) != `{"type":"LineString","coordinates":[[1,2,3,0],[4,5,6,10]]}` {
+		t.Errorf("should keep the measures, got %s", data)
+	}
+
+	decoded, err := UnmarshalGeometry(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if wkt, _ := decoded.ToWKT(); wkt != "LINESTRING ZM (1 2 3 0, 4 5 6 10)" {
+		t.Errorf("should keep the measures through GeoJSON, got %s", wkt)
+	}
+
+	f := NewFeature(decoded)
+	data, err = MarshalOptions{DropMeasures: true}.MarshalFeature(f)
+	if err != nil || !strings.Contains(string(data), `[[1,2,3],[4,5,6]]`) {
+		t.Errorf("should drop the measures, got %s, %v", data, err)
+	}
+	if !decoded.HasM() {
+		t.Errorf("should not change the feature")
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+	data, err = MarshalOptions{DropMeasures: true}.MarshalFeatureCollection(fc)
+	if err != nil || !strings.Contains(string(data), `[[1,2,3],[4,5,6]]`) {
+		t.Errorf("should drop the measures of collections, got %s, %v", data, err)
+	}
+}
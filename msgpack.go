@@ -0,0 +1,361 @@
+package geojson
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+// msgpackArgumentSizes are the sizes of the argument following the type
+// bytes having one.
+var msgpackArgumentSizes = map[byte]int{
+	0xc4: 1, 0xc5: 2, 0xc6: 4, // bin
+	0xc7: 1, 0xc8: 2, 0xc9: 4, // ext
+	0xca: 4, 0xcb: 8, // float
+	0xcc: 1, 0xcd: 2, 0xce: 4, 0xcf: 8, // uint
+	0xd0: 1, 0xd1: 2, 0xd2: 4, 0xd3: 8, // int
+	0xd4: 0, 0xd5: 0, 0xd6: 0, 0xd7: 0, 0xd8: 0, // fixext
+	0xd9: 1, 0xda: 2, 0xdb: 4, // str
+	0xdc: 2, 0xdd: 4, // array
+	0xde: 2, 0xdf: 4, // map
+}
+
+// MarshalMsgpack encodes the geometry as a MessagePack map with the members
+// of its GeoJSON object, in the order of its JSON form, so it can be used
+// in msgpack-RPC calls or cached payloads.
+// This fulfills the msgpack.Marshaler interface.
+func (g Geometry) MarshalMsgpack() ([]byte, error) {
+	return appendValue(msgpackFormat{}, nil, &g, 0)
+}
+
+// UnmarshalMsgpack decodes the MessagePack map into a GeoJSON geometry.
+// This fulfills the msgpack.Unmarshaler interface.
+func (g *Geometry) UnmarshalMsgpack(data []byte) error {
+	object, err := unmarshalMsgpackObject(data)
+	if err != nil {
+		return err
+	}
+	return decodeGeometry(g, object, false)
+}
+
+// MarshalMsgpack encodes the feature as a MessagePack map with the members
+// of its GeoJSON object, in the order of its JSON form. Properties can
+// hold any value encoding/json can encode; values with no MessagePack
+// counterpart, like structs, are encoded as their JSON values.
+// This fulfills the msgpack.Marshaler interface.
+func (f Feature) MarshalMsgpack() ([]byte, error) {
+	members, err := f.members()
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(msgpackFormat{}, nil, members, 0)
+}
+
+// UnmarshalMsgpack decodes the MessagePack map into a GeoJSON feature.
+// Integers are decoded as int64, like BSON ones, floats as float64, binary
+// data as []byte and timestamps as time.Time. Other extension types are
+// not supported.
+// This fulfills the msgpack.Unmarshaler interface.
+func (f *Feature) UnmarshalMsgpack(data []byte) error {
+	object, err := unmarshalMsgpackObject(data)
+	if err != nil {
+		return err
+	}
+	return decodeFeature(f, object, false)
+}
+
+// msgpackFormat is the binaryFormat of MessagePack.
+type msgpackFormat struct{}
+
+// appendSized appends the type byte followed by n in size bytes.
+func (msgpackFormat) appendSized(buf []byte, b byte, size int, n uint64) []byte {
+	buf = append(buf, b)
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(n>>(8*uint(i))))
+	}
+	return buf
+}
+
+// appendLength appends the head of a string, binary, array or map of
+// length n, with the fixed type if it has one and the types of 8, 16 and
+// 32 bit lengths. A zero type is not available.
+func (m msgpackFormat) appendLength(buf []byte, n int, fixed byte, fixedMax int, types [3]byte) []byte {
+	switch {
+	case fixed != 0 && n <= fixedMax:
+		return append(buf, fixed|byte(n))
+	case types[0] != 0 && n <= math.MaxUint8:
+		return m.appendSized(buf, types[0], 1, uint64(n))
+	case n <= math.MaxUint16:
+		return m.appendSized(buf, types[1], 2, uint64(n))
+	}
+	return m.appendSized(buf, types[2], 4, uint64(n))
+}
+
+func (msgpackFormat) appendNull(buf []byte) []byte {
+	return append(buf, 0xc0)
+}
+
+func (msgpackFormat) appendBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, 0xc3)
+	}
+	return append(buf, 0xc2)
+}
+
+func (m msgpackFormat) appendInt(buf []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return m.appendUint(buf, uint64(i))
+	case i >= -32:
+		return append(buf, byte(i))
+	case i >= math.MinInt8:
+		return m.appendSized(buf, 0xd0, 1, uint64(i))
+	case i >= math.MinInt16:
+		return m.appendSized(buf, 0xd1, 2, uint64(i))
+	case i >= math.MinInt32:
+		return m.appendSized(buf, 0xd2, 4, uint64(i))
+	}
+	return m.appendSized(buf, 0xd3, 8, uint64(i))
+}
+
+func (m msgpackFormat) appendUint(buf []byte, u uint64) []byte {
+	switch {
+	case u <= math.MaxInt8:
+		return append(buf, byte(u))
+	case u <= math.MaxUint8:
+		return m.appendSized(buf, 0xcc, 1, u)
+	case u <= math.MaxUint16:
+		return m.appendSized(buf, 0xcd, 2, u)
+	case u <= math.MaxUint32:
+		return m.appendSized(buf, 0xce, 4, u)
+	}
+	return m.appendSized(buf, 0xcf, 8, u)
+}
+
+// appendFloat appends float32 values in single precision, the others in
+// double precision.
+func (m msgpackFormat) appendFloat(buf []byte, x float64, single bool) []byte {
+	if single {
+		return m.appendSized(buf, 0xca, 4, uint64(math.Float32bits(float32(x))))
+	}
+	return m.appendSized(buf, 0xcb, 8, math.Float64bits(x))
+}
+
+func (m msgpackFormat) appendText(buf []byte, s string) []byte {
+	buf = m.appendLength(buf, len(s), 0xa0, 31, [3]byte{0xd9, 0xda, 0xdb})
+	return append(buf, s...)
+}
+
+func (m msgpackFormat) appendBytes(buf []byte, b []byte) []byte {
+	buf = m.appendLength(buf, len(b), 0, 0, [3]byte{0xc4, 0xc5, 0xc6})
+	return append(buf, b...)
+}
+
+func (m msgpackFormat) appendArrayHead(buf []byte, n int) []byte {
+	return m.appendLength(buf, n, 0x90, 15, [3]byte{0, 0xdc, 0xdd})
+}
+
+func (m msgpackFormat) appendMapHead(buf []byte, n int) []byte {
+	return m.appendLength(buf, n, 0x80, 15, [3]byte{0, 0xde, 0xdf})
+}
+
+// sortMembers keeps the order of the members, the one of the JSON form.
+func (msgpackFormat) sortMembers(members []member) {}
+
+// unmarshalMsgpackObject decodes the data, a single MessagePack map.
+func unmarshalMsgpackObject(data []byte) (map[string]interface{}, error) {
+	d := &msgpackDecoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("msgpack: %d bytes after the object", len(data)-d.pos)
+	}
+
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("msgpack: expected a map, got %T", v)
+	}
+	return object, nil
+}
+
+// A msgpackDecoder decodes MessagePack objects into the values
+// encoding/json decodes into an interface{}, integers decoded as int64.
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+// uint reads a big endian unsigned integer of size bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	if len(d.data)-d.pos < size {
+		return 0, errMsgpackTruncated
+	}
+	var n uint64
+	for _, c := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.pos += size
+	return n, nil
+}
+
+// bytes reads n bytes.
+func (d *msgpackDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errMsgpackTruncated
+	}
+	b := make([]byte, n)
+	copy(b, d.data[d.pos:])
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *msgpackDecoder) value(depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("msgpack: data nested too deeply")
+	}
+	if d.pos >= len(d.data) {
+		return nil, errMsgpackTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return d.object(uint64(b&0x0f), depth)
+	case b&0xf0 == 0x90:
+		return d.array(uint64(b&0x0f), depth)
+	case b&0xe0 == 0xa0:
+		return d.text(uint64(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	}
+	s, ok := msgpackArgumentSizes[b]
+	if !ok {
+		return nil, fmt.Errorf("msgpack: invalid type 0x%02x at offset %d", b, d.pos-1)
+	}
+	n, err := d.uint(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0xc6:
+		return d.bytes(n)
+	case b <= 0xc9:
+		return d.extension(n)
+	case b == 0xca:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case b == 0xcb:
+		return math.Float64frombits(n), nil
+	case b <= 0xcf:
+		if n > math.MaxInt64 {
+			return float64(n), nil
+		}
+		return int64(n), nil
+	case b <= 0xd3:
+		shift := uint(64 - 8*s)
+		return int64(n<<shift) >> shift, nil
+	case b <= 0xd8:
+		return d.extension(1 << (b - 0xd4))
+	case b <= 0xdb:
+		return d.text(n)
+	case b <= 0xdd:
+		return d.array(n, depth)
+	}
+	return d.object(n, depth)
+}
+
+func (d *msgpackDecoder) text(n uint64) (string, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", errors.New("msgpack: invalid UTF-8 text")
+	}
+	return string(b), nil
+}
+
+// extension decodes the extension of n bytes, only the timestamp one is
+// supported.
+func (d *msgpackDecoder) extension(n uint64) (interface{}, error) {
+	t, err := d.uint(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(t) != -1 {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", int8(t))
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp of %d bytes", n)
+}
+
+func (d *msgpackDecoder) array(n uint64, depth int) ([]interface{}, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errMsgpackTruncated
+	}
+
+	values := make([]interface{}, 0, n)
+	for i := uint64(0); i < n; i++ {
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *msgpackDecoder) object(n uint64, depth int) (map[string]interface{}, error) {
+	if 2*n > uint64(len(d.data)-d.pos) {
+		return nil, errMsgpackTruncated
+	}
+
+	object := make(map[string]interface{}, n)
+	for i := uint64(0); i < n; i++ {
+		k, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key must be a string, got %T", k)
+		}
+		if object[key], err = d.value(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
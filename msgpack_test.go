@@ -0,0 +1,142 @@
+package geojson
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeometryMarshalMsgpack(t *testing.T) {
+	data, err := NewPointGeometry([]float64{1, 2}).MarshalMsgpack()
+	if err != nil {
+		t.Fatalf("should marshal point, but got %v", err)
+	}
+
+	expected := "82" + "a474797065" + "a5506f696e74" + "ab636f6f7264696e61746573" + "92" + "cb3ff0000000000000" + "cb4000000000000000"
+	if hex.EncodeToString(data) != expected {
+		t.Errorf("incorrect encoding, expected %s, got %x", expected, data)
+	}
+}
+
+func TestGeometryMsgpackRoundTrip(t *testing.T) {
+	ring := make([][]float64, 0, 70000)
+	for i := 0; i < cap(ring)-1; i++ {
+		ring = append(ring, []float64{float64(i), float64(i % 7)})
+	}
+	ring = append(ring, ring[0])
+
+	g := NewCollectionGeometry(
+		NewPolygonGeometry([][][]float64{ring}),
+		NewMultiPointGeometry([]float64{-1e300, 2}, []float64{3, 4, 5}),
+	)
+	g.BoundingBox = []float64{-1e300, 0, 69998, 6}
+	g.ForeignMembers = map[string]json.RawMessage{"title": json.RawMessage(`"` + strings.Repeat("a", 300) + `"`)}
+
+	data, err := g.MarshalMsgpack()
+	if err != nil {
+		t.Fatalf("should marshal collection, but got %v", err)
+	}
+
+	var decoded Geometry
+	if err := decoded.UnmarshalMsgpack(data); err != nil {
+		t.Fatalf("should unmarshal collection, but got %v", err)
+	}
+	if !decoded.Equal(g) || !reflect.DeepEqual(decoded.BoundingBox, g.BoundingBox) {
+		t.Errorf("incorrect collection after round trip")
+	}
+	if string(decoded.ForeignMembers["title"]) != string(g.ForeignMembers["title"]) {
+		t.Errorf("incorrect foreign members, got %v", decoded.ForeignMembers)
+	}
+}
+
+func TestFeatureMsgpackRoundTrip(t *testing.T) {
+	f := NewPointFeature([]float64{4.35, 50.85})
+	f.ID = "bru"
+	f.SetProperty("name", "Brussels")
+	f.SetProperty("population", 1200000)
+	f.SetProperty("small", int8(-100))
+	f.SetProperty("negative", int64(-5000000000))
+	f.SetProperty("ratio", float32(0.5))
+	f.SetProperty("capital", false)
+	f.SetProperty("tags", []string{"city", "region"})
+	f.SetProperty("raw", []byte{1, 2})
+
+	data, err := f.MarshalMsgpack()
+	if err != nil {
+		t.Fatalf("should marshal feature, but got %v", err)
+	}
+	if !strings.HasPrefix(hex.EncodeToString(data), "84"+"a26964"+"a3627275"+"a474797065") {
+		t.Errorf("should write the members in the order of JSON, got %x", data)
+	}
+
+	var decoded Feature
+	if err := decoded.UnmarshalMsgpack(data); err != nil {
+		t.Fatalf("should unmarshal feature, but got %v", err)
+	}
+	if decoded.ID != "bru" || decoded.Type != "Feature" || !decoded.Geometry.Equal(f.Geometry) {
+		t.Errorf("incorrect feature after round trip, got %v", decoded)
+	}
+
+	expected := map[string]interface{}{
+		"name":       "Brussels",
+		"population": int64(1200000),
+		"small":      int64(-100),
+		"negative":   int64(-5000000000),
+		"ratio":      0.5,
+		"capital":    false,
+		"tags":       []interface{}{"city", "region"},
+		"raw":        []byte{1, 2},
+	}
+	if !reflect.DeepEqual(decoded.Properties, expected) {
+		t.Errorf("incorrect properties, got %#v", decoded.Properties)
+	}
+}
+
+func TestUnmarshalMsgpackTimestamp(t *testing.T) {
+	// {"type": "Feature", "geometry": nil, "properties": {"at": timestamp}}
+	prefix := "83" + "a474797065" + "a7466561747572 65" + "a867656f6d65747279" + "c0" + "aa70726f70657274696573" + "81" + "a26174"
+	cases := map[string]time.Time{
+		"d6ff" + "5f5e1000":                        time.Unix(1600000000, 0).UTC(),
+		"d7ff" + "00000004" + "5f5e1000":           time.Unix(1600000000, 1).UTC(),
+		"c70cff" + "00000002" + "000000005f5e1000": time.Unix(1600000000, 2).UTC(),
+	}
+
+	for timestamp, expected := range cases {
+		data, _ := hex.DecodeString(strings.Replace(prefix, " ", "", -1) + timestamp)
+		var f Feature
+		if err := f.UnmarshalMsgpack(data); err != nil {
+			t.Fatalf("should unmarshal %s, but got %v", timestamp, err)
+		}
+		if f.Properties["at"] != expected {
+			t.Errorf("incorrect timestamp, expected %v, got %v", expected, f.Properties["at"])
+		}
+	}
+}
+
+func TestUnmarshalMsgpackErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"truncated":      "82a474797065",
+		"not a map":      "9101",
+		"integer key":    "810101",
+		"trailing bytes": "80c0",
+		"huge array":     "81a474797065dd7fffffff",
+		"invalid type":   "81a474797065c1",
+		"invalid text":   "81a474797065a1ff",
+		"extension":      "81a474797065d40101",
+		"deep":           "81a474797065" + strings.Repeat("91", maxBinaryDepth+1) + "00",
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			b, _ := hex.DecodeString(data)
+			var f Feature
+			if err := f.UnmarshalMsgpack(b); err == nil {
+				t.Errorf("should fail to unmarshal %s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Package mvt converts Mapbox Vector Tiles to and from GeoJSON feature collections.
+Tiles are decoded with their layers in tile coordinates, or projected to
+WGS 84 longitude/latitude for a given tile address.
+*/
+package mvt
+
+import (
+	"fmt"
+	"math"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+// DefaultExtent is the extent of layers that do not specify one.
+const DefaultExtent = 4096
+
+// The geometry types of vector tile features.
+const (
+	geomUnknown    = 0
+	geomPoint      = 1
+	geomLineString = 2
+	geomPolygon    = 3
+)
+
+// The geometry commands.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// A Tile addresses a tile in the XYZ scheme used by web maps.
+type Tile struct {
+	Z, X, Y uint32
+}
+
+// A Layer is a named layer of a vector tile.
+type Layer struct {
+	Name     string
+	Version  uint32
+	Extent   uint32
+	Features *geojson.FeatureCollection
+}
+
+// Decode decodes the layers of an encoded vector tile, with the
+// coordinates of their features in the tile coordinate system,
+// from 0 to the extent of the layer, y pointing down.
+func Decode(data []byte) ([]*Layer, error) {
+	return decode(data, nil)
+}
+
+// DecodeWGS84 decodes the layers of an encoded vector tile, with the
+// coordinates of their features projected to longitude/latitude for
+// the given tile address.
+func DecodeWGS84(data []byte, tile Tile) ([]*Layer, error) {
+	return decode(data, &tile)
+}
+
+func decode(data []byte, tile *Tile) ([]*Layer, error) {
+	var layers []*Layer
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		if r.Field() != 3 {
+			r.Skip()
+			continue
+		}
+
+		l, err := decodeLayer(r.Bytes(), tile)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %v", len(layers), err)
+		}
+		layers = append(layers, l)
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	return layers, nil
+}
+
+func decodeLayer(data []byte, tile *Tile) (*Layer, error) {
+	l := &Layer{Version: 1, Extent: DefaultExtent}
+
+	var (
+		keys     []string
+		values   []interface{}
+		features [][]byte
+	)
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 15:
+			l.Version = r.Uint32()
+		case 1:
+			l.Name = r.Text()
+		case 2:
+			features = append(features, r.Bytes())
+		case 3:
+			keys = append(keys, r.Text())
+		case 4:
+			v, err := decodeValue(r.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case 5:
+			l.Extent = r.Uint32()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	if l.Extent == 0 {
+		return nil, fmt.Errorf("layer %q has extent 0", l.Name)
+	}
+
+	project := func(x, y int64) []float64 {
+		return []float64{float64(x), float64(y)}
+	}
+	if tile != nil {
+		project = projection(*tile, l.Extent)
+	}
+
+	l.Features = geojson.NewFeatureCollection()
+	for i, data := range features {
+		f, err := decodeFeature(data, keys, values, project)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		l.Features.AddFeature(f)
+	}
+
+	return l, nil
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var v interface{}
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			v = r.Text()
+		case 2:
+			v = float64(r.Float())
+		case 3:
+			v = r.Double()
+		case 4:
+			v = r.Int64()
+		case 5:
+			u := r.Uint64()
+			if u > math.MaxInt64 {
+				v = u
+			} else {
+				v = int64(u)
+			}
+		case 6:
+			v = r.Sint64()
+		case 7:
+			v = r.Bool()
+		default:
+			r.Skip()
+		}
+	}
+
+	return v, r.Err()
+}
+
+func decodeFeature(data []byte, keys []string, values []interface{}, project func(x, y int64) []float64) (*geojson.Feature, error) {
+	var (
+		id       interface{}
+		tags     []uint32
+		geomType uint64
+		commands []uint32
+	)
+
+	r := pbf.NewReader(data)
+	for r.Next() {
+		switch r.Field() {
+		case 1:
+			id = int64(r.Uint64())
+		case 2:
+			tags = r.PackedUint32()
+		case 3:
+			geomType = r.Uint64()
+		case 4:
+			commands = r.PackedUint32()
+		default:
+			r.Skip()
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	g, err := decodeGeometry(int(geomType), commands, project)
+	if err != nil {
+		return nil, err
+	}
+
+	f := geojson.NewFeature(g)
+	f.ID = id
+
+	if len(tags)%2 != 0 {
+		return nil, fmt.Errorf("odd number of tags")
+	}
+	for i := 0; i < len(tags); i += 2 {
+		if int(tags[i]) >= len(keys) || int(tags[i+1]) >= len(values) {
+			return nil, fmt.Errorf("tag %d out of range", i/2)
+		}
+		f.SetProperty(keys[tags[i]], values[tags[i+1]])
+	}
+
+	return f, nil
+}
+
+// decodeGeometry runs the geometry commands, returning the geometry they draw.
+func decodeGeometry(geomType int, commands []uint32, project func(x, y int64) []float64) (*geojson.Geometry, error) {
+	var (
+		paths [][][]int64
+		x, y  int64
+	)
+
+	for i := 0; i < len(commands); {
+		id, count := commands[i]&7, int(commands[i]>>3)
+		i++
+
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if len(commands)-i < 2*count {
+				return nil, fmt.Errorf("geometry command needs %d parameters", 2*count)
+			}
+			for j := 0; j < count; j++ {
+				x += pbf.DecodeZigzag(uint64(commands[i]))
+				y += pbf.DecodeZigzag(uint64(commands[i+1]))
+				i += 2
+
+				if id == cmdMoveTo && (geomType != geomPoint || len(paths) == 0) {
+					paths = append(paths, nil)
+				}
+				if len(paths) == 0 {
+					return nil, fmt.Errorf("LineTo before MoveTo")
+				}
+				paths[len(paths)-1] = append(paths[len(paths)-1], []int64{x, y})
+			}
+		case cmdClosePath:
+			if len(paths) == 0 || len(paths[len(paths)-1]) == 0 {
+				return nil, fmt.Errorf("ClosePath before MoveTo")
+			}
+			path := paths[len(paths)-1]
+			paths[len(paths)-1] = append(path, path[0])
+		default:
+			return nil, fmt.Errorf("unknown geometry command %d", id)
+		}
+	}
+
+	projectPath := func(path [][]int64) [][]float64 {
+		result := make([][]float64, len(path))
+		for i, p := range path {
+			result[i] = project(p[0], p[1])
+		}
+		return result
+	}
+
+	switch geomType {
+	case geomPoint:
+		if len(paths) == 0 {
+			return nil, nil
+		}
+		points := projectPath(paths[0])
+		if len(points) == 1 {
+			return geojson.NewPointGeometry(points[0]), nil
+		}
+		return geojson.NewMultiPointGeometry(points...), nil
+	case geomLineString:
+		var lines [][][]float64
+		for _, p := range paths {
+			lines = append(lines, projectPath(p))
+		}
+		switch len(lines) {
+		case 0:
+			return nil, nil
+		case 1:
+			return geojson.NewLineStringGeometry(lines[0]), nil
+		}
+		return geojson.NewMultiLineStringGeometry(lines...), nil
+	case geomPolygon:
+		var polygons [][][][]float64
+		for _, p := range paths {
+			area := ringArea(p)
+			switch {
+			case area > 0:
+				polygons = append(polygons, [][][]float64{projectPath(p)})
+			case area < 0 && len(polygons) > 0:
+				polygons[len(polygons)-1] = append(polygons[len(polygons)-1], projectPath(p))
+			}
+		}
+		switch len(polygons) {
+		case 0:
+			return nil, nil
+		case 1:
+			return geojson.NewPolygonGeometry(polygons[0]), nil
+		}
+		return geojson.NewMultiPolygonGeometry(polygons...), nil
+	case geomUnknown:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown geometry type %d", geomType)
+}
+
+// ringArea returns twice the signed area of the ring with the surveyor's
+// formula, positive for exterior rings in the tile coordinate system.
+func ringArea(ring [][]int64) int64 {
+	var a int64
+	for i := 0; i < len(ring); i++ {
+		j := (i + 1) % len(ring)
+		a += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return a
+}
+
+// projection returns the function converting the tile coordinates
+// of the given tile into longitude/latitude.
+func projection(tile Tile, extent uint32) func(x, y int64) []float64 {
+	scale, x0, y0 := tileGrid(tile, extent)
+	t := geojson.Chain{
+		geojson.Affine{A: 1 / scale, C: x0/scale - halfWorld, E: -1 / scale, F: halfWorld - y0/scale},
+		geojson.FromWebMercator,
+	}
+
+	return func(x, y int64) []float64 {
+		lon, lat, _, _ := t.Transform(float64(x), float64(y), 0)
+		return []float64{lon, lat}
+	}
+}
@@ -0,0 +1,150 @@
+package mvt
+
+import (
+	"math"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+func command(id, count uint32) uint32 {
+	return id | count<<3
+}
+
+func zigzag(v ...int64) []uint32 {
+	result := make([]uint32, len(v))
+	for i, e := range v {
+		result[i] = uint32(pbf.EncodeZigzag(e))
+	}
+	return result
+}
+
+func testTile() []byte {
+	var layer pbf.Writer
+	layer.Uint64(15, 2)
+	layer.Text(1, "places")
+	layer.Uint64(5, 4096)
+	layer.Text(3, "name")
+	layer.Text(3, "rank")
+
+	var v pbf.Writer
+	v.Text(1, "Brussels")
+	layer.Message(4, &v)
+	v = pbf.Writer{}
+	v.Sint64(6, -3)
+	layer.Message(4, &v)
+
+	// a point at the center of the tile
+	var f pbf.Writer
+	f.Uint64(1, 7)
+	f.PackedUint32(2, []uint32{0, 0, 1, 1})
+	f.Uint64(3, geomPoint)
+	f.PackedUint32(4, append([]uint32{command(cmdMoveTo, 1)}, zigzag(2048, 2048)...))
+	layer.Message(2, &f)
+
+	// two lines
+	f = pbf.Writer{}
+	f.Uint64(3, geomLineString)
+	geometry := append([]uint32{command(cmdMoveTo, 1)}, zigzag(0, 0)...)
+	geometry = append(geometry, command(cmdLineTo, 1))
+	geometry = append(geometry, zigzag(10, 0)...)
+	geometry = append(geometry, command(cmdMoveTo, 1))
+	geometry = append(geometry, zigzag(0, 10)...)
+	geometry = append(geometry, command(cmdLineTo, 1))
+	geometry = append(geometry, zigzag(-10, 0)...)
+	f.PackedUint32(4, geometry)
+	layer.Message(2, &f)
+
+	// a square with a hole, the exterior clockwise with y down
+	f = pbf.Writer{}
+	f.Uint64(3, geomPolygon)
+	geometry = append([]uint32{command(cmdMoveTo, 1)}, zigzag(0, 0)...)
+	geometry = append(geometry, command(cmdLineTo, 3))
+	geometry = append(geometry, zigzag(100, 0, 0, 100, -100, 0)...)
+	geometry = append(geometry, command(cmdClosePath, 1))
+	geometry = append(geometry, command(cmdMoveTo, 1))
+	geometry = append(geometry, zigzag(10, -90)...)
+	geometry = append(geometry, command(cmdLineTo, 3))
+	geometry = append(geometry, zigzag(0, 10, 10, 0, 0, -10)...)
+	geometry = append(geometry, command(cmdClosePath, 1))
+	f.PackedUint32(4, geometry)
+	layer.Message(2, &f)
+
+	var tile pbf.Writer
+	tile.Message(3, &layer)
+	return tile.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	layers, err := Decode(testTile())
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(layers) != 1 {
+		t.Fatalf("should have one layer, got %v", len(layers))
+	}
+
+	l := layers[0]
+	if l.Name != "places" || l.Version != 2 || l.Extent != 4096 || len(l.Features.Features) != 3 {
+		t.Fatalf("incorrect layer, got %+v", l)
+	}
+
+	point := l.Features.Features[0]
+	if !point.Geometry.Equal(geojson.NewPointGeometry([]float64{2048, 2048})) {
+		t.Errorf("incorrect point, got %v", point.Geometry)
+	}
+	if point.ID != int64(7) || point.Properties["name"] != "Brussels" || point.Properties["rank"] != int64(-3) {
+		t.Errorf("incorrect feature, got %v %v", point.ID, point.Properties)
+	}
+
+	lines := l.Features.Features[1].Geometry
+	expected := geojson.NewMultiLineStringGeometry([][]float64{{0, 0}, {10, 0}}, [][]float64{{10, 10}, {0, 10}})
+	if !lines.Equal(expected) {
+		t.Errorf("incorrect lines, got %v", lines)
+	}
+
+	polygon := l.Features.Features[2].Geometry
+	expected = geojson.NewPolygonGeometry([][][]float64{
+		{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}},
+		{{10, 10}, {10, 20}, {20, 20}, {20, 10}, {10, 10}},
+	})
+	if !polygon.Equal(expected) {
+		t.Errorf("incorrect polygon, got %v", polygon.Polygon)
+	}
+}
+
+func TestDecodeWGS84(t *testing.T) {
+	layers, err := DecodeWGS84(testTile(), Tile{Z: 1, X: 1, Y: 0})
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	p := layers[0].Features.Features[0].Geometry.Point
+	if math.Abs(p[0]-90) > 1e-9 || math.Abs(p[1]-66.51326044311186) > 1e-9 {
+		t.Errorf("incorrect projection, got %v", p)
+	}
+
+	corner := layers[0].Features.Features[1].Geometry.MultiLineString[0][0]
+	if math.Abs(corner[0]) > 1e-9 || math.Abs(corner[1]-85.0511287798066) > 1e-9 {
+		t.Errorf("incorrect tile corner, got %v", corner)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tile := testTile()
+	if _, err := Decode(tile[:len(tile)-3]); err == nil {
+		t.Errorf("should fail on truncated tiles")
+	}
+
+	var f pbf.Writer
+	f.Uint64(3, geomLineString)
+	f.PackedUint32(4, append([]uint32{command(cmdLineTo, 1)}, zigzag(1, 1)...))
+	var layer pbf.Writer
+	layer.Message(2, &f)
+	var bad pbf.Writer
+	bad.Message(3, &layer)
+	if _, err := Decode(bad.Bytes()); err == nil {
+		t.Errorf("should fail on LineTo before MoveTo")
+	}
+}
@@ -0,0 +1,547 @@
+package mvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+// DefaultBuffer is the size of the buffer around the tile, in tile
+// coordinates, kept by Encode and EncodeWGS84 when clipping, so lines and
+// polygons do not show seams at the edges of the tiles.
+const DefaultBuffer = 64
+
+// maxLatitude is the latitude limit of Web Mercator.
+const maxLatitude = 85.0511287798066
+
+// halfWorld is half the width of the world in Web Mercator meters.
+const halfWorld = math.Pi * 6378137
+
+// Options configures the encoding of vector tiles.
+type Options struct {
+	// Buffer is the size of the buffer around the tile kept when clipping,
+	// in tile coordinates. Geometries are clipped at the edges of the tile
+	// if 0.
+	Buffer uint32
+
+	// KeepProperty, if set, selects the properties encoded as attributes of
+	// the features of the layers. The others are left out.
+	KeepProperty func(layer, key string) bool
+
+	// Downcast encodes floating point values in the smallest type keeping
+	// them exactly: integral ones as integers, unsigned if not negative,
+	// and others as float32 when they have no more precision. Equal
+	// values then share their entry in the values of the layer.
+	Downcast bool
+
+	// Keys is a dictionary of keys written first, in order, in the keys of
+	// every layer, used or not, so they have the same index in all the
+	// layers and tiles, which compress better. Other keys follow in the
+	// order they are met.
+	Keys []string
+}
+
+// Encode encodes the layers, with the coordinates of their features in the
+// tile coordinate system, as a vector tile, with the default buffer.
+func Encode(layers []*Layer) ([]byte, error) {
+	return Options{Buffer: DefaultBuffer}.Encode(layers)
+}
+
+// EncodeWGS84 encodes the layers, with the coordinates of their features in
+// longitude/latitude, as the vector tile of the given tile address, with
+// the default buffer.
+func EncodeWGS84(layers []*Layer, tile Tile) ([]byte, error) {
+	return Options{Buffer: DefaultBuffer}.EncodeWGS84(layers, tile)
+}
+
+// Encode encodes the layers, with the coordinates of their features in the
+// tile coordinate system, from 0 to the extent of the layer, y pointing
+// down, as a vector tile. The geometries are clipped to the tile and its
+// buffer and rounded to integer coordinates.
+func (o Options) Encode(layers []*Layer) ([]byte, error) {
+	return o.encode(layers, nil)
+}
+
+// EncodeWGS84 encodes the layers, with the coordinates of their features in
+// longitude/latitude, as the vector tile of the given tile address. The
+// geometries are projected to Web Mercator, clipped to the tile and its
+// buffer and rounded to integer tile coordinates.
+func (o Options) EncodeWGS84(layers []*Layer, tile Tile) ([]byte, error) {
+	return o.encode(layers, &tile)
+}
+
+func (o Options) encode(layers []*Layer, tile *Tile) ([]byte, error) {
+	var w pbf.Writer
+	for i, l := range layers {
+		layer, err := o.encodeLayer(l, tile)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %v", i, err)
+		}
+		w.Message(3, layer)
+	}
+	return w.Bytes(), nil
+}
+
+// A layerEncoder encodes the features of a layer, sharing the keys and
+// values of their properties.
+type layerEncoder struct {
+	layer      string
+	options    Options
+	keys       []string
+	keyIndex   map[string]uint32
+	values     []interface{}
+	valueIndex map[interface{}]uint32
+	project    func(p []float64) []float64
+	clipBox    [4]float64
+	features   []*pbf.Writer
+}
+
+func (o Options) encodeLayer(l *Layer, tile *Tile) (*pbf.Writer, error) {
+	if l.Name == "" {
+		return nil, fmt.Errorf("layer without name")
+	}
+	version, extent := l.Version, l.Extent
+	if version == 0 {
+		version = 2
+	}
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+
+	buffer := float64(o.Buffer)
+	e := &layerEncoder{
+		layer:      l.Name,
+		options:    o,
+		keyIndex:   make(map[string]uint32),
+		valueIndex: make(map[interface{}]uint32),
+		project: func(p []float64) []float64 {
+			return []float64{p[0], p[1]}
+		},
+		clipBox: [4]float64{-buffer, -buffer, float64(extent) + buffer, float64(extent) + buffer},
+	}
+	if tile != nil {
+		e.project = inverseProjection(*tile, extent)
+	}
+	for _, k := range o.Keys {
+		e.key(k)
+	}
+
+	if l.Features != nil {
+		for i, f := range l.Features.Features {
+			if f == nil || f.Geometry == nil {
+				continue
+			}
+			if err := e.encodeFeature(f); err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+		}
+	}
+
+	var w pbf.Writer
+	w.Uint64(15, uint64(version))
+	w.Text(1, l.Name)
+	for _, f := range e.features {
+		w.Message(2, f)
+	}
+	for _, k := range e.keys {
+		w.Text(3, k)
+	}
+	for _, v := range e.values {
+		w.Message(4, encodeValue(v))
+	}
+	w.Uint64(5, uint64(extent))
+
+	return &w, nil
+}
+
+// encodeFeature adds the feature to the layer, a feature per member of a
+// geometry collection, unless nothing of it is left in the tile.
+func (e *layerEncoder) encodeFeature(f *geojson.Feature) error {
+	geometries := []*geojson.Geometry{f.Geometry}
+	if f.Geometry.IsCollection() {
+		geometries = f.Geometry.Geometries
+	}
+
+	var tags []uint32
+	tagged := false
+	for _, g := range geometries {
+		if g == nil || g.IsCollection() {
+			continue
+		}
+		geomType, commands, err := e.encodeGeometry(g)
+		if err != nil {
+			return err
+		}
+		if len(commands) == 0 {
+			continue
+		}
+
+		if !tagged {
+			tags = e.tags(f.Properties)
+			tagged = true
+		}
+
+		var w pbf.Writer
+		if id, ok := featureID(f.ID); ok {
+			w.Uint64(1, id)
+		}
+		if len(tags) > 0 {
+			w.PackedUint32(2, tags)
+		}
+		w.Uint64(3, uint64(geomType))
+		w.PackedUint32(4, commands)
+		e.features = append(e.features, &w)
+	}
+
+	return nil
+}
+
+// featureID returns the ID of the feature as the unsigned integer vector
+// tiles expect, and false if it is not one.
+func featureID(id interface{}) (uint64, bool) {
+	switch v := id.(type) {
+	case float64:
+		if v >= 0 && v <= math.MaxUint64 && v == math.Trunc(v) {
+			return uint64(v), true
+		}
+	case int:
+		return uint64(v), v >= 0
+	case int64:
+		return uint64(v), v >= 0
+	case uint64:
+		return v, true
+	case json.Number:
+		n, err := strconv.ParseUint(string(v), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// tags returns the indexes of the keys and values of the properties kept,
+// sorted by key. Null properties are left out, and arrays and objects are
+// encoded as JSON strings.
+func (e *layerEncoder) tags(properties map[string]interface{}) []uint32 {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		if e.options.KeepProperty == nil || e.options.KeepProperty(e.layer, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var tags []uint32
+	for _, k := range keys {
+		v := tagValue(properties[k])
+		if v == nil {
+			continue
+		}
+		if e.options.Downcast {
+			v = downcast(v)
+		}
+
+		ki := e.key(k)
+		vi, ok := e.valueIndex[v]
+		if !ok {
+			vi = uint32(len(e.values))
+			e.valueIndex[v] = vi
+			e.values = append(e.values, v)
+		}
+		tags = append(tags, ki, vi)
+	}
+	return tags
+}
+
+// key returns the index of the key, adding it to the keys of the layer
+// if needed.
+func (e *layerEncoder) key(k string) uint32 {
+	ki, ok := e.keyIndex[k]
+	if !ok {
+		ki = uint32(len(e.keys))
+		e.keyIndex[k] = ki
+		e.keys = append(e.keys, k)
+	}
+	return ki
+}
+
+// downcast returns the value in the smallest vector tile type keeping it
+// exactly.
+func downcast(v interface{}) interface{} {
+	var x float64
+	switch n := v.(type) {
+	case float64:
+		x = n
+	case float32:
+		x = float64(n)
+	case int64:
+		if n >= 0 {
+			return uint64(n)
+		}
+		return n
+	default:
+		return v
+	}
+
+	switch {
+	case x != math.Trunc(x) || math.IsInf(x, 0):
+	case x >= 0 && x < math.MaxUint64:
+		return uint64(x)
+	case x < 0 && x >= math.MinInt64:
+		return int64(x)
+	}
+	if f := float32(x); float64(f) == x {
+		return f
+	}
+	return v
+}
+
+// tagValue converts the property value to one of the types of vector tile
+// values: string, float32, float64, int64, uint64 or bool.
+func tagValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string, float32, float64, int64, uint64, bool:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func encodeValue(v interface{}) *pbf.Writer {
+	var w pbf.Writer
+	switch v := v.(type) {
+	case string:
+		w.Text(1, v)
+	case float32:
+		w.Float(2, v)
+	case float64:
+		w.Double(3, v)
+	case int64:
+		w.Sint64(6, v)
+	case uint64:
+		w.Uint64(5, v)
+	case bool:
+		w.Bool(7, v)
+	}
+	return &w
+}
+
+// encodeGeometry returns the type and the commands of the geometry, clipped
+// and rounded to tile coordinates, no commands if nothing of it is left.
+func (e *layerEncoder) encodeGeometry(g *geojson.Geometry) (int, []uint32, error) {
+	switch g.Type {
+	case geojson.GeometryPoint, geojson.GeometryMultiPoint:
+		points := g.MultiPoint
+		if g.Type == geojson.GeometryPoint {
+			points = [][]float64{g.Point}
+		}
+
+		var kept [][]int64
+		for _, p := range points {
+			if len(p) < 2 {
+				return 0, nil, fmt.Errorf("position %v needs at least 2 coordinates", p)
+			}
+			q := e.project(p)
+			if q[0] < e.clipBox[0] || q[0] > e.clipBox[2] || q[1] < e.clipBox[1] || q[1] > e.clipBox[3] {
+				continue
+			}
+			kept = append(kept, []int64{int64(math.Round(q[0])), int64(math.Round(q[1]))})
+		}
+
+		var c commandWriter
+		c.moveTo(kept...)
+		return geomPoint, c.commands, nil
+	case geojson.GeometryLineString, geojson.GeometryMultiLineString:
+		lines := g.MultiLineString
+		if g.Type == geojson.GeometryLineString {
+			lines = [][][]float64{g.LineString}
+		}
+
+		projected := make([][][]float64, len(lines))
+		for i, l := range lines {
+			path, err := e.projectPath(l)
+			if err != nil {
+				return 0, nil, err
+			}
+			projected[i] = path
+		}
+
+		var c commandWriter
+		for _, piece := range e.clip(geojson.NewMultiLineStringGeometry(projected...)).MultiLineString {
+			if q := quantize(piece); len(q) >= 2 {
+				c.moveTo(q[0])
+				c.lineTo(q[1:]...)
+			}
+		}
+		return geomLineString, c.commands, nil
+	case geojson.GeometryPolygon, geojson.GeometryMultiPolygon:
+		polygons := g.MultiPolygon
+		if g.Type == geojson.GeometryPolygon {
+			polygons = [][][][]float64{g.Polygon}
+		}
+
+		projected := make([][][][]float64, len(polygons))
+		for i, p := range polygons {
+			projected[i] = make([][][]float64, len(p))
+			for j, r := range p {
+				path, err := e.projectPath(r)
+				if err != nil {
+					return 0, nil, err
+				}
+				projected[i][j] = path
+			}
+		}
+
+		var c commandWriter
+		for _, p := range e.clip(geojson.NewMultiPolygonGeometry(projected...)).MultiPolygon {
+			for i, r := range p {
+				ring := quantize(r)
+				if n := len(ring); n > 1 && (ring[0][0] != ring[n-1][0] || ring[0][1] != ring[n-1][1]) {
+					ring = append(ring, ring[0])
+				}
+				if len(ring) < 4 {
+					if i == 0 {
+						break
+					}
+					continue
+				}
+				area := ringArea(ring)
+				if area == 0 {
+					if i == 0 {
+						break
+					}
+					continue
+				}
+				// exterior rings are clockwise on screen, y pointing
+				// down, holes counterclockwise
+				if (area > 0) != (i == 0) {
+					reverse(ring)
+				}
+
+				c.moveTo(ring[0])
+				c.lineTo(ring[1 : len(ring)-1]...)
+				c.closePath()
+			}
+		}
+		return geomPolygon, c.commands, nil
+	}
+
+	return 0, nil, fmt.Errorf("geometry type %s can not be encoded", g.Type)
+}
+
+// clip returns the multi-line string or the multi-polygon in tile
+// coordinates clipped to the tile and its buffer, empty if nothing of it is
+// left.
+func (e *layerEncoder) clip(g *geojson.Geometry) *geojson.Geometry {
+	clipped := geojson.ClipToBox(g, e.clipBox[:])
+	switch {
+	case clipped == nil:
+		return &geojson.Geometry{}
+	case clipped.Type == geojson.GeometryLineString:
+		return geojson.NewMultiLineStringGeometry(clipped.LineString)
+	case clipped.Type == geojson.GeometryPolygon:
+		return geojson.NewMultiPolygonGeometry(clipped.Polygon)
+	}
+	return clipped
+}
+
+func (e *layerEncoder) projectPath(path [][]float64) ([][]float64, error) {
+	result := make([][]float64, len(path))
+	for i, p := range path {
+		if len(p) < 2 {
+			return nil, fmt.Errorf("position %v needs at least 2 coordinates", p)
+		}
+		result[i] = e.project(p)
+	}
+	return result, nil
+}
+
+// quantize rounds the path to integer coordinates, dropping the positions
+// rounded to the previous one.
+func quantize(path [][]float64) [][]int64 {
+	var result [][]int64
+	for _, p := range path {
+		q := []int64{int64(math.Round(p[0])), int64(math.Round(p[1]))}
+		if n := len(result); n > 0 && result[n-1][0] == q[0] && result[n-1][1] == q[1] {
+			continue
+		}
+		result = append(result, q)
+	}
+	return result
+}
+
+func reverse(path [][]int64) {
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+}
+
+// A commandWriter writes geometry commands, with the parameters relative
+// to the cursor.
+type commandWriter struct {
+	commands []uint32
+	x, y     int64
+}
+
+func (c *commandWriter) command(id int, positions [][]int64) {
+	if len(positions) == 0 {
+		return
+	}
+	c.commands = append(c.commands, uint32(id)|uint32(len(positions))<<3)
+	for _, p := range positions {
+		c.commands = append(c.commands, uint32(pbf.EncodeZigzag(p[0]-c.x)), uint32(pbf.EncodeZigzag(p[1]-c.y)))
+		c.x, c.y = p[0], p[1]
+	}
+}
+
+func (c *commandWriter) moveTo(positions ...[]int64) {
+	c.command(cmdMoveTo, positions)
+}
+
+func (c *commandWriter) lineTo(positions ...[]int64) {
+	c.command(cmdLineTo, positions)
+}
+
+func (c *commandWriter) closePath() {
+	c.commands = append(c.commands, cmdClosePath|1<<3)
+}
+
+// inverseProjection returns the function converting longitude/latitude
+// into the tile coordinates of the given tile, the inverse of projection.
+func inverseProjection(tile Tile, extent uint32) func(p []float64) []float64 {
+	scale, x0, y0 := tileGrid(tile, extent)
+	t := geojson.Chain{
+		geojson.ToWebMercator,
+		geojson.Affine{A: scale, C: halfWorld*scale - x0, E: -scale, F: halfWorld*scale - y0},
+	}
+
+	return func(p []float64) []float64 {
+		// the latitude is clamped, so the transformation cannot fail
+		x, y, _, _ := t.Transform(p[0], math.Max(-maxLatitude, math.Min(maxLatitude, p[1])), 0)
+		return []float64{x, y}
+	}
+}
+
+// tileGrid returns the scale from Web Mercator meters to tile coordinates,
+// and the origin of the given tile in the tile coordinates of the world.
+func tileGrid(tile Tile, extent uint32) (scale, x0, y0 float64) {
+	size := float64(extent) * math.Exp2(float64(tile.Z))
+	return size / (2 * halfWorld), float64(extent) * float64(tile.X), float64(extent) * float64(tile.Y)
+}
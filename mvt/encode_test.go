@@ -0,0 +1,222 @@
+package mvt
+
+import (
+	"math"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/internal/pbf"
+)
+
+func TestEncode(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+
+	point := geojson.NewPointFeature([]float64{2048, 2048})
+	point.ID = 7.0
+	point.SetProperty("name", "Brussels")
+	point.SetProperty("rank", -3)
+	point.SetProperty("tags", []interface{}{"a"})
+	point.SetProperty("none", nil)
+	fc.AddFeature(point)
+
+	line := geojson.NewLineStringFeature([][]float64{{-1000, 100}, {100, 100}, {100.2, 100.4}, {200, 200}})
+	line.SetProperty("name", "Brussels")
+	fc.AddFeature(line)
+
+	// counterclockwise with y up, so clockwise on the screen
+	square := [][]float64{{0, 0}, {0, 100}, {100, 100}, {100, 0}, {0, 0}}
+	hole := [][]float64{{10, 10}, {50, 10}, {50, 50}, {10, 50}, {10, 10}}
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{hole, square}))
+
+	fc.AddFeature(geojson.NewPointFeature([]float64{-500, -500}))
+	fc.AddFeature(geojson.NewFeature(nil))
+
+	data, err := Encode([]*Layer{{Name: "places", Features: fc}})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	layers, err := Decode(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(layers) != 1 || layers[0].Name != "places" || layers[0].Version != 2 || layers[0].Extent != DefaultExtent {
+		t.Fatalf("incorrect layers, got %v", layers)
+	}
+
+	features := layers[0].Features.Features
+	if len(features) != 3 {
+		t.Fatalf("should keep 3 features in the tile, got %d", len(features))
+	}
+
+	p := features[0]
+	if p.ID != int64(7) || !p.Geometry.Equal(point.Geometry) {
+		t.Errorf("incorrect point, got %v %v", p.ID, p.Geometry)
+	}
+	if p.PropertyMustString("name") != "Brussels" || p.Properties["rank"] != int64(-3) || p.PropertyMustString("tags") != `["a"]` {
+		t.Errorf("incorrect properties, got %v", p.Properties)
+	}
+	if _, ok := p.Properties["none"]; ok {
+		t.Errorf("should leave out null properties, got %v", p.Properties)
+	}
+
+	expectedLine := geojson.NewLineStringGeometry([][]float64{{-64, 100}, {100, 100}, {200, 200}})
+	if !features[1].Geometry.Equal(expectedLine) {
+		t.Errorf("should clip to the buffer and drop rounded duplicates, got %v", features[1].Geometry.LineString)
+	}
+
+	g := features[2].Geometry
+	if !g.IsPolygon() || len(g.Polygon) != 2 {
+		t.Fatalf("should decode the polygon with its hole, got %v", g)
+	}
+	if ringArea(toInt(g.Polygon[0])) <= 0 || ringArea(toInt(g.Polygon[1])) >= 0 {
+		t.Errorf("incorrect ring orientations, got %v", g.Polygon)
+	}
+}
+
+func toInt(ring [][]float64) [][]int64 {
+	result := make([][]int64, len(ring))
+	for i, p := range ring {
+		result[i] = []int64{int64(p[0]), int64(p[1])}
+	}
+	return result
+}
+
+func TestEncodeSharesValues(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	for i := 0; i < 3; i++ {
+		f := geojson.NewPointFeature([]float64{float64(i), 0})
+		f.SetProperty("kind", "shop")
+		f.SetProperty("open", i == 1)
+		fc.AddFeature(f)
+	}
+
+	data, _ := Options{}.Encode([]*Layer{{Name: "shops", Extent: 512, Features: fc}})
+
+	r := pbf.NewReader(data)
+	r.Next()
+	layer := pbf.NewReader(r.Bytes())
+	keys, values := 0, 0
+	for layer.Next() {
+		switch layer.Field() {
+		case 3:
+			keys++
+		case 4:
+			values++
+		}
+		layer.Skip()
+	}
+	if keys != 2 || values != 3 {
+		t.Errorf("should share keys and values, got %d keys and %d values", keys, values)
+	}
+}
+
+func TestEncodeAttributeControls(t *testing.T) {
+	roads := geojson.NewFeatureCollection()
+	for i, lanes := range []interface{}{2.0, int64(2), 2.5, 0.1, -4.0} {
+		f := geojson.NewPointFeature([]float64{float64(i), 0})
+		f.SetProperty("lanes", lanes)
+		f.SetProperty("internal_id", i)
+		roads.AddFeature(f)
+	}
+	places := geojson.NewFeatureCollection()
+	place := geojson.NewPointFeature([]float64{0, 0})
+	place.SetProperty("name", "Brussels")
+	places.AddFeature(place)
+
+	o := Options{
+		KeepProperty: func(layer, key string) bool { return key != "internal_id" },
+		Downcast:     true,
+		Keys:         []string{"name", "lanes"},
+	}
+	data, err := o.Encode([]*Layer{{Name: "roads", Features: roads}, {Name: "places", Features: places}})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	r := pbf.NewReader(data)
+	var keys [][]string
+	var fields []map[int]int
+	for r.Next() {
+		layer := pbf.NewReader(r.Bytes())
+		var layerKeys []string
+		layerFields := map[int]int{}
+		for layer.Next() {
+			switch layer.Field() {
+			case 3:
+				layerKeys = append(layerKeys, layer.Text())
+			case 4:
+				value := pbf.NewReader(layer.Bytes())
+				for value.Next() {
+					layerFields[value.Field()]++
+					value.Skip()
+				}
+			default:
+				layer.Skip()
+			}
+		}
+		keys = append(keys, layerKeys)
+		fields = append(fields, layerFields)
+	}
+
+	for i, layerKeys := range keys {
+		if len(layerKeys) != 2 || layerKeys[0] != "name" || layerKeys[1] != "lanes" {
+			t.Errorf("incorrect keys of layer %d, got %v", i, layerKeys)
+		}
+	}
+	// 2 shared as uint, 2.5 as float, 0.1 as double and -4 as sint
+	if len(fields) != 2 || fields[0][5] != 1 || fields[0][2] != 1 || fields[0][3] != 1 || fields[0][6] != 1 || len(fields[0]) != 4 {
+		t.Errorf("incorrect value types, got %v", fields)
+	}
+
+	layers, err := Decode(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	lanes := []interface{}{int64(2), int64(2), 2.5, 0.1, int64(-4)}
+	for i, f := range layers[0].Features.Features {
+		if f.Properties["lanes"] != lanes[i] || len(f.Properties) != 1 {
+			t.Errorf("incorrect properties of feature %d, got %v", i, f.Properties)
+		}
+	}
+}
+
+func TestEncodeWGS84(t *testing.T) {
+	tile := Tile{Z: 10, X: 524, Y: 343}
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPointFeature([]float64{4.35, 50.85}))
+	fc.AddFeature(geojson.NewLineStringFeature([][]float64{{4.3, 50.8}, {4.4, 50.9}}))
+	fc.AddFeature(geojson.NewPointFeature([]float64{-73.98, 40.75}))
+
+	data, err := EncodeWGS84([]*Layer{{Name: "city", Features: fc}}, tile)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	layers, err := DecodeWGS84(data, tile)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	features := layers[0].Features.Features
+	if len(features) != 2 {
+		t.Fatalf("should leave out the point outside the tile, got %d features", len(features))
+	}
+	// a tile coordinate at zoom 10 is less than 10 meters
+	if p := features[0].Geometry.Point; math.Abs(p[0]-4.35) > 1e-4 || math.Abs(p[1]-50.85) > 1e-4 {
+		t.Errorf("incorrect point, got %v", p)
+	}
+	if !features[1].Geometry.IsLineString() {
+		t.Errorf("should keep the clipped line, got %v", features[1].Geometry)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode([]*Layer{{Features: geojson.NewFeatureCollection()}}); err == nil {
+		t.Errorf("should reject a layer without name")
+	}
+
+	fc := geojson.NewFeatureCollection().AddFeature(geojson.NewPointFeature([]float64{1}))
+	if _, err := Encode([]*Layer{{Name: "bad", Features: fc}}); err == nil {
+		t.Errorf("should reject an invalid position")
+	}
+}
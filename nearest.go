@@ -0,0 +1,210 @@
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// A NearestPair matches a feature of a collection with the nearest feature
+// of another one.
+type NearestPair struct {
+	A, B     int     // indexes of the features in their collections
+	Distance float64 // in meters, 0 when the geometries touch or overlap
+}
+
+// NearestPairs finds, for each feature of a, the nearest feature of b within
+// maxDistanceMeters, no limit if zero. Features without a match, or without
+// positions, get no pair; the pairs are ordered by feature of a.
+//
+// Both collections are indexed in packed R-trees traversed together, so
+// whole groups of features are ruled out at once instead of comparing every
+// feature of a with every feature of b. Distances are measured between the
+// closest points of the geometries, geodesic, see MeasureOptions.NearestPairs
+// for other measurers.
+func NearestPairs(a, b *FeatureCollection, maxDistanceMeters float64) ([]NearestPair, error) {
+	return MeasureOptions{}.NearestPairs(a, b, maxDistanceMeters)
+}
+
+// NearestPairs is NearestPairs measuring with the Measurer of the options.
+// The groups of features are still ruled out with spherical distances, so
+// a measurer far from them may miss the nearest feature.
+func (o MeasureOptions) NearestPairs(a, b *FeatureCollection, maxDistanceMeters float64) ([]NearestPair, error) {
+	treeA, err := NewPackedRTree(a, 0)
+	if err != nil {
+		return nil, err
+	}
+	treeB, err := NewPackedRTree(b, 0)
+	if err != nil {
+		return nil, err
+	}
+	if treeA.Len() == 0 || treeB.Len() == 0 {
+		return nil, nil
+	}
+
+	max := maxDistanceMeters
+	if max <= 0 {
+		max = math.Inf(1)
+	}
+
+	numNodes := len(treeA.nodes) / packedRTreeNodeSize
+	p := &pairer{
+		a: a, b: b,
+		treeA: treeA, treeB: treeB,
+		measurer: o.measurer(),
+		bounds:   make([]float64, numNodes),
+		best:     make(map[int]NearestPair),
+	}
+	for i := range p.bounds {
+		p.bounds[i] = max
+	}
+
+	p.visit(0, len(treeA.levelBounds)-1, 0, len(treeB.levelBounds)-1)
+
+	pairs := make([]NearestPair, 0, len(p.best))
+	for _, pair := range p.best {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].A < pairs[j].A })
+	return pairs, nil
+}
+
+type pairer struct {
+	a, b         *FeatureCollection
+	treeA, treeB *PackedRTree
+	measurer     Measurer
+
+	// bounds holds for each node of the tree of a the largest distance still
+	// worth looking at for the features under it
+	bounds []float64
+	best   map[int]NearestPair
+}
+
+// visit compares the features under the node na at level la of the tree of
+// a with the ones under the node nb at level lb of the tree of b.
+func (p *pairer) visit(na, la, nb, lb int) {
+	if boxDistance(p.treeA.nodeBoundingBox(na), p.treeB.nodeBoundingBox(nb)) > p.bounds[na] {
+		return
+	}
+
+	if la == 0 && lb == 0 {
+		i, j := int(p.treeA.nodeOffset(na)), int(p.treeB.nodeOffset(nb))
+		d := geometryDistance(p.a.Features[i].Geometry, p.b.Features[j].Geometry, p.measurer)
+		if d <= p.bounds[na] {
+			if best, ok := p.best[i]; !ok || d < best.Distance || d == best.Distance && j < best.B {
+				p.best[i] = NearestPair{A: i, B: j, Distance: d}
+				p.bounds[na] = d
+			}
+		}
+		return
+	}
+
+	if lb > 0 && lb >= la {
+		// closest children first, so the bound shrinks early
+		children := p.treeB.children(nb, lb)
+		ba := p.treeA.nodeBoundingBox(na)
+		sort.Slice(children, func(i, j int) bool {
+			return boxDistance(ba, p.treeB.nodeBoundingBox(children[i])) < boxDistance(ba, p.treeB.nodeBoundingBox(children[j]))
+		})
+		for _, c := range children {
+			p.visit(na, la, c, lb-1)
+		}
+		return
+	}
+
+	bound := 0.0
+	for _, c := range p.treeA.children(na, la) {
+		p.visit(c, la-1, nb, lb)
+		bound = math.Max(bound, p.bounds[c])
+	}
+	p.bounds[na] = math.Min(p.bounds[na], bound)
+}
+
+// boxDistance returns a lower bound of the distance in meters between two
+// bounding boxes.
+func boxDistance(a, b []float64) float64 {
+	dx := math.Max(0, math.Max(a[0]-b[2], b[0]-a[2]))
+	dy := math.Max(0, math.Max(a[1]-b[3], b[1]-a[3]))
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+
+	// longitudes are closest at the latitude furthest from the equator
+	lat := math.Max(math.Max(math.Abs(a[1]), math.Abs(a[3])), math.Max(math.Abs(b[1]), math.Abs(b[3])))
+	x := radians(dx) * math.Cos(radians(math.Min(lat, 90)))
+	return EarthRadius * math.Hypot(x, radians(dy))
+}
+
+// geometryDistance returns the distance in meters between the closest points
+// of the geometries, 0 if they intersect.
+func geometryDistance(a, b *Geometry, m Measurer) float64 {
+	if intersects(a, b) {
+		return 0
+	}
+
+	d := math.Inf(1)
+	closest := func(from, to *Geometry) {
+		var vertices [][]float64
+		forEachPosition(to, func(p []float64) {
+			if len(p) >= 2 {
+				vertices = append(vertices, p)
+			}
+		})
+
+		forEachPosition(from, func(p []float64) {
+			if len(p) < 2 {
+				return
+			}
+			for _, v := range vertices {
+				d = math.Min(d, m.Distance(p, v))
+			}
+			forEachSegment(to, func(s, e []float64) {
+				d = math.Min(d, m.Distance(p, closestPointOnSegment(p, s, e)))
+			})
+		})
+	}
+	closest(a, b)
+	closest(b, a)
+
+	return d
+}
+
+// intersects reports whether segments of the geometries cross, or a vertex of
+// one lies in a polygon of the other.
+func intersects(a, b *Geometry) bool {
+	crossing := false
+	forEachSegment(a, func(p, q []float64) {
+		if crossing {
+			return
+		}
+		forEachSegment(b, func(r, s []float64) {
+			if !crossing && properIntersection(p, q, r, s) != nil {
+				crossing = true
+			}
+		})
+	})
+	if crossing {
+		return true
+	}
+
+	inside := func(g, polygonal *Geometry) bool {
+		polygons := polygons(polygonal)
+		if len(polygons) == 0 {
+			return false
+		}
+
+		found := false
+		forEachPosition(g, func(p []float64) {
+			if found || len(p) < 2 {
+				return
+			}
+			for _, polygon := range polygons {
+				if PointInPolygonWinding(p, polygon) != Exterior {
+					found = true
+					return
+				}
+			}
+		})
+		return found
+	}
+	return inside(a, b) || inside(b, a)
+}
@@ -0,0 +1,84 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNearestPairs(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	random := func(n int) *FeatureCollection {
+		fc := NewFeatureCollection()
+		for i := 0; i < n; i++ {
+			x, y := 4+r.Float64(), 50+r.Float64()
+			if i%5 == 0 {
+				fc.AddFeature(NewLineStringFeature([][]float64{{x, y}, {x + r.Float64()*0.01, y + r.Float64()*0.01}}))
+			} else {
+				fc.AddFeature(NewPointFeature([]float64{x, y}))
+			}
+		}
+		return fc
+	}
+	a, b := random(300), random(500)
+
+	for _, max := range []float64{0, 2000} {
+		pairs, err := NearestPairs(a, b, max)
+		if err != nil {
+			t.Fatalf("should pair, but got %v", err)
+		}
+
+		var expected []NearestPair
+		for i, fa := range a.Features {
+			best := NearestPair{A: i, B: -1, Distance: math.Inf(1)}
+			for j, fb := range b.Features {
+				if d := geometryDistance(fa.Geometry, fb.Geometry, Geodesic{}); d < best.Distance {
+					best.B, best.Distance = j, d
+				}
+			}
+			if max == 0 || best.Distance <= max {
+				expected = append(expected, best)
+			}
+		}
+
+		if len(pairs) != len(expected) {
+			t.Fatalf("should find %d pairs with a maximum of %v, got %d", len(expected), max, len(pairs))
+		}
+		for i, p := range pairs {
+			if p.A != expected[i].A || math.Abs(p.Distance-expected[i].Distance) > 1e-9 {
+				t.Errorf("incorrect pair for feature %d, expected %+v, got %+v", expected[i].A, expected[i], p)
+			}
+		}
+	}
+}
+
+func TestNearestPairsIntersecting(t *testing.T) {
+	a := NewFeatureCollection()
+	a.AddFeature(NewPointFeature([]float64{0.5, 0.5}))
+	a.AddFeature(NewLineStringFeature([][]float64{{2, -1}, {2, 1}}))
+	a.AddFeature(NewFeature(nil))
+
+	b := NewFeatureCollection()
+	b.AddFeature(NewLineStringFeature([][]float64{{1, 0}, {3, 0}}))
+	b.AddFeature(NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}))
+
+	pairs, err := NearestPairs(a, b, 0)
+	if err != nil {
+		t.Fatalf("should pair, but got %v", err)
+	}
+
+	expected := []NearestPair{{A: 0, B: 1}, {A: 1, B: 0}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("should pair the features with positions, got %+v", pairs)
+	}
+	for i, p := range pairs {
+		if p != expected[i] {
+			t.Errorf("should pair intersecting geometries at 0, expected %+v, got %+v", expected[i], p)
+		}
+	}
+
+	pairs, _ = NearestPairs(a, NewFeatureCollection(), 0)
+	if len(pairs) != 0 {
+		t.Errorf("should find no pairs in an empty collection, got %+v", pairs)
+	}
+}
@@ -0,0 +1,274 @@
+package geojson
+
+import (
+	"sort"
+)
+
+// Normalize rewrites the geometry in place into a canonical form, so that
+// geometries describing the same shape end up with the same structure:
+//
+//   - nested geometry collections are flattened and duplicate members removed,
+//   - collections with a single member are unwrapped to that member,
+//   - collections whose members are all points, all lines or all polygons
+//     collapse into a MultiPoint, MultiLineString or MultiPolygon,
+//   - closed rings start at their lexicographically smallest vertex,
+//   - the members of multi geometries, polygon holes and collections are sorted.
+//
+// The exterior ring of a polygon stays first. Line direction is preserved.
+func Normalize(g *Geometry) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryMultiPoint:
+		sortPositions(g.MultiPoint)
+	case GeometryLineString:
+	case GeometryMultiLineString:
+		sortPaths(g.MultiLineString)
+	case GeometryPolygon:
+		normalizePolygon(g.Polygon)
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			normalizePolygon(p)
+		}
+		sortPolygons(g.MultiPolygon)
+	case GeometryCollection:
+		normalizeCollection(g)
+	}
+}
+
+func normalizeCollection(g *Geometry) {
+	members := flattenCollection(g.Geometries, nil)
+	for _, m := range members {
+		Normalize(m)
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return compareGeometries(members[i], members[j]) < 0
+	})
+
+	unique := members[:0]
+	for i, m := range members {
+		if i > 0 && compareGeometries(members[i-1], m) == 0 {
+			continue
+		}
+		unique = append(unique, m)
+	}
+	members = unique
+
+	if len(members) == 1 {
+		bbox, crs := g.BoundingBox, g.CRS
+		*g = *members[0]
+		if len(bbox) != 0 {
+			g.BoundingBox = bbox
+		}
+		if len(crs) != 0 {
+			g.CRS = crs
+		}
+		return
+	}
+
+	g.Geometries = members
+	if len(members) == 0 {
+		return
+	}
+
+	var (
+		points   [][]float64
+		lines    [][][]float64
+		polygons [][][][]float64
+	)
+	for _, m := range members {
+		switch m.Type {
+		case GeometryPoint:
+			points = append(points, m.Point)
+		case GeometryMultiPoint:
+			points = append(points, m.MultiPoint...)
+		case GeometryLineString:
+			lines = append(lines, m.LineString)
+		case GeometryMultiLineString:
+			lines = append(lines, m.MultiLineString...)
+		case GeometryPolygon:
+			polygons = append(polygons, m.Polygon)
+		case GeometryMultiPolygon:
+			polygons = append(polygons, m.MultiPolygon...)
+		default:
+			return
+		}
+	}
+
+	switch {
+	case lines == nil && polygons == nil:
+		sortPositions(points)
+		g.Type, g.MultiPoint = GeometryMultiPoint, points
+	case points == nil && polygons == nil:
+		sortPaths(lines)
+		g.Type, g.MultiLineString = GeometryMultiLineString, lines
+	case points == nil && lines == nil:
+		sortPolygons(polygons)
+		g.Type, g.MultiPolygon = GeometryMultiPolygon, polygons
+	default:
+		return
+	}
+	g.Geometries = nil
+}
+
+func flattenCollection(geometries []*Geometry, result []*Geometry) []*Geometry {
+	for _, m := range geometries {
+		if m == nil {
+			continue
+		}
+		if m.Type == GeometryCollection {
+			result = flattenCollection(m.Geometries, result)
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func normalizePolygon(polygon [][][]float64) {
+	for _, ring := range polygon {
+		rotateRing(ring)
+	}
+	if len(polygon) > 2 {
+		sortPaths(polygon[1:])
+	}
+}
+
+// rotateRing rotates a closed ring in place so it starts at its
+// lexicographically smallest vertex, keeping the ring closed.
+func rotateRing(ring [][]float64) {
+	n := len(ring) - 1
+	if n < 3 || comparePositions(ring[0], ring[n]) != 0 {
+		return
+	}
+
+	min := 0
+	for i := 1; i < n; i++ {
+		if comparePositions(ring[i], ring[min]) < 0 {
+			min = i
+		}
+	}
+	if min == 0 {
+		return
+	}
+
+	rotated := make([][]float64, 0, n+1)
+	rotated = append(rotated, ring[min:n]...)
+	rotated = append(rotated, ring[:min]...)
+	rotated = append(rotated, ring[min])
+	copy(ring, rotated)
+}
+
+func sortPositions(positions [][]float64) {
+	sort.SliceStable(positions, func(i, j int) bool {
+		return comparePositions(positions[i], positions[j]) < 0
+	})
+}
+
+func sortPaths(paths [][][]float64) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return comparePaths(paths[i], paths[j]) < 0
+	})
+}
+
+func sortPolygons(polygons [][][][]float64) {
+	sort.SliceStable(polygons, func(i, j int) bool {
+		return comparePolygons(polygons[i], polygons[j]) < 0
+	})
+}
+
+var geometryTypeOrder = map[GeometryType]int{
+	GeometryPoint:           1,
+	GeometryMultiPoint:      2,
+	GeometryLineString:      3,
+	GeometryMultiLineString: 4,
+	GeometryPolygon:         5,
+	GeometryMultiPolygon:    6,
+	GeometryCollection:      7,
+}
+
+// compareGeometries orders geometries by type and then by coordinates.
+func compareGeometries(a, b *Geometry) int {
+	if a.Type != b.Type {
+		ta, tb := geometryTypeOrder[a.Type], geometryTypeOrder[b.Type]
+		if ta != tb {
+			return compareInts(ta, tb)
+		}
+		if a.Type < b.Type {
+			return -1
+		}
+		return 1
+	}
+
+	switch a.Type {
+	case GeometryPoint:
+		return comparePositions(a.Point, b.Point)
+	case GeometryMultiPoint:
+		return comparePaths(a.MultiPoint, b.MultiPoint)
+	case GeometryLineString:
+		return comparePaths(a.LineString, b.LineString)
+	case GeometryMultiLineString:
+		return comparePolygons(a.MultiLineString, b.MultiLineString)
+	case GeometryPolygon:
+		return comparePolygons(a.Polygon, b.Polygon)
+	case GeometryMultiPolygon:
+		for i := 0; i < len(a.MultiPolygon) && i < len(b.MultiPolygon); i++ {
+			if c := comparePolygons(a.MultiPolygon[i], b.MultiPolygon[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(a.MultiPolygon), len(b.MultiPolygon))
+	case GeometryCollection:
+		for i := 0; i < len(a.Geometries) && i < len(b.Geometries); i++ {
+			if c := compareGeometries(a.Geometries[i], b.Geometries[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(a.Geometries), len(b.Geometries))
+	}
+
+	return 0
+}
+
+func comparePositions(a, b []float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func comparePaths(a, b [][]float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePositions(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func comparePolygons(a, b [][][]float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePaths(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
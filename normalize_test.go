@@ -0,0 +1,81 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeUnwrapsSingleMember(t *testing.T) {
+	g := NewCollectionGeometry(NewPointGeometry([]float64{1, 2}))
+	Normalize(g)
+
+	if !g.IsPoint() {
+		t.Fatalf("should unwrap to point, got %v", g.Type)
+	}
+
+	if !reflect.DeepEqual(g.Point, []float64{1, 2}) {
+		t.Errorf("incorrect point, got %v", g.Point)
+	}
+}
+
+func TestNormalizeCollapsesHomogeneousCollection(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometry([]float64{3, 4}),
+		NewCollectionGeometry(
+			NewMultiPointGeometry([]float64{5, 6}, []float64{1, 2}),
+		),
+		NewPointGeometry([]float64{3, 4}),
+	)
+	Normalize(g)
+
+	if !g.IsMultiPoint() {
+		t.Fatalf("should collapse to multi point, got %v", g.Type)
+	}
+
+	expected := [][]float64{{1, 2}, {3, 4}, {5, 6}}
+	if !reflect.DeepEqual(g.MultiPoint, expected) {
+		t.Errorf("incorrect points, got %v", g.MultiPoint)
+	}
+	if g.Geometries != nil {
+		t.Errorf("should clear geometries, got %v", g.Geometries)
+	}
+}
+
+func TestNormalizeKeepsMixedCollection(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewLineStringGeometry([][]float64{{0, 0}, {1, 1}}),
+		NewPointGeometry([]float64{3, 4}),
+	)
+	Normalize(g)
+
+	if !g.IsCollection() {
+		t.Fatalf("should stay a collection, got %v", g.Type)
+	}
+
+	if !g.Geometries[0].IsPoint() || !g.Geometries[1].IsLineString() {
+		t.Errorf("members should be sorted by type, got %v, %v", g.Geometries[0].Type, g.Geometries[1].Type)
+	}
+}
+
+func TestNormalizeRotatesRings(t *testing.T) {
+	a := NewPolygonGeometry([][][]float64{{{1, 0}, {1, 1}, {0, 0}, {1, 0}}})
+	b := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	Normalize(a)
+	Normalize(b)
+
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("should be equal after normalization, got %v and %v", a.Polygon, b.Polygon)
+	}
+}
+
+func TestNormalizeOrdersMultiPolygon(t *testing.T) {
+	p1 := [][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}
+	p2 := [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+
+	a := NewMultiPolygonGeometry(p1, p2)
+	Normalize(a)
+
+	if a.MultiPolygon[0][0][0][0] != 0 {
+		t.Errorf("polygons should be sorted, got %v", a.MultiPolygon)
+	}
+}
@@ -0,0 +1,239 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxBinaryDepth is the deepest nesting of arrays and maps encoded or
+// decoded by the binary encodings, like the limit encoding/json applies,
+// so malicious payloads can not exhaust the stack.
+const maxBinaryDepth = 10000
+
+// A member is a member of a GeoJSON object, for the binary encodings
+// writing the objects in the order of their JSON form.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// members returns the members of the geometry object, as encoded in JSON.
+func (g Geometry) members() ([]member, error) {
+	members := []member{{"type", string(g.Type)}}
+	if len(g.BoundingBox) != 0 {
+		members = append(members, member{"bbox", g.BoundingBox})
+	}
+	coordinates, geometries := g.encodedMembers()
+	if coordinates != nil {
+		members = append(members, member{"coordinates", coordinates})
+	}
+	if geometries != nil {
+		members = append(members, member{"geometries", geometries})
+	}
+	if len(g.CRS) != 0 {
+		members = append(members, member{"crs", g.CRS})
+	}
+	return appendDecodedMembers(members, g.ForeignMembers, geometryMembers)
+}
+
+// members returns the members of the feature object, as encoded in JSON.
+func (f Feature) members() ([]member, error) {
+	var members []member
+	if f.ID != nil {
+		members = append(members, member{"id", f.ID})
+	}
+	members = append(members, member{"type", "Feature"})
+	if len(f.BoundingBox) != 0 {
+		members = append(members, member{"bbox", f.BoundingBox})
+	}
+	members = append(members, member{"geometry", f.Geometry})
+	if len(f.Properties) != 0 {
+		members = append(members, member{"properties", f.Properties})
+	} else {
+		members = append(members, member{"properties", nil})
+	}
+	if len(f.CRS) != 0 {
+		members = append(members, member{"crs", f.CRS})
+	}
+	return appendDecodedMembers(members, f.ForeignMembers, featureMembers)
+}
+
+// appendDecodedMembers appends the foreign members, decoded from JSON and
+// sorted by key, skipping the reserved ones.
+func appendDecodedMembers(members []member, foreign map[string]json.RawMessage, reserved []string) ([]member, error) {
+	keys := make([]string, 0, len(foreign))
+	for key := range foreign {
+		if !isReservedMember(key, reserved) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var value interface{}
+		if err := json.Unmarshal(foreign[key], &value); err != nil {
+			return nil, fmt.Errorf("foreign member `%s`: %v", key, err)
+		}
+		members = append(members, member{key, value})
+	}
+	return members, nil
+}
+
+// A binaryFormat appends the data items of a binary encoding of JSON
+// like values, such as CBOR or MessagePack.
+type binaryFormat interface {
+	appendNull(buf []byte) []byte
+	appendBool(buf []byte, b bool) []byte
+	appendInt(buf []byte, i int64) []byte
+	appendUint(buf []byte, u uint64) []byte
+	// appendFloat appends the float, single is true if it comes from a
+	// float32.
+	appendFloat(buf []byte, x float64, single bool) []byte
+	appendText(buf []byte, s string) []byte
+	appendBytes(buf []byte, b []byte) []byte
+	appendArrayHead(buf []byte, n int) []byte
+	appendMapHead(buf []byte, n int) []byte
+
+	// sortMembers orders the members of objects, if the format has a
+	// deterministic order.
+	sortMembers(members []member)
+}
+
+// appendValue appends the value in the format. Values with no counterpart
+// in the format, like structs, are encoded as their JSON values.
+func appendValue(format binaryFormat, buf []byte, v interface{}, depth int) ([]byte, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("value nested too deeply")
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return format.appendNull(buf), nil
+	case bool:
+		return format.appendBool(buf, v), nil
+	case string:
+		if !utf8.ValidString(v) {
+			return nil, fmt.Errorf("invalid UTF-8 text %q", v)
+		}
+		return format.appendText(buf, v), nil
+	case []byte:
+		return format.appendBytes(buf, v), nil
+	case float64:
+		return format.appendFloat(buf, v, false), nil
+	case float32:
+		return format.appendFloat(buf, float64(v), true), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return format.appendInt(buf, i), nil
+		}
+		x, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", v)
+		}
+		return format.appendFloat(buf, x, false), nil
+	case []float64:
+		buf = format.appendArrayHead(buf, len(v))
+		for _, x := range v {
+			buf = format.appendFloat(buf, x, false)
+		}
+		return buf, nil
+	case *Geometry:
+		if v == nil {
+			return format.appendNull(buf), nil
+		}
+		members, err := v.members()
+		if err != nil {
+			return nil, err
+		}
+		return appendMembers(format, buf, members, depth)
+	case map[string]interface{}:
+		if v == nil {
+			return format.appendNull(buf), nil
+		}
+		return appendMap(format, buf, v, depth)
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return format.appendInt(buf, value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return format.appendUint(buf, value.Uint()), nil
+	case reflect.Float32:
+		return format.appendFloat(buf, value.Float(), true), nil
+	case reflect.Float64:
+		return format.appendFloat(buf, value.Float(), false), nil
+	case reflect.String:
+		return appendValue(format, buf, value.String(), depth)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return format.appendNull(buf), nil
+		}
+		var err error
+		buf = format.appendArrayHead(buf, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			if buf, err = appendValue(format, buf, value.Index(i).Interface(), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Map:
+		if value.Type().Key().Kind() == reflect.String {
+			if value.IsNil() {
+				return format.appendNull(buf), nil
+			}
+			object := make(map[string]interface{}, value.Len())
+			iter := value.MapRange()
+			for iter.Next() {
+				object[iter.Key().String()] = iter.Value().Interface()
+			}
+			return appendMap(format, buf, object, depth)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return format.appendNull(buf), nil
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return appendValue(format, buf, decoded, depth+1)
+}
+
+// appendMap appends the map with its keys sorted, like encoding/json
+// does, or in the order of the format.
+func appendMap(format binaryFormat, buf []byte, object map[string]interface{}, depth int) ([]byte, error) {
+	members := make([]member, 0, len(object))
+	for key, value := range object {
+		members = append(members, member{key, value})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].key < members[j].key })
+	return appendMembers(format, buf, members, depth)
+}
+
+// appendMembers appends the members as a map.
+func appendMembers(format binaryFormat, buf []byte, members []member, depth int) ([]byte, error) {
+	format.sortMembers(members)
+
+	var err error
+	buf = format.appendMapHead(buf, len(members))
+	for _, m := range members {
+		if buf, err = appendValue(format, buf, m.key, depth+1); err != nil {
+			return nil, err
+		}
+		if buf, err = appendValue(format, buf, m.value, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
@@ -0,0 +1,151 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrGeometryOverflow is returned when a feature geometry has more
+// vertices than allowed by an OverflowPolicy set to reject it.
+var ErrGeometryOverflow = errors.New("geometry exceeds vertex budget")
+
+// An OverflowAction defines what an OverflowPolicy does with a feature
+// whose geometry exceeds the vertex budget.
+type OverflowAction int
+
+// The actions available to an OverflowPolicy.
+const (
+	// OverflowReject refuses the feature.
+	OverflowReject OverflowAction = iota
+
+	// OverflowSimplify simplifies the geometry until it fits the budget.
+	// Geometries that can not be simplified enough, like large multi points,
+	// are replaced by their bounding box polygon instead.
+	OverflowSimplify
+
+	// OverflowBoundingBox replaces the geometry by its bounding box polygon.
+	OverflowBoundingBox
+)
+
+// DefaultOverflowProperty is the property set on features modified
+// by an OverflowPolicy when no FlagProperty is configured.
+const DefaultOverflowProperty = "geometry_overflow"
+
+// An OverflowPolicy protects consumers, like renderers, from features
+// with huge geometries.
+type OverflowPolicy struct {
+	// MaxVertices is the vertex budget of a single feature geometry.
+	// A value of 0 or less disables the policy.
+	MaxVertices int
+
+	// Action defines what happens with features over budget.
+	Action OverflowAction
+
+	// FlagProperty is the property set on modified features, with as value
+	// "simplified" or "bbox" depending on what was done.
+	// Defaults to DefaultOverflowProperty.
+	FlagProperty string
+}
+
+// Apply enforces the policy on the feature, modifying it in place.
+// An error wrapping ErrGeometryOverflow is returned if the feature is rejected.
+func (p OverflowPolicy) Apply(f *Feature) error {
+	if p.MaxVertices <= 0 || f.Geometry == nil {
+		return nil
+	}
+
+	n := f.Geometry.VertexCount()
+	if n <= p.MaxVertices {
+		return nil
+	}
+
+	switch p.Action {
+	case OverflowSimplify:
+		if g := simplifyToFit(f.Geometry, p.MaxVertices); g != nil {
+			f.Geometry = g
+			f.SetProperty(p.flagProperty(), "simplified")
+			return nil
+		}
+		fallthrough
+	case OverflowBoundingBox:
+		bb := boundingBox(f.Geometry)
+		if bb == nil {
+			return nil
+		}
+		f.Geometry = boundingBoxPolygon(bb)
+		f.SetProperty(p.flagProperty(), "bbox")
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d vertices, budget is %d", ErrGeometryOverflow, n, p.MaxVertices)
+}
+
+// ApplyCollection enforces the policy on every feature of the collection.
+// Rejected features are removed from the collection and returned.
+func (p OverflowPolicy) ApplyCollection(fc *FeatureCollection) []*Feature {
+	var rejected []*Feature
+
+	kept := fc.Features[:0]
+	for _, f := range fc.Features {
+		if err := p.Apply(f); err != nil {
+			rejected = append(rejected, f)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	for i := len(kept); i < len(fc.Features); i++ {
+		fc.Features[i] = nil
+	}
+	fc.Features = kept
+
+	return rejected
+}
+
+func (p OverflowPolicy) flagProperty() string {
+	if p.FlagProperty == "" {
+		return DefaultOverflowProperty
+	}
+	return p.FlagProperty
+}
+
+// simplifyToFit searches for a simplification tolerance bringing the
+// geometry within the budget. It returns nil if there is none.
+func simplifyToFit(g *Geometry, max int) *Geometry {
+	bb := boundingBox(g)
+	if bb == nil {
+		return nil
+	}
+
+	diagonal := math.Hypot(bb[2]-bb[0], bb[3]-bb[1])
+	if diagonal == 0 {
+		return nil
+	}
+
+	// grow the tolerance until it fits, then narrow down to keep as much detail as possible
+	low, high := 0.0, diagonal*1e-6
+	var fit *Geometry
+	for high <= diagonal {
+		s := Simplify(g, high)
+		if s.VertexCount() <= max {
+			fit = s
+			break
+		}
+		low, high = high, high*2
+	}
+	if fit == nil {
+		return nil
+	}
+
+	for i := 0; i < 10; i++ {
+		mid := (low + high) / 2
+		s := Simplify(g, mid)
+		if s.VertexCount() <= max {
+			fit, high = s, mid
+		} else {
+			low = mid
+		}
+	}
+
+	return fit
+}
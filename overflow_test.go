@@ -0,0 +1,85 @@
+package geojson
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func overflowTestFeature(n int) *Feature {
+	line := make([][]float64, 0, n)
+	for i := 0; i < n; i++ {
+		x := float64(i) / float64(n)
+		line = append(line, []float64{x, math.Sin(x * 20)})
+	}
+	return NewLineStringFeature(line)
+}
+
+func TestOverflowPolicyWithinBudget(t *testing.T) {
+	f := overflowTestFeature(10)
+	p := OverflowPolicy{MaxVertices: 10}
+
+	if err := p.Apply(f); err != nil {
+		t.Fatalf("should not reject feature within budget, got %v", err)
+	}
+
+	if len(f.Properties) != 0 {
+		t.Errorf("should not flag feature within budget")
+	}
+}
+
+func TestOverflowPolicyReject(t *testing.T) {
+	f := overflowTestFeature(100)
+	p := OverflowPolicy{MaxVertices: 10, Action: OverflowReject}
+
+	err := p.Apply(f)
+	if !errors.Is(err, ErrGeometryOverflow) {
+		t.Fatalf("should reject feature, got %v", err)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(overflowTestFeature(5)).AddFeature(f)
+
+	rejected := p.ApplyCollection(fc)
+	if len(rejected) != 1 || len(fc.Features) != 1 {
+		t.Errorf("should remove rejected feature, got %d rejected and %d kept", len(rejected), len(fc.Features))
+	}
+}
+
+func TestOverflowPolicySimplify(t *testing.T) {
+	f := overflowTestFeature(1000)
+	p := OverflowPolicy{MaxVertices: 50, Action: OverflowSimplify}
+
+	if err := p.Apply(f); err != nil {
+		t.Fatalf("should not reject feature, got %v", err)
+	}
+
+	if n := f.Geometry.VertexCount(); n > 50 || n < 2 {
+		t.Errorf("should fit the budget, got %d vertices", n)
+	}
+
+	if f.PropertyMustString(DefaultOverflowProperty) != "simplified" {
+		t.Errorf("should flag simplified feature")
+	}
+}
+
+func TestOverflowPolicyBoundingBox(t *testing.T) {
+	f := NewMultiPointFeature([]float64{0, 0}, []float64{1, 2}, []float64{3, 1})
+	p := OverflowPolicy{MaxVertices: 2, Action: OverflowSimplify, FlagProperty: "overflow"}
+
+	if err := p.Apply(f); err != nil {
+		t.Fatalf("should not reject feature, got %v", err)
+	}
+
+	if !f.Geometry.IsPolygon() {
+		t.Fatalf("should replace by bbox polygon, got %v", f.Geometry.Type)
+	}
+
+	if f.Geometry.Polygon[0][2][0] != 3 || f.Geometry.Polygon[0][2][1] != 2 {
+		t.Errorf("incorrect bbox polygon, got %v", f.Geometry.Polygon)
+	}
+
+	if f.PropertyMustString("overflow") != "bbox" {
+		t.Errorf("should flag feature replaced by bbox")
+	}
+}
@@ -0,0 +1,335 @@
+package geojson
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultNodeSize is the number of children of the nodes of a packed R-tree,
+// as in FlatGeobuf.
+const DefaultNodeSize = 16
+
+// The layout of a serialized packed R-tree, a header followed by the nodes.
+const (
+	packedRTreeMagic      = "GJRT"
+	packedRTreeHeaderSize = 16
+	packedRTreeNodeSize   = 40 // min x, min y, max x, max y and offset
+)
+
+// A PackedRTree is a static spatial index of the bounding boxes of features,
+// packed in Hilbert order with the node layout of FlatGeobuf. The nodes are
+// kept in their serialized form, so a tree loaded from bytes, possibly
+// memory mapped, is ready to search without being rebuilt. Being static,
+// it is not updated by the edits of the collection, and must be built
+// again after them.
+type PackedRTree struct {
+	numItems    int
+	nodeSize    int
+	levelBounds [][2]int // first and last node of each level, leaves first
+	nodes       []byte
+}
+
+// NewPackedRTree creates and initializes a packed R-tree of the bounding
+// boxes of the features of the collection, each node having up to nodeSize
+// children, DefaultNodeSize if zero. The items are the indexes of the
+// features; features without positions are left out.
+func NewPackedRTree(fc *FeatureCollection, nodeSize int) (*PackedRTree, error) {
+	if nodeSize == 0 {
+		nodeSize = DefaultNodeSize
+	}
+	if nodeSize < 2 || nodeSize > math.MaxUint16 {
+		return nil, fmt.Errorf("node size must be between 2 and %d, got %d", math.MaxUint16, nodeSize)
+	}
+
+	type item struct {
+		bbox    []float64
+		index   int
+		hilbert uint32
+	}
+	var items []item
+	extent := []float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for i, f := range fc.Features {
+		bb := featureBoundingBox(f)
+		if bb == nil {
+			continue
+		}
+		items = append(items, item{bbox: bb, index: i})
+		extent[0] = math.Min(extent[0], bb[0])
+		extent[1] = math.Min(extent[1], bb[1])
+		extent[2] = math.Max(extent[2], bb[2])
+		extent[3] = math.Max(extent[3], bb[3])
+	}
+
+	width, height := extent[2]-extent[0], extent[3]-extent[1]
+	for i := range items {
+		bb := items[i].bbox
+		var x, y uint32
+		if width > 0 {
+			x = uint32(math.Floor(math.MaxUint16 * ((bb[0]+bb[2])/2 - extent[0]) / width))
+		}
+		if height > 0 {
+			y = uint32(math.Floor(math.MaxUint16 * ((bb[1]+bb[3])/2 - extent[1]) / height))
+		}
+		items[i].hilbert = hilbert(x, y)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].hilbert < items[j].hilbert })
+
+	t := newPackedRTree(len(items), nodeSize)
+	if len(items) == 0 {
+		return t, nil
+	}
+
+	leaves := t.levelBounds[0][0]
+	for i, it := range items {
+		t.setNode(leaves+i, it.bbox, uint64(it.index))
+	}
+
+	// each parent covers the nodes it points to, in the level below
+	for level := 0; level < len(t.levelBounds)-1; level++ {
+		parent := t.levelBounds[level+1][0]
+		for child := t.levelBounds[level][0]; child < t.levelBounds[level][1]; child += nodeSize {
+			end := child + nodeSize
+			if end > t.levelBounds[level][1] {
+				end = t.levelBounds[level][1]
+			}
+
+			bb := t.nodeBoundingBox(child)
+			for n := child + 1; n < end; n++ {
+				nb := t.nodeBoundingBox(n)
+				bb[0] = math.Min(bb[0], nb[0])
+				bb[1] = math.Min(bb[1], nb[1])
+				bb[2] = math.Max(bb[2], nb[2])
+				bb[3] = math.Max(bb[3], nb[3])
+			}
+			t.setNode(parent, bb, uint64(child))
+			parent++
+		}
+	}
+
+	return t, nil
+}
+
+// LoadPackedRTree returns the packed R-tree serialized in data by
+// MarshalBinary. The tree reads its nodes from data, which must not be
+// modified while the tree is in use.
+func LoadPackedRTree(data []byte) (*PackedRTree, error) {
+	if len(data) < packedRTreeHeaderSize || string(data[:4]) != packedRTreeMagic {
+		return nil, errors.New("not a packed R-tree")
+	}
+
+	nodeSize := int(binary.LittleEndian.Uint16(data[4:]))
+	numItems := binary.LittleEndian.Uint64(data[8:])
+	if nodeSize < 2 {
+		return nil, fmt.Errorf("invalid packed R-tree node size %d", nodeSize)
+	}
+	if numItems > uint64(len(data)-packedRTreeHeaderSize)/packedRTreeNodeSize {
+		return nil, fmt.Errorf("packed R-tree of %d items is truncated", numItems)
+	}
+
+	levelBounds, numNodes := packedRTreeLevels(int(numItems), nodeSize)
+	if len(data) != packedRTreeHeaderSize+numNodes*packedRTreeNodeSize {
+		return nil, fmt.Errorf("packed R-tree of %d items must be %d bytes, got %d",
+			numItems, packedRTreeHeaderSize+numNodes*packedRTreeNodeSize, len(data))
+	}
+
+	return &PackedRTree{
+		numItems:    int(numItems),
+		nodeSize:    nodeSize,
+		levelBounds: levelBounds,
+		nodes:       data[packedRTreeHeaderSize:],
+	}, nil
+}
+
+// MarshalBinary serializes the tree, a 16 bytes header holding the node size
+// and number of items followed by the nodes as laid out by FlatGeobuf.
+func (t *PackedRTree) MarshalBinary() ([]byte, error) {
+	data := make([]byte, packedRTreeHeaderSize+len(t.nodes))
+	copy(data, packedRTreeMagic)
+	binary.LittleEndian.PutUint16(data[4:], uint16(t.nodeSize))
+	binary.LittleEndian.PutUint64(data[8:], uint64(t.numItems))
+	copy(data[packedRTreeHeaderSize:], t.nodes)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a tree serialized by MarshalBinary, copying the data.
+func (t *PackedRTree) UnmarshalBinary(data []byte) error {
+	loaded, err := LoadPackedRTree(append([]byte(nil), data...))
+	if err != nil {
+		return err
+	}
+	*t = *loaded
+	return nil
+}
+
+// Len returns the number of items in the tree.
+func (t *PackedRTree) Len() int {
+	return t.numItems
+}
+
+// Search returns the items whose bounding boxes intersect the two
+// dimensional bounding box, in index order.
+func (t *PackedRTree) Search(bbox []float64) []int {
+	if t.numItems == 0 || len(bbox) < 4 {
+		return nil
+	}
+
+	type entry struct{ node, level int }
+	var result []int
+	stack := []entry{{0, len(t.levelBounds) - 1}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		end := e.node + t.nodeSize
+		if end > t.levelBounds[e.level][1] {
+			end = t.levelBounds[e.level][1]
+		}
+		for n := e.node; n < end; n++ {
+			bb := t.nodeBoundingBox(n)
+			if bbox[2] < bb[0] || bbox[3] < bb[1] || bbox[0] > bb[2] || bbox[1] > bb[3] {
+				continue
+			}
+
+			offset := t.nodeOffset(n)
+			if e.level == 0 {
+				result = append(result, int(offset))
+				continue
+			}
+
+			// a loaded tree may point anywhere
+			below := t.levelBounds[e.level-1]
+			if offset >= uint64(below[0]) && offset < uint64(below[1]) {
+				stack = append(stack, entry{int(offset), e.level - 1})
+			}
+		}
+	}
+
+	sort.Ints(result)
+	return result
+}
+
+// children returns the nodes below the node at the level, above the leaves.
+func (t *PackedRTree) children(n, level int) []int {
+	first := int(t.nodeOffset(n))
+	end := first + t.nodeSize
+	if end > t.levelBounds[level-1][1] {
+		end = t.levelBounds[level-1][1]
+	}
+
+	children := make([]int, 0, end-first)
+	for c := first; c < end; c++ {
+		children = append(children, c)
+	}
+	return children
+}
+
+func newPackedRTree(numItems, nodeSize int) *PackedRTree {
+	levelBounds, numNodes := packedRTreeLevels(numItems, nodeSize)
+	return &PackedRTree{
+		numItems:    numItems,
+		nodeSize:    nodeSize,
+		levelBounds: levelBounds,
+		nodes:       make([]byte, numNodes*packedRTreeNodeSize),
+	}
+}
+
+// packedRTreeLevels returns the node ranges of the levels, leaves first,
+// the root being the first node, and the number of nodes.
+func packedRTreeLevels(numItems, nodeSize int) ([][2]int, int) {
+	if numItems == 0 {
+		return nil, 0
+	}
+
+	n := numItems
+	numNodes := n
+	levelNumNodes := []int{n}
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+
+	levelBounds := make([][2]int, len(levelNumNodes))
+	n = numNodes
+	for i, size := range levelNumNodes {
+		levelBounds[i] = [2]int{n - size, n}
+		n -= size
+	}
+	return levelBounds, numNodes
+}
+
+func (t *PackedRTree) setNode(i int, bb []float64, offset uint64) {
+	b := t.nodes[i*packedRTreeNodeSize:]
+	for j := 0; j < 4; j++ {
+		binary.LittleEndian.PutUint64(b[j*8:], math.Float64bits(bb[j]))
+	}
+	binary.LittleEndian.PutUint64(b[32:], offset)
+}
+
+func (t *PackedRTree) nodeBoundingBox(i int) []float64 {
+	b := t.nodes[i*packedRTreeNodeSize:]
+	return []float64{
+		math.Float64frombits(binary.LittleEndian.Uint64(b)),
+		math.Float64frombits(binary.LittleEndian.Uint64(b[8:])),
+		math.Float64frombits(binary.LittleEndian.Uint64(b[16:])),
+		math.Float64frombits(binary.LittleEndian.Uint64(b[24:])),
+	}
+}
+
+func (t *PackedRTree) nodeOffset(i int) uint64 {
+	return binary.LittleEndian.Uint64(t.nodes[i*packedRTreeNodeSize+32:])
+}
+
+// hilbert returns the position of the point, with 16 bits coordinates, on
+// the Hilbert curve, using the algorithm of Flatbush.
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00FF00FF
+	i0 = (i0 | (i0 << 4)) & 0x0F0F0F0F
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00FF00FF
+	i1 = (i1 | (i1 << 4)) & 0x0F0F0F0F
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
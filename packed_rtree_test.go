@@ -0,0 +1,109 @@
+package geojson
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPackedRTreeSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	fc := NewFeatureCollection()
+	for i := 0; i < 1000; i++ {
+		x, y := r.Float64()*360-180, r.Float64()*170-85
+		if i%10 == 0 {
+			fc.AddFeature(NewLineStringFeature([][]float64{{x, y}, {x + r.Float64()*5, y + r.Float64()*5}}))
+		} else {
+			fc.AddFeature(NewPointFeature([]float64{x, y}))
+		}
+	}
+	fc.AddFeature(NewFeature(nil))
+
+	tree, err := NewPackedRTree(fc, 0)
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+	if tree.Len() != 1000 {
+		t.Errorf("should skip features without positions, got %d items", tree.Len())
+	}
+
+	for _, bbox := range [][]float64{{-10, -10, 10, 10}, {100, 20, 140, 60}, {-180, -90, 180, 90}, {0, 86, 1, 87}} {
+		var expected []int
+		for i, f := range fc.Features {
+			bb := featureBoundingBox(f)
+			if bb != nil && bb[0] <= bbox[2] && bb[1] <= bbox[3] && bb[2] >= bbox[0] && bb[3] >= bbox[1] {
+				expected = append(expected, i)
+			}
+		}
+
+		if got := tree.Search(bbox); !reflect.DeepEqual(got, expected) {
+			t.Errorf("incorrect search of %v, expected %v, got %v", bbox, expected, got)
+		}
+	}
+}
+
+func TestPackedRTreeBinary(t *testing.T) {
+	fc := NewFeatureCollection()
+	for i := 0; i < 100; i++ {
+		fc.AddFeature(NewPointFeature([]float64{float64(i), float64(i)}))
+	}
+
+	tree, err := NewPackedRTree(fc, 4)
+	if err != nil {
+		t.Fatalf("should build, but got %v", err)
+	}
+
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	// 100 leaves, then 25, 7, 2 and 1 parents
+	if len(data) != packedRTreeHeaderSize+135*packedRTreeNodeSize {
+		t.Errorf("incorrect size, got %d bytes", len(data))
+	}
+
+	loaded, err := LoadPackedRTree(data)
+	if err != nil {
+		t.Fatalf("should load, but got %v", err)
+	}
+	if got := loaded.Search([]float64{9.5, 9.5, 12, 12}); !reflect.DeepEqual(got, []int{10, 11, 12}) {
+		t.Errorf("incorrect search in the loaded tree, got %v", got)
+	}
+
+	var copied PackedRTree
+	if err := copied.UnmarshalBinary(data); err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	data[packedRTreeHeaderSize] = 0xff
+	if copied.Len() != 100 || !reflect.DeepEqual(copied.Search([]float64{0, 0, 1, 1}), []int{0, 1}) {
+		t.Errorf("should copy the data")
+	}
+}
+
+func TestPackedRTreeInvalid(t *testing.T) {
+	if _, err := NewPackedRTree(NewFeatureCollection(), 1); err == nil {
+		t.Errorf("should reject a node size of 1")
+	}
+
+	empty, err := NewPackedRTree(NewFeatureCollection(), 0)
+	if err != nil {
+		t.Fatalf("should build an empty tree, but got %v", err)
+	}
+	if empty.Search([]float64{-180, -90, 180, 90}) != nil {
+		t.Errorf("should find nothing in an empty tree")
+	}
+
+	data, _ := empty.MarshalBinary()
+	if _, err := LoadPackedRTree(data); err != nil {
+		t.Errorf("should load an empty tree, but got %v", err)
+	}
+	if _, err := LoadPackedRTree([]byte("GJRT")); err == nil {
+		t.Errorf("should reject a truncated header")
+	}
+
+	data[8] = 3
+	if _, err := LoadPackedRTree(data); err == nil {
+		t.Errorf("should reject missing nodes")
+	}
+}
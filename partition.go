@@ -0,0 +1,617 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// A PartitionMode sets how features are split between partitions.
+type PartitionMode int
+
+// The partition modes.
+const (
+	// PartitionByCentroid puts every feature as is in the partition of
+	// its centroid.
+	PartitionByCentroid PartitionMode = iota
+
+	// PartitionByClipping puts every feature in all the partitions it
+	// overlaps, with its geometry clipped to the partition. The points of
+	// a multi point are split between their partitions.
+	PartitionByClipping
+)
+
+// MaxPartitionCells bounds the number of cells a feature is clipped into by
+// PartitionByTile and PartitionByGrid, which fail beyond it rather than
+// running out of memory, like with a world wide polygon at a high zoom.
+const MaxPartitionCells = 1 << 20
+
+// webMercatorHalfWorld is half the width of the world in Web Mercator meters.
+const webMercatorHalfWorld = math.Pi * 6378137
+
+// PartitionByTile splits the collection into the XYZ tiles of the zoom
+// level, like those of web maps, keyed by "z/x/y". The partitions share
+// the features, or their properties when clipping, with the collection.
+// Features without geometry are left out.
+func PartitionByTile(fc *FeatureCollection, zoom int, mode PartitionMode) (map[string]*FeatureCollection, error) {
+	if zoom < 0 || zoom > 30 {
+		return nil, fmt.Errorf("zoom %d out of range", zoom)
+	}
+
+	size := 2 * webMercatorHalfWorld / math.Exp2(float64(zoom))
+	grid := mercatorGrid{
+		size:  size,
+		tiles: 1 << uint(zoom),
+		key: func(x, y int) string {
+			return fmt.Sprintf("%d/%d/%d", zoom, x, y)
+		},
+	}
+	return grid.partition(fc, mode)
+}
+
+// PartitionByGrid splits the collection into the square cells of a grid
+// in Web Mercator meters, keyed by "x/y", the column and row of the cell
+// from the origin, northwards. The size of the cells is only true at the
+// equator, and shrinks with the cosine of the latitude. When clipping, the
+// parts of the geometries beyond the ±85.05° latitude limit of Web Mercator
+// are lost. The partitions share the features, or their properties when
+// clipping, with the collection. Features without geometry are left out.
+func PartitionByGrid(fc *FeatureCollection, cellSizeMeters float64, mode PartitionMode) (map[string]*FeatureCollection, error) {
+	if !(cellSizeMeters > 0) || math.IsInf(cellSizeMeters, 1) {
+		return nil, fmt.Errorf("invalid cell size %v", cellSizeMeters)
+	}
+
+	grid := mercatorGrid{
+		size: cellSizeMeters,
+		key: func(x, y int) string {
+			return fmt.Sprintf("%d/%d", x, y)
+		},
+	}
+	return grid.partition(fc, mode)
+}
+
+// PartitionByGrid is PartitionByGrid with cells of cellSizeMeters measured
+// with the Measurer of the options, along the parallel of the center of the
+// bounding box of the collection, rather than at the equator. Their size
+// in Web Mercator meters is the same everywhere, so they are smaller or
+// larger away from that parallel.
+func (o MeasureOptions) PartitionByGrid(fc *FeatureCollection, cellSizeMeters float64, mode PartitionMode) (map[string]*FeatureCollection, error) {
+	var bb []float64
+	for _, f := range fc.Features {
+		if fbb := featureBoundingBox(f); fbb != nil {
+			if bb == nil {
+				bb = append([]float64(nil), fbb...)
+				continue
+			}
+			growBoundingBox(bb, fbb)
+		}
+	}
+	if bb == nil {
+		return PartitionByGrid(fc, cellSizeMeters, mode)
+	}
+
+	// a degree of longitude, in Web Mercator meters and measured
+	lat := math.Max(-webMercatorMaxLatitude, math.Min(webMercatorMaxLatitude, (bb[1]+bb[3])/2))
+	west, _, _, _ := ToWebMercator.Transform(0, lat, 0)
+	east, _, _, _ := ToWebMercator.Transform(1, lat, 0)
+	measured := o.measurer().Distance([]float64{0, lat}, []float64{1, lat})
+	if !(measured > 0) {
+		return nil, fmt.Errorf("invalid distance %v measured at latitude %v", measured, lat)
+	}
+	return PartitionByGrid(fc, cellSizeMeters*(east-west)/measured, mode)
+}
+
+// A mercatorGrid is a grid of square cells in Web Mercator meters. Tiles
+// count rows down from the north edge of the world and are clamped to it,
+// other grids count rows up from the equator.
+type mercatorGrid struct {
+	size  float64
+	tiles int // number of tiles in a row, 0 for other grids
+	key   func(x, y int) string
+}
+
+// cell returns the column and row of the cell of the position.
+func (g mercatorGrid) cell(p []float64) (int, int) {
+	mx, my, _, _ := ToWebMercator.Transform(p[0], math.Max(-90, math.Min(90, p[1])), 0)
+	if g.tiles == 0 {
+		return int(math.Floor(mx / g.size)), int(math.Floor(my / g.size))
+	}
+
+	clamp := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		if i >= g.tiles {
+			return g.tiles - 1
+		}
+		return i
+	}
+	return clamp(int(math.Floor((mx + webMercatorHalfWorld) / g.size))),
+		clamp(int(math.Floor((webMercatorHalfWorld - my) / g.size)))
+}
+
+// bounds returns the longitude/latitude bounding box of the cell.
+func (g mercatorGrid) bounds(x, y int) [4]float64 {
+	minX, maxX := float64(x)*g.size, float64(x+1)*g.size
+	minY, maxY := float64(y)*g.size, float64(y+1)*g.size
+	if g.tiles != 0 {
+		minX, maxX = minX-webMercatorHalfWorld, maxX-webMercatorHalfWorld
+		minY, maxY = webMercatorHalfWorld-maxY, webMercatorHalfWorld-minY
+	}
+
+	west, south, _, _ := FromWebMercator.Transform(minX, minY, 0)
+	east, north, _, _ := FromWebMercator.Transform(maxX, maxY, 0)
+	if g.tiles != 0 {
+		// the edge tiles extend to the poles
+		if y == 0 {
+			north = 90
+		}
+		if y == g.tiles-1 {
+			south = -90
+		}
+	}
+	return [4]float64{west, south, east, north}
+}
+
+func (g mercatorGrid) partition(fc *FeatureCollection, mode PartitionMode) (map[string]*FeatureCollection, error) {
+	result := make(map[string]*FeatureCollection)
+	add := func(x, y int, f *Feature) {
+		key := g.key(x, y)
+		if result[key] == nil {
+			result[key] = NewFeatureCollection()
+		}
+		result[key].Features = append(result[key].Features, f)
+	}
+
+	for i, f := range fc.Features {
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+
+		if mode == PartitionByCentroid {
+			if c := centroid(f.Geometry); c != nil {
+				x, y := g.cell(c)
+				add(x, y, f)
+			}
+			continue
+		}
+
+		bbox := boundingBox(f.Geometry)
+		if bbox == nil {
+			continue
+		}
+		x0, y0 := g.cell([]float64{bbox[0], bbox[1]})
+		x1, y1 := g.cell([]float64{bbox[2], bbox[3]})
+		if y0 > y1 {
+			y0, y1 = y1, y0
+		}
+
+		if g.minCells(f.Geometry) > MaxPartitionCells {
+			return nil, fmt.Errorf("feature %d: more than %d cells", i, MaxPartitionCells)
+		}
+
+		// the cells are split in halves, and only the halves the geometry
+		// is in are visited, down to the cells
+		cells := 0
+		var visit func(geometry *Geometry, x0, y0, x1, y1 int) error
+		visit = func(geometry *Geometry, x0, y0, x1, y1 int) error {
+			clipped := g.clip(geometry, x0, y0, x1, y1)
+			if clipped == nil {
+				return nil
+			}
+			switch {
+			case x0 == x1 && y0 == y1:
+				if cells++; cells > MaxPartitionCells {
+					return fmt.Errorf("feature %d: more than %d cells", i, MaxPartitionCells)
+				}
+				c := *f
+				c.Geometry = clipped
+				c.BoundingBox = nil
+				add(x0, y0, &c)
+				return nil
+			case x1-x0 >= y1-y0:
+				middle := x0 + (x1-x0)/2
+				if err := visit(clipped, x0, y0, middle, y1); err != nil {
+					return err
+				}
+				return visit(clipped, middle+1, y0, x1, y1)
+			}
+			middle := y0 + (y1-y0)/2
+			if err := visit(clipped, x0, y0, x1, middle); err != nil {
+				return err
+			}
+			return visit(clipped, x0, middle+1, x1, y1)
+		}
+		if err := visit(f.Geometry, x0, y0, x1, y1); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// clip returns the part of the geometry in the cells from x0, y0 to x1, y1,
+// nil if none. Points are kept in the cells they belong to only.
+func (g mercatorGrid) clip(geometry *Geometry, x0, y0, x1, y1 int) *Geometry {
+	inCells := func(p []float64) bool {
+		x, y := g.cell(p)
+		return x >= x0 && x <= x1 && y >= y0 && y <= y1
+	}
+
+	switch geometry.Type {
+	case GeometryPoint:
+		if len(geometry.Point) < 2 || !inCells(geometry.Point) {
+			return nil
+		}
+		return geometry
+	case GeometryMultiPoint:
+		var points [][]float64
+		for _, p := range geometry.MultiPoint {
+			if len(p) >= 2 && inCells(p) {
+				points = append(points, p)
+			}
+		}
+		if len(points) == 0 {
+			return nil
+		}
+		return NewMultiPointGeometry(points...)
+	case GeometryCollection:
+		var members []*Geometry
+		for _, m := range geometry.Geometries {
+			if m == nil {
+				continue
+			}
+			if c := g.clip(m, x0, y0, x1, y1); c != nil {
+				members = append(members, c)
+			}
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return NewCollectionGeometry(members...)
+	}
+
+	return clipToBox(geometry, g.rangeBounds(x0, y0, x1, y1))
+}
+
+// minCells returns about the least number of cells the geometry is in,
+// from the area of its polygons and the length of its lines and rings in
+// cells, so the geometries far too large for the grid fail at once.
+func (g mercatorGrid) minCells(geometry *Geometry) float64 {
+	project := func(p []float64) (float64, float64) {
+		x, y, _, _ := ToWebMercator.Transform(p[0], math.Max(-90, math.Min(90, p[1])), 0)
+		return x / g.size, y / g.size
+	}
+
+	length := 0.0
+	forEachSegment(geometry, func(a, b []float64) {
+		if len(a) >= 2 && len(b) >= 2 {
+			ax, ay := project(a)
+			bx, by := project(b)
+			length += math.Hypot(bx-ax, by-ay)
+		}
+	})
+
+	area := 0.0
+	for _, p := range polygons(geometry) {
+		for i, r := range p {
+			ring := make([][]float64, 0, len(r))
+			for _, q := range r {
+				if len(q) >= 2 {
+					x, y := project(q)
+					ring = append(ring, []float64{x, y})
+				}
+			}
+			if i == 0 {
+				area += math.Abs(ringArea2D(ring))
+			} else {
+				area -= math.Abs(ringArea2D(ring))
+			}
+		}
+	}
+
+	// a line crosses at least a cell every √2 cells of length
+	return math.Max(area, 0) + length/math.Sqrt2
+}
+
+// rangeBounds returns the longitude/latitude bounding box of the cells from
+// x0, y0 to x1, y1.
+func (g mercatorGrid) rangeBounds(x0, y0, x1, y1 int) [4]float64 {
+	first, last := g.bounds(x0, y0), g.bounds(x1, y1)
+	return [4]float64{
+		math.Min(first[0], last[0]), math.Min(first[1], last[1]),
+		math.Max(first[2], last[2]), math.Max(first[3], last[3]),
+	}
+}
+
+// ClipToBox returns the part of the geometry in the two dimensional
+// bounding box, of longitudes and latitudes, nil if none. Points on the
+// edges of the box are kept, clipped lines may be split in several lines,
+// and clipped rings follow the edges of the box.
+func ClipToBox(g *Geometry, bbox []float64) *Geometry {
+	if g == nil || len(bbox) < 4 {
+		return nil
+	}
+	box := [4]float64{bbox[0], bbox[1], bbox[2], bbox[3]}
+	if len(bbox) >= 6 {
+		box = [4]float64{bbox[0], bbox[1], bbox[3], bbox[4]}
+	}
+	inBox := func(p []float64) bool {
+		return len(p) >= 2 && p[0] >= box[0] && p[0] <= box[2] && p[1] >= box[1] && p[1] <= box[3]
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if !inBox(g.Point) {
+			return nil
+		}
+		return NewPointGeometry(g.Point)
+	case GeometryMultiPoint:
+		var points [][]float64
+		for _, p := range g.MultiPoint {
+			if inBox(p) {
+				points = append(points, p)
+			}
+		}
+		if len(points) == 0 {
+			return nil
+		}
+		return NewMultiPointGeometry(points...)
+	case GeometryCollection:
+		var members []*Geometry
+		for _, m := range g.Geometries {
+			if c := ClipToBox(m, bbox); c != nil {
+				members = append(members, c)
+			}
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return NewCollectionGeometry(members...)
+	}
+
+	return clipToBox(g, box)
+}
+
+// clipToBox returns the part of the lines or polygons of the geometry in the
+// longitude/latitude box, nil if none. Clipped lines may be split in several
+// lines, and clipped rings follow the edges of the box.
+func clipToBox(g *Geometry, box [4]float64) *Geometry {
+	switch g.Type {
+	case GeometryLineString, GeometryMultiLineString:
+		lines := g.MultiLineString
+		if g.Type == GeometryLineString {
+			lines = [][][]float64{g.LineString}
+		}
+
+		var clipped [][][]float64
+		for _, l := range lines {
+			clipped = append(clipped, clipLine(l, box)...)
+		}
+		switch len(clipped) {
+		case 0:
+			return nil
+		case 1:
+			return NewLineStringGeometry(clipped[0])
+		}
+		return NewMultiLineStringGeometry(clipped...)
+	case GeometryPolygon, GeometryMultiPolygon:
+		var clipped [][][][]float64
+		for _, p := range polygons(g) {
+			var rings [][][]float64
+			for i, r := range p {
+				c := clipRing(r, box)
+				if c == nil {
+					if i == 0 {
+						break
+					}
+					continue
+				}
+				rings = append(rings, c)
+			}
+			if len(rings) > 0 {
+				clipped = append(clipped, rings)
+			}
+		}
+		switch len(clipped) {
+		case 0:
+			return nil
+		case 1:
+			if g.Type == GeometryPolygon {
+				return NewPolygonGeometry(clipped[0])
+			}
+		}
+		return NewMultiPolygonGeometry(clipped...)
+	}
+
+	return nil
+}
+
+// clipLine clips the line to the box with the Liang-Barsky algorithm,
+// returning the pieces inside it.
+func clipLine(line [][]float64, box [4]float64) [][][]float64 {
+	var result [][][]float64
+	var current [][]float64
+	flush := func() {
+		if len(current) >= 2 {
+			result = append(result, current)
+		}
+		current = nil
+	}
+
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		if len(a) < 2 || len(b) < 2 {
+			flush()
+			continue
+		}
+
+		t0, t1, ok := clipSegment(a, b, box)
+		if !ok {
+			flush()
+			continue
+		}
+		if current == nil {
+			current = [][]float64{interpolate(a, b, t0)}
+		}
+		current = append(current, interpolate(a, b, t1))
+		if t1 < 1 {
+			flush()
+		}
+	}
+	flush()
+
+	return result
+}
+
+// clipSegment returns the fractions of the segment ab where it enters
+// and leaves the box, and false if it misses the box.
+func clipSegment(a, b []float64, box [4]float64) (float64, float64, bool) {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	p := [4]float64{-dx, dx, -dy, dy}
+	q := [4]float64{a[0] - box[0], box[2] - a[0], a[1] - box[1], box[3] - a[1]}
+
+	t0, t1 := 0.0, 1.0
+	for i := range p {
+		if p[i] == 0 {
+			if q[i] < 0 {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		t := q[i] / p[i]
+		if p[i] < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+	}
+
+	return t0, t1, t0 <= t1
+}
+
+// clipRing clips the ring to the box with the Sutherland-Hodgman
+// algorithm, returning nil if nothing of it is left.
+func clipRing(ring [][]float64, box [4]float64) [][]float64 {
+	edges := []struct {
+		axis  int
+		value float64
+		above bool
+	}{
+		{0, box[0], true},
+		{0, box[2], false},
+		{1, box[1], true},
+		{1, box[3], false},
+	}
+
+	for _, p := range ring {
+		if len(p) < 2 {
+			return nil
+		}
+	}
+
+	path := ring
+	if len(path) > 1 && samePosition(path[0], path[len(path)-1]) {
+		path = path[:len(path)-1]
+	}
+
+	for _, e := range edges {
+		inside := func(p []float64) bool {
+			if e.above {
+				return p[e.axis] >= e.value
+			}
+			return p[e.axis] <= e.value
+		}
+
+		var result [][]float64
+		for i, b := range path {
+			a := path[(i+len(path)-1)%len(path)]
+			if inside(b) {
+				if !inside(a) {
+					result = append(result, interpolate(a, b, (e.value-a[e.axis])/(b[e.axis]-a[e.axis])))
+				}
+				result = append(result, b)
+			} else if inside(a) {
+				result = append(result, interpolate(a, b, (e.value-a[e.axis])/(b[e.axis]-a[e.axis])))
+			}
+		}
+		path = result
+		if len(path) == 0 {
+			return nil
+		}
+	}
+
+	if len(path) < 3 {
+		return nil
+	}
+	return append(path, path[0])
+}
+
+// interpolate returns the position at the fraction t of the segment ab,
+// with all the coordinates they have in common.
+func interpolate(a, b []float64, t float64) []float64 {
+	if t == 0 {
+		return a
+	}
+	if t == 1 {
+		return b
+	}
+
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	p := make([]float64, n)
+	for i := range p {
+		p[i] = a[i] + t*(b[i]-a[i])
+	}
+	return p
+}
+
+// centroid returns the center of mass of the geometry, in its highest
+// dimension: the area weighted centroid of its polygons, or else the length
+// weighted centroid of its lines, or else the mean of its positions.
+// It returns nil for an empty geometry.
+func centroid(g *Geometry) []float64 {
+	var cx, cy, area float64
+	for _, p := range polygons(g) {
+		for _, r := range p {
+			for i := 1; i < len(r); i++ {
+				a, b := r[i-1], r[i]
+				cross := a[0]*b[1] - b[0]*a[1]
+				area += cross
+				cx += (a[0] + b[0]) * cross
+				cy += (a[1] + b[1]) * cross
+			}
+		}
+	}
+	if area != 0 {
+		return []float64{cx / (3 * area), cy / (3 * area)}
+	}
+
+	var length float64
+	cx, cy = 0, 0
+	forEachSegment(g, func(a, b []float64) {
+		l := math.Hypot(b[0]-a[0], b[1]-a[1])
+		length += l
+		cx += (a[0] + b[0]) / 2 * l
+		cy += (a[1] + b[1]) / 2 * l
+	})
+	if length != 0 {
+		return []float64{cx / length, cy / length}
+	}
+
+	n := 0
+	cx, cy = 0, 0
+	forEachPosition(g, func(p []float64) {
+		if len(p) >= 2 {
+			cx += p[0]
+			cy += p[1]
+			n++
+		}
+	})
+	if n == 0 {
+		return nil
+	}
+	return []float64{cx / float64(n), cy / float64(n)}
+}
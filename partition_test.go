@@ -0,0 +1,193 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPartitionByTileCentroid(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewPointFeature([]float64{4.35, 50.85}))
+	fc.AddFeature(NewPointFeature([]float64{-74.0, 40.7}))
+	fc.AddFeature(NewLineStringFeature([][]float64{{-1, 1}, {3, 1}}))
+	fc.AddFeature(NewFeature(nil))
+
+	parts, err := PartitionByTile(fc, 1, PartitionByCentroid)
+	if err != nil {
+		t.Fatalf("should partition, but got %v", err)
+	}
+
+	expected := map[string]int{"1/1/0": 2, "1/0/0": 1}
+	if len(parts) != len(expected) {
+		t.Fatalf("incorrect partitions, got %v", parts)
+	}
+	for key, n := range expected {
+		if parts[key] == nil || len(parts[key].Features) != n {
+			t.Errorf("incorrect partition %s, got %v", key, parts[key])
+		}
+	}
+	if parts["1/0/0"].Features[0] != fc.Features[1] {
+		t.Errorf("should share the features with the collection")
+	}
+
+	if _, err := PartitionByTile(fc, -1, PartitionByCentroid); err == nil {
+		t.Errorf("should reject a negative zoom")
+	}
+}
+
+func TestPartitionByTileClipping(t *testing.T) {
+	fc := NewFeatureCollection()
+	f := NewPolygonFeature([][][]float64{{{-10, -10}, {10, -10}, {10, 10}, {-10, 10}, {-10, -10}}})
+	f.SetProperty("name", "square")
+	fc.AddFeature(f)
+	fc.AddFeature(NewMultiPointFeature([]float64{-1, 1}, []float64{1, 1}, []float64{2, 2}))
+
+	parts, err := PartitionByTile(fc, 1, PartitionByClipping)
+	if err != nil {
+		t.Fatalf("should partition, but got %v", err)
+	}
+	if len(parts) != 4 {
+		t.Fatalf("should clip into the 4 tiles, got %d", len(parts))
+	}
+
+	nw := parts["1/0/0"].Features[0]
+	if nw.PropertyMustString("name") != "square" {
+		t.Errorf("should keep the properties, got %v", nw.Properties)
+	}
+	bbox := boundingBox(nw.Geometry)
+	if bbox[0] != -10 || bbox[1] != 0 || bbox[2] != 0 || bbox[3] != 10 {
+		t.Errorf("incorrect clipped polygon, got %v", nw.Geometry.Polygon)
+	}
+
+	ne := parts["1/1/0"]
+	if len(ne.Features) != 2 || len(ne.Features[1].Geometry.MultiPoint) != 2 {
+		t.Errorf("should split the multi point, got %+v", ne.Features)
+	}
+	if f.Geometry.Polygon[0][1][0] != 10 {
+		t.Errorf("should not change the original geometry")
+	}
+}
+
+func TestPartitionByGrid(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewLineStringFeature([][]float64{{0.5, 0.5}, {1.5, 0.5}, {1.5, -0.5}}))
+
+	parts, err := PartitionByGrid(fc, 100000, PartitionByClipping)
+	if err != nil {
+		t.Fatalf("should partition, but got %v", err)
+	}
+	for _, key := range []string{"0/0", "1/0", "1/-1"} {
+		if parts[key] == nil {
+			t.Errorf("should have partition %s, got %v", key, parts)
+		}
+	}
+	if len(parts) != 3 {
+		t.Errorf("incorrect number of partitions, got %d", len(parts))
+	}
+
+	line := parts["1/0"].Features[0].Geometry
+	if line.Type != GeometryLineString || len(line.LineString) != 3 {
+		t.Errorf("incorrect clipped line, got %+v", line)
+	}
+
+	if _, err := PartitionByGrid(fc, 0, PartitionByClipping); err == nil {
+		t.Errorf("should reject a zero cell size")
+	}
+}
+
+func TestClipLine(t *testing.T) {
+	box := [4]float64{0, 0, 10, 10}
+	line := [][]float64{{-5, 5}, {5, 5}, {5, 15}, {8, 15}, {8, 5, 1}}
+
+	pieces := clipLine(line, box)
+	if len(pieces) != 2 {
+		t.Fatalf("should have 2 pieces, got %v", pieces)
+	}
+	if !(&Geometry{Type: GeometryMultiLineString, MultiLineString: pieces}).Equal(NewMultiLineStringGeometry(
+		[][]float64{{0, 5}, {5, 5}, {5, 10}},
+		[][]float64{{8, 10}, {8, 5, 1}},
+	)) {
+		t.Errorf("incorrect pieces, got %v", pieces)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		centroid []float64
+	}{
+		{"polygon", NewPolygonGeometry([][][]float64{{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}}}), []float64{2, 1}},
+		{"line", NewLineStringGeometry([][]float64{{0, 0}, {2, 0}, {2, 2}}), []float64{1.5, 0.5}},
+		{"points", NewMultiPointGeometry([]float64{0, 0}, []float64{2, 4}), []float64{1, 2}},
+		{"empty", NewMultiPointGeometry(), nil},
+	}
+
+	for _, tc := range cases {
+		c := centroid(tc.geometry)
+		if len(c) != len(tc.centroid) || (c != nil && (c[0] != tc.centroid[0] || c[1] != tc.centroid[1])) {
+			t.Errorf("incorrect %s centroid, got %v", tc.name, c)
+		}
+	}
+}
+
+func TestClipToBox(t *testing.T) {
+	box := []float64{0, 0, 10, 10}
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		expected *Geometry
+	}{
+		{"point inside", NewPointGeometry([]float64{1, 2}), NewPointGeometry([]float64{1, 2})},
+		{"point on edge", NewPointGeometry([]float64{10, 2}), NewPointGeometry([]float64{10, 2})},
+		{"point outside", NewPointGeometry([]float64{11, 2}), nil},
+		{"multi point", NewMultiPointGeometry([]float64{1, 2}, []float64{-1, 2}), NewMultiPointGeometry([]float64{1, 2})},
+		{"line", NewLineStringGeometry([][]float64{{-5, 5}, {5, 5}}), NewLineStringGeometry([][]float64{{0, 5}, {5, 5}})},
+		{"line outside", NewLineStringGeometry([][]float64{{-5, 5}, {-1, 5}}), nil},
+		{
+			"collection",
+			NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewPointGeometry([]float64{20, 2})),
+			NewCollectionGeometry(NewPointGeometry([]float64{1, 2})),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clipped := ClipToBox(c.geometry, box)
+			if c.expected == nil {
+				if clipped != nil {
+					t.Errorf("should clip it all, got %v", clipped)
+				}
+				return
+			}
+			if !clipped.Equal(c.expected) {
+				t.Errorf("incorrect geometry, got %v", clipped)
+			}
+		})
+	}
+
+	square := NewPolygonGeometry([][][]float64{{{-5, -5}, {5, -5}, {5, 5}, {-5, 5}, {-5, -5}}})
+	clipped := ClipToBox(square, box)
+	if clipped == nil || clipped.Type != GeometryPolygon || math.Abs(ringArea2D(clipped.Polygon[0])) != 25 {
+		t.Errorf("incorrect clipped polygon, got %v", clipped)
+	}
+}
+
+func TestPartitionLargeZoom(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewLineStringFeature([][]float64{{0, 0}, {1, 1}}))
+
+	parts, err := PartitionByTile(fc, 20, PartitionByClipping)
+	if err != nil {
+		t.Fatalf("should partition, but got %v", err)
+	}
+	if len(parts) < 2900 || len(parts) > 6000 {
+		t.Errorf("should only have the tiles along the line, got %d", len(parts))
+	}
+
+	fc = NewFeatureCollection()
+	fc.AddFeature(NewPolygonFeature([][][]float64{{{-170, -80}, {170, -80}, {170, 80}, {-170, 80}, {-170, -80}}}))
+	if _, err := PartitionByTile(fc, 20, PartitionByClipping); err == nil {
+		t.Errorf("should fail beyond the maximum number of cells")
+	}
+}
@@ -0,0 +1,434 @@
+/*
+Package pipeline runs transformations of features described by a
+declarative spec, so they can be configured in a JSON file rather than
+written against the geojson API.
+
+	{
+		"stages": [
+			{"filter": {"property": "highway", "op": "in", "value": ["primary", "secondary"]}},
+			{"clip": {"bbox": [2.5, 49.5, 6.4, 51.5]}},
+			{"simplify": {"tolerance": 0.0001}},
+			{"properties": {"keep": ["name", "highway"], "rename": {"highway": "class"}}},
+			{"reproject": {"from": "EPSG:4326", "to": "EPSG:3857"}}
+		]
+	}
+
+The spec is run on streams of features, one feature at a time, with the
+readers and writers of the convert package:
+
+	spec, err := pipeline.ParseSpec(data)
+	if err != nil {
+		return err
+	}
+	src, err := convert.NewReader(in)
+	if err != nil {
+		return err
+	}
+	dst, err := convert.NewWriter(out, convert.GeoJSONSeq)
+	if err != nil {
+		return err
+	}
+	n, err := pipeline.Run(spec, src, dst)
+*/
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/convert"
+)
+
+// Transformers are the transformers of the reproject stages, keyed by
+// "<from> to <to>", like "EPSG:4326 to EPSG:3857". Add the transformers
+// of other coordinate reference systems before running specs using them.
+var Transformers = map[string]geojson.Transformer{
+	"EPSG:4326 to EPSG:3857": geojson.ToWebMercator,
+	"EPSG:3857 to EPSG:4326": geojson.FromWebMercator,
+}
+
+// A Spec describes the stages a feature goes through, in order.
+type Spec struct {
+	Stages []Stage `json:"stages"`
+}
+
+// A Stage is a step of a spec. Exactly one of its members is set.
+type Stage struct {
+	Filter     *Filter     `json:"filter,omitempty"`
+	Reproject  *Reproject  `json:"reproject,omitempty"`
+	Simplify   *Simplify   `json:"simplify,omitempty"`
+	Properties *Properties `json:"properties,omitempty"`
+	Clip       *Clip       `json:"clip,omitempty"`
+}
+
+// A Filter stage drops the features whose property does not compare to
+// the value with the operator: "==", "!=", "<", "<=", ">", ">=", "in",
+// the value being an array of the allowed values, "exists" or "missing",
+// without value. Numbers compare to numbers and strings to strings,
+// properties of other types only equal values of the same type.
+type Filter struct {
+	Property string      `json:"property"`
+	Operator string      `json:"op"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// A Reproject stage transforms the geometries between coordinate reference
+// systems, see Transformers.
+type Reproject struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// A Simplify stage simplifies the geometries, see geojson.Simplify.
+type Simplify struct {
+	// Tolerance is in the units of the coordinates.
+	Tolerance float64 `json:"tolerance"`
+}
+
+// A Properties stage maps the properties of the features. It keeps the
+// properties listed, if any, drops the ones listed, renames the remaining
+// ones, then sets the given values, in that order. Renames apply all at
+// once, so properties can swap names. Two renames to the same name are
+// rejected, and renaming a property to the name of another one kept as is
+// is an error for the feature.
+type Properties struct {
+	Keep   []string               `json:"keep,omitempty"`
+	Drop   []string               `json:"drop,omitempty"`
+	Rename map[string]string      `json:"rename,omitempty"`
+	Set    map[string]interface{} `json:"set,omitempty"`
+}
+
+// A Clip stage clips the geometries to a bounding box, dropping the
+// features left without geometry, see geojson.ClipToBox.
+type Clip struct {
+	BoundingBox []float64 `json:"bbox"`
+}
+
+// ParseSpec decodes and checks the JSON spec. Unknown members are errors,
+// so a misspelled option is not silently ignored.
+func ParseSpec(data []byte) (*Spec, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	var spec Spec
+	if err := d.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid pipeline spec: %v", err)
+	}
+	if _, err := spec.compile(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Run runs the features of src through the stages of the spec, writing the
+// features left to dst, one at a time, then closes dst. It returns the
+// number of features written. The spec is checked before reading the first
+// feature.
+func Run(spec *Spec, src convert.Reader, dst convert.Writer) (int, error) {
+	stages, err := spec.compile()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for read := 0; ; read++ {
+		f, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+
+		for i, stage := range stages {
+			if f, err = stage(f); err != nil {
+				return n, fmt.Errorf("feature %d: stage %d: %v", read, i, err)
+			}
+			if f == nil {
+				break
+			}
+		}
+		if f == nil {
+			continue
+		}
+
+		if err := dst.Write(f); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, dst.Close()
+}
+
+// A stage transforms a feature, returning nil to drop it.
+type stage func(f *geojson.Feature) (*geojson.Feature, error)
+
+func (s *Spec) compile() ([]stage, error) {
+	if s == nil {
+		return nil, errors.New("no pipeline spec")
+	}
+
+	stages := make([]stage, 0, len(s.Stages))
+	for i, st := range s.Stages {
+		c, err := st.compile()
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %v", i, err)
+		}
+		stages = append(stages, c)
+	}
+	return stages, nil
+}
+
+func (s Stage) compile() (stage, error) {
+	set := 0
+	for _, member := range []bool{s.Filter != nil, s.Reproject != nil, s.Simplify != nil, s.Properties != nil, s.Clip != nil} {
+		if member {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("a stage must have exactly one of filter, reproject, simplify, properties or clip, got %d", set)
+	}
+
+	switch {
+	case s.Filter != nil:
+		return s.Filter.compile()
+	case s.Reproject != nil:
+		return s.Reproject.compile()
+	case s.Simplify != nil:
+		return s.Simplify.compile()
+	case s.Properties != nil:
+		return s.Properties.compile()
+	}
+	return s.Clip.compile()
+}
+
+func (p *Filter) compile() (stage, error) {
+	if p.Property == "" {
+		return nil, errors.New("filter without property")
+	}
+
+	var match func(v interface{}, ok bool) bool
+	switch p.Operator {
+	case "exists", "missing":
+		exists := p.Operator == "exists"
+		match = func(v interface{}, ok bool) bool { return ok == exists }
+	case "in":
+		values, ok := p.Value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter in needs an array of values, got %T", p.Value)
+		}
+		match = func(v interface{}, ok bool) bool {
+			for _, allowed := range values {
+				if c, comparable := compare(v, allowed); ok && comparable && c == 0 {
+					return true
+				}
+			}
+			return false
+		}
+	case "==", "!=", "<", "<=", ">", ">=":
+		op := p.Operator
+		match = func(v interface{}, ok bool) bool {
+			c, comparable := compare(v, p.Value)
+			if !ok || !comparable {
+				return op == "!="
+			}
+			switch op {
+			case "==":
+				return c == 0
+			case "!=":
+				return c != 0
+			case "<":
+				return c < 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			}
+			return c >= 0
+		}
+	default:
+		return nil, fmt.Errorf("unknown filter operator %q", p.Operator)
+	}
+
+	return func(f *geojson.Feature) (*geojson.Feature, error) {
+		v, ok := f.Properties[p.Property]
+		if !match(v, ok) {
+			return nil, nil
+		}
+		return f, nil
+	}, nil
+}
+
+// compare compares the values, false if they are not comparable.
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if x, ok := a.(bool); ok {
+		y, ok := b.(bool)
+		if !ok || x != y {
+			return 1, ok
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (p *Reproject) compile() (stage, error) {
+	if p.From == p.To {
+		return func(f *geojson.Feature) (*geojson.Feature, error) { return f, nil }, nil
+	}
+
+	t, ok := Transformers[p.From+" to "+p.To]
+	if !ok {
+		return nil, fmt.Errorf("no transformer from %s to %s", p.From, p.To)
+	}
+	return func(f *geojson.Feature) (*geojson.Feature, error) {
+		if f.Geometry == nil {
+			return f, nil
+		}
+		return geojson.TransformFeature(f, t)
+	}, nil
+}
+
+func (p *Simplify) compile() (stage, error) {
+	if !(p.Tolerance >= 0) {
+		return nil, fmt.Errorf("invalid simplify tolerance %v", p.Tolerance)
+	}
+	return func(f *geojson.Feature) (*geojson.Feature, error) {
+		if f.Geometry == nil {
+			return f, nil
+		}
+		c := *f
+		c.Geometry = geojson.Simplify(f.Geometry, p.Tolerance)
+		c.BoundingBox = nil
+		return &c, nil
+	}, nil
+}
+
+func (p *Properties) compile() (stage, error) {
+	// sorted, so the same collision is reported for every run
+	from := make([]string, 0, len(p.Rename))
+	for key := range p.Rename {
+		from = append(from, key)
+	}
+	sort.Strings(from)
+	renamedFrom := make(map[string]string, len(p.Rename))
+	for _, key := range from {
+		to := p.Rename[key]
+		if other, ok := renamedFrom[to]; ok {
+			return nil, fmt.Errorf("properties %q and %q both renamed to %q", other, key, to)
+		}
+		renamedFrom[to] = key
+	}
+
+	return func(f *geojson.Feature) (*geojson.Feature, error) {
+		properties := make(map[string]interface{}, len(f.Properties))
+		if len(p.Keep) > 0 {
+			for _, key := range p.Keep {
+				if v, ok := f.Properties[key]; ok {
+					properties[key] = v
+				}
+			}
+		} else {
+			for key, v := range f.Properties {
+				properties[key] = v
+			}
+		}
+		for _, key := range p.Drop {
+			delete(properties, key)
+		}
+
+		renamed := make(map[string]interface{}, len(properties))
+		for key, v := range properties {
+			if _, ok := p.Rename[key]; !ok {
+				renamed[key] = v
+			}
+		}
+		for _, key := range from {
+			v, ok := properties[key]
+			if !ok {
+				continue
+			}
+			to := p.Rename[key]
+			if _, ok := renamed[to]; ok {
+				return nil, fmt.Errorf("renaming property %q overwrites property %q", key, to)
+			}
+			renamed[to] = v
+		}
+		for key, v := range p.Set {
+			renamed[key] = v
+		}
+
+		c := *f
+		c.Properties = renamed
+		return &c, nil
+	}, nil
+}
+
+func (p *Clip) compile() (stage, error) {
+	if len(p.BoundingBox) != 4 || p.BoundingBox[0] > p.BoundingBox[2] || p.BoundingBox[1] > p.BoundingBox[3] {
+		return nil, fmt.Errorf("invalid clip bounding box %v", p.BoundingBox)
+	}
+	return func(f *geojson.Feature) (*geojson.Feature, error) {
+		if f.Geometry == nil {
+			return nil, nil
+		}
+		g := geojson.ClipToBox(f.Geometry, p.BoundingBox)
+		if g == nil {
+			return nil, nil
+		}
+		c := *f
+		c.Geometry = g
+		c.BoundingBox = nil
+		return &c, nil
+	}, nil
+}
@@ -0,0 +1,146 @@
+package pipeline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+	"github.com/fmechant/go.geojson/convert"
+)
+
+func TestRun(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"stages": [
+		{"filter": {"property": "highway", "op": "in", "value": ["primary", "secondary"]}},
+		{"filter": {"property": "lanes", "op": ">=", "value": 2}},
+		{"clip": {"bbox": [0, 0, 10, 10]}},
+		{"simplify": {"tolerance": 0.1}},
+		{"properties": {"keep": ["name", "highway"], "rename": {"highway": "class"}, "set": {"source": "osm"}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("should parse the spec, but got %v", err)
+	}
+
+	input := `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[-5,5],[5,5],[5.01,5],[6,5]]},"properties":{"name":"A1","highway":"primary","lanes":3,"ref":"x"}}
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,1],[2,2]]},"properties":{"name":"B","highway":"residential","lanes":2}}
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,1],[2,2]]},"properties":{"name":"C","highway":"secondary","lanes":1}}
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[20,20],[30,30]]},"properties":{"name":"D","highway":"secondary","lanes":2}}
+{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"name":"E","highway":"secondary","lanes":2}}
+`
+	src, err := convert.NewFormatReader(strings.NewReader(input), convert.GeoJSONSeq)
+	if err != nil {
+		t.Fatalf("should create the reader, but got %v", err)
+	}
+	var buf bytes.Buffer
+	dst, _ := convert.NewWriter(&buf, convert.GeoJSONSeq)
+
+	n, err := Run(spec, src, dst)
+	if err != nil {
+		t.Fatalf("should run, but got %v", err)
+	}
+	if n != 2 {
+		t.Errorf("should write 2 features, got %d", n)
+	}
+
+	expected := `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,5],[6,5]]},"properties":{"class":"primary","name":"A1","source":"osm"}}` + "\n" +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"class":"secondary","name":"E","source":"osm"}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("incorrect output, got %s", buf.String())
+	}
+}
+
+func TestRunReproject(t *testing.T) {
+	spec := &Spec{Stages: []Stage{{Reproject: &Reproject{From: "EPSG:4326", To: "EPSG:3857"}}}}
+	src, _ := convert.NewFormatReader(strings.NewReader(`{"type":"Feature","geometry":{"type":"Point","coordinates":[180,0]},"properties":null}`), convert.GeoJSONSeq)
+	var buf bytes.Buffer
+	dst, _ := convert.NewWriter(&buf, convert.WKT)
+
+	if _, err := Run(spec, src, dst); err != nil {
+		t.Fatalf("should run, but got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "POINT (20037508.34") {
+		t.Errorf("should reproject to Web Mercator, got %s", buf.String())
+	}
+}
+
+func TestFilterOperators(t *testing.T) {
+	f := geojson.NewPointFeature([]float64{1, 2})
+	f.SetProperty("name", "b")
+	f.SetProperty("count", 3.0)
+
+	cases := []struct {
+		filter Filter
+		kept   bool
+	}{
+		{Filter{Property: "name", Operator: "==", Value: "b"}, true},
+		{Filter{Property: "name", Operator: "!=", Value: "b"}, false},
+		{Filter{Property: "name", Operator: "<", Value: "c"}, true},
+		{Filter{Property: "count", Operator: ">", Value: 3.0}, false},
+		{Filter{Property: "count", Operator: "<=", Value: 3}, true},
+		{Filter{Property: "count", Operator: "==", Value: "3"}, false},
+		{Filter{Property: "other", Operator: "!=", Value: 3}, true},
+		{Filter{Property: "other", Operator: "missing"}, true},
+		{Filter{Property: "count", Operator: "exists"}, true},
+		{Filter{Property: "name", Operator: "in", Value: []interface{}{"a", "c"}}, false},
+	}
+
+	for _, c := range cases {
+		s, err := c.filter.compile()
+		if err != nil {
+			t.Fatalf("should compile %v, but got %v", c.filter, err)
+		}
+		kept, _ := s(f)
+		if (kept != nil) != c.kept {
+			t.Errorf("incorrect filter %v, got %v", c.filter, kept != nil)
+		}
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	cases := []string{
+		`{"stages": [{"simplfy": {"tolerance": 1}}]}`,
+		`{"stages": [{}]}`,
+		`{"stages": [{"simplify": {"tolerance": 1}, "clip": {"bbox": [0, 0, 1, 1]}}]}`,
+		`{"stages": [{"filter": {"property": "a", "op": "~"}}]}`,
+		`{"stages": [{"filter": {"property": "a", "op": "in", "value": 1}}]}`,
+		`{"stages": [{"reproject": {"from": "EPSG:4326", "to": "EPSG:2154"}}]}`,
+		`{"stages": [{"clip": {"bbox": [1, 0, 0, 1]}}]}`,
+		`{"stages": [{"simplify": {"tolerance": -1}}]}`,
+		`{"stages": [{"properties": {"rename": {"a": "c", "b": "c"}}}]}`,
+	}
+
+	for _, c := range cases {
+		if _, err := ParseSpec([]byte(c)); err == nil {
+			t.Errorf("should reject %s", c)
+		}
+	}
+}
+
+func TestPropertiesRename(t *testing.T) {
+	f := geojson.NewPointFeature([]float64{1, 2})
+	f.SetProperty("a", 1.0)
+	f.SetProperty("b", 2.0)
+
+	swap, err := (&Properties{Rename: map[string]string{"a": "b", "b": "a"}}).compile()
+	if err != nil {
+		t.Fatalf("should compile, but got %v", err)
+	}
+	swapped, err := swap(f)
+	if err != nil {
+		t.Fatalf("should rename, but got %v", err)
+	}
+	if swapped.Properties["a"] != 2.0 || swapped.Properties["b"] != 1.0 {
+		t.Errorf("should swap the properties, got %v", swapped.Properties)
+	}
+
+	overwrite, err := (&Properties{Rename: map[string]string{"a": "b"}}).compile()
+	if err != nil {
+		t.Fatalf("should compile, but got %v", err)
+	}
+	if _, err := overwrite(f); err == nil {
+		t.Errorf("should not overwrite a property kept as is")
+	}
+	if _, err := overwrite(geojson.NewPointFeature([]float64{1, 2})); err != nil {
+		t.Errorf("should accept features without the properties, but got %v", err)
+	}
+}
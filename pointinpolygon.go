@@ -0,0 +1,167 @@
+package geojson
+
+// A PointLocation describes where a point lies relative to a polygon.
+type PointLocation int
+
+// The possible locations of a point relative to a polygon.
+const (
+	Exterior PointLocation = iota
+	Interior
+	Boundary
+)
+
+// String returns the name of the location.
+func (l PointLocation) String() string {
+	switch l {
+	case Interior:
+		return "Interior"
+	case Boundary:
+		return "Boundary"
+	}
+	return "Exterior"
+}
+
+// PointInPolygonWinding locates the point relative to the polygon using the
+// winding number algorithm (non-zero rule).
+//
+// A point lying exactly on an edge or a vertex of any ring, holes included,
+// is reported as Boundary; the test is done with exact float comparisons so
+// the result is deterministic. Otherwise the point is Interior when the
+// exterior ring winds around it and no hole does. For self-intersecting
+// rings, areas wound around more than once stay Interior.
+func PointInPolygonWinding(point []float64, polygon [][][]float64) PointLocation {
+	return locatePoint(point, polygon, func(p []float64, ring [][]float64) bool {
+		return WindingNumber(p, ring) != 0
+	})
+}
+
+// PointInPolygonCrossing locates the point relative to the polygon using the
+// ray crossing number algorithm (even-odd rule).
+//
+// Boundary handling is the same as for PointInPolygonWinding: a point exactly
+// on an edge or vertex is Boundary. The two functions only differ for
+// self-intersecting rings, where areas wound around an even number of times
+// are Exterior with the crossing rule.
+func PointInPolygonCrossing(point []float64, polygon [][][]float64) PointLocation {
+	return locatePoint(point, polygon, func(p []float64, ring [][]float64) bool {
+		return CrossingNumber(p, ring)%2 == 1
+	})
+}
+
+// WindingNumber returns how many times the ring winds around the point,
+// positive for counterclockwise. The ring does not need to be closed.
+// Points on the ring boundary get an unspecified value, use a
+// PointInPolygon function to detect them.
+func WindingNumber(point []float64, ring [][]float64) int {
+	wn := 0
+	forEachRingEdge(ring, func(a, b []float64) {
+		if a[1] <= point[1] {
+			if b[1] > point[1] && isLeft(a, b, point) > 0 {
+				wn++
+			}
+		} else {
+			if b[1] <= point[1] && isLeft(a, b, point) < 0 {
+				wn--
+			}
+		}
+	})
+	return wn
+}
+
+// CrossingNumber returns how many edges of the ring a horizontal ray cast
+// from the point to the east crosses. Edges are treated as half-open on
+// their upper end, so a ray passing through a vertex is counted once.
+// The ring does not need to be closed.
+func CrossingNumber(point []float64, ring [][]float64) int {
+	cn := 0
+	forEachRingEdge(ring, func(a, b []float64) {
+		if (a[1] <= point[1]) != (b[1] <= point[1]) {
+			x := a[0] + (point[1]-a[1])*(b[0]-a[0])/(b[1]-a[1])
+			if point[0] < x {
+				cn++
+			}
+		}
+	})
+	return cn
+}
+
+func locatePoint(point []float64, polygon [][][]float64, inside func([]float64, [][]float64) bool) PointLocation {
+	if len(point) < 2 || len(polygon) == 0 {
+		return Exterior
+	}
+
+	for _, ring := range polygon {
+		if onRingBoundary(point, ring) {
+			return Boundary
+		}
+	}
+
+	if !inside(point, polygon[0]) {
+		return Exterior
+	}
+
+	for _, hole := range polygon[1:] {
+		if inside(point, hole) {
+			return Exterior
+		}
+	}
+
+	return Interior
+}
+
+func onRingBoundary(point []float64, ring [][]float64) bool {
+	on := false
+	forEachRingEdge(ring, func(a, b []float64) {
+		if !on && onSegment(point, a, b) {
+			on = true
+		}
+	})
+	return on
+}
+
+// onSegment reports whether p lies exactly on the segment a-b.
+func onSegment(p, a, b []float64) bool {
+	if isLeft(a, b, p) != 0 {
+		return false
+	}
+	return p[0] >= minFloat(a[0], b[0]) && p[0] <= maxFloat(a[0], b[0]) &&
+		p[1] >= minFloat(a[1], b[1]) && p[1] <= maxFloat(a[1], b[1])
+}
+
+// isLeft is positive when c is left of the line through a and b,
+// negative when it is right of it and zero when the three are collinear.
+func isLeft(a, b, c []float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (c[0]-a[0])*(b[1]-a[1])
+}
+
+// forEachRingEdge calls fn for every edge of the ring,
+// including the closing edge when the ring is not explicitly closed.
+func forEachRingEdge(ring [][]float64, fn func(a, b []float64)) {
+	n := len(ring)
+	if n < 2 {
+		return
+	}
+
+	for i := 0; i < n-1; i++ {
+		fn(ring[i], ring[i+1])
+	}
+
+	first, last := ring[0], ring[n-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		fn(last, first)
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
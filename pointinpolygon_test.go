@@ -0,0 +1,63 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestPointInPolygon(t *testing.T) {
+	polygon := [][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+	}
+
+	cases := []struct {
+		name     string
+		point    []float64
+		expected PointLocation
+	}{
+		{name: "interior", point: []float64{2, 2}, expected: Interior},
+		{name: "exterior", point: []float64{12, 2}, expected: Exterior},
+		{name: "in hole", point: []float64{5, 5}, expected: Exterior},
+		{name: "on edge", point: []float64{10, 5}, expected: Boundary},
+		{name: "on vertex", point: []float64{0, 0}, expected: Boundary},
+		{name: "on hole edge", point: []float64{5, 4}, expected: Boundary},
+		{name: "level with vertex", point: []float64{-1, 10}, expected: Exterior},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if l := PointInPolygonWinding(tc.point, polygon); l != tc.expected {
+				t.Errorf("winding: expected %v, got %v", tc.expected, l)
+			}
+			if l := PointInPolygonCrossing(tc.point, polygon); l != tc.expected {
+				t.Errorf("crossing: expected %v, got %v", tc.expected, l)
+			}
+		})
+	}
+}
+
+func TestPointInPolygonSelfIntersecting(t *testing.T) {
+	// a pentagram, its center is wound around twice
+	star := [][][]float64{{{0, 10}, {6, -8}, {-9.5, 3}, {9.5, 3}, {-6, -8}, {0, 10}}}
+	center := []float64{0, 0}
+
+	if l := PointInPolygonWinding(center, star); l != Interior {
+		t.Errorf("winding: center should be interior, got %v", l)
+	}
+
+	if l := PointInPolygonCrossing(center, star); l != Exterior {
+		t.Errorf("crossing: center should be exterior, got %v", l)
+	}
+}
+
+func TestWindingNumberOrientation(t *testing.T) {
+	ccw := [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	cw := [][]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+
+	if wn := WindingNumber([]float64{0.5, 0.5}, ccw); wn != 1 {
+		t.Errorf("should be 1 for counterclockwise ring, got %d", wn)
+	}
+	if wn := WindingNumber([]float64{0.5, 0.5}, cw); wn != -1 {
+		t.Errorf("should be -1 for clockwise ring, got %d", wn)
+	}
+}
@@ -0,0 +1,104 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ToPolyline converts a line string or multi point into Google's Encoded
+// Polyline, keeping the given number of decimal digits, from 1 to 10:
+// 5 for the Google APIs, 6 for OSRM and Valhalla. Altitudes are dropped.
+func (g *Geometry) ToPolyline(precision int) (string, error) {
+	if g == nil {
+		return "", errors.New("no geometry to convert to a polyline")
+	}
+	if precision < 1 || precision > 10 {
+		return "", fmt.Errorf("polyline precision must be between 1 and 10, got %d", precision)
+	}
+
+	var path [][]float64
+	switch g.Type {
+	case GeometryLineString:
+		path = g.LineString
+	case GeometryMultiPoint:
+		path = g.MultiPoint
+	default:
+		return "", fmt.Errorf("geometry type %s can not be converted to a polyline", g.Type)
+	}
+
+	factor := math.Pow10(precision)
+	var b strings.Builder
+	var prevLat, prevLon int64
+	for _, p := range path {
+		if len(p) < 2 {
+			return "", fmt.Errorf("position %v needs at least 2 coordinates", p)
+		}
+		if math.IsNaN(p[0]) || math.IsInf(p[0], 0) || math.IsNaN(p[1]) || math.IsInf(p[1], 0) {
+			return "", fmt.Errorf("position %v can not be encoded", p)
+		}
+
+		lat, lon := int64(math.Round(p[1]*factor)), int64(math.Round(p[0]*factor))
+		writePolylineValue(&b, lat-prevLat)
+		writePolylineValue(&b, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+
+	return b.String(), nil
+}
+
+func writePolylineValue(b *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte(0x20|u&0x1f) + 63)
+		u >>= 5
+	}
+	b.WriteByte(byte(u) + 63)
+}
+
+// FromPolyline decodes Google's Encoded Polyline, encoded with the given
+// number of decimal digits, into a line string.
+func FromPolyline(s string, precision int) (*Geometry, error) {
+	if precision < 1 || precision > 10 {
+		return nil, fmt.Errorf("polyline precision must be between 1 and 10, got %d", precision)
+	}
+
+	factor := math.Pow10(precision)
+	var path [][]float64
+	var lat, lon int64
+	for i := 0; i < len(s); {
+		var deltas [2]int64
+		for j := range deltas {
+			var u uint64
+			for shift := uint(0); ; shift += 5 {
+				if i >= len(s) {
+					return nil, errors.New("truncated polyline")
+				}
+				c := s[i] - 63
+				i++
+				if s[i-1] < 63 || c > 0x3f || shift > 60 {
+					return nil, fmt.Errorf("invalid polyline character %q", s[i-1])
+				}
+				u |= uint64(c&0x1f) << shift
+				if c < 0x20 {
+					break
+				}
+			}
+
+			deltas[j] = int64(u >> 1)
+			if u&1 != 0 {
+				deltas[j] = ^deltas[j]
+			}
+		}
+
+		lat += deltas[0]
+		lon += deltas[1]
+		path = append(path, []float64{float64(lon) / factor, float64(lat) / factor})
+	}
+
+	return NewLineStringGeometry(path), nil
+}
@@ -0,0 +1,74 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestToPolyline(t *testing.T) {
+	// the example of the Google polyline algorithm documentation
+	g := NewLineStringGeometry([][]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}})
+
+	s, err := g.ToPolyline(5)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	if s != "_p~iF~ps|U_ulLnnqC_mqNvxq`@" {
+		t.Errorf("incorrect polyline, got %s", s)
+	}
+
+	s, err = NewMultiPointGeometry([]float64{0.000001, 0}, []float64{-0.000002, 0.000001, 10}).ToPolyline(6)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	if s != "?AAD" {
+		t.Errorf("incorrect polyline, got %s", s)
+	}
+
+	invalid := []struct {
+		geometry  *Geometry
+		precision int
+	}{
+		{g, 0},
+		{g, 11},
+		{NewPointGeometry([]float64{1, 2}), 5},
+		{NewLineStringGeometry([][]float64{{1}}), 5},
+	}
+	for _, tc := range invalid {
+		if _, err := tc.geometry.ToPolyline(tc.precision); err == nil {
+			t.Errorf("should reject %s with precision %d", tc.geometry.Type, tc.precision)
+		}
+	}
+}
+
+func TestFromPolyline(t *testing.T) {
+	g, err := FromPolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@", 5)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	expected := NewLineStringGeometry([][]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}})
+	if !g.EqualWithTolerance(expected, 1e-9) {
+		t.Errorf("incorrect line string, got %v", g.LineString)
+	}
+
+	for _, s := range []string{"_p~iF", "_p~iF~ps|", "\x01\x02"} {
+		if _, err := FromPolyline(s, 5); err == nil {
+			t.Errorf("should reject %q", s)
+		}
+	}
+}
+
+func TestPolylineRoundTrip(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{4.351721, 50.850346}, {4.402464, 51.219448}, {-0.127758, 51.507351}})
+
+	s, err := g.ToPolyline(6)
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	decoded, err := FromPolyline(s, 6)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if !decoded.EqualWithTolerance(g, 1e-9) {
+		t.Errorf("should round trip, got %v", decoded.LineString)
+	}
+}
@@ -0,0 +1,118 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// A Problem is an RFC 7807 problem details document, reporting why GeoJSON
+// uploaded to an API was rejected.
+type Problem struct {
+	Type     string `json:"type,omitempty"` // about:blank if empty
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Errors is an extension member locating each error in the document.
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// A ProblemError is one of the errors of a problem.
+type ProblemError struct {
+	// Pointer is the RFC 6901 JSON pointer to the offending member,
+	// empty for the whole document.
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// NewProblem creates and initializes a problem with the HTTP status,
+// usually http.StatusBadRequest or http.StatusUnprocessableEntity, from
+// the errors of decoding or validating a document. Errors with a
+// JSONPointer method, like FeatureError and TopologyError, and the errors
+// of the encoding/json package are located in the document.
+func NewProblem(status int, errs ...error) *Problem {
+	p := &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			p.Errors = append(p.Errors, ProblemError{
+				Pointer: errorPointer(err),
+				Detail:  err.Error(),
+			})
+		}
+	}
+
+	switch len(p.Errors) {
+	case 0:
+	case 1:
+		p.Detail = p.Errors[0].Detail
+	default:
+		p.Detail = fmt.Sprintf("%d errors in the document", len(p.Errors))
+	}
+
+	return p
+}
+
+// ServeHTTP writes the problem as the response, with its status.
+// This fulfills the http.Handler interface.
+func (p *Problem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := p.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// JSONPointer returns the pointer to the feature in its collection, or to
+// the offending member of the feature if the error locates it.
+func (e *FeatureError) JSONPointer() string {
+	pointer := "/features/" + strconv.Itoa(e.Index)
+	var located interface{ JSONPointer() string }
+	if errors.As(e.Err, &located) {
+		pointer += located.JSONPointer()
+	}
+	return pointer
+}
+
+// JSONPointer returns the pointer to the first offending feature.
+func (e TopologyError) JSONPointer() string {
+	if len(e.Features) == 0 {
+		return ""
+	}
+	return "/features/" + strconv.Itoa(e.Features[0])
+}
+
+// errorPointer returns the JSON pointer locating the error, if known.
+func errorPointer(err error) string {
+	var located interface{ JSONPointer() string }
+	if errors.As(err, &located) {
+		return located.JSONPointer()
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return "/" + strings.Replace(typeErr.Field, ".", "/", -1)
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	opts := DecodeOptions{}.WithValidator(func(f *Feature) error {
+		if _, ok := f.Properties["name"]; !ok {
+			return errors.New("missing name")
+		}
+		return nil
+	})
+	_, err := opts.UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":{"name":"a"}},
+		{"type":"Feature","geometry":null,"properties":{}}
+	]}`))
+
+	p := NewProblem(http.StatusUnprocessableEntity, err)
+	if p.Title != "Unprocessable Entity" || p.Status != 422 || p.Detail != "feature 1: missing name" {
+		t.Errorf("incorrect problem, got %+v", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Pointer != "/features/1" {
+		t.Errorf("incorrect errors, got %+v", p.Errors)
+	}
+
+	p = NewProblem(http.StatusUnprocessableEntity,
+		TopologyError{Kind: TopologyOverlap, Features: []int{3, 4}},
+		errors.New("plain"),
+		nil,
+	)
+	if p.Detail != "2 errors in the document" {
+		t.Errorf("incorrect detail, got %q", p.Detail)
+	}
+	if p.Errors[0].Pointer != "/features/3" || p.Errors[1].Pointer != "" {
+		t.Errorf("incorrect pointers, got %+v", p.Errors)
+	}
+}
+
+func TestNewProblemJSONErrors(t *testing.T) {
+	var v struct {
+		Meta struct {
+			Count int `json:"count"`
+		} `json:"meta"`
+	}
+	err := json.Unmarshal([]byte(`{"meta":{"count":"x"}}`), &v)
+
+	p := NewProblem(http.StatusBadRequest, err)
+	if p.Errors[0].Pointer != "/meta/count" {
+		t.Errorf("incorrect pointer, got %q", p.Errors[0].Pointer)
+	}
+}
+
+func TestProblemServeHTTP(t *testing.T) {
+	p := NewProblem(http.StatusUnprocessableEntity, &FeatureError{Index: 2, Err: errors.New("too large")})
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("POST", "/upload", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("incorrect status, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("incorrect content type, got %s", ct)
+	}
+
+	expected := `{"title":"Unprocessable Entity","status":422,"detail":"feature 2: too large","errors":[{"pointer":"/features/2","detail":"feature 2: too large"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("incorrect body, got %s", w.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package geojson
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -13,6 +14,32 @@ func (f *Feature) SetProperty(key string, value interface{}) {
 	f.Properties[key] = value
 }
 
+// DecodeProperties stores the properties of the feature in the value pointed
+// to by dst, typically a struct, using the json tags of its fields.
+func (f *Feature) DecodeProperties(dst interface{}) error {
+	data, err := json.Marshal(f.Properties)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// EncodeProperties replaces the properties of the feature with the members
+// of src, typically a struct, encoded using the json tags of its fields.
+func (f *Feature) EncodeProperties(src interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+
+	var properties map[string]interface{}
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return fmt.Errorf("properties must encode to a JSON object, got %s", data)
+	}
+	f.Properties = properties
+	return nil
+}
+
 // PropertyBool type asserts a property to `bool`.
 func (f *Feature) PropertyBool(key string) (bool, error) {
 	if b, ok := (f.Properties[key]).(bool); ok {
@@ -22,12 +49,16 @@ func (f *Feature) PropertyBool(key string) (bool, error) {
 }
 
 // PropertyInt type asserts a property to `int`.
+// Numbers decoded from JSON or BSON are converted.
 func (f *Feature) PropertyInt(key string) (int, error) {
-	if i, ok := (f.Properties[key]).(int); ok {
+	switch i := f.Properties[key].(type) {
+	case int:
 		return i, nil
-	}
-
-	if i, ok := (f.Properties[key]).(float64); ok {
+	case float64:
+		return int(i), nil
+	case int32:
+		return int(i), nil
+	case int64:
 		return int(i), nil
 	}
 
@@ -35,9 +66,17 @@ func (f *Feature) PropertyInt(key string) (int, error) {
 }
 
 // PropertyFloat64 type asserts a property to `float64`.
+// Integers, as decoded from BSON, are converted.
 func (f *Feature) PropertyFloat64(key string) (float64, error) {
-	if i, ok := (f.Properties[key]).(float64); ok {
+	switch i := f.Properties[key].(type) {
+	case float64:
 		return i, nil
+	case int:
+		return float64(i), nil
+	case int32:
+		return float64(i), nil
+	case int64:
+		return float64(i), nil
 	}
 	return 0, fmt.Errorf("type assertion of `%s` to float64 failed", key)
 }
@@ -69,9 +108,9 @@ func (f *Feature) PropertyMustBool(key string, def ...bool) bool {
 	return defaul
 }
 
-// PropertyMustInt guarantees the return of a `bool` (with optional default)
+// PropertyMustInt guarantees the return of a `int` (with optional default)
 //
-// useful when you explicitly want a `bool` in a single value return context:
+// useful when you explicitly want a `int` in a single value return context:
 //     myFunc(f.PropertyMustInt("param1"), f.PropertyMustInt("optional_param", 123))
 func (f *Feature) PropertyMustInt(key string, def ...int) int {
 	var defaul int
@@ -88,9 +127,9 @@ func (f *Feature) PropertyMustInt(key string, def ...int) int {
 	return defaul
 }
 
-// PropertyMustFloat64 guarantees the return of a `bool` (with optional default)
+// PropertyMustFloat64 guarantees the return of a `float64` (with optional default)
 //
-// useful when you explicitly want a `bool` in a single value return context:
+// useful when you explicitly want a `float64` in a single value return context:
 //     myFunc(f.PropertyMustFloat64("param1"), f.PropertyMustFloat64("optional_param", 10.1))
 func (f *Feature) PropertyMustFloat64(key string, def ...float64) float64 {
 	var defaul float64
@@ -107,9 +146,9 @@ func (f *Feature) PropertyMustFloat64(key string, def ...float64) float64 {
 	return defaul
 }
 
-// PropertyMustString guarantees the return of a `bool` (with optional default)
+// PropertyMustString guarantees the return of a `string` (with optional default)
 //
-// useful when you explicitly want a `bool` in a single value return context:
+// useful when you explicitly want a `string` in a single value return context:
 //     myFunc(f.PropertyMustString("param1"), f.PropertyMustString("optional_param", "default"))
 func (f *Feature) PropertyMustString(key string, def ...string) string {
 	var defaul string
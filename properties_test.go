@@ -185,3 +185,65 @@ func TestFeaturePropertyMustString(t *testing.T) {
 		t.Errorf("should return proper property, without default")
 	}
 }
+
+func TestFeaturePropertyBSONNumbers(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.SetProperty("int32", int32(3))
+	f.SetProperty("int64", int64(4))
+
+	if i, err := f.PropertyInt("int32"); err != nil || i != 3 {
+		t.Errorf("should convert int32, got %v %v", i, err)
+	}
+	if i := f.PropertyMustInt("int64"); i != 4 {
+		t.Errorf("should convert int64, got %v", i)
+	}
+	if v, err := f.PropertyFloat64("int32"); err != nil || v != 3 {
+		t.Errorf("should convert int32 to float64, got %v %v", v, err)
+	}
+	if v := f.PropertyMustFloat64("int64", 1.5); v != 4 {
+		t.Errorf("should convert int64 to float64, got %v", v)
+	}
+}
+
+func TestFeatureDecodeProperties(t *testing.T) {
+	f := propertiesTestFeature()
+
+	var p struct {
+		Bool    bool    `json:"bool"`
+		Int     int     `json:"int"`
+		Float64 float64 `json:"float64"`
+		String  string  `json:"string"`
+	}
+	if err := f.DecodeProperties(&p); err != nil {
+		t.Fatalf("should decode properties, but got %v", err)
+	}
+	if !p.Bool || p.Int != 1 || p.Float64 != 1.2 || p.String != "text" {
+		t.Errorf("should decode all properties, got %+v", p)
+	}
+
+	var wrong struct {
+		String int `json:"string"`
+	}
+	if err := f.DecodeProperties(&wrong); err == nil {
+		t.Errorf("should return error for mismatched types")
+	}
+}
+
+func TestFeatureEncodeProperties(t *testing.T) {
+	f := propertiesTestFeature()
+
+	p := struct {
+		Name  string `json:"name"`
+		Lanes int    `json:"lanes,omitempty"`
+	}{Name: "Main Street"}
+	if err := f.EncodeProperties(p); err != nil {
+		t.Fatalf("should encode properties, but got %v", err)
+	}
+	if len(f.Properties) != 1 || f.PropertyMustString("name") != "Main Street" {
+		t.Errorf("should replace properties, got %v", f.Properties)
+	}
+
+	if err := f.EncodeProperties([]int{1, 2}); err == nil {
+		t.Errorf("should return error for non objects")
+	}
+}
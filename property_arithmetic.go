@@ -0,0 +1,229 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// AddToProperty adds delta to the numeric property, a missing or null
+// property counting as 0. The property keeps its type: integers, as
+// decoded from BSON, stay integers when the result is one and fits, and
+// json.Number values stay json.Number; other results are float64. The
+// property is left unchanged, and an error returned, if it is not a number
+// or the result is not finite.
+func (f *Feature) AddToProperty(key string, delta float64) error {
+	v, ok := f.Properties[key]
+	if !ok || v == nil {
+		v = 0
+	}
+
+	result, err := applyNumber(v, func(x float64) float64 { return x + delta }, func(x int64) (int64, bool) {
+		d, ok := exactInt64(delta)
+		if !ok || (d > 0 && x > math.MaxInt64-d) || (d < 0 && x < math.MinInt64-d) {
+			return 0, false
+		}
+		return x + d, true
+	})
+	if err != nil {
+		return fmt.Errorf("property `%s`: %v", key, err)
+	}
+	f.SetProperty(key, result)
+	return nil
+}
+
+// MultiplyProperty multiplies the numeric property by factor, keeping its
+// type like AddToProperty does. The property must exist.
+func (f *Feature) MultiplyProperty(key string, factor float64) error {
+	v, ok := f.Properties[key]
+	if !ok || v == nil {
+		return fmt.Errorf("property `%s`: no number to multiply", key)
+	}
+
+	result, err := applyNumber(v, func(x float64) float64 { return x * factor }, func(x int64) (int64, bool) {
+		m, ok := exactInt64(factor)
+		if !ok {
+			return 0, false
+		}
+		if x == 0 || m == 0 {
+			return 0, true
+		}
+		r := x * m
+		if r/m != x || (x == -1 && m == math.MinInt64) || (m == -1 && x == math.MinInt64) {
+			return 0, false
+		}
+		return r, true
+	})
+	if err != nil {
+		return fmt.Errorf("property `%s`: %v", key, err)
+	}
+	f.SetProperty(key, result)
+	return nil
+}
+
+// RecalculateProperty sets the numeric property of every feature to the
+// result of fn, called with the feature and the current value of the
+// property, 0 if missing or null. The results keep the types of the values
+// like AddToProperty does. All the values are computed before any is set,
+// so the collection is left unchanged when fn fails or a property is not
+// a number; the error is returned with the index of the feature.
+func (fc *FeatureCollection) RecalculateProperty(key string, fn func(f *Feature, value float64) (float64, error)) error {
+	results := make([]interface{}, len(fc.Features))
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+
+		v, ok := f.Properties[key]
+		if !ok || v == nil {
+			v = 0
+		}
+		current, ok := numberValue(v)
+		if !ok {
+			return fmt.Errorf("feature %d: property `%s` is not a number", i, key)
+		}
+
+		x, err := fn(f, current)
+		if err != nil {
+			return fmt.Errorf("feature %d: %v", i, err)
+		}
+		result, err := applyNumber(v, func(float64) float64 { return x }, func(int64) (int64, bool) {
+			return exactInt64(x)
+		})
+		if err != nil {
+			return fmt.Errorf("feature %d: property `%s`: %v", i, key, err)
+		}
+		results[i] = result
+	}
+
+	for i, f := range fc.Features {
+		if f != nil {
+			f.SetProperty(key, results[i])
+		}
+	}
+	return nil
+}
+
+// numberValue returns the value of the number, and false if v is not one.
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		x, err := n.Float64()
+		return x, err == nil
+	}
+	if i, ok := integerValue(v); ok {
+		return float64(i), true
+	}
+	if u, ok := v.(uint64); ok {
+		return float64(u), true
+	}
+	return 0, false
+}
+
+// integerValue returns the value of an integer that fits an int64, and
+// false if v is not one.
+func integerValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), uint64(n) <= math.MaxInt64
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// exactInt64 returns x as an int64, and false if it is not an integer
+// exactly represented by one.
+func exactInt64(x float64) (int64, bool) {
+	if x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(x), true
+}
+
+// applyNumber applies the operation to the number v, with integer
+// arithmetic when v is an integer and intOp succeeds, and returns the
+// result with the type of v when it can have it, float64 else.
+func applyNumber(v interface{}, op func(float64) float64, intOp func(int64) (int64, bool)) (interface{}, error) {
+	if i, ok := integerValue(v); ok {
+		if r, ok := intOp(i); ok {
+			if result, ok := integerOfType(v, r); ok {
+				return result, nil
+			}
+		}
+	}
+
+	x, ok := numberValue(v)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a number", v)
+	}
+	r := op(x)
+	if math.IsNaN(r) || math.IsInf(r, 0) {
+		return nil, fmt.Errorf("result %v is not a finite number", r)
+	}
+
+	switch v.(type) {
+	case json.Number:
+		return json.Number(strconv.FormatFloat(r, 'g', -1, 64)), nil
+	case float32:
+		if r32 := float32(r); !math.IsInf(float64(r32), 0) {
+			return r32, nil
+		}
+	}
+	if ri, ok := exactInt64(r); ok {
+		if result, ok := integerOfType(v, ri); ok {
+			return result, nil
+		}
+	}
+	return r, nil
+}
+
+// integerOfType returns the integer with the integer type of v, and false
+// if v is not an integer or the type can not hold it.
+func integerOfType(v interface{}, r int64) (interface{}, bool) {
+	switch v.(type) {
+	case int:
+		if r >= math.MinInt32 && r <= math.MaxInt32 || strconv.IntSize == 64 {
+			return int(r), true
+		}
+	case int32:
+		if r >= math.MinInt32 && r <= math.MaxInt32 {
+			return int32(r), true
+		}
+	case int64:
+		return r, true
+	case uint:
+		if r >= 0 && (strconv.IntSize == 64 || r <= math.MaxUint32) {
+			return uint(r), true
+		}
+	case uint32:
+		if r >= 0 && r <= math.MaxUint32 {
+			return uint32(r), true
+		}
+	case uint64:
+		if r >= 0 {
+			return uint64(r), true
+		}
+	case json.Number:
+		if _, err := v.(json.Number).Int64(); err == nil {
+			return json.Number(strconv.FormatInt(r, 10)), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,133 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAddToProperty(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		delta    float64
+		expected interface{}
+	}{
+		{2.5, 1, 3.5},
+		{float32(1.5), 1, float32(2.5)},
+		{int(2), 3, int(5)},
+		{int(2), 0.5, 2.5},
+		{int32(math.MaxInt32), 1, float64(math.MaxInt32) + 1},
+		{int64(math.MaxInt64), 1, float64(math.MaxInt64) + 1},
+		{int64(1) << 60, 1, int64(1)<<60 + 1},
+		{uint32(1), -2, -1.0},
+		{uint64(7), 3, uint64(10)},
+		{json.Number("12"), 30, json.Number("42")},
+		{json.Number("1.5"), 1, json.Number("2.5")},
+		{json.Number("2"), 0.25, json.Number("2.25")},
+		{nil, 1, int(1)},
+	}
+
+	for _, tc := range cases {
+		f := NewFeature(nil)
+		if tc.value != nil {
+			f.SetProperty("n", tc.value)
+		}
+		if err := f.AddToProperty("n", tc.delta); err != nil {
+			t.Errorf("should add %v to %#v, but got %v", tc.delta, tc.value, err)
+			continue
+		}
+		if f.Properties["n"] != tc.expected {
+			t.Errorf("incorrect sum of %#v and %v, expected %#v, got %#v", tc.value, tc.delta, tc.expected, f.Properties["n"])
+		}
+	}
+
+	f := NewFeature(nil)
+	f.SetProperty("s", "12")
+	f.SetProperty("big", math.MaxFloat64)
+	if err := f.AddToProperty("s", 1); err == nil || f.Properties["s"] != "12" {
+		t.Errorf("should not add to a string, got %v", f.Properties["s"])
+	}
+	if err := f.AddToProperty("big", math.MaxFloat64); err == nil || f.Properties["big"] != math.MaxFloat64 {
+		t.Errorf("should not overflow to infinity, got %v", f.Properties["big"])
+	}
+}
+
+func TestMultiplyProperty(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		factor   float64
+		expected interface{}
+	}{
+		{2.5, 2, 5.0},
+		{int(4), 3, int(12)},
+		{int(4), 0.5, int(2)},
+		{int(3), 0.5, 1.5},
+		{int64(math.MaxInt64), 2, float64(math.MaxInt64) * 2},
+		{int64(math.MinInt64), -1, -float64(math.MinInt64)},
+		{json.Number("7"), 6, json.Number("42")},
+		{json.Number("7"), 1.5, json.Number("10.5")},
+	}
+
+	for _, tc := range cases {
+		f := NewFeature(nil)
+		f.SetProperty("n", tc.value)
+		if err := f.MultiplyProperty("n", tc.factor); err != nil {
+			t.Errorf("should multiply %#v by %v, but got %v", tc.value, tc.factor, err)
+			continue
+		}
+		if f.Properties["n"] != tc.expected {
+			t.Errorf("incorrect product of %#v and %v, expected %#v, got %#v", tc.value, tc.factor, tc.expected, f.Properties["n"])
+		}
+	}
+
+	f := NewFeature(nil)
+	if err := f.MultiplyProperty("n", 2); err == nil {
+		t.Errorf("should not multiply a missing property")
+	}
+	f.SetProperty("b", true)
+	if err := f.MultiplyProperty("b", 2); err == nil {
+		t.Errorf("should not multiply a boolean")
+	}
+}
+
+func TestRecalculateProperty(t *testing.T) {
+	fc := NewFeatureCollection()
+	for _, v := range []interface{}{10.0, int64(20), nil} {
+		f := NewFeature(nil)
+		f.SetProperty("area", 2.0)
+		if v != nil {
+			f.SetProperty("population", v)
+		}
+		fc.AddFeature(f)
+	}
+	fc.AddFeature(nil)
+
+	err := fc.RecalculateProperty("population", func(f *Feature, value float64) (float64, error) {
+		return value / f.PropertyMustFloat64("area"), nil
+	})
+	if err != nil {
+		t.Fatalf("should recalculate, but got %v", err)
+	}
+	if fc.Features[0].Properties["population"] != 5.0 || fc.Features[1].Properties["population"] != int64(10) || fc.Features[2].Properties["population"] != int(0) {
+		t.Errorf("incorrect results, got %v, %v and %v", fc.Features[0].Properties, fc.Features[1].Properties, fc.Features[2].Properties)
+	}
+
+	err = fc.RecalculateProperty("population", func(f *Feature, value float64) (float64, error) {
+		if value == 0 {
+			return 0, errors.New("empty")
+		}
+		return value * 2, nil
+	})
+	if err == nil || err.Error() != "feature 2: empty" {
+		t.Errorf("should fail on the third feature, got %v", err)
+	}
+	if fc.Features[0].Properties["population"] != 5.0 {
+		t.Errorf("should leave the collection unchanged, got %v", fc.Features[0].Properties)
+	}
+
+	fc.Features[1].SetProperty("population", "many")
+	if err := fc.RecalculateProperty("population", func(f *Feature, value float64) (float64, error) { return 1, nil }); err == nil {
+		t.Errorf("should reject a string")
+	}
+}
@@ -0,0 +1,106 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProvenanceMember is the foreign member the provenance of geometries is
+// written to, see MarshalOptions.Provenance.
+const ProvenanceMember = "provenance"
+
+// The operations recorded in the provenance of geometries by this package.
+const (
+	// OperationTransform is a TransformGeometry, with the "transformer"
+	// parameter describing the Transformer.
+	OperationTransform = "transform"
+
+	// OperationSimplify is a Simplify, with the "tolerance" parameter.
+	OperationSimplify = "simplify"
+)
+
+// A Transformation is an operation applied to the coordinates of a
+// geometry, like a reprojection or a simplification, with its parameters.
+type Transformation struct {
+	Operation  string                 `json:"operation"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// Provenance returns the transformations applied to the coordinates of the
+// geometry, oldest first, for data lineage. The functions of this package
+// returning transformed copies of geometries, like TransformGeometry and
+// Simplify, record their transformation on the copy. Only the top level
+// geometry of a collection holds the record.
+func (g *Geometry) Provenance() []Transformation {
+	if g == nil {
+		return nil
+	}
+	return g.provenance
+}
+
+// RecordTransformation appends the transformation to the provenance of the
+// geometry, for transformations done outside this package.
+func (g *Geometry) RecordTransformation(t Transformation) {
+	g.provenance = append(g.provenance[:len(g.provenance):len(g.provenance)], t)
+}
+
+// describeTransformer returns the description of the transformer recorded
+// in provenances: its String method if it has one, or its type.
+func describeTransformer(t Transformer) string {
+	if s, ok := t.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", t)
+}
+
+// String describes the transformers of the chain, in order.
+func (c Chain) String() string {
+	names := make([]string, len(c))
+	for i, t := range c {
+		names[i] = describeTransformer(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// String describes the affine transformation by its coefficients.
+func (a Affine) String() string {
+	return fmt.Sprintf("affine %v %v %v %v %v %v", a.A, a.B, a.C, a.D, a.E, a.F)
+}
+
+// withProvenance returns the geometry, or a copy of it sharing the
+// coordinates with its provenance as a foreign member, when the option is
+// set and it has one.
+func (o MarshalOptions) withProvenance(g *Geometry) (*Geometry, error) {
+	if !o.Provenance || g == nil || len(g.provenance) == 0 {
+		return g, nil
+	}
+
+	data, err := json.Marshal(g.provenance)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *g
+	c.ForeignMembers = make(map[string]json.RawMessage, len(g.ForeignMembers)+1)
+	for k, v := range g.ForeignMembers {
+		c.ForeignMembers[k] = v
+	}
+	c.ForeignMembers[ProvenanceMember] = data
+	return &c, nil
+}
+
+// featureWithProvenance returns the feature, or a copy of it with its
+// geometry with its provenance, when the option is set.
+func (o MarshalOptions) featureWithProvenance(f *Feature) (*Feature, error) {
+	if f == nil {
+		return nil, nil
+	}
+	g, err := o.withProvenance(f.Geometry)
+	if err != nil || g == f.Geometry {
+		return f, err
+	}
+	c := *f
+	c.Geometry = g
+	return &c, nil
+}
@@ -0,0 +1,79 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeometryProvenance(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{0, 0}, {1, 0.01}, {2, 0}, {3, 5}})
+	if g.Provenance() != nil {
+		t.Errorf("should have no provenance, got %v", g.Provenance())
+	}
+
+	projected, err := TransformGeometry(g, ToWebMercator)
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	simplified := Simplify(projected, 10)
+	simplified.RecordTransformation(Transformation{Operation: "round", Parameters: map[string]interface{}{"digits": 1}})
+
+	expected := []Transformation{
+		{Operation: OperationTransform, Parameters: map[string]interface{}{"transformer": "EPSG:4326 to EPSG:3857"}},
+		{Operation: OperationSimplify, Parameters: map[string]interface{}{"tolerance": 10.0}},
+		{Operation: "round", Parameters: map[string]interface{}{"digits": 1}},
+	}
+	if !reflect.DeepEqual(simplified.Provenance(), expected) {
+		t.Errorf("incorrect provenance, got %v", simplified.Provenance())
+	}
+	if len(projected.Provenance()) != 1 || g.Provenance() != nil {
+		t.Errorf("should not change the provenance of the sources")
+	}
+	if len(simplified.Clone().Provenance()) != 3 {
+		t.Errorf("should clone the provenance")
+	}
+
+	collection, _ := TransformGeometry(NewCollectionGeometry(NewPointGeometry([]float64{1, 2})), Chain{Affine{A: 1, E: 1}, FromWebMercator})
+	if p := collection.Provenance(); len(p) != 1 || p[0].Parameters["transformer"] != "affine 1 0 0 0 1 0, EPSG:3857 to EPSG:4326" {
+		t.Errorf("incorrect provenance of the collection, got %v", p)
+	}
+	if collection.Geometries[0].Provenance() != nil {
+		t.Errorf("should not record on the members")
+	}
+}
+
+func TestMarshalOptionsProvenance(t *testing.T) {
+	g := NewPointGeometry([]float64{1, 2})
+	g.RecordTransformation(Transformation{Operation: OperationSimplify, Parameters: map[string]interface{}{"tolerance": 0.5}})
+
+	data, err := MarshalOptions{Provenance: true}.MarshalGeometry(g)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"Point","coordinates":[1,2],"provenance":[{"operation":"simplify","parameters":{"tolerance":0.5}}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+	if g.ForeignMembers != nil {
+		t.Errorf("should leave the geometry as it is")
+	}
+
+	data, err = MarshalOptions{}.MarshalGeometry(g)
+	if err != nil || string(data) != `{"type":"Point","coordinates":[1,2]}` {
+		t.Errorf("should not write the provenance by default, got %s", data)
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(NewFeature(g))
+	data, err = MarshalOptions{Provenance: true}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	decoded, err := UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if _, ok := decoded.Features[0].Geometry.ForeignMembers[ProvenanceMember]; !ok {
+		t.Errorf("should write the provenance of features, got %s", data)
+	}
+}
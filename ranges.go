@@ -0,0 +1,106 @@
+package geojson
+
+import (
+	"math"
+)
+
+// A CoordinateRangeMode sets how decoding handles longitudes outside
+// [-180, 180] and latitudes outside [-90, 90].
+type CoordinateRangeMode int
+
+// The coordinate range modes.
+const (
+	// CoordinatesAsIs keeps the coordinates as they are.
+	CoordinatesAsIs CoordinateRangeMode = iota
+
+	// CoordinatesReject rejects the geometries with a coordinate out of
+	// range with a ValidationError of kind ValidationOutOfRange.
+	CoordinatesReject
+
+	// CoordinatesNormalize wraps the longitudes into range, see
+	// Geometry.NormalizeLongitudes, and rejects the geometries with a
+	// latitude out of range, which can not be fixed.
+	CoordinatesNormalize
+)
+
+// NormalizeLongitudes wraps in place the longitudes of the geometry
+// outside [-180, 180] into that range, like 0 to 360 longitudes of some
+// systems, and returns true if any was. The longitudes of bounding boxes
+// are wrapped too, which may make them cross the antimeridian. The members
+// of geometry collections are normalized too.
+//
+// The positions are wrapped one by one, so a line or polygon crossing the
+// antimeridian ends up with segments spanning the whole map.
+func (g *Geometry) NormalizeLongitudes() bool {
+	if g == nil {
+		return false
+	}
+
+	wrapped := wrapBoundingBox(g.BoundingBox)
+	switch g.Type {
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			wrapped = m.NormalizeLongitudes() || wrapped
+		}
+	default:
+		forEachPosition(g, func(p []float64) {
+			if len(p) != 0 && (p[0] < -180 || p[0] > 180) {
+				p[0] = normalizeLongitude(p[0])
+				wrapped = true
+			}
+		})
+	}
+	return wrapped
+}
+
+// normalizeLongitude returns the longitude wrapped into [-180, 180].
+func normalizeLongitude(lon float64) float64 {
+	if lon >= -180 && lon <= 180 {
+		return lon
+	}
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// wrapBoundingBox wraps in place the longitudes of the bounding box into
+// [-180, 180] and returns true if any was.
+func wrapBoundingBox(bb []float64) bool {
+	n := len(bb) / 2
+	if n < 2 || len(bb)%2 != 0 {
+		return false
+	}
+
+	wrapped := false
+	for _, i := range []int{0, n} {
+		if bb[i] < -180 || bb[i] > 180 {
+			bb[i] = normalizeLongitude(bb[i])
+			wrapped = true
+		}
+	}
+	return wrapped
+}
+
+// apply handles the coordinates of the geometry, and its bounding box,
+// according to the mode. The error locates the first position out of range
+// with a pointer relative to the geometry, prefixed by pointer.
+func (m CoordinateRangeMode) apply(g *Geometry, bb []float64, pointer string) error {
+	if m == CoordinatesAsIs || g == nil {
+		return nil
+	}
+	if m == CoordinatesNormalize {
+		g.NormalizeLongitudes()
+		wrapBoundingBox(bb)
+	}
+
+	v := &validator{}
+	v.geometry(g, pointer, false)
+	for _, err := range v.errs {
+		if err.Kind == ValidationOutOfRange {
+			return err
+		}
+	}
+	return nil
+}
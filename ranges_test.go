@@ -0,0 +1,86 @@
+package geojson
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeLongitude(t *testing.T) {
+	cases := []struct {
+		lon, expected float64
+	}{
+		{0, 0},
+		{180, 180},
+		{-180, -180},
+		{190, -170},
+		{360, 0},
+		{350, -10},
+		{-190, 170},
+		{540, -180},
+	}
+
+	for _, c := range cases {
+		if got := normalizeLongitude(c.lon); got != c.expected {
+			t.Errorf("incorrect longitude for %v, got %v", c.lon, got)
+		}
+	}
+}
+
+func TestGeometryNormalizeLongitudes(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewLineStringGeometry([][]float64{{350, 10}, {355, 20, 100}}),
+		NewPointGeometry([]float64{10, 10}),
+	)
+	g.Geometries[0].BoundingBox = []float64{350, 10, 355, 20}
+
+	if !g.NormalizeLongitudes() {
+		t.Fatalf("should wrap the longitudes")
+	}
+	if !reflect.DeepEqual(g.Geometries[0].LineString, [][]float64{{-10, 10}, {-5, 20, 100}}) {
+		t.Errorf("incorrect line, got %v", g.Geometries[0].LineString)
+	}
+	if !reflect.DeepEqual(g.Geometries[0].BoundingBox, []float64{-10, 10, -5, 20}) {
+		t.Errorf("incorrect bounding box, got %v", g.Geometries[0].BoundingBox)
+	}
+	if g.NormalizeLongitudes() {
+		t.Errorf("should not wrap twice")
+	}
+}
+
+func TestDecodeOptionsCoordinateRange(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[10,10]},"properties":null},
+		{"type":"Feature","bbox":[350,0,355,5],"geometry":{"type":"MultiPoint","coordinates":[[350,0],[355,5]]},"properties":null}
+	]}`)
+
+	fc, err := DecodeOptions{}.UnmarshalFeatureCollection(data)
+	if err != nil || fc.Features[1].Geometry.MultiPoint[0][0] != 350 {
+		t.Fatalf("should keep the coordinates, but got %v", err)
+	}
+
+	_, err = DecodeOptions{CoordinateRange: CoordinatesReject}.UnmarshalFeatureCollection(data)
+	if err == nil {
+		t.Fatalf("should reject the longitudes")
+	}
+	p := NewProblem(http.StatusUnprocessableEntity, err)
+	if p.Errors[0].Pointer != "/features/1/geometry/coordinates/0" {
+		t.Errorf("incorrect pointer, got %q", p.Errors[0].Pointer)
+	}
+
+	fc, err = DecodeOptions{CoordinateRange: CoordinatesNormalize}.UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("should normalize, but got %v", err)
+	}
+	if !reflect.DeepEqual(fc.Features[1].Geometry.MultiPoint, [][]float64{{-10, 0}, {-5, 5}}) {
+		t.Errorf("incorrect coordinates, got %v", fc.Features[1].Geometry.MultiPoint)
+	}
+	if !reflect.DeepEqual(fc.Features[1].BoundingBox, []float64{-10, 0, -5, 5}) {
+		t.Errorf("incorrect bounding box, got %v", fc.Features[1].BoundingBox)
+	}
+
+	_, err = DecodeOptions{CoordinateRange: CoordinatesNormalize}.UnmarshalGeometry([]byte(`{"type":"Point","coordinates":[370,95]}`))
+	if verr, ok := err.(ValidationError); !ok || verr.Kind != ValidationOutOfRange || verr.Pointer != "/coordinates" {
+		t.Errorf("should reject the latitude, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package geojson
+
+import (
+	"encoding/json"
+)
+
+// AccessMember is the foreign member listing the roles allowed to see a
+// feature, see Feature.SetAccess.
+const AccessMember = "access"
+
+// SetAccess tags the feature with the roles allowed to see it, written as
+// its "access" foreign member. Without roles, the tag is removed and every
+// role sees the feature.
+func (f *Feature) SetAccess(roles ...string) {
+	if len(roles) == 0 {
+		delete(f.ForeignMembers, AccessMember)
+		return
+	}
+
+	data, _ := json.Marshal(roles)
+	if f.ForeignMembers == nil {
+		f.ForeignMembers = make(map[string]json.RawMessage)
+	}
+	f.ForeignMembers[AccessMember] = data
+}
+
+// Access returns the roles allowed to see the feature, nil if every role
+// does.
+func (f *Feature) Access() []string {
+	var roles []string
+	if err := json.Unmarshal(f.ForeignMembers[AccessMember], &roles); err != nil {
+		return nil
+	}
+	return roles
+}
+
+// A RedactionPolicy sets what the consumers of a role see of features, so
+// the same collection can be served to consumers of different roles, like
+// public and internal ones, by keeping a policy per role.
+type RedactionPolicy struct {
+	// Role is the role of the consumers. Features tagged with the roles
+	// allowed to see them, see Feature.SetAccess, are left out unless the
+	// role is one of them.
+	Role string
+
+	// Properties lists the properties the role sees, the others are
+	// removed, unless AllProperties is set. The foreign members of the
+	// features are removed too, unless AllProperties is set.
+	Properties    []string
+	AllProperties bool
+
+	// Tolerance generalizes the geometries with Simplify, in coordinate
+	// units, if positive. Their bounding boxes, and those of the features,
+	// are computed again.
+	Tolerance float64
+}
+
+// Redact returns a copy of the collection with what the role of the policy
+// sees: the features it is allowed to see, with their allowed properties
+// and generalized geometries. It shares nothing with the collection, whose
+// bounding box is computed again, if it has one, not to reveal the extent
+// of the features left out.
+func Redact(fc *FeatureCollection, policy RedactionPolicy) *FeatureCollection {
+	c := &FeatureCollection{
+		Type:           fc.Type,
+		Features:       make([]*Feature, 0, len(fc.Features)),
+		CRS:            cloneProperties(fc.CRS),
+		ForeignMembers: cloneForeignMembers(fc.ForeignMembers),
+	}
+	for _, f := range fc.Features {
+		if f != nil && policy.allows(f) {
+			c.Features = append(c.Features, policy.redact(f))
+		}
+	}
+
+	if fc.BoundingBox != nil {
+		c.ComputeBoundingBox()
+	}
+	return c
+}
+
+// allows returns true if the role of the policy is allowed to see the
+// feature.
+func (p RedactionPolicy) allows(f *Feature) bool {
+	roles := f.Access()
+	if roles == nil {
+		return true
+	}
+	for _, role := range roles {
+		if role == p.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns a copy of the feature with what the role of the policy
+// sees.
+func (p RedactionPolicy) redact(f *Feature) *Feature {
+	c := f.Clone()
+	delete(c.ForeignMembers, AccessMember)
+	if !p.AllProperties {
+		c.ForeignMembers = nil
+		c.Properties = make(map[string]interface{}, len(p.Properties))
+		for _, key := range p.Properties {
+			if v, ok := f.Properties[key]; ok {
+				c.Properties[key] = cloneValue(v)
+			}
+		}
+	}
+
+	if p.Tolerance > 0 && c.Geometry != nil {
+		c.Geometry = Simplify(c.Geometry, p.Tolerance)
+		c.Geometry.recomputeBoundingBoxes()
+		if c.BoundingBox != nil {
+			c.ComputeBoundingBox()
+		}
+	}
+	return c
+}
+
+// recomputeBoundingBoxes computes again the bounding boxes of the geometry
+// and of its members that have one.
+func (g *Geometry) recomputeBoundingBoxes() {
+	for _, m := range g.Geometries {
+		m.recomputeBoundingBoxes()
+	}
+	if g.BoundingBox != nil {
+		g.ComputeBoundingBox()
+	}
+}
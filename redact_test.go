@@ -0,0 +1,85 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFeatureSetAccess(t *testing.T) {
+	f := NewPointFeature([]float64{0, 0})
+	if f.Access() != nil {
+		t.Errorf("should have no access roles")
+	}
+
+	f.SetAccess("internal", "audit")
+	if !reflect.DeepEqual(f.Access(), []string{"internal", "audit"}) {
+		t.Errorf("incorrect access roles, got %v", f.Access())
+	}
+	data, err := json.Marshal(f)
+	if err != nil || string(data) != `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":null,"access":["internal","audit"]}` {
+		t.Errorf("incorrect JSON, got %s, %v", data, err)
+	}
+
+	f.SetAccess()
+	if f.Access() != nil || len(f.ForeignMembers) != 0 {
+		t.Errorf("should remove the access roles")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	public := NewLineStringFeature([][]float64{{0, 0}, {1, 0.01}, {2, 0}})
+	public.BoundingBox = []float64{0, 0, 2, 0.01}
+	public.Properties["name"] = "road"
+	public.Properties["owner"] = "someone"
+	public.ForeignMembers = map[string]json.RawMessage{"note": json.RawMessage(`"private"`)}
+
+	internal := NewPointFeature([]float64{10, 10})
+	internal.Properties["name"] = "depot"
+	internal.SetAccess("internal")
+
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{0, 0, 10, 10}
+	fc.AddFeature(public)
+	fc.AddFeature(internal)
+
+	redacted := Redact(fc, RedactionPolicy{Role: "public", Properties: []string{"name"}, Tolerance: 0.1})
+	if len(redacted.Features) != 1 {
+		t.Fatalf("should leave out the internal feature, got %d features", len(redacted.Features))
+	}
+	f := redacted.Features[0]
+	if !reflect.DeepEqual(f.Properties, map[string]interface{}{"name": "road"}) {
+		t.Errorf("incorrect properties, got %v", f.Properties)
+	}
+	if f.ForeignMembers != nil {
+		t.Errorf("should remove the foreign members, got %v", f.ForeignMembers)
+	}
+	if !reflect.DeepEqual(f.Geometry.LineString, [][]float64{{0, 0}, {2, 0}}) {
+		t.Errorf("should generalize the geometry, got %v", f.Geometry.LineString)
+	}
+	if !reflect.DeepEqual(f.BoundingBox, []float64{0, 0, 2, 0}) {
+		t.Errorf("incorrect feature bounding box, got %v", f.BoundingBox)
+	}
+	if !reflect.DeepEqual(redacted.BoundingBox, []float64{0, 0, 2, 0}) {
+		t.Errorf("should not reveal the internal feature, got %v", redacted.BoundingBox)
+	}
+
+	redacted = Redact(fc, RedactionPolicy{Role: "internal", AllProperties: true})
+	if len(redacted.Features) != 2 {
+		t.Fatalf("should keep every feature, got %d features", len(redacted.Features))
+	}
+	if len(redacted.Features[0].Properties) != 2 || redacted.Features[0].ForeignMembers["note"] == nil {
+		t.Errorf("should keep everything, got %+v", redacted.Features[0])
+	}
+	if redacted.Features[1].Access() != nil {
+		t.Errorf("should remove the access roles")
+	}
+	if len(redacted.Features[0].Geometry.LineString) != 3 {
+		t.Errorf("should keep the geometry as it is")
+	}
+
+	redacted.Features[0].Properties["name"] = "changed"
+	if public.Properties["name"] != "road" || internal.Access() == nil {
+		t.Errorf("should leave the collection as it is")
+	}
+}
@@ -0,0 +1,356 @@
+package geojson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sync"
+)
+
+// regionKeyProperty holds the region keys in the encoded index.
+const regionKeyProperty = "key"
+
+// regionIndexMagic starts the encoded indexes holding their packed R-tree.
+const regionIndexMagic = "GJRI"
+
+// A RegionIndex finds the region, like a country or time zone,
+// a point lies in, from a collection of polygon features. The bounding
+// boxes of the regions are searched with a packed R-tree.
+type RegionIndex struct {
+	regions []region
+	tree    *PackedRTree
+}
+
+type region struct {
+	key      string
+	bbox     []float64
+	polygons [][][][]float64
+}
+
+// NewRegionIndex creates and initializes a region index from the polygon and
+// multi polygon features of the collection, keyed by a string property.
+// Features without polygons are skipped, and a missing key is an error.
+func NewRegionIndex(fc *FeatureCollection, keyProperty string) (*RegionIndex, error) {
+	regions, err := regionsOf(fc, keyProperty)
+	if err != nil {
+		return nil, err
+	}
+	r := &RegionIndex{regions: regions}
+	return r, r.buildTree()
+}
+
+// regionsOf returns the regions of the polygon and multi polygon features
+// of the collection, see NewRegionIndex.
+func regionsOf(fc *FeatureCollection, keyProperty string) ([]region, error) {
+	var regions []region
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+
+		polygons := polygons(f.Geometry)
+		if len(polygons) == 0 {
+			continue
+		}
+
+		key, err := f.PropertyString(keyProperty)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+
+		regions = append(regions, region{
+			key:      key,
+			bbox:     boundingBox(f.Geometry),
+			polygons: polygons,
+		})
+	}
+
+	return regions, nil
+}
+
+// buildTree indexes the bounding boxes of the regions.
+func (r *RegionIndex) buildTree() error {
+	fc := NewFeatureCollection()
+	for _, reg := range r.regions {
+		fc.Features = append(fc.Features, &Feature{Type: "Feature", BoundingBox: reg.bbox})
+	}
+
+	tree, err := NewPackedRTree(fc, 0)
+	if err != nil {
+		return err
+	}
+	r.tree = tree
+	return nil
+}
+
+// Lookup returns the key of the first region containing the point,
+// boundaries included.
+func (r *RegionIndex) Lookup(point []float64) (string, bool) {
+	if len(point) < 2 {
+		return "", false
+	}
+
+	if r.tree == nil {
+		return "", false
+	}
+	for _, i := range r.tree.Search([]float64{point[0], point[1], point[0], point[1]}) {
+		if i >= len(r.regions) {
+			continue
+		}
+		reg := r.regions[i]
+		for _, p := range reg.polygons {
+			if PointInPolygonWinding(point, p) != Exterior {
+				return reg.key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Len returns the number of regions in the index.
+func (r *RegionIndex) Len() int {
+	return len(r.regions)
+}
+
+// MarshalBinary encodes the index, gzip compressed, compact enough to be
+// embedded in a program, for example as a generated byte slice: its packed
+// R-tree, see PackedRTree.MarshalBinary, followed by its regions as
+// GeoJSON. This fulfills the encoding.BinaryMarshaler interface.
+func (r *RegionIndex) MarshalBinary() ([]byte, error) {
+	fc := NewFeatureCollection()
+	for _, reg := range r.regions {
+		f := NewMultiPolygonFeature(reg.polygons...)
+		f.SetProperty(regionKeyProperty, reg.key)
+		fc.Features = append(fc.Features, f)
+	}
+
+	data, err := fc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if r.tree == nil {
+		if err := r.buildTree(); err != nil {
+			return nil, err
+		}
+	}
+	tree, err := r.tree.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	header := make([]byte, len(regionIndexMagic)+4)
+	copy(header, regionIndexMagic)
+	binary.LittleEndian.PutUint32(header[len(regionIndexMagic):], uint32(len(tree)))
+	for _, b := range [][]byte{header, tree, data} {
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an index encoded by MarshalBinary, without
+// building its packed R-tree again. Indexes encoded as gzip compressed
+// GeoJSON only, by earlier versions, are indexed when decoded.
+// This fulfills the encoding.BinaryUnmarshaler interface.
+func (r *RegionIndex) UnmarshalBinary(data []byte) error {
+	z, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	data, err = ioutil.ReadAll(z)
+	if err != nil {
+		return err
+	}
+
+	var tree *PackedRTree
+	if bytes.HasPrefix(data, []byte(regionIndexMagic)) {
+		header := len(regionIndexMagic) + 4
+		if len(data) < header {
+			return errors.New("truncated region index")
+		}
+		n := binary.LittleEndian.Uint32(data[len(regionIndexMagic):])
+		if uint64(n) > uint64(len(data)-header) {
+			return fmt.Errorf("region index tree of %d bytes is truncated", n)
+		}
+		if tree, err = LoadPackedRTree(data[header : header+int(n)]); err != nil {
+			return err
+		}
+		data = data[header+int(n):]
+	}
+
+	fc, err := UnmarshalFeatureCollection(data)
+	if err != nil {
+		return err
+	}
+	regions, err := regionsOf(fc, regionKeyProperty)
+	if err != nil {
+		return err
+	}
+	index := &RegionIndex{regions: regions, tree: tree}
+	if tree == nil {
+		if err := index.buildTree(); err != nil {
+			return err
+		}
+	} else if tree.Len() != len(regions) {
+		return fmt.Errorf("region index tree has %d items for %d regions", tree.Len(), len(regions))
+	}
+
+	*r = *index
+	return nil
+}
+
+// An embeddedIndex is a region index encoded by MarshalBinary in a file
+// generated by internal/cmd/regiongen, decoded on first use.
+type embeddedIndex struct {
+	data  []byte
+	once  sync.Once
+	index *RegionIndex
+	err   error
+}
+
+// The indexes of the generated files built with the geojson_countries and
+// geojson_timezones tags, empty otherwise.
+var embeddedCountries, embeddedTimezones embeddedIndex
+
+// get returns the decoded index, nil if none is embedded.
+func (e *embeddedIndex) get() (*RegionIndex, error) {
+	e.once.Do(func() {
+		if len(e.data) == 0 {
+			return
+		}
+		index := &RegionIndex{}
+		if e.err = index.UnmarshalBinary(e.data); e.err == nil {
+			e.index = index
+		}
+	})
+	return e.index, e.err
+}
+
+var (
+	countriesMu sync.RWMutex
+	countries   *RegionIndex
+)
+
+// SetCountryBoundaries sets the boundaries used by CountryOf, polygon features
+// with their ISO 3166-1 alpha-2 code in the given property, for example from
+// a low resolution world boundaries dataset. They replace the embedded ones.
+//
+// Boundaries are embedded by building with the geojson_countries tag, once
+// countries_data.go is generated from a dataset like the 1:110m countries of
+// Natural Earth:
+//
+//	go run ./internal/cmd/regiongen -in ne_110m_admin_0_countries.geojson \
+//		-key ISO_A2 -var embeddedCountries -tag geojson_countries -out countries_data.go
+//
+// The repository does not ship the generated file.
+func SetCountryBoundaries(fc *FeatureCollection, isoProperty string) error {
+	index, err := NewRegionIndex(fc, isoProperty)
+	if err != nil {
+		return err
+	}
+	if index.Len() == 0 {
+		return errors.New("no country boundaries in the collection")
+	}
+
+	countriesMu.Lock()
+	countries = index
+	countriesMu.Unlock()
+	return nil
+}
+
+// CountryOf returns the ISO 3166-1 alpha-2 code of the country the point lies in.
+// It returns false if the point is in no country, or no boundaries were set
+// with SetCountryBoundaries or embedded.
+func CountryOf(point []float64) (string, bool) {
+	countriesMu.RLock()
+	index := countries
+	countriesMu.RUnlock()
+
+	if index == nil {
+		index, _ = embeddedCountries.get()
+	}
+	if index == nil {
+		return "", false
+	}
+	return index.Lookup(point)
+}
+
+// ErrNoTimezoneIndex is returned by TimezoneOf when no index was set or
+// embedded.
+var ErrNoTimezoneIndex = errors.New("no time zone index set")
+
+var (
+	timezonesMu sync.RWMutex
+	timezones   *RegionIndex
+)
+
+// SetTimezoneIndex sets the index used by TimezoneOf, keyed by IANA time zone
+// names like "Europe/Brussels", replacing the embedded one. It is typically
+// decoded with UnmarshalBinary from a copy of the time zone boundaries.
+//
+// An index is embedded by building with the geojson_timezones tag, once
+// timezones_data.go is generated from the boundaries of
+// timezone-boundary-builder, simplified first to keep it small:
+//
+//	go run ./internal/cmd/regiongen -in combined.json -key tzid \
+//		-var embeddedTimezones -tag geojson_timezones -out timezones_data.go
+//
+// The repository does not ship the generated file.
+func SetTimezoneIndex(index *RegionIndex) {
+	timezonesMu.Lock()
+	timezones = index
+	timezonesMu.Unlock()
+}
+
+// TimezoneOf returns the IANA name of the time zone the point lies in.
+// Points outside all time zone boundaries, like at sea, get the nautical
+// time zone of their longitude, like "Etc/GMT-2".
+func TimezoneOf(point []float64) (string, error) {
+	if len(point) < 2 {
+		return "", fmt.Errorf("point needs at least 2 coordinates, got %d", len(point))
+	}
+
+	timezonesMu.RLock()
+	index := timezones
+	timezonesMu.RUnlock()
+
+	if index == nil {
+		var err error
+		if index, err = embeddedTimezones.get(); err != nil {
+			return "", fmt.Errorf("invalid embedded time zone index: %v", err)
+		}
+	}
+	if index == nil {
+		return "", ErrNoTimezoneIndex
+	}
+	if name, ok := index.Lookup(point); ok {
+		return name, nil
+	}
+
+	return nauticalTimezone(point[0]), nil
+}
+
+// nauticalTimezone returns the Etc zone of the longitude,
+// whose sign is inverted by POSIX convention.
+func nauticalTimezone(lon float64) string {
+	offset := int(math.Round(lon / 15))
+	switch {
+	case offset == 0:
+		return "Etc/GMT"
+	case offset > 0:
+		return fmt.Sprintf("Etc/GMT-%d", offset)
+	default:
+		return fmt.Sprintf("Etc/GMT+%d", -offset)
+	}
+}
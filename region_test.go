@@ -0,0 +1,180 @@
+package geojson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func regionTestCollection() *FeatureCollection {
+	fc := NewFeatureCollection()
+
+	a := NewPolygonFeature([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	a.SetProperty("iso", "AA")
+	fc.AddFeature(a)
+
+	b := NewMultiPolygonFeature(
+		[][][]float64{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}},
+		[][][]float64{{{30, 30}, {31, 30}, {31, 31}, {30, 30}}},
+	)
+	b.SetProperty("iso", "BB")
+	fc.AddFeature(b)
+
+	fc.AddFeature(NewPointFeature([]float64{5, 5}))
+	return fc
+}
+
+func TestRegionIndex(t *testing.T) {
+	r, err := NewRegionIndex(regionTestCollection(), "iso")
+	if err != nil {
+		t.Fatalf("should create index, but got %v", err)
+	}
+	if r.Len() != 2 {
+		t.Errorf("should skip features without polygons, got %v", r.Len())
+	}
+
+	cases := []struct {
+		point []float64
+		key   string
+		ok    bool
+	}{
+		{[]float64{5, 5}, "AA", true},
+		{[]float64{15, 5}, "BB", true},
+		{[]float64{30.8, 30.2}, "BB", true},
+		{[]float64{25, 5}, "", false},
+	}
+	for _, tc := range cases {
+		key, ok := r.Lookup(tc.point)
+		if key != tc.key || ok != tc.ok {
+			t.Errorf("incorrect region for %v, got %v %v", tc.point, key, ok)
+		}
+	}
+
+	fc := regionTestCollection()
+	delete(fc.Features[1].Properties, "iso")
+	if _, err := NewRegionIndex(fc, "iso"); err == nil {
+		t.Errorf("should fail on missing keys")
+	}
+}
+
+func TestCountryOf(t *testing.T) {
+	if err := SetCountryBoundaries(NewFeatureCollection(), "iso"); err == nil {
+		t.Errorf("should fail without boundaries")
+	}
+
+	if err := SetCountryBoundaries(regionTestCollection(), "iso"); err != nil {
+		t.Fatalf("should set boundaries, but got %v", err)
+	}
+	if iso, ok := CountryOf([]float64{15, 5}); !ok || iso != "BB" {
+		t.Errorf("incorrect country, got %v %v", iso, ok)
+	}
+}
+
+func TestRegionIndexBinary(t *testing.T) {
+	r, _ := NewRegionIndex(regionTestCollection(), "iso")
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	var decoded RegionIndex
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if decoded.Len() != 2 {
+		t.Errorf("should keep regions, got %v", decoded.Len())
+	}
+	if key, ok := decoded.Lookup([]float64{30.8, 30.2}); !ok || key != "BB" {
+		t.Errorf("should keep lookups working, got %v %v", key, ok)
+	}
+
+	if err := decoded.UnmarshalBinary([]byte("not gzip")); err == nil {
+		t.Errorf("should fail on invalid data")
+	}
+
+	// indexes encoded as GeoJSON only are indexed when decoded
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},"properties":{"key":"CC"}}]}`))
+	w.Close()
+	var legacy RegionIndex
+	if err := legacy.UnmarshalBinary(buf.Bytes()); err != nil {
+		t.Fatalf("should unmarshal GeoJSON indexes, but got %v", err)
+	}
+	if key, ok := legacy.Lookup([]float64{0.8, 0.2}); !ok || key != "CC" {
+		t.Errorf("incorrect region, got %v %v", key, ok)
+	}
+}
+
+func TestRegionIndexOverlaps(t *testing.T) {
+	fc := NewFeatureCollection()
+	for i, iso := range []string{"AA", "BB", "CC"} {
+		f := NewPolygonFeature([][][]float64{{{float64(i), 0}, {10, 0}, {10, 10}, {float64(i), 10}, {float64(i), 0}}})
+		f.SetProperty("iso", iso)
+		fc.AddFeature(f)
+	}
+	r, _ := NewRegionIndex(fc, "iso")
+
+	if key, ok := r.Lookup([]float64{5, 5}); !ok || key != "AA" {
+		t.Errorf("should return the first region, got %v %v", key, ok)
+	}
+	if key, ok := r.Lookup([]float64{11, 5}); ok {
+		t.Errorf("should find no region, got %v", key)
+	}
+	if _, ok := (&RegionIndex{}).Lookup([]float64{5, 5}); ok {
+		t.Errorf("should find nothing in an empty index")
+	}
+}
+
+func TestTimezoneOf(t *testing.T) {
+	SetTimezoneIndex(nil)
+	if _, err := TimezoneOf([]float64{0, 0}); err != ErrNoTimezoneIndex {
+		t.Errorf("should fail without index, got %v", err)
+	}
+
+	fc := NewFeatureCollection()
+	f := NewPolygonFeature([][][]float64{{{2.5, 49.5}, {6.4, 49.5}, {6.4, 51.5}, {2.5, 51.5}, {2.5, 49.5}}})
+	f.SetProperty("tzid", "Europe/Brussels")
+	fc.AddFeature(f)
+
+	r, _ := NewRegionIndex(fc, "tzid")
+	SetTimezoneIndex(r)
+	defer SetTimezoneIndex(nil)
+
+	cases := []struct {
+		point []float64
+		name  string
+	}{
+		{[]float64{4.35, 50.85}, "Europe/Brussels"},
+		{[]float64{-30, 40}, "Etc/GMT+2"},
+		{[]float64{170, -40}, "Etc/GMT-11"},
+		{[]float64{-3, 0}, "Etc/GMT"},
+	}
+	for _, tc := range cases {
+		if name, err := TimezoneOf(tc.point); err != nil || name != tc.name {
+			t.Errorf("incorrect time zone for %v, got %v %v", tc.point, name, err)
+		}
+	}
+}
+
+func TestEmbeddedIndex(t *testing.T) {
+	r, _ := NewRegionIndex(regionTestCollection(), "iso")
+	data, _ := r.MarshalBinary()
+
+	e := &embeddedIndex{data: data}
+	index, err := e.get()
+	if err != nil {
+		t.Fatalf("should decode the embedded index, but got %v", err)
+	}
+	if key, ok := index.Lookup([]float64{15, 5}); !ok || key != "BB" {
+		t.Errorf("incorrect region, got %v %v", key, ok)
+	}
+
+	if index, err := (&embeddedIndex{}).get(); index != nil || err != nil {
+		t.Errorf("should have no index without data, got %v %v", index, err)
+	}
+	if _, err := (&embeddedIndex{data: []byte("not gzip")}).get(); err == nil {
+		t.Errorf("should fail on invalid data")
+	}
+}
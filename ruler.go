@@ -0,0 +1,164 @@
+package geojson
+
+import (
+	"math"
+)
+
+// The WGS 84 ellipsoid, used by the Ruler.
+const (
+	wgs84SemiMajorAxis = 6378137.0
+	wgs84Flattening    = 1 / 298.257223563
+)
+
+// A Ruler computes fast approximate measures in meters, by treating the
+// earth as flat around the latitude it was created for, like Mapbox's
+// cheap-ruler. For city scale data, it is within 0.1% of the ellipsoidal
+// distance and an order of magnitude faster than Geodesic; the error grows
+// with the distance from the ruler's latitude.
+type Ruler struct {
+	kx, ky float64 // meters per degree of longitude and latitude
+}
+
+// NewRuler creates and initializes a ruler for data around the given latitude.
+func NewRuler(latitude float64) Ruler {
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	m := radians(1) * wgs84SemiMajorAxis
+
+	cos := math.Cos(radians(latitude))
+	w2 := 1 / (1 - e2*(1-cos*cos))
+	w := math.Sqrt(w2)
+
+	return Ruler{kx: m * w * cos, ky: m * w * w2 * (1 - e2)}
+}
+
+// Distance returns the distance in meters between two positions.
+func (r Ruler) Distance(a, b []float64) float64 {
+	dx := wrapLongitude(a[0]-b[0]) * r.kx
+	dy := (a[1] - b[1]) * r.ky
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// Length returns the length in meters of the lines of the geometry.
+// Points and polygons have no length, see Perimeter for the latter.
+func (r Ruler) Length(g *Geometry) float64 {
+	return lengthOf(g, r.pathLength)
+}
+
+// Perimeter returns the length in meters of all the rings of the polygons of the geometry.
+func (r Ruler) Perimeter(g *Geometry) float64 {
+	return perimeterOf(g, r.pathLength)
+}
+
+// Area returns the area in square meters of the polygons of the geometry.
+// Holes are subtracted, whatever the winding order of the rings.
+func (r Ruler) Area(g *Geometry) float64 {
+	return areaOf(g, func(polygon [][][]float64) float64 {
+		a := 0.0
+		for i, ring := range polygon {
+			ra := 0.0
+			for j := 1; j < len(ring); j++ {
+				ra += wrapLongitude(ring[j][0]-ring[j-1][0]) * (ring[j][1] + ring[j-1][1])
+			}
+			ra = math.Abs(ra) / 2 * r.kx * r.ky
+
+			if i == 0 {
+				a += ra
+			} else {
+				a -= ra
+			}
+		}
+		return a
+	})
+}
+
+// LocateAlong snaps the point onto the closest segment of the line string,
+// like the LocateAlong function. It returns the measure of the snapped
+// position, in meters from the start of the line, and the position itself.
+func (r Ruler) LocateAlong(line *Geometry, point []float64) (float64, []float64, error) {
+	if err := checkLinearGeometry(line); err != nil {
+		return 0, nil, err
+	}
+
+	path := line.LineString
+	var (
+		measure, along float64
+		snapped        = path[0]
+		min            = math.Inf(1)
+	)
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		dx := wrapLongitude(b[0]-a[0]) * r.kx
+		dy := (b[1] - a[1]) * r.ky
+
+		t := 0.0
+		if dx != 0 || dy != 0 {
+			t = (wrapLongitude(point[0]-a[0])*r.kx*dx + (point[1]-a[1])*r.ky*dy) / (dx*dx + dy*dy)
+			t = math.Max(0, math.Min(1, t))
+		}
+
+		q := interpolatePosition(a, b, t)
+		if d := r.Distance(point, q); d < min {
+			min, snapped, measure = d, q, along+t*math.Sqrt(dx*dx+dy*dy)
+		}
+		along += math.Sqrt(dx*dx + dy*dy)
+	}
+
+	return measure, append([]float64(nil), snapped...), nil
+}
+
+// PositionAt returns the position of the line string at the given measure,
+// in meters from its start. Measures beyond the line are clamped to its ends.
+func (r Ruler) PositionAt(line *Geometry, measure float64) ([]float64, error) {
+	if err := checkLinearGeometry(line); err != nil {
+		return nil, err
+	}
+
+	path := line.LineString
+	if measure <= 0 {
+		return append([]float64(nil), path[0]...), nil
+	}
+
+	along := 0.0
+	for i := 1; i < len(path); i++ {
+		d := r.Distance(path[i-1], path[i])
+		if d > 0 && along+d >= measure {
+			return interpolatePosition(path[i-1], path[i], (measure-along)/d), nil
+		}
+		along += d
+	}
+
+	return append([]float64(nil), path[len(path)-1]...), nil
+}
+
+// BufferPoint returns the bounding box, [west, south, east, north],
+// extending the given distance in meters around the position.
+func (r Ruler) BufferPoint(p []float64, meters float64) []float64 {
+	dx, dy := meters/r.kx, meters/r.ky
+	return []float64{p[0] - dx, p[1] - dy, p[0] + dx, p[1] + dy}
+}
+
+// BufferBoundingBox returns the two dimensional bounding box extended
+// by the given distance in meters on all sides.
+func (r Ruler) BufferBoundingBox(bb []float64, meters float64) []float64 {
+	dx, dy := meters/r.kx, meters/r.ky
+	return []float64{bb[0] - dx, bb[1] - dy, bb[2] + dx, bb[3] + dy}
+}
+
+func (r Ruler) pathLength(path [][]float64) float64 {
+	l := 0.0
+	for i := 1; i < len(path); i++ {
+		l += r.Distance(path[i-1], path[i])
+	}
+	return l
+}
+
+// wrapLongitude wraps a longitude difference into [-180, 180).
+func wrapLongitude(d float64) float64 {
+	for d < -180 {
+		d += 360
+	}
+	for d >= 180 {
+		d -= 360
+	}
+	return d
+}
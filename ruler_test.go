@@ -0,0 +1,96 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRulerDistance(t *testing.T) {
+	r := NewRuler(50.85)
+	a, b := []float64{4.35, 50.85}, []float64{4.37, 50.86}
+
+	d := r.Distance(a, b)
+	g := Geodesic{}.Distance(a, b)
+	if math.Abs(d-g)/g > 0.005 {
+		t.Errorf("should be close to the geodesic distance, got %v and %v", d, g)
+	}
+
+	// one degree of latitude on the ellipsoid at 45 degrees
+	if d := NewRuler(45).Distance([]float64{0, 44.5}, []float64{0, 45.5}); math.Abs(d-111132) > 5 {
+		t.Errorf("incorrect meridian distance, got %v", d)
+	}
+
+	if d := r.Distance([]float64{179.99, 0}, []float64{-179.99, 0}); d > 3000 {
+		t.Errorf("should wrap around the antimeridian, got %v", d)
+	}
+}
+
+func TestRulerMeasurer(t *testing.T) {
+	square := NewPolygonGeometry([][][]float64{
+		{{4.35, 50.85}, {4.36, 50.85}, {4.36, 50.86}, {4.35, 50.86}, {4.35, 50.85}},
+		{{4.352, 50.852}, {4.352, 50.853}, {4.353, 50.853}, {4.353, 50.852}, {4.352, 50.852}},
+	})
+	line := NewLineStringGeometry([][]float64{{4.35, 50.85}, {4.36, 50.85}, {4.36, 50.86}})
+
+	for _, m := range []Measurer{NewRuler(50.855), Geodesic{}} {
+		if a := m.Area(square); math.Abs(a-774000) > 5000 {
+			t.Errorf("incorrect area for %T, got %v", m, a)
+		}
+		if l := m.Length(line); math.Abs(l-1815) > 10 {
+			t.Errorf("incorrect length for %T, got %v", m, l)
+		}
+		if p := m.Perimeter(square); math.Abs(p-3993) > 15 {
+			t.Errorf("incorrect perimeter for %T, got %v", m, p)
+		}
+	}
+}
+
+func TestRulerLinearReferencing(t *testing.T) {
+	r := NewRuler(50.85)
+	line := NewLineStringGeometry([][]float64{{4.35, 50.85}, {4.36, 50.85}, {4.36, 50.86}})
+	first := r.Distance(line.LineString[0], line.LineString[1])
+
+	m, p, err := r.LocateAlong(line, []float64{4.361, 50.855})
+	if err != nil {
+		t.Fatalf("should locate, but got %v", err)
+	}
+	if p[0] != 4.36 || math.Abs(p[1]-50.855) > 1e-9 {
+		t.Errorf("should snap onto the second segment, got %v", p)
+	}
+
+	q, _ := r.PositionAt(line, m)
+	if math.Abs(q[0]-p[0]) > 1e-9 || math.Abs(q[1]-p[1]) > 1e-9 {
+		t.Errorf("should find the position back, got %v and %v", q, p)
+	}
+	if m <= first {
+		t.Errorf("should be beyond the first segment, got %v", m)
+	}
+}
+
+func TestRulerBuffer(t *testing.T) {
+	r := NewRuler(50.85)
+	p := []float64{4.35, 50.85}
+
+	bb := r.BufferPoint(p, 1000)
+	if d := r.Distance(p, []float64{bb[2], p[1]}); math.Abs(d-1000) > 1e-6 {
+		t.Errorf("incorrect east extent, got %v", d)
+	}
+	if d := r.Distance(p, []float64{p[0], bb[1]}); math.Abs(d-1000) > 1e-6 {
+		t.Errorf("incorrect south extent, got %v", d)
+	}
+
+	bb = r.BufferBoundingBox([]float64{4.35, 50.85, 4.36, 50.86}, 100)
+	if bb[0] >= 4.35 || bb[3] <= 50.86 {
+		t.Errorf("should grow the bounding box, got %v", bb)
+	}
+}
+
+func TestBuildGraphMeasurer(t *testing.T) {
+	g, err := BuildGraph(graphTestCollection(), GraphOptions{Measurer: NewRuler(0.5)})
+	if err != nil {
+		t.Fatalf("should build the graph, but got %v", err)
+	}
+	if l := g.Edges[0].Length; math.Abs(l-NewRuler(0.5).Distance([]float64{0, 0}, []float64{1, 0})-NewRuler(0.5).Distance([]float64{1, 0}, []float64{1, 1})) > 1e-6 {
+		t.Errorf("should measure edges with the ruler, got %v", l)
+	}
+}
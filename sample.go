@@ -0,0 +1,123 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// sampleMaxZoom is the deepest tile zoom level SamplePreview stratifies by.
+const sampleMaxZoom = 16
+
+// SamplePreview returns a spatially representative subset of at most
+// maxFeatures features of the collection, with geometries simplified to at
+// most maxVerticesPerFeature positions, to show a fast preview of a large
+// dataset before it is fully processed.
+//
+// The features are stratified by the XYZ tile of their centroid, at the
+// lowest zoom level with as many tiles as features to pick, and picked in
+// turn from each tile, so sparse regions are shown as well as dense ones.
+// Geometries that can not be simplified enough are replaced by their
+// bounding box polygon, or else their centroid. A maxVerticesPerFeature of
+// 0 or less keeps the geometries as is. The sample is deterministic, its
+// features are in the order of the collection, and share their properties
+// with it. Features without geometry are left out.
+func SamplePreview(fc *FeatureCollection, maxFeatures, maxVerticesPerFeature int) (*FeatureCollection, error) {
+	if maxFeatures <= 0 {
+		return nil, fmt.Errorf("invalid number of features %d", maxFeatures)
+	}
+
+	var indexes []int
+	var centroids [][]float64
+	for i, f := range fc.Features {
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+		if c := centroid(f.Geometry); c != nil {
+			indexes = append(indexes, i)
+			centroids = append(centroids, c)
+		}
+	}
+
+	var picked []int
+	if len(indexes) <= maxFeatures {
+		picked = indexes
+	} else {
+		picked = stratifiedSample(indexes, centroids, maxFeatures)
+	}
+
+	sample := NewFeatureCollection()
+	for _, i := range picked {
+		c := *fc.Features[i]
+		c.Geometry = previewGeometry(c.Geometry, maxVerticesPerFeature)
+		c.BoundingBox = nil
+		sample.Features = append(sample.Features, &c)
+	}
+	return sample, nil
+}
+
+// stratifiedSample picks n of the indexes, in turn from each tile of their
+// centroids, and returns them sorted.
+func stratifiedSample(indexes []int, centroids [][]float64, n int) []int {
+	var tiles [][]int
+	for zoom := 0; zoom <= sampleMaxZoom; zoom++ {
+		grid := mercatorGrid{size: 2 * webMercatorHalfWorld / math.Exp2(float64(zoom)), tiles: 1 << uint(zoom)}
+		tiles = sampleTiles(grid, indexes, centroids)
+		if len(tiles) >= n {
+			break
+		}
+	}
+
+	var picked []int
+	for round := 0; len(picked) < n; round++ {
+		for _, tile := range tiles {
+			if round < len(tile) && len(picked) < n {
+				picked = append(picked, tile[round])
+			}
+		}
+	}
+	sort.Ints(picked)
+	return picked
+}
+
+// sampleTiles groups the indexes by the cell of their centroids, in the
+// order of the cells.
+func sampleTiles(grid mercatorGrid, indexes []int, centroids [][]float64) [][]int {
+	type cell struct{ x, y int }
+	groups := make(map[cell][]int)
+	var cells []cell
+	for k, i := range indexes {
+		x, y := grid.cell(centroids[k])
+		c := cell{x, y}
+		if groups[c] == nil {
+			cells = append(cells, c)
+		}
+		groups[c] = append(groups[c], i)
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].y != cells[j].y {
+			return cells[i].y < cells[j].y
+		}
+		return cells[i].x < cells[j].x
+	})
+	tiles := make([][]int, len(cells))
+	for i, c := range cells {
+		tiles[i] = groups[c]
+	}
+	return tiles
+}
+
+// previewGeometry returns the geometry with at most max positions.
+func previewGeometry(g *Geometry, max int) *Geometry {
+	if max <= 0 || g.VertexCount() <= max {
+		return g
+	}
+	if s := simplifyToFit(g, max); s != nil {
+		return s
+	}
+	if bb := boundingBox(g); bb != nil && max >= 5 {
+		return boundingBoxPolygon(bb)
+	}
+	return NewPointGeometry(centroid(g))
+}
@@ -0,0 +1,84 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestSamplePreview(t *testing.T) {
+	fc := NewFeatureCollection()
+	// a dense cluster in Brussels and a few features elsewhere
+	for i := 0; i < 100; i++ {
+		fc.AddFeature(NewPointFeature([]float64{4.35 + float64(i)*0.0001, 50.85}))
+	}
+	fc.AddFeature(NewPointFeature([]float64{-74.0, 40.7}))
+	fc.AddFeature(NewPointFeature([]float64{151.2, -33.9}))
+	fc.AddFeature(NewFeature(nil))
+
+	sample, err := SamplePreview(fc, 3, 0)
+	if err != nil {
+		t.Fatalf("should sample, but got %v", err)
+	}
+	if len(sample.Features) != 3 {
+		t.Fatalf("incorrect number of features, got %d", len(sample.Features))
+	}
+	for i, expected := range [][]float64{{4.35, 50.85}, {-74.0, 40.7}, {151.2, -33.9}} {
+		p := sample.Features[i].Geometry.Point
+		if p[0] != expected[0] || p[1] != expected[1] {
+			t.Errorf("incorrect feature %d, got %v", i, p)
+		}
+	}
+
+	again, _ := SamplePreview(fc, 3, 0)
+	for i := range sample.Features {
+		if again.Features[i].Geometry != sample.Features[i].Geometry {
+			t.Errorf("should be deterministic")
+		}
+	}
+
+	all, _ := SamplePreview(fc, 200, 0)
+	if len(all.Features) != 102 {
+		t.Errorf("should keep every feature with geometry, got %d", len(all.Features))
+	}
+
+	if _, err := SamplePreview(fc, 0, 0); err == nil {
+		t.Errorf("should reject 0 features")
+	}
+}
+
+func TestSamplePreviewSimplifies(t *testing.T) {
+	var ring [][]float64
+	for i := 0; i <= 100; i++ {
+		x := float64(i) * 0.01
+		y := 0.0
+		if i%2 == 1 {
+			y = 0.001
+		}
+		ring = append(ring, []float64{x, y})
+	}
+	ring = append(ring, []float64{1, 1}, []float64{0, 1}, []float64{0, 0})
+
+	fc := NewFeatureCollection()
+	f := NewPolygonFeature([][][]float64{ring})
+	f.BoundingBox = []float64{0, 0, 1, 1}
+	fc.AddFeature(f)
+
+	sample, err := SamplePreview(fc, 10, 10)
+	if err != nil {
+		t.Fatalf("should sample, but got %v", err)
+	}
+	g := sample.Features[0].Geometry
+	if g.Type != GeometryPolygon || g.VertexCount() > 10 {
+		t.Errorf("should simplify the polygon, got %d vertices", g.VertexCount())
+	}
+	if sample.Features[0].BoundingBox != nil {
+		t.Errorf("should drop the bounding box")
+	}
+	if fc.Features[0].Geometry.VertexCount() != len(ring) {
+		t.Errorf("should not change the collection")
+	}
+
+	sample, _ = SamplePreview(fc, 10, 2)
+	if g := sample.Features[0].Geometry; g.Type != GeometryPoint {
+		t.Errorf("should fall back to the centroid, got %v", g.Type)
+	}
+}
@@ -0,0 +1,152 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A PropertyType is the type a property is coerced to by a PropertySchema.
+type PropertyType string
+
+// The property types of schemas.
+const (
+	// PropertyTypeString values are strings. Numbers and booleans are
+	// formatted.
+	PropertyTypeString PropertyType = "string"
+
+	// PropertyTypeInt values are int64. Integral numbers and strings
+	// holding one, like "42", are converted.
+	PropertyTypeInt PropertyType = "int"
+
+	// PropertyTypeFloat values are float64. Numbers and strings holding a
+	// finite one are converted.
+	PropertyTypeFloat PropertyType = "float"
+
+	// PropertyTypeBool values are bool. The numbers 0 and 1, and the
+	// strings accepted by strconv.ParseBool, are converted.
+	PropertyTypeBool PropertyType = "bool"
+
+	// PropertyTypeTime values are time.Time. RFC 3339 strings are parsed.
+	PropertyTypeTime PropertyType = "time"
+)
+
+// A PropertySchema declares the types of properties, by key, so the
+// values of dirty sources can be normalized when decoded.
+type PropertySchema map[string]PropertyType
+
+// A CoercionError is a property value that could not be coerced to the
+// type of its schema.
+type CoercionError struct {
+	Key   string
+	Value interface{}
+	Type  PropertyType
+}
+
+func (e *CoercionError) Error() string {
+	return fmt.Sprintf("property `%s`: can not coerce %#v to %s", e.Key, e.Value, e.Type)
+}
+
+// Coerce converts the properties of the feature declared by the schema to
+// their types. Missing and null properties are left alone, like values
+// that can not be converted, which are returned sorted by key.
+func (s PropertySchema) Coerce(f *Feature) []*CoercionError {
+	var errs []*CoercionError
+	for key, value := range f.Properties {
+		t, ok := s[key]
+		if !ok || value == nil {
+			continue
+		}
+
+		coerced, ok := coerceProperty(value, t)
+		if !ok {
+			errs = append(errs, &CoercionError{Key: key, Value: value, Type: t})
+			continue
+		}
+		f.Properties[key] = coerced
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return errs
+}
+
+// coerceProperty returns the value converted to the type, false if it can
+// not be.
+func coerceProperty(value interface{}, t PropertyType) (interface{}, bool) {
+	if s, ok := value.(string); ok && t != PropertyTypeString {
+		value = strings.TrimSpace(s)
+	}
+
+	switch t {
+	case PropertyTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case bool:
+			return strconv.FormatBool(v), true
+		case json.Number:
+			return v.String(), true
+		}
+		if i, ok := integerValue(value); ok {
+			return strconv.FormatInt(i, 10), true
+		}
+		if x, ok := numberValue(value); ok {
+			return strconv.FormatFloat(x, 'f', -1, 64), true
+		}
+
+	case PropertyTypeInt:
+		if i, ok := integerValue(value); ok {
+			return i, true
+		}
+		x, ok := numberValue(value)
+		if s, isString := value.(string); isString {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, true
+			}
+			var err error
+			x, err = strconv.ParseFloat(s, 64)
+			ok = err == nil
+		}
+		if ok {
+			return exactInt64(x)
+		}
+
+	case PropertyTypeFloat:
+		x, ok := numberValue(value)
+		if s, isString := value.(string); isString {
+			var err error
+			x, err = strconv.ParseFloat(s, 64)
+			ok = err == nil
+		}
+		if ok && !math.IsNaN(x) && !math.IsInf(x, 0) {
+			return x, true
+		}
+
+	case PropertyTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, true
+		case string:
+			b, err := strconv.ParseBool(v)
+			return b, err == nil
+		}
+		if x, ok := numberValue(value); ok && (x == 0 || x == 1) {
+			return x == 1, true
+		}
+
+	case PropertyTypeTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, true
+		case string:
+			tm, err := time.Parse(time.RFC3339Nano, v)
+			return tm, err == nil
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,129 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPropertySchemaCoerce(t *testing.T) {
+	schema := PropertySchema{
+		"code":    PropertyTypeString,
+		"count":   PropertyTypeInt,
+		"rank":    PropertyTypeInt,
+		"area":    PropertyTypeFloat,
+		"open":    PropertyTypeBool,
+		"closed":  PropertyTypeBool,
+		"since":   PropertyTypeTime,
+		"missing": PropertyTypeInt,
+		"empty":   PropertyTypeInt,
+	}
+
+	f := NewFeature(nil)
+	f.SetProperty("code", 1040.0)
+	f.SetProperty("count", " 42 ")
+	f.SetProperty("rank", json.Number("7"))
+	f.SetProperty("area", "12.5")
+	f.SetProperty("open", 1.0)
+	f.SetProperty("closed", "false")
+	f.SetProperty("since", "2020-09-13T12:26:40+02:00")
+	f.SetProperty("empty", nil)
+	f.SetProperty("other", "42")
+
+	if errs := schema.Coerce(f); len(errs) != 0 {
+		t.Fatalf("should coerce all properties, but got %v", errs)
+	}
+
+	expected := map[string]interface{}{
+		"code":   "1040",
+		"count":  int64(42),
+		"rank":   int64(7),
+		"area":   12.5,
+		"open":   true,
+		"closed": false,
+		"since":  time.Date(2020, 9, 13, 10, 26, 40, 0, time.UTC),
+		"empty":  nil,
+		"other":  "42",
+	}
+	for key, value := range expected {
+		actual := f.Properties[key]
+		if tm, ok := actual.(time.Time); ok {
+			actual = tm.UTC()
+		}
+		if !reflect.DeepEqual(actual, value) {
+			t.Errorf("incorrect %s, expected %#v, got %#v", key, value, f.Properties[key])
+		}
+	}
+}
+
+func TestPropertySchemaCoerceFailures(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		t     PropertyType
+	}{
+		{"forty-two", PropertyTypeInt},
+		{"4.5", PropertyTypeInt},
+		{1e300, PropertyTypeInt},
+		{"NaN", PropertyTypeFloat},
+		{true, PropertyTypeFloat},
+		{2.0, PropertyTypeBool},
+		{"yes please", PropertyTypeBool},
+		{"13/09/2020", PropertyTypeTime},
+		{1600000000.0, PropertyTypeTime},
+		{[]interface{}{"a"}, PropertyTypeString},
+		{"a", PropertyType("uuid")},
+	}
+
+	for _, tc := range cases {
+		f := NewFeature(nil)
+		f.SetProperty("p", tc.value)
+		errs := PropertySchema{"p": tc.t}.Coerce(f)
+		if len(errs) != 1 || errs[0].Key != "p" || errs[0].Type != tc.t {
+			t.Errorf("should fail to coerce %#v to %s, got %v", tc.value, tc.t, errs)
+			continue
+		}
+		if !reflect.DeepEqual(f.Properties["p"], tc.value) {
+			t.Errorf("should leave %#v unchanged, got %#v", tc.value, f.Properties["p"])
+		}
+	}
+}
+
+func TestDecodeOptionsSchema(t *testing.T) {
+	data := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":{"floors":"3","lift":"1"}},
+		null,
+		{"type":"Feature","geometry":null,"properties":{"floors":"three","lift":"maybe"}}
+	]}`
+	opts := DecodeOptions{Schema: PropertySchema{"floors": PropertyTypeInt, "lift": PropertyTypeBool}}
+
+	_, err := opts.UnmarshalFeatureCollection([]byte(data))
+	var cerr *CoercionError
+	if !errors.As(err, &cerr) || err.Error() != "feature 2: property `floors`: can not coerce \"three\" to int" {
+		t.Fatalf("should stop on the coercion failure, but got %v", err)
+	}
+
+	failures := map[int][]*CoercionError{}
+	opts.CoercionFailures = func(i int, f *Feature, errs []*CoercionError) {
+		failures[i] = errs
+	}
+	fc, err := opts.UnmarshalFeatureCollection([]byte(data))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if fc.Features[0].Properties["floors"] != int64(3) || fc.Features[0].Properties["lift"] != true {
+		t.Errorf("incorrect coerced properties, got %v", fc.Features[0].Properties)
+	}
+	if len(failures) != 1 || len(failures[2]) != 2 || failures[2][0].Key != "floors" || failures[2][1].Key != "lift" {
+		t.Errorf("incorrect failures, got %v", failures)
+	}
+	if fc.Features[2].Properties["floors"] != "three" {
+		t.Errorf("should leave the failed property, got %v", fc.Features[2].Properties)
+	}
+
+	f, err := opts.UnmarshalFeature([]byte(`{"type":"Feature","geometry":null,"properties":{"floors":2}}`))
+	if err != nil || f.Properties["floors"] != int64(2) {
+		t.Errorf("should coerce a single feature, got %v, %v", f, err)
+	}
+}
@@ -0,0 +1,129 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A table holds the records of a dBASE file.
+type table struct {
+	fields  []field
+	records [][]byte
+	deleted []bool
+}
+
+type field struct {
+	name     string
+	kind     byte
+	offset   int
+	length   int
+	decimals int
+}
+
+func decodeTable(data []byte) (*table, error) {
+	if len(data) < 32 {
+		return nil, errors.New("truncated dBASE header")
+	}
+
+	numRecords := int(binary.LittleEndian.Uint32(data[4:]))
+	headerSize := int(binary.LittleEndian.Uint16(data[8:]))
+	recordSize := int(binary.LittleEndian.Uint16(data[10:]))
+	if headerSize > len(data) || recordSize == 0 {
+		return nil, errors.New("invalid dBASE header")
+	}
+
+	t := &table{}
+	offset := 1 // deletion flag
+	for i := 32; i+32 <= headerSize && data[i] != 0x0d; i += 32 {
+		desc := data[i : i+32]
+		name := desc[:11]
+		if end := bytes.IndexByte(name, 0); end >= 0 {
+			name = name[:end]
+		}
+
+		f := field{
+			name:     decodeText(name),
+			kind:     desc[11],
+			offset:   offset,
+			length:   int(desc[16]),
+			decimals: int(desc[17]),
+		}
+		offset += f.length
+		if offset > recordSize {
+			return nil, fmt.Errorf("field %s beyond the record size", f.name)
+		}
+		t.fields = append(t.fields, f)
+	}
+
+	if numRecords > (len(data)-headerSize)/recordSize {
+		return nil, errors.New("truncated dBASE records")
+	}
+	for i := 0; i < numRecords; i++ {
+		r := data[headerSize+i*recordSize : headerSize+(i+1)*recordSize]
+		t.records = append(t.records, r)
+		t.deleted = append(t.deleted, r[0] == '*')
+	}
+
+	return t, nil
+}
+
+// properties returns the values of the record as feature properties.
+// Numbers become float64, logicals bool, dates "YYYY-MM-DD" strings and
+// the other types trimmed strings. Blank values are null.
+func (t *table) properties(i int) map[string]interface{} {
+	props := make(map[string]interface{}, len(t.fields))
+	for _, f := range t.fields {
+		raw := t.records[i][f.offset : f.offset+f.length]
+		props[f.name] = f.value(raw)
+	}
+	return props
+}
+
+func (f field) value(raw []byte) interface{} {
+	s := strings.TrimSpace(decodeText(bytes.TrimRight(raw, "\x00")))
+	switch f.kind {
+	case 'N', 'F':
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil // blank or filled with asterisks
+		}
+		return v
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true
+		case "F", "f", "N", "n":
+			return false
+		}
+		return nil
+	case 'D':
+		if len(s) != 8 {
+			return nil
+		}
+		return s[:4] + "-" + s[4:6] + "-" + s[6:]
+	}
+
+	if s == "" && f.kind != 'C' {
+		return nil
+	}
+	return s
+}
+
+// decodeText returns the text as UTF-8. Text that is not valid UTF-8 is
+// read as Latin-1, the encoding of most older shapefiles.
+func decodeText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
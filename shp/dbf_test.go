@@ -0,0 +1,83 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// dbase builds a .dbf file with the fields and the raw records,
+// deletion flag included.
+func dbase(fields []field, records ...string) []byte {
+	recordSize := 1
+	for _, f := range fields {
+		recordSize += f.length
+	}
+
+	var buf bytes.Buffer
+	header := make([]byte, 32)
+	header[0] = 3
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(header[8:], uint16(32+32*len(fields)+1))
+	binary.LittleEndian.PutUint16(header[10:], uint16(recordSize))
+	buf.Write(header)
+
+	for _, f := range fields {
+		desc := make([]byte, 32)
+		copy(desc, f.name)
+		desc[11] = f.kind
+		desc[16] = byte(f.length)
+		desc[17] = byte(f.decimals)
+		buf.Write(desc)
+	}
+	buf.WriteByte(0x0d)
+
+	for _, r := range records {
+		buf.WriteString(r)
+	}
+	buf.WriteByte(0x1a)
+	return buf.Bytes()
+}
+
+func TestTableProperties(t *testing.T) {
+	data := dbase([]field{
+		{name: "NAME", kind: 'C', length: 6},
+		{name: "AREA", kind: 'F', length: 6, decimals: 2},
+		{name: "OPEN", kind: 'L', length: 1},
+		{name: "BUILT", kind: 'D', length: 8},
+		{name: "EMPTY", kind: 'N', length: 3},
+	}, " Li\xe8ge  12.50T19050601   ")
+
+	tbl, err := decodeTable(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	props := tbl.properties(0)
+	expected := map[string]interface{}{
+		"NAME":  "Liège",
+		"AREA":  12.5,
+		"OPEN":  true,
+		"BUILT": "1905-06-01",
+		"EMPTY": nil,
+	}
+	for k, v := range expected {
+		if props[k] != v {
+			t.Errorf("incorrect %s, expected %v, got %v", k, v, props[k])
+		}
+	}
+}
+
+func TestTableInvalid(t *testing.T) {
+	valid := dbase([]field{{name: "A", kind: 'C', length: 2}}, " ab")
+
+	cases := map[string][]byte{
+		"header":    valid[:20],
+		"truncated": valid[:len(valid)-3],
+	}
+	for name, data := range cases {
+		if _, err := decodeTable(data); err == nil {
+			t.Errorf("should reject %s table", name)
+		}
+	}
+}
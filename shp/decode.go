@@ -0,0 +1,343 @@
+/*
+Package shp reads ESRI shapefiles into GeoJSON feature collections, with the
+attributes of the dBASE table as properties. The records are read in
+sequence, so the .shx index is not needed.
+*/
+package shp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// The shape types of the shapefile specification.
+const (
+	shapeNull        = 0
+	shapePoint       = 1
+	shapePolyLine    = 3
+	shapePolygon     = 5
+	shapeMultiPoint  = 8
+	shapePointZ      = 11
+	shapePolyLineZ   = 13
+	shapePolygonZ    = 15
+	shapeMultiPointZ = 18
+	shapePointM      = 21
+	shapePolyLineM   = 23
+	shapePolygonM    = 25
+	shapeMultiPointM = 28
+	shapeMultiPatch  = 31
+)
+
+// fileCode starts the header of .shp and .shx files.
+const fileCode = 9994
+
+// Decode reads the shapes of a .shp file and the attributes of the
+// matching .dbf file, which may be nil, into a feature collection.
+// Null shapes become features without geometry, and the records deleted
+// from the table are skipped. Altitudes are kept and measures dropped.
+func Decode(shp, dbf []byte) (*geojson.FeatureCollection, error) {
+	geometries, err := decodeShapes(shp)
+	if err != nil {
+		return nil, err
+	}
+
+	var t *table
+	if dbf != nil {
+		if t, err = decodeTable(dbf); err != nil {
+			return nil, err
+		}
+		if len(t.records) != len(geometries) {
+			return nil, fmt.Errorf("%d shapes for %d records", len(geometries), len(t.records))
+		}
+	}
+
+	fc := geojson.NewFeatureCollection()
+	for i, g := range geometries {
+		f := geojson.NewFeature(g)
+		if t != nil {
+			if t.deleted[i] {
+				continue
+			}
+			f.Properties = t.properties(i)
+		}
+		fc.AddFeature(f)
+	}
+
+	return fc, nil
+}
+
+// DecodeZip reads the first shapefile of a zip archive, as shapefiles are
+// usually distributed. The .dbf file is optional.
+func DecodeZip(data []byte) (*geojson.FeatureCollection, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File)
+	var name string
+	for _, f := range r.File {
+		ext := strings.ToLower(path.Ext(f.Name))
+		base := strings.TrimSuffix(f.Name, path.Ext(f.Name))
+		files[strings.ToLower(base)+ext] = f
+		if ext == ".shp" && name == "" {
+			name = strings.ToLower(base)
+		}
+	}
+	if name == "" {
+		return nil, errors.New("no .shp file in the archive")
+	}
+
+	shp, err := readZipFile(files[name+".shp"])
+	if err != nil {
+		return nil, err
+	}
+	var dbf []byte
+	if f, ok := files[name+".dbf"]; ok {
+		if dbf, err = readZipFile(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return Decode(shp, dbf)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func decodeShapes(data []byte) ([]*geojson.Geometry, error) {
+	if len(data) < 100 || binary.BigEndian.Uint32(data) != fileCode {
+		return nil, errors.New("not a shapefile")
+	}
+	if v := binary.LittleEndian.Uint32(data[28:]); v != 1000 {
+		return nil, fmt.Errorf("unsupported shapefile version %d", v)
+	}
+
+	length := int(binary.BigEndian.Uint32(data[24:])) * 2
+	if length < 100 || length > len(data) {
+		return nil, errors.New("truncated shapefile")
+	}
+	data = data[100:length]
+
+	var geometries []*geojson.Geometry
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, errors.New("truncated record header")
+		}
+		size := int(binary.BigEndian.Uint32(data[4:])) * 2
+		if size > len(data)-8 {
+			return nil, fmt.Errorf("record %d: truncated", len(geometries))
+		}
+
+		g, err := decodeShape(data[8 : 8+size])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", len(geometries), err)
+		}
+		geometries = append(geometries, g)
+		data = data[8+size:]
+	}
+
+	return geometries, nil
+}
+
+// A shapeReader reads the little endian numbers of a record.
+type shapeReader struct {
+	data []byte
+	err  error
+}
+
+func (r *shapeReader) uint32() int {
+	if len(r.data) < 4 {
+		r.err = errors.New("truncated shape")
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return int(v)
+}
+
+func (r *shapeReader) float64s(n int) []float64 {
+	if n < 0 || len(r.data) < 8*n {
+		r.err = errors.New("truncated shape")
+		return nil
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(r.data[8*i:]))
+	}
+	r.data = r.data[8*n:]
+	return values
+}
+
+func decodeShape(data []byte) (*geojson.Geometry, error) {
+	r := &shapeReader{data: data}
+	shapeType := r.uint32()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	hasZ := false
+	switch shapeType {
+	case shapeNull:
+		return nil, nil
+	case shapePoint, shapePointM, shapePointZ:
+		p := r.float64s(2)
+		if shapeType == shapePointZ {
+			p = append(p, r.float64s(1)...)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return geojson.NewPointGeometry(p), nil
+	case shapeMultiPointZ, shapePolyLineZ, shapePolygonZ:
+		hasZ = true
+	case shapeMultiPoint, shapeMultiPointM, shapePolyLine, shapePolyLineM, shapePolygon, shapePolygonM:
+	case shapeMultiPatch:
+		return nil, errors.New("multipatch shapes are not supported")
+	default:
+		return nil, fmt.Errorf("unknown shape type %d", shapeType)
+	}
+
+	r.float64s(4) // bounding box
+
+	multiPoint := shapeType == shapeMultiPoint || shapeType == shapeMultiPointM || shapeType == shapeMultiPointZ
+	parts := []int{0}
+	if !multiPoint {
+		numParts := r.uint32()
+		if numParts > len(r.data)/4 {
+			return nil, errors.New("truncated shape")
+		}
+		parts = make([]int, numParts)
+	}
+	numPoints := r.uint32()
+	if !multiPoint {
+		for i := range parts {
+			parts[i] = r.uint32()
+		}
+	}
+
+	xy := r.float64s(2 * numPoints)
+	var z []float64
+	if hasZ {
+		r.float64s(2) // z range
+		z = r.float64s(numPoints)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	positions := make([][]float64, numPoints)
+	for i := range positions {
+		positions[i] = []float64{xy[2*i], xy[2*i+1]}
+		if hasZ {
+			positions[i] = append(positions[i], z[i])
+		}
+	}
+
+	paths := make([][][]float64, len(parts))
+	for i, start := range parts {
+		end := numPoints
+		if i+1 < len(parts) {
+			end = parts[i+1]
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, fmt.Errorf("part %d out of range", i)
+		}
+		paths[i] = positions[start:end]
+	}
+
+	if multiPoint {
+		return geojson.NewMultiPointGeometry(positions...), nil
+	}
+
+	switch shapeType {
+	case shapePolyLine, shapePolyLineM, shapePolyLineZ:
+		if len(paths) == 1 {
+			return geojson.NewLineStringGeometry(paths[0]), nil
+		}
+		return geojson.NewMultiLineStringGeometry(paths...), nil
+	}
+
+	polygons, err := assemblePolygons(paths)
+	if err != nil {
+		return nil, err
+	}
+	switch len(polygons) {
+	case 0:
+		return nil, nil
+	case 1:
+		return geojson.NewPolygonGeometry(polygons[0]), nil
+	}
+	return geojson.NewMultiPolygonGeometry(polygons...), nil
+}
+
+// assemblePolygons groups the rings of a polygon shape into polygons.
+// Shapefile exterior rings are clockwise and holes counterclockwise; the
+// holes are put in the first exterior ring containing them, and all the
+// rings are reversed to the orientation of RFC 7946.
+func assemblePolygons(rings [][][]float64) ([][][][]float64, error) {
+	var polygons [][][][]float64
+	var holes [][][]float64
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return nil, fmt.Errorf("ring with %d positions, at least 4 are needed", len(ring))
+		}
+		ring = reverseRing(ring)
+		if signedArea(ring) >= 0 {
+			polygons = append(polygons, [][][]float64{ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	for _, h := range holes {
+		owner := -1
+		for i, p := range polygons {
+			if geojson.PointInPolygonWinding(h[0], p[:1]) != geojson.Exterior {
+				owner = i
+				break
+			}
+		}
+		if owner < 0 {
+			// a hole outside of all exterior rings is a wrongly oriented exterior ring
+			polygons = append(polygons, [][][]float64{reverseRing(h)})
+			continue
+		}
+		polygons[owner] = append(polygons[owner], h)
+	}
+
+	return polygons, nil
+}
+
+// signedArea returns twice the signed area of the ring, positive for
+// counterclockwise rings.
+func signedArea(ring [][]float64) float64 {
+	a := 0.0
+	for i := 1; i < len(ring); i++ {
+		a += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	return a
+}
+
+func reverseRing(ring [][]float64) [][]float64 {
+	result := make([][]float64, len(ring))
+	for i, p := range ring {
+		result[len(ring)-1-i] = p
+	}
+	return result
+}
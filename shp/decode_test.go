@@ -0,0 +1,184 @@
+package shp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// shapefile builds a .shp file from the contents of its records.
+func shapefile(shapeType uint32, records ...[]byte) []byte {
+	var body bytes.Buffer
+	for i, r := range records {
+		binary.Write(&body, binary.BigEndian, uint32(i+1))
+		binary.Write(&body, binary.BigEndian, uint32(len(r)/2))
+		body.Write(r)
+	}
+
+	header := make([]byte, 100)
+	binary.BigEndian.PutUint32(header, fileCode)
+	binary.BigEndian.PutUint32(header[24:], uint32((100+body.Len())/2))
+	binary.LittleEndian.PutUint32(header[28:], 1000)
+	binary.LittleEndian.PutUint32(header[32:], shapeType)
+	return append(header, body.Bytes()...)
+}
+
+// shape builds the content of a record from its little endian values.
+func shape(values ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, v := range values {
+		switch v := v.(type) {
+		case int:
+			binary.Write(&buf, binary.LittleEndian, uint32(v))
+		case float64:
+			binary.Write(&buf, binary.LittleEndian, math.Float64bits(v))
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	box := []interface{}{0.0, 0.0, 10.0, 10.0}
+	cases := []struct {
+		name     string
+		record   []byte
+		geometry *geojson.Geometry
+	}{
+		{
+			"point",
+			shape(shapePoint, 4.35, 50.85),
+			geojson.NewPointGeometry([]float64{4.35, 50.85}),
+		},
+		{
+			"point z",
+			shape(shapePointZ, 1.0, 2.0, 3.0, 0.0),
+			geojson.NewPointGeometry([]float64{1, 2, 3}),
+		},
+		{
+			"multi point",
+			shape(append(append([]interface{}{shapeMultiPoint}, box...), 2, 1.0, 2.0, 3.0, 4.0)...),
+			geojson.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		},
+		{
+			"polyline",
+			shape(append(append([]interface{}{shapePolyLine}, box...), 2, 4, 0, 2, 0.0, 0.0, 1.0, 1.0, 2.0, 2.0, 3.0, 3.0)...),
+			geojson.NewMultiLineStringGeometry([][]float64{{0, 0}, {1, 1}}, [][]float64{{2, 2}, {3, 3}}),
+		},
+		{
+			"polyline z",
+			shape(append(append([]interface{}{shapePolyLineZ}, box...), 1, 2, 0, 0.0, 0.0, 1.0, 1.0, 5.0, 6.0, 5.0, 6.0)...),
+			geojson.NewLineStringGeometry([][]float64{{0, 0, 5}, {1, 1, 6}}),
+		},
+		{
+			"polygon with hole",
+			shape(append(append([]interface{}{shapePolygon}, box...), 2, 10, 0, 5,
+				0.0, 0.0, 0.0, 10.0, 10.0, 10.0, 10.0, 0.0, 0.0, 0.0,
+				2.0, 2.0, 4.0, 2.0, 4.0, 4.0, 2.0, 4.0, 2.0, 2.0)...),
+			geojson.NewPolygonGeometry([][][]float64{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+			}),
+		},
+		{
+			"multi polygon",
+			shape(append(append([]interface{}{shapePolygon}, box...), 2, 8, 0, 4,
+				0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 0.0,
+				5.0, 5.0, 5.0, 6.0, 6.0, 5.0, 5.0, 5.0)...),
+			geojson.NewMultiPolygonGeometry(
+				[][][]float64{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+				[][][]float64{{{5, 5}, {6, 5}, {5, 6}, {5, 5}}},
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc, err := Decode(shapefile(0, tc.record), nil)
+			if err != nil {
+				t.Fatalf("should decode, but got %v", err)
+			}
+			if len(fc.Features) != 1 {
+				t.Fatalf("should have 1 feature, got %d", len(fc.Features))
+			}
+			if !fc.Features[0].Geometry.Equal(tc.geometry) {
+				t.Errorf("incorrect geometry, got %+v", fc.Features[0].Geometry)
+			}
+		})
+	}
+}
+
+func TestDecodeWithAttributes(t *testing.T) {
+	shp := shapefile(shapePoint,
+		shape(shapePoint, 1.0, 2.0),
+		shape(shapeNull),
+		shape(shapePoint, 3.0, 4.0),
+	)
+	dbf := dbase([]field{{name: "NAME", kind: 'C', length: 10}, {name: "POP", kind: 'N', length: 8}},
+		" Brussels   1200000",
+		"*Deleted         12",
+		" Gent        260000",
+	)
+
+	fc, err := Decode(shp, dbf)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("should skip the deleted record, got %d features", len(fc.Features))
+	}
+	if fc.Features[1].PropertyMustString("NAME") != "Gent" || fc.Features[1].PropertyMustFloat64("POP") != 260000 {
+		t.Errorf("incorrect properties, got %v", fc.Features[1].Properties)
+	}
+
+	if _, err := Decode(shp, dbase([]field{{name: "NAME", kind: 'C', length: 1}}, " a")); err == nil {
+		t.Errorf("should reject a table with another number of records")
+	}
+}
+
+func TestDecodeZip(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := map[string][]byte{
+		"roads/Roads.SHP": shapefile(shapePoint, shape(shapePoint, 1.0, 2.0)),
+		"roads/Roads.dbf": dbase([]field{{name: "ID", kind: 'N', length: 4}}, "   42"),
+		"roads/Roads.prj": []byte(`GEOGCS["WGS 84"]`),
+	}
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+	}
+	w.Close()
+
+	fc, err := DecodeZip(buf.Bytes())
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].PropertyMustFloat64("ID") != 42 {
+		t.Errorf("incorrect features, got %+v", fc.Features)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	cases := map[string][]byte{
+		"empty":       nil,
+		"file code":   make([]byte, 100),
+		"truncated":   shapefile(shapePoint, shape(shapePoint, 1.0)),
+		"multipatch":  shapefile(shapeMultiPatch, shape(shapeMultiPatch)),
+		"unknown":     shapefile(0, shape(99)),
+		"parts range": shapefile(shapePolyLine, shape(shapePolyLine, 0.0, 0.0, 0.0, 0.0, 1, 1, 5, 0.0, 0.0)),
+		"short ring":  shapefile(shapePolygon, shape(shapePolygon, 0.0, 0.0, 0.0, 0.0, 1, 2, 0, 0.0, 0.0, 0.0, 0.0)),
+	}
+
+	for name, data := range cases {
+		if _, err := Decode(data, nil); err == nil {
+			t.Errorf("should reject %s", name)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package geojson
+
+// Simplify returns a simplified copy of the geometry using the
+// Douglas-Peucker algorithm. The tolerance is expressed in coordinate units.
+// Rings stay closed and keep at least 4 positions, line strings keep at
+// least their two end points. Points and multi points are returned unchanged.
+func Simplify(g *Geometry, tolerance float64) *Geometry {
+	r := simplifyGeometry(g, tolerance)
+	if r != nil {
+		r.provenance = g.provenance
+		r.RecordTransformation(Transformation{
+			Operation:  OperationSimplify,
+			Parameters: map[string]interface{}{"tolerance": tolerance},
+		})
+	}
+	return r
+}
+
+func simplifyGeometry(g *Geometry, tolerance float64) *Geometry {
+	if g == nil {
+		return nil
+	}
+
+	r := &Geometry{
+		Type:        g.Type,
+		BoundingBox: g.BoundingBox,
+		CRS:         g.CRS,
+		Point:       g.Point,
+		MultiPoint:  g.MultiPoint,
+	}
+
+	switch g.Type {
+	case GeometryLineString:
+		r.LineString = simplifyPath(g.LineString, tolerance, 2)
+	case GeometryMultiLineString:
+		r.MultiLineString = simplifyPaths(g.MultiLineString, tolerance, 2)
+	case GeometryPolygon:
+		r.Polygon = simplifyPaths(g.Polygon, tolerance, 4)
+	case GeometryMultiPolygon:
+		r.MultiPolygon = make([][][][]float64, 0, len(g.MultiPolygon))
+		for _, p := range g.MultiPolygon {
+			r.MultiPolygon = append(r.MultiPolygon, simplifyPaths(p, tolerance, 4))
+		}
+	case GeometryCollection:
+		r.Geometries = make([]*Geometry, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			r.Geometries = append(r.Geometries, simplifyGeometry(c, tolerance))
+		}
+	}
+
+	return r
+}
+
+// VertexCount returns the number of positions in the geometry,
+// including those of all members of a geometry collection.
+func (g *Geometry) VertexCount() int {
+	if g == nil {
+		return 0
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) == 0 {
+			return 0
+		}
+		return 1
+	case GeometryMultiPoint:
+		return len(g.MultiPoint)
+	case GeometryLineString:
+		return len(g.LineString)
+	case GeometryMultiLineString:
+		return pathsVertexCount(g.MultiLineString)
+	case GeometryPolygon:
+		return pathsVertexCount(g.Polygon)
+	case GeometryMultiPolygon:
+		n := 0
+		for _, p := range g.MultiPolygon {
+			n += pathsVertexCount(p)
+		}
+		return n
+	case GeometryCollection:
+		n := 0
+		for _, c := range g.Geometries {
+			n += c.VertexCount()
+		}
+		return n
+	}
+
+	return 0
+}
+
+func pathsVertexCount(paths [][][]float64) int {
+	n := 0
+	for _, p := range paths {
+		n += len(p)
+	}
+	return n
+}
+
+func simplifyPaths(paths [][][]float64, tolerance float64, min int) [][][]float64 {
+	result := make([][][]float64, 0, len(paths))
+	for _, p := range paths {
+		result = append(result, simplifyPath(p, tolerance, min))
+	}
+	return result
+}
+
+// simplifyPath runs Douglas-Peucker over the path, keeping at least min
+// positions when the path had that many to begin with.
+func simplifyPath(path [][]float64, tolerance float64, min int) [][]float64 {
+	if len(path) <= min || len(path) < 3 {
+		return path
+	}
+
+	keep := make([]bool, len(path))
+	keep[0], keep[len(path)-1] = true, true
+
+	sqTolerance := tolerance * tolerance
+	type span struct{ first, last int }
+	stack := []span{{0, len(path) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		index, maxDist := -1, sqTolerance
+		for i := s.first + 1; i < s.last; i++ {
+			d := sqSegmentDistance(path[i], path[s.first], path[s.last])
+			if d > maxDist {
+				index, maxDist = i, d
+			}
+		}
+
+		if index != -1 {
+			keep[index] = true
+			stack = append(stack, span{s.first, index}, span{index, s.last})
+		}
+	}
+
+	result := make([][]float64, 0, len(path))
+	for i, p := range path {
+		if keep[i] {
+			result = append(result, p)
+		}
+	}
+
+	if len(result) < min {
+		// Too aggressive for this path, put back the most distant positions
+		// until the minimum is reached.
+		return padPath(path, keep, min)
+	}
+
+	return result
+}
+
+func padPath(path [][]float64, keep []bool, min int) [][]float64 {
+	count := 0
+	for _, k := range keep {
+		if k {
+			count++
+		}
+	}
+
+	for count < min {
+		index, maxDist := -1, -1.0
+		prev := 0
+		for i := 1; i < len(path); i++ {
+			if keep[i] {
+				for j := prev + 1; j < i; j++ {
+					if d := sqSegmentDistance(path[j], path[prev], path[i]); d > maxDist {
+						index, maxDist = j, d
+					}
+				}
+				prev = i
+			}
+		}
+		if index == -1 {
+			break
+		}
+		keep[index] = true
+		count++
+	}
+
+	result := make([][]float64, 0, count)
+	for i, p := range path {
+		if keep[i] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// sqSegmentDistance returns the squared planar distance from p to the segment a-b.
+func sqSegmentDistance(p, a, b []float64) float64 {
+	x, y := a[0], a[1]
+	dx, dy := b[0]-x, b[1]-y
+
+	if dx != 0 || dy != 0 {
+		t := ((p[0]-x)*dx + (p[1]-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b[0], b[1]
+		} else if t > 0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+
+	dx, dy = p[0]-x, p[1]-y
+	return dx*dx + dy*dy
+}
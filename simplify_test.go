@@ -0,0 +1,43 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestSimplifyLineString(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{0, 0}, {1, 0.01}, {2, 0}, {3, 5}, {4, 6}})
+	s := Simplify(g, 0.1)
+
+	if len(s.LineString) != 4 {
+		t.Errorf("should remove the almost collinear vertex, got %v", s.LineString)
+	}
+
+	if len(g.LineString) != 5 {
+		t.Errorf("should not modify the original geometry")
+	}
+}
+
+func TestSimplifyKeepsRingsValid(t *testing.T) {
+	g := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0.5, 1.001}, {0, 1}, {0, 0}}})
+	s := Simplify(g, 100)
+
+	ring := s.Polygon[0]
+	if len(ring) != 4 {
+		t.Fatalf("should keep 4 positions in ring, got %v", ring)
+	}
+
+	if ring[0][0] != ring[3][0] || ring[0][1] != ring[3][1] {
+		t.Errorf("ring should stay closed, got %v", ring)
+	}
+}
+
+func TestGeometryVertexCount(t *testing.T) {
+	g := NewCollectionGeometry(
+		NewPointGeometry([]float64{1, 2}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+	)
+
+	if n := g.VertexCount(); n != 5 {
+		t.Errorf("should count 5 vertices, got %d", n)
+	}
+}
@@ -0,0 +1,107 @@
+package geojson
+
+import (
+	"math"
+)
+
+// SnapTo moves the vertices of the target geometry onto the reference
+// geometry when they are within toleranceMeters of it, and returns the
+// number of moved vertices. A vertex snaps to the closest reference vertex
+// in tolerance, or else to the closest point on a reference edge. This cleans
+// almost coincident boundaries before overlay operations.
+//
+// Distances are geodesic, see MeasureOptions.SnapTo for other measurers.
+// Only the first two elements of the positions are changed; altitudes are
+// kept.
+func SnapTo(target *Geometry, reference *Geometry, toleranceMeters float64) int {
+	return MeasureOptions{}.SnapTo(target, reference, toleranceMeters)
+}
+
+// SnapTo is SnapTo measuring with the Measurer of the options.
+func (o MeasureOptions) SnapTo(target *Geometry, reference *Geometry, toleranceMeters float64) int {
+	if target == nil || reference == nil || toleranceMeters <= 0 {
+		return 0
+	}
+
+	var vertices [][]float64
+	forEachPosition(reference, func(p []float64) {
+		vertices = append(vertices, p)
+	})
+
+	type segment struct{ a, b []float64 }
+	var segments []segment
+	forEachSegment(reference, func(a, b []float64) {
+		segments = append(segments, segment{a, b})
+	})
+
+	m := o.measurer()
+	// the candidates are found with a margin, for measurers other than
+	// Geodesic
+	tolLat := 1.01 * toleranceMeters / (EarthRadius * radians(1))
+	snapped := 0
+
+	forEachPosition(target, func(p []float64) {
+		if len(p) < 2 {
+			return
+		}
+
+		tolLon := tolLat / math.Max(math.Cos(radians(p[1])), 1e-9)
+		near := func(q []float64) bool {
+			return math.Abs(q[0]-p[0]) <= tolLon && math.Abs(q[1]-p[1]) <= tolLat
+		}
+
+		var best []float64
+		bestDist := toleranceMeters
+		for _, v := range vertices {
+			if !near(v) {
+				continue
+			}
+			if d := m.Distance(p, v); d <= bestDist {
+				best, bestDist = v, d
+			}
+		}
+
+		if best == nil {
+			for _, s := range segments {
+				if math.Min(s.a[0], s.b[0])-tolLon > p[0] || math.Max(s.a[0], s.b[0])+tolLon < p[0] ||
+					math.Min(s.a[1], s.b[1])-tolLat > p[1] || math.Max(s.a[1], s.b[1])+tolLat < p[1] {
+					continue
+				}
+
+				q := closestPointOnSegment(p, s.a, s.b)
+				if d := m.Distance(p, q); d <= bestDist {
+					best, bestDist = q, d
+				}
+			}
+		}
+
+		if best != nil && (best[0] != p[0] || best[1] != p[1]) {
+			p[0], p[1] = best[0], best[1]
+			snapped++
+		}
+	})
+
+	return snapped
+}
+
+// closestPointOnSegment returns the point of the segment a-b closest to p,
+// computed in an equirectangular projection centered on p.
+func closestPointOnSegment(p, a, b []float64) []float64 {
+	t := segmentFraction(p, a, b)
+	return []float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t}
+}
+
+// segmentFraction returns the fraction along the segment a-b of the
+// point closest to p, see closestPointOnSegment.
+func segmentFraction(p, a, b []float64) float64 {
+	k := math.Cos(radians(p[1]))
+
+	ax, ay := (a[0]-p[0])*k, a[1]-p[1]
+	bx, by := (b[0]-p[0])*k, b[1]-p[1]
+	dx, dy := bx-ax, by-ay
+
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, -(ax*dx+ay*dy)/(dx*dx+dy*dy)))
+}
@@ -0,0 +1,42 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSnapToVertex(t *testing.T) {
+	reference := NewPolygonGeometry([][][]float64{{{0, 0}, {0.01, 0}, {0.01, 0.01}, {0, 0.01}, {0, 0}}})
+	target := NewPolygonGeometry([][][]float64{{{0.01000001, 0.00999999}, {0.02, 0.01}, {0.02, 0}, {0.01000001, 0.00999999}}})
+
+	n := SnapTo(target, reference, 1)
+	if n != 2 {
+		t.Errorf("should snap the two ends of the ring, got %d", n)
+	}
+
+	ring := target.Polygon[0]
+	if ring[0][0] != 0.01 || ring[0][1] != 0.01 || ring[3][0] != 0.01 || ring[3][1] != 0.01 {
+		t.Errorf("should snap onto reference vertex, got %v", ring)
+	}
+	if ring[1][0] != 0.02 {
+		t.Errorf("should not move vertices out of tolerance, got %v", ring)
+	}
+}
+
+func TestSnapToEdge(t *testing.T) {
+	reference := NewLineStringGeometry([][]float64{{0, 0}, {0.01, 0}})
+	target := NewPointGeometry([]float64{0.005, 0.000001, 12})
+
+	if n := SnapTo(target, reference, 1); n != 1 {
+		t.Fatalf("should snap the point, got %d", n)
+	}
+
+	if math.Abs(target.Point[0]-0.005) > 1e-12 || target.Point[1] != 0 || target.Point[2] != 12 {
+		t.Errorf("should snap onto the reference edge and keep altitude, got %v", target.Point)
+	}
+
+	far := NewPointGeometry([]float64{0.005, 0.001})
+	if n := SnapTo(far, reference, 1); n != 0 {
+		t.Errorf("should not snap point out of tolerance, got %v", far.Point)
+	}
+}
@@ -0,0 +1,89 @@
+package geojson
+
+// A Spec is a version of the GeoJSON specification.
+type Spec int
+
+// The versions of the GeoJSON specification.
+const (
+	// SpecLegacy is the 2008 GeoJSON specification, which this package
+	// followed first: crs members are decoded and written.
+	SpecLegacy Spec = iota
+
+	// SpecRFC7946 is the specification of RFC 7946, which removed the crs
+	// members, always WGS 84, and asks to avoid nested geometry
+	// collections: crs members are ignored when decoding and left out when
+	// writing, and nested geometry collections are reported as warnings.
+	SpecRFC7946
+)
+
+// String returns the name of the specification.
+func (s Spec) String() string {
+	switch s {
+	case SpecLegacy:
+		return "GeoJSON 2008"
+	case SpecRFC7946:
+		return "RFC 7946"
+	}
+	return "unknown specification"
+}
+
+// decodedGeometry removes the crs members of the decoded geometry and of
+// its members, and warns of the nested geometry collections, in RFC 7946
+// mode. The pointer locates the geometry.
+func (s Spec) decodedGeometry(g *Geometry, pointer string, warn func(ValidationError)) {
+	if s != SpecRFC7946 || g == nil {
+		return
+	}
+
+	g.CRS = nil
+	for i, m := range g.Geometries {
+		if m == nil {
+			continue
+		}
+		if m.Type == GeometryCollection && warn != nil {
+			warn(ValidationError{Kind: ValidationNestedCollection, Pointer: indexPointer(pointer+"/geometries", i)})
+		}
+		s.decodedGeometry(m, indexPointer(pointer+"/geometries", i), warn)
+	}
+}
+
+// decodedFeature removes the crs members of the decoded feature and of its
+// geometry in RFC 7946 mode, see decodedGeometry.
+func (s Spec) decodedFeature(f *Feature, warn func(ValidationError)) {
+	if s != SpecRFC7946 || f == nil {
+		return
+	}
+	f.CRS = nil
+	s.decodedGeometry(f.Geometry, "/geometry", warn)
+}
+
+// withoutCRS returns the geometry, or a copy of it and of its members
+// sharing the coordinates without their crs members, in RFC 7946 mode.
+func (o MarshalOptions) withoutCRS(g *Geometry) *Geometry {
+	if o.Spec != SpecRFC7946 || g == nil {
+		return g
+	}
+
+	c := *g
+	c.CRS = nil
+	if g.Geometries != nil {
+		c.Geometries = make([]*Geometry, len(g.Geometries))
+		for i, m := range g.Geometries {
+			c.Geometries[i] = o.withoutCRS(m)
+		}
+	}
+	return &c
+}
+
+// featureWithoutCRS returns the feature, or a copy of it and of its
+// geometry without their crs members, in RFC 7946 mode.
+func (o MarshalOptions) featureWithoutCRS(f *Feature) *Feature {
+	if o.Spec != SpecRFC7946 || f == nil {
+		return f
+	}
+
+	c := *f
+	c.CRS = nil
+	c.Geometry = o.withoutCRS(f.Geometry)
+	return &c
+}
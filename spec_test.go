@@ -0,0 +1,87 @@
+package geojson
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeOptionsSpec(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:4326"}},"features":[
+		{"type":"Feature","geometry":null,"properties":null},
+		{"type":"Feature","crs":{"type":"name"},"geometry":{"type":"GeometryCollection","crs":{"type":"name"},"geometries":[
+			{"type":"Point","coordinates":[0,0]},
+			{"type":"GeometryCollection","geometries":[{"type":"Point","crs":{"type":"name"},"coordinates":[1,1]}]}
+		]},"properties":null}
+	]}`)
+
+	fc, err := DecodeOptions{}.UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if fc.CRS == nil || fc.Features[1].CRS == nil || fc.Features[1].Geometry.Geometries[1].Geometries[0].CRS == nil {
+		t.Errorf("should keep the crs members in legacy mode")
+	}
+
+	type warning struct {
+		index int
+		w     ValidationError
+	}
+	var warnings []warning
+	o := DecodeOptions{Spec: SpecRFC7946, Warnings: func(i int, w ValidationError) {
+		warnings = append(warnings, warning{i, w})
+	}}
+	fc, err = o.UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	g := fc.Features[1].Geometry
+	if fc.CRS != nil || fc.Features[1].CRS != nil || g.CRS != nil || g.Geometries[1].Geometries[0].CRS != nil {
+		t.Errorf("should ignore the crs members in RFC 7946 mode")
+	}
+	expected := []warning{{1, ValidationError{ValidationNestedCollection, "/geometry/geometries/1"}}}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("incorrect warnings, got %v", warnings)
+	}
+
+	warnings = nil
+	if _, err := o.UnmarshalGeometry([]byte(`{"type":"GeometryCollection","geometries":[{"type":"GeometryCollection","geometries":[]}]}`)); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	if !reflect.DeepEqual(warnings, []warning{{0, ValidationError{ValidationNestedCollection, "/geometries/0"}}}) {
+		t.Errorf("incorrect geometry warnings, got %v", warnings)
+	}
+}
+
+func TestMarshalOptionsSpec(t *testing.T) {
+	crs := map[string]interface{}{"type": "name", "properties": map[string]interface{}{"name": "EPSG:4326"}}
+	g := NewCollectionGeometry(NewPointGeometry([]float64{1, 2}))
+	g.Geometries[0].CRS = crs
+	f := NewFeature(g)
+	f.CRS = crs
+	fc := NewFeatureCollection()
+	fc.CRS = crs
+	fc.AddFeature(f)
+
+	data, err := MarshalOptions{Spec: SpecRFC7946}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]},"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("incorrect JSON, got %s", data)
+	}
+	if g.Geometries[0].CRS == nil || f.CRS == nil || fc.CRS == nil {
+		t.Errorf("should leave the collection as it is")
+	}
+
+	data, err = MarshalOptions{Spec: SpecRFC7946}.MarshalGeometry(g)
+	if err != nil || string(data) != `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}` {
+		t.Errorf("incorrect geometry, got %s, %v", data, err)
+	}
+
+	data, err = MarshalOptions{}.MarshalFeature(f)
+	if err != nil || !bytes.Contains(data, []byte(`"crs"`)) {
+		t.Errorf("should write the crs members in legacy mode, got %s, %v", data, err)
+	}
+}
@@ -0,0 +1,194 @@
+package geojson
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A StrictError is a member of a document rejected by strict decoding,
+// see DecodeOptions.Strict.
+type StrictError struct {
+	// Pointer is the RFC 6901 JSON pointer to the offending member, empty
+	// for the whole document.
+	Pointer string
+	Reason  string
+}
+
+// Error describes the strict decoding error.
+func (e *StrictError) Error() string {
+	if e.Pointer == "" {
+		return e.Reason
+	}
+	return e.Pointer + ": " + e.Reason
+}
+
+// JSONPointer returns the pointer to the offending member.
+func (e *StrictError) JSONPointer() string {
+	return e.Pointer
+}
+
+func strictError(pointer, format string, args ...interface{}) error {
+	return &StrictError{Pointer: pointer, Reason: fmt.Sprintf(format, args...)}
+}
+
+// checkStrictFeatureCollection checks the decoded JSON object is a
+// feature collection whose features are all feature objects.
+func checkStrictFeatureCollection(object map[string]interface{}) error {
+	if object["type"] != "FeatureCollection" {
+		return strictError("/type", "type must be \"FeatureCollection\", got %v", object["type"])
+	}
+
+	if err := checkStrictBoundingBox(object, ""); err != nil {
+		return err
+	}
+
+	features, ok := object["features"].([]interface{})
+	if !ok {
+		return strictError("/features", "features must be an array, got %T", object["features"])
+	}
+	for i, f := range features {
+		pointer := "/features/" + strconv.Itoa(i)
+		feature, ok := f.(map[string]interface{})
+		if !ok {
+			return strictError(pointer, "feature must be an object, got %T", f)
+		}
+		if err := checkStrictFeature(feature, pointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStrictFeature checks the decoded JSON object is a feature with
+// geometry and properties members.
+func checkStrictFeature(object map[string]interface{}, pointer string) error {
+	if object["type"] != "Feature" {
+		return strictError(pointer+"/type", "type must be \"Feature\", got %v", object["type"])
+	}
+
+	if err := checkStrictBoundingBox(object, pointer); err != nil {
+		return err
+	}
+
+	g, ok := object["geometry"]
+	if !ok {
+		return strictError(pointer+"/geometry", "geometry member is missing")
+	}
+	if g != nil {
+		geometry, ok := g.(map[string]interface{})
+		if !ok {
+			return strictError(pointer+"/geometry", "geometry must be an object or null, got %T", g)
+		}
+		if err := checkStrictGeometry(geometry, pointer+"/geometry"); err != nil {
+			return err
+		}
+	}
+
+	p, ok := object["properties"]
+	if !ok {
+		return strictError(pointer+"/properties", "properties member is missing")
+	}
+	if _, ok := p.(map[string]interface{}); !ok && p != nil {
+		return strictError(pointer+"/properties", "properties must be an object or null, got %T", p)
+	}
+	return nil
+}
+
+// checkStrictGeometry checks the decoded JSON object is a geometry of a
+// known type, with coordinates nested as the type asks and positions of
+// at least 2 numbers.
+func checkStrictGeometry(object map[string]interface{}, pointer string) error {
+	if err := checkStrictBoundingBox(object, pointer); err != nil {
+		return err
+	}
+
+	t, _ := object["type"].(string)
+
+	depth := -1
+	switch GeometryType(t) {
+	case GeometryPoint:
+		depth = 0
+	case GeometryMultiPoint, GeometryLineString:
+		depth = 1
+	case GeometryMultiLineString, GeometryPolygon:
+		depth = 2
+	case GeometryMultiPolygon:
+		depth = 3
+	case GeometryCollection:
+		geometries, ok := object["geometries"].([]interface{})
+		if !ok {
+			return strictError(pointer+"/geometries", "geometries must be an array, got %T", object["geometries"])
+		}
+		for i, g := range geometries {
+			member := pointer + "/geometries/" + strconv.Itoa(i)
+			geometry, ok := g.(map[string]interface{})
+			if !ok {
+				return strictError(member, "geometry must be an object, got %T", g)
+			}
+			if err := checkStrictGeometry(geometry, member); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return strictError(pointer+"/type", "unknown geometry type %v", object["type"])
+	}
+
+	coordinates, ok := object["coordinates"]
+	if !ok {
+		return strictError(pointer+"/coordinates", "coordinates member is missing")
+	}
+	if values, ok := coordinates.([]interface{}); ok && len(values) == 0 {
+		// an empty geometry
+		return nil
+	}
+	return checkStrictCoordinates(coordinates, depth, pointer+"/coordinates")
+}
+
+// checkStrictBoundingBox checks the bounding box of the object, if any, is
+// an array of an even number of numbers, at least 4.
+func checkStrictBoundingBox(object map[string]interface{}, pointer string) error {
+	bbox, ok := object["bbox"]
+	if !ok {
+		return nil
+	}
+
+	values, ok := bbox.([]interface{})
+	if !ok || len(values) < 4 || len(values)%2 != 0 {
+		return strictError(pointer+"/bbox", "bbox must be an array of 2n numbers, n >= 2, got %v", bbox)
+	}
+	for i, v := range values {
+		if _, ok := v.(float64); !ok {
+			return strictError(pointer+"/bbox/"+strconv.Itoa(i), "bbox coordinate must be a number, got %T", v)
+		}
+	}
+	return nil
+}
+
+// checkStrictCoordinates checks the coordinates are arrays of positions
+// nested depth times.
+func checkStrictCoordinates(coordinates interface{}, depth int, pointer string) error {
+	values, ok := coordinates.([]interface{})
+	if !ok {
+		return strictError(pointer, "coordinates must be an array, got %T", coordinates)
+	}
+
+	if depth == 0 {
+		if len(values) < 2 {
+			return strictError(pointer, "position must have at least 2 coordinates, got %d", len(values))
+		}
+		for i, v := range values {
+			if _, ok := v.(float64); !ok {
+				return strictError(pointer+"/"+strconv.Itoa(i), "coordinate must be a number, got %T", v)
+			}
+		}
+		return nil
+	}
+
+	for i, v := range values {
+		if err := checkStrictCoordinates(v, depth-1, pointer+"/"+strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package geojson
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStrictUnmarshalGeometry(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		pointer string
+	}{
+		{name: "point", data: `{"type":"Point","coordinates":[1,2,3]}`},
+		{name: "empty point", data: `{"type":"Point","coordinates":[]}`},
+		{name: "empty polygon", data: `{"type":"Polygon","coordinates":[]}`},
+		{name: "collection", data: `{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`},
+		{name: "unknown type", data: `{"type":"Circle","coordinates":[0,0]}`, pointer: "/type"},
+		{name: "missing type", data: `{"coordinates":[0,0]}`, pointer: "/type"},
+		{name: "missing coordinates", data: `{"type":"Point"}`, pointer: "/coordinates"},
+		{name: "missing geometries", data: `{"type":"GeometryCollection"}`, pointer: "/geometries"},
+		{name: "string coordinate", data: `{"type":"LineString","coordinates":[[0,0],[1,"1"]]}`, pointer: "/coordinates/1/1"},
+		{name: "short position", data: `{"type":"MultiPoint","coordinates":[[0,0],[1]]}`, pointer: "/coordinates/1"},
+		{name: "shallow coordinates", data: `{"type":"Polygon","coordinates":[[0,0],[1,1]]}`, pointer: "/coordinates/0/0"},
+		{name: "odd bbox", data: `{"type":"Point","bbox":[1,2,3],"coordinates":[1,2]}`, pointer: "/bbox"},
+		{name: "string bbox", data: `{"type":"Point","bbox":[1,2,"3",4],"coordinates":[1,2]}`, pointer: "/bbox/2"},
+		{name: "nested unknown type", data: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"point","coordinates":[0,0]}]}`, pointer: "/geometries/1/type"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g, err := DecodeOptions{Strict: true}.UnmarshalGeometry([]byte(c.data))
+			if c.pointer == "" {
+				if err != nil || g == nil {
+					t.Fatalf("should decode, but got %v", err)
+				}
+				return
+			}
+
+			serr, ok := err.(*StrictError)
+			if !ok {
+				t.Fatalf("should return a strict error, but got %v", err)
+			}
+			if serr.Pointer != c.pointer {
+				t.Errorf("incorrect pointer, got %q", serr.Pointer)
+			}
+		})
+	}
+}
+
+func TestStrictUnmarshalFeature(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		pointer string
+	}{
+		{name: "feature", data: `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{"a":1}}`},
+		{name: "null members", data: `{"type":"Feature","geometry":null,"properties":null}`},
+		{name: "wrong type", data: `{"type":"FeatureCollection","geometry":null,"properties":null}`, pointer: "/type"},
+		{name: "missing geometry", data: `{"type":"Feature","properties":null}`, pointer: "/geometry"},
+		{name: "missing properties", data: `{"type":"Feature","geometry":null}`, pointer: "/properties"},
+		{name: "array properties", data: `{"type":"Feature","geometry":null,"properties":[]}`, pointer: "/properties"},
+		{name: "bad geometry", data: `{"type":"Feature","geometry":{"type":"Point","coordinates":[true,0]},"properties":null}`, pointer: "/geometry/coordinates/0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := DecodeOptions{Strict: true}.UnmarshalFeature([]byte(c.data))
+			if c.pointer == "" {
+				if err != nil || f == nil {
+					t.Fatalf("should decode, but got %v", err)
+				}
+				return
+			}
+
+			serr, ok := err.(*StrictError)
+			if !ok {
+				t.Fatalf("should return a strict error, but got %v", err)
+			}
+			if serr.Pointer != c.pointer {
+				t.Errorf("incorrect pointer, got %q", serr.Pointer)
+			}
+		})
+	}
+}
+
+func TestStrictUnmarshalFeatureCollection(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":null},
+		{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},"properties":null}
+	]}`)
+	fc, err := DecodeOptions{Strict: true}.UnmarshalFeatureCollection(data)
+	if err != nil || len(fc.Features) != 2 {
+		t.Fatalf("should decode, but got %v", err)
+	}
+
+	// without strict, the unknown type can be decoded as an empty geometry
+	data = []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":null},
+		{"type":"Feature","geometry":{"type":"Circle","coordinates":[0,0]},"properties":null}
+	]}`)
+	if _, err := (DecodeOptions{AllowUnknownGeometryTypes: true}).UnmarshalFeatureCollection(data); err != nil {
+		t.Fatalf("should decode, but got %v", err)
+	}
+	_, err = DecodeOptions{Strict: true, AllowUnknownGeometryTypes: true}.UnmarshalFeatureCollection(data)
+	if err == nil {
+		t.Fatalf("should reject the unknown type")
+	}
+	p := NewProblem(http.StatusUnprocessableEntity, err)
+	if len(p.Errors) != 1 || p.Errors[0].Pointer != "/features/1/geometry/type" {
+		t.Errorf("incorrect problem, got %+v", p.Errors)
+	}
+
+	_, err = DecodeOptions{Strict: true}.UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","features":[null]}`))
+	if serr, ok := err.(*StrictError); !ok || serr.Pointer != "/features/0" {
+		t.Errorf("incorrect error for a null feature, got %v", err)
+	}
+
+	_, err = DecodeOptions{Strict: true}.UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","features":[]} []`))
+	if err == nil {
+		t.Errorf("should reject trailing data")
+	}
+}
@@ -0,0 +1,139 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// The foreign members of tombstones, see NewTombstone.
+const (
+	DeletedMember   = "deleted"
+	DeletedAtMember = "deletedAt"
+)
+
+// NewTombstone creates and initializes a tombstone: a feature standing for
+// the deleted feature with the id, in collections synchronized with Sync.
+// It has no geometry nor properties, and the foreign members "deleted",
+// true, and "deletedAt", the time of the deletion in RFC 3339 format, so it
+// survives a round trip as plain GeoJSON.
+func NewTombstone(id interface{}, at time.Time) *Feature {
+	deletedAt, _ := json.Marshal(at.UTC().Format(time.RFC3339Nano))
+	return &Feature{
+		ID:   id,
+		Type: "Feature",
+		ForeignMembers: map[string]json.RawMessage{
+			DeletedMember:   json.RawMessage("true"),
+			DeletedAtMember: deletedAt,
+		},
+	}
+}
+
+// IsTombstone returns true if the feature is a tombstone, see NewTombstone.
+func (f *Feature) IsTombstone() bool {
+	var deleted bool
+	return f != nil && json.Unmarshal(f.ForeignMembers[DeletedMember], &deleted) == nil && deleted
+}
+
+// DeletedAt returns the time of the deletion of a tombstone. It returns
+// false if the feature is not a tombstone or has no valid time.
+func (f *Feature) DeletedAt() (time.Time, bool) {
+	if !f.IsTombstone() {
+		return time.Time{}, false
+	}
+
+	var s string
+	if err := json.Unmarshal(f.ForeignMembers[DeletedAtMember], &s); err != nil {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// SoftDelete replaces the feature with the id by a tombstone deleted at
+// the time, so the deletion is synchronized with the collection. Ids are
+// compared by IDString. It returns false if there is no such feature.
+func (fc *FeatureCollection) SoftDelete(id interface{}, at time.Time) bool {
+	i := fc.indexOfID(id)
+	if i < 0 || fc.Features[i].IsTombstone() {
+		return false
+	}
+
+	fc.ReplaceFeature(i, NewTombstone(fc.Features[i].ID, at))
+	return true
+}
+
+// Sync applies the changes to the collection, for incremental
+// synchronization: the features of the changes replace the features with
+// the same id, and the others are appended. Every feature of the changes
+// needs an id. Ids are compared by IDString.
+//
+// Tombstones are kept, so the collection can be synchronized further. A
+// tombstone is only replaced by a later tombstone, so a change made before
+// the deletion does not bring the feature back.
+func (fc *FeatureCollection) Sync(changes *FeatureCollection) error {
+	for i, f := range changes.Features {
+		if f == nil {
+			return fmt.Errorf("change %d has no id", i)
+		}
+		if _, ok := f.IDString(); !ok {
+			return fmt.Errorf("change %d has no id", i)
+		}
+	}
+
+	for _, f := range changes.Features {
+		j := fc.indexOfID(f.ID)
+		if j < 0 {
+			fc.AddFeature(f)
+			continue
+		}
+		if deleted, ok := fc.Features[j].DeletedAt(); ok {
+			if at, ok := f.DeletedAt(); !ok || !at.After(deleted) {
+				continue
+			}
+		}
+		fc.ReplaceFeature(j, f)
+	}
+	return nil
+}
+
+// CompactTombstones removes the tombstones deleted before the time, once
+// every copy of the collection is synchronized, and returns how many were.
+// Tombstones without a valid time are removed too.
+func (fc *FeatureCollection) CompactTombstones(before time.Time) int {
+	kept := fc.Features[:0]
+	for _, f := range fc.Features {
+		if at, ok := f.DeletedAt(); f.IsTombstone() && (!ok || at.Before(before)) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	n := len(fc.Features) - len(kept)
+	for i := len(kept); i < len(fc.Features); i++ {
+		fc.Features[i] = nil
+	}
+	fc.Features = kept
+	return n
+}
+
+// indexOfID returns the index of the feature with the id, -1 if there is
+// none.
+func (fc *FeatureCollection) indexOfID(id interface{}) int {
+	s, ok := (&Feature{ID: id}).IDString()
+	if !ok {
+		return -1
+	}
+	for i, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+		if other, ok := f.IDString(); ok && other == s {
+			return i
+		}
+	}
+	return -1
+}
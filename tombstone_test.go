@@ -0,0 +1,112 @@
+package geojson
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTombstone(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	data, err := NewTombstone("a", at).MarshalJSON()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	expected := `{"id":"a","type":"Feature","geometry":null,"properties":null,"deleted":true,"deletedAt":"2024-03-01T11:00:00Z"}`
+	if string(data) != expected {
+		t.Errorf("incorrect tombstone, got %s", data)
+	}
+
+	f, err := UnmarshalFeature(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if !f.IsTombstone() {
+		t.Fatalf("should survive a round trip")
+	}
+	if deleted, ok := f.DeletedAt(); !ok || !deleted.Equal(at) {
+		t.Errorf("incorrect deletion time, got %v", deleted)
+	}
+
+	if NewPointFeature([]float64{0, 0}).IsTombstone() {
+		t.Errorf("a feature should not be a tombstone")
+	}
+	if _, ok := NewPointFeature([]float64{0, 0}).DeletedAt(); ok {
+		t.Errorf("a feature should have no deletion time")
+	}
+}
+
+func TestFeatureCollectionSoftDelete(t *testing.T) {
+	fc := NewFeatureCollection()
+	f := NewPointFeature([]float64{1, 2})
+	f.ID = float64(7)
+	fc.AddFeature(f)
+
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !fc.SoftDelete("7", at) {
+		t.Fatalf("should delete the feature")
+	}
+	if !fc.Features[0].IsTombstone() || fc.Features[0].ID != float64(7) {
+		t.Errorf("should replace the feature by a tombstone, got %+v", fc.Features[0])
+	}
+	if fc.SoftDelete(7, at) {
+		t.Errorf("should not delete a tombstone")
+	}
+	if fc.SoftDelete(8, at) {
+		t.Errorf("should not delete a missing feature")
+	}
+}
+
+func TestFeatureCollectionSync(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	feature := func(id string, x float64) *Feature {
+		f := NewPointFeature([]float64{x, 0})
+		f.ID = id
+		return f
+	}
+
+	fc := NewFeatureCollection()
+	fc.AddFeature(feature("a", 1))
+	fc.AddFeature(feature("b", 2))
+	fc.AddFeature(NewTombstone("c", day(2)))
+
+	changes := NewFeatureCollection()
+	changes.AddFeature(feature("a", 10))
+	changes.AddFeature(NewTombstone("b", day(3)))
+	changes.AddFeature(feature("c", 3))
+	changes.AddFeature(feature("d", 4))
+	if err := fc.Sync(changes); err != nil {
+		t.Fatalf("should sync, but got %v", err)
+	}
+
+	if len(fc.Features) != 4 {
+		t.Fatalf("incorrect number of features, got %d", len(fc.Features))
+	}
+	if fc.Features[0].Geometry.Point[0] != 10 {
+		t.Errorf("should update the feature, got %v", fc.Features[0].Geometry)
+	}
+	if !fc.Features[1].IsTombstone() {
+		t.Errorf("should delete the feature")
+	}
+	if !fc.Features[2].IsTombstone() {
+		t.Errorf("should not bring a deleted feature back")
+	}
+	if fc.Features[3].ID != "d" {
+		t.Errorf("should append the new feature, got %v", fc.Features[3].ID)
+	}
+
+	changes = NewFeatureCollection()
+	changes.AddFeature(NewPointFeature([]float64{0, 0}))
+	if err := fc.Sync(changes); err == nil {
+		t.Errorf("should reject a change without id")
+	}
+
+	if n := fc.CompactTombstones(day(3)); n != 1 {
+		t.Errorf("should compact the tombstone deleted before, got %d", n)
+	}
+	if len(fc.Features) != 3 || fc.Features[0].ID != "a" || fc.Features[1].ID != "b" || fc.Features[2].ID != "d" {
+		t.Errorf("incorrect features after compaction")
+	}
+	if n := fc.CompactTombstones(day(4)); n != 1 || len(fc.Features) != 2 {
+		t.Errorf("should compact the remaining tombstone, got %d", n)
+	}
+}
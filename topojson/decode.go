@@ -0,0 +1,268 @@
+package topojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// UnmarshalTopoJSON decodes a TopoJSON topology into a feature collection
+// holding the features of all its objects, by object name. The arcs are
+// decoded and stitched back into lines and rings.
+func UnmarshalTopoJSON(data []byte) (*geojson.FeatureCollection, error) {
+	t := &Topology{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	if t.Type != "Topology" {
+		return nil, fmt.Errorf("not a topology, got type %q", t.Type)
+	}
+
+	names := make([]string, 0, len(t.Objects))
+	for name := range t.Objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fc := geojson.NewFeatureCollection()
+	for _, name := range names {
+		objectFC, err := t.Features(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range objectFC.Features {
+			fc.AddFeature(f)
+		}
+	}
+	return fc, nil
+}
+
+// Features converts the named object of the topology into a feature
+// collection. A geometry collection object gives a feature per geometry,
+// any other object a single feature.
+func (t *Topology) Features(name string) (*geojson.FeatureCollection, error) {
+	o, ok := t.Objects[name]
+	if !ok || o == nil {
+		return nil, fmt.Errorf("no object %s in the topology", name)
+	}
+
+	d := &decoder{topology: t, arcs: make([][][]float64, len(t.Arcs))}
+
+	objects := []*Object{o}
+	if o.Type == "GeometryCollection" {
+		objects = o.Geometries
+	}
+
+	fc := geojson.NewFeatureCollection()
+	for i, o := range objects {
+		if o == nil {
+			return nil, fmt.Errorf("object %s: geometry %d is null", name, i)
+		}
+
+		g, err := d.geometry(o)
+		if err != nil {
+			return nil, fmt.Errorf("object %s: geometry %d: %v", name, i, err)
+		}
+
+		f := geojson.NewFeature(g)
+		f.ID = o.ID
+		if o.Properties != nil {
+			f.Properties = o.Properties
+		}
+		if len(o.BoundingBox) > 0 {
+			f.BoundingBox = o.BoundingBox
+		}
+		fc.AddFeature(f)
+	}
+	return fc, nil
+}
+
+type decoder struct {
+	topology *Topology
+	arcs     [][][]float64 // decoded arcs, as they are needed
+}
+
+func (d *decoder) geometry(o *Object) (*geojson.Geometry, error) {
+	var g *geojson.Geometry
+	var err error
+	switch geojson.GeometryType(o.Type) {
+	case "":
+		return nil, nil
+	case geojson.GeometryPoint:
+		var p []float64
+		if err = unmarshalMember(o.Coordinates, &p, "coordinates"); err == nil {
+			g = geojson.NewPointGeometry(d.position(p))
+		}
+	case geojson.GeometryMultiPoint:
+		var points [][]float64
+		if err = unmarshalMember(o.Coordinates, &points, "coordinates"); err == nil {
+			for i, p := range points {
+				points[i] = d.position(p)
+			}
+			g = geojson.NewMultiPointGeometry(points...)
+		}
+	case geojson.GeometryLineString:
+		var arcs []int
+		if err = unmarshalMember(o.Arcs, &arcs, "arcs"); err == nil {
+			var line [][]float64
+			if line, err = d.line(arcs, false); err == nil {
+				g = geojson.NewLineStringGeometry(line)
+			}
+		}
+	case geojson.GeometryMultiLineString, geojson.GeometryPolygon:
+		var arcs [][]int
+		if err = unmarshalMember(o.Arcs, &arcs, "arcs"); err == nil {
+			var lines [][][]float64
+			if lines, err = d.lines(arcs, o.Type == string(geojson.GeometryPolygon)); err == nil {
+				if o.Type == string(geojson.GeometryPolygon) {
+					g = geojson.NewPolygonGeometry(lines)
+				} else {
+					g = geojson.NewMultiLineStringGeometry(lines...)
+				}
+			}
+		}
+	case geojson.GeometryMultiPolygon:
+		var arcs [][][]int
+		if err = unmarshalMember(o.Arcs, &arcs, "arcs"); err == nil {
+			polygons := make([][][][]float64, len(arcs))
+			for i, rings := range arcs {
+				if polygons[i], err = d.lines(rings, true); err != nil {
+					break
+				}
+			}
+			g = geojson.NewMultiPolygonGeometry(polygons...)
+		}
+	case geojson.GeometryCollection:
+		geometries := make([]*geojson.Geometry, 0, len(o.Geometries))
+		for _, c := range o.Geometries {
+			if c == nil {
+				return nil, errors.New("null geometry in collection")
+			}
+			cg, err := d.geometry(c)
+			if err != nil {
+				return nil, err
+			}
+			if cg != nil {
+				geometries = append(geometries, cg)
+			}
+		}
+		g = geojson.NewCollectionGeometry(geometries...)
+	default:
+		return nil, fmt.Errorf("unknown geometry type %s", o.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o.BoundingBox) > 0 && o.Type != "GeometryCollection" {
+		g.BoundingBox = o.BoundingBox
+	}
+	return g, nil
+}
+
+func unmarshalMember(data json.RawMessage, v interface{}, name string) error {
+	if len(data) == 0 {
+		return fmt.Errorf("missing %s", name)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
+// position converts a quantized position into coordinates.
+func (d *decoder) position(p []float64) []float64 {
+	tr := d.topology.Transform
+	if tr == nil || len(p) < 2 {
+		return p
+	}
+
+	result := append([]float64(nil), p...)
+	result[0] = p[0]*tr.Scale[0] + tr.Translate[0]
+	result[1] = p[1]*tr.Scale[1] + tr.Translate[1]
+	return result
+}
+
+// arc returns the decoded arc of the index, reversed for negative indexes.
+func (d *decoder) arc(i int) ([][]float64, error) {
+	index := i
+	if i < 0 {
+		index = ^i
+	}
+	if index >= len(d.arcs) {
+		return nil, fmt.Errorf("arc %d out of range", i)
+	}
+
+	if d.arcs[index] == nil {
+		encoded := d.topology.Arcs[index]
+		arc := make([][]float64, len(encoded))
+		var x, y float64
+		for j, p := range encoded {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("arc %d has a position with %d coordinates", index, len(p))
+			}
+			if d.topology.Transform == nil {
+				arc[j] = p
+				continue
+			}
+
+			x, y = x+p[0], y+p[1]
+			arc[j] = d.position(append([]float64{x, y}, p[2:]...))
+		}
+		d.arcs[index] = arc
+	}
+
+	arc := d.arcs[index]
+	if i >= 0 {
+		return arc, nil
+	}
+
+	reversed := make([][]float64, len(arc))
+	for j, p := range arc {
+		reversed[len(arc)-1-j] = p
+	}
+	return reversed, nil
+}
+
+// line stitches the arcs together, each starting where the previous ends.
+// Rings are padded to four positions if their arcs are degenerate.
+func (d *decoder) line(arcs []int, ring bool) ([][]float64, error) {
+	var line [][]float64
+	for i, a := range arcs {
+		arc, err := d.arc(a)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && len(arc) > 0 {
+			arc = arc[1:]
+		}
+		for _, p := range arc {
+			line = append(line, append([]float64(nil), p...))
+		}
+	}
+
+	if len(line) == 0 {
+		return [][]float64{}, nil
+	}
+	if !ring && len(line) < 2 {
+		line = append(line, append([]float64(nil), line[0]...))
+	}
+	for ring && len(line) < 4 {
+		line = append(line, append([]float64(nil), line[0]...))
+	}
+	return line, nil
+}
+
+func (d *decoder) lines(arcs [][]int, ring bool) ([][][]float64, error) {
+	lines := make([][][]float64, len(arcs))
+	for i, a := range arcs {
+		var err error
+		if lines[i], err = d.line(a, ring); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,137 @@
+package topojson
+
+import (
+	"encoding/json"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// the example of the TopoJSON specification
+const testTopology = `{
+	"type": "Topology",
+	"transform": {"scale": [0.0005000500050005, 0.00010001000100010001], "translate": [100, 0]},
+	"objects": {
+		"example": {
+			"type": "GeometryCollection",
+			"geometries": [
+				{"type": "Point", "properties": {"prop0": "value0"}, "coordinates": [4000, 5000]},
+				{"type": "LineString", "properties": {"prop0": "value0", "prop1": 0}, "arcs": [0]},
+				{"type": "Polygon", "id": "p", "properties": {"prop0": "value0", "prop1": {"this": "that"}}, "arcs": [[1]]},
+				{"type": null, "properties": {"prop0": "empty"}}
+			]
+		}
+	},
+	"arcs": [
+		[[4000, 0], [1999, 9999], [2000, -9999], [2000, 9999]],
+		[[0, 0], [0, 9999], [2000, 0], [0, -9999], [-2000, 0]]
+	]
+}`
+
+func TestUnmarshalTopoJSON(t *testing.T) {
+	fc, err := UnmarshalTopoJSON([]byte(testTopology))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if len(fc.Features) != 4 {
+		t.Fatalf("should have a feature per geometry, got %d", len(fc.Features))
+	}
+
+	expected := []*geojson.Geometry{
+		geojson.NewPointGeometry([]float64{102, 0.5}),
+		geojson.NewLineStringGeometry([][]float64{{102, 0}, {103, 1}, {104, 0}, {105, 1}}),
+		geojson.NewPolygonGeometry([][][]float64{{{100, 0}, {100, 1}, {101, 1}, {101, 0}, {100, 0}}}),
+	}
+	for i, g := range expected {
+		if !fc.Features[i].Geometry.EqualWithTolerance(g, 1e-3) {
+			t.Errorf("incorrect geometry %d, got %+v", i, fc.Features[i].Geometry)
+		}
+	}
+
+	if fc.Features[2].ID != "p" || fc.Features[1].PropertyMustString("prop0") != "value0" {
+		t.Errorf("should keep the ids and properties, got %+v", fc.Features[2])
+	}
+	if fc.Features[3].Geometry != nil || fc.Features[3].PropertyMustString("prop0") != "empty" {
+		t.Errorf("should decode null geometries, got %+v", fc.Features[3])
+	}
+}
+
+func TestTopologyRoundTrip(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}))
+	fc.AddFeature(geojson.NewMultiPolygonFeature(
+		[][][]float64{{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}},
+		[][][]float64{{{3, 0}, {4, 0}, {4, 1}, {3, 0}}},
+	))
+	fc.AddFeature(geojson.NewMultiLineStringFeature([][]float64{{0, 2}, {4, 2}}, [][]float64{{2, 2}, {2, 3}}))
+	fc.AddFeature(geojson.NewMultiPointFeature([]float64{0, 4}, []float64{4, 4}))
+
+	for _, q := range []int{0, 5} {
+		topology, err := Encode(fc, Options{Quantization: q})
+		if err != nil {
+			t.Fatalf("should encode, but got %v", err)
+		}
+		data, err := json.Marshal(topology)
+		if err != nil {
+			t.Fatalf("should marshal, but got %v", err)
+		}
+
+		decoded, err := UnmarshalTopoJSON(data)
+		if err != nil {
+			t.Fatalf("should unmarshal, but got %v", err)
+		}
+		if len(decoded.Features) != len(fc.Features) {
+			t.Fatalf("should decode all the features, got %d", len(decoded.Features))
+		}
+
+		if !sameRings(decoded.Features[0].Geometry.Polygon[0], fc.Features[0].Geometry.Polygon[0]) {
+			t.Errorf("should round trip the polygon with quantization %d, got %v", q, decoded.Features[0].Geometry.Polygon)
+		}
+		for i, p := range fc.Features[1].Geometry.MultiPolygon {
+			if !sameRings(decoded.Features[1].Geometry.MultiPolygon[i][0], p[0]) {
+				t.Errorf("should round trip the multi polygon with quantization %d, got %v", q, decoded.Features[1].Geometry.MultiPolygon)
+			}
+		}
+		for _, i := range []int{2, 3} {
+			if !decoded.Features[i].Geometry.Equal(fc.Features[i].Geometry) {
+				t.Errorf("should round trip %v with quantization %d, got %+v", fc.Features[i].Geometry.Type, q, decoded.Features[i].Geometry)
+			}
+		}
+	}
+}
+
+func TestUnmarshalTopoJSONInvalid(t *testing.T) {
+	cases := map[string]string{
+		"not json":     `{`,
+		"type":         `{"type": "FeatureCollection"}`,
+		"arc index":    `{"type": "Topology", "objects": {"a": {"type": "LineString", "arcs": [3]}}, "arcs": []}`,
+		"missing arcs": `{"type": "Topology", "objects": {"a": {"type": "Polygon"}}, "arcs": []}`,
+		"type object":  `{"type": "Topology", "objects": {"a": {"type": "Circle"}}, "arcs": []}`,
+	}
+
+	for name, data := range cases {
+		if _, err := UnmarshalTopoJSON([]byte(data)); err == nil {
+			t.Errorf("should reject invalid %s", name)
+		}
+	}
+}
+
+// sameRings compares closed rings, ignoring where they start.
+func sameRings(a, b [][]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	n := len(a) - 1
+	for shift := 0; shift < n; shift++ {
+		same := true
+		for i := 0; i < n && same; i++ {
+			p, q := a[(i+shift)%n], b[i]
+			same = p[0] == q[0] && p[1] == q[1]
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,484 @@
+package topojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+// DefaultObjectName is the name of the object holding the features when
+// the options do not set one.
+const DefaultObjectName = "collection"
+
+// Options configures the conversion into a topology.
+type Options struct {
+	// Name is the name of the geometry collection object holding the
+	// features, DefaultObjectName if empty.
+	Name string
+
+	// Quantization is the number of distinct values of each coordinate,
+	// like 1e4 or 1e5. The positions are then stored as integers, the
+	// arcs delta encoded, with a transform back to coordinates.
+	// Zero keeps the coordinates as they are.
+	Quantization int
+}
+
+// Encode converts the feature collection into a topology, with the features
+// as a single geometry collection object. Lines and rings are cut where they
+// meet other lines or rings, and the resulting arcs are stored once,
+// however many geometries use them. TopoJSON positions are two dimensional,
+// altitudes are dropped.
+func Encode(fc *geojson.FeatureCollection, opts Options) (*Topology, error) {
+	if opts.Quantization == 1 || opts.Quantization < 0 {
+		return nil, fmt.Errorf("quantization must be 0 or at least 2, got %d", opts.Quantization)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = DefaultObjectName
+	}
+
+	e := &encoder{
+		points: make(map[point]*neighbours),
+		arcIDs: make(map[string]int),
+	}
+	if err := e.boundingBox(fc); err != nil {
+		return nil, err
+	}
+	t := &Topology{
+		Type:    "Topology",
+		Objects: make(map[string]*Object),
+	}
+	if e.hasPositions {
+		t.BoundingBox = []float64{e.min[0], e.min[1], e.max[0], e.max[1]}
+	}
+
+	e.scale, e.translate = [2]float64{1, 1}, [2]float64{0, 0}
+	if opts.Quantization > 0 {
+		e.quantized = true
+		for i := 0; i < 2; i++ {
+			e.translate[i] = e.min[i]
+			if e.max[i] > e.min[i] {
+				e.scale[i] = (e.max[i] - e.min[i]) / float64(opts.Quantization-1)
+			}
+		}
+		t.Transform = &Transform{Scale: e.scale, Translate: e.translate}
+	}
+
+	// the lines are collected first, as their junctions are only known
+	// once all of them are, then cut into arcs
+	collection := &Object{Type: "GeometryCollection", Geometries: make([]*Object, 0, len(fc.Features))}
+	for i, f := range fc.Features {
+		if f == nil {
+			return nil, fmt.Errorf("feature %d is nil", i)
+		}
+		o, err := e.geometry(f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		o.ID = f.ID
+		if len(f.Properties) > 0 {
+			o.Properties = f.Properties
+		}
+		collection.Geometries = append(collection.Geometries, o)
+	}
+
+	e.findJunctions()
+	if err := e.resolveArcs(); err != nil {
+		return nil, err
+	}
+
+	t.Objects[name] = collection
+	t.Arcs = e.encodedArcs()
+	if t.Arcs == nil {
+		t.Arcs = [][][]float64{}
+	}
+	return t, nil
+}
+
+// A point is a quantized position.
+type point [2]float64
+
+// neighbours records the positions around a point the first time it is met,
+// a point met with other neighbours being a junction.
+type neighbours struct {
+	previous, next point
+	junction       bool
+}
+
+type encoder struct {
+	quantized        bool
+	scale, translate [2]float64
+	min, max         [2]float64
+	hasPositions     bool
+
+	lines  [][]point // lines and closed rings, as referenced by the objects
+	rings  []bool
+	points map[point]*neighbours
+
+	arcs     [][]point
+	arcIDs   map[string]int
+	pendings []pending
+}
+
+// pending holds the indexes of the lines of an object until they are cut
+// into arcs.
+type pending struct {
+	object *Object
+	lines  interface{} // int, []int or [][]int
+}
+
+func (e *encoder) boundingBox(fc *geojson.FeatureCollection) error {
+	e.min = [2]float64{math.Inf(1), math.Inf(1)}
+	e.max = [2]float64{math.Inf(-1), math.Inf(-1)}
+
+	var err error
+	for _, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+		forEachPosition(f.Geometry, func(p []float64) {
+			if len(p) < 2 {
+				if len(p) != 0 {
+					err = errors.New("position must have at least 2 coordinates")
+				}
+				return
+			}
+			if math.IsNaN(p[0]) || math.IsNaN(p[1]) || math.IsInf(p[0], 0) || math.IsInf(p[1], 0) {
+				err = fmt.Errorf("position %v can not be encoded", p)
+				return
+			}
+			e.hasPositions = true
+			for i := 0; i < 2; i++ {
+				e.min[i] = math.Min(e.min[i], p[i])
+				e.max[i] = math.Max(e.max[i], p[i])
+			}
+		})
+	}
+	return err
+}
+
+// quantize converts the position into the point stored in the topology.
+func (e *encoder) quantize(p []float64) point {
+	if !e.quantized {
+		return point{p[0], p[1]}
+	}
+	return point{
+		math.Round((p[0] - e.translate[0]) / e.scale[0]),
+		math.Round((p[1] - e.translate[1]) / e.scale[1]),
+	}
+}
+
+func (e *encoder) geometry(g *geojson.Geometry) (*Object, error) {
+	if g == nil {
+		return &Object{}, nil
+	}
+
+	o := &Object{Type: string(g.Type)}
+	var err error
+	switch g.Type {
+	case geojson.GeometryPoint:
+		if len(g.Point) == 0 {
+			o.Coordinates = json.RawMessage("[]")
+			break
+		}
+		o.Coordinates, err = json.Marshal(e.quantize(g.Point))
+	case geojson.GeometryMultiPoint:
+		points := make([]point, 0, len(g.MultiPoint))
+		for _, p := range g.MultiPoint {
+			if len(p) > 0 {
+				points = append(points, e.quantize(p))
+			}
+		}
+		o.Coordinates, err = json.Marshal(points)
+	case geojson.GeometryLineString:
+		e.addPending(o, e.addLine(g.LineString, false))
+	case geojson.GeometryMultiLineString:
+		lines := make([]int, len(g.MultiLineString))
+		for i, l := range g.MultiLineString {
+			lines[i] = e.addLine(l, false)
+		}
+		e.addPending(o, lines)
+	case geojson.GeometryPolygon:
+		e.addPending(o, e.addRings(g.Polygon))
+	case geojson.GeometryMultiPolygon:
+		polygons := make([][]int, len(g.MultiPolygon))
+		for i, p := range g.MultiPolygon {
+			polygons[i] = e.addRings(p)
+		}
+		e.addPending(o, polygons)
+	case geojson.GeometryCollection:
+		o.Geometries = make([]*Object, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			co, err := e.geometry(c)
+			if err != nil {
+				return nil, err
+			}
+			o.Geometries = append(o.Geometries, co)
+		}
+	default:
+		return nil, fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+
+	return o, err
+}
+
+func (e *encoder) addRings(rings [][][]float64) []int {
+	indexes := make([]int, len(rings))
+	for i, r := range rings {
+		indexes[i] = e.addLine(r, true)
+	}
+	return indexes
+}
+
+// addLine adds the quantized line, without consecutive duplicate points,
+// returning its index.
+func (e *encoder) addLine(positions [][]float64, ring bool) int {
+	line := make([]point, 0, len(positions))
+	for _, p := range positions {
+		if len(p) == 0 {
+			continue
+		}
+		q := e.quantize(p)
+		if len(line) == 0 || line[len(line)-1] != q {
+			line = append(line, q)
+		}
+	}
+	if ring && len(line) > 0 && line[0] != line[len(line)-1] {
+		line = append(line, line[0])
+	}
+	for len(line) > 0 && len(line) < 2 {
+		line = append(line, line[0])
+	}
+
+	e.lines = append(e.lines, line)
+	e.rings = append(e.rings, ring)
+	return len(e.lines) - 1
+}
+
+func (e *encoder) addPending(o *Object, lines interface{}) {
+	e.pendings = append(e.pendings, pending{o, lines})
+}
+
+// findJunctions marks the points where lines start, end, meet or part.
+func (e *encoder) findJunctions() {
+	visit := func(p, previous, next point) {
+		n, ok := e.points[p]
+		if !ok {
+			e.points[p] = &neighbours{previous: previous, next: next}
+			return
+		}
+		if !(n.previous == previous && n.next == next) && !(n.previous == next && n.next == previous) {
+			n.junction = true
+		}
+	}
+
+	for i, line := range e.lines {
+		if len(line) == 0 {
+			continue
+		}
+		if e.rings[i] {
+			m := len(line) - 1
+			for j := 0; j < m; j++ {
+				visit(line[j], line[(j+m-1)%m], line[(j+1)%m])
+			}
+			continue
+		}
+
+		for j := 1; j < len(line)-1; j++ {
+			visit(line[j], line[j-1], line[j+1])
+		}
+		for _, end := range []point{line[0], line[len(line)-1]} {
+			if _, ok := e.points[end]; !ok {
+				e.points[end] = &neighbours{}
+			}
+			e.points[end].junction = true
+		}
+	}
+}
+
+// resolveArcs cuts the lines of the objects into arcs and sets their indexes.
+func (e *encoder) resolveArcs() error {
+	cut := make([][]int, len(e.lines))
+	arcsOf := func(i int) []int {
+		if cut[i] == nil {
+			cut[i] = e.cut(i)
+		}
+		return cut[i]
+	}
+
+	for _, p := range e.pendings {
+		var arcs interface{}
+		switch lines := p.lines.(type) {
+		case int:
+			arcs = arcsOf(lines)
+		case []int:
+			a := make([][]int, len(lines))
+			for i, l := range lines {
+				a[i] = arcsOf(l)
+			}
+			arcs = a
+		case [][]int:
+			a := make([][][]int, len(lines))
+			for i, polygon := range lines {
+				a[i] = make([][]int, len(polygon))
+				for j, l := range polygon {
+					a[i][j] = arcsOf(l)
+				}
+			}
+			arcs = a
+		}
+
+		var err error
+		if p.object.Arcs, err = json.Marshal(arcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut splits the line at its junctions, returning the indexes of its arcs.
+func (e *encoder) cut(i int) []int {
+	line := e.lines[i]
+	if len(line) == 0 {
+		return []int{}
+	}
+
+	if e.rings[i] {
+		m := len(line) - 1
+		start := -1
+		for j := 0; j < m; j++ {
+			if e.points[line[j]].junction {
+				start = j
+				break
+			}
+		}
+		if start < 0 {
+			// an isolated ring starts at its smallest point, so the same
+			// ring met elsewhere gives the same arc
+			start = 0
+			for j := 1; j < m; j++ {
+				if less(line[j], line[start]) {
+					start = j
+				}
+			}
+		}
+
+		rotated := make([]point, 0, len(line))
+		rotated = append(rotated, line[start:m]...)
+		rotated = append(rotated, line[:start]...)
+		line = append(rotated, rotated[0])
+	}
+
+	var arcs []int
+	from := 0
+	for j := 1; j < len(line); j++ {
+		if j != len(line)-1 && !e.points[line[j]].junction {
+			continue
+		}
+		arcs = append(arcs, e.arc(line[from:j+1]))
+		from = j
+	}
+	return arcs
+}
+
+// arc returns the index of the arc, or the ones' complement of the index of
+// the same arc in the other direction, adding it if new.
+func (e *encoder) arc(a []point) int {
+	if i, ok := e.arcIDs[arcKey(a, false)]; ok {
+		return i
+	}
+	if i, ok := e.arcIDs[arcKey(a, true)]; ok {
+		return ^i
+	}
+
+	e.arcIDs[arcKey(a, false)] = len(e.arcs)
+	e.arcs = append(e.arcs, a)
+	return len(e.arcs) - 1
+}
+
+// encodedArcs returns the arcs, delta encoded when quantized.
+func (e *encoder) encodedArcs() [][][]float64 {
+	var arcs [][][]float64
+	for _, a := range e.arcs {
+		arc := make([][]float64, len(a))
+		previous := point{}
+		for i, p := range a {
+			if e.quantized {
+				arc[i] = []float64{p[0] - previous[0], p[1] - previous[1]}
+				previous = p
+			} else {
+				arc[i] = []float64{p[0], p[1]}
+			}
+		}
+		arcs = append(arcs, arc)
+	}
+	return arcs
+}
+
+func arcKey(a []point, reversed bool) string {
+	var b strings.Builder
+	for i := range a {
+		p := a[i]
+		if reversed {
+			p = a[len(a)-1-i]
+		}
+		b.WriteString(strconv.FormatFloat(p[0], 'g', -1, 64))
+		b.WriteByte(',')
+		b.WriteString(strconv.FormatFloat(p[1], 'g', -1, 64))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+func less(a, b point) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+func forEachPosition(g *geojson.Geometry, fn func(p []float64)) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		fn(g.Point)
+	case geojson.GeometryMultiPoint:
+		for _, p := range g.MultiPoint {
+			fn(p)
+		}
+	case geojson.GeometryLineString:
+		for _, p := range g.LineString {
+			fn(p)
+		}
+	case geojson.GeometryMultiLineString:
+		for _, l := range g.MultiLineString {
+			for _, p := range l {
+				fn(p)
+			}
+		}
+	case geojson.GeometryPolygon:
+		for _, r := range g.Polygon {
+			for _, p := range r {
+				fn(p)
+			}
+		}
+	case geojson.GeometryMultiPolygon:
+		for _, polygon := range g.MultiPolygon {
+			for _, r := range polygon {
+				for _, p := range r {
+					fn(p)
+				}
+			}
+		}
+	case geojson.GeometryCollection:
+		for _, c := range g.Geometries {
+			forEachPosition(c, fn)
+		}
+	}
+}
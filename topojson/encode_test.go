@@ -0,0 +1,158 @@
+package topojson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	geojson "github.com/fmechant/go.geojson"
+)
+
+func TestEncodeSharedBoundary(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	a := geojson.NewPolygonFeature([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}})
+	a.ID = "a"
+	a.SetProperty("name", "A")
+	fc.AddFeature(a)
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}}))
+
+	topology, err := Encode(fc, Options{})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	if len(topology.Arcs) != 3 {
+		t.Fatalf("should share the common edge, got arcs %v", topology.Arcs)
+	}
+
+	objects := topology.Objects[DefaultObjectName].Geometries
+	if len(objects) != 2 {
+		t.Fatalf("should have an object per feature, got %d", len(objects))
+	}
+	if objects[0].ID != "a" || objects[0].Properties["name"] != "A" {
+		t.Errorf("should keep the id and properties, got %+v", objects[0])
+	}
+
+	var first, second [][]int
+	json.Unmarshal(objects[0].Arcs, &first)
+	json.Unmarshal(objects[1].Arcs, &second)
+
+	shared := 0
+	for _, i := range first[0] {
+		for _, j := range second[0] {
+			if i == ^j {
+				shared++
+			}
+		}
+	}
+	if shared != 1 {
+		t.Errorf("should reference the shared arc reversed, got %v and %v", first, second)
+	}
+
+	expected := []float64{0, 0, 2, 1}
+	for i, v := range expected {
+		if topology.BoundingBox[i] != v {
+			t.Errorf("incorrect bounding box, got %v", topology.BoundingBox)
+			break
+		}
+	}
+}
+
+func TestEncodeQuantization(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewLineStringFeature([][]float64{{0, 0}, {1, 0.5}, {2, 1}}))
+	fc.AddFeature(geojson.NewPointFeature([]float64{2, 0.5}))
+
+	topology, err := Encode(fc, Options{Quantization: 3, Name: "roads"})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	if topology.Transform == nil || topology.Transform.Scale != [2]float64{1, 0.5} || topology.Transform.Translate != [2]float64{0, 0} {
+		t.Errorf("incorrect transform, got %+v", topology.Transform)
+	}
+
+	data, _ := json.Marshal(topology.Arcs)
+	if string(data) != "[[[0,0],[1,1],[1,1]]]" {
+		t.Errorf("should delta encode the arcs, got %s", data)
+	}
+
+	point := topology.Objects["roads"].Geometries[1]
+	if string(point.Coordinates) != "[2,1]" {
+		t.Errorf("should quantize the points, got %s", point.Coordinates)
+	}
+
+	if _, err := Encode(fc, Options{Quantization: 1}); err == nil {
+		t.Errorf("should reject a quantization of 1")
+	}
+}
+
+func TestEncodeJunctions(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewLineStringFeature([][]float64{{0, 1}, {1, 1}, {2, 1}}))
+	fc.AddFeature(geojson.NewLineStringFeature([][]float64{{1, 0}, {1, 1}, {1, 2}}))
+
+	topology, err := Encode(fc, Options{})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	if len(topology.Arcs) != 4 {
+		t.Errorf("should cut the lines where they cross, got arcs %v", topology.Arcs)
+	}
+}
+
+func TestEncodeIsolatedRings(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+	}))
+	fc.AddFeature(geojson.NewPolygonFeature([][][]float64{{{4, 4}, {4, 2}, {2, 2}, {2, 4}, {4, 4}}}))
+
+	topology, err := Encode(fc, Options{})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+	if len(topology.Arcs) != 2 {
+		t.Errorf("should share the ring of the island and the hole, got arcs %v", topology.Arcs)
+	}
+}
+
+func TestEncodeNullGeometry(t *testing.T) {
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(geojson.NewFeature(nil))
+	fc.AddFeature(geojson.NewFeature(geojson.NewCollectionGeometry(
+		geojson.NewPointGeometry([]float64{1, 2}),
+		geojson.NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+	)))
+
+	topology, err := Encode(fc, Options{})
+	if err != nil {
+		t.Fatalf("should encode, but got %v", err)
+	}
+
+	data, err := json.Marshal(topology)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	expected := `{"type":"Topology","bbox":[1,2,3,4],"objects":{"collection":{"type":"GeometryCollection","geometries":[` +
+		`{"type":null},` +
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"MultiLineString","arcs":[[0]]}]}]}},` +
+		`"arcs":[[[1,2],[3,4]]]}`
+	if string(data) != expected {
+		t.Errorf("incorrect topology, expected %s, got %s", expected, data)
+	}
+
+	if _, err := Encode(collection(&geojson.Geometry{Type: "Circle"}), Options{}); err == nil || !strings.Contains(err.Error(), "Circle") {
+		t.Errorf("should reject unknown geometries, got %v", err)
+	}
+}
+
+func collection(geometries ...*geojson.Geometry) *geojson.FeatureCollection {
+	fc := geojson.NewFeatureCollection()
+	for _, g := range geometries {
+		fc.AddFeature(geojson.NewFeature(g))
+	}
+	return fc
+}
@@ -0,0 +1,53 @@
+/*
+Package topojson converts GeoJSON feature collections to and from TopoJSON.
+TopoJSON stores the lines and polygon rings as arcs shared between the
+geometries, so common boundaries are only stored once, optionally with
+quantized and delta encoded coordinates.
+*/
+package topojson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// A Topology is a TopoJSON topology object.
+type Topology struct {
+	Type        string             `json:"type"`
+	BoundingBox []float64          `json:"bbox,omitempty"`
+	Transform   *Transform         `json:"transform,omitempty"`
+	Objects     map[string]*Object `json:"objects"`
+	Arcs        [][][]float64      `json:"arcs"`
+}
+
+// A Transform maps the quantized positions of a topology back to their
+// coordinates.
+type Transform struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// An Object is a TopoJSON geometry object. Lines and polygons reference
+// arcs of the topology by index, the ones' complement of the index meaning
+// the arc is reversed. An empty type is a null geometry.
+type Object struct {
+	Type        string                 `json:"type"`
+	ID          interface{}            `json:"id,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	BoundingBox []float64              `json:"bbox,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Arcs        json.RawMessage        `json:"arcs,omitempty"`
+	Geometries  []*Object              `json:"geometries,omitempty"`
+}
+
+// MarshalJSON converts the object into JSON, with a null type for null
+// geometries.
+func (o Object) MarshalJSON() ([]byte, error) {
+	type object Object
+	data, err := json.Marshal(object(o))
+	if err != nil || o.Type != "" {
+		return data, err
+	}
+
+	return append([]byte(`{"type":null`), bytes.TrimPrefix(data, []byte(`{"type":""`))...), nil
+}
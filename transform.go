@@ -0,0 +1,281 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// A Transformer converts a position between coordinate reference systems,
+// z being 0 for two dimensional positions. Implementations can wrap PROJ
+// bindings or custom datum shifts.
+type Transformer interface {
+	Transform(x, y, z float64) (float64, float64, float64, error)
+}
+
+// A CRSTransformer is a Transformer telling the coordinate reference system
+// of the positions it returns, nil for the default one of GeoJSON, so the
+// transformed objects get it. The objects transformed by other transformers
+// lose their crs members, which no longer apply.
+type CRSTransformer interface {
+	Transformer
+	TargetCRS() CRS
+}
+
+// TransformerFunc adapts a function to a Transformer.
+type TransformerFunc func(x, y, z float64) (float64, float64, float64, error)
+
+// Transform calls f(x, y, z).
+func (f TransformerFunc) Transform(x, y, z float64) (float64, float64, float64, error) {
+	return f(x, y, z)
+}
+
+// The built-in transformers between WGS 84 longitude/latitude (EPSG:4326)
+// and Web Mercator meters (EPSG:3857).
+var (
+	ToWebMercator   Transformer = namedTransformer{"EPSG:4326 to EPSG:3857", toWebMercator, NewEPSGCRS(3857)}
+	FromWebMercator Transformer = namedTransformer{"EPSG:3857 to EPSG:4326", fromWebMercator, nil}
+)
+
+// A namedTransformer is a TransformerFunc described by its name, to the
+// target coordinate reference system.
+type namedTransformer struct {
+	name   string
+	f      TransformerFunc
+	target CRS
+}
+
+func (t namedTransformer) Transform(x, y, z float64) (float64, float64, float64, error) {
+	return t.f(x, y, z)
+}
+
+func (t namedTransformer) TargetCRS() CRS {
+	return t.target
+}
+
+func (t namedTransformer) String() string {
+	return t.name
+}
+
+// webMercatorMaxLatitude is the latitude where Web Mercator becomes a square.
+const webMercatorMaxLatitude = 85.0511287798066
+
+func toWebMercator(x, y, z float64) (float64, float64, float64, error) {
+	if y < -90 || y > 90 {
+		return 0, 0, 0, fmt.Errorf("latitude %v out of range", y)
+	}
+	y = math.Max(-webMercatorMaxLatitude, math.Min(webMercatorMaxLatitude, y))
+
+	return radians(x) * 6378137, math.Log(math.Tan(math.Pi/4+radians(y)/2)) * 6378137, z, nil
+}
+
+func fromWebMercator(x, y, z float64) (float64, float64, float64, error) {
+	return degrees(x / 6378137), degrees(2*math.Atan(math.Exp(y/6378137)) - math.Pi/2), z, nil
+}
+
+// Affine is a Transformer applying x' = A*x + B*y + C and y' = D*x + E*y + F,
+// leaving z as is. It covers translations, scaling and rotations.
+type Affine struct {
+	A, B, C float64
+	D, E, F float64
+}
+
+// Transform applies the affine transformation.
+func (a Affine) Transform(x, y, z float64) (float64, float64, float64, error) {
+	return a.A*x + a.B*y + a.C, a.D*x + a.E*y + a.F, z, nil
+}
+
+// Chain is a Transformer applying its transformers in order.
+type Chain []Transformer
+
+// Transform applies the transformers in order, stopping at the first error.
+func (c Chain) Transform(x, y, z float64) (float64, float64, float64, error) {
+	var err error
+	for _, t := range c {
+		if x, y, z, err = t.Transform(x, y, z); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return x, y, z, nil
+}
+
+// TargetCRS returns the coordinate reference system of the last transformer
+// of the chain, nil if it does not tell it.
+func (c Chain) TargetCRS() CRS {
+	if len(c) == 0 {
+		return nil
+	}
+	if t, ok := c[len(c)-1].(CRSTransformer); ok {
+		return t.TargetCRS()
+	}
+	return nil
+}
+
+// TransformGeometry returns a copy of the geometry with all its positions
+// transformed. Positions keep their dimension, and coordinates beyond the
+// third, like measures, are kept as is. The bounding boxes of the geometry
+// and of its members are recomputed, and the copy gets the coordinate
+// reference system of the transformer, see CRSTransformer. The
+// transformation is recorded in the provenance of the copy.
+func TransformGeometry(g *Geometry, t Transformer) (*Geometry, error) {
+	c, err := transformGeometry(g, t)
+	if c != nil {
+		c.CRS = targetCRS(t)
+	}
+	return c, err
+}
+
+// transformGeometry transforms the geometry like TransformGeometry, the
+// geometry and its members only keeping a crs member if they had one.
+func transformGeometry(g *Geometry, t Transformer) (*Geometry, error) {
+	c := g.Clone()
+
+	var err error
+	forEachPosition(c, func(p []float64) {
+		if err != nil || len(p) < 2 {
+			return
+		}
+
+		z := 0.0
+		if len(p) > 2 {
+			z = p[2]
+		}
+
+		var x, y float64
+		x, y, z, err = t.Transform(p[0], p[1], z)
+		if err != nil {
+			err = fmt.Errorf("transforming %v: %v", p, err)
+			return
+		}
+
+		p[0], p[1] = x, y
+		if len(p) > 2 {
+			p[2] = z
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		transformedMembers(c, t)
+		c.RecordTransformation(Transformation{
+			Operation:  OperationTransform,
+			Parameters: map[string]interface{}{"transformer": describeTransformer(t)},
+		})
+	}
+
+	return c, nil
+}
+
+// TransformFeature returns a copy of the feature with its geometry
+// transformed, see TransformGeometry. The copy gets the coordinate
+// reference system of the transformer.
+func TransformFeature(f *Feature, t Transformer) (*Feature, error) {
+	c, err := transformFeature(f, t)
+	if c != nil {
+		c.CRS = targetCRS(t)
+	}
+	return c, err
+}
+
+// transformFeature transforms the feature like TransformFeature, the feature
+// and its geometry only keeping a crs member if they had one.
+func transformFeature(f *Feature, t Transformer) (*Feature, error) {
+	if f == nil {
+		return nil, nil
+	}
+	c := f.Clone()
+
+	g, err := transformGeometry(c.Geometry, t)
+	if err != nil {
+		return nil, err
+	}
+	c.Geometry = g
+	c.BoundingBox = transformedBoundingBox(g, len(c.BoundingBox))
+	c.CRS = transformedCRS(c.CRS, t)
+
+	return c, nil
+}
+
+// TransformFeatureCollection returns a copy of the feature collection with
+// the geometries of all its features transformed, see TransformGeometry.
+// The copy gets the coordinate reference system of the transformer.
+func TransformFeatureCollection(fc *FeatureCollection, t Transformer) (*FeatureCollection, error) {
+	c := &FeatureCollection{
+		Type:           fc.Type,
+		CRS:            targetCRS(t),
+		ForeignMembers: cloneForeignMembers(fc.ForeignMembers),
+		Features:       make([]*Feature, 0, len(fc.Features)),
+	}
+
+	all := &Geometry{Type: GeometryCollection}
+	for i, f := range fc.Features {
+		tf, err := transformFeature(f, t)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		c.Features = append(c.Features, tf)
+		if tf != nil {
+			all.Geometries = append(all.Geometries, tf.Geometry)
+		}
+	}
+	c.BoundingBox = transformedBoundingBox(all, len(fc.BoundingBox))
+
+	return c, nil
+}
+
+// transformedMembers recomputes the bounding boxes of the transformed
+// geometry and of its members, and replaces the crs members they have by
+// the one of the transformer.
+func transformedMembers(g *Geometry, t Transformer) {
+	g.BoundingBox = transformedBoundingBox(g, len(g.BoundingBox))
+	g.CRS = transformedCRS(g.CRS, t)
+	for _, m := range g.Geometries {
+		if m != nil {
+			transformedMembers(m, t)
+		}
+	}
+}
+
+// targetCRS returns the crs member of the coordinate reference system of
+// the transformer, nil if it is the default one or unknown.
+func targetCRS(t Transformer) map[string]interface{} {
+	if ct, ok := t.(CRSTransformer); ok {
+		return crsObject(ct.TargetCRS())
+	}
+	return nil
+}
+
+// transformedCRS returns the crs member replacing the given one after the
+// transformation, nil if there was none.
+func transformedCRS(crs map[string]interface{}, t Transformer) map[string]interface{} {
+	if crs == nil {
+		return nil
+	}
+	return targetCRS(t)
+}
+
+// transformedBoundingBox recomputes a bounding box of the given length,
+// returning nil if there was none.
+func transformedBoundingBox(g *Geometry, length int) []float64 {
+	if length == 0 {
+		return nil
+	}
+
+	bb := boundingBox(g)
+	if length != 6 || bb == nil {
+		return bb
+	}
+
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	forEachPosition(g, func(p []float64) {
+		if len(p) > 2 {
+			minZ, maxZ = math.Min(minZ, p[2]), math.Max(maxZ, p[2])
+		}
+	})
+	if math.IsInf(minZ, 1) {
+		minZ, maxZ = 0, 0
+	}
+
+	return []float64{bb[0], bb[1], minZ, bb[2], bb[3], maxZ}
+}
@@ -0,0 +1,137 @@
+package geojson
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWebMercator(t *testing.T) {
+	x, y, z, err := ToWebMercator.Transform(180, 85.0511287798066, 12)
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if math.Abs(x-20037508.34) > 0.01 || math.Abs(y-20037508.34) > 0.01 || z != 12 {
+		t.Errorf("incorrect projection, got %v %v %v", x, y, z)
+	}
+
+	lon, lat, _, _ := FromWebMercator.Transform(x/2, y/3, 0)
+	if math.Abs(lon-90) > 1e-9 || lat <= 0 || lat >= 85 {
+		t.Errorf("incorrect inverse projection, got %v %v", lon, lat)
+	}
+
+	if _, _, _, err := ToWebMercator.Transform(0, 91, 0); err == nil {
+		t.Errorf("should fail on invalid latitudes")
+	}
+}
+
+func TestTransformGeometry(t *testing.T) {
+	g := NewLineStringGeometry([][]float64{{1, 2}, {3, 4, 5, 6}})
+	g.BoundingBox = []float64{1, 2, 3, 4}
+
+	moved, err := TransformGeometry(g, Chain{Affine{A: 2, E: 2}, Affine{A: 1, C: 10, E: 1, F: -1}})
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+
+	expected := NewLineStringGeometry([][]float64{{12, 3}, {16, 7, 5, 6}})
+	if !moved.Equal(expected) {
+		t.Errorf("incorrect transform, got %v", moved.LineString)
+	}
+	if !equalPosition(moved.BoundingBox, []float64{12, 3, 16, 7}, 0) {
+		t.Errorf("should recompute bounding box, got %v", moved.BoundingBox)
+	}
+	if g.LineString[0][0] != 1 {
+		t.Errorf("should not modify the original")
+	}
+
+	failure := errors.New("outside grid")
+	_, err = TransformGeometry(g, TransformerFunc(func(x, y, z float64) (float64, float64, float64, error) {
+		return 0, 0, 0, failure
+	}))
+	if err == nil {
+		t.Errorf("should return transformer errors")
+	}
+}
+
+func TestTransformFeatureCollection(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.BoundingBox = []float64{0, 0, 0, 0}
+	fc.AddFeature(NewPointFeature([]float64{1, 1}))
+	fc.AddFeature(NewPointFeature([]float64{-1, 2}))
+	fc.AddFeature(NewFeature(nil))
+
+	moved, err := TransformFeatureCollection(fc, Affine{A: 1, C: 5, E: 1})
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if moved.Features[0].Geometry.Point[0] != 6 || moved.Features[2].Geometry != nil {
+		t.Errorf("incorrect transform, got %v", moved.Features)
+	}
+	if !equalPosition(moved.BoundingBox, []float64{4, 1, 6, 2}, 0) {
+		t.Errorf("should recompute bounding box, got %v", moved.BoundingBox)
+	}
+}
+
+func TestTransformNestedBoundingBoxes(t *testing.T) {
+	inner := NewPointGeometry([]float64{1, 2})
+	inner.BoundingBox = []float64{1, 2, 1, 2}
+	middle := NewCollectionGeometry(inner)
+	middle.BoundingBox = []float64{1, 2, 1, 2}
+	g := NewCollectionGeometry(middle)
+
+	moved, err := TransformGeometry(g, Affine{A: 1, C: 10, E: 1})
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if bb := moved.Geometries[0].Geometries[0].BoundingBox; !equalPosition(bb, []float64{11, 2, 11, 2}, 0) {
+		t.Errorf("should recompute nested bounding box, got %v", bb)
+	}
+	if moved.BoundingBox != nil {
+		t.Errorf("should not add a bounding box, got %v", moved.BoundingBox)
+	}
+}
+
+func TestTransformCRS(t *testing.T) {
+	f := NewPointFeature([]float64{1, 2})
+	f.CRS = NewEPSGCRS(4326).Object()
+
+	projected, err := TransformFeature(f, ToWebMercator)
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	c, err := ParseCRS(projected.CRS)
+	if err != nil {
+		t.Fatalf("should have a crs, but got %v", err)
+	}
+	if code, ok := c.(NamedCRS).EPSG(); !ok || code != 3857 {
+		t.Errorf("incorrect crs, got %v", projected.CRS)
+	}
+	if projected.Geometry.CRS != nil {
+		t.Errorf("should not add a crs to the geometry, got %v", projected.Geometry.CRS)
+	}
+
+	back, err := TransformFeature(projected, FromWebMercator)
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if back.CRS != nil {
+		t.Errorf("should drop the crs of the default system, got %v", back.CRS)
+	}
+
+	moved, err := TransformFeature(projected, Affine{A: 1, E: 1})
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if moved.CRS != nil {
+		t.Errorf("should drop the crs with an unknown system, got %v", moved.CRS)
+	}
+
+	chained, err := TransformGeometry(f.Geometry, Chain{Affine{A: 1, E: 1}, ToWebMercator})
+	if err != nil {
+		t.Fatalf("should transform, but got %v", err)
+	}
+	if chained.CRS == nil {
+		t.Errorf("should have the crs of the last transformer")
+	}
+}
@@ -0,0 +1,683 @@
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulate triangulates the polygon, or multi-polygon, by ear clipping,
+// like the earcut library WebGL renderers use, and returns the triangles
+// as an index buffer into a vertex buffer of x, y pairs. The vertices are
+// the positions of the rings in order, without their closing positions,
+// so each triangle is 3 indexes in the vertex buffer divided by 2. Other
+// geometries return nil buffers.
+//
+// Holes are joined to the exterior ring by bridges before clipping the
+// ears, and self-intersecting or degenerate rings are triangulated as
+// well as possible rather than rejected.
+func Triangulate(polygon *Geometry) ([]uint32, []float64) {
+	if polygon == nil {
+		return nil, nil
+	}
+
+	var rings [][][][]float64
+	switch polygon.Type {
+	case GeometryPolygon:
+		rings = [][][][]float64{polygon.Polygon}
+	case GeometryMultiPolygon:
+		rings = polygon.MultiPolygon
+	default:
+		return nil, nil
+	}
+
+	var indexes []uint32
+	var vertices []float64
+	for _, p := range rings {
+		offset := len(vertices) / 2
+		data, holes := flattenPolygon(p)
+		for _, i := range earcut(data, holes) {
+			indexes = append(indexes, uint32(offset+i))
+		}
+		vertices = append(vertices, data...)
+	}
+	return indexes, vertices
+}
+
+// flattenPolygon returns the x, y pairs of the rings of the polygon,
+// without their closing positions, and the index of the first vertex of
+// each hole.
+func flattenPolygon(polygon [][][]float64) ([]float64, []int) {
+	var data []float64
+	var holes []int
+	for r, ring := range polygon {
+		if r > 0 {
+			holes = append(holes, len(data)/2)
+		}
+		n := len(ring)
+		if n > 1 && samePosition(ring[0], ring[n-1]) {
+			n--
+		}
+		for _, p := range ring[:n] {
+			if len(p) >= 2 {
+				data = append(data, p[0], p[1])
+			}
+		}
+	}
+	return data, holes
+}
+
+// An earNode is a vertex of the doubly linked rings of earcut, also linked
+// in z-order when the polygon is large enough to hash the vertices.
+type earNode struct {
+	i          int
+	x, y       float64
+	z          int32
+	steiner    bool
+	prev, next *earNode
+	prevZ      *earNode
+	nextZ      *earNode
+}
+
+// An earcutter clips the ears of a polygon into triangles.
+type earcutter struct {
+	triangles  []int
+	minX, minY float64
+	invSize    float64
+}
+
+// earcut returns the triangles of the polygon of the x, y pairs, as
+// vertex indexes, holes starting at the given vertices.
+func earcut(data []float64, holes []int) []int {
+	outerLen := len(data)
+	if len(holes) > 0 {
+		outerLen = holes[0] * 2
+	}
+
+	outer := earLinkedList(data, 0, outerLen, true)
+	if outer == nil || outer.next == outer.prev {
+		return nil
+	}
+	if len(holes) > 0 {
+		outer = eliminateHoles(data, holes, outer)
+	}
+
+	e := &earcutter{}
+	// hash the vertices in z-order if the polygon is not too simple
+	if len(data) > 80*2 {
+		minX, minY := data[0], data[1]
+		maxX, maxY := minX, minY
+		for i := 2; i < outerLen; i += 2 {
+			minX, maxX = math.Min(minX, data[i]), math.Max(maxX, data[i])
+			minY, maxY = math.Min(minY, data[i+1]), math.Max(maxY, data[i+1])
+		}
+		e.minX, e.minY = minX, minY
+		if size := math.Max(maxX-minX, maxY-minY); size != 0 {
+			e.invSize = 32767 / size
+		}
+	}
+
+	e.earcutLinked(outer, 0)
+	return e.triangles
+}
+
+// earLinkedList links the vertices from start to end, offsets in data,
+// in the given orientation.
+func earLinkedList(data []float64, start, end int, clockwise bool) *earNode {
+	var last *earNode
+	if clockwise == (earSignedArea(data, start, end) > 0) {
+		for i := start; i < end; i += 2 {
+			last = insertEarNode(i/2, data[i], data[i+1], last)
+		}
+	} else {
+		for i := end - 2; i >= start; i -= 2 {
+			last = insertEarNode(i/2, data[i], data[i+1], last)
+		}
+	}
+
+	if last != nil && earEquals(last, last.next) {
+		removeEarNode(last)
+		last = last.next
+	}
+	return last
+}
+
+// filterEarPoints removes duplicate and collinear vertices.
+func filterEarPoints(start, end *earNode) *earNode {
+	if start == nil {
+		return start
+	}
+	if end == nil {
+		end = start
+	}
+
+	p := start
+	for {
+		again := false
+		if !p.steiner && (earEquals(p, p.next) || earArea(p.prev, p, p.next) == 0) {
+			removeEarNode(p)
+			p = p.prev
+			end = p
+			if p == p.next {
+				break
+			}
+			again = true
+		} else {
+			p = p.next
+		}
+		if !again && p == end {
+			break
+		}
+	}
+	return end
+}
+
+// earcutLinked clips the ears of the ring. Passes over rings left with no
+// ears filter their points, then cure their local self-intersections,
+// then split them in two.
+func (e *earcutter) earcutLinked(ear *earNode, pass int) {
+	if ear == nil {
+		return
+	}
+	if pass == 0 && e.invSize != 0 {
+		e.indexCurve(ear)
+	}
+
+	stop := ear
+	for ear.prev != ear.next {
+		prev, next := ear.prev, ear.next
+
+		isEar := false
+		if e.invSize != 0 {
+			isEar = e.isEarHashed(ear)
+		} else {
+			isEar = isEarNode(ear)
+		}
+		if isEar {
+			e.triangles = append(e.triangles, prev.i, ear.i, next.i)
+			removeEarNode(ear)
+			ear = next.next
+			stop = next.next
+			continue
+		}
+
+		ear = next
+		if ear == stop {
+			switch pass {
+			case 0:
+				e.earcutLinked(filterEarPoints(ear, nil), 1)
+			case 1:
+				ear = e.cureLocalIntersections(filterEarPoints(ear, nil))
+				e.earcutLinked(ear, 2)
+			case 2:
+				e.splitEarcut(ear)
+			}
+			break
+		}
+	}
+}
+
+// isEarNode returns true if the vertex is an ear: a convex vertex whose
+// triangle has no other vertex inside.
+func isEarNode(ear *earNode) bool {
+	a, b, c := ear.prev, ear, ear.next
+	if earArea(a, b, c) >= 0 {
+		return false
+	}
+
+	x0, x1 := math.Min(a.x, math.Min(b.x, c.x)), math.Max(a.x, math.Max(b.x, c.x))
+	y0, y1 := math.Min(a.y, math.Min(b.y, c.y)), math.Max(a.y, math.Max(b.y, c.y))
+	for p := c.next; p != a; p = p.next {
+		if p.x >= x0 && p.x <= x1 && p.y >= y0 && p.y <= y1 &&
+			pointInTriangle(a.x, a.y, b.x, b.y, c.x, c.y, p.x, p.y) &&
+			earArea(p.prev, p, p.next) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isEarHashed is isEarNode looking only at the vertices in the z-order
+// range of the bounding box of the triangle.
+func (e *earcutter) isEarHashed(ear *earNode) bool {
+	a, b, c := ear.prev, ear, ear.next
+	if earArea(a, b, c) >= 0 {
+		return false
+	}
+
+	x0, x1 := math.Min(a.x, math.Min(b.x, c.x)), math.Max(a.x, math.Max(b.x, c.x))
+	y0, y1 := math.Min(a.y, math.Min(b.y, c.y)), math.Max(a.y, math.Max(b.y, c.y))
+	minZ, maxZ := e.zOrder(x0, y0), e.zOrder(x1, y1)
+
+	blocks := func(p *earNode) bool {
+		return p.x >= x0 && p.x <= x1 && p.y >= y0 && p.y <= y1 && p != a && p != c &&
+			pointInTriangle(a.x, a.y, b.x, b.y, c.x, c.y, p.x, p.y) &&
+			earArea(p.prev, p, p.next) >= 0
+	}
+
+	p, n := ear.prevZ, ear.nextZ
+	for p != nil && p.z >= minZ && n != nil && n.z <= maxZ {
+		if blocks(p) {
+			return false
+		}
+		p = p.prevZ
+		if blocks(n) {
+			return false
+		}
+		n = n.nextZ
+	}
+	for ; p != nil && p.z >= minZ; p = p.prevZ {
+		if blocks(p) {
+			return false
+		}
+	}
+	for ; n != nil && n.z <= maxZ; n = n.nextZ {
+		if blocks(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// cureLocalIntersections clips the triangles of the small self-intersections
+// of the ring.
+func (e *earcutter) cureLocalIntersections(start *earNode) *earNode {
+	p := start
+	for {
+		a, b := p.prev, p.next.next
+		if !earEquals(a, b) && earIntersects(a, p, p.next, b) && locallyInside(a, b) && locallyInside(b, a) {
+			e.triangles = append(e.triangles, a.i, p.i, b.i)
+			removeEarNode(p)
+			removeEarNode(p.next)
+			p = b
+			start = b
+		}
+		p = p.next
+		if p == start {
+			break
+		}
+	}
+	return filterEarPoints(p, nil)
+}
+
+// splitEarcut splits the ring in two along a valid diagonal and
+// triangulates both.
+func (e *earcutter) splitEarcut(start *earNode) {
+	a := start
+	for {
+		for b := a.next.next; b != a.prev; b = b.next {
+			if a.i != b.i && isValidDiagonal(a, b) {
+				c := splitEarPolygon(a, b)
+				a = filterEarPoints(a, a.next)
+				c = filterEarPoints(c, c.next)
+				e.earcutLinked(a, 0)
+				e.earcutLinked(c, 0)
+				return
+			}
+		}
+		a = a.next
+		if a == start {
+			return
+		}
+	}
+}
+
+// eliminateHoles links the holes into the exterior ring, from the left.
+func eliminateHoles(data []float64, holes []int, outer *earNode) *earNode {
+	queue := make([]*earNode, 0, len(holes))
+	for i, h := range holes {
+		end := len(data)
+		if i < len(holes)-1 {
+			end = holes[i+1] * 2
+		}
+		list := earLinkedList(data, h*2, end, false)
+		if list == nil {
+			continue
+		}
+		if list == list.next {
+			list.steiner = true
+		}
+		queue = append(queue, leftmostEarNode(list))
+	}
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].x < queue[j].x })
+
+	for _, hole := range queue {
+		outer = eliminateHole(hole, outer)
+	}
+	return outer
+}
+
+// eliminateHole links the hole into the ring through a bridge.
+func eliminateHole(hole, outer *earNode) *earNode {
+	bridge := findHoleBridge(hole, outer)
+	if bridge == nil {
+		return outer
+	}
+
+	bridgeReverse := splitEarPolygon(bridge, hole)
+	filterEarPoints(bridgeReverse, bridgeReverse.next)
+	return filterEarPoints(bridge, bridge.next)
+}
+
+// findHoleBridge returns the vertex of the ring to connect the leftmost
+// vertex of the hole to, using David Eberly's algorithm.
+func findHoleBridge(hole, outer *earNode) *earNode {
+	hx, hy := hole.x, hole.y
+	qx := math.Inf(-1)
+	var m *earNode
+
+	// find a segment intersected by a ray from the hole's leftmost point
+	// to the left; the segment's endpoint with lesser x is a candidate
+	p := outer
+	for {
+		if hy <= p.y && hy >= p.next.y && p.next.y != p.y {
+			x := p.x + (hy-p.y)*(p.next.x-p.x)/(p.next.y-p.y)
+			if x <= hx && x > qx {
+				qx = x
+				m = p
+				if p.next.x < p.x {
+					m = p.next
+				}
+				if x == hx {
+					// the hole touches the segment
+					return m
+				}
+			}
+		}
+		p = p.next
+		if p == outer {
+			break
+		}
+	}
+	if m == nil {
+		return nil
+	}
+
+	// look for points inside the triangle of the hole point, the
+	// intersection and the candidate, and take the one with the smallest
+	// angle to the ray
+	stop := m
+	mx, my := m.x, m.y
+	tanMin := math.Inf(1)
+	p = m
+	for {
+		ax, cx := qx, hx
+		if hy < my {
+			ax, cx = hx, qx
+		}
+		if hx >= p.x && p.x >= mx && hx != p.x && pointInTriangle(ax, hy, mx, my, cx, hy, p.x, p.y) {
+			tan := math.Abs(hy-p.y) / (hx - p.x)
+			if locallyInside(p, hole) &&
+				(tan < tanMin || tan == tanMin && (p.x > m.x || p.x == m.x && sectorContainsSector(m, p))) {
+				m = p
+				tanMin = tan
+			}
+		}
+		p = p.next
+		if p == stop {
+			break
+		}
+	}
+	return m
+}
+
+// sectorContainsSector returns true if the sector of m contains the one
+// of p.
+func sectorContainsSector(m, p *earNode) bool {
+	return earArea(m.prev, m, p.prev) < 0 && earArea(p.next, m, m.next) < 0
+}
+
+// indexCurve links the vertices of the ring in z-order.
+func (e *earcutter) indexCurve(start *earNode) {
+	p := start
+	for {
+		if p.z == 0 {
+			p.z = e.zOrder(p.x, p.y)
+		}
+		p.prevZ = p.prev
+		p.nextZ = p.next
+		p = p.next
+		if p == start {
+			break
+		}
+	}
+	p.prevZ.nextZ = nil
+	p.prevZ = nil
+
+	sortEarNodes(p)
+}
+
+// sortEarNodes sorts the z-order list with Simon Tatham's merge sort.
+func sortEarNodes(list *earNode) *earNode {
+	inSize := 1
+	for {
+		p := list
+		list = nil
+		var tail *earNode
+		merges := 0
+
+		for p != nil {
+			merges++
+			q := p
+			pSize := 0
+			for i := 0; i < inSize; i++ {
+				pSize++
+				q = q.nextZ
+				if q == nil {
+					break
+				}
+			}
+			qSize := inSize
+
+			for pSize > 0 || qSize > 0 && q != nil {
+				var e *earNode
+				if pSize != 0 && (qSize == 0 || q == nil || p.z <= q.z) {
+					e = p
+					p = p.nextZ
+					pSize--
+				} else {
+					e = q
+					q = q.nextZ
+					qSize--
+				}
+
+				if tail != nil {
+					tail.nextZ = e
+				} else {
+					list = e
+				}
+				e.prevZ = tail
+				tail = e
+			}
+			p = q
+		}
+
+		tail.nextZ = nil
+		inSize *= 2
+		if merges <= 1 {
+			return list
+		}
+	}
+}
+
+// zOrder returns the z-order of the point in the bounding box of the
+// polygon, as 15 bit coordinates interleaved.
+func (e *earcutter) zOrder(x, y float64) int32 {
+	ix := uint32(int32((x - e.minX) * e.invSize))
+	iy := uint32(int32((y - e.minY) * e.invSize))
+	return int32(interleave(ix) | interleave(iy)<<1)
+}
+
+func interleave(v uint32) uint32 {
+	v = (v | v<<8) & 0x00FF00FF
+	v = (v | v<<4) & 0x0F0F0F0F
+	v = (v | v<<2) & 0x33333333
+	v = (v | v<<1) & 0x55555555
+	return v
+}
+
+// leftmostEarNode returns the leftmost vertex of the ring, the lowest if
+// there are several.
+func leftmostEarNode(start *earNode) *earNode {
+	leftmost := start
+	for p := start.next; p != start; p = p.next {
+		if p.x < leftmost.x || p.x == leftmost.x && p.y < leftmost.y {
+			leftmost = p
+		}
+	}
+	return leftmost
+}
+
+func pointInTriangle(ax, ay, bx, by, cx, cy, px, py float64) bool {
+	return (cx-px)*(ay-py) >= (ax-px)*(cy-py) &&
+		(ax-px)*(by-py) >= (bx-px)*(ay-py) &&
+		(bx-px)*(cy-py) >= (cx-px)*(by-py)
+}
+
+// isValidDiagonal returns true if the diagonal from a to b is inside the
+// ring and crosses none of its edges.
+func isValidDiagonal(a, b *earNode) bool {
+	return a.next.i != b.i && a.prev.i != b.i && !intersectsRing(a, b) &&
+		(locallyInside(a, b) && locallyInside(b, a) && middleInside(a, b) &&
+			(earArea(a.prev, a, b.prev) != 0 || earArea(a, b.prev, b) != 0) ||
+			earEquals(a, b) && earArea(a.prev, a, a.next) > 0 && earArea(b.prev, b, b.next) > 0)
+}
+
+// earArea returns twice the signed area of the triangle, negative when
+// p, q, r turn the way the ring goes around its inside.
+func earArea(p, q, r *earNode) float64 {
+	return (q.y-p.y)*(r.x-q.x) - (q.x-p.x)*(r.y-q.y)
+}
+
+func earEquals(p, q *earNode) bool {
+	return p.x == q.x && p.y == q.y
+}
+
+// earIntersects returns true if the segments p1 q1 and p2 q2 intersect.
+func earIntersects(p1, q1, p2, q2 *earNode) bool {
+	o1 := sign(earArea(p1, q1, p2))
+	o2 := sign(earArea(p1, q1, q2))
+	o3 := sign(earArea(p2, q2, p1))
+	o4 := sign(earArea(p2, q2, q1))
+
+	return o1 != o2 && o3 != o4 ||
+		o1 == 0 && onEarSegment(p1, p2, q1) ||
+		o2 == 0 && onEarSegment(p1, q2, q1) ||
+		o3 == 0 && onEarSegment(p2, p1, q2) ||
+		o4 == 0 && onEarSegment(p2, q1, q2)
+}
+
+// onEarSegment returns true if q, collinear with p and r, is between them.
+func onEarSegment(p, q, r *earNode) bool {
+	return q.x <= math.Max(p.x, r.x) && q.x >= math.Min(p.x, r.x) &&
+		q.y <= math.Max(p.y, r.y) && q.y >= math.Min(p.y, r.y)
+}
+
+func sign(x float64) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// intersectsRing returns true if the diagonal from a to b crosses an edge
+// of the ring.
+func intersectsRing(a, b *earNode) bool {
+	p := a
+	for {
+		if p.i != a.i && p.next.i != a.i && p.i != b.i && p.next.i != b.i && earIntersects(p, p.next, a, b) {
+			return true
+		}
+		p = p.next
+		if p == a {
+			return false
+		}
+	}
+}
+
+// locallyInside returns true if the diagonal from a to b starts inside
+// the ring.
+func locallyInside(a, b *earNode) bool {
+	if earArea(a.prev, a, a.next) < 0 {
+		return earArea(a, b, a.next) >= 0 && earArea(a, a.prev, b) >= 0
+	}
+	return earArea(a, b, a.prev) < 0 || earArea(a, a.next, b) < 0
+}
+
+// middleInside returns true if the middle of the diagonal from a to b is
+// inside the ring.
+func middleInside(a, b *earNode) bool {
+	inside := false
+	px, py := (a.x+b.x)/2, (a.y+b.y)/2
+	p := a
+	for {
+		if (p.y > py) != (p.next.y > py) && p.next.y != p.y &&
+			px < (p.next.x-p.x)*(py-p.y)/(p.next.y-p.y)+p.x {
+			inside = !inside
+		}
+		p = p.next
+		if p == a {
+			return inside
+		}
+	}
+}
+
+// splitEarPolygon splits the ring in two along the diagonal from a to b,
+// duplicating them, and returns the copy of b, in the second ring.
+func splitEarPolygon(a, b *earNode) *earNode {
+	a2 := &earNode{i: a.i, x: a.x, y: a.y}
+	b2 := &earNode{i: b.i, x: b.x, y: b.y}
+	an, bp := a.next, b.prev
+
+	a.next = b
+	b.prev = a
+
+	a2.next = an
+	an.prev = a2
+
+	b2.next = a2
+	a2.prev = b2
+
+	bp.next = b2
+	b2.prev = bp
+
+	return b2
+}
+
+func insertEarNode(i int, x, y float64, last *earNode) *earNode {
+	p := &earNode{i: i, x: x, y: y}
+	if last == nil {
+		p.prev = p
+		p.next = p
+	} else {
+		p.next = last.next
+		p.prev = last
+		last.next.prev = p
+		last.next = p
+	}
+	return p
+}
+
+func removeEarNode(p *earNode) {
+	p.next.prev = p.prev
+	p.prev.next = p.next
+	if p.prevZ != nil {
+		p.prevZ.nextZ = p.nextZ
+	}
+	if p.nextZ != nil {
+		p.nextZ.prevZ = p.prevZ
+	}
+}
+
+// earSignedArea returns the signed area of the ring from start to end,
+// offsets in data, times 2.
+func earSignedArea(data []float64, start, end int) float64 {
+	sum := 0.0
+	for i, j := start, end-2; i < end; i += 2 {
+		sum += (data[j] - data[i]) * (data[i+1] + data[j+1])
+		j = i
+	}
+	return sum
+}
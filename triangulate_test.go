@@ -0,0 +1,126 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+// triangleArea returns the total area of the triangles.
+func triangleArea(indexes []uint32, vertices []float64) float64 {
+	area := 0.0
+	for i := 0; i+2 < len(indexes); i += 3 {
+		a, b, c := indexes[i]*2, indexes[i+1]*2, indexes[i+2]*2
+		area += math.Abs((vertices[a]-vertices[c])*(vertices[b+1]-vertices[a+1])-
+			(vertices[a]-vertices[b])*(vertices[c+1]-vertices[a+1])) / 2
+	}
+	return area
+}
+
+// polygonArea returns the area of the polygon, its holes removed.
+func polygonArea(polygon [][][]float64) float64 {
+	area := 0.0
+	for i, ring := range polygon {
+		a := math.Abs(ringArea2D(ring))
+		if i > 0 {
+			a = -a
+		}
+		area += a
+	}
+	return area
+}
+
+func star(points int, cx, cy, r float64) [][]float64 {
+	var ring [][]float64
+	for i := 0; i < 2*points; i++ {
+		radius := r
+		if i%2 == 1 {
+			radius = r / 2
+		}
+		angle := float64(i) * math.Pi / float64(points)
+		ring = append(ring, []float64{cx + radius*math.Cos(angle), cy + radius*math.Sin(angle)})
+	}
+	return append(ring, ring[0])
+}
+
+func TestTriangulate(t *testing.T) {
+	cases := []struct {
+		name      string
+		polygon   [][][]float64
+		triangles int
+	}{
+		{
+			name:      "square",
+			polygon:   [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+			triangles: 2,
+		},
+		{
+			name:      "clockwise concave",
+			polygon:   [][][]float64{{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {2, 2}, {0, 0}}},
+			triangles: 3,
+		},
+		{
+			name: "square with holes",
+			polygon: [][][]float64{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+				{{6, 6}, {6, 8}, {8, 8}, {8, 6}, {6, 6}},
+			},
+			triangles: 14,
+		},
+		{
+			name:      "collinear points",
+			polygon:   [][][]float64{{{0, 0}, {1, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+			triangles: 3,
+		},
+		{
+			name:      "large star hashed in z-order",
+			polygon:   [][][]float64{star(60, 5, 5, 4), star(5, 5, 5, 1)},
+			triangles: 2*60 - 2 + 2*5 + 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexes, vertices := Triangulate(NewPolygonGeometry(tc.polygon))
+			if len(indexes) != 3*tc.triangles {
+				t.Errorf("incorrect number of triangles, expected %d, got %d", tc.triangles, len(indexes)/3)
+			}
+			if expected, actual := polygonArea(tc.polygon), triangleArea(indexes, vertices); math.Abs(expected-actual) > 1e-9*expected {
+				t.Errorf("incorrect triangulated area, expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestTriangulateMultiPolygon(t *testing.T) {
+	g := NewMultiPolygonGeometry(
+		[][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		[][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 6}, {5, 5}}},
+	)
+	indexes, vertices := Triangulate(g)
+
+	expected := []float64{0, 0, 1, 0, 1, 1, 5, 5, 6, 5, 6, 6, 5, 6}
+	if len(vertices) != len(expected) {
+		t.Fatalf("incorrect vertices, got %v", vertices)
+	}
+	for i := range expected {
+		if vertices[i] != expected[i] {
+			t.Fatalf("incorrect vertices, got %v", vertices)
+		}
+	}
+	if len(indexes) != 9 || triangleArea(indexes, vertices) != 1.5 {
+		t.Errorf("incorrect triangles, got %v", indexes)
+	}
+	for _, i := range indexes[3:] {
+		if i < 3 {
+			t.Errorf("should offset the indexes of the second polygon, got %v", indexes)
+		}
+	}
+
+	if indexes, vertices := Triangulate(NewPointGeometry([]float64{1, 2})); indexes != nil || vertices != nil {
+		t.Errorf("should not triangulate a point")
+	}
+	if indexes, _ := Triangulate(NewPolygonGeometry([][][]float64{{{0, 0}, {1, 1}, {0, 0}}})); len(indexes) != 0 {
+		t.Errorf("should not triangulate a degenerate ring, got %v", indexes)
+	}
+}
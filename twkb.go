@@ -0,0 +1,389 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The TWKB metadata header flags.
+const (
+	twkbBoundingBox       = 1 << 0
+	twkbSize              = 1 << 1
+	twkbIDList            = 1 << 2
+	twkbExtendedPrecision = 1 << 3
+	twkbEmpty             = 1 << 4
+)
+
+// MarshalTWKB converts the geometry into Tiny Well-Known Binary, keeping the
+// given number of decimal digits, from -8 to 7. Negative precisions round to
+// tens, hundreds and so on. Altitudes are kept, with the same precision
+// clamped from 0 to 7, if all positions have one.
+func (g *Geometry) MarshalTWKB(precision int) ([]byte, error) {
+	if g == nil {
+		return nil, errors.New("no geometry to convert to TWKB")
+	}
+	if precision < -8 || precision > 7 {
+		return nil, fmt.Errorf("TWKB precision must be between -8 and 7, got %d", precision)
+	}
+
+	z, err := hasAltitude(g)
+	if err != nil {
+		return nil, err
+	}
+
+	zPrecision := precision
+	if zPrecision < 0 {
+		zPrecision = 0
+	}
+
+	e := &twkbWriter{precision: precision, zPrecision: zPrecision, z: z}
+	if err := e.writeGeometry(g); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalTWKB decodes Tiny Well-Known Binary into a geometry.
+// Altitudes are kept and measures dropped.
+func UnmarshalTWKB(data []byte) (*Geometry, error) {
+	r := &twkbReader{data: data}
+	g, err := r.readGeometry()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(data) {
+		return nil, fmt.Errorf("invalid TWKB: %d trailing bytes", len(data)-r.pos)
+	}
+	return g, nil
+}
+
+type twkbWriter struct {
+	buf                   bytes.Buffer
+	precision, zPrecision int
+	z                     bool
+
+	// previous holds the last scaled position, coordinates being delta encoded
+	previous [3]int64
+}
+
+func (e *twkbWriter) writeGeometry(g *Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry in collection")
+	}
+
+	code, ok := wkbTypes[g.Type]
+	if !ok {
+		return fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+	e.buf.WriteByte(byte(code) | byte(zigzag(int64(e.precision)))<<4)
+
+	var metadata byte
+	if e.z {
+		metadata |= twkbExtendedPrecision
+	}
+	empty := g.Type == GeometryCollection && len(g.Geometries) == 0 || g.Type != GeometryCollection && g.IsEmpty()
+	if empty {
+		metadata |= twkbEmpty
+	}
+	e.buf.WriteByte(metadata)
+	if e.z {
+		e.buf.WriteByte(1 | byte(e.zPrecision)<<2)
+	}
+	if empty {
+		return nil
+	}
+
+	e.previous = [3]int64{}
+	switch g.Type {
+	case GeometryPoint:
+		return e.position(g.Point)
+	case GeometryLineString:
+		return e.path(g.LineString)
+	case GeometryPolygon:
+		return e.paths(g.Polygon)
+	case GeometryMultiPoint:
+		e.uvarint(uint64(len(g.MultiPoint)))
+		for _, p := range g.MultiPoint {
+			if err := e.position(p); err != nil {
+				return err
+			}
+		}
+	case GeometryMultiLineString:
+		return e.paths(g.MultiLineString)
+	case GeometryMultiPolygon:
+		e.uvarint(uint64(len(g.MultiPolygon)))
+		for _, p := range g.MultiPolygon {
+			if err := e.paths(p); err != nil {
+				return err
+			}
+		}
+	case GeometryCollection:
+		e.uvarint(uint64(len(g.Geometries)))
+		for _, c := range g.Geometries {
+			if err := e.writeGeometry(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *twkbWriter) paths(paths [][][]float64) error {
+	e.uvarint(uint64(len(paths)))
+	for _, p := range paths {
+		if err := e.path(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *twkbWriter) path(path [][]float64) error {
+	e.uvarint(uint64(len(path)))
+	for _, p := range path {
+		if err := e.position(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *twkbWriter) position(p []float64) error {
+	n := 2
+	if e.z {
+		n = 3
+	}
+
+	for i := 0; i < n; i++ {
+		precision := e.precision
+		if i == 2 {
+			precision = e.zPrecision
+		}
+
+		scaled := math.Round(p[i] * math.Pow10(precision))
+		if math.IsNaN(scaled) || math.Abs(scaled) > 1<<62 {
+			return fmt.Errorf("coordinate %v can not be written as TWKB", p[i])
+		}
+
+		v := int64(scaled)
+		e.uvarint(zigzag(v - e.previous[i]))
+		e.previous[i] = v
+	}
+	return nil
+}
+
+func (e *twkbWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+type twkbReader struct {
+	data []byte
+	pos  int
+}
+
+// twkbHeader holds the dimensions and scales of a TWKB geometry.
+type twkbHeader struct {
+	dims     int
+	z, m     bool
+	scales   [4]float64
+	previous [4]int64
+}
+
+func (r *twkbReader) readGeometry() (*Geometry, error) {
+	if len(r.data)-r.pos < 2 {
+		return nil, errors.New("invalid TWKB: unexpected end of data")
+	}
+	typeAndPrecision, metadata := r.data[r.pos], r.data[r.pos+1]
+	r.pos += 2
+
+	h := &twkbHeader{dims: 2}
+	h.scales[0] = math.Pow10(-int(unzigzag(uint64(typeAndPrecision >> 4))))
+	h.scales[1] = h.scales[0]
+
+	if metadata&twkbExtendedPrecision != 0 {
+		if r.pos >= len(r.data) {
+			return nil, errors.New("invalid TWKB: unexpected end of data")
+		}
+		ext := r.data[r.pos]
+		r.pos++
+
+		h.z, h.m = ext&1 != 0, ext&2 != 0
+		if h.z {
+			h.scales[h.dims] = math.Pow10(-int(ext >> 2 & 7))
+			h.dims++
+		}
+		if h.m {
+			h.scales[h.dims] = math.Pow10(-int(ext >> 5 & 7))
+			h.dims++
+		}
+	}
+
+	if metadata&twkbSize != 0 {
+		if _, err := r.uvarint(); err != nil {
+			return nil, err
+		}
+	}
+	if metadata&twkbBoundingBox != 0 {
+		for i := 0; i < 2*h.dims; i++ {
+			if _, err := r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	code := uint32(typeAndPrecision & 0x0f)
+	t := GeometryType("")
+	for gt, c := range wkbTypes {
+		if c == code {
+			t = gt
+		}
+	}
+	if t == "" {
+		return nil, fmt.Errorf("invalid TWKB: unknown geometry type %d", code)
+	}
+
+	g := &Geometry{Type: t}
+	if metadata&twkbEmpty != 0 {
+		if t == GeometryCollection {
+			g.Geometries = []*Geometry{}
+		}
+		return g, nil
+	}
+
+	var err error
+	switch t {
+	case GeometryPoint:
+		g.Point, err = r.position(h)
+	case GeometryLineString:
+		g.LineString, err = r.path(h)
+	case GeometryPolygon:
+		g.Polygon, err = r.paths(h)
+	case GeometryMultiPoint:
+		var n int
+		if n, err = r.count(metadata, 1); err == nil {
+			g.MultiPoint = make([][]float64, n)
+			for i := 0; i < n && err == nil; i++ {
+				g.MultiPoint[i], err = r.position(h)
+			}
+		}
+	case GeometryMultiLineString:
+		var n int
+		if n, err = r.count(metadata, 1); err == nil {
+			g.MultiLineString = make([][][]float64, n)
+			for i := 0; i < n && err == nil; i++ {
+				g.MultiLineString[i], err = r.path(h)
+			}
+		}
+	case GeometryMultiPolygon:
+		var n int
+		if n, err = r.count(metadata, 1); err == nil {
+			g.MultiPolygon = make([][][][]float64, n)
+			for i := 0; i < n && err == nil; i++ {
+				g.MultiPolygon[i], err = r.paths(h)
+			}
+		}
+	case GeometryCollection:
+		var n int
+		if n, err = r.count(metadata, 2); err == nil {
+			g.Geometries = make([]*Geometry, n)
+			for i := 0; i < n && err == nil; i++ {
+				g.Geometries[i], err = r.readGeometry()
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// count reads the number of parts of a multi geometry, skipping the id list,
+// checking the remaining data can hold them when each takes at least size bytes.
+func (r *twkbReader) count(metadata byte, size int) (int, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n*uint64(size) > uint64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("invalid TWKB: %d elements do not fit in the data", n)
+	}
+
+	if metadata&twkbIDList != 0 {
+		for i := uint64(0); i < n; i++ {
+			if _, err := r.uvarint(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return int(n), nil
+}
+
+func (r *twkbReader) paths(h *twkbHeader) ([][][]float64, error) {
+	n, err := r.count(0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([][][]float64, n)
+	for i := range paths {
+		if paths[i], err = r.path(h); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+func (r *twkbReader) path(h *twkbHeader) ([][]float64, error) {
+	n, err := r.count(0, h.dims)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([][]float64, n)
+	for i := range path {
+		if path[i], err = r.position(h); err != nil {
+			return nil, err
+		}
+	}
+	return path, nil
+}
+
+// position reads a delta encoded position, dropping its measure.
+func (r *twkbReader) position(h *twkbHeader) ([]float64, error) {
+	p := make([]float64, 0, h.dims)
+	for i := 0; i < h.dims; i++ {
+		v, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		h.previous[i] += unzigzag(v)
+
+		if !h.m || i < h.dims-1 {
+			p = append(p, float64(h.previous[i])*h.scales[i])
+		}
+	}
+	return p, nil
+}
+
+func (r *twkbReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errors.New("invalid TWKB: unexpected end of data")
+	}
+	r.pos += n
+	return v, nil
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,122 @@
+package geojson
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestMarshalTWKB(t *testing.T) {
+	cases := []struct {
+		name      string
+		geometry  *Geometry
+		precision int
+		expected  string
+	}{
+		{"point", NewPointGeometry([]float64{1, 2}), 0, "01000204"},
+		{"line string", NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}), 0, "02000202040404"},
+		{"precision", NewPointGeometry([]float64{1.25, -0.5}), 2, "4100fa0163"},
+		{"negative precision", NewPointGeometry([]float64{1240, 560}), -1, "1100f80170"},
+		{"altitude", NewPointGeometry([]float64{1, 2, 3}), 0, "010801020406"},
+		{"empty", NewCollectionGeometry(), 0, "0710"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := c.geometry.MarshalTWKB(c.precision)
+			if err != nil {
+				t.Fatalf("should marshal, but got %v", err)
+			}
+			if h := hex.EncodeToString(data); h != c.expected {
+				t.Errorf("incorrect encoding, expected %v, got %v", c.expected, h)
+			}
+		})
+	}
+
+	if _, err := NewPointGeometry([]float64{1, 2}).MarshalTWKB(8); err == nil {
+		t.Errorf("should reject a precision above 7")
+	}
+	if _, err := NewLineStringGeometry([][]float64{{1, 2}, {3, 4, 5}}).MarshalTWKB(0); err == nil {
+		t.Errorf("should reject mixed dimensions")
+	}
+}
+
+func TestTWKBRoundTrip(t *testing.T) {
+	geometries := []*Geometry{
+		NewPointGeometry([]float64{1.5, 2.25}),
+		NewPointGeometry([]float64{1, 2, 3.5}),
+		{Type: GeometryPoint, Point: []float64{}},
+		NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		NewLineStringGeometry([][]float64{{-71.0625, 42.375}, {-71.125, 42.25}}),
+		NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {{0.25, 0.25}, {0.5, 0.25}, {0.5, 0.5}, {0.25, 0.25}}}),
+		NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, [][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}),
+		NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewCollectionGeometry(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}))),
+		NewCollectionGeometry(),
+	}
+
+	for _, g := range geometries {
+		data, err := g.MarshalTWKB(5)
+		if err != nil {
+			t.Fatalf("should marshal %v, but got %v", g.Type, err)
+		}
+
+		decoded, err := UnmarshalTWKB(data)
+		if err != nil {
+			t.Fatalf("should unmarshal %v, but got %v", g.Type, err)
+		}
+		if !decoded.EqualWithTolerance(g, 1e-9) {
+			t.Errorf("should round trip %v, got %+v", g.Type, decoded)
+		}
+	}
+}
+
+func TestTWKBRounding(t *testing.T) {
+	data, err := NewLineStringGeometry([][]float64{{1.23456, 2.34567}, {1.23461, 2.34562}}).MarshalTWKB(3)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+
+	g, err := UnmarshalTWKB(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	expected := NewLineStringGeometry([][]float64{{1.235, 2.346}, {1.235, 2.346}})
+	if !g.EqualWithTolerance(expected, 1e-12) {
+		t.Errorf("should round to 3 decimals, got %v", g.LineString)
+	}
+}
+
+func TestUnmarshalTWKBHeader(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected *Geometry
+	}{
+		{"bounding box", "0201020404040202040404", NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})},
+		{"size", "0202050202040404", NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})},
+		{"measure", "010802020406", NewPointGeometry([]float64{1, 2})},
+		{"id list", "040402020402040408", NewMultiPointGeometry([]float64{1, 2}, []float64{3, 6})},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(c.data)
+			g, err := UnmarshalTWKB(data)
+			if err != nil {
+				t.Fatalf("should unmarshal, but got %v", err)
+			}
+			if !g.Equal(c.expected) {
+				t.Errorf("incorrect geometry, got %+v", g)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTWKBInvalid(t *testing.T) {
+	for _, s := range []string{"", "01", "0100", "0900", "020002ff", "0200ff", "0100020400"} {
+		data, _ := hex.DecodeString(s)
+		if _, err := UnmarshalTWKB(data); err == nil {
+			t.Errorf("should reject %q", s)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package geojson
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A ValidationErrorKind classifies the problems found by the Validate
+// methods.
+type ValidationErrorKind int
+
+// The kinds of validation errors.
+const (
+	// ValidationType is an unknown geometry type, or a feature whose type
+	// is not "Feature".
+	ValidationType ValidationErrorKind = iota
+
+	// ValidationPositionArity is a position with less than 2 coordinates.
+	ValidationPositionArity
+
+	// ValidationOutOfRange is a longitude outside [-180, 180] or a
+	// latitude outside [-90, 90].
+	ValidationOutOfRange
+
+	// ValidationTooFewPositions is a line string with less than 2
+	// positions, or a linear ring with less than 4.
+	ValidationTooFewPositions
+
+	// ValidationUnclosedRing is a linear ring whose last position is not
+	// its first.
+	ValidationUnclosedRing
+
+	// ValidationWindingOrder is an exterior ring that is not
+	// counterclockwise, or a hole that is not clockwise. RFC 7946 asks
+	// for the right-hand rule but older data often does not follow it.
+	ValidationWindingOrder
+
+	// ValidationBoundingBox is a bounding box whose length is not twice
+	// the dimension of the positions, or whose minimum is above its
+	// maximum. A west edge east of the east edge crosses the antimeridian
+	// and is valid.
+	ValidationBoundingBox
+
+	// ValidationNestedCollection is a geometry collection nested in
+	// another, which RFC 7946 asks to avoid.
+	ValidationNestedCollection
+
+	// ValidationMixedDimensions is a geometry mixing positions with and
+	// without altitude, or with and without further coordinates.
+	ValidationMixedDimensions
+
+	// ValidationLatLonOrder is a geometry whose positions look like
+	// latitude, longitude pairs rather than longitude, latitude ones. It
+	// is a heuristic only reported by the LintLatLonOrder methods.
+	ValidationLatLonOrder
+)
+
+// String returns a description of the kind.
+func (k ValidationErrorKind) String() string {
+	switch k {
+	case ValidationType:
+		return "invalid type"
+	case ValidationPositionArity:
+		return "position with less than 2 coordinates"
+	case ValidationOutOfRange:
+		return "coordinate out of range"
+	case ValidationTooFewPositions:
+		return "too few positions"
+	case ValidationUnclosedRing:
+		return "ring is not closed"
+	case ValidationWindingOrder:
+		return "ring does not follow the right-hand rule"
+	case ValidationBoundingBox:
+		return "invalid bounding box"
+	case ValidationNestedCollection:
+		return "nested geometry collection"
+	case ValidationMixedDimensions:
+		return "positions of different dimensions"
+	case ValidationLatLonOrder:
+		return "coordinates look like latitude, longitude"
+	}
+	return fmt.Sprintf("validation error %d", int(k))
+}
+
+// A ValidationError is a violation of RFC 7946 found by the Validate
+// methods.
+type ValidationError struct {
+	Kind ValidationErrorKind
+
+	// Pointer is the RFC 6901 JSON pointer to the offending member,
+	// relative to the validated object, like "/geometry/coordinates/0/3"
+	// for the fourth position of the exterior ring of a feature polygon.
+	Pointer string
+}
+
+// Error describes the validation error.
+func (e ValidationError) Error() string {
+	if e.Pointer == "" {
+		return e.Kind.String()
+	}
+	return e.Pointer + ": " + e.Kind.String()
+}
+
+// JSONPointer returns the pointer to the offending member.
+func (e ValidationError) JSONPointer() string {
+	return e.Pointer
+}
+
+// Validate checks the geometry against RFC 7946 and returns the problems
+// found, nil if none. Geometries with empty coordinates are valid.
+func (g *Geometry) Validate() []ValidationError {
+	v := &validator{}
+	v.geometry(g, "", false)
+	return v.errs
+}
+
+// Validate checks the feature, and its geometry, against RFC 7946 and
+// returns the problems found, nil if none.
+func (f *Feature) Validate() []ValidationError {
+	v := &validator{}
+	v.feature(f, "")
+	return v.errs
+}
+
+// Validate checks the collection, and its features, against RFC 7946 and
+// returns the problems found, nil if none.
+func (fc *FeatureCollection) Validate() []ValidationError {
+	v := &validator{}
+	dimension := 0
+	for i, f := range fc.Features {
+		if f != nil {
+			dimension = maxInt(dimension, v.feature(f, "/features/"+strconv.Itoa(i)))
+		}
+	}
+	v.boundingBox(fc.BoundingBox, dimension, "")
+	return v.errs
+}
+
+// A validator collects the validation errors of an object.
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) add(kind ValidationErrorKind, pointer string) {
+	v.errs = append(v.errs, ValidationError{Kind: kind, Pointer: pointer})
+}
+
+// feature validates the feature and returns the dimension of its positions.
+func (v *validator) feature(f *Feature, pointer string) int {
+	if f.Type != "Feature" {
+		v.add(ValidationType, pointer+"/type")
+	}
+
+	dimension := 0
+	if f.Geometry != nil {
+		dimension = v.geometry(f.Geometry, pointer+"/geometry", false)
+	}
+	v.boundingBox(f.BoundingBox, dimension, pointer)
+	return dimension
+}
+
+// geometry validates the geometry and returns the dimension of its
+// positions, the largest number of coordinates.
+func (v *validator) geometry(g *Geometry, pointer string, nested bool) int {
+	coordinates := pointer + "/coordinates"
+	dimension := 0
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) != 0 {
+			dimension = v.position(g.Point, coordinates)
+		}
+	case GeometryMultiPoint:
+		for i, p := range g.MultiPoint {
+			dimension = maxInt(dimension, v.position(p, indexPointer(coordinates, i)))
+		}
+	case GeometryLineString:
+		dimension = v.lineString(g.LineString, coordinates)
+	case GeometryMultiLineString:
+		for i, line := range g.MultiLineString {
+			dimension = maxInt(dimension, v.lineString(line, indexPointer(coordinates, i)))
+		}
+	case GeometryPolygon:
+		dimension = v.polygon(g.Polygon, coordinates)
+	case GeometryMultiPolygon:
+		for i, polygon := range g.MultiPolygon {
+			dimension = maxInt(dimension, v.polygon(polygon, indexPointer(coordinates, i)))
+		}
+	case GeometryCollection:
+		if nested {
+			v.add(ValidationNestedCollection, pointer)
+		}
+		for i, m := range g.Geometries {
+			if m != nil {
+				dimension = maxInt(dimension, v.geometry(m, indexPointer(pointer+"/geometries", i), true))
+			}
+		}
+	default:
+		v.add(ValidationType, pointer+"/type")
+	}
+	if mixedDimensions(g) {
+		v.add(ValidationMixedDimensions, coordinates)
+	}
+
+	v.boundingBox(g.BoundingBox, dimension, pointer)
+	return dimension
+}
+
+// position validates the position and returns its dimension.
+func (v *validator) position(p []float64, pointer string) int {
+	if len(p) < 2 {
+		v.add(ValidationPositionArity, pointer)
+		return len(p)
+	}
+	if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+		v.add(ValidationOutOfRange, pointer)
+	}
+	return len(p)
+}
+
+// path validates the positions and returns their dimension, and whether
+// they all have at least 2 coordinates.
+func (v *validator) path(path [][]float64, pointer string) (int, bool) {
+	dimension := 0
+	valid := true
+	for i, p := range path {
+		dimension = maxInt(dimension, v.position(p, indexPointer(pointer, i)))
+		valid = valid && len(p) >= 2
+	}
+	return dimension, valid
+}
+
+func (v *validator) lineString(line [][]float64, pointer string) int {
+	if len(line) == 1 {
+		v.add(ValidationTooFewPositions, pointer)
+	}
+	dimension, _ := v.path(line, pointer)
+	return dimension
+}
+
+func (v *validator) polygon(polygon [][][]float64, pointer string) int {
+	dimension := 0
+	for i, ring := range polygon {
+		ringPointer := indexPointer(pointer, i)
+		d, valid := v.path(ring, ringPointer)
+		dimension = maxInt(dimension, d)
+
+		if len(ring) < 4 {
+			v.add(ValidationTooFewPositions, ringPointer)
+			continue
+		}
+		if !valid {
+			continue
+		}
+		if !samePosition(ring[0], ring[len(ring)-1]) {
+			v.add(ValidationUnclosedRing, ringPointer)
+			continue
+		}
+		if area := ringArea2D(ring); i == 0 && area < 0 || i > 0 && area > 0 {
+			v.add(ValidationWindingOrder, ringPointer)
+		}
+	}
+	return dimension
+}
+
+// boundingBox validates the bounding box of an object whose positions
+// have the dimension, 0 if it has none.
+func (v *validator) boundingBox(bb []float64, dimension int, pointer string) {
+	if bb == nil {
+		return
+	}
+
+	pointer += "/bbox"
+	n := len(bb) / 2
+	if len(bb)%2 != 0 || n < 2 || dimension != 0 && n != dimension {
+		v.add(ValidationBoundingBox, pointer)
+		return
+	}
+	for i := 1; i < n; i++ {
+		if bb[i] > bb[n+i] {
+			v.add(ValidationBoundingBox, pointer)
+			return
+		}
+	}
+}
+
+// indexPointer returns the pointer to the element of the array.
+func indexPointer(pointer string, i int) string {
+	return pointer + "/" + strconv.Itoa(i)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeometryValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected []ValidationError
+	}{
+		{
+			name: "valid polygon with hole",
+			data: `{"type":"Polygon","bbox":[0,0,10,10],"coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[2,2]]]}`,
+		},
+		{
+			name: "empty geometries",
+			data: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[]},{"type":"LineString","coordinates":[]}]}`,
+		},
+		{
+			name: "antimeridian bbox",
+			data: `{"type":"MultiPoint","bbox":[170,0,-170,1],"coordinates":[[170,0],[-170,1]]}`,
+		},
+		{
+			name:     "unknown type",
+			data:     `{"type":"Circle","coordinates":[0,0]}`,
+			expected: []ValidationError{{ValidationType, "/type"}},
+		},
+		{
+			name: "bad positions",
+			data: `{"type":"MultiPoint","coordinates":[[1],[200,0],[0,-91]]}`,
+			expected: []ValidationError{
+				{ValidationPositionArity, "/coordinates/0"},
+				{ValidationOutOfRange, "/coordinates/1"},
+				{ValidationOutOfRange, "/coordinates/2"},
+			},
+		},
+		{
+			name:     "single position line",
+			data:     `{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[0,0]]]}`,
+			expected: []ValidationError{{ValidationTooFewPositions, "/coordinates/1"}},
+		},
+		{
+			name: "bad rings",
+			data: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,0]]],[[[0,0],[1,0],[1,1],[0,1]]],[[[0,0],[0,1],[1,1],[0,0]],[[0.2,0.1],[0.8,0.8],[0.2,0.8],[0.2,0.1]]]]}`,
+			expected: []ValidationError{
+				{ValidationTooFewPositions, "/coordinates/0/0"},
+				{ValidationUnclosedRing, "/coordinates/1/0"},
+				{ValidationWindingOrder, "/coordinates/2/0"},
+				{ValidationWindingOrder, "/coordinates/2/1"},
+			},
+		},
+		{
+			name: "bounding boxes",
+			data: `{"type":"GeometryCollection","bbox":[0,0,0,1,1,1],"geometries":[{"type":"Point","bbox":[1,2,1,1],"coordinates":[1,2]}]}`,
+			expected: []ValidationError{
+				{ValidationBoundingBox, "/geometries/0/bbox"},
+				{ValidationBoundingBox, "/bbox"},
+			},
+		},
+		{
+			name: "nested collection",
+			data: `{"type":"GeometryCollection","geometries":[{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]}]}]}`,
+			expected: []ValidationError{
+				{ValidationNestedCollection, "/geometries/0"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := DecodeOptions{AllowUnknownGeometryTypes: true}.UnmarshalGeometry([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("should unmarshal, but got %v", err)
+			}
+			if errs := g.Validate(); !reflect.DeepEqual(errs, tc.expected) {
+				t.Errorf("incorrect validation errors, expected %v, got %v", tc.expected, errs)
+			}
+		})
+	}
+}
+
+func TestFeatureValidate(t *testing.T) {
+	fc, err := UnmarshalFeatureCollection([]byte(`{"type":"FeatureCollection","bbox":[0,0,5,5],"features":[
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0,1],[1,1,2]]},"properties":null},
+		{"type":"Feature","geometry":null,"properties":null},
+		{"type":"Place","bbox":[0,0,1,1],"geometry":{"type":"Point","coordinates":[0,100]},"properties":null}
+	]}`))
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+
+	if errs := fc.Features[0].Validate(); errs != nil {
+		t.Errorf("should validate the line feature, got %v", errs)
+	}
+
+	expected := []ValidationError{
+		{ValidationType, "/type"},
+		{ValidationOutOfRange, "/geometry/coordinates"},
+	}
+	if errs := fc.Features[2].Validate(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("incorrect feature errors, got %v", errs)
+	}
+
+	errs := fc.Validate()
+	expected = []ValidationError{
+		{ValidationType, "/features/2/type"},
+		{ValidationOutOfRange, "/features/2/geometry/coordinates"},
+		{ValidationBoundingBox, "/bbox"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("incorrect collection errors, got %v", errs)
+	}
+	if errs[1].Error() != "/features/2/geometry/coordinates: coordinate out of range" {
+		t.Errorf("incorrect error message, got %s", errs[1].Error())
+	}
+	if p := NewProblem(422, errs[1]); p.Errors[0].Pointer != "/features/2/geometry/coordinates" {
+		t.Errorf("should locate the error in a problem, got %v", p.Errors)
+	}
+}
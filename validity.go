@@ -0,0 +1,495 @@
+package geojson
+
+import (
+	"fmt"
+)
+
+// A ValidityErrorKind classifies the problems found by CheckValidity.
+type ValidityErrorKind int
+
+// The kinds of validity errors.
+const (
+	// ValidityCoordinate is a position with less than 2 coordinates, or
+	// with a coordinate that is not finite.
+	ValidityCoordinate ValidityErrorKind = iota
+
+	// ValidityTooFewPoints is a line string with less than 2 distinct
+	// positions, or a ring with less than 4 positions.
+	ValidityTooFewPoints
+
+	// ValidityUnclosedRing is a ring whose last position is not its first.
+	ValidityUnclosedRing
+
+	// ValidityRepeatedPoint is a position repeated consecutively.
+	ValidityRepeatedPoint
+
+	// ValiditySelfIntersection is a ring crossing or touching itself, like
+	// a bowtie.
+	ValiditySelfIntersection
+
+	// ValidityRingsCross is a hole crossing the exterior ring or another
+	// hole of its polygon.
+	ValidityRingsCross
+
+	// ValidityHoleOutsideShell is a hole not inside the exterior ring of
+	// its polygon.
+	ValidityHoleOutsideShell
+
+	// ValidityNestedHoles is a hole inside another hole.
+	ValidityNestedHoles
+
+	// ValidityOverlappingPolygons is a multi polygon whose polygons have
+	// overlapping interiors.
+	ValidityOverlappingPolygons
+)
+
+// String returns a description of the kind.
+func (k ValidityErrorKind) String() string {
+	switch k {
+	case ValidityCoordinate:
+		return "invalid coordinate"
+	case ValidityTooFewPoints:
+		return "too few points"
+	case ValidityUnclosedRing:
+		return "ring is not closed"
+	case ValidityRepeatedPoint:
+		return "repeated point"
+	case ValiditySelfIntersection:
+		return "ring self-intersection"
+	case ValidityRingsCross:
+		return "rings cross"
+	case ValidityHoleOutsideShell:
+		return "hole outside shell"
+	case ValidityNestedHoles:
+		return "nested holes"
+	case ValidityOverlappingPolygons:
+		return "overlapping polygons"
+	}
+	return fmt.Sprintf("validity error %d", int(k))
+}
+
+// A ValidityError is a violation of the OGC simple features rules found by
+// CheckValidity.
+type ValidityError struct {
+	Kind ValidityErrorKind
+
+	// Point locates the problem: the offending position or intersection,
+	// or a point inside the overlap of polygons.
+	Point []float64
+}
+
+// Error describes the validity error.
+func (e ValidityError) Error() string {
+	return fmt.Sprintf("%s at %v", e.Kind, e.Point)
+}
+
+// IsValid returns true if the geometry follows the OGC simple features
+// rules, see CheckValidity. Databases like MongoDB reject invalid polygons
+// from their geospatial indexes.
+func (g *Geometry) IsValid() bool {
+	return len(g.CheckValidity()) == 0
+}
+
+// CheckValidity checks the geometry against the OGC simple features rules
+// and returns the problems found, nil if none. Rings must be closed,
+// without repeated points and simple, holes must be inside their exterior
+// ring, not inside each other and not cross any other ring, and the
+// polygons of multi polygons must not overlap. Only the first two
+// coordinates are considered.
+//
+// The checks are quadratic in the number of vertices of each polygon.
+func (g *Geometry) CheckValidity() []ValidityError {
+	v := &validityChecker{}
+	v.geometry(g)
+	return v.errs
+}
+
+// A validityChecker collects the validity errors of a geometry.
+type validityChecker struct {
+	errs []ValidityError
+}
+
+func (v *validityChecker) add(kind ValidityErrorKind, p []float64) {
+	v.errs = append(v.errs, ValidityError{Kind: kind, Point: p})
+}
+
+func (v *validityChecker) geometry(g *Geometry) {
+	if g == nil {
+		return
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) != 0 {
+			v.positions([][]float64{g.Point})
+		}
+	case GeometryMultiPoint:
+		v.positions(g.MultiPoint)
+	case GeometryLineString:
+		v.lineString(g.LineString)
+	case GeometryMultiLineString:
+		for _, line := range g.MultiLineString {
+			v.lineString(line)
+		}
+	case GeometryPolygon:
+		v.polygon(g.Polygon)
+	case GeometryMultiPolygon:
+		valid := true
+		for _, polygon := range g.MultiPolygon {
+			n := len(v.errs)
+			v.polygon(polygon)
+			valid = valid && len(v.errs) == n
+		}
+		if !valid {
+			return
+		}
+		for i, a := range g.MultiPolygon {
+			for _, b := range g.MultiPolygon[i+1:] {
+				if len(a) == 0 || len(b) == 0 {
+					continue
+				}
+				if p := polygonsOverlap(a, b); p != nil {
+					v.add(ValidityOverlappingPolygons, p)
+				}
+			}
+		}
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			v.geometry(m)
+		}
+	}
+}
+
+// positions checks the coordinates of the positions, and returns false if
+// any is invalid.
+func (v *validityChecker) positions(positions [][]float64) bool {
+	for _, p := range positions {
+		if !finitePosition(p) {
+			v.add(ValidityCoordinate, p)
+			return false
+		}
+	}
+	return true
+}
+
+func (v *validityChecker) lineString(line [][]float64) {
+	if len(line) == 0 || !v.positions(line) {
+		return
+	}
+	for i := 1; i < len(line); i++ {
+		if !same2D(line[0], line[i]) {
+			return
+		}
+	}
+	v.add(ValidityTooFewPoints, line[0])
+}
+
+func (v *validityChecker) polygon(polygon [][][]float64) {
+	for _, ring := range polygon {
+		if !v.ring(ring) {
+			return
+		}
+	}
+
+	for i, a := range polygon {
+		for _, b := range polygon[i+1:] {
+			if p := ringsCross(a, b); p != nil {
+				v.add(ValidityRingsCross, p)
+				return
+			}
+		}
+	}
+
+	for i, hole := range polygon[1:] {
+		if p := ringVertexIn(hole, polygon[0], Exterior); p != nil {
+			v.add(ValidityHoleOutsideShell, p)
+		}
+		for _, other := range polygon[i+2:] {
+			if p := ringVertexIn(other, hole, Interior); p != nil {
+				v.add(ValidityNestedHoles, p)
+			} else if p := ringVertexIn(hole, other, Interior); p != nil {
+				v.add(ValidityNestedHoles, p)
+			}
+		}
+	}
+}
+
+// ring checks the ring on its own, and returns false if it is invalid.
+func (v *validityChecker) ring(ring [][]float64) bool {
+	if len(ring) == 0 {
+		v.add(ValidityTooFewPoints, nil)
+		return false
+	}
+	if !v.positions(ring) {
+		return false
+	}
+	if len(ring) < 4 {
+		v.add(ValidityTooFewPoints, ring[0])
+		return false
+	}
+	if !same2D(ring[0], ring[len(ring)-1]) {
+		v.add(ValidityUnclosedRing, ring[len(ring)-1])
+		return false
+	}
+	for i := 1; i < len(ring); i++ {
+		if same2D(ring[i-1], ring[i]) {
+			v.add(ValidityRepeatedPoint, ring[i])
+			return false
+		}
+	}
+	if p := ringSelfIntersection(ring); p != nil {
+		v.add(ValiditySelfIntersection, p)
+		return false
+	}
+	return true
+}
+
+// ringSelfIntersection returns a point where the closed ring, without
+// repeated points, crosses or touches itself, nil if it is simple.
+func ringSelfIntersection(ring [][]float64) []float64 {
+	n := len(ring) - 1
+	for i := 0; i < n; i++ {
+		a, b := ring[i], ring[i+1]
+
+		// the next edge shares b, it may only fold back over this one
+		c := ring[(i+2)%n]
+		if isLeft(a, b, c) == 0 && (onSegment(c, a, b) || onSegment(a, b, c)) {
+			return b
+		}
+
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				// the closing edge shares a
+				continue
+			}
+			if p := segmentContact(a, b, ring[j], ring[j+1]); p != nil {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// segmentContact returns a point where the segments a-b and c-d cross or
+// touch, nil if they are disjoint.
+func segmentContact(a, b, c, d []float64) []float64 {
+	if p := properIntersection(a, b, c, d); p != nil {
+		return p
+	}
+	switch {
+	case onSegment(c, a, b):
+		return c
+	case onSegment(d, a, b):
+		return d
+	case onSegment(a, c, d):
+		return a
+	case onSegment(b, c, d):
+		return b
+	}
+	return nil
+}
+
+// ringsCross returns a point where the rings cross, nil if they do not.
+// Rings may touch at points.
+func ringsCross(a, b [][]float64) []float64 {
+	for i := 1; i < len(a); i++ {
+		for j := 1; j < len(b); j++ {
+			if p := properIntersection(a[i-1], a[i], b[j-1], b[j]); p != nil {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// ringVertexIn returns the first vertex of the ring at the location
+// relative to the other ring, nil if none is.
+func ringVertexIn(ring, other [][]float64, location PointLocation) []float64 {
+	polygon := [][][]float64{other}
+	for _, p := range ring {
+		if PointInPolygonWinding(p, polygon) == location {
+			return p
+		}
+	}
+	return nil
+}
+
+// finitePosition tells if the position has at least 2 coordinates, all
+// finite.
+func finitePosition(p []float64) bool {
+	return validPositions([][]float64{p})
+}
+
+// same2D tells if the positions have the same first two coordinates.
+func same2D(a, b []float64) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+// MakeValid returns a copy of the geometry with the common defects of
+// polygons repaired, following the OGC simple features rules:
+//
+//   - positions with invalid coordinates and repeated points are removed,
+//   - unclosed rings are closed,
+//   - rings crossing themselves, like bowties, are split into simple rings
+//     at their crossings, the pieces of an exterior ring becoming separate
+//     polygons,
+//   - holes are given to the polygon containing them, and dropped if none
+//     does,
+//   - rings without area are dropped,
+//   - rings are wound following the right-hand rule of RFC 7946.
+//
+// A polygon becomes a multi polygon if it is split, and an empty polygon
+// if nothing is left of it. Rings touching themselves without crossing,
+// crossing holes and overlapping polygons are not repaired. Other
+// geometries are only copied, with their invalid positions removed.
+func (g *Geometry) MakeValid() *Geometry {
+	if g == nil {
+		return nil
+	}
+
+	c := g.Clone()
+	switch c.Type {
+	case GeometryPoint:
+		if len(c.Point) != 0 && !finitePosition(c.Point) {
+			c.Point = []float64{}
+		}
+	case GeometryMultiPoint:
+		c.MultiPoint = finitePositions(c.MultiPoint)
+	case GeometryLineString:
+		c.LineString = finitePositions(c.LineString)
+	case GeometryMultiLineString:
+		for i, line := range c.MultiLineString {
+			c.MultiLineString[i] = finitePositions(line)
+		}
+	case GeometryPolygon:
+		polygons := makeValidPolygon(c.Polygon)
+		switch len(polygons) {
+		case 0:
+			c.Polygon = [][][]float64{}
+		case 1:
+			c.Polygon = polygons[0]
+		default:
+			c.Type = GeometryMultiPolygon
+			c.Polygon = nil
+			c.MultiPolygon = polygons
+		}
+	case GeometryMultiPolygon:
+		polygons := [][][][]float64{}
+		for _, p := range c.MultiPolygon {
+			polygons = append(polygons, makeValidPolygon(p)...)
+		}
+		c.MultiPolygon = polygons
+	case GeometryCollection:
+		for i, m := range g.Geometries {
+			c.Geometries[i] = m.MakeValid()
+		}
+	}
+
+	if c.BoundingBox != nil {
+		c.BoundingBox = boundingBox(c)
+	}
+	return c
+}
+
+// finitePositions returns the positions without the invalid ones.
+func finitePositions(positions [][]float64) [][]float64 {
+	result := positions[:0]
+	for _, p := range positions {
+		if finitePosition(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// makeValidPolygon repairs the polygon, which may be split into several.
+func makeValidPolygon(polygon [][][]float64) [][][][]float64 {
+	var shells, holes [][][]float64
+	for i, ring := range polygon {
+		ring = cleanRing(ring)
+		if ring == nil {
+			continue
+		}
+		for _, r := range splitRing(ring) {
+			if len(r) < 4 || ringArea2D(r) == 0 {
+				continue
+			}
+			if i == 0 {
+				shells = append(shells, r)
+			} else {
+				holes = append(holes, r)
+			}
+		}
+	}
+
+	result := make([][][][]float64, len(shells))
+	for i, shell := range shells {
+		if ringArea2D(shell) < 0 {
+			reverseRing(shell)
+		}
+		result[i] = [][][]float64{shell}
+	}
+
+	for _, hole := range holes {
+		if ringArea2D(hole) > 0 {
+			reverseRing(hole)
+		}
+		p := interiorPoint([][][]float64{hole})
+		if p == nil {
+			continue
+		}
+		for i, shell := range shells {
+			if PointInPolygonWinding(p, [][][]float64{shell}) == Interior {
+				result[i] = append(result[i], hole)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// cleanRing returns the ring without invalid positions or repeated points,
+// closed, or nil if less than 3 distinct positions are left.
+func cleanRing(ring [][]float64) [][]float64 {
+	var clean [][]float64
+	for _, p := range ring {
+		if !finitePosition(p) || len(clean) != 0 && same2D(clean[len(clean)-1], p) {
+			continue
+		}
+		clean = append(clean, p)
+	}
+	for len(clean) > 1 && same2D(clean[0], clean[len(clean)-1]) {
+		clean = clean[:len(clean)-1]
+	}
+	if len(clean) < 3 {
+		return nil
+	}
+	return append(clean, append([]float64(nil), clean[0]...))
+}
+
+// splitRing splits the closed ring at the points where it crosses itself
+// into simple rings.
+func splitRing(ring [][]float64) [][][]float64 {
+	n := len(ring) - 1
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			p := properIntersection(ring[i], ring[i+1], ring[j], ring[j+1])
+			if p == nil {
+				continue
+			}
+
+			loop := make([][]float64, 0, j-i+2)
+			loop = append(loop, p)
+			loop = append(loop, ring[i+1:j+1]...)
+			loop = append(loop, append([]float64(nil), p...))
+
+			rest := make([][]float64, 0, n-j+i+3)
+			rest = append(rest, ring[:i+1]...)
+			rest = append(rest, append([]float64(nil), p...))
+			rest = append(rest, ring[j+1:]...)
+
+			return append(splitRing(rest), splitRing(loop)...)
+		}
+	}
+	return [][][]float64{ring}
+}
@@ -0,0 +1,147 @@
+package geojson
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGeometryCheckValidity(t *testing.T) {
+	square := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		expected []ValidityError
+	}{
+		{
+			name:     "valid polygon with hole",
+			geometry: NewPolygonGeometry([][][]float64{square, {{2, 2}, {2, 8}, {8, 8}, {2, 2}}}),
+		},
+		{
+			name:     "hole touching the shell",
+			geometry: NewPolygonGeometry([][][]float64{square, {{0, 5}, {5, 8}, {5, 2}, {0, 5}}}),
+		},
+		{
+			name:     "empty",
+			geometry: NewMultiPolygonGeometry(),
+		},
+		{
+			name:     "bowtie",
+			geometry: NewPolygonGeometry([][][]float64{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}}),
+			expected: []ValidityError{{ValiditySelfIntersection, []float64{5, 5}}},
+		},
+		{
+			name:     "ring touching itself",
+			geometry: NewPolygonGeometry([][][]float64{{{0, 0}, {10, 0}, {5, 0}, {5, 5}, {0, 0}}}),
+			expected: []ValidityError{{ValiditySelfIntersection, []float64{10, 0}}},
+		},
+		{
+			name:     "unclosed ring",
+			geometry: NewPolygonGeometry([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}),
+			expected: []ValidityError{{ValidityUnclosedRing, []float64{0, 10}}},
+		},
+		{
+			name:     "repeated point",
+			geometry: NewPolygonGeometry([][][]float64{{{0, 0}, {10, 0}, {10, 0}, {10, 10}, {0, 0}}}),
+			expected: []ValidityError{{ValidityRepeatedPoint, []float64{10, 0}}},
+		},
+		{
+			name:     "too few points",
+			geometry: NewPolygonGeometry([][][]float64{{{0, 0}, {10, 0}, {0, 0}}}),
+			expected: []ValidityError{{ValidityTooFewPoints, []float64{0, 0}}},
+		},
+		{
+			name:     "invalid coordinate",
+			geometry: NewLineStringGeometry([][]float64{{0, 0}, {math.Inf(1), 0}}),
+			expected: []ValidityError{{ValidityCoordinate, []float64{math.Inf(1), 0}}},
+		},
+		{
+			name:     "degenerate line",
+			geometry: NewLineStringGeometry([][]float64{{1, 1}, {1, 1}}),
+			expected: []ValidityError{{ValidityTooFewPoints, []float64{1, 1}}},
+		},
+		{
+			name:     "hole crossing the shell",
+			geometry: NewPolygonGeometry([][][]float64{square, {{5, 5}, {15, 5}, {5, 8}, {5, 5}}}),
+			expected: []ValidityError{{ValidityRingsCross, []float64{10, 5}}},
+		},
+		{
+			name:     "hole outside shell",
+			geometry: NewPolygonGeometry([][][]float64{square, {{20, 20}, {20, 25}, {25, 25}, {20, 20}}}),
+			expected: []ValidityError{{ValidityHoleOutsideShell, []float64{20, 20}}},
+		},
+		{
+			name: "nested holes",
+			geometry: NewPolygonGeometry([][][]float64{square,
+				{{1, 1}, {1, 9}, {9, 9}, {9, 1}, {1, 1}},
+				{{2, 2}, {2, 8}, {8, 8}, {2, 2}},
+			}),
+			expected: []ValidityError{{ValidityNestedHoles, []float64{2, 2}}},
+		},
+		{
+			name: "overlapping polygons",
+			geometry: NewMultiPolygonGeometry(
+				[][][]float64{square},
+				[][][]float64{{{5, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 5}}},
+			),
+			expected: []ValidityError{{ValidityOverlappingPolygons, []float64{10, 5}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := c.geometry.CheckValidity()
+			if !reflect.DeepEqual(errs, c.expected) {
+				t.Errorf("incorrect errors, got %v", errs)
+			}
+			if c.geometry.IsValid() != (len(c.expected) == 0) {
+				t.Errorf("incorrect validity")
+			}
+		})
+	}
+}
+
+func TestGeometryMakeValid(t *testing.T) {
+	bowtie := NewPolygonGeometry([][][]float64{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}})
+	valid := bowtie.MakeValid()
+	if valid.Type != GeometryMultiPolygon || len(valid.MultiPolygon) != 2 {
+		t.Fatalf("should split the bowtie in two polygons, but got %v", valid)
+	}
+	if !valid.IsValid() {
+		t.Errorf("should be valid, got %v", valid.CheckValidity())
+	}
+	for _, p := range valid.MultiPolygon {
+		if math.Abs(ringArea2D(p[0])) != 25 || IsClockwise(p[0]) {
+			t.Errorf("incorrect triangle, got %v", p)
+		}
+	}
+	if bowtie.Type != GeometryPolygon || len(bowtie.Polygon[0]) != 5 {
+		t.Errorf("should leave the geometry as it is, got %v", bowtie)
+	}
+
+	dirty := NewPolygonGeometry([][][]float64{
+		{{0, 0}, {0, 10}, {0, 10}, {10, 10}, {math.NaN(), 0}, {10, 0}},
+		{{2, 2}, {8, 2}, {8, 8}, {2, 2}},
+		{{20, 20}, {20, 25}, {25, 25}, {20, 20}},
+		{{3, 3}, {3, 3}, {4, 3}},
+	})
+	valid = dirty.MakeValid()
+	expected := [][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {8, 8}, {8, 2}, {2, 2}},
+	}
+	if valid.Type != GeometryPolygon || !reflect.DeepEqual(valid.Polygon, expected) {
+		t.Errorf("incorrect polygon, got %v", valid.Polygon)
+	}
+
+	degenerate := NewPolygonGeometry([][][]float64{{{0, 0}, {1, 1}, {2, 2}, {0, 0}}}).MakeValid()
+	if degenerate.Type != GeometryPolygon || len(degenerate.Polygon) != 0 {
+		t.Errorf("should drop the polygon without area, got %v", degenerate.Polygon)
+	}
+
+	collection := NewCollectionGeometry(NewPointGeometry([]float64{math.NaN(), 0}), bowtie).MakeValid()
+	if len(collection.Geometries[0].Point) != 0 || collection.Geometries[1].Type != GeometryMultiPolygon {
+		t.Errorf("should repair the members of the collection, got %v", collection.Geometries)
+	}
+}
@@ -0,0 +1,209 @@
+package geojson
+
+import (
+	"fmt"
+)
+
+// geometryPath returns the path of the geometry addressed by ring,
+// sharing its backing array, and whether that path is a polygon ring.
+// Rings are numbered as for MoveVertex; points and collections have no paths.
+func geometryPath(g *Geometry, ring int) ([][]float64, bool, error) {
+	switch g.Type {
+	case GeometryMultiPoint:
+		if ring == 0 {
+			return g.MultiPoint, false, nil
+		}
+	case GeometryLineString:
+		if ring == 0 {
+			return g.LineString, false, nil
+		}
+	case GeometryMultiLineString:
+		if ring >= 0 && ring < len(g.MultiLineString) {
+			return g.MultiLineString[ring], false, nil
+		}
+	case GeometryPolygon:
+		if ring >= 0 && ring < len(g.Polygon) {
+			return g.Polygon[ring], true, nil
+		}
+	case GeometryMultiPolygon:
+		if ring >= 0 {
+			r := ring
+			for _, p := range g.MultiPolygon {
+				if r < len(p) {
+					return p[r], true, nil
+				}
+				r -= len(p)
+			}
+		}
+	default:
+		return nil, false, fmt.Errorf("vertex editing not supported on %s", g.Type)
+	}
+
+	return nil, false, fmt.Errorf("ring %d out of range", ring)
+}
+
+// vertex returns a copy of the vertex of the geometry at the given ring and position.
+// Points only have vertex 0 of ring 0.
+func vertex(g *Geometry, ring, pos int) ([]float64, error) {
+	if g.Type == GeometryPoint {
+		if ring != 0 || pos != 0 {
+			return nil, fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+		}
+		return append([]float64(nil), g.Point...), nil
+	}
+
+	path, _, err := geometryPath(g, ring)
+	if err != nil {
+		return nil, err
+	}
+	if pos < 0 || pos >= len(path) {
+		return nil, fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+	}
+
+	return append([]float64(nil), path[pos]...), nil
+}
+
+// MoveVertex moves the vertex at position pos of the given ring of the geometry.
+// Moving the first or last vertex of a polygon ring moves both, so that
+// the ring stays closed. Rings are numbered per path: 0 for a point, multi
+// point or line string, the line of a multi line string, the ring of a
+// polygon, or the ring of a multi polygon with the rings of all its polygons
+// numbered consecutively.
+func MoveVertex(g *Geometry, ring, pos int, to []float64) error {
+	to = append([]float64(nil), to...)
+
+	if g.Type == GeometryPoint {
+		if ring != 0 || pos != 0 {
+			return fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+		}
+		g.Point = to
+		return nil
+	}
+
+	path, isRing, err := geometryPath(g, ring)
+	if err != nil {
+		return err
+	}
+	if pos < 0 || pos >= len(path) {
+		return fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+	}
+
+	last := len(path) - 1
+	if isRing && last > 0 && (pos == 0 || pos == last) && samePosition(path[0], path[last]) {
+		path[0], path[last] = to, append([]float64(nil), to...)
+		return nil
+	}
+
+	path[pos] = to
+	return nil
+}
+
+// InsertVertex inserts a vertex before position pos of the given ring of the
+// geometry, pos being the length of the path to append. For closed polygon
+// rings, positions address the distinct vertices: inserting at 0 makes the new
+// vertex the start of the ring and the closing vertex follows, and the
+// closing vertex itself can not be addressed. Rings are numbered as for MoveVertex.
+func InsertVertex(g *Geometry, ring, pos int, v []float64) error {
+	path, isRing, err := geometryPath(g, ring)
+	if err != nil {
+		return err
+	}
+
+	closed := isRing && len(path) > 1 && samePosition(path[0], path[len(path)-1])
+	if closed {
+		path = path[:len(path)-1]
+	}
+
+	if pos < 0 || pos > len(path) {
+		return fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+	}
+
+	result := make([][]float64, 0, len(path)+2)
+	result = append(result, path[:pos]...)
+	result = append(result, append([]float64(nil), v...))
+	result = append(result, path[pos:]...)
+	if closed {
+		result = append(result, append([]float64(nil), result[0]...))
+	}
+
+	setGeometryPath(g, ring, result)
+	return nil
+}
+
+// DeleteVertex removes the vertex at position pos of the given ring of the
+// geometry. Deleting the first or last vertex of a closed polygon ring moves
+// the closing vertex along. An error is returned when the deletion would leave
+// a ring with less than 4 positions, a line with less than 2 or a multi point
+// empty. Rings are numbered as for MoveVertex.
+func DeleteVertex(g *Geometry, ring, pos int) error {
+	path, isRing, err := geometryPath(g, ring)
+	if err != nil {
+		return err
+	}
+	if pos < 0 || pos >= len(path) {
+		return fmt.Errorf("vertex %d of ring %d out of range", pos, ring)
+	}
+
+	min := 1
+	switch {
+	case isRing:
+		min = 4
+	case g.Type == GeometryLineString || g.Type == GeometryMultiLineString:
+		min = 2
+	}
+	if len(path)-1 < min {
+		return fmt.Errorf("deleting vertex %d would leave ring %d with less than %d positions", pos, ring, min)
+	}
+
+	closed := isRing && len(path) > 1 && samePosition(path[0], path[len(path)-1])
+	if closed {
+		path = path[:len(path)-1]
+		if pos == len(path) {
+			pos = 0
+		}
+	}
+
+	result := make([][]float64, 0, len(path))
+	result = append(result, path[:pos]...)
+	result = append(result, path[pos+1:]...)
+	if closed {
+		result = append(result, append([]float64(nil), result[0]...))
+	}
+
+	setGeometryPath(g, ring, result)
+	return nil
+}
+
+// setGeometryPath replaces the path of the geometry addressed by ring.
+func setGeometryPath(g *Geometry, ring int, path [][]float64) {
+	switch g.Type {
+	case GeometryMultiPoint:
+		g.MultiPoint = path
+	case GeometryLineString:
+		g.LineString = path
+	case GeometryMultiLineString:
+		g.MultiLineString[ring] = path
+	case GeometryPolygon:
+		g.Polygon[ring] = path
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			if ring < len(p) {
+				p[ring] = path
+				return
+			}
+			ring -= len(p)
+		}
+	}
+}
+
+func samePosition(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
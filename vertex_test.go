@@ -0,0 +1,89 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func vertexTestPolygon() *Geometry {
+	return NewMultiPolygonGeometry(
+		[][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}},
+		[][][]float64{
+			{{10, 10}, {20, 10}, {20, 20}, {10, 20}, {10, 10}},
+			{{12, 12}, {14, 12}, {14, 14}, {12, 12}},
+		},
+	)
+}
+
+func TestMoveVertex(t *testing.T) {
+	g := vertexTestPolygon()
+
+	if err := MoveVertex(g, 1, 4, []float64{9, 9}); err != nil {
+		t.Fatalf("should move vertex, got %v", err)
+	}
+
+	ring := g.MultiPolygon[1][0]
+	if !reflect.DeepEqual(ring[0], []float64{9, 9}) || !reflect.DeepEqual(ring[4], []float64{9, 9}) {
+		t.Errorf("should move both ends of the ring, got %v", ring)
+	}
+
+	p := NewPointGeometry([]float64{1, 2})
+	if err := MoveVertex(p, 0, 0, []float64{3, 4}); err != nil || p.Point[0] != 3 {
+		t.Errorf("should move point, got %v, %v", err, p.Point)
+	}
+
+	if err := MoveVertex(g, 3, 0, []float64{0, 0}); err == nil {
+		t.Errorf("should return error for unknown ring")
+	}
+}
+
+func TestInsertVertex(t *testing.T) {
+	g := vertexTestPolygon()
+
+	if err := InsertVertex(g, 0, 0, []float64{-1, -1}); err != nil {
+		t.Fatalf("should insert vertex, got %v", err)
+	}
+
+	expected := [][]float64{{-1, -1}, {0, 0}, {4, 0}, {4, 4}, {-1, -1}}
+	if !reflect.DeepEqual(g.MultiPolygon[0][0], expected) {
+		t.Errorf("should insert at start and keep ring closed, got %v", g.MultiPolygon[0][0])
+	}
+
+	if err := InsertVertex(g, 2, 3, []float64{13, 15}); err != nil {
+		t.Fatalf("should insert vertex, got %v", err)
+	}
+	if len(g.MultiPolygon[1][1]) != 5 || g.MultiPolygon[1][1][3][1] != 15 {
+		t.Errorf("should insert before closing vertex, got %v", g.MultiPolygon[1][1])
+	}
+
+	l := NewLineStringGeometry([][]float64{{0, 0}, {1, 1}})
+	if err := InsertVertex(l, 0, 2, []float64{2, 2}); err != nil || len(l.LineString) != 3 {
+		t.Errorf("should append vertex, got %v, %v", err, l.LineString)
+	}
+}
+
+func TestDeleteVertex(t *testing.T) {
+	g := vertexTestPolygon()
+
+	if err := DeleteVertex(g, 0, 1); err == nil {
+		t.Errorf("should refuse leaving a ring with less than 4 positions")
+	}
+
+	if err := DeleteVertex(g, 1, 0); err != nil {
+		t.Fatalf("should delete vertex, got %v", err)
+	}
+
+	expected := [][]float64{{20, 10}, {20, 20}, {10, 20}, {20, 10}}
+	if !reflect.DeepEqual(g.MultiPolygon[1][0], expected) {
+		t.Errorf("should delete first vertex and keep ring closed, got %v", g.MultiPolygon[1][0])
+	}
+
+	l := NewLineStringGeometry([][]float64{{0, 0}, {1, 1}})
+	if err := DeleteVertex(l, 0, 0); err == nil {
+		t.Errorf("should refuse leaving a line with less than 2 positions")
+	}
+
+	if err := DeleteVertex(NewPointGeometry([]float64{1, 2}), 0, 0); err == nil {
+		t.Errorf("should refuse deleting a point")
+	}
+}
@@ -0,0 +1,132 @@
+package geojson
+
+// IsClockwise returns true if the ring turns clockwise, in the plane of its
+// first two coordinates. Rings with less than 3 positions, or with
+// positions of less than 2 coordinates, are not clockwise.
+func IsClockwise(ring [][]float64) bool {
+	if len(ring) < 3 || !validPositions(ring) {
+		return false
+	}
+	return ringArea2D(ring) < 0
+}
+
+// Rewind reverses in place the rings of the polygons of the geometry that
+// do not follow the right-hand rule of RFC 7946: exterior rings must be
+// counterclockwise and holes clockwise. Many renderers fill wrongly wound
+// polygons incorrectly. The members of geometry collections are rewound
+// too. Rewind returns true if any ring was reversed.
+func (g *Geometry) Rewind() bool {
+	if g == nil {
+		return false
+	}
+
+	rewound := false
+	switch g.Type {
+	case GeometryPolygon:
+		rewound = rewindPolygon(g.Polygon)
+	case GeometryMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			rewound = rewindPolygon(p) || rewound
+		}
+	case GeometryCollection:
+		for _, m := range g.Geometries {
+			rewound = m.Rewind() || rewound
+		}
+	}
+	return rewound
+}
+
+// rewindPolygon reverses in place the rings of the polygon with the wrong
+// orientation.
+func rewindPolygon(polygon [][][]float64) bool {
+	rewound := false
+	for i, ring := range polygon {
+		if wrongOrientation(ring, i == 0) {
+			reverseRing(ring)
+			rewound = true
+		}
+	}
+	return rewound
+}
+
+// wrongOrientation tells if the ring does not follow the right-hand rule.
+// Degenerate rings, without area, are left as they are.
+func wrongOrientation(ring [][]float64, exterior bool) bool {
+	if len(ring) < 3 || !validPositions(ring) {
+		return false
+	}
+	area := ringArea2D(ring)
+	return exterior && area < 0 || !exterior && area > 0
+}
+
+// rewound returns the geometry, or a copy of it sharing the coordinates but
+// with rewound copies of the rings of the wrong orientation, when the
+// option is set.
+func (o MarshalOptions) rewound(g *Geometry) *Geometry {
+	if !o.Rewind || g == nil {
+		return g
+	}
+
+	switch g.Type {
+	case GeometryPolygon:
+		if polygon, ok := rewoundPolygon(g.Polygon); ok {
+			c := *g
+			c.Polygon = polygon
+			return &c
+		}
+	case GeometryMultiPolygon:
+		var multi [][][][]float64
+		for i, p := range g.MultiPolygon {
+			polygon, ok := rewoundPolygon(p)
+			if !ok {
+				continue
+			}
+			if multi == nil {
+				multi = append([][][][]float64(nil), g.MultiPolygon...)
+			}
+			multi[i] = polygon
+		}
+		if multi != nil {
+			c := *g
+			c.MultiPolygon = multi
+			return &c
+		}
+	case GeometryCollection:
+		c := *g
+		c.Geometries = make([]*Geometry, len(g.Geometries))
+		for i, m := range g.Geometries {
+			c.Geometries[i] = o.rewound(m)
+		}
+		return &c
+	}
+	return g
+}
+
+// rewoundFeature returns the feature, or a copy of it with its geometry
+// rewound, when the option is set.
+func (o MarshalOptions) rewoundFeature(f *Feature) *Feature {
+	if !o.Rewind || f == nil || f.Geometry == nil {
+		return f
+	}
+
+	c := *f
+	c.Geometry = o.rewound(f.Geometry)
+	return &c
+}
+
+// rewoundPolygon returns a copy of the polygon with its rings of the wrong
+// orientation reversed, and false if none is.
+func rewoundPolygon(polygon [][][]float64) ([][][]float64, bool) {
+	var c [][][]float64
+	for i, ring := range polygon {
+		if !wrongOrientation(ring, i == 0) {
+			continue
+		}
+		if c == nil {
+			c = append([][][]float64(nil), polygon...)
+		}
+		c[i] = append([][]float64(nil), ring...)
+		reverseRing(c[i])
+	}
+	return c, c != nil
+}
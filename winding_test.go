@@ -0,0 +1,99 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsClockwise(t *testing.T) {
+	cases := []struct {
+		name     string
+		ring     [][]float64
+		expected bool
+	}{
+		{"counterclockwise", [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, false},
+		{"clockwise", [][]float64{{0, 0}, {1, 1}, {1, 0}, {0, 0}}, true},
+		{"unclosed clockwise", [][]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}}, true},
+		{"too short", [][]float64{{0, 0}, {1, 1}}, false},
+		{"bad position", [][]float64{{0, 0}, {1}, {1, 0}, {0, 0}}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsClockwise(c.ring); got != c.expected {
+				t.Errorf("incorrect orientation, got %v", got)
+			}
+		})
+	}
+}
+
+func TestGeometryRewind(t *testing.T) {
+	g := NewMultiPolygonGeometry(
+		[][][]float64{
+			{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}},
+			{{2, 2}, {8, 2}, {8, 8}, {2, 2}},
+		},
+		[][][]float64{
+			{{20, 0}, {30, 0}, {30, 10}, {20, 0}},
+		},
+	)
+	if !g.Rewind() {
+		t.Fatalf("should rewind the first polygon")
+	}
+
+	expected := [][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {8, 8}, {8, 2}, {2, 2}},
+	}
+	if !reflect.DeepEqual(g.MultiPolygon[0], expected) {
+		t.Errorf("incorrect polygon, got %v", g.MultiPolygon[0])
+	}
+	if !reflect.DeepEqual(g.MultiPolygon[1], [][][]float64{{{20, 0}, {30, 0}, {30, 10}, {20, 0}}}) {
+		t.Errorf("should keep the second polygon, got %v", g.MultiPolygon[1])
+	}
+	if g.Rewind() {
+		t.Errorf("should not rewind twice")
+	}
+
+	collection := NewCollectionGeometry(NewPointGeometry([]float64{0, 0}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 1}, {1, 0}, {0, 0}}}))
+	if !collection.Rewind() || IsClockwise(collection.Geometries[1].Polygon[0]) {
+		t.Errorf("should rewind the members of collections")
+	}
+
+	var nilGeometry *Geometry
+	if nilGeometry.Rewind() {
+		t.Errorf("should not rewind nil")
+	}
+}
+
+func TestMarshalOptionsRewind(t *testing.T) {
+	g := NewPolygonGeometry([][][]float64{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}})
+	f := NewFeature(g)
+	fc := NewFeatureCollection()
+	fc.AddFeature(f)
+
+	data, err := MarshalOptions{Rewind: true}.MarshalGeometry(g)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if string(data) != `{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,1],[0,0]]]}` {
+		t.Errorf("incorrect geometry, got %s", data)
+	}
+
+	data, err = MarshalOptions{Rewind: true}.MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	decoded, err := UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if IsClockwise(decoded.Features[0].Geometry.Polygon[0]) {
+		t.Errorf("should rewind the features, got %s", data)
+	}
+
+	if !IsClockwise(g.Polygon[0]) {
+		t.Errorf("should leave the marshaled geometry as it is")
+	}
+}
@@ -0,0 +1,457 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The WKB geometry type codes, ISO WKB adds 1000 for Z, 2000 for M and 3000 for ZM.
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// The EWKB flags, set on the type code by PostGIS.
+const (
+	ewkbZFlag    = 0x80000000
+	ewkbMFlag    = 0x40000000
+	ewkbSRIDFlag = 0x20000000
+)
+
+var wkbTypes = map[GeometryType]uint32{
+	GeometryPoint:           wkbPoint,
+	GeometryLineString:      wkbLineString,
+	GeometryPolygon:         wkbPolygon,
+	GeometryMultiPoint:      wkbMultiPoint,
+	GeometryMultiLineString: wkbMultiLineString,
+	GeometryMultiPolygon:    wkbMultiPolygon,
+	GeometryCollection:      wkbGeometryCollection,
+}
+
+// MarshalWKB converts the geometry into little endian OGC Well-Known Binary.
+// Geometries whose positions all have an altitude are written with Z
+// coordinates, and with ZM coordinates if they all have a measure too,
+// using the ISO type codes. Empty points have NaN coordinates.
+func (g *Geometry) MarshalWKB() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := g.WriteWKB(&buf, binary.LittleEndian); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteWKB writes the geometry as Well-Known Binary in the given byte order,
+// see MarshalWKB.
+func (g *Geometry) WriteWKB(w io.Writer, order binary.ByteOrder) error {
+	if g == nil {
+		return errors.New("no geometry to convert to WKB")
+	}
+
+	z, err := hasAltitude(g)
+	if err != nil {
+		return err
+	}
+
+	e := &wkbWriter{order: order, z: z, m: z && g.HasM()}
+	if err := e.writeGeometry(g); err != nil {
+		return err
+	}
+	_, err = w.Write(e.buf.Bytes())
+	return err
+}
+
+// MarshalEWKB converts the geometry into little endian PostGIS Extended
+// Well-Known Binary, with the given SRID if it is positive.
+func (g *Geometry) MarshalEWKB(srid int) ([]byte, error) {
+	if g == nil {
+		return nil, errors.New("no geometry to convert to EWKB")
+	}
+
+	z, err := hasAltitude(g)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &wkbWriter{order: binary.LittleEndian, z: z, m: z && g.HasM(), extended: true, srid: srid}
+	if err := e.writeGeometry(g); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalWKB decodes Well-Known Binary, in either byte order, into a geometry.
+// Z coordinates are kept as altitudes and the M coordinates of ZM geometries
+// as the fourth coordinates of positions, see MeasureOf. The M coordinates
+// of geometries without Z are dropped. PostGIS EWKB is accepted too, see
+// UnmarshalEWKB to get its SRID.
+func UnmarshalWKB(data []byte) (*Geometry, error) {
+	g, _, err := UnmarshalEWKB(data)
+	return g, err
+}
+
+// UnmarshalEWKB decodes PostGIS Extended Well-Known Binary into a geometry,
+// also returning its SRID, 0 if it has none.
+func UnmarshalEWKB(data []byte) (*Geometry, int, error) {
+	r := &wkbReader{data: data}
+	g, err := r.readGeometry()
+	if err != nil {
+		return nil, 0, err
+	}
+	if r.pos != len(data) {
+		return nil, 0, fmt.Errorf("invalid WKB: %d trailing bytes", len(data)-r.pos)
+	}
+	return g, r.srid, nil
+}
+
+type wkbWriter struct {
+	buf   bytes.Buffer
+	order binary.ByteOrder
+	z, m  bool
+
+	// extended writes EWKB flags instead of ISO codes,
+	// and the SRID on the first geometry if it is positive.
+	extended bool
+	srid     int
+}
+
+func (e *wkbWriter) writeGeometry(g *Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry in collection")
+	}
+
+	code, ok := wkbTypes[g.Type]
+	if !ok {
+		return fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+	withSRID := e.extended && e.srid > 0
+	switch {
+	case e.extended && e.m:
+		code |= ewkbZFlag | ewkbMFlag
+	case e.extended && e.z:
+		code |= ewkbZFlag
+	case e.m:
+		code += 3000
+	case e.z:
+		code += 1000
+	}
+	if withSRID {
+		code |= ewkbSRIDFlag
+	}
+
+	if e.order == binary.BigEndian {
+		e.buf.WriteByte(0)
+	} else {
+		e.buf.WriteByte(1)
+	}
+	e.uint32(code)
+	if withSRID {
+		e.uint32(uint32(e.srid))
+		e.srid = 0 // only on the outer geometry
+	}
+
+	switch g.Type {
+	case GeometryPoint:
+		if len(g.Point) == 0 {
+			nan := []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+			e.position(nan)
+			return nil
+		}
+		e.position(g.Point)
+	case GeometryLineString:
+		e.path(g.LineString)
+	case GeometryPolygon:
+		e.paths(g.Polygon)
+	case GeometryMultiPoint:
+		e.uint32(uint32(len(g.MultiPoint)))
+		for _, p := range g.MultiPoint {
+			if err := e.writeGeometry(NewPointGeometry(p)); err != nil {
+				return err
+			}
+		}
+	case GeometryMultiLineString:
+		e.uint32(uint32(len(g.MultiLineString)))
+		for _, l := range g.MultiLineString {
+			if err := e.writeGeometry(NewLineStringGeometry(l)); err != nil {
+				return err
+			}
+		}
+	case GeometryMultiPolygon:
+		e.uint32(uint32(len(g.MultiPolygon)))
+		for _, p := range g.MultiPolygon {
+			if err := e.writeGeometry(NewPolygonGeometry(p)); err != nil {
+				return err
+			}
+		}
+	case GeometryCollection:
+		e.uint32(uint32(len(g.Geometries)))
+		for _, c := range g.Geometries {
+			if err := e.writeGeometry(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *wkbWriter) uint32(v uint32) {
+	var b [4]byte
+	e.order.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *wkbWriter) position(p []float64) {
+	n := 2
+	switch {
+	case e.m:
+		n = 4
+	case e.z:
+		n = 3
+	}
+
+	var b [8]byte
+	for i := 0; i < n; i++ {
+		e.order.PutUint64(b[:], math.Float64bits(p[i]))
+		e.buf.Write(b[:])
+	}
+}
+
+func (e *wkbWriter) path(path [][]float64) {
+	e.uint32(uint32(len(path)))
+	for _, p := range path {
+		e.position(p)
+	}
+}
+
+func (e *wkbWriter) paths(paths [][][]float64) {
+	e.uint32(uint32(len(paths)))
+	for _, p := range paths {
+		e.path(p)
+	}
+}
+
+type wkbReader struct {
+	data []byte
+	pos  int
+	srid int // of the outer geometry
+}
+
+// wkbHeader is the decoded start of a WKB geometry.
+type wkbHeader struct {
+	order binary.ByteOrder
+	code  uint32 // base type code, 1 to 7
+	z, m  bool
+}
+
+func (r *wkbReader) readHeader() (wkbHeader, error) {
+	var h wkbHeader
+	if r.pos >= len(r.data) {
+		return h, errors.New("invalid WKB: unexpected end of data")
+	}
+
+	switch r.data[r.pos] {
+	case 0:
+		h.order = binary.BigEndian
+	case 1:
+		h.order = binary.LittleEndian
+	default:
+		return h, fmt.Errorf("invalid WKB: unknown byte order %d", r.data[r.pos])
+	}
+	r.pos++
+
+	code, err := r.uint32(h.order)
+	if err != nil {
+		return h, err
+	}
+
+	h.z, h.m = code&ewkbZFlag != 0, code&ewkbMFlag != 0
+	if code&ewkbSRIDFlag != 0 {
+		srid, err := r.uint32(h.order)
+		if err != nil {
+			return h, err
+		}
+		if r.srid == 0 {
+			r.srid = int(int32(srid))
+		}
+	}
+	code &^= ewkbZFlag | ewkbMFlag | ewkbSRIDFlag
+
+	switch code / 1000 {
+	case 1:
+		h.z = true
+	case 2:
+		h.m = true
+	case 3:
+		h.z, h.m = true, true
+	}
+	h.code = code % 1000
+
+	return h, nil
+}
+
+func (r *wkbReader) readGeometry() (*Geometry, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	return r.readBody(h)
+}
+
+func (r *wkbReader) readBody(h wkbHeader) (*Geometry, error) {
+	dims := 2
+	if h.z {
+		dims++
+	}
+	if h.m {
+		dims++
+	}
+
+	switch h.code {
+	case wkbPoint:
+		p, err := r.position(h, dims)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(p[0]) && math.IsNaN(p[1]) {
+			p = []float64{}
+		}
+		return NewPointGeometry(p), nil
+	case wkbLineString:
+		path, err := r.path(h, dims)
+		if err != nil {
+			return nil, err
+		}
+		return NewLineStringGeometry(path), nil
+	case wkbPolygon:
+		n, err := r.count(h.order, 4)
+		if err != nil {
+			return nil, err
+		}
+		polygon := make([][][]float64, n)
+		for i := range polygon {
+			if polygon[i], err = r.path(h, dims); err != nil {
+				return nil, err
+			}
+		}
+		return NewPolygonGeometry(polygon), nil
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := r.count(h.order, 5)
+		if err != nil {
+			return nil, err
+		}
+
+		g := &Geometry{Type: GeometryCollection, Geometries: make([]*Geometry, 0, n)}
+		for i := 0; i < n; i++ {
+			c, err := r.readGeometry()
+			if err != nil {
+				return nil, err
+			}
+			g.Geometries = append(g.Geometries, c)
+		}
+		return collectParts(h.code, g)
+	}
+
+	return nil, fmt.Errorf("invalid WKB: unknown geometry type %d", h.code)
+}
+
+// collectParts turns the members read for a multi geometry into its coordinates.
+func collectParts(code uint32, g *Geometry) (*Geometry, error) {
+	expected := map[uint32]GeometryType{
+		wkbMultiPoint:      GeometryPoint,
+		wkbMultiLineString: GeometryLineString,
+		wkbMultiPolygon:    GeometryPolygon,
+	}[code]
+	if expected == "" {
+		return g, nil
+	}
+
+	result := &Geometry{}
+	for _, c := range g.Geometries {
+		if c.Type != expected {
+			return nil, fmt.Errorf("invalid WKB: %s in a multi %s", c.Type, expected)
+		}
+	}
+
+	switch code {
+	case wkbMultiPoint:
+		result.Type, result.MultiPoint = GeometryMultiPoint, make([][]float64, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			result.MultiPoint = append(result.MultiPoint, c.Point)
+		}
+	case wkbMultiLineString:
+		result.Type, result.MultiLineString = GeometryMultiLineString, make([][][]float64, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			result.MultiLineString = append(result.MultiLineString, c.LineString)
+		}
+	case wkbMultiPolygon:
+		result.Type, result.MultiPolygon = GeometryMultiPolygon, make([][][][]float64, 0, len(g.Geometries))
+		for _, c := range g.Geometries {
+			result.MultiPolygon = append(result.MultiPolygon, c.Polygon)
+		}
+	}
+	return result, nil
+}
+
+func (r *wkbReader) uint32(order binary.ByteOrder) (uint32, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, errors.New("invalid WKB: unexpected end of data")
+	}
+	v := order.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// count reads a number of elements, checking the remaining data
+// can hold them when each takes at least size bytes.
+func (r *wkbReader) count(order binary.ByteOrder, size int) (int, error) {
+	n, err := r.uint32(order)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(size) > uint64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("invalid WKB: %d elements do not fit in the data", n)
+	}
+	return int(n), nil
+}
+
+// position reads a position, dropping its M coordinate if it has no Z.
+func (r *wkbReader) position(h wkbHeader, dims int) ([]float64, error) {
+	if len(r.data)-r.pos < 8*dims {
+		return nil, errors.New("invalid WKB: unexpected end of data")
+	}
+
+	p := make([]float64, dims)
+	for i := range p {
+		p[i] = math.Float64frombits(h.order.Uint64(r.data[r.pos:]))
+		r.pos += 8
+	}
+
+	if h.m && !h.z {
+		p = p[:2]
+	}
+	return p, nil
+}
+
+func (r *wkbReader) path(h wkbHeader, dims int) ([][]float64, error) {
+	n, err := r.count(h.order, 8*dims)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([][]float64, n)
+	for i := range path {
+		if path[i], err = r.position(h, dims); err != nil {
+			return nil, err
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,187 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestMarshalWKB(t *testing.T) {
+	data, err := NewPointGeometry([]float64{1, 2}).MarshalWKB()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if h := hex.EncodeToString(data); h != "0101000000000000000000f03f0000000000000040" {
+		t.Errorf("incorrect little endian point, got %v", h)
+	}
+
+	var buf bytes.Buffer
+	if err := NewPointGeometry([]float64{1, 2}).WriteWKB(&buf, binary.BigEndian); err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if h := hex.EncodeToString(buf.Bytes()); h != "00000000013ff00000000000004000000000000000" {
+		t.Errorf("incorrect big endian point, got %v", h)
+	}
+
+	data, _ = NewPointGeometry([]float64{1, 2, 3}).MarshalWKB()
+	if h := hex.EncodeToString(data[:5]); h != "01e9030000" {
+		t.Errorf("should use the ISO Z type code, got %v", h)
+	}
+}
+
+func TestWKBRoundTrip(t *testing.T) {
+	geometries := []*Geometry{
+		NewPointGeometry([]float64{1, 2}),
+		NewPointGeometry([]float64{1, 2, 3}),
+		{Type: GeometryPoint, Point: []float64{}},
+		NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}),
+		NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}),
+		NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}}),
+		NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, [][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}),
+		NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewCollectionGeometry(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}))),
+		NewCollectionGeometry(),
+	}
+
+	for _, g := range geometries {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			var buf bytes.Buffer
+			if err := g.WriteWKB(&buf, order); err != nil {
+				t.Fatalf("should marshal %v, but got %v", g.Type, err)
+			}
+
+			decoded, err := UnmarshalWKB(buf.Bytes())
+			if err != nil {
+				t.Fatalf("should unmarshal %v, but got %v", g.Type, err)
+			}
+			if !decoded.Equal(g) {
+				t.Errorf("should round trip %v in %v, got %+v", g.Type, order, decoded)
+			}
+		}
+	}
+}
+
+func TestGeometryNotBinaryMarshaler(t *testing.T) {
+	// WKB is only written on request, encoding/gob and the other users of
+	// encoding.BinaryMarshaler keep all the fields of the geometry.
+	var g interface{} = &Geometry{}
+	if _, ok := g.(encoding.BinaryMarshaler); ok {
+		t.Errorf("should not implement encoding.BinaryMarshaler")
+	}
+	if _, ok := g.(encoding.BinaryUnmarshaler); ok {
+		t.Errorf("should not implement encoding.BinaryUnmarshaler")
+	}
+}
+
+func TestUnmarshalWKBMeasures(t *testing.T) {
+	// ISO LINESTRING M (1 2 9, 3 4 9)
+	var buf bytes.Buffer
+	buf.WriteByte(1)
+	binary.Write(&buf, binary.LittleEndian, uint32(2002))
+	binary.Write(&buf, binary.LittleEndian, uint32(2))
+	binary.Write(&buf, binary.LittleEndian, []float64{1, 2, 9, 3, 4, 9})
+
+	g, err := UnmarshalWKB(buf.Bytes())
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if !g.Equal(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})) {
+		t.Errorf("should drop measures, got %v", g.LineString)
+	}
+}
+
+func TestWKBMeasuresRoundTrip(t *testing.T) {
+	line := NewLineStringGeometry([][]float64{{1, 2, 3, 0}, {4, 5, 6, 10}})
+
+	data, err := line.MarshalWKB()
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if binary.LittleEndian.Uint32(data[1:]) != 3002 {
+		t.Errorf("should write a ZM line string, got %d", binary.LittleEndian.Uint32(data[1:]))
+	}
+	if g, err := UnmarshalWKB(data); err != nil || !g.Equal(line) {
+		t.Errorf("should keep the measures, got %v, %v", g, err)
+	}
+
+	data, err = line.MarshalEWKB(4326)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if g, srid, err := UnmarshalEWKB(data); err != nil || srid != 4326 || !g.Equal(line) {
+		t.Errorf("should keep the measures of EWKB, got %v, %d, %v", g, srid, err)
+	}
+}
+
+func TestUnmarshalWKBInvalid(t *testing.T) {
+	valid, _ := NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}).MarshalWKB()
+
+	for name, data := range map[string][]byte{
+		"empty":      {},
+		"byte order": {2, 1, 0, 0, 0},
+		"type":       {1, 9, 0, 0, 0},
+		"truncated":  valid[:len(valid)-3],
+		"trailing":   append(append([]byte(nil), valid...), 0),
+		"count":      {1, 2, 0, 0, 0, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if _, err := UnmarshalWKB(data); err == nil {
+			t.Errorf("should fail for %s", name)
+		}
+	}
+}
+
+func TestMarshalEWKB(t *testing.T) {
+	data, err := NewPointGeometry([]float64{1, 2}).MarshalEWKB(4326)
+	if err != nil {
+		t.Fatalf("should marshal, but got %v", err)
+	}
+	if h := hex.EncodeToString(data); h != "0101000020e6100000000000000000f03f0000000000000040" {
+		t.Errorf("incorrect EWKB, got %v", h)
+	}
+
+	g := NewMultiPointGeometry([]float64{1, 2, 3}, []float64{4, 5, 6})
+	data, _ = g.MarshalEWKB(3857)
+
+	decoded, srid, err := UnmarshalEWKB(data)
+	if err != nil {
+		t.Fatalf("should unmarshal, but got %v", err)
+	}
+	if srid != 3857 || !decoded.Equal(g) {
+		t.Errorf("should round trip, got %v %v", srid, decoded)
+	}
+
+	data, _ = g.MarshalEWKB(0)
+	if _, srid, _ := UnmarshalEWKB(data); srid != 0 {
+		t.Errorf("should not write an SRID, got %v", srid)
+	}
+}
+
+func TestGeometryScanEWKB(t *testing.T) {
+	// SELECT 'SRID=4326;LINESTRING(1 2, 3 4)'::geometry
+	ewkb := "0102000020E610000002000000000000000000F03F000000000000004000000000000008400000000000001040"
+
+	for _, value := range []interface{}{ewkb, []byte(ewkb)} {
+		var g Geometry
+		if err := g.Scan(value); err != nil {
+			t.Fatalf("should scan hex EWKB, but got %v", err)
+		}
+		if !g.Equal(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})) || g.CRS != nil {
+			t.Errorf("incorrect geometry, got %v", g)
+		}
+	}
+
+	data, _ := NewPointGeometry([]float64{1, 2}).MarshalEWKB(3857)
+	var g Geometry
+	if err := g.Scan(data); err != nil {
+		t.Fatalf("should scan binary EWKB, but got %v", err)
+	}
+	if name := g.CRS["properties"].(map[string]interface{})["name"]; name != "EPSG:3857" {
+		t.Errorf("should keep the SRID as CRS, got %v", g.CRS)
+	}
+
+	if err := g.Scan("not hex"); err == nil {
+		t.Errorf("should fail on invalid data")
+	}
+}
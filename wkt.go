@@ -0,0 +1,420 @@
+package geojson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ToWKT converts the geometry into OGC Well-Known Text, like "POINT (1 2)".
+// Geometries whose positions all have an altitude are written with Z
+// coordinates, and with ZM coordinates if they all have a measure too,
+// see Geometry.HasM. Further coordinates are dropped. Empty geometries are
+// written as "POINT EMPTY" and the like.
+func (g *Geometry) ToWKT() (string, error) {
+	if g == nil {
+		return "", errors.New("no geometry to convert to WKT")
+	}
+
+	z, err := hasAltitude(g)
+	if err != nil {
+		return "", err
+	}
+
+	w := &wktWriter{z: z, m: z && g.HasM()}
+	if err := w.writeGeometry(g); err != nil {
+		return "", err
+	}
+	return w.buf.String(), nil
+}
+
+// hasAltitude returns true if all positions of the geometry have an altitude,
+// and an error if only some have one.
+func hasAltitude(g *Geometry) (bool, error) {
+	dims := 0
+	var err error
+	forEachPosition(g, func(p []float64) {
+		d := 2
+		if len(p) > 2 {
+			d = 3
+		}
+		switch {
+		case err != nil:
+		case len(p) < 2:
+			err = fmt.Errorf("position %v needs at least 2 coordinates", p)
+		case dims == 0:
+			dims = d
+		case dims != d:
+			err = errors.New("positions mix 2 and 3 dimensions")
+		}
+	})
+	return dims == 3, err
+}
+
+type wktWriter struct {
+	buf  bytes.Buffer
+	z, m bool
+}
+
+func (w *wktWriter) writeGeometry(g *Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry in collection")
+	}
+
+	tag := wktTag(g.Type)
+	if tag == "" {
+		return fmt.Errorf("unknown geometry type %s", g.Type)
+	}
+	w.buf.WriteString(tag)
+	switch {
+	case w.m:
+		w.buf.WriteString(" ZM")
+	case w.z:
+		w.buf.WriteString(" Z")
+	}
+
+	if (g.Type == GeometryCollection && len(g.Geometries) == 0) || (g.Type != GeometryCollection && g.IsEmpty()) {
+		w.buf.WriteString(" EMPTY")
+		return nil
+	}
+	w.buf.WriteByte(' ')
+
+	switch g.Type {
+	case GeometryPoint:
+		w.buf.WriteByte('(')
+		err := w.writePosition(g.Point)
+		w.buf.WriteByte(')')
+		return err
+	case GeometryMultiPoint:
+		w.buf.WriteByte('(')
+		for i, p := range g.MultiPoint {
+			if i > 0 {
+				w.buf.WriteString(", ")
+			}
+			w.buf.WriteByte('(')
+			if err := w.writePosition(p); err != nil {
+				return err
+			}
+			w.buf.WriteByte(')')
+		}
+		w.buf.WriteByte(')')
+		return nil
+	case GeometryLineString:
+		return w.writePath(g.LineString)
+	case GeometryMultiLineString:
+		return w.writePaths(g.MultiLineString)
+	case GeometryPolygon:
+		return w.writePaths(g.Polygon)
+	case GeometryMultiPolygon:
+		w.buf.WriteByte('(')
+		for i, p := range g.MultiPolygon {
+			if i > 0 {
+				w.buf.WriteString(", ")
+			}
+			if err := w.writePaths(p); err != nil {
+				return err
+			}
+		}
+		w.buf.WriteByte(')')
+		return nil
+	case GeometryCollection:
+		w.buf.WriteByte('(')
+		for i, c := range g.Geometries {
+			if i > 0 {
+				w.buf.WriteString(", ")
+			}
+			if err := w.writeGeometry(c); err != nil {
+				return err
+			}
+		}
+		w.buf.WriteByte(')')
+		return nil
+	}
+
+	return nil
+}
+
+func (w *wktWriter) writePaths(paths [][][]float64) error {
+	w.buf.WriteByte('(')
+	for i, p := range paths {
+		if i > 0 {
+			w.buf.WriteString(", ")
+		}
+		if err := w.writePath(p); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte(')')
+	return nil
+}
+
+func (w *wktWriter) writePath(path [][]float64) error {
+	w.buf.WriteByte('(')
+	for i, p := range path {
+		if i > 0 {
+			w.buf.WriteString(", ")
+		}
+		if err := w.writePosition(p); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte(')')
+	return nil
+}
+
+func (w *wktWriter) writePosition(p []float64) error {
+	n := 2
+	switch {
+	case w.m:
+		n = 4
+	case w.z:
+		n = 3
+	}
+
+	for i := 0; i < n; i++ {
+		if math.IsNaN(p[i]) || math.IsInf(p[i], 0) {
+			return fmt.Errorf("coordinate %v can not be written as WKT", p[i])
+		}
+		if i > 0 {
+			w.buf.WriteByte(' ')
+		}
+		w.buf.WriteString(strconv.FormatFloat(p[i], 'f', -1, 64))
+	}
+	return nil
+}
+
+// wktTag returns the WKT name of the geometry type, or "" if it is unknown.
+func wktTag(t GeometryType) string {
+	switch t {
+	case GeometryPoint, GeometryMultiPoint, GeometryLineString, GeometryMultiLineString, GeometryPolygon, GeometryMultiPolygon:
+		return strings.ToUpper(string(t))
+	case GeometryCollection:
+		return "GEOMETRYCOLLECTION"
+	}
+	return ""
+}
+
+// UnmarshalWKT parses OGC Well-Known Text into a geometry. Z coordinates are
+// kept as altitudes and the M coordinates of ZM geometries as the fourth
+// coordinates of positions, see MeasureOf. The M coordinates of geometries
+// without Z, which positions have no place for, are dropped. A leading EWKT
+// "SRID=...;" is ignored.
+func UnmarshalWKT(s string) (*Geometry, error) {
+	if i := strings.IndexByte(s, ';'); i >= 0 && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s)), "SRID=") {
+		s = s[i+1:]
+	}
+
+	p := &wktParser{input: s}
+	p.next()
+
+	g, err := p.parseGeometry()
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		return nil, p.errorf("unexpected %q after geometry", p.token)
+	}
+	return g, nil
+}
+
+// wktParser is a recursive descent parser over WKT tokens:
+// words, numbers and punctuation.
+type wktParser struct {
+	input string
+	pos   int
+	token string
+}
+
+// next reads the next token, "" at the end of the input.
+func (p *wktParser) next() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos < len(p.input) && strings.IndexByte("(),", p.input[p.pos]) >= 0 {
+		p.pos++
+	} else {
+		for p.pos < len(p.input) && strings.IndexByte(" \t\r\n(),", p.input[p.pos]) < 0 {
+			p.pos++
+		}
+	}
+	p.token = p.input[start:p.pos]
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid WKT at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) expect(token string) error {
+	if p.token != token {
+		return p.errorf("expected %q, got %q", token, p.token)
+	}
+	p.next()
+	return nil
+}
+
+// parseGeometry parses a tagged geometry, like "POINT Z (1 2 3)".
+func (p *wktParser) parseGeometry() (*Geometry, error) {
+	tag := strings.ToUpper(p.token)
+	p.next()
+
+	// dimensions can be a separate word or glued to the tag, like POINTZ
+	dims := ""
+	for _, d := range []string{"ZM", "Z", "M"} {
+		if strings.HasSuffix(tag, d) && wktType(strings.TrimSuffix(tag, d)) != "" {
+			tag, dims = strings.TrimSuffix(tag, d), d
+			break
+		}
+	}
+	if dims == "" {
+		switch strings.ToUpper(p.token) {
+		case "Z", "M", "ZM":
+			dims = strings.ToUpper(p.token)
+			p.next()
+		}
+	}
+
+	t := wktType(tag)
+	if t == "" {
+		return nil, p.errorf("unknown geometry type %q", tag)
+	}
+	g := &Geometry{Type: t}
+
+	if strings.ToUpper(p.token) == "EMPTY" {
+		p.next()
+		return g, nil
+	}
+
+	var err error
+	switch t {
+	case GeometryPoint:
+		if err = p.expect("("); err != nil {
+			return nil, err
+		}
+		if g.Point, err = p.parsePosition(dims); err != nil {
+			return nil, err
+		}
+		err = p.expect(")")
+	case GeometryMultiPoint:
+		g.MultiPoint, err = p.parseMultiPoint(dims)
+	case GeometryLineString:
+		g.LineString, err = p.parsePath(dims)
+	case GeometryMultiLineString:
+		g.MultiLineString, err = p.parsePaths(dims)
+	case GeometryPolygon:
+		g.Polygon, err = p.parsePaths(dims)
+	case GeometryMultiPolygon:
+		err = p.parseList(func() error {
+			polygon, err := p.parsePaths(dims)
+			g.MultiPolygon = append(g.MultiPolygon, polygon)
+			return err
+		})
+	case GeometryCollection:
+		g.Geometries = []*Geometry{}
+		err = p.parseList(func() error {
+			c, err := p.parseGeometry()
+			g.Geometries = append(g.Geometries, c)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// parseList parses a parenthesized, comma separated list.
+func (p *wktParser) parseList(item func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if p.token != "," {
+			break
+		}
+		p.next()
+	}
+	return p.expect(")")
+}
+
+func (p *wktParser) parseMultiPoint(dims string) ([][]float64, error) {
+	var points [][]float64
+	err := p.parseList(func() error {
+		// points may or may not be parenthesized
+		parenthesized := p.token == "("
+		if parenthesized {
+			p.next()
+		}
+		point, err := p.parsePosition(dims)
+		if err != nil {
+			return err
+		}
+		points = append(points, point)
+		if parenthesized {
+			return p.expect(")")
+		}
+		return nil
+	})
+	return points, err
+}
+
+func (p *wktParser) parsePaths(dims string) ([][][]float64, error) {
+	var paths [][][]float64
+	err := p.parseList(func() error {
+		path, err := p.parsePath(dims)
+		paths = append(paths, path)
+		return err
+	})
+	return paths, err
+}
+
+func (p *wktParser) parsePath(dims string) ([][]float64, error) {
+	var path [][]float64
+	err := p.parseList(func() error {
+		position, err := p.parsePosition(dims)
+		path = append(path, position)
+		return err
+	})
+	return path, err
+}
+
+// parsePosition parses the coordinates of a position, dropping M values of
+// positions without Z.
+func (p *wktParser) parsePosition(dims string) ([]float64, error) {
+	var position []float64
+	for p.token != "," && p.token != ")" && p.token != "" {
+		f, err := strconv.ParseFloat(p.token, 64)
+		if err != nil {
+			return nil, p.errorf("invalid coordinate %q", p.token)
+		}
+		position = append(position, f)
+		p.next()
+	}
+
+	switch {
+	case len(position) < 2 || len(position) > 4:
+		return nil, p.errorf("position needs 2 to 4 coordinates, got %d", len(position))
+	case dims == "M" && len(position) == 3:
+		// drop the measure, there is no altitude to put before it
+		position = position[:2]
+	}
+	return position, nil
+}
+
+// wktType returns the geometry type of the upper case WKT name, or "" if it is unknown.
+func wktType(tag string) GeometryType {
+	for _, t := range []GeometryType{GeometryPoint, GeometryMultiPoint, GeometryLineString, GeometryMultiLineString, GeometryPolygon, GeometryMultiPolygon, GeometryCollection} {
+		if wktTag(t) == tag {
+			return t
+		}
+	}
+	return ""
+}
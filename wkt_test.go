@@ -0,0 +1,133 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeometryToWKT(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry *Geometry
+		wkt      string
+	}{
+		{"point", NewPointGeometry([]float64{1, 2.5}), "POINT (1 2.5)"},
+		{"point z", NewPointGeometry([]float64{1, 2, 3}), "POINT Z (1 2 3)"},
+		{"line string zm", NewLineStringGeometry([][]float64{{1, 2, 3, 0}, {4, 5, 6, 10}}), "LINESTRING ZM (1 2 3 0, 4 5 6 10)"},
+		{"mixed measures", NewLineStringGeometry([][]float64{{1, 2, 3, 0}, {4, 5, 6}}), "LINESTRING Z (1 2 3, 4 5 6)"},
+		{"multi point", NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4}), "MULTIPOINT ((1 2), (3 4))"},
+		{"line string", NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}), "LINESTRING (1 2, 3 4)"},
+		{"multi line string", NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}}), "MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))"},
+		{"polygon", NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}), "POLYGON ((0 0, 1 0, 1 1, 0 0))"},
+		{
+			"multi polygon",
+			NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, [][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}),
+			"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+		},
+		{
+			"collection",
+			NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewCollectionGeometry(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}))),
+			"GEOMETRYCOLLECTION (POINT (1 2), GEOMETRYCOLLECTION (LINESTRING (1 2, 3 4)))",
+		},
+		{"empty", &Geometry{Type: GeometryPolygon}, "POLYGON EMPTY"},
+		{"empty collection", NewCollectionGeometry(), "GEOMETRYCOLLECTION EMPTY"},
+		{"precision", NewPointGeometry([]float64{-0.000001, 123456789.125}), "POINT (-0.000001 123456789.125)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wkt, err := tc.geometry.ToWKT()
+			if err != nil {
+				t.Fatalf("should convert, but got %v", err)
+			}
+			if wkt != tc.wkt {
+				t.Errorf("incorrect wkt, got %v", wkt)
+			}
+		})
+	}
+}
+
+func TestGeometryToWKTInvalid(t *testing.T) {
+	invalid := []*Geometry{
+		NewLineStringGeometry([][]float64{{1, 2}, {3, 4, 5}}),
+		NewPointGeometry([]float64{math.NaN(), 1}),
+		{Type: "Circle", Point: []float64{1, 2}},
+		NewCollectionGeometry(nil),
+	}
+
+	for _, g := range invalid {
+		if _, err := g.ToWKT(); err == nil {
+			t.Errorf("should fail for %v", g)
+		}
+	}
+}
+
+func TestUnmarshalWKT(t *testing.T) {
+	cases := []struct {
+		wkt      string
+		geometry *Geometry
+	}{
+		{"POINT (1 2.5)", NewPointGeometry([]float64{1, 2.5})},
+		{"point(1 2)", NewPointGeometry([]float64{1, 2})},
+		{"POINT Z (1 2 3)", NewPointGeometry([]float64{1, 2, 3})},
+		{"POINTZ(1 2 3)", NewPointGeometry([]float64{1, 2, 3})},
+		{"POINT M (1 2 9)", NewPointGeometry([]float64{1, 2})},
+		{"POINT ZM (1 2 3 9)", NewPointGeometry([]float64{1, 2, 3, 9})},
+		{"SRID=4326;POINT (1 2)", NewPointGeometry([]float64{1, 2})},
+		{"MULTIPOINT ((1 2), (3 4))", NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4})},
+		{"MULTIPOINT (1 2, 3 4)", NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4})},
+		{"LINESTRING (1 2, 3 4)", NewLineStringGeometry([][]float64{{1, 2}, {3, 4}})},
+		{"MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))", NewMultiLineStringGeometry([][]float64{{1, 2}, {3, 4}}, [][]float64{{5, 6}, {7, 8}})},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 0), (0.1 0.1, 0.2 0.1, 0.2 0.2, 0.1 0.1))", NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {{0.1, 0.1}, {0.2, 0.1}, {0.2, 0.2}, {0.1, 0.1}}})},
+		{
+			"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+			NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, [][][]float64{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}),
+		},
+		{
+			"GEOMETRYCOLLECTION (POINT (1 2), GEOMETRYCOLLECTION (LINESTRING (1 2, 3 4)))",
+			NewCollectionGeometry(NewPointGeometry([]float64{1, 2}), NewCollectionGeometry(NewLineStringGeometry([][]float64{{1, 2}, {3, 4}}))),
+		},
+		{"POLYGON EMPTY", &Geometry{Type: GeometryPolygon}},
+		{"GEOMETRYCOLLECTION EMPTY", NewCollectionGeometry()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.wkt, func(t *testing.T) {
+			g, err := UnmarshalWKT(tc.wkt)
+			if err != nil {
+				t.Fatalf("should parse, but got %v", err)
+			}
+			if !g.Equal(tc.geometry) {
+				t.Errorf("incorrect geometry, got %+v", g)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWKTRoundTrip(t *testing.T) {
+	wkt := "GEOMETRYCOLLECTION Z (POINT Z (1 2 3), LINESTRING Z (1 2 3, 4 5 6))"
+
+	g, err := UnmarshalWKT(wkt)
+	if err != nil {
+		t.Fatalf("should parse, but got %v", err)
+	}
+	if s, err := g.ToWKT(); err != nil || s != wkt {
+		t.Errorf("should round trip, got %v %v", s, err)
+	}
+}
+
+func TestUnmarshalWKTInvalid(t *testing.T) {
+	for _, wkt := range []string{
+		"",
+		"CIRCLE (1 2)",
+		"POINT (1)",
+		"POINT (1 2",
+		"POINT (1 a)",
+		"LINESTRING (1 2, 3 4) extra",
+		"POLYGON (0 0, 1 0, 1 1, 0 0)",
+	} {
+		if _, err := UnmarshalWKT(wkt); err == nil {
+			t.Errorf("should fail for %q", wkt)
+		}
+	}
+}